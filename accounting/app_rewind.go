@@ -0,0 +1,358 @@
+package accounting
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	models "github.com/algorand/indexer/api/generated/v2"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// appRewind collects the application changes of an account after the rewind
+// round. Transactions are applied newest first.
+type appRewind struct {
+	addr basics.Address
+
+	// optedIn is whether the account was opted in at the rewind round, for the
+	// applications it opted in to or closed out of after the round.
+	optedIn map[uint64]bool
+	// keys are the local state keys changed after the round, by application.
+	keys map[uint64]map[string]bool
+}
+
+func makeAppRewind(addr basics.Address) appRewind {
+	return appRewind{
+		addr:    addr,
+		optedIn: make(map[uint64]bool),
+		keys:    make(map[uint64]map[string]bool),
+	}
+}
+
+// localDeltas returns the local state changes of `addr` made by an
+// application call.
+func localDeltas(addr basics.Address, stxn *transactions.SignedTxnWithAD) []basics.StateDelta {
+	var deltas []basics.StateDelta
+	for idx, delta := range stxn.ApplyData.EvalDelta.LocalDeltas {
+		var account basics.Address
+		switch {
+		case idx == 0:
+			account = stxn.Txn.Sender
+		case idx <= uint64(len(stxn.Txn.Accounts)):
+			account = stxn.Txn.Accounts[idx-1]
+		default:
+			continue
+		}
+		if account == addr {
+			deltas = append(deltas, delta)
+		}
+	}
+	return deltas
+}
+
+// apply records an application call of application `appID`.
+func (ar *appRewind) apply(stxn *transactions.SignedTxnWithAD, appID uint64) {
+	for _, delta := range localDeltas(ar.addr, stxn) {
+		if ar.keys[appID] == nil {
+			ar.keys[appID] = make(map[string]bool)
+		}
+		for key := range delta {
+			ar.keys[appID][key] = true
+		}
+	}
+
+	if stxn.Txn.Sender != ar.addr {
+		return
+	}
+	switch stxn.Txn.OnCompletion {
+	case transactions.OptInOC:
+		ar.optedIn[appID] = false
+	case transactions.CloseOutOC, transactions.ClearStateOC:
+		ar.optedIn[appID] = true
+	}
+}
+
+// localStateAtRound returns the last change of the local state `keys` of
+// `addr` in application `appID` at or before `round`, all keys if `keys` is
+// nil. The keys without a change since the last opt-in are left out.
+func localStateAtRound(db idb.IndexerDb, addr basics.Address, appID uint64, round uint64, keys map[string]bool) (map[string]basics.ValueDelta, error) {
+	// Stop the query once the last opt-in is found.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tf := idb.TransactionFilter{
+		Address:       addr[:],
+		ApplicationID: appID,
+		MaxRound:      round,
+	}
+	txns, _ := db.Transactions(ctx, tf)
+	found := make(map[string]basics.ValueDelta)
+	for txnrow := range txns {
+		if txnrow.Error != nil {
+			return nil, txnrow.Error
+		}
+		stxn, err := rowTxn(txnrow)
+		if err != nil {
+			return nil, err
+		}
+		if stxn.Txn.Type != protocol.ApplicationCallTx || txnrow.AssetID != appID {
+			continue
+		}
+
+		oc := stxn.Txn.OnCompletion
+		if stxn.Txn.Sender == addr && (oc == transactions.CloseOutOC || oc == transactions.ClearStateOC) {
+			break
+		}
+		for _, delta := range localDeltas(addr, stxn) {
+			for key, vd := range delta {
+				if _, ok := found[key]; !ok && (keys == nil || keys[key]) {
+					found[key] = vd
+				}
+			}
+		}
+		if stxn.Txn.Sender == addr && oc == transactions.OptInOC {
+			break
+		}
+		if keys != nil && len(found) == len(keys) {
+			break
+		}
+	}
+	return found, nil
+}
+
+// tealValue converts the value set by a state change.
+func tealValue(vd basics.ValueDelta) models.TealValue {
+	if vd.Action == basics.SetBytesAction {
+		return models.TealValue{
+			Bytes: base64.StdEncoding.EncodeToString([]byte(vd.Bytes)),
+			Type:  uint64(basics.TealBytesType),
+		}
+	}
+	return models.TealValue{
+		Uint: vd.Uint,
+		Type: uint64(basics.TealUintType),
+	}
+}
+
+// setKeyValues applies the changes of `found` to a key value store, the `keys`
+// without a change are removed.
+func setKeyValues(store *models.TealKeyValueStore, keys map[string]bool, found map[string]basics.ValueDelta) *models.TealKeyValueStore {
+	values := make(map[string]models.TealValue)
+	if store != nil {
+		for _, kv := range *store {
+			values[kv.Key] = kv.Value
+		}
+	}
+	for key := range keys {
+		delete(values, base64.StdEncoding.EncodeToString([]byte(key)))
+	}
+	for key, vd := range found {
+		if vd.Action != basics.DeleteAction {
+			values[base64.StdEncoding.EncodeToString([]byte(key))] = tealValue(vd)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	out := make(models.TealKeyValueStore, 0, len(values))
+	for key, value := range values {
+		out = append(out, models.TealKeyValue{Key: key, Value: value})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Key < out[j].Key
+	})
+	return &out
+}
+
+// schemaUpdate adds `add` and subtracts `sub` from the total schema of an
+// account.
+func schemaUpdate(account *models.Account, add, sub *models.ApplicationStateSchema) {
+	if add == nil && sub == nil {
+		return
+	}
+	var total models.ApplicationStateSchema
+	if account.AppsTotalSchema != nil {
+		total = *account.AppsTotalSchema
+	}
+	if add != nil {
+		total.NumByteSlice += add.NumByteSlice
+		total.NumUint += add.NumUint
+	}
+	if sub != nil {
+		total.NumByteSlice -= sub.NumByteSlice
+		total.NumUint -= sub.NumUint
+	}
+	account.AppsTotalSchema = &total
+}
+
+// extraPagesUpdate adds `add` and subtracts `sub` from the total extra program
+// pages of an account.
+func extraPagesUpdate(account *models.Account, add, sub *uint64) {
+	if add == nil && sub == nil {
+		return
+	}
+	var total uint64
+	if account.AppsTotalExtraPages != nil {
+		total = *account.AppsTotalExtraPages
+	}
+	if add != nil {
+		total += *add
+	}
+	if sub != nil {
+		total -= *sub
+	}
+	account.AppsTotalExtraPages = &total
+}
+
+// rewindCreatedApps rewinds the created applications of an account. An
+// application is only created and deleted once, so its rounds are enough.
+// Applications deleted after the round are only restored if the account was
+// looked up with the deleted applications.
+func rewindCreatedApps(account *models.Account, round uint64) {
+	if account.CreatedApps == nil {
+		return
+	}
+	apps := make([]models.Application, 0, len(*account.CreatedApps))
+	for _, app := range *account.CreatedApps {
+		deleted := app.Deleted != nil && *app.Deleted
+		switch {
+		case app.CreatedAtRound != nil && *app.CreatedAtRound > round:
+			if !deleted {
+				schemaUpdate(account, nil, app.Params.GlobalStateSchema)
+				extraPagesUpdate(account, nil, app.Params.ExtraProgramPages)
+			}
+			continue
+		case deleted && app.DeletedAtRound != nil && *app.DeletedAtRound > round:
+			schemaUpdate(account, app.Params.GlobalStateSchema, nil)
+			extraPagesUpdate(account, app.Params.ExtraProgramPages, nil)
+			app.Deleted = new(bool)
+			app.DeletedAtRound = nil
+		}
+		apps = append(apps, app)
+	}
+	account.CreatedApps = &apps
+}
+
+// appLocalSchema returns the local state schema of an application, an empty
+// schema if it was deleted.
+func appLocalSchema(db idb.IndexerDb, appID uint64) (models.ApplicationStateSchema, error) {
+	includeAll := true
+	filter := models.SearchForApplicationsParams{
+		ApplicationId: &appID,
+		IncludeAll:    &includeAll,
+	}
+	apps, _ := db.Applications(context.Background(), &filter)
+	var schema models.ApplicationStateSchema
+	for row := range apps {
+		if row.Error != nil {
+			return models.ApplicationStateSchema{}, row.Error
+		}
+		if row.Application.Params.LocalStateSchema != nil {
+			schema = *row.Application.Params.LocalStateSchema
+		}
+	}
+	return schema, nil
+}
+
+// rewindAppsLocalState rewinds the local states changed after the round.
+func rewindAppsLocalState(account *models.Account, round uint64, ar appRewind, db idb.IndexerDb) error {
+	apps := make(map[uint64]bool)
+	for appID := range ar.optedIn {
+		apps[appID] = true
+	}
+	for appID := range ar.keys {
+		apps[appID] = true
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+
+	var states []models.ApplicationLocalState
+	if account.AppsLocalState != nil {
+		states = append(states, *account.AppsLocalState...)
+	}
+	index := make(map[uint64]int)
+	for i, state := range states {
+		index[state.Id] = i
+	}
+
+	var removed []uint64
+	for appID := range apps {
+		i, present := index[appID]
+		optedInNow := present && !(states[i].Deleted != nil && *states[i].Deleted)
+		optedIn, changed := ar.optedIn[appID]
+		if !changed {
+			optedIn = optedInNow
+		}
+
+		if !optedIn {
+			if optedInNow {
+				schemaUpdate(account, nil, &states[i].Schema)
+			}
+			if present {
+				if states[i].Deleted == nil || (states[i].OptedInAtRound != nil && *states[i].OptedInAtRound > round) {
+					removed = append(removed, appID)
+				} else {
+					deleted := true
+					states[i].Deleted = &deleted
+					states[i].KeyValue = nil
+				}
+			}
+			continue
+		}
+
+		if !present {
+			states = append(states, models.ApplicationLocalState{Id: appID})
+			i = len(states) - 1
+		}
+		state := &states[i]
+		if !optedInNow {
+			schema, err := appLocalSchema(db, appID)
+			if err != nil {
+				return err
+			}
+			state.Schema = schema
+			schemaUpdate(account, &schema, nil)
+		}
+		if state.Deleted != nil {
+			state.Deleted = new(bool)
+		}
+		if state.ClosedOutAtRound != nil && *state.ClosedOutAtRound > round {
+			state.ClosedOutAtRound = nil
+		}
+
+		// After a close-out the whole local state is looked up, otherwise
+		// only the keys changed since the round.
+		keys := ar.keys[appID]
+		if changed || !optedInNow {
+			state.KeyValue = nil
+			keys = nil
+		}
+		found, err := localStateAtRound(db, ar.addr, appID, round, keys)
+		if err != nil {
+			return fmt.Errorf("rewindAppsLocalState() app %d err: %w", appID, err)
+		}
+		state.KeyValue = setKeyValues(state.KeyValue, keys, found)
+	}
+
+	out := make([]models.ApplicationLocalState, 0, len(states))
+	for _, state := range states {
+		keep := true
+		for _, appID := range removed {
+			if state.Id == appID {
+				keep = false
+			}
+		}
+		if keep {
+			out = append(out, state)
+		}
+	}
+	account.AppsLocalState = &out
+	return nil
+}
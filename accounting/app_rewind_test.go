@@ -0,0 +1,166 @@
+package accounting
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+func appCall(sender basics.Address, appID uint64, oc transactions.OnCompletion, accounts []basics.Address, deltas map[uint64]basics.StateDelta) *transactions.SignedTxnWithAD {
+	stxn := &transactions.SignedTxnWithAD{}
+	stxn.Txn.Type = protocol.ApplicationCallTx
+	stxn.Txn.Sender = sender
+	stxn.Txn.ApplicationID = basics.AppIndex(appID)
+	stxn.Txn.OnCompletion = oc
+	stxn.Txn.Accounts = accounts
+	stxn.EvalDelta.LocalDeltas = deltas
+	return stxn
+}
+
+func txnChannel(rows ...idb.TxnRow) <-chan idb.TxnRow {
+	ch := make(chan idb.TxnRow, len(rows))
+	for _, row := range rows {
+		ch <- row
+	}
+	close(ch)
+	return ch
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestRewindApps(t *testing.T) {
+	a := test.AccountA
+	other := test.AccountB
+
+	// An application pays `a` with an inner transaction.
+	pay := transactions.SignedTxnWithAD{}
+	pay.Txn.Type = protocol.PaymentTx
+	pay.Txn.Sender = other
+	pay.Txn.Receiver = a
+	pay.Txn.Amount = basics.MicroAlgos{Raw: 5}
+	root := appCall(other, 50, transactions.NoOpOC, nil, nil)
+	root.EvalDelta.InnerTxns = []transactions.SignedTxnWithAD{pay}
+
+	window := []idb.TxnRow{
+		{Round: 7, Intra: 1, RootTxn: root, AssetID: 0,
+			Extra: idb.TxnExtra{RootIntra: idb.OptionalUint{Present: true, Value: 0}}},
+		{Round: 7, Intra: 0, Txn: root, AssetID: 50},
+		{Round: 7, Txn: appCall(a, 30, transactions.CloseOutOC, nil, nil), AssetID: 30},
+		{Round: 7, Txn: appCall(other, 10, transactions.NoOpOC, []basics.Address{a},
+			map[uint64]basics.StateDelta{1: {"k": {Action: basics.SetUintAction, Uint: 3}}}), AssetID: 10},
+		{Round: 6, Txn: appCall(a, 20, transactions.OptInOC, nil, nil), AssetID: 20},
+		{Round: 6, Txn: appCall(a, 0, transactions.NoOpOC, nil, nil), AssetID: 40},
+	}
+	history := map[uint64][]idb.TxnRow{
+		10: {
+			{Round: 4, Txn: appCall(a, 10, transactions.NoOpOC, nil,
+				map[uint64]basics.StateDelta{0: {"k": {Action: basics.SetUintAction, Uint: 1}}}), AssetID: 10},
+			{Round: 2, Txn: appCall(a, 10, transactions.OptInOC, nil, nil), AssetID: 10},
+		},
+		30: {
+			{Round: 3, Txn: appCall(a, 30, transactions.OptInOC, nil,
+				map[uint64]basics.StateDelta{0: {"z": {Action: basics.SetBytesAction, Bytes: "q"}}}), AssetID: 30},
+		},
+	}
+
+	db := &mocks.IndexerDb{}
+	db.On("GetSpecialAccounts").Return(transactions.SpecialAddresses{}, nil)
+	db.On("Transactions", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, tf idb.TransactionFilter) <-chan idb.TxnRow {
+			if tf.MinRound == 6 {
+				return txnChannel(window...)
+			}
+			if tf.ApplicationID == 0 {
+				return txnChannel()
+			}
+			assert.Equal(t, uint64(5), tf.MaxRound)
+			return txnChannel(history[tf.ApplicationID]...)
+		},
+		uint64(8))
+	appRow := idb.ApplicationRow{Application: models.Application{
+		Id:     30,
+		Params: models.ApplicationParams{LocalStateSchema: &models.ApplicationStateSchema{NumByteSlice: 2}},
+	}}
+	apps := make(chan idb.ApplicationRow, 1)
+	apps <- appRow
+	close(apps)
+	var appsCh <-chan idb.ApplicationRow = apps
+	db.On("Applications", mock.Anything, mock.Anything).Return(appsCh, uint64(8))
+	db.On("GetRewardsLevel", mock.Anything, mock.Anything).Return(uint64(0), nil)
+	db.On("GetOldestRound").Return(uint64(0), nil)
+
+	genesis := uint64(0)
+	created := uint64(6)
+	extraPages := uint64(1)
+	account := models.Account{
+		Address:                     a.String(),
+		Amount:                      105,
+		AmountWithoutPendingRewards: 105,
+		CreatedAtRound:              &genesis,
+		Round:                       8,
+		AppsLocalState: &[]models.ApplicationLocalState{
+			{
+				Id:     10,
+				Schema: models.ApplicationStateSchema{NumByteSlice: 1, NumUint: 1},
+				KeyValue: &models.TealKeyValueStore{
+					{Key: b64("k"), Value: models.TealValue{Type: uint64(basics.TealUintType), Uint: 3}},
+					{Key: b64("x"), Value: models.TealValue{Type: uint64(basics.TealBytesType), Bytes: b64("b")}},
+				},
+			},
+			{Id: 20, Schema: models.ApplicationStateSchema{NumUint: 2}},
+		},
+		CreatedApps: &[]models.Application{{
+			Id:             40,
+			CreatedAtRound: &created,
+			Params: models.ApplicationParams{
+				GlobalStateSchema: &models.ApplicationStateSchema{NumByteSlice: 1},
+				ExtraProgramPages: &extraPages,
+			},
+		}},
+		AppsTotalSchema:     &models.ApplicationStateSchema{NumByteSlice: 2, NumUint: 3},
+		AppsTotalExtraPages: &extraPages,
+	}
+
+	acct, err := AccountAtRound(account, 5, db)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(100), acct.Amount)
+	assert.Empty(t, *acct.CreatedApps)
+	assert.Equal(t, uint64(0), *acct.AppsTotalExtraPages)
+	assert.Equal(t, models.ApplicationStateSchema{NumByteSlice: 3, NumUint: 1}, *acct.AppsTotalSchema)
+	assert.Equal(t, []models.ApplicationLocalState{
+		{
+			Id:     10,
+			Schema: models.ApplicationStateSchema{NumByteSlice: 1, NumUint: 1},
+			KeyValue: &models.TealKeyValueStore{
+				{Key: b64("k"), Value: models.TealValue{Type: uint64(basics.TealUintType), Uint: 1}},
+				{Key: b64("x"), Value: models.TealValue{Type: uint64(basics.TealBytesType), Bytes: b64("b")}},
+			},
+		},
+		{
+			Id:     30,
+			Schema: models.ApplicationStateSchema{NumByteSlice: 2},
+			KeyValue: &models.TealKeyValueStore{
+				{Key: b64("z"), Value: models.TealValue{Type: uint64(basics.TealBytesType), Bytes: b64("q")}},
+			},
+		},
+	}, *acct.AppsLocalState)
+
+	// The original account is not changed.
+	assert.Equal(t, uint64(1), extraPages)
+	assert.Len(t, *account.AppsLocalState, 2)
+}
@@ -0,0 +1,174 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// AssetRewind is the change of every holding of one asset after a round. It is
+// built with one pass over the asset's transactions, so the holdings of all
+// accounts can be rewound without querying the transactions of each account.
+type AssetRewind struct {
+	AssetID uint64
+	Round   uint64
+
+	// delta is added to a current amount to get the amount at Round, it wraps
+	// around like the amounts of assetUpdate.
+	delta map[basics.Address]uint64
+	// firstClose and firstOptIn are the first rounds after Round where the
+	// holding was closed and opted in. A zero amount transfer to self is taken
+	// as an opt-in.
+	firstClose map[basics.Address]uint64
+	firstOptIn map[basics.Address]uint64
+}
+
+// innerTxnAt returns the inner transaction `offset` positions after `stxn` in
+// the preorder traversal used to number the inner transactions.
+func innerTxnAt(stxn *transactions.SignedTxnWithAD, offset int) (*transactions.SignedTxnWithAD, int) {
+	for i := range stxn.ApplyData.EvalDelta.InnerTxns {
+		itxn := &stxn.ApplyData.EvalDelta.InnerTxns[i]
+		offset--
+		if offset == 0 {
+			return itxn, 0
+		}
+		var found *transactions.SignedTxnWithAD
+		found, offset = innerTxnAt(itxn, offset)
+		if found != nil {
+			return found, 0
+		}
+	}
+	return nil, offset
+}
+
+// rowTxn returns the transaction of a row, looking up inner transactions in
+// their root transaction.
+func rowTxn(txnrow idb.TxnRow) (*transactions.SignedTxnWithAD, error) {
+	if txnrow.Txn != nil {
+		return txnrow.Txn, nil
+	}
+	if txnrow.RootTxn == nil || !txnrow.Extra.RootIntra.Present {
+		return nil, fmt.Errorf("rowTxn() [%d,%d]: missing transaction", txnrow.Round, txnrow.Intra)
+	}
+	stxn, _ := innerTxnAt(txnrow.RootTxn, txnrow.Intra-int(txnrow.Extra.RootIntra.Value))
+	if stxn == nil {
+		return nil, fmt.Errorf("rowTxn() [%d,%d]: inner transaction not found", txnrow.Round, txnrow.Intra)
+	}
+	return stxn, nil
+}
+
+func (ar *AssetRewind) add(addr basics.Address, amount uint64) {
+	ar.delta[addr] += amount
+}
+
+func (ar *AssetRewind) sub(addr basics.Address, amount uint64) {
+	ar.delta[addr] -= amount
+}
+
+// setFirst records `round` in `rounds` if it is the first round of `addr`.
+func setFirst(rounds map[basics.Address]uint64, addr basics.Address, round uint64) {
+	if first, ok := rounds[addr]; !ok || round < first {
+		rounds[addr] = round
+	}
+}
+
+// AssetRewindAt returns the changes of the holdings of `assetID` in the rounds
+// (`round`, `currentRound`]. `currentRound` is the round of the holdings that
+// are rewound.
+func AssetRewindAt(ctx context.Context, db idb.IndexerDb, assetID uint64, round uint64, currentRound uint64) (AssetRewind, error) {
+	ar := AssetRewind{
+		AssetID:    assetID,
+		Round:      round,
+		delta:      make(map[basics.Address]uint64),
+		firstClose: make(map[basics.Address]uint64),
+		firstOptIn: make(map[basics.Address]uint64),
+	}
+	if round >= currentRound {
+		return ar, nil
+	}
+
+	tf := idb.TransactionFilter{
+		AssetID:  assetID,
+		MinRound: round + 1,
+		MaxRound: currentRound,
+	}
+	txns, r := db.Transactions(ctx, tf)
+	if r < currentRound {
+		return AssetRewind{}, ConsistencyError{fmt.Sprintf("queried round r: %d < currentRound: %d", r, currentRound)}
+	}
+	for txnrow := range txns {
+		if txnrow.Error != nil {
+			return AssetRewind{}, txnrow.Error
+		}
+		stxn, err := rowTxn(txnrow)
+		if err != nil {
+			return AssetRewind{}, err
+		}
+
+		switch stxn.Txn.Type {
+		case protocol.AssetConfigTx:
+			if stxn.Txn.ConfigAsset == 0 {
+				// create asset, unwind the application of the value
+				ar.sub(stxn.Txn.Sender, stxn.Txn.AssetParams.Total)
+			}
+		case protocol.AssetTransferTx:
+			if uint64(stxn.Txn.XferAsset) != assetID {
+				continue
+			}
+			source := stxn.Txn.Sender
+			if !stxn.Txn.AssetSender.IsZero() {
+				// clawback
+				source = stxn.Txn.AssetSender
+			}
+			closeAmount := txnrow.Extra.AssetCloseAmount
+			ar.add(source, stxn.Txn.AssetAmount+closeAmount)
+			ar.sub(stxn.Txn.AssetReceiver, stxn.Txn.AssetAmount)
+			if !stxn.Txn.AssetCloseTo.IsZero() {
+				ar.sub(stxn.Txn.AssetCloseTo, closeAmount)
+				setFirst(ar.firstClose, source, txnrow.Round)
+			} else if stxn.Txn.AssetAmount == 0 && source == stxn.Txn.AssetReceiver {
+				setFirst(ar.firstOptIn, source, txnrow.Round)
+			}
+		}
+	}
+	return ar, nil
+}
+
+// HoldingAtRound returns the amount of a current holding at the round of the
+// rewind, and whether the account held the asset at that round. The holding
+// must be from AssetBalances with IncludeDeleted, at the current round of the
+// rewind.
+func (ar AssetRewind) HoldingAtRound(row idb.AssetBalanceRow) (uint64, bool) {
+	var addr basics.Address
+	copy(addr[:], row.Address)
+	amount := row.Amount + ar.delta[addr]
+	if amount > 0 {
+		return amount, true
+	}
+
+	// CreatedRound is the first opt-in and ClosedRound the last close, the
+	// opt-ins in between are only known from the transactions.
+	if row.CreatedRound != nil && *row.CreatedRound > ar.Round {
+		return 0, false
+	}
+	optIn, optedIn := ar.firstOptIn[addr]
+	if closeRound, ok := ar.firstClose[addr]; ok {
+		// Held until the close, unless it was opted in first.
+		return 0, !optedIn || optIn > closeRound
+	}
+	if row.Deleted != nil && *row.Deleted {
+		// Closed before the round and not opted in since.
+		return 0, false
+	}
+	if row.ClosedRound == nil {
+		return 0, true
+	}
+	// Closed before the round and opted in again, held unless that was after
+	// the round.
+	return 0, !optedIn
+}
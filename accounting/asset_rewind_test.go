@@ -0,0 +1,155 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+func axfer(sender, assetSender, receiver, closeTo basics.Address, amount uint64) transactions.SignedTxnWithAD {
+	return transactions.SignedTxnWithAD{
+		SignedTxn: transactions.SignedTxn{
+			Txn: transactions.Transaction{
+				Type:   protocol.AssetTransferTx,
+				Header: transactions.Header{Sender: sender},
+				AssetTransferTxnFields: transactions.AssetTransferTxnFields{
+					XferAsset:     1,
+					AssetAmount:   amount,
+					AssetSender:   assetSender,
+					AssetReceiver: receiver,
+					AssetCloseTo:  closeTo,
+				},
+			},
+		},
+	}
+}
+
+func assetRewindDb(rows []idb.TxnRow, round uint64) *mocks.IndexerDb {
+	ch := make(chan idb.TxnRow, len(rows))
+	for _, row := range rows {
+		ch <- row
+	}
+	close(ch)
+	var outCh <-chan idb.TxnRow = ch
+
+	db := &mocks.IndexerDb{}
+	db.On("Transactions", mock.Anything, mock.Anything).Return(outCh, round)
+	return db
+}
+
+func TestAssetRewind(t *testing.T) {
+	var zero basics.Address
+	transfer := axfer(test.AccountA, zero, test.AccountB, zero, 10)
+	clawback := axfer(test.AccountE, test.AccountB, test.AccountC, zero, 3)
+	optIn := axfer(test.AccountD, zero, test.AccountD, zero, 0)
+	closeOut := axfer(test.AccountC, zero, test.AccountA, test.AccountB, 1)
+	// An application sends the asset to D with an inner transaction.
+	appl := transactions.SignedTxnWithAD{}
+	appl.Txn.Type = protocol.ApplicationCallTx
+	inner := axfer(test.AccountA, zero, test.AccountD, zero, 5)
+	appl.EvalDelta.InnerTxns = []transactions.SignedTxnWithAD{
+		axfer(test.AccountE, zero, test.AccountE, zero, 0),
+	}
+	appl.EvalDelta.InnerTxns[0].EvalDelta.InnerTxns = []transactions.SignedTxnWithAD{inner}
+
+	rows := []idb.TxnRow{
+		{Round: 6, Txn: &transfer},
+		{Round: 7, Txn: &clawback},
+		{Round: 7, Intra: 1, Txn: &optIn},
+		{Round: 8, Txn: &closeOut, Extra: idb.TxnExtra{AssetCloseAmount: 2}},
+		{Round: 8, Intra: 3, RootTxn: &appl,
+			Extra: idb.TxnExtra{RootIntra: idb.OptionalUint{Present: true, Value: 1}}},
+	}
+	db := assetRewindDb(rows, 8)
+
+	ar, err := AssetRewindAt(context.Background(), db, 1, 5, 8)
+	require.NoError(t, err)
+	tf := db.Calls[0].Arguments.Get(1).(idb.TransactionFilter)
+	assert.Equal(t, idb.TransactionFilter{AssetID: 1, MinRound: 6, MaxRound: 8}, tf)
+
+	deleted := true
+	tests := []struct {
+		name   string
+		row    idb.AssetBalanceRow
+		amount uint64
+		held   bool
+	}{
+		{
+			name:   "sender",
+			row:    idb.AssetBalanceRow{Address: test.AccountA[:], Amount: 86, CreatedRound: uint64Ptr(1)},
+			amount: 100,
+			held:   true,
+		},
+		{
+			name:   "received, clawed back and closed to",
+			row:    idb.AssetBalanceRow{Address: test.AccountB[:], Amount: 9, CreatedRound: uint64Ptr(2)},
+			amount: 0,
+			held:   true,
+		},
+		{
+			name: "closed",
+			row: idb.AssetBalanceRow{Address: test.AccountC[:], CreatedRound: uint64Ptr(4),
+				ClosedRound: uint64Ptr(8), Deleted: &deleted},
+			held: true,
+		},
+		{
+			name:   "opted in after the round",
+			row:    idb.AssetBalanceRow{Address: test.AccountD[:], Amount: 5, CreatedRound: uint64Ptr(7)},
+			amount: 0,
+			held:   false,
+		},
+		{
+			name: "closed before the round",
+			row: idb.AssetBalanceRow{Address: test.AccountE[:], CreatedRound: uint64Ptr(1),
+				ClosedRound: uint64Ptr(3), Deleted: &deleted},
+			held: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			amount, held := ar.HoldingAtRound(tc.row)
+			assert.Equal(t, tc.amount, amount)
+			assert.Equal(t, tc.held, held)
+		})
+	}
+}
+
+func TestAssetRewindReopened(t *testing.T) {
+	var zero basics.Address
+	optIn := axfer(test.AccountA, zero, test.AccountA, zero, 0)
+	db := assetRewindDb([]idb.TxnRow{{Round: 7, Txn: &optIn}}, 8)
+
+	ar, err := AssetRewindAt(context.Background(), db, 1, 5, 8)
+	require.NoError(t, err)
+
+	// First opted in at 1, closed at 3 and opted in again at 7.
+	row := idb.AssetBalanceRow{Address: test.AccountA[:], CreatedRound: uint64Ptr(1), ClosedRound: uint64Ptr(3)}
+	_, held := ar.HoldingAtRound(row)
+	assert.False(t, held)
+
+	// Without the opt-in, it was opted in again before the round.
+	ar, err = AssetRewindAt(context.Background(), assetRewindDb(nil, 8), 1, 5, 8)
+	require.NoError(t, err)
+	_, held = ar.HoldingAtRound(row)
+	assert.True(t, held)
+}
+
+func TestAssetRewindStale(t *testing.T) {
+	_, err := AssetRewindAt(context.Background(), assetRewindDb(nil, 7), 1, 5, 8)
+	assert.True(t, errors.As(err, &ConsistencyError{}), "err: %v", err)
+}
+
+func uint64Ptr(x uint64) *uint64 {
+	return &x
+}
@@ -0,0 +1,169 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	models "github.com/algorand/indexer/api/generated/v2"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// lastTxn returns the first transaction of a newest first query accepted by
+// `match`, nil if there is none.
+func lastTxn(db idb.IndexerDb, tf idb.TransactionFilter, match func(stxn *transactions.SignedTxnWithAD) bool) (*idb.TxnRow, *transactions.SignedTxnWithAD, error) {
+	// Stop the query at the first match.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txns, _ := db.Transactions(ctx, tf)
+	for txnrow := range txns {
+		if txnrow.Error != nil {
+			return nil, nil, txnrow.Error
+		}
+		stxn, err := rowTxn(txnrow)
+		if err != nil {
+			return nil, nil, err
+		}
+		if match(stxn) {
+			return &txnrow, stxn, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// rewindParticipation sets the status and participation keys of `account` at
+// `round` from the last key registration at or before the round.
+func rewindParticipation(account *models.Account, addr basics.Address, round uint64, db idb.IndexerDb) error {
+	tf := idb.TransactionFilter{
+		Address:  addr[:],
+		TypeEnum: idb.TypeEnumKeyreg,
+		MaxRound: round,
+	}
+	_, keyreg, err := lastTxn(db, tf, func(stxn *transactions.SignedTxnWithAD) bool {
+		return stxn.Txn.Type == protocol.KeyRegistrationTx && stxn.Txn.Sender == addr
+	})
+	if err != nil {
+		return fmt.Errorf("rewindParticipation() err: %w", err)
+	}
+
+	account.Participation = nil
+	account.Status = "Offline"
+	if keyreg == nil {
+		return nil
+	}
+	if keyreg.Txn.Nonparticipation {
+		account.Status = "NotParticipating"
+		return nil
+	}
+	if keyreg.Txn.VotePK == (crypto.OneTimeSignatureVerifier{}) {
+		return nil
+	}
+	account.Status = "Online"
+	account.Participation = &models.AccountParticipation{
+		SelectionParticipationKey: keyreg.Txn.SelectionPK[:],
+		VoteFirstValid:            uint64(keyreg.Txn.VoteFirst),
+		VoteKeyDilution:           keyreg.Txn.VoteKeyDilution,
+		VoteLastValid:             uint64(keyreg.Txn.VoteLast),
+		VoteParticipationKey:      keyreg.Txn.VotePK[:],
+	}
+	return nil
+}
+
+// touchesBalance returns whether a transaction applies the rewards of `addr`,
+// which happens whenever its Algo balance changes.
+func touchesBalance(addr basics.Address, stxn *transactions.SignedTxnWithAD) bool {
+	if stxn.Txn.Sender == addr {
+		return true
+	}
+	return stxn.Txn.Type == protocol.PaymentTx &&
+		(stxn.Txn.Receiver == addr || stxn.Txn.CloseRemainderTo == addr)
+}
+
+// maxRewardsBaseScan is the maximum number of transactions of an account read
+// back from the rewind round to find the last change of its balance.
+const maxRewardsBaseScan = 10000
+
+// missingRewardsBase returns why no balance change of `account` was found in
+// the `scanned` transactions before the rewind round, nil for a genesis
+// account whose balance did not change since genesis.
+func missingRewardsBase(account *models.Account, scanned uint64, db idb.IndexerDb) error {
+	if scanned >= maxRewardsBaseScan {
+		return fmt.Errorf("no balance change in the last %d transactions", maxRewardsBaseScan)
+	}
+	var created uint64
+	if account.CreatedAtRound != nil {
+		created = *account.CreatedAtRound
+	}
+	oldest, err := db.GetOldestRound()
+	if err != nil {
+		return err
+	}
+	if oldest > created {
+		return fmt.Errorf("the transactions before round %d are pruned", oldest)
+	}
+	if account.CreatedAtRound == nil || created != 0 {
+		return fmt.Errorf("no balance change since the account was created")
+	}
+	return nil
+}
+
+// rewindPendingRewards sets the rewards base and the pending rewards of
+// `account` at `round`. The rewards base is the rewards level of the last
+// round at or before `round` where the balance of the account changed.
+func rewindPendingRewards(account *models.Account, addr basics.Address, round uint64, db idb.IndexerDb) error {
+	account.PendingRewards = 0
+	account.RewardBase = nil
+	if account.Status == "NotParticipating" {
+		return nil
+	}
+
+	tf := idb.TransactionFilter{
+		Address:  addr[:],
+		MaxRound: round,
+		Limit:    maxRewardsBaseScan,
+	}
+	// The balance cannot change before the account is created.
+	if account.CreatedAtRound != nil {
+		tf.MinRound = *account.CreatedAtRound
+	}
+	var scanned uint64
+	txnrow, _, err := lastTxn(db, tf, func(stxn *transactions.SignedTxnWithAD) bool {
+		scanned++
+		return touchesBalance(addr, stxn)
+	})
+	if err != nil {
+		return fmt.Errorf("rewindPendingRewards() err: %w", err)
+	}
+
+	// Genesis accounts start at rewards level 0.
+	var base uint64
+	if txnrow != nil {
+		base, err = db.GetRewardsLevel(context.Background(), txnrow.Round)
+		if err != nil {
+			return fmt.Errorf("rewindPendingRewards() base round %d err: %w", txnrow.Round, err)
+		}
+	} else {
+		err = missingRewardsBase(account, scanned, db)
+		if err != nil {
+			return fmt.Errorf("rewindPendingRewards() err: %w", err)
+		}
+	}
+	level, err := db.GetRewardsLevel(context.Background(), round)
+	if err != nil {
+		return fmt.Errorf("rewindPendingRewards() round %d err: %w", round, err)
+	}
+
+	// The reward unit has not changed between protocol versions.
+	rewardUnit := config.Consensus[protocol.ConsensusCurrentVersion].RewardUnit
+	if rewardUnit != 0 {
+		account.PendingRewards = account.AmountWithoutPendingRewards / rewardUnit * (level - base)
+	}
+	account.RewardBase = &base
+	return nil
+}
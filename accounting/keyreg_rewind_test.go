@@ -0,0 +1,200 @@
+package accounting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+func keyregTxn(sender basics.Address, key byte) *transactions.SignedTxnWithAD {
+	stxn := &transactions.SignedTxnWithAD{}
+	stxn.Txn.Type = protocol.KeyRegistrationTx
+	stxn.Txn.Sender = sender
+	if key != 0 {
+		stxn.Txn.VotePK[0] = key
+		stxn.Txn.SelectionPK[0] = key
+		stxn.Txn.VoteFirst = basics.Round(key)
+		stxn.Txn.VoteLast = basics.Round(key) + 1000
+		stxn.Txn.VoteKeyDilution = 10
+	}
+	return stxn
+}
+
+func payTxn(sender, receiver basics.Address, amount uint64, receiverRewards uint64) *transactions.SignedTxnWithAD {
+	stxn := &transactions.SignedTxnWithAD{}
+	stxn.Txn.Type = protocol.PaymentTx
+	stxn.Txn.Sender = sender
+	stxn.Txn.Receiver = receiver
+	stxn.Txn.Amount = basics.MicroAlgos{Raw: amount}
+	stxn.ReceiverRewards = basics.MicroAlgos{Raw: receiverRewards}
+	return stxn
+}
+
+// keyregRewindDb returns a database where `window` are the transactions after
+// round 5, `keyregs` the key registrations and `history` the other
+// transactions at or before round 5.
+func keyregRewindDb(t *testing.T, window, keyregs, history []idb.TxnRow) *mocks.IndexerDb {
+	db := &mocks.IndexerDb{}
+	db.On("GetSpecialAccounts").Return(transactions.SpecialAddresses{}, nil)
+	db.On("Transactions", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, tf idb.TransactionFilter) <-chan idb.TxnRow {
+			if tf.MinRound == 6 {
+				return txnChannel(window...)
+			}
+			assert.Equal(t, uint64(5), tf.MaxRound)
+			if tf.TypeEnum == idb.TypeEnumKeyreg {
+				return txnChannel(keyregs...)
+			}
+			return txnChannel(history...)
+		},
+		uint64(10))
+	db.On("GetRewardsLevel", mock.Anything, uint64(4)).Return(uint64(100), nil)
+	db.On("GetRewardsLevel", mock.Anything, uint64(5)).Return(uint64(103), nil)
+	return db
+}
+
+func TestRewindParticipationAndRewards(t *testing.T) {
+	a := test.AccountA
+	window := []idb.TxnRow{
+		{Round: 9, Txn: payTxn(test.AccountB, a, 1000000, 7)},
+		{Round: 8, Txn: keyregTxn(a, 2)},
+	}
+	keyregs := []idb.TxnRow{
+		{Round: 3, Txn: keyregTxn(a, 1)},
+	}
+	history := []idb.TxnRow{
+		// `a` is only a participant of the asset transfer.
+		{Round: 5, Txn: &transactions.SignedTxnWithAD{SignedTxn: transactions.SignedTxn{Txn: transactions.Transaction{
+			Type:                   protocol.AssetTransferTx,
+			Header:                 transactions.Header{Sender: test.AccountB},
+			AssetTransferTxnFields: transactions.AssetTransferTxnFields{AssetReceiver: a},
+		}}}},
+		{Round: 4, Txn: payTxn(test.AccountB, a, 2000000, 0)},
+	}
+	db := keyregRewindDb(t, window, keyregs, history)
+
+	account := models.Account{
+		Address:                     a.String(),
+		AmountWithoutPendingRewards: 3000007,
+		Rewards:                     20,
+		Round:                       10,
+		Status:                      "Online",
+		Participation:               &models.AccountParticipation{VoteParticipationKey: []byte{2}},
+	}
+	acct, err := AccountAtRound(account, 5, db)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Online", acct.Status)
+	require.NotNil(t, acct.Participation)
+	expected := keyregTxn(a, 1)
+	assert.Equal(t, expected.Txn.VotePK[:], acct.Participation.VoteParticipationKey)
+	assert.Equal(t, expected.Txn.SelectionPK[:], acct.Participation.SelectionParticipationKey)
+	assert.Equal(t, uint64(1), acct.Participation.VoteFirstValid)
+	assert.Equal(t, uint64(1001), acct.Participation.VoteLastValid)
+	assert.Equal(t, uint64(10), acct.Participation.VoteKeyDilution)
+
+	// 2 Algos for 3 rewards levels since round 4.
+	assert.Equal(t, uint64(2000000), acct.AmountWithoutPendingRewards)
+	assert.Equal(t, uint64(6), acct.PendingRewards)
+	assert.Equal(t, uint64(2000006), acct.Amount)
+	assert.Equal(t, uint64(13), acct.Rewards)
+	require.NotNil(t, acct.RewardBase)
+	assert.Equal(t, uint64(100), *acct.RewardBase)
+}
+
+func TestRewindParticipationOffline(t *testing.T) {
+	a := test.AccountA
+	window := []idb.TxnRow{
+		{Round: 8, Txn: keyregTxn(a, 2)},
+	}
+	history := []idb.TxnRow{
+		{Round: 4, Txn: payTxn(test.AccountB, a, 2000000, 0)},
+	}
+	db := keyregRewindDb(t, window, nil, history)
+
+	account := models.Account{
+		Address:                     a.String(),
+		AmountWithoutPendingRewards: 2000000,
+		Round:                       10,
+		Status:                      "Online",
+		Participation:               &models.AccountParticipation{VoteParticipationKey: []byte{2}},
+	}
+	acct, err := AccountAtRound(account, 5, db)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Offline", acct.Status)
+	assert.Nil(t, acct.Participation)
+	assert.Equal(t, uint64(6), acct.PendingRewards)
+}
+
+func TestRewindPendingRewardsWithoutHistory(t *testing.T) {
+	genesis := uint64(0)
+	created := uint64(2)
+	testcases := []struct {
+		name    string
+		created *uint64
+		oldest  uint64
+		history []idb.TxnRow
+		err     string
+	}{
+		{name: "genesis account", created: &genesis},
+		{name: "pruned", created: &genesis, oldest: 3, err: "pruned"},
+		{name: "created before pruned rounds", created: &created, oldest: 3, err: "pruned"},
+		{name: "missing history", created: &created, err: "since the account was created"},
+		{name: "unknown creation round", err: "since the account was created"},
+		{
+			name:    "scan limit",
+			created: &genesis,
+			history: make([]idb.TxnRow, maxRewardsBaseScan),
+			err:     "no balance change in the last",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := test.AccountA
+			// Transactions that don't change the balance of `a`.
+			for i := range tc.history {
+				tc.history[i] = idb.TxnRow{Round: 5, Txn: payTxn(test.AccountB, test.AccountC, 1, 0)}
+			}
+			db := &mocks.IndexerDb{}
+			db.On("Transactions", mock.Anything, mock.Anything).Return(
+				func(ctx context.Context, tf idb.TransactionFilter) <-chan idb.TxnRow {
+					assert.Equal(t, uint64(maxRewardsBaseScan), tf.Limit)
+					if tc.created != nil {
+						assert.Equal(t, *tc.created, tf.MinRound)
+					}
+					return txnChannel(tc.history...)
+				},
+				uint64(10))
+			db.On("GetOldestRound").Return(tc.oldest, nil)
+			db.On("GetRewardsLevel", mock.Anything, uint64(5)).Return(uint64(103), nil)
+
+			account := models.Account{
+				AmountWithoutPendingRewards: 2000000,
+				CreatedAtRound:              tc.created,
+				Status:                      "Offline",
+			}
+			err := rewindPendingRewards(&account, a, 5, db)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, account.RewardBase)
+			assert.Equal(t, uint64(0), *account.RewardBase)
+			assert.Equal(t, uint64(206), account.PendingRewards)
+		})
+	}
+}
@@ -105,20 +105,24 @@ func AccountAtRound(account models.Account, round uint64, db idb.IndexerDb) (acc
 		return
 	}
 	txcount := 0
+	apps := makeAppRewind(addr)
+	var rewards uint64
+	var keyreg, closed bool
 	for txnrow := range txns {
 		if txnrow.Error != nil {
 			err = txnrow.Error
 			return
 		}
 		txcount++
-		stxn := txnrow.Txn
-		if stxn == nil {
-			return models.Account{},
-				fmt.Errorf("rewinding past inner transactions is not supported")
+		var stxn *transactions.SignedTxnWithAD
+		stxn, err = rowTxn(txnrow)
+		if err != nil {
+			return
 		}
 		if addr == stxn.Txn.Sender {
 			acct.AmountWithoutPendingRewards += stxn.Txn.Fee.ToUint64()
 			acct.AmountWithoutPendingRewards -= stxn.SenderRewards.ToUint64()
+			rewards += stxn.SenderRewards.ToUint64()
 		}
 		switch stxn.Txn.Type {
 		case protocol.PaymentTx:
@@ -128,17 +132,20 @@ func AccountAtRound(account models.Account, round uint64, db idb.IndexerDb) (acc
 			if addr == stxn.Txn.Receiver {
 				acct.AmountWithoutPendingRewards -= stxn.Txn.Amount.ToUint64()
 				acct.AmountWithoutPendingRewards -= stxn.ReceiverRewards.ToUint64()
+				rewards += stxn.ReceiverRewards.ToUint64()
 			}
 			if addr == stxn.Txn.CloseRemainderTo {
 				// unwind receiving a close-to
 				acct.AmountWithoutPendingRewards -= stxn.ClosingAmount.ToUint64()
 				acct.AmountWithoutPendingRewards -= stxn.CloseRewards.ToUint64()
+				rewards += stxn.CloseRewards.ToUint64()
 			} else if !stxn.Txn.CloseRemainderTo.IsZero() {
 				// unwind sending a close-to
 				acct.AmountWithoutPendingRewards += stxn.ClosingAmount.ToUint64()
+				closed = closed || addr == stxn.Txn.Sender
 			}
 		case protocol.KeyRegistrationTx:
-			// TODO: keyreg does not rewind. workaround: query for txns on an account with typeenum=2 to find previous values it was set to.
+			keyreg = keyreg || addr == stxn.Txn.Sender
 		case protocol.AssetConfigTx:
 			if stxn.Txn.ConfigAsset == 0 {
 				// create asset, unwind the application of the value
@@ -155,20 +162,43 @@ func AccountAtRound(account models.Account, round uint64, db idb.IndexerDb) (acc
 				assetUpdate(&acct, uint64(stxn.Txn.XferAsset), 0, txnrow.Extra.AssetCloseAmount)
 			}
 		case protocol.AssetFreezeTx:
+		case protocol.ApplicationCallTx:
+			apps.apply(stxn, txnrow.AssetID)
 		default:
 			err = fmt.Errorf("%s[%d,%d]: rewinding past txn type %s is not currently supported", account.Address, txnrow.Round, txnrow.Intra, stxn.Txn.Type)
 			return
 		}
 	}
 
+	rewindCreatedApps(&acct, round)
+	err = rewindAppsLocalState(&acct, round, apps, db)
+	if err != nil {
+		return
+	}
+
 	acct.Round = round
 
-	// Due to accounts being closed and re-opened, we cannot always rewind Rewards. So clear it out.
-	acct.Rewards = 0
+	// Closing an account resets its status and keys, like a key registration.
+	if keyreg || closed {
+		err = rewindParticipation(&acct, addr, round, db)
+		if err != nil {
+			return
+		}
+	}
+
+	// The rewards total starts over when an account is closed and re-opened,
+	// so it cannot be rewound past a close.
+	if closed {
+		acct.Rewards = 0
+	} else {
+		acct.Rewards -= rewards
+	}
 
-	// Computing pending rewards is not supported.
-	acct.PendingRewards = 0
-	acct.Amount = acct.AmountWithoutPendingRewards
+	err = rewindPendingRewards(&acct, addr, round, db)
+	if err != nil {
+		return
+	}
+	acct.Amount = acct.AmountWithoutPendingRewards + acct.PendingRewards
 
 	// TODO: Clear out the closed-at field as well. Like Rewards we cannot know this value for all accounts.
 	//acct.ClosedAt = 0
@@ -19,10 +19,12 @@ func TestBasic(t *testing.T) {
 	var a basics.Address
 	a[0] = 'a'
 
+	genesis := uint64(0)
 	account := models.Account{
 		Address:                     a.String(),
 		Amount:                      100,
 		AmountWithoutPendingRewards: 100,
+		CreatedAtRound:              &genesis,
 		Round:                       8,
 	}
 
@@ -49,6 +51,8 @@ func TestBasic(t *testing.T) {
 	db := &mocks.IndexerDb{}
 	db.On("GetSpecialAccounts").Return(transactions.SpecialAddresses{}, nil)
 	db.On("Transactions", mock.Anything, mock.Anything).Return(outCh, uint64(8))
+	db.On("GetRewardsLevel", mock.Anything, mock.Anything).Return(uint64(0), nil)
+	db.On("GetOldestRound").Return(uint64(0), nil)
 
 	account, err := AccountAtRound(account, 6, db)
 	assert.NoError(t, err)
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/labstack/echo/v4"
+
+	"github.com/algorand/indexer/accounting"
+	"github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+)
+
+// maxAccountsBatchSize is the maximum number of addresses of a bulk account
+// lookup.
+const maxAccountsBatchSize = maxAccountsLimit
+
+// LookupAccountsBatch looks up several accounts with one query.
+// (POST /v2/accounts/batch)
+func (si *ServerImplementation) LookupAccountsBatch(ctx echo.Context) error {
+	var req generated.LookupAccountsBatchJSONRequestBody
+	err := ctx.Bind(&req)
+	if err != nil {
+		return badRequest(ctx, fmt.Sprintf("%s: %v", errInvalidAccountsBatch, err))
+	}
+	if len(req.Addresses) == 0 {
+		return badRequest(ctx, errNoAddresses)
+	}
+	if len(req.Addresses) > maxAccountsBatchSize {
+		return badRequest(ctx, fmt.Sprintf(errTooManyAddresses, maxAccountsBatchSize))
+	}
+	if req.Round != nil && len(req.Addresses) > 1 && !si.EnableAddressSearchRoundRewind {
+		return badRequest(ctx, errMultiAcctRewind)
+	}
+	msg, err := si.rewindPrunedError(req.Round)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errFailedSearchingAccount, err))
+	}
+	if msg != "" {
+		return badRequest(ctx, fmt.Sprintf("%s: %s", errRewindingAccount, msg))
+	}
+
+	var batchErrors []generated.AccountError
+	var addresses []string
+	var addrs [][]byte
+	seen := make(map[string]bool)
+	for _, address := range req.Addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		addr, err := basics.UnmarshalChecksumAddress(address)
+		if err != nil {
+			batchErrors = append(batchErrors, generated.AccountError{Address: address, Message: errUnableToParseAddress})
+			continue
+		}
+		addresses = append(addresses, address)
+		addrs = append(addrs, addr[:])
+	}
+
+	includeAssets := req.IncludeAssets == nil || *req.IncludeAssets
+	includeApps := req.IncludeApps == nil || *req.IncludeApps
+	options := idb.AccountQueryOptions{
+		EqualToAddresses:     addrs,
+		IncludeAssetHoldings: includeAssets,
+		IncludeAssetParams:   includeAssets,
+		// Rewinding needs the applications for the schema totals, they are
+		// left out after.
+		ExcludeApps:    !includeApps && req.Round == nil,
+		IncludeDeleted: boolOrDefault(req.IncludeAll),
+		Limit:          uint64(len(addrs)),
+	}
+	accounts := make(map[string]generated.Account)
+	var accountErrors map[string]string
+	var round uint64
+	if len(addrs) > 0 {
+		accounts, accountErrors, round, err = si.fetchAccountsByAddress(ctx.Request().Context(), options, req.Round)
+		if err != nil {
+			return indexerError(ctx, fmt.Errorf("%s: %w", errFailedSearchingAccount, err))
+		}
+	} else {
+		nextRound, err := si.db.GetNextRoundToAccount()
+		if err != nil {
+			return indexerError(ctx, err)
+		}
+		if nextRound > 0 {
+			round = nextRound - 1
+		}
+	}
+
+	res := generated.AccountsBatchResponse{
+		Accounts:     make([]generated.Account, 0, len(accounts)),
+		CurrentRound: round,
+	}
+	for _, address := range addresses {
+		account, ok := accounts[address]
+		switch {
+		case accountErrors[address] != "":
+			batchErrors = append(batchErrors, generated.AccountError{Address: address, Message: accountErrors[address]})
+		case !ok:
+			batchErrors = append(batchErrors, generated.AccountError{Address: address, Message: errNoAccountsFound})
+		default:
+			if !includeApps {
+				account.AppsLocalState = nil
+				account.CreatedApps = nil
+			}
+			res.Accounts = append(res.Accounts, account)
+		}
+	}
+	if len(batchErrors) > 0 {
+		res.Errors = &batchErrors
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// fetchAccountsByAddress returns the accounts of options.EqualToAddresses by
+// address, rewound to `atRound` if it is not nil. The accounts that cannot be
+// rewound are left out, with their error by address.
+func (si *ServerImplementation) fetchAccountsByAddress(ctx context.Context, options idb.AccountQueryOptions, atRound *uint64) (map[string]generated.Account, map[string]string, uint64 /*round*/, error) {
+	var round uint64
+	accounts := make(map[string]generated.Account)
+	accountErrors := make(map[string]string)
+	err := callWithTimeout(ctx, si.log, si.timeout, func(ctx context.Context) error {
+		var accountchan <-chan idb.AccountRow
+		accountchan, round = si.db.GetAccounts(ctx, options)
+
+		if (atRound != nil) && (*atRound > round) {
+			return fmt.Errorf("%s: the requested round %d > the current round %d",
+				errRewindingAccount, *atRound, round)
+		}
+
+		for row := range accountchan {
+			if row.Error != nil {
+				return row.Error
+			}
+
+			account := row.Account
+			if atRound != nil {
+				acct, err := accounting.AccountAtRound(row.Account, *atRound, si.db)
+				if err != nil {
+					accountErrors[row.Account.Address] = fmt.Sprintf("%s: %v", errRewindingAccount, err)
+					continue
+				}
+				account = acct
+			}
+
+			// match the algod equivalent which includes pending rewards
+			account.Rewards += account.PendingRewards
+			accounts[account.Address] = account
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return accounts, accountErrors, round, nil
+}
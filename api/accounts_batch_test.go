@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+func callAccountsBatch(t *testing.T, si *ServerImplementation, body string) (int, string) {
+	req := httptest.NewRequest(http.MethodPost, "/v2/accounts/batch", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "application/json")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	require.NoError(t, si.LookupAccountsBatch(c))
+	return rec.Code, rec.Body.String()
+}
+
+func TestLookupAccountsBatch(t *testing.T) {
+	apps := []generated.Application{{Id: 1}}
+	known := map[basics.Address]generated.Account{
+		test.AccountA: {Address: test.AccountA.String(), Amount: 1, CreatedApps: &apps},
+		test.AccountB: {Address: test.AccountB.String(), Amount: 2, CreatedApps: &apps},
+	}
+	var queries []idb.AccountQueryOptions
+	db := &mocks.IndexerDb{}
+	db.On("GetAccounts", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, opts idb.AccountQueryOptions) <-chan idb.AccountRow {
+			queries = append(queries, opts)
+			ch := make(chan idb.AccountRow, len(opts.EqualToAddresses))
+			for _, addr := range opts.EqualToAddresses {
+				var address basics.Address
+				copy(address[:], addr)
+				if account, ok := known[address]; ok {
+					if opts.ExcludeApps {
+						account.CreatedApps = nil
+					}
+					ch <- idb.AccountRow{Account: account}
+				}
+			}
+			close(ch)
+			return ch
+		},
+		uint64(10))
+	si := &ServerImplementation{db: db, timeout: time.Second, log: log.New()}
+
+	body := `{"addresses": ["` + test.AccountB.String() + `", "bad", "` + test.AccountA.String() + `", "` +
+		test.AccountB.String() + `", "` + test.AccountC.String() + `"], "include-apps": false}`
+	code, resBody := callAccountsBatch(t, si, body)
+	require.Equal(t, http.StatusOK, code, resBody)
+
+	// One query for all valid addresses.
+	require.Len(t, queries, 1)
+	assert.Equal(t, [][]byte{test.AccountB[:], test.AccountA[:], test.AccountC[:]}, queries[0].EqualToAddresses)
+	assert.True(t, queries[0].IncludeAssetHoldings)
+	assert.True(t, queries[0].ExcludeApps)
+
+	var res generated.AccountsBatchResponse
+	require.NoError(t, json.Unmarshal([]byte(resBody), &res))
+	assert.Equal(t, uint64(10), res.CurrentRound)
+	require.Len(t, res.Accounts, 2)
+	assert.Equal(t, test.AccountB.String(), res.Accounts[0].Address)
+	assert.Equal(t, test.AccountA.String(), res.Accounts[1].Address)
+	assert.Nil(t, res.Accounts[0].CreatedApps)
+	require.NotNil(t, res.Errors)
+	assert.Equal(t, []generated.AccountError{
+		{Address: "bad", Message: errUnableToParseAddress},
+		{Address: test.AccountC.String(), Message: errNoAccountsFound},
+	}, *res.Errors)
+}
+
+func TestLookupAccountsBatchBadRequests(t *testing.T) {
+	si := &ServerImplementation{db: &mocks.IndexerDb{}, timeout: time.Second, log: log.New()}
+	addresses := `["` + test.AccountA.String() + `", "` + test.AccountB.String() + `"]`
+	tooMany := make([]string, maxAccountsBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = `"` + test.AccountA.String() + `"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"not json", `addresses`, errInvalidAccountsBatch},
+		{"no addresses", `{"addresses": []}`, errNoAddresses},
+		{"too many addresses", `{"addresses": [` + strings.Join(tooMany, ",") + `]}`, "cannot look up more than"},
+		{"multiple account rewind", `{"addresses": ` + addresses + `, "round": 5}`, errMultiAcctRewind},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, body := callAccountsBatch(t, si, tc.body)
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Contains(t, body, tc.message)
+		})
+	}
+}
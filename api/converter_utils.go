@@ -112,6 +112,19 @@ func decodeBase64Byte(str *string, field string, errorArr []string) ([]byte, []s
 	return nil, errorArr
 }
 
+// decodeGroupID decodes a group id path parameter. Standard base64 may contain
+// '/', so base64url is accepted as well.
+func decodeGroupID(str string) ([]byte, error) {
+	data, err := base64.URLEncoding.DecodeString(str)
+	if err != nil {
+		data, err = base64.StdEncoding.DecodeString(str)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: '%s'", errUnableToParseBase64, "group-id")
+	}
+	return data, nil
+}
+
 // decodeSigType validates the input string and dereferences it if present, or appends an error to errorArr
 func decodeSigType(str *string, errorArr []string) (idb.SigType, []string) {
 	if str != nil {
@@ -578,6 +591,8 @@ func transactionParamsToTransactionFilter(params generated.SearchForTransactions
 
 	// Byte array
 	filter.NotePrefix, errorArr = decodeBase64Byte(params.NotePrefix, "note-prefix", errorArr)
+	filter.GroupID, errorArr = decodeBase64Byte(params.GroupId, "group-id", errorArr)
+	filter.Lease, errorArr = decodeBase64Byte(params.Lease, "lease", errorArr)
 
 	// Time
 	if params.AfterTime != nil {
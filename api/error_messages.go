@@ -25,17 +25,31 @@ const (
 	errNoAccountsFound                 = "no accounts found for address"
 	errNoAssetsFound                   = "no assets found for asset-id"
 	errNoTransactionFound              = "no transaction found for transaction id"
+	errNoTransactionGroupFound         = "no transactions found for group-id"
 	errMultipleTransactions            = "multiple transactions found for this txid, please contact us, this shouldn't happen"
 	errMultipleAccounts                = "multiple accounts found for this address, please contact us, this shouldn't happen"
 	errMultipleAssets                  = "multiple assets found for this id, please contact us, this shouldn't happen"
 	errMultipleApplications            = "multiple applications found for this id, please contact us, this shouldn't happen"
 	errMultiAcctRewind                 = "multiple accounts rewind is not supported by this server"
 	errRewindingAccount                = "error while rewinding account"
+	errRewindingAssetBalances          = "error while rewinding asset balances"
 	errLookingUpBlockForRound          = "error while looking up block for round"
 	errTransactionSearch               = "error while searching for transaction"
+	errTransactionsPruned              = "transactions before round %d have been pruned"
 	errZeroAddressCloseRemainderToRole = "searching transactions by zero address with close address role is not supported"
 	errZeroAddressAssetSenderRole      = "searching transactions by zero address with asset sender role is not supported"
 	errZeroAddressAssetCloseToRole     = "searching transactions by zero address with asset close address role is not supported"
+	errStreamRoundOrNext               = "cannot specify round or next when streaming transactions"
+	errStreamLastEventID               = "unable to parse Last-Event-ID"
+	errStreamTooOld                    = "cannot stream from more than %d rounds back, search for the older transactions instead"
+	errNoWebhookFound                  = "no webhook found for webhook-id"
+	errInvalidWebhook                  = "unable to parse webhook"
+	errInvalidWebhookURL               = "webhook url must be an http or https url"
+	errInvalidWebhookReplay            = "unable to parse min-round"
+	errWebhookFutureRound              = "min-round must not be after the next round of the webhook"
+	errInvalidAccountsBatch            = "unable to parse accounts batch request"
+	errNoAddresses                     = "no addresses given"
+	errTooManyAddresses                = "cannot look up more than %d addresses at once"
 )
 
 var errUnknownAddressRole string
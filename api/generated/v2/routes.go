@@ -21,6 +21,9 @@ type ServerInterface interface {
 	// (GET /v2/accounts)
 	SearchForAccounts(ctx echo.Context, params SearchForAccountsParams) error
 
+	// (POST /v2/accounts/batch)
+	LookupAccountsBatch(ctx echo.Context) error
+
 	// (GET /v2/accounts/{account-id})
 	LookupAccountByID(ctx echo.Context, accountId string, params LookupAccountByIDParams) error
 
@@ -54,8 +57,14 @@ type ServerInterface interface {
 	// (GET /v2/transactions)
 	SearchForTransactions(ctx echo.Context, params SearchForTransactionsParams) error
 
+	// (GET /v2/transactions/groups/{group-id})
+	LookupTransactionGroup(ctx echo.Context, groupId string) error
+
 	// (GET /v2/transactions/{txid})
 	LookupTransaction(ctx echo.Context, txid string) error
+
+	// (GET /v2/transactions/{txid}/tree)
+	LookupTransactionTree(ctx echo.Context, txid string, params LookupTransactionTreeParams) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -185,6 +194,27 @@ func (w *ServerInterfaceWrapper) SearchForAccounts(ctx echo.Context) error {
 	return err
 }
 
+// LookupAccountsBatch converts echo context to params.
+func (w *ServerInterfaceWrapper) LookupAccountsBatch(ctx echo.Context) error {
+
+	validQueryParams := map[string]bool{
+		"pretty": true,
+	}
+
+	// Check for unknown query parameters.
+	for name, _ := range ctx.QueryParams() {
+		if _, ok := validQueryParams[name]; !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unknown parameter detected: %s", name))
+		}
+	}
+
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.LookupAccountsBatch(ctx)
+	return err
+}
+
 // LookupAccountByID converts echo context to params.
 func (w *ServerInterfaceWrapper) LookupAccountByID(ctx echo.Context) error {
 
@@ -257,6 +287,8 @@ func (w *ServerInterfaceWrapper) LookupAccountTransactions(ctx echo.Context) err
 		"currency-greater-than": true,
 		"currency-less-than":    true,
 		"rekey-to":              true,
+		"group-id":              true,
+		"lease":                 true,
 	}
 
 	// Check for unknown query parameters.
@@ -427,6 +459,26 @@ func (w *ServerInterfaceWrapper) LookupAccountTransactions(ctx echo.Context) err
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter rekey-to: %s", err))
 	}
 
+	// ------------- Optional query parameter "group-id" -------------
+	if paramValue := ctx.QueryParam("group-id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "group-id", ctx.QueryParams(), &params.GroupId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group-id: %s", err))
+	}
+
+	// ------------- Optional query parameter "lease" -------------
+	if paramValue := ctx.QueryParam("lease"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "lease", ctx.QueryParams(), &params.Lease)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lease: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
 	err = w.Handler.LookupAccountTransactions(ctx, accountId, params)
 	return err
@@ -895,6 +947,8 @@ func (w *ServerInterfaceWrapper) LookupAssetTransactions(ctx echo.Context) error
 		"address-role":          true,
 		"exclude-close-to":      true,
 		"rekey-to":              true,
+		"group-id":              true,
+		"lease":                 true,
 	}
 
 	// Check for unknown query parameters.
@@ -1085,6 +1139,26 @@ func (w *ServerInterfaceWrapper) LookupAssetTransactions(ctx echo.Context) error
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter rekey-to: %s", err))
 	}
 
+	// ------------- Optional query parameter "group-id" -------------
+	if paramValue := ctx.QueryParam("group-id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "group-id", ctx.QueryParams(), &params.GroupId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group-id: %s", err))
+	}
+
+	// ------------- Optional query parameter "lease" -------------
+	if paramValue := ctx.QueryParam("lease"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "lease", ctx.QueryParams(), &params.Lease)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lease: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
 	err = w.Handler.LookupAssetTransactions(ctx, assetId, params)
 	return err
@@ -1142,6 +1216,8 @@ func (w *ServerInterfaceWrapper) SearchForTransactions(ctx echo.Context) error {
 		"exclude-close-to":      true,
 		"rekey-to":              true,
 		"application-id":        true,
+		"group-id":              true,
+		"lease":                 true,
 	}
 
 	// Check for unknown query parameters.
@@ -1345,11 +1421,59 @@ func (w *ServerInterfaceWrapper) SearchForTransactions(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter application-id: %s", err))
 	}
 
+	// ------------- Optional query parameter "group-id" -------------
+	if paramValue := ctx.QueryParam("group-id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "group-id", ctx.QueryParams(), &params.GroupId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group-id: %s", err))
+	}
+
+	// ------------- Optional query parameter "lease" -------------
+	if paramValue := ctx.QueryParam("lease"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "lease", ctx.QueryParams(), &params.Lease)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lease: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
 	err = w.Handler.SearchForTransactions(ctx, params)
 	return err
 }
 
+// LookupTransactionGroup converts echo context to params.
+func (w *ServerInterfaceWrapper) LookupTransactionGroup(ctx echo.Context) error {
+
+	validQueryParams := map[string]bool{
+		"pretty": true,
+	}
+
+	// Check for unknown query parameters.
+	for name, _ := range ctx.QueryParams() {
+		if _, ok := validQueryParams[name]; !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unknown parameter detected: %s", name))
+		}
+	}
+
+	var err error
+	// ------------- Path parameter "group-id" -------------
+	var groupId string
+
+	err = runtime.BindStyledParameter("simple", false, "group-id", ctx.Param("group-id"), &groupId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group-id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.LookupTransactionGroup(ctx, groupId)
+	return err
+}
+
 // LookupTransaction converts echo context to params.
 func (w *ServerInterfaceWrapper) LookupTransaction(ctx echo.Context) error {
 
@@ -1378,6 +1502,58 @@ func (w *ServerInterfaceWrapper) LookupTransaction(ctx echo.Context) error {
 	return err
 }
 
+// LookupTransactionTree converts echo context to params.
+func (w *ServerInterfaceWrapper) LookupTransactionTree(ctx echo.Context) error {
+
+	validQueryParams := map[string]bool{
+		"pretty":   true,
+		"tx-type":  true,
+		"asset-id": true,
+	}
+
+	// Check for unknown query parameters.
+	for name, _ := range ctx.QueryParams() {
+		if _, ok := validQueryParams[name]; !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unknown parameter detected: %s", name))
+		}
+	}
+
+	var err error
+	// ------------- Path parameter "txid" -------------
+	var txid string
+
+	err = runtime.BindStyledParameter("simple", false, "txid", ctx.Param("txid"), &txid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter txid: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params LookupTransactionTreeParams
+	// ------------- Optional query parameter "tx-type" -------------
+	if paramValue := ctx.QueryParam("tx-type"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "tx-type", ctx.QueryParams(), &params.TxType)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tx-type: %s", err))
+	}
+
+	// ------------- Optional query parameter "asset-id" -------------
+	if paramValue := ctx.QueryParam("asset-id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "asset-id", ctx.QueryParams(), &params.AssetId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter asset-id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.LookupTransactionTree(ctx, txid, params)
+	return err
+}
+
 // RegisterHandlers adds each server route to the EchoRouter.
 func RegisterHandlers(router interface {
 	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
@@ -1396,6 +1572,7 @@ func RegisterHandlers(router interface {
 	}
 
 	router.GET("/v2/accounts", wrapper.SearchForAccounts, m...)
+	router.POST("/v2/accounts/batch", wrapper.LookupAccountsBatch, m...)
 	router.GET("/v2/accounts/:account-id", wrapper.LookupAccountByID, m...)
 	router.GET("/v2/accounts/:account-id/transactions", wrapper.LookupAccountTransactions, m...)
 	router.GET("/v2/applications", wrapper.SearchForApplications, m...)
@@ -1407,187 +1584,203 @@ func RegisterHandlers(router interface {
 	router.GET("/v2/assets/:asset-id/transactions", wrapper.LookupAssetTransactions, m...)
 	router.GET("/v2/blocks/:round-number", wrapper.LookupBlock, m...)
 	router.GET("/v2/transactions", wrapper.SearchForTransactions, m...)
+	router.GET("/v2/transactions/groups/:group-id", wrapper.LookupTransactionGroup, m...)
 	router.GET("/v2/transactions/:txid", wrapper.LookupTransaction, m...)
+	router.GET("/v2/transactions/:txid/tree", wrapper.LookupTransactionTree, m...)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
-
-	"H4sIAAAAAAAC/+y9fW/cNtYo/lWI+T1Ak/2N7LRpF7cBFg/SZIMGm3SDOO0CN+7Fw5E4M6wlUiUp29Pc",
-	"fPcLnkNSlETNaOyxk7TzV+IRXw55Dg/POz/MclnVUjBh9OzJh1lNFa2YYQr+onkuG2EyXti/CqZzxWvD",
-	"pZg98d+INoqL1Ww+4/bXmpr1bD4TtGJtG9t/PlPs94YrVsyeGNWw+Uzna1ZRO7DZ1La1G+njx/mMFoVi",
-	"Wg9n/bcoN4SLvGwKRoyiQtPcftLkips1MWuuietMuCBSMCKXxKw7jcmSs7LQJx7o3xumNhHUbvJxEOez",
-	"64yWK6moKLKlVBU1syezp67fx52f3QyZkiUbrvGZrBZcML8iFhYUkEOMJAVbQqM1NcRCZ9fpGxpJNKMq",
-	"X5OlVDuWiUDEa2WiqWZP3s80EwVTgLmc8Uv471Ix9gfLDFUrZma/zlO4WxqmMsOrxNJeOswpppvSaAJt",
-	"YY0rfskEsb1OyOtGG7JghAry9sUz8vjx4+8JbqNhhSO40VW1s8drClgoqGH+8xSkvn3xDOY/cwuc2orW",
-	"dclzatedPD5P2+/k5fOxxXQHSRAkF4atmMKN15qlz+pT+2XLNL7jrgkas84s2Ywj1p14TXIplnzVKFZY",
-	"amw0w7OpayYKLlbkgm1GURimubsTuGBLqdhEKsXGByXTeP5PSqd5oxQT+SZbKUbh6KypGG7JW7cVei2b",
-	"siBregnrphXcAa4vsX0Rz5e0bOwW8VzJp+VKakLdDhZsSZvSED8xaURpeZYdzdEh4ZrUSl7yghVzy8av",
-	"1jxfk5xqHALakStelnb7G82KsW1Or24HmYdOFq4b7Qcs6PPdjHZdO3aCXcNByPJSapYZueOu8tcPFQWJ",
-	"b5f24tL73Vzk3ZoRmNx+wFsb9k5Ygi7LDTGA14JQTSjx99Sc8CXZyIZcAXJKfgH93WrsrlXEbhogp3Op",
-	"WslkbPsGm5HYvIWUJaMCNs9JKRktyy38siwJN6zSTqixrBEmKAIrnZOClQwW2V4H8Ks2Sm5g8ZrZdrI2",
-	"rMhkYxxRrGVpB9RzwAgOi5+jy6eUOS21oYaNCkTxSnYsuuQVN8PlvqbXvGoqIppqwZRFuOetRhLFTKME",
-	"IFsxkgPOFiD1cNudlqSmK6YJs6yXozQH89ijIaQhitF8PU73CNMOUq/odaZkI4oJQoshUsWXgq5Zzpec",
-	"FSSMMgZLO80ueLjYD55WlIrA8YOMghNm2QGOYNcJtNrjab8AgiKsnpCfHXeCr0ZeMBGYGFls4FOt2CWX",
-	"jQ6dRmCEqberC0IaltWKLfn1EMgztx2WQ2Abx0Ird3/nUhjKBSssdwWgpWHIbUZhiibcV0hZUM3+/u3Y",
-	"Dd1+VeyCbZJMt08AuJygFa3tF+y7fRVhhh2HeiIdLmWf/rbS3iS6g0YZso3ELWy/OqaS1kA7/SfooPHc",
-	"qP9kt9JFcQx/vY1tRW+muxN7NV9lOOLglPDVO3sXL3kJ9/Rv9nB4zDba3ktd3PqbW/OVoKZR7Mm5+Jv9",
-	"i2TkzFBRUFXYXyr86XVTGn7GV/anEn96JVc8P+OrsU3xsCZ1U+hW4T92vLQuaq7DclNT+M+pGWpqG16w",
-	"jWJ2Dpov4Z/rJRASXao/Zqjljc2cUsReSXnR1PFO5h3DxGJDXj4foxIYchsjBKahayk0A3J9ihLEW/eb",
-	"/cnyOiaAlUdCwOlvWoKQ245dK1kzZTiLDUH2v/+l2HL2ZPb/nbaGo1Pspk/dhK1eYcbuMDy51DjehTzL",
-	"cTOUAqq6MXinp9hCOMfvA2z9OVu0yMVvLDe4QV0wHrCqNpuHFmAHuz7cbsH/QbjbY98cyFQpurnjfcRb",
-	"PYPbeTjyz1YGtSy9pisuYOFzcrVmglT0wrIDKqRZM0UsLpg2/n5HvodXfrBgOSHBSdons9SJSeBU3xqp",
-	"LdZeydVBcLvDrnN+/p7WNS+uz89/7cjZXBTsOo2GO8VxKVdZQQ2dToydPXtuuybo8vMlnb7N7FAEdFji",
-	"2QML98tOD7VdBz5s+ib0e2SoiVNxe6aqNTM/0JKKnB0Cyws31GQMv+aCAxA/ooHjiGaP5rCVh0DxIQ6w",
-	"HWfngYVG9yszwpSH2CR9qF3ag8H5/TrSfMDlrSn+h1LmFzfC5TZUwag7Zv6nUlIdgIq8kNdb9XxWMa3p",
-	"iqVNZ/FO+oZTts4DDGhndglgYPiR0dKsn63ZHWxmNPaOLX3XqtQH2Ng7PVaR9r9r/dGqdkht3WH3PAnR",
-	"NPpz373Phyl1tnw6L+/gtM/Rp+NY74fkj96KFJuJEmEDLsSHC7QlWjWWGkKdFxytu+fiXDxnSy7AWfPk",
-	"XFg+dLqgmuf6tNFMOUnxZCXJE+KGtFrluZjN+xfhmKkVHJ0OmrpZlDwnF2yTwgJ6YNN6ebmSVis30tAy",
-	"ckVFflnnAGhNSkOSwwkySxmyMZmLZ8gUu6KqSICug/sBRkYH8bZZ58SNjV4SFy/hxk8fA1rXOgNHXgae",
-	"vDGzRNkzSmj0/hGLMqKNVN4HwrWHBvD7kzTOr0CvCNIXaTTT5H8qWr/nwvxKsvPm0aPHjDyt61d2zDML",
-	"x/84n4A9T5saPat7myD8YCmJBxYO+MzYtVE0A09hcvmG0Rqwv2ZENxU4ncuSQLeOoaZWcqVo5ZyOYQF+",
-	"P8YRgHBMu8uiFcLizrCXj+JJLwE+AQqhDVmz0nnTboGvSI+6Mbp26GJb4obOz99DSJDHTAghWFEutL8V",
-	"NF8JewhctMWCkdxKAaw4IS+XBLjavNPdxfw5jhlYB9cYIEHe2TWCb4zkVEDgRF1AIAEXhIpN3yivmTHe",
-	"BfKWXbDNu8i1tqeLxvnh6Y4rsWjscOFabDFMrqgmlQT3TM6EKTfOtZ8gzTQwDRcGfYw5hk9kln7HmAac",
-	"miiCwx6cmIW4MfqEGAU00Lomq1IuHKcJJPok0KjvM85U3lgA9AEYSlJx8tuw5ezVVCU2Ag/iyBbcYKF2",
-	"vFsdw63LuzHJLbnSEDbCqLsjaHxEbkB5LqZlCMp/1gykMqkgtqNLUtof6RTRB5f1fFZTZXjO62mmVhz9",
-	"TaePHWTX1Z68zOWyf2cPrtTkFYKNswXV6eub2S+WAhuN8U52jZ7R+ZlQWoYVnBDwT7ujuighBCqEZyKO",
-	"qYLYLL9sDFccAy19LpgSrUzlwejuSCy8ran2YVoQzeZZxCQxZ4R439kNAAK25yai3lhu5Xbekl3Ssf0f",
-	"d42/FIXlHUx3Q9aC49tfK/3jPw8RJhiG7h3k3ivuXeH2X0vtTVkSviSNuBDyygrH+zi75zMr+TVpJEkB",
-	"kp89cyvcDmzsyccB/JWO0Gah+vdyWXLBSEZ42AMDe4CBhzLnGH3Xnk83B7OKwd+IpUE7wOQRUsQdgV1L",
-	"WeLA5CcZn1ix2gdIwTjwGOrHBmYT/c0mmJ1CsIZTOXaqBkOO0h6teRsOg2gc6nPBRf2mz9ySWlunFcEm",
-	"C6eFRJdYinAtw8qt2i90A8GnRuayPBmoa5qVDPh/1uG3mVXNkpIeAzI8890iVY484EsreD2MGLxiK64N",
-	"U06NBwhDRFEbMLUxzEJGjWHKTvR/Hvz3k/dPs/9Nsz8eZd///6e/fvj248O/DX785uM//vF/uz89/viP",
-	"h//9Xymt8lIalsElmF3ScsTvaxu90CCgv4D7MsmUOltFMDqYj5g3YNoLtskKXjZpbLt5//XcTvtT0Gl1",
-	"s7hgG7h6GM3XZEFNvoa7qTO9bbNl6pLuXPArXPArerD1TqMl29ROrKQ0vTm+EKrq8ZNthylBgCniGGJt",
-	"dEu3sBfQR5+zEq3J41krYGmwDNPQk22WnMFhKvzY24SyCIpxzosjJdfS9bSPrwLCMiA+mpsoGFwPVjRV",
-	"iAYLI3LTaBqrs7kR7lxYjlcXC8xulLTE7D7eYnnD4acu71BxNIC9fXRBVCoHBAYHxw22g7gi89QwpNJI",
-	"xbyJDU9LJI5gxoSI1zY8Rm3M/jTE+AvcpRDIJghRvWnujADZMLnArT1Fi2SpZAUnb6gbRcTJR6T+Dgm2",
-	"V05vVpcDOaQXyzwhN2enlZ7R8l9s84ttC1i1vTHbgoupR6ZVgqAn4cLIA6DmdvbGFOW7EXdSPsaGjZE9",
-	"ZMuh0afjP9jzBJRyldZpyhXIHXLVBp7H5LBgVidg1yxvTJtz0LNZBLPK/UqTfftMOlY4cg1h6uZ2+QE2",
-	"yo21A3VvAp+8S8zRulbykpaZM6iP8XglLx2Ph+be/n7P4lj6mL3759NXbxz4YLplVKGLZeuqoF39xazK",
-	"yiVSjbBYn5hnNWpv5+zf/86gznXHCH8F+Vw9fdNKWo64kEG3Dpbo9Dqj/NLL5Xua2J0vCJe4xSfE6uAS",
-	"am156BHqeoHoJeWlN6J5aNOXCi6u9cPtfa/EA9zamxQ5BbOD3hSD050+HTs4UTzDlsStCtMHNZEuQSvo",
-	"uaDcgkUOCLSiG0s36MocsiTRVJk9dJkueZ42s4qFtiQh0ENoGxNoPKIm2xHtXZweq+HRWLaZnhCb1gMy",
-	"miO5mT7CbmzvFtKFMDSC/94wwgsmjP2k4Cz2jqc9jT75+MYqUMKPgEnK96gEwYT7qD8umfZWiwuj3EQJ",
-	"snrNcFKHNbeegLvb6D92qDHNB4DYrvzEzt4BuM+DndFTUfBSU9Hxi+0RMxLPOJAytsR7uMPnWEUjuPOZ",
-	"3wA7u2treEXLJV2PZHqMXbVPx69ZO/4eF2x7nwJg8U2KeeC01DIxTCOuqDA+m9ztluutGRqFba8rqbSB",
-	"8gPJKKi9NMU4S/1W+qHOlkr+wdL20aWlg6vh9NHE2Ds9+GQ9r8cZRvS9gJlxQtlFjCHP/7YgBfvArYHq",
-	"SwfBJdKWlvG0H6NrlMGMqSjRR9KNrBq5xIDXRP57UMa9d4kKZC7PoFhNRztMs6g45O4Ux29ZlIN5aMOh",
-	"VwuaX6Q1BQvT0zZqpeMHM5L4zqGWQxdfJyQKgAltXVmEmqmKm+6V1x7Um0r9Xxo7ynlFy7T4X8Duv+sI",
-	"lAVfcSxL0WgWFVVwA5FacmGQigqu65JuMC6o3ZqXS/JoHvE3h42CX3LNFyWDFl9jiwXVIJi1ZjrfxS6P",
-	"CbPW0PybCc3XjSgUK8za1fvQkgTNDKxcwR29YOaKMUEeQbuvvycPwBGv+SV7aHfRiduzJ19/D4Uo8I9H",
-	"qQvNFbDZxn4L4L+e/afpGCIRcAwrKrhR0/wYS5CNc/otpwm7TjlL0NJdDrvPUkUFXbF0eFu1AybsC9gE",
-	"j11vX0SBJXNAsCTcpOdnhlr+lK2pXqdlIQSD5LKquKnsATKSaFlZemqT+nFSPxzW30FeH+DyHyHqoSZp",
-	"G+b92tMwPz61aohN+YlWrLutc0I10Y2FubUNOoZ4Qlxdi4JIUW4i6y3sjZ0LRBUrWIONfUlqxYUB60Bj",
-	"ltn/IvmaKppb9ncyBm62+Pu3Q5B/gOIfhIlc2vnFfoDf+74rppm6TG+9GiF7L3S5vuSBkCKrLEcpHjou",
-	"3z2VSQOqNLRMR/l6jt4P8t4+9FTJy46SjZJb0yE3GnHqWxGe2DLgLUkxrGcvetx7ZfdOmY1KkwdtLIZ+",
-	"fvvKSRmVVKxr5F74wPuOvKKYUZxdQsBxGkl2zFviQpWTsHAb6D9tiEOrAQSxzJ/llCKAyXPD7bA/x8se",
-	"MydIeXHBWM3F6nRh+6CojqP2hfQVE0xzPX6BrtaWcuxne+VF1h8YmixYKcVK3z+le8BHfOgrBjzp5fNd",
-	"UA8G9uW5Mmg6vjG2nZ3ijS/nhUPb9p/iRgqRqjvTMt+6tuOBpfYaw9SEZy6RACOcut5mXO8VBZ8AEwWK",
-	"dcD+1pSLkWhTxoqRGDkGM55JZTjG2TD2CSLeDK+YNrSq09csGMnxJMKptoCGLlYb0SyXotBEc5Ezwmqp",
-	"17vyH0fydq4FTFZyjVdOXGgrlworHoFMYWQvN21q5PzWLLwujJmS0owBCsJHnD4ppSG0MWsmTIhMZVB7",
-	"sr8SjK0HjQMvFGRZ5LXl8b5WFC3LzZxw8xWOA7FvcB9XTF2UjBjFGLlaS81IyeglawuNwmhfafLumhca",
-	"yoiW7JrncqVoveY5kapg6oS8cJ500IKwk5vv0QlxWUUusvbdtYDlFZKhihSvE5fpA6SD3yZe8Rwv0P7P",
-	"UJ1Ts/KS6RPy7koiELrNxNRWCOn0WDQGMxIKvlwyOKewHFCeoF/7IYIJSqZC4dYwrFvTJzht1yID+XhE",
-	"iTRoqbgWz7ARcWH8XWdY72hUqLF6gipZsWJqjiZV2HZesTbz1spuUpnWYLNkGN1uORsXRsmiyRnme551",
-	"6DECiw9ACjUgo2gGoCFfsbaF0xtbPE+1CjkIuI9QzBKyu0LAHbtkiiwYE9FAD5DpRHBpQxWEgUBUiFsq",
-	"Kx6mmXNTrxQt2DQfLjDBn7FHyFP0I1zK/Qb4xbbvi00d2aRz46dv6SiW3N4yMS9P8bJR0evtWNrHCyzE",
-	"q1iJkfdQwxXazgeC1ZKxTHORtn4uGQPeTvOc1Zac4xr9jFlGhUIssApIFPR3q8WwMPySYU7AFmEgy2mZ",
-	"NyXGvm656a9yWqquy6hkSyMtgcWlm1uTILdzLSD2Fouf4nzKMsCoB1RIuGRq41qg9uRrjdrDoXpxDsPc",
-	"m6xklyyt0zCKKTg/yitSUbEJuLBTtGDM8bzAUQmQo6wCTnTE9s9OsYvAx8PkqG47kBYVI5tbxHiumeKy",
-	"4Dnh4jfmTnNgS55isGixFIaLBmo9K9bCjfcEgWyifsbQkALUWE60/dANnBfsqoPtIpLnumHm2tALhmD7",
-	"vCd3NU7FqWKaF82IKVPRvAvZfsToDu9batipCqjVB6LLHocKh3zboevTco9setga7tIon+ow3ynMioac",
-	"FuIYdSLy1hVb8C1HdB9ppLc4+WTjMPYlU7ob0xnZANn1jrFti874WIJCSbQv7D9L5kN29Oh8G2THLc15",
-	"4QuzBaE/czEjiR0cqc8RANBX3OTrbCSNxbbFFhaGt31NazglihBwCtlyyXIzBQbIh8Da3aNQ4GcLxXNG",
-	"C0hga1NbMKmlD8qDnySxQ+tIrhGagxTaijUwysM9ivAFCtlF/L/IibR/KeF/4CKdcAy8IONwnzZ7YhtH",
-	"PG22JCUbpmFXQoRudEZqqWmZ9vD4SQtW0s22KaFBd9Ig2HonF9451N5h9kLBiOB0qHU0tTtn2ya3TfoL",
-	"DsdzeCri2sB9TP7zkpYjGTdvWa2YtgIjoeTdP5++cr68sbybfDRNjBqXA2ooGU3b/jgHhSfNIjA0Dr67",
-	"Ny2SdsyxcDiMhrOfB71vFmQwVt4o2lAfXTkE6F8++J/UlDtHdZt0NNxZl4g2TA2ckkDQIri/CJfeBYOk",
-	"VhIXvRpGQ5A1fMZyGMQXfx4CP1obrFhkIbY1Vf19PnO1veKCRjsD2rnOKr5SwHTSo47XJIuscYkEQbzs",
-	"Eu+QOMYyfhv29r2z8B7ELXitKuVnTuFoUI8ygSjNq7pEJ6sbyt6vcS+yVxJdG/d292GUh47QuvMYK3Zj",
-	"B9/hQ6tuCsvudPPtYVT/Fs9kVZds/D6o0T2Oz/HgzQkFDqKHV7ypReZ5o1obXD9Q6hdacnwRQEORAyFl",
-	"DVUNasOF/Q/ko8nG4P8ZVfY/WHKn+z+kqqj2gR1qBnjhYuaK58jG+HDzmb2yC1QYXN9UbYQb5rROMh4P",
-	"75oER9wa6N654wEzJZq82+B9eyrhywq+xDkCBAGBYA3t/9KkYIapysqua3lFqiZfQ1g8XTEfJQ8RKGA4",
-	"7U3UGd0H03WzPZzzUdc0x4EwQKmkasUUcTFDxJWjDYFHFeW9p1b6YQGgytLU/bsrdn/4xBBIS1EEfyJF",
-	"wINxwTanKAzA7zdgHOOJACOAQTrAHYJ0q6yCODFlB71edOQorJ/VyeUJ4B9QnrLwubO2pzw1TLmZujxY",
-	"BxyHRrPhOqc7m+K9TbCKdm1TlYHh5o7L8GYxRYZPF8Kx3UGJwA3xxakSWtR9qQC4TjeGmzeJ9W6V1f4b",
-	"dsCUNNQDdI/M5bKqpABjUVn2PHWiIBC7pOHVOUGYuGSlrFmyNWwSiRAH+TiKrZqSooeKC8FUp9OU4GPN",
-	"V4IV5lpgVMMZ/PnuWqTaxtc1tI62I1WFM3pB4WblaXvl1jAIHF8EvemIbZh2O6J/jPbmI77AWNIwIgy1",
-	"ZOo2Y75zY0yofLgSCvMPMZia+9AiELQQw72HpXy4ka+I6IOmgxeW/d7Q0nmZBfh030HgcH7BBBY7DG+x",
-	"GkmY0I1yTl0LK4xnQXHDyPiS1m2Tm5Y9zLaVElNg8A62dBdKBkHw2NWKD4VFjtxeSs2252KVbckNyiE5",
-	"yDX0yZ9gpdpa1c4ObolQVayYmPQf+7QgAc7335IhhBUZ22dM0qlh0cN2Ylgigzx4+fwhgfo3Y5VIonfK",
-	"di87LpE4DSKMTxzA0k8F3AeKJWNjjsRe7AVZspHLaVcZp+VlW8EJWvWNvzuhnBhM9iPVUJLJNXdO7880",
-	"gqwDpHukbDhUnLq8d5mf+WylZJMOOFphOn0vFBKEexCcMAxGr+l3X39z+s13fycFXzFtTsh/IN8HL99h",
-	"gbguNglvC8916lsSACzky6L442IdojnXDqGDmBbuYh5gmPvH8E2qS8xnIJdk5joVl/VyILOQ2gWIQKpn",
-	"xG86BvdDRGNxYRRF5pvJ5TKZ/vxv+L01BynPkxUbYn0CV8ZnAG8oFfwL3xD8OJ/tqKdWXoZSajdjPCUb",
-	"qx5aXieOz+NvsvYEnZBXtjdhYimV1ZarxlgZAJ499vbKjpQK+TKmraQMqTLiD6YkGAMEkVb379+BPNps",
-	"iO+gOcjz2gUpWRhCnnOIJH9wBtLMHIF8iLrm8KiRRhiO4o/dxl+iXaztxWOB/s+alwkqqKX9rmM45kRI",
-	"gm8ExC0xGq/N+0KYXax1h5Du95jHtR6KtK3LUkKBdXPaEkmtpSFfU9EWPd9dUGdIk/s8d9jl/f1jfsjC",
-	"P1vg/LSVf4QcCUwRrryhVVAgAytYxe4X4JpuKibMDTnfG+yNMS/4Nvt2DUCNaAC+964SymMvJtux7ceQ",
-	"ARxULbB/IreN1jgf0Xvad/ZdufhWdsUTZEWEZQNxk1Goqbd/OpUu2NEv2IYobxqI67C2zwXvqWXhtWh4",
-	"KkPpHa9Yq5egIJcSgfikKxHVy7Rei0HzyLK/2rKc9pHlrVShR6jCP668jSYCFvYg27PQp/uE8NAatqlZ",
-	"NwSgUyG6G/MKOv4JeR5ikcFfglF5bYAy2p/6XhXM6A0J1lx5OxVV3m4Mjpfz8/c1RkQkDq5rgLKMbTOU",
-	"alwTmi9X4Z2JhOHGN7teMtW2SxlPfMul+qNtOLTb+GbDJ0o6nGd+iNeZ02fIoTmDCRLxbbOu4tiR5cJh",
-	"aKllhxFya3lSF7UDjpfoYtvXQhjbprFIQfvDM1qW764FzpQIImnfL065DbHir8vHCEzSclLnOfSGI3dA",
-	"YycHzXMrZRVtvGcE51ea9OtKYRTosLJU5xLfk0kmXpEJ5EbVanTdYDMaSoI8J1Stmgrt8ne/vh0rGK2m",
-	"yguXCjYsCeqkJjzpjWIFkcolgfCly/AZq2kzsc4fvr4Db7630lkbgjpC6XOrf7DaVVyQIsuDU5vA8/qQ",
-	"x36OzuDz2Ql5iQHjitECeabihqUqznXWD9mrV6wswaSPFJ0F7Eb1RE/sKepU9NNA2YrBIzuJGpNfag1D",
-	"WutmBGNjXAkFmy6SPgGGntmZ3EgBSTkVQpovCE971jDsPTMWhXDUdShmWDLhX7tD0ReGHTGTSsX4Smx7",
-	"GmhJ/UWg++hKXgddLuUS1WLE68EtESTimzFRcH7gYPgCCC0yKcpNirvGSYk99hr2Yuv7QCFNUbdhP9qt",
-	"MqqIM22Jns28iVYIhA1a85vDru8GJSdvXWeyN0CHa+zq24lt2vkAfHfoXZJZ5GjcKplheZbSLhz5k2KZ",
-	"vz89xxIFVm5p2lCpc/GU/MGUdPpiGMoeiNY87dL3XWbtSaJTKLOkB936U+5ZxgoXv0U6HC2Fd37+/poO",
-	"pAyA6Rbyxc2qGu7E8YuRMkIxjr23ytUNumV9MJxxy8aOvX55fv5+SYuiV1ElDp9CJhMqguBuu3pKQCz0",
-	"aqR00VZsLrdic8v4nfSLK6/wbXmhyCuImOhy5Xcce6RCSsfDI9uKc8Oppxz+4L+fRBpe6b0tcfhZt5DH",
-	"lkqXtAKd7GkoYuyAkwG+E+JYiPN1+9+VN6WUS8/NvHvMO3B7T0Ths+ekovVB62juZB4RxONufzbq9G+T",
-	"mtzF7MeL6jXAAG10Qf8hqtu9eOdHT2MQvvZTWWhczKV9/FKxCvKwWhUzgRxXBC6IhW11PgykgLiHOLxb",
-	"RzPEe03ISzsyLa/oRntTaUtY48P5XcWqLwkzXZyoifbd9N6oHBxjb1nOaw7veXa5YKDxcQPjyHuqaKi0",
-	"TAczyPhlMFq4+G7allXsOr+878sViKPRBT1320zLrrUAB/bGYNvmmR/bryigNLrPJrxFlii3GbZ0B89z",
-	"3smtzM5ZCvflcdgLmRxOM87dRP/hoxG3iLCNLNJeU3XRuQOp7r5liIkMnVE7IkaUfnCDh8ycM+FN+9YU",
-	"hFMH0/4vTKED8y0VhazIi0YgFTz45e2Lh+6Nc09kvnSBJT4HyWf8xtly+MZZ4qUvuyWHet3sovhEr5uV",
-	"g9fNbr7S6e+aedoae9XMB+6j+2jFtVEJE/H91/raxma8K3A7n3Fei30ZjeuGnMbNdDNBCuWokbfhTaju",
-	"1LsibyWOdF5KpYZc2XtauwqdrVjSDX9sa+WKEMUYWdx3hkd2xxt5xMRJJDAJlPhLPLCp3cOtngtHT3Tj",
-	"G1RY47eMxIRlIwrd28L2XY0tvsKtUoITEnybrW7Hsetz6p15FjsVu5CA084lPoQHYvtP50DdVaywCo/0",
-	"4vuw/aJJ7VbWSl7yIvWiRSlXPNdoq9jXu/nK9/04n1VNafgNx3nt+6K7NX1jcnAonhkqCqoKwopvvvvu",
-	"6+/b5X5m7Gq4SclQFLcsZ46jhuddiS+sbgIT86g8Wckhyxr1SqlVa6QPXqg5VIpuI732cyYBIOn1Rov1",
-	"wQyLDaERqUsr4JaGtz/N7W9rqtct6+y+1E8FJY5f9SPUIMfl0zydFB2K7FZBBL3jMcY42kPyOZyN3sti",
-	"PJ/MEl9HnGRYDNstEQ2Ull584h/sdV0yK9u1PHB4bnK1qY089ajBK9/PecaHD4TE46V3HRpAdU9pJREs",
-	"B2CFyVbiAlW6heoGkayD/TmL4UoVHVwrpi1E6ciTtTo//zUtbI7lyFvpMt3p4564PevtaXfHcd9GJdz6",
-	"AoG437O8gwbuH6Thnn+E4OYlSGO5FIbmIDdiuenZU2damrnqxrO1MbV+cnp6dXV14u1OJ7msTleQoJEZ",
-	"2eTrUz8QvnEUpz27Lq4uoOXC5cbwXJOnb16CzMRNyfD5e3YN9q1AWbNvTh5htjwTtOazJ7PHJ49OvsYd",
-	"WwMRnGJlitmTDx/ns9PLb07joJJV8skqRlW+RkXAtT2BzG+G2s3LIjR6IdVTP5xzEOCjvU/ejz3PY4+s",
-	"/fv3hqnNzNeMjw0mrdtqeDx25/SiQq8xWNE0CrOkFSO5F+Iinyw+KscumSAcxb6SVzw8FaGsUutu7QTM",
-	"0HZPgNsCUnTFInhPyM+aRVUa5QWkS6C46YOvfZHB0GkEMDtECq6W5IcJrbhrTtSFWDgqvI16BQlC4F4Q",
-	"UZDlSacCmjNquicjXIGKfEMaUVr5whvqwb+mw9KgOB7Wbsip2wGXmeQjPPU4BvwkmYMwsxDuiRFXRxx0",
-	"I7hMXEwq2IOc6uRofB6KbcQe9nn73Jgzac9JKF/Rs8XOnYfcvyY8fKQX/e9jC3bhshkty9QyI6/Mfhgu",
-	"3SMznyl67RS3wq2PjYscn+5lGVgvVLS0CL9gmzFg2hTS8ZO1M+Jt++cx8D1P8/7m9p0QLAwI5YJrpmBI",
-	"kYPJWwNleisZ8mUf8lBwTRcllM5HFbjjLx8lvlDNdA8MxEU9xpl/P1Jgywy/wnMXUF4JrrBvHj3y97Qz",
-	"a0Wjnf6mUQBrBxyPsNwnZSIlKPpacVvTUUOZX/RMIF6v8H6q6saMe2+vTQa3wnDkn7WLB6vpigsX8wDG",
-	"oopegE1IYPKMCznyp9NnH9urJtjL3eXkKGaCzaa9/7sb8GtSrupC/gBCDx7aBX57KzyO1tUar2/VW4dv",
-	"OAXst44AMWwS63J9nM+++9KXYImarqz0NtMg381+/diTGk8/+Jg/XnwcFSFfSXnR1MEWGb8RMZAksa07",
-	"Vz9sgElslSSDhdPzXGApVuCNOEoAchbvkVEN20sumsqBD8gx/5zyyJ2w7T2Y9R0y5zRDPPLD2bePvj2y",
-	"9M+HpZfAaHew9NP+OyBT+Hvf3bmFwcevcuxi9Efl/jDKfVQNxM6y5NeOzfkImFz2qsEJqBXM3RPNSSjA",
-	"kw6D7a0PoU9kTB0KXz8kJ/aJXfGkB0hGS20bX73b1HYfSogX/83ulqfApvX0BlHApxsGEyekAmq+Illw",
-	"uNlfKvwJjLhnfGV/KvEncB+h8Ty1ds1X44vX0K3Cf+x4kxbpTnK0kK7nbLFxNUbSuEhra5+lPOWnpIZI",
-	"FRXsb6euuCuRPzZ9aHAQELC6eR8Ger0DBt9gX0X8Tuyv/ZVFa8L3nwyv2Al57RgNFeTti2fk8ePH37vn",
-	"TK3wiuQytmAcElOpY+ACwyioCZ+nsJ+3L54BAGfBJzGp1U6kBoo61MphxM9v4X9hW/Ff0oj6KfVtXLXT",
-	"Mp1ahrUltosnoQLFPSqlfxH73/CpwNsXkxp5dcPfc50Jj3bFP5USGhmuJnmk4/bjTuluq+2O6UN7KP6q",
-	"Hsajun4jdf3AltHeeZrm1OqWCT46tnrpy7d2bv2pPUPRPp1+6DLK3R6ibt32pOGwbZL2DqXE0T673imS",
-	"Hh0yh2I7ezKb+3PM3NIdc/RlfCFi5IAJnfoSlhM5EbHtJ7CjV3KlPw1LOopah/GMfGLr91/UFA2pVsGm",
-	"M6hbhRF5Ln+uTR1LOkSwaGVbdOJuAvPu7K4crxFX8+K6V4ERS/iPpBLepYheylXm2f+++sQruXpO04WF",
-	"vwTJH1n1LSSHbXdWKGK10+gBLbcF4eNQOywdRzvE8XLc47Z6AX5vdHv7Gmr+SKGLK1QU2c6lXbNDz25H",
-	"H10t7TnNDjBfI7gZm89+22++g/hjD3wxBYY0jcvb5kd7Ubg1PA8+hkH/iY1dgOTTD/547jZwudpduwOg",
-	"bcPp2mRcX+ho2rpT05Z2L9tM4oX3GGcMUx7ZzdEy93lb5voc89Q9RrzTIoeid6+4/dVaAkOJn0XfylH9",
-	"ZEfd6KgbHU43+gRxrMewuz972N3B5LzDCkAxv56kGL7mggPz/RH53VFHDM9kt7fRUUv8K8k8+2RVdTwi",
-	"cY3rrarjMbHqmFh1TKw6JlYdE6vu2Zt9TIE6pkAddbE/dwrUlIgVX0iVi7iMcMzy3ZOiY6R+x0Esg0U9",
-	"k9WCC9ZqM34Fbb0oIy2ioFH8nqpvCO+V+CiFHevKlCxH7lf/Zmeo+jyf+ZdJqbKS8pT7trMaDyDUvI7m",
-	"j5//2mtt8DQFGOyITz1DWhZ2n8tyQwwcqQJexQvFr+dWQN7IhlzBYSn5BfSHp9wxn63Ch/m6ZbrgVY9m",
-	"1LntumfhIZNdNsC7dyAd8/WO+XrHfL2/gGkDXivXpx/wfXM0IOx0gkOnMevFD/bjLosFHkacLp2BHAN0",
-	"v9bSbacIF3dMLfiCKX6S6S4K1txeDCmEbB7tdUd73dFed7TXHe11x0JIRyvg0Qp4tAIerYBHK+DRCnh3",
-	"VsBPabn70t4FONoGj7bBo6Vkz+SYziuKH6xOtDs9hlj1sRw86Z4yFMZUNyVHxill06sRfkEsJNquvQ7r",
-	"9MN5zCQ5spfPxRALL8OrS3/Wu8/usWta1SWDF/egVIPrHx7sy2VVwUUVfnEjR784Vvbx14//LwAA///+",
-	"OqaUiR0BAA==",
+	"H4sIAAAAAAAC/+19a4/bRpboXyH6LjD2rNjt2JPFxsBg4djjiTFOxrCdLLBxLpYSSxLTFKlhkf2Ir//7",
+	"PY96kawiKbW63U70yW6xHqfqnDp13vXxZFFutmUhilqePP14sk2qZCNqUdFfyWJRNkUdZyn+lQq5qLJt",
+	"nZXFyVP9LZJ1lRWrk9lJhr9uk3oN/y9gENsG+89OKvGvJqsEDFVXjZidyMVabBIcuL7eYms10qdPs5Mk",
+	"TSshZX/Wfxb5dZQVi7xJRVRXSSGTBX6S0WVWr6N6nclIdYZmESwsKpfwc6txtMxEnspTDfS/GlFdO1Cr",
+	"ycMgzk6u4iRflTBkGi/LapPU8PGZ6vdp9LOaIa7KXPTX+LzczDMAXK1ImAUZ5ER1GaViSY3WSR0hdLhO",
+	"3RA+S5FUi3UEs48sk4Fw1yqKZnPy9OcTKYpUVIS5hcgu6L/LSojfRFwn1UrUJ7/MfLhbAoRxnW08S3ul",
+	"MAcTN3kNqFrSamCNK5igiLDXafR9I+toDusuorcvn0dPnjz5JuJtrEWqCC64Kju7uyaDhTSphf48BakA",
+	"AM3/Ti1waqtku82zRYLr9h6fZ/Z79OpFaDHtQTwEmRW1WAFmaOOlFP6z+gy/DEyjO45N0NTrGMkmjFh1",
+	"4mW0KItltmrgvCM1NlLw2ZRbICrYouhcXAdRaKa5vRM4F/CrmEil3PigZOrO/1npdNFUlSgW1/GqEgkd",
+	"nXVS9LfkrdoKuS6bPI3WyQWtO9nQHaD6RtiX8XyR5A1uUbaoymcABhx1tYPAtxIYKtITR02RI8/C0RQd",
+	"RjDAtiovslSkM2Tjl+sMeNkikTwEtQP2mOe4/UBbaWib/asbIXPTCeHaaz9oQfd3M+y6RnZCXNFBiBd5",
+	"KYEay5G7Sl8/QHKRe7vYi0vudnNF72GBNDl+4Fub9q5Ags5BFKgJrzAd/B7pewq2aRldl010ScjJs3Pq",
+	"r1aDu7aJcNMIOa1LFSWT0Pb1NsOzefMSlgv7ipu3qspm62XHr8vyvNm2xZf5NcKVVRF1A14dAsMMuytz",
+	"nAPF/MdfQpzBflXSFbTJB/g8kFtWi41UwhiydNqY1FwBM0B0Lgg59hqjXwHM8pqQBliAX8ottIrLplbE",
+	"vC5zHBC+ICXxsPzZuTTzcpHksgbsBwU5dyUjyIL/SLEjpqhPaG4e8LZwlGebrO6D+31ylW2aTQQC3BwI",
+	"Gs6VvsKAtitRN1VBZwpO0IKOxpyEywy7JzkchZWQkcAbLmOhmeZBDlSUNQyQwGKC7IVhGuEom+QKWEJT",
+	"pBNkwzoqK/fuBdlhkQETSCMzSggWO80YPFmxGzxWYnXA0YMEwTGzjIBTiCsPWpEL4hdCkIPV0+hHdQnQ",
+	"17o8B8zpu0LRKfwtLrKykaZTAEaaelgrAyIQMYy3zK76QL5T24GMmNuom2qjxCSQCOsEGH+KlxgBDcMx",
+	"Uw/C5Ex4W0epEiCKeu+2LgHwcozyucYv3Hd4FWaGER40kQ5hDR36G6S9SXRHjWJmGx5hB78qpuJX9Fv9",
+	"J6j67tysZsY3Uvl5DC1FhLaiM9PtaRcyW8U8Yu+UZKv3KPIss5zEoV/xcGjMNhKv0TZutYAEQxYJMHDx",
+	"9EPxZ/wrikGKBwCSKsVfNvzT9zBQBpPgTzn/9LpcZQv4KbQpGlavCYC6bfgfHM+v8tdXZrm+KfRn3wzb",
+	"BBvCAakEzpEslvTP1ZIIKVlWv52wMh2aeUjAsju5aNl/gDWGBSwacogREtOQW6BAQeT6jAWet+o3/Al5",
+	"nSiIlTsyy9mvsiRdwo4N3HorqjoTrr0N//tvwPVg0v9zZu1zZ9xNnqkJrfpWh+4wPrlwczHvYp6luBlL",
+	"AZttU/Od7mML5hz/bGDrzmnRUs5/FYuaN6gNxgOx2dbXDxFgBbv8NqkX68NtmfTfmsYcsURYZ/rmKauU",
+	"xSPeECAAWRM5oES7w+6rhSdVBVR8u9gAZayqyiq0TuY9gnkiicqFXnxE9wXMyiIfSnJwG+dwRIC9NNtd",
+	"1/03BKO/eD+1yEORy4Ep5V4jGiWxmIS5/sg/ooaFEgCIgllBC5/BLCD3bZJzvD0SwC8I+JqqtTjI1yRL",
+	"iMaurGRKpf+envgY7K0g1WINLqeD4HbE2vrhw8/QJEuvPnz4paVFZiARXPnRcKs4zstVnCZ1Mp0YW3v2",
+	"Art66PL+kk7Xkn0oAjos8eyAhbu9fQ+1XQc+bHIf+j0yVM+puDlTRdPZt0meFAtxCCzP1VCTMfx9VmQE",
+	"xHdsvjuiWaPZbOUhUHyIA4zjjB5YanS3KgZNeYhNkofapR0YnN6vI80bXN6Y4r/Ny8X5XrgcQhWNOjIz",
+	"qTkHoCIt5HVWPTvZgL6WrITf0urupG44Zes0wIR2pTBCo+9Ektfr52txC5vpjD2ype+tBeYAG3urx8ox",
+	"Fo2t31nViNTWHnbHk+BM874S4r5vYFGmImCraJlwTXCWEGic2VaCbDMzY8dgV1tWFOh1cXpONlt0Nu4H",
+	"AGzUetHFG69mf4zJe4+ue3ONtA7J9Nu3dQp3xG5rwp2Q/EmbiV07sCf8ShnisoKdBWh4AEwlKpqI3Tcf",
+	"ig/FC4yMIG/s0w8F3hxn80RmC3nWSFEp2f50VUZPIzUk2gE+YBhHR3QJ+VJqaxONts0ciA8DsXxY4EgW",
+	"vyUlX5VoR6nLOskdX7MT36LsrNZm3Cc5niBW5zxWcWFxJS6TKvWALo1/kUbmQJuhWVs8JNJxZ2p8/zGA",
+	"8yhjCiyIKbIgZEjKO2YkydEIEaIsknVZaScnRqMyNITfH9DjSUczuYyYvjBiR0b/u0m2PwMgv0Txh+bR",
+	"oyciAn35NY75DuH4X+X0w/MEQE832bpGIz2YT0alhRM+YzigVRJTKIB3+bVItoR9dK40GwreyfOIurVM",
+	"a0CSKzjnKqrALEDvRxgBDMc06cNZIS3uHffS0ZD+JdAnQiG1idYiV+7yG+DL0Xz3RteI9jwQfwmrotBK",
+	"jRkTirVKskLqWwGdiHgIVNQaOuVRboNbIXq1jIirzVrd1fWsOKZhHZnkQLPoPa6RnN/RIikoAG2bUkAW",
+	"kH9SXHe9brC+Wvs436Jb/L3jO9/RB6vigpKRKzFtcDhzLVoMR5eJjDYl+V8XsLr8WoUaeUjTD0wDnzmI",
+	"YMFhaDHSb4hp0KlxIuHw4LgsRI3RJUQnwAqaR6u8nCtOY0j0qaFR3SfMVN4gAPIADMWr6uptGDh7sAOe",
+	"jeCDGNiCPRaK493oGA4ub2+SW2aVpDA2kag7InGPyB6Up2Ls+qD891qQVAZbgC6/NklJfaR9RG9iUmaY",
+	"DlJni2w7zTjOo79p9cFBxq5272UOf3Xu7N6V6r1CuHE898bzAQEK/IIU2EiOG8U1Wl8wz8TSMq3gNKIA",
+	"FHVU5zmFkhq/MuMYQ1KdreKw7xBo/nMhqsLKVBqM9o64wts6kTrcldzamkVMEnMCxIvKGX2ic+NQryu3",
+	"ZjhvLi6S0P6HY19eAWgLjNdsh/6ayBZ9rXSP/8yEkHE6j46A0WEvOtYF/0VqbzA2dRk1xXlRXqJwvEs0",
+	"CywA8N74kVQWJPnhmVvxdnBjTT4K4D9JB20I1T+XyxxDnmP0N6o9IJe8CuAuFxlHMdvzqeYQqBj8OUIa",
+	"xAEmj+AjbgfsLRxxHjgCvvrGJd1dgCxERjwm0WMTs3H+FhMMhSYaS6kco6pBn6PYozWz8W6Mxr4+Z4IK",
+	"OJLBewzIdIb7hhTnCENJNG/yc7MFOcUZnQ7pXz3ymmz3sxsTtgCatbzpMmqvBtpqFXGTudKonAvZdwiR",
+	"+S7QhFHIhhIS6nIBNNRbugTE010Wt+6OGNVMr9Qq6Ei9090ctTR6AKcYhMiHzmVViVUmAUhlkiAITfij",
+	"je68rjHabIuZOBVO9H8f/NfTn5/F/5PEvz2Kv/n3s18+/uXTwz/3fnz86a9//X/tn558+uvD//o3n4Z8",
+	"gcGpdKHHF0keiDrARi8lKRsv6e73MtjWVkWcMZIFTDU0LQaUplne+LGt5v3HC5z2B6Ofy2YO/egaxUju",
+	"aI4hWHTPtqbHNgNT58nogl/zgl8nB1vvNFrCpjhxVZZ1Z44vhKo6LGDoMHkI0EccfawFt3SAvZBu/ULk",
+	"7MsIZzKS1QSZP2gnO3HFVI89JGA6UISZJY80sBYdekjGSl9mF1sxd+b2YjQ2DwRHO4ZRRXZUe7tKyZeZ",
+	"rtNXNcw6vCr0Kyfqvjcskx3nnWkVsr0IDEPHG03l241AENBeWzC0Vt6eWW3TpDlvHvM/5lM3xOk9Eu3Q",
+	"p/DBpjg5WmZWOzlzsncYpurI5EBgAcOZBk0yaoRb14Xd1bn6sBrFjy/18QbL6w8/dXmHCmwk7O1i6mGb",
+	"UY+06C5Rg40Ql2N97qdEoL1cuifZ1TY4sbRw19bnwJbnTEOM5uiKBSKv0hpce5pbI8AgF/PSYrSsyg2d",
+	"vL7pw2VgAaW+RYJWCuvMqkpF9OkF5QlKYR51wokk/4e4/gnbElaxN98WWTH1yFgbB/UEQsa83Buj5mbu",
+	"BB/lqxFHKZ+DdUNkT0UF2Kbbcg/ueALycuU3WeQrEsXhs7lEXHKYCxQFxJVYNLXNGeyYJI2ocrcKVlfS",
+	"8ef6OJ5frnAxLFLTRqmxRlD3xvDJ28QcfKxKOF6x8peFeDw0Ujyemmv32h1rKP5j9v5vz16/UeCTZ0Yk",
+	"FXtQB1dF7bZfzKpQLgkZjEz9Akq0UZJg9/5X/rJMtnxsl5SP3THBoKSliIsZtPWfOqdX+dyWHVlwqiqh",
+	"XL28xAGXr9gaj6811bPDt+3kTS6SLNc2cg2t/1LhxVk3+873ijvAjZ3Fjs8/PuhN0Tvd/tMxwoncGQYS",
+	"rzec/i+jUiVYG9MP2XvI4E4EukmukW44UqHPkqBfjIculgCA34tSzCWSRMEBANg4osYByxGOiHexf6wm",
+	"c8bCZnKCYtMB0pnDu5k65Dm0d/NSRSg1RfYvUNyyFNCNnyo6i53jiadR12jZWwXyuAm5lssdKkE04S7q",
+	"jzIG3GhxZpR9lCDUa/qTKqyp9Rjc3UT/waFCmg8BMaz8uLEcPXBfGNO7piIThGITN3cNCXNn7EkZA+Fc",
+	"6vApVgE7qUJi9sDOeAkyrWgp00kg9S501T4LX7M4/g4XrL1PCTD3JmU7VpLL0jNMU1wmhTHzqN1SvaVg",
+	"Pwn2uizR2r9QFWJ89s7pmqJra7qRfihjaPib8LsMlkgHl/3pnYm5t3/wyXpehzME9D2DmTChjBGjsVPe",
+	"FCRjH7gxUF3pwHg8bQU+TfsuuoIMJqSiOB+jduBk4BIjXuOE55Ayrp3H0IgGfE41/VraoZ9FuRG1Zzy+",
+	"ZVEK5r4NJ7mcJ4tzv6aAMD1z/LCumxvoRXe2FtkWvk4jJ77NtFVljQCGTVa3rzx7UPeV+r80drTINjCF",
+	"d/NT2v33LYEyzVYZl5XC0o62KJIaKNqWGUbYIRWlmdzmyTWH/dmtAYQ8mjn8TWEjzS4ymYEKQS2+4hYY",
+	"skNrM2Y63QWXB8tcS2r+eELzNWwpHD/owhsL22o0M7JymWiTuagvBSzgEbX76pvoAXkIZHYhHuIuKnH7",
+	"5OlX31AhKf7jke9CUz6AIfabEv/V7N9PxxRoxGOMeha4UmuY0w+cJu465SxRS3U5jJ+lTVIkK+GPXt2M",
+	"wMR9CZvkxO7sS5FyZUESLOEm9M8v6gT5U7xO5NovCzEYGP8F69jgAcKKhOUG6ck6X3hSPRyXKWReb+DS",
+	"HymoaRv5bZh3a0/j+ja+VVPo2Q/wub2tM4wgkg3CbG2DiiHCeWMfFVyPGB9nrbe0NzgXiSooWJONfRlt",
+	"AZCarANNvYz/M1qsgf8tkP2dhsCN53Br9kD+lop3RaJYlDh/sRvgd77vQNKiuvBvfRUgey10qb7Rg6Is",
+	"4g1ylPSh4vLtU+k1oGIwoT+IX3P0bg7H8NBTJS8cJQ6SW9Mit8Th1DcivGJgwBuSolnPTvS488runDKb",
+	"yk8eSYMY+vHtayVlbLAEY8vIPdd5NS15pRIwtLigfAI/knDMG+Kiyidh4SbQf96oH6sBGLFMn2WfIsDZ",
+	"zP3twJ/dZYfMCWV5fi7EFiA5m2MfFtV51K6QvhKFkKCXBC/Q1RopBz/jledYf2ho2OW8BIni7ildAx7w",
+	"ocNnhPvVizGoewPr8poxNQ1vDLbDKd7ocpw8NLb/HDeSCUQfzZN/q9qGg1XwGuPMo+cqT4iD/treZl4v",
+	"mv8w/aFIWawj9rdOsiIQTC5EGggbFTTjuxJok0PPhPgMQaBYoV3WyWbrv2bJSM4nkU41Amq6oDYixaIs",
+	"UrgSQLUQkQCmuB5LSA+k5V0VNFmecexaK8t6UVZcsZBkCsxfaKWe7pFK7fXPOlPGGIMZApSEDzc7GuM1",
+	"MbkNzbY68FxQie7uSjh1hjQOvlCYZUXfI4/XtR6xMvgMlIA/8TgUDkr38UZU57nKNr9cY1lx0JYuhK3H",
+	"TqNBt/dXWSqpXGAurrIFOmm2QMpcOPA0eqk86aQFcSc136PTSCUNqsD591cFLS8tBatI7jp5mTr/wfht",
+	"3BXP+ALt/kxFzKXIAXhQPy5LBkLaRGuJQki7bnVTc8JRmi2Xgs4p10FE5Yn62Q8OTFRZnurbm2HVmj7D",
+	"absqYpKPA0pkzZaKq+I5N4pUlk7bGdY5GhvWWDVB5SJdYQECMqnStsN5tYn1KLsBz7EGm6Xg5BXkbHBg",
+	"qzJtFoLTud+16NEBK+uBZGo4O9EMREO6sL+FUxtbNE9FhZwE3EcsZhVle4WEOxBrsJa2KJyBHjDTceAC",
+	"tlRRGAhFhailgsbhZ87NFo5FKqb5cIkJ/sg9TBqyHgEDkncZ4Cds3xWbWrJJ68b339JOqgjeMi4v9/Gy",
+	"oOj1NpTV9ZLfK6hEzok1VIOd2s56gtVSwD5mhd/6CR+Jt4NyKLZIzu5TRvANeQ8JscQqKA9Y362IYWA2",
+	"QAGU8jMgDMRAposm53DwgZv+EtpVbZdRLpZ1iQTmvnBhTYIZzjWncHQuXs7zVcgAnR5UsgZGuVYtWHvS",
+	"tcLxcARjXu0ichjBr9PAtUEXz3flJRqTrg0ucAoLxozPCx0VAznLKuREZ2z/qBQ7B3w+TIrqhoFEVAQ2",
+	"N3XxDPSRlSlcO1nxq1Cn2bAlTTEce1wCkouGnsSA42Dg5nsiomTBbkJgnwKqUMkD/NDOJSnEZQvbqSPP",
+	"tTMv4ESdCwZbpzWqq3EqTuEWytImYMoEVbEN2W7EqA7vW1jgWWVQKw9Elx0OZQ750KHr0nKHbDrY6u9S",
+	"kE+1mO8UZpWYNK9IMWpP5K2qpaJbBnQf+KgtTrqWgBkbtla2YzodGyDWpRkcG1u0xucKMwAk2Rd2nyXW",
+	"ITsyON81s2NLc1r44mRg6i9UzIhnBwPldwwAEoSxxToOZHZhW26BMLztalr9KVmEoFMoQL5b1FNgoBQh",
+	"fnsjCAV/RiheiCSl/FSb7cV5Xl1QHvxQRji0dOSaAuhWVK5YQ6M83KEqqqGQMeL/qZxI+wAk/o9cpBOO",
+	"gRZkFO79Zk9uo4jHJkMnEfxEu2IidJ0zAmSc5H4Pj540Bbivh6akBu1JjWCrnVx852A0CV0oHBHsD7V2",
+	"plbnbGhybNJdsDme/VPh1vbvYvJvcCoDSWhvYR6BDk8cHgM3lS8vlIq2CGZOJrVK8a6TKFiVAV+QA4XH",
+	"zyI4NI6+q6e/vHbMUDgcR8Ph517v/YIMQvXmnA3V0ZV9gP6hg//hWs+Uo9rm4fV3VuVm9rNlpyQQWAR3",
+	"F6EyHmkQ30rcKoT9aIhoTZ+52k2kH2/oAx8s1pjOYxPb6nu9xa3OH0js86XuyXiTwQGoVYxYf9Rwsrhj",
+	"jfPkzPJl53muTTGW8G3Y2ffWwjsQW/CsKqVn9uGoVyDYgyiZbbY5O1nVUHi/ur2infJKbdzb7YdRHjpC",
+	"69ZjrMTeDr7Dh1btC8t4NYnhMKp/Fs+BDwGOgvfBlt3j/Goh35xUv8R5OM1Us1wA4q0Nrhso9RPmX1MY",
+	"tqQaJkUJVyMWLYHpCvwP5aPBlvD/QWPF/3BFrfb/mKqc0iY41AnhJStOVG0sGEiHm5/glZ2ywqD6+kqf",
+	"7JnmPcl43L9rPBxxMNC9dccTZnI2edvgfTyV9GVFX9wcgYgBoWANqf/CWOQaY1YKDHe5jDYNmvhqoLWV",
+	"0FHyFIFChtPORK3RdTBdO9tDOR/lNlnwQByglOPzyVWkYoYiVR/cBB5tkqzzVFo3LIBU2cR3/47F7vef",
+	"CCRpyYng96QIaDDgFj5jYYB+34NxhBMBAoBROsAtgnSjrAI3MWWEXs9bchSXx2vl8hjwDyhPIXzqrO0o",
+	"T/VTbqYuj9ZBxwEjA3vrnO5scvfWwyrs2qYqA/3NDcvw9XyKDO+vc4XdSYngDdG15zxa1F2pALxONYaa",
+	"14v1dtnr7lO/xJQkFZNQb/GiMwE9FSX92PbUYWwlxi5Jepy3iERxIfJyK7ytaZMiB3GUj1OJVQO8kusO",
+	"9EtATwg+RkeWSOurgqMa3tGf768KX1v3uqbWznb4iuw6T9rsVy+8U02Rg8D54fR9R7Rh2nZEDue8yYgv",
+	"OZbUjEhDLfE57f3HfK/GmFDYdFVUnH/IwdSZDi0iQYsx3HkYUocb6YKnOmjaeGHhcIDcxl7mgny67ylw",
+	"eHGOzhP0pZgn69HyX8imUk5dhJXGQ1DUMKV7SUvbZN+qpvFQpcCKDN7Glq5CySgInrui+IDvXNo6hYEs",
+	"G2iPVdQGcoMWlBykGurkT7JSDRatxMGRCKsNaAnTkv5dnxYlwOn+AxlCXHDVvivlTw1zHqYt+iUyogev",
+	"XjyMqCRUqBKJ887o+LLdCqjTIOL4xB4s3VTAXaBYChFyJHZiL9CNFBhjpLLZ8sIWNaNWXePvKJQTg8m+",
+	"w2AyEAdVc+X0vqcRZC0g1SOj/aHc1OWdK1+pl+X9UHA6fScUkoR7Epw4DEauk6+/enz2+Ov/wDwOfPsS",
+	"8w7o9QWVM9Kp/9jGZpTZupKt8rXq7XqdL8vij4p1cOZcK4T2YloyFfNAw9w9hvepLoEJqQXy2qvCWzGq",
+	"K7Og6ZsCRCjV0+E3LYP7IaKxMBYlYeYbl8ulN/35n/S7NQdVmidXoo/1CVyZn/HdUyr4B78BjNn4wyUG",
+	"8wtTXXA/xpOLUHHg/MpzfJ48ju0JOo1eY2/4CPOhtrxpapQBxBWlBrG9siWlUr5MbQulU6pM8ZuoSjIG",
+	"YNTGQvTuwMzZbIrvSBYkz0sVpIQwmDxnE0n+4B1JMzMG8iHrmv2jFsE1nrH4g9v4k7OLW7x4EOj/Xme5",
+	"hwq2JX6XLhwzDDniJ0DclhyNZ/O+GGYVa90ipLs95m6th9Rv60JKSLluji2RZC0Ni3VS2DcNxgvq9Gly",
+	"l/dn27y/e8wPWfhnAM7PW/mnKAOBKYWq+IkKCmVgGavY3QK8Ta4xWWxPzveGe3PMC9X0roY1gCqgAeje",
+	"YxXS0VpTl/6x8aPJADaqFtk/mds6a5wF9B7j3devQVjZlU8QigjLhuImnVBTbf9UKp2xo2PV1kqbBtzS",
+	"xKw27aFl8bWIDnDPlY9ucaOXsCDnE4GySVciq5d+vZaD5pll/2lgOWaYYaqQAargvsM0YbCwA9m+M30o",
+	"NDcOW8PgQzsEoFUAvh3zSjr+afTCxCKTv4Sj8myAMtuful4Vzug1CdZw9yk7FcZ3s92YHC8Yk8QREZ6D",
+	"qxqwLINt+lKNapIslivzjIzHcKObXQHQtp3PeKJbLqvfbMO+3UY3679A1OI81i0EyzvRYhn6xABg/AcB",
+	"wn9huhN6dCfvu4P8Z0ihOaYJPPFtJ23FsSXLmcNgqWXECDlYnlRF7ZDjxbnYdrUQurZpLlJgf3ie5Pn7",
+	"q4Jn8gSR2AflfW5DLoKt8jEMk0ROqjyH2nCkDqjr5MBwHCm137hzef9JRt26Uqr0bq+y1CErDbvkllSr",
+	"4LrJZtSXBLMFHMNVs2G7/O2vb2QFwWqqWapSwfolQZXUxCe9QS8UxoZSEki2VBk+oZo2E+v88eNaILbB",
+	"dhnpzIagBih9hvqH2KqKCyVmSWinNl5VqOQBrX1gZ/CHk1PMGEBJHCBOmWdWsIu+inOt9VP26qWAuz0x",
+	"gQyxwa5TT/QUT1Grop8kyq4EvaHlqTH5pdYwTLayCWAsxJVYsGkj6TNg6DnOpEYySIIp0Tz+5eBpxxqG",
+	"nVcEnRCO7dYUM8wxM47LwLPoS8MGzKQgVIAcNPTy1zLRF4Hsost7HbS5lEpUcxEve7eEkYj3Y6Lk/ODB",
+	"+IGfJI0xl8THXd2kxA57NXsx+PyXSVOUNuxHqlU6FXGmLVGzmTfOComwSWt+c9j17VFy8sZ1JjsDtLjG",
+	"WN9WbJOnMqV7F3aHHpPMHEfjoGTG5VlyXDjzp0rE+v7UHAvDxLByS2NDpT4UzyI0kSl90QyFB8Kap1X6",
+	"vsqsPfV0MmWWZK9bd8ody1jx4gekw2ApPDgGV0lPyiCYbiBf7FfVcBTHLwNlhFwca2+Vqht0w/pgPOPA",
+	"xoYet0WnFHzsVFRxw6eYyZiKILzbqp4SEUtyGShdNIjN5SA2B8ZvpV9caoVv4AEyrSByosul3nHu4Qsp",
+	"DYdH2opz/amnHH7jv59EGlrpvSlx6FkHyGOg0mWyIZ3smSlirIArDXwguDILUb5u/XulTSn5UnMz7R7T",
+	"DtzOC3DP+F7bJNuD1tEcZR4OxGG3vwg6/W1Sk7qY9XhOvQYawEYXdN+Zu9mDlnp0PwbpazeVJXGLudi3",
+	"bSuxoTwsq2J6kKOKwBmx0Fbn40AKintoP/ZiZ3D3GrOwUebKL5NrqU2llrDCw+ld5aovHjOdm6jJ9l3/",
+	"3lQLcoy9haVsM3qut80FDY2HDYyB55LZUEkP41EGGeYTK6OFiu9ObFnFtvNL+75UgbjEuaBnapuTvG0t",
+	"4IG1MRjbPNdj6xUZlDr32YSnBj3lNs2WjvA85Z0cZHbKUrgrj+NezOR4mjB3K7pvgQXcIgU2QqR9n1Tn",
+	"rTswke2nSjmRoTVqS8Rw0g/2eNtPORPe2OfXKJzamPZ/EhU7MN/CMQScvmwKpoIHP719+VC9f6SJTJcu",
+	"QOJTkNzjZ/+W/Wf/PI/f4ZYc6sG/8/QzPfiX9x7823+l05/607QVeuhPB+6z+whf+Ks8JuK7r/U1xGa0",
+	"K3CYzyivxa6MRnVjTqNm2k+QYjnKhuo76fKIT13dqXNF3kgcaT2EjIVR8J6WqkKnFUva4Y+2Vm5hohgd",
+	"i/toeGR7vMAjJkoioUmoxJ/n/Vyp3mXWXNjKEPoZPqrxmztiwrLBwlDtLbTvagz4CgelBCUk6DaDbsfQ",
+	"9Tn1znznOhXbkJDTTiU+mPefu0/nUN1VrrBKb3Dz88/dokl2K9EUlKW+Fy1ytM5KtlXs6t18rftiPibc",
+	"Rtme43yv+7K71X9jZuRQfFcDOWCpCZE+/vrrr76xy71n7Kq/Sd5QFLUsZY4DtC/aEp9Z3QQmplEJXKzP",
+	"soJeqWpljfTGCzWjStE20ms3ZxIB4l+vs1gdzIDhfg6plyjgAj3Yn2b4G4ZGWta5br89XYCQzfyqG6FG",
+	"OS6f5+kk51DENwoi6ByPEOOwh+Q+nI3Oy2JAD1NZ4vcOJ+kXw1ZLZAMl0otO/KO93uYCZTvLA/vnZlFd",
+	"b+vyTKOGr3w9JwDRf7fbGc+/69SAqnuWKIlwOQAUJq3ERaq0hWqPSNbe/rxz4fIVHVzDTAiRP/JkjYEX",
+	"fmEzlCOP0qW/06cdcfuus6ftHed9C0q423MG4m7P8ggN3D1II3v+vhLiB+CBntjmoh3ySsHqC048E75K",
+	"RvAJpLyxEAF/XACnt2dSNqruTy9bzS9jpmJbr33PA21rX4i8tjLiCmbRI+PhoAqXbjsdgp+6A+EKSQZ1",
+	"AN/mjSS5SllrJ8K9Q3B5eBUVv27h3S4O2WIFQ/lgK6HKVepiXlnVW3jQNYIxXjtFxDvx8B58Ex3ti/T9",
+	"gkT7pTmIdry4aM/Rv5I+EQqXpNBg6ThoSMYlqth+8kxZZ09UgfCTdV1v5dOzs8vLy1Ntuj0FaM9WlOME",
+	"mlGzWJ/pgfiZMLdygOqiSmuiIJNfgwwoo2dvXtEWZTWWVTl5hUlQZCI2zPnk8ekjLjghimSbwQ9PTh+d",
+	"fsVMZ00H94yLu8B/od3ZxeMzNy5r5X31TSQVYG5pra10VyE3IPS+Sk2jl2Wln43X733xu9dPfw69cEUo",
+	"gb//1YgKo+/Urjo2R+v57d8w42nx+k1wivcFVs2FBiqMqVd6kBPWwO8yCqz1mzEZ59kmM6+tVGgXUoKv",
+	"B2ZquyPAtgYb1lCw8J5GP0rhFDotzynjiDU2nb+g63SaTgHAcAgfXPbW6OeE864pbZHCSdE7xW6eFeXY",
+	"kYeucOKUT1tFBJVfQL26omq8LK6jpshRRNe+LnJRS7M0qi/JrAKf33E8FSZIWoYxoCeJFYQxQrgjRvRj",
+	"8sizSB5TYd1kUlXWB0XjM1Ovxg1SmdkX+8zr9qYCTMedMVNBJvpB7v471xzCElqwijiPAVjfMh3H5m4Y",
+	"ztU7TfcUvTjFjXCrw0ud2AH1OBOtl4rCIsJB3AwBY7OwwydrNGh0+HMIfM3TtEBjn9rh2ppUcRvYMw2J",
+	"6VJwGiRRpjY0M1/WUUNpJrFiFVVGJCtSK+QkSHymIPAOGHDr4oSZfzfYZmCGX+jFGKpQRlfY40eP9D2t",
+	"LMPOaGe/SpYi7IDhIOVdso58upYutziY0W0qZbNzj/F6yffTZtvU4QCIqzqmW6E/8o9ShVTCnZIVKmyI",
+	"7K2b5JxE2oLzz1TUnj6dOoEfrxrjclKXk6KYCWZPe/+3N8AvV7Uhf0DROw9xgX+5ER6DpenCJeI669AN",
+	"p4D9VhEgRx5zaTto9PWXvgQk6gRthD+fSJLvTn751JEaz8hLRtCX0iM8vi7L82YbSYzHptJfivGqoF6h",
+	"adAEHarcqEoocc3WLmJ9RqsXzCSFnLX/pIHpIi2sD7cSFEHJQ/Hi+hIsQ6rF129pWbyfAN63ZXq9Ey4n",
+	"8Aye4y1P4MPJ+9bCUFAot6pymhJOCeTTExftddWIT7fGEz0AapSp1xd9yNI43jGV886Zqq1KOYQID4WV",
+	"Kh2FdQpUFeaMHSrauuu6/4Zg9Bd/ZLR/BEbLZxqHuorRXbESRazOTzwHLhRr4cvwjQ5D/qjzGLL0U1Cn",
+	"V2xZ06/77tUwY/z2mqS2QdXeeG21EEwyHlogHBHPANnjXjspqlNF4gOKsL9PBfFW5OgdGP0tMnY/4zzy",
+	"TVjCX46s//6x/kGWftZ922wKf++GcA0wePelsTFGf7S2Hsba6lQ4w1mW2ZViczqqd1F2KtwW9P4BRqIF",
+	"oaDoQBpsZwMVx3mE7FPm60fvxDpZ3Z30AAn2vm3LVu+xKMIyyykH7lfcLU2BjY1eM6KALqFg3LZU3gD+",
+	"imITRIS/bPgnckzDJPhTzj9RSAwHBPjWjmEdwcVL6rbhf3C8SYtUJ9lZSDsaCIiT66b5ceE3n91LeUpP",
+	"mZA+ZR8hslNvMvXsT2h60+AgIPCLLV0YkqsRGHSDXS2jt+IQ667MWRO/aYlVXU6B1JnRgE779uXz6MmT",
+	"J9+oJ9pReGVyCS2Yh+TyMC5whmFgURv9eQr7AQgIgHcmzmJSq1GkGoo61MppxPu38D+w8+4P6dX6nPq2",
+	"iupQv7JaxvWyhsUTU1VrN6+lvg1bL7Veq/AWrqcZvg3pezx4I95QHtoBWKrvF5RtqeTi7YB5WEX/D+Lk",
+	"6j8pffOio4HX2bTs0JrwaNP9XSn2jjFwUtiV2z4cedVuNRx9dWg3/B81jOZoAtnLBHJga3PnPE1ztrWf",
+	"kzhGb3TK3NzYsfi7Dn9w9unsY5tRjnvd2u/7eI2xtonf4+YT8bvselTMPzq5DsV2dmQ2d+fsuqGL6+gf",
+	"+kLEyB4TOtOlzidyogjbT2BHWCL987Cko6h1GG/TZ/Yo/EHN+5SSb+xkvfqmHHau6izYEgNeJxMXN7fF",
+	"yW4n+vzW7spwLeFtll51KnXzU0+BkhO3KaIDO4w1+99VnwAe+SLxP0DxJUj+zKpvIDkM3Vmm2Omo0YNa",
+	"DmWa8VAjlo6jHeJ4Oe5wW72kWAIOJdC1dvWRYrehqTw3zKVVs0PPjqMHV5tsxKHna4qsDs2H33ab7yA+",
+	"7gNfTIYhTePy2PxoLzK3hubBxxD037Gxi5AMGqY6nuMGLlXjdTyoHBtO1ybdOpRH09atmrakqvcwiRfe",
+	"Yey25IINx8jto2XuPlvmuhzzbJ7kmIk9apFj0bvzCNLluiSGoooZEoMZ5Kh6sqNudNSNDqcbfYbY4GMo",
+	"4+89lPFgct5hBSCXX09SDL/PioyY73fM7446or5o5/Y2OmqJfySZZ5dMtZZHxH0LZVB1PCarHZPVjslq",
+	"x2S1Y7LaHXuzj2llx7Syoy72+04rmxKxogvuZ4X73ITL8tXT8yFSv+Uglt6inpebOcgmVpvRK7BFEUEc",
+	"TLE4PDSi90jUPawb0rt2OkphZF3AW/PA/arfdjevg8xO9Av2SYWS8pT7trUaDSC9jeLM7z4Tu9PaqCQb",
+	"Gewinc6nCk3jPudAHTUdqZReTzaPpMxQQL4um+iSDkuenVN/cWVyBDf8gHO7FiW9/tYEnduqe2wevBuz",
+	"Ad6+A+mYA3nMgTzmQB5zII/moj3MRfO8XJzLs49ciZ+NMqOBBdQpZBH6Fj+OWYH4MPJ0/kx5F6C7tUAP",
+	"nSJe3DFd4wum+EnmUCcAdrholwmDPdpAjzbQow30aAM92kCPBbuOltWjZfVoWT1aVo+W1aNl9fYsq/fK",
+	"Gnr7lYyO9tajvfVobz3aW7/sJK7Wq/DEleTZR82dPk1JS2jxFHofmLr7n/yhTyFLrUOif8d2Y2arvysm",
+	"a16gR12P/tdUees5+r4512HA08ueHvnVkV8ds8COLPdwBv+zj2jaG8+cjdAKmovus9gjXHRS+qyyLf4u",
+	"ueCBHgkf4C9H9nJkL/eevcBvQozzGNeKhDaNrEY7UyHaPke2PSwwORSHZaNFAaIWmyoydLgJEvr4DU7P",
+	"COgV3OArl+TaYT9TpGv7oE2OnuYUyzoqm3oCn3uPy7sVXvfZfWP3sDTK7cqtSEj+pza7igYrH6JDcvoZ",
+	"TjZt9Ilv8qubHfr6AQDbWRDl1RyviOMVcU+uCPhRiupCs0jQk6H7uq638unZmbhKNttcnMJhOCPjnOr/",
+	"0Xg4ys2G1DHzixrZ+UUZGD798un/Ax1tdvkWPwEA",
 }
 
 // GetSwagger returns the Swagger specification corresponding to the generated code
@@ -86,6 +86,12 @@ type Account struct {
 	Status string `json:"status"`
 }
 
+// AccountError defines model for AccountError.
+type AccountError struct {
+	Address string `json:"address"`
+	Message string `json:"message"`
+}
+
 // AccountParticipation defines model for AccountParticipation.
 type AccountParticipation struct {
 
@@ -113,6 +119,25 @@ type AccountStateDelta struct {
 	Delta StateDelta `json:"delta"`
 }
 
+// AccountsBatchRequest defines model for AccountsBatchRequest.
+type AccountsBatchRequest struct {
+
+	// The addresses to lookup.
+	Addresses []string `json:"addresses"`
+
+	// Include all items including closed accounts, deleted applications, destroyed assets, opted-out asset holdings, and closed-out application localstates.
+	IncludeAll *bool `json:"include-all,omitempty"`
+
+	// Include the application local states and created applications, true by default.
+	IncludeApps *bool `json:"include-apps,omitempty"`
+
+	// Include the asset holdings and created assets, true by default.
+	IncludeAssets *bool `json:"include-assets,omitempty"`
+
+	// Include results for the specified round.
+	Round *uint64 `json:"round,omitempty"`
+}
+
 // Application defines model for Application.
 type Application struct {
 
@@ -811,6 +836,29 @@ type TransactionSignatureMultisigSubsignature struct {
 	Signature *[]byte `json:"signature,omitempty"`
 }
 
+// TransactionTreeNode defines model for TransactionTreeNode.
+type TransactionTreeNode struct {
+
+	// ID of the application which issued the inner transaction.
+	CallerApplicationId *uint64 `json:"caller-application-id,omitempty"`
+
+	// Depth of the transaction in the tree, 0 for the root transaction and the depth of the calling application plus one for an inner transaction.
+	Depth uint64 `json:"depth"`
+
+	// Offset of the transaction in the round, the inner transactions are numbered in preorder after their root transaction.
+	IntraRoundOffset uint64 `json:"intra-round-offset"`
+
+	// Offset in the round of the application call which issued the inner transaction.
+	ParentIntraRoundOffset *uint64 `json:"parent-intra-round-offset,omitempty"`
+
+	// Contains all fields common to all transactions and serves as an envelope to all transactions type. Represents both regular and inner transactions.
+	//
+	// Definition:
+	// data/transactions/signedtxn.go : SignedTxn
+	// data/transactions/transaction.go : Transaction
+	Transaction Transaction `json:"transaction"`
+}
+
 // AccountId defines model for account-id.
 type AccountId string
 
@@ -844,9 +892,15 @@ type CurrencyLessThan uint64
 // ExcludeCloseTo defines model for exclude-close-to.
 type ExcludeCloseTo bool
 
+// GroupId defines model for group-id.
+type GroupId string
+
 // IncludeAll defines model for include-all.
 type IncludeAll bool
 
+// Lease defines model for lease.
+type Lease string
+
 // Limit defines model for limit.
 type Limit uint64
 
@@ -896,6 +950,19 @@ type AccountResponse struct {
 	CurrentRound uint64 `json:"current-round"`
 }
 
+// AccountsBatchResponse defines model for AccountsBatchResponse.
+type AccountsBatchResponse struct {
+
+	// The accounts found, in the order of the request.
+	Accounts []Account `json:"accounts"`
+
+	// Round at which the results were computed.
+	CurrentRound uint64 `json:"current-round"`
+
+	// The addresses without an account or that could not be looked up.
+	Errors *[]AccountError `json:"errors,omitempty"`
+}
+
 // AccountsResponse defines model for AccountsResponse.
 type AccountsResponse struct {
 	Accounts []Account `json:"accounts"`
@@ -1000,6 +1067,16 @@ type TransactionResponse struct {
 	Transaction Transaction `json:"transaction"`
 }
 
+// TransactionTreeResponse defines model for TransactionTreeResponse.
+type TransactionTreeResponse struct {
+
+	// Round at which the results were computed.
+	CurrentRound uint64 `json:"current-round"`
+
+	// The transactions of the tree in preorder, without their inner transactions.
+	Nodes []TransactionTreeNode `json:"nodes"`
+}
+
 // TransactionsResponse defines model for TransactionsResponse.
 type TransactionsResponse struct {
 
@@ -1042,6 +1119,9 @@ type SearchForAccountsParams struct {
 	ApplicationId *uint64 `json:"application-id,omitempty"`
 }
 
+// lookupAccountsBatchJSONBody defines parameters for LookupAccountsBatch.
+type lookupAccountsBatchJSONBody AccountsBatchRequest
+
 // LookupAccountByIDParams defines parameters for LookupAccountByID.
 type LookupAccountByIDParams struct {
 
@@ -1100,6 +1180,12 @@ type LookupAccountTransactionsParams struct {
 
 	// Include results which include the rekey-to field.
 	RekeyTo *bool `json:"rekey-to,omitempty"`
+
+	// Lookup transactions by their group ID.
+	GroupId *string `json:"group-id,omitempty"`
+
+	// Lookup transactions by their lease.
+	Lease *string `json:"lease,omitempty"`
 }
 
 // SearchForApplicationsParams defines parameters for SearchForApplications.
@@ -1255,6 +1341,12 @@ type LookupAssetTransactionsParams struct {
 
 	// Include results which include the rekey-to field.
 	RekeyTo *bool `json:"rekey-to,omitempty"`
+
+	// Lookup transactions by their group ID.
+	GroupId *string `json:"group-id,omitempty"`
+
+	// Lookup transactions by their lease.
+	Lease *string `json:"lease,omitempty"`
 }
 
 // SearchForTransactionsParams defines parameters for SearchForTransactions.
@@ -1317,4 +1409,21 @@ type SearchForTransactionsParams struct {
 
 	// Application ID
 	ApplicationId *uint64 `json:"application-id,omitempty"`
+
+	// Lookup transactions by their group ID.
+	GroupId *string `json:"group-id,omitempty"`
+
+	// Lookup transactions by their lease.
+	Lease *string `json:"lease,omitempty"`
 }
+
+// LookupTransactionTreeParams defines parameters for LookupTransactionTree.
+type LookupTransactionTreeParams struct {
+	TxType *string `json:"tx-type,omitempty"`
+
+	// Asset ID
+	AssetId *uint64 `json:"asset-id,omitempty"`
+}
+
+// LookupAccountsBatchRequestBody defines body for LookupAccountsBatch for application/json ContentType.
+type LookupAccountsBatchJSONRequestBody lookupAccountsBatchJSONBody
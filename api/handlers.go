@@ -37,6 +37,9 @@ type ServerImplementation struct {
 
 	timeout time.Duration
 
+	// rounds wakes up the transaction streams.
+	rounds *RoundNotifier
+
 	log *log.Logger
 }
 
@@ -100,6 +103,56 @@ func validateTransactionFilter(filter *idb.TransactionFilter) error {
 	return nil
 }
 
+// prunedRoundError returns the error message of a query that needs the
+// transactions of `round` and later, "" unless they were pruned.
+func (si *ServerImplementation) prunedRoundError(round uint64) (string, error) {
+	oldest, err := si.db.GetOldestRound()
+	if err != nil {
+		return "", err
+	}
+	if round < oldest {
+		return fmt.Sprintf(errTransactionsPruned, oldest), nil
+	}
+	return "", nil
+}
+
+// rewindPrunedError returns the error message of a rewind to `atRound`, which
+// needs the transactions after it, "" if there is no rewind.
+func (si *ServerImplementation) rewindPrunedError(atRound *uint64) (string, error) {
+	if atRound == nil {
+		return "", nil
+	}
+	return si.prunedRoundError(*atRound + 1)
+}
+
+// filterPrunedError returns the error message of a transaction search whose
+// round filters select pruned rounds. A search without a lower bound returns
+// the transactions that are left.
+func (si *ServerImplementation) filterPrunedError(filter idb.TransactionFilter) (string, error) {
+	switch {
+	case filter.Round != nil:
+		return si.prunedRoundError(*filter.Round)
+	case filter.MinRound != 0:
+		return si.prunedRoundError(filter.MinRound)
+	case filter.MaxRound != 0:
+		return si.prunedRoundError(filter.MaxRound)
+	}
+	return "", nil
+}
+
+// notFoundOrPruned returns the not found error `msg` of a lookup, or the pruned
+// error when transactions were pruned, the lookup may have needed them.
+func (si *ServerImplementation) notFoundOrPruned(ctx echo.Context, msg string) error {
+	pruned, err := si.prunedRoundError(0)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errTransactionSearch, err))
+	}
+	if pruned != "" {
+		return badRequest(ctx, fmt.Sprintf("%s, %s", msg, pruned))
+	}
+	return notFound(ctx, msg)
+}
+
 ////////////////////////////
 // Handler implementation //
 ////////////////////////////
@@ -155,6 +208,14 @@ func (si *ServerImplementation) LookupAccountByID(ctx echo.Context, accountID st
 		IncludeDeleted:       boolOrDefault(params.IncludeAll),
 	}
 
+	msg, err := si.rewindPrunedError(params.Round)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errFailedSearchingAccount, err))
+	}
+	if msg != "" {
+		return badRequest(ctx, fmt.Sprintf("%s: %s", errRewindingAccount, msg))
+	}
+
 	accounts, round, err := si.fetchAccounts(ctx.Request().Context(), options, params.Round)
 	if err != nil {
 		return indexerError(ctx, fmt.Errorf("%s: %w", errFailedSearchingAccount, err))
@@ -213,6 +274,14 @@ func (si *ServerImplementation) SearchForAccounts(ctx echo.Context, params gener
 		options.GreaterThanAddress = addr[:]
 	}
 
+	msg, err := si.rewindPrunedError(params.Round)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errFailedSearchingAccount, err))
+	}
+	if msg != "" {
+		return badRequest(ctx, fmt.Sprintf("%s: %s", errRewindingAccount, msg))
+	}
+
 	accounts, round, err := si.fetchAccounts(ctx.Request().Context(), options, params.Round)
 
 	if err != nil {
@@ -242,7 +311,13 @@ func (si *ServerImplementation) LookupAccountTransactions(ctx echo.Context, acco
 		return badRequest(ctx, errors[0])
 	}
 
-	searchParams := generated.SearchForTransactionsParams{
+	return si.SearchForTransactions(ctx, accountTransactionsParams(accountID, params))
+}
+
+// accountTransactionsParams converts the parameters of an account transactions
+// lookup to transaction search parameters.
+func accountTransactionsParams(accountID string, params generated.LookupAccountTransactionsParams) generated.SearchForTransactionsParams {
+	return generated.SearchForTransactionsParams{
 		Address: strPtr(accountID),
 		// not applicable to this endpoint
 		//AddressRole:         params.AddressRole,
@@ -263,9 +338,9 @@ func (si *ServerImplementation) LookupAccountTransactions(ctx echo.Context, acco
 		CurrencyGreaterThan: params.CurrencyGreaterThan,
 		CurrencyLessThan:    params.CurrencyLessThan,
 		RekeyTo:             params.RekeyTo,
+		GroupId:             params.GroupId,
+		Lease:               params.Lease,
 	}
-
-	return si.SearchForTransactions(ctx, searchParams)
 }
 
 // SearchForApplications returns applications for the provided parameters.
@@ -341,6 +416,14 @@ func (si *ServerImplementation) LookupApplicationLogsByID(ctx echo.Context, appl
 		return badRequest(ctx, err.Error())
 	}
 
+	msg, err := si.filterPrunedError(filter)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errTransactionSearch, err))
+	}
+	if msg != "" {
+		return badRequest(ctx, msg)
+	}
+
 	// Fetch the transactions
 	txns, next, round, err := si.fetchTransactions(ctx.Request().Context(), filter)
 	if err != nil {
@@ -423,7 +506,21 @@ func (si *ServerImplementation) LookupAssetBalances(ctx echo.Context, assetID ui
 		query.PrevAddress = addr[:]
 	}
 
-	balances, round, err := si.fetchAssetBalances(ctx.Request().Context(), query)
+	msg, err := si.rewindPrunedError(params.Round)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errFailedSearchingAssetBalances, err))
+	}
+	if msg != "" {
+		return badRequest(ctx, fmt.Sprintf("%s: %s", errRewindingAssetBalances, msg))
+	}
+
+	var balances []generated.MiniAssetHolding
+	var round uint64
+	if params.Round != nil {
+		balances, round, err = si.fetchAssetBalancesAtRound(ctx.Request().Context(), query, *params.Round)
+	} else {
+		balances, round, err = si.fetchAssetBalances(ctx.Request().Context(), query)
+	}
 	if err != nil {
 		return indexerError(ctx, fmt.Errorf("%s: %w", errFailedSearchingAssetBalances, err))
 	}
@@ -463,6 +560,8 @@ func (si *ServerImplementation) LookupAssetTransactions(ctx echo.Context, assetI
 		AddressRole:         params.AddressRole,
 		ExcludeCloseTo:      params.ExcludeCloseTo,
 		RekeyTo:             params.RekeyTo,
+		GroupId:             params.GroupId,
+		Lease:               params.Lease,
 	}
 
 	return si.SearchForTransactions(ctx, searchParams)
@@ -504,6 +603,15 @@ func (si *ServerImplementation) LookupBlock(ctx echo.Context, roundNumber uint64
 		return indexerError(ctx, fmt.Errorf("%s '%d': %w", errLookingUpBlockForRound, roundNumber, err))
 	}
 
+	// The header of a pruned round is kept, without its transactions.
+	msg, err := si.prunedRoundError(roundNumber)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s '%d': %w", errLookingUpBlockForRound, roundNumber, err))
+	}
+	if msg != "" {
+		return badRequest(ctx, msg)
+	}
+
 	return ctx.JSON(http.StatusOK, generated.BlockResponse(blk))
 }
 
@@ -528,7 +636,7 @@ func (si *ServerImplementation) LookupTransaction(ctx echo.Context, txid string)
 	}
 
 	if len(txns) == 0 {
-		return notFound(ctx, fmt.Sprintf("%s: %s", errNoTransactionFound, txid))
+		return si.notFoundOrPruned(ctx, fmt.Sprintf("%s: %s", errNoTransactionFound, txid))
 	}
 
 	if len(txns) > 1 {
@@ -543,6 +651,33 @@ func (si *ServerImplementation) LookupTransaction(ctx echo.Context, txid string)
 	return ctx.JSON(http.StatusOK, response)
 }
 
+// LookupTransactionGroup returns the transactions of a group in intra order.
+// (GET /v2/transactions/groups/{group-id})
+func (si *ServerImplementation) LookupTransactionGroup(ctx echo.Context, groupID string) error {
+	group, err := decodeGroupID(groupID)
+	if err != nil {
+		return badRequest(ctx, err.Error())
+	}
+
+	// Without an address the transactions are sorted by round and intra.
+	filter := idb.TransactionFilter{GroupID: group}
+	txns, _, round, err := si.fetchTransactions(ctx.Request().Context(), filter)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errTransactionSearch, err))
+	}
+
+	if len(txns) == 0 {
+		return si.notFoundOrPruned(ctx, fmt.Sprintf("%s: %s", errNoTransactionGroupFound, groupID))
+	}
+
+	response := generated.TransactionsResponse{
+		CurrentRound: round,
+		Transactions: txns,
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
 // SearchForTransactions returns transactions matching the provided parameters
 // (GET /v2/transactions)
 func (si *ServerImplementation) SearchForTransactions(ctx echo.Context, params generated.SearchForTransactionsParams) error {
@@ -556,6 +691,14 @@ func (si *ServerImplementation) SearchForTransactions(ctx echo.Context, params g
 		return badRequest(ctx, err.Error())
 	}
 
+	msg, err := si.filterPrunedError(filter)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errTransactionSearch, err))
+	}
+	if msg != "" {
+		return badRequest(ctx, msg)
+	}
+
 	// Fetch the transactions
 	txns, next, round, err := si.fetchTransactions(ctx.Request().Context(), filter)
 	if err != nil {
@@ -718,19 +861,9 @@ func (si *ServerImplementation) fetchAssetBalances(ctx context.Context, options
 				return row.Error
 			}
 
-			addr := basics.Address{}
-			if len(row.Address) != len(addr) {
-				return fmt.Errorf(errInvalidCreatorAddress)
-			}
-			copy(addr[:], row.Address[:])
-
-			bal := generated.MiniAssetHolding{
-				Address:         addr.String(),
-				Amount:          row.Amount,
-				IsFrozen:        row.Frozen,
-				OptedInAtRound:  row.CreatedRound,
-				OptedOutAtRound: row.ClosedRound,
-				Deleted:         row.Deleted,
+			bal, err := miniAssetHolding(row)
+			if err != nil {
+				return err
 			}
 
 			balances = append(balances, bal)
@@ -745,6 +878,103 @@ func (si *ServerImplementation) fetchAssetBalances(ctx context.Context, options
 	return balances, round, nil
 }
 
+// miniAssetHolding converts an asset balance row.
+func miniAssetHolding(row idb.AssetBalanceRow) (generated.MiniAssetHolding, error) {
+	addr := basics.Address{}
+	if len(row.Address) != len(addr) {
+		return generated.MiniAssetHolding{}, fmt.Errorf(errInvalidCreatorAddress)
+	}
+	copy(addr[:], row.Address[:])
+
+	return generated.MiniAssetHolding{
+		Address:         addr.String(),
+		Amount:          row.Amount,
+		IsFrozen:        row.Frozen,
+		OptedInAtRound:  row.CreatedRound,
+		OptedOutAtRound: row.ClosedRound,
+		Deleted:         row.Deleted,
+	}, nil
+}
+
+// fetchAssetBalancesAtRound returns the holdings of an asset at `atRound`. The
+// holdings are rewound with one pass over the asset's transactions after
+// `atRound`, the amount filters apply to the rewound amounts. The frozen state
+// is the current one.
+func (si *ServerImplementation) fetchAssetBalancesAtRound(ctx context.Context, options idb.AssetBalanceQuery, atRound uint64) ([]generated.MiniAssetHolding, uint64 /*round*/, error) {
+	var round uint64
+	balances := make([]generated.MiniAssetHolding, 0)
+	err := callWithTimeout(ctx, si.log, si.timeout, func(ctx context.Context) error {
+		// Holdings closed since atRound are deleted now, and the amounts are
+		// only known after rewinding.
+		query := options
+		query.AmountGT = nil
+		query.AmountLT = nil
+		query.IncludeDeleted = true
+
+		var rewind accounting.AssetRewind
+		for page := 0; ; page++ {
+			assetbalchan, r := si.db.AssetBalances(ctx, query)
+			if page == 0 {
+				round = r
+				if atRound > round {
+					return fmt.Errorf("%s: the requested round %d > the current round %d",
+						errRewindingAssetBalances, atRound, round)
+				}
+				var err error
+				rewind, err = accounting.AssetRewindAt(ctx, si.db, options.AssetID, atRound, round)
+				if err != nil {
+					return fmt.Errorf("%s: %w", errRewindingAssetBalances, err)
+				}
+			} else if r != round {
+				return fmt.Errorf("%s: the current round changed from %d to %d",
+					errRewindingAssetBalances, round, r)
+			}
+
+			var count uint64
+			for row := range assetbalchan {
+				if row.Error != nil {
+					return row.Error
+				}
+				count++
+				query.PrevAddress = row.Address
+
+				amount, held := rewind.HoldingAtRound(row)
+				if !held ||
+					(options.AmountGT != nil && amount <= *options.AmountGT) ||
+					(options.AmountLT != nil && amount >= *options.AmountLT) ||
+					uint64(len(balances)) >= options.Limit {
+					continue
+				}
+
+				bal, err := miniAssetHolding(row)
+				if err != nil {
+					return err
+				}
+				bal.Amount = amount
+				if bal.Deleted != nil {
+					bal.Deleted = boolPtr(false)
+				}
+				if bal.OptedInAtRound != nil && *bal.OptedInAtRound > atRound {
+					bal.OptedInAtRound = nil
+				}
+				if bal.OptedOutAtRound != nil && *bal.OptedOutAtRound > atRound {
+					bal.OptedOutAtRound = nil
+				}
+				balances = append(balances, bal)
+			}
+
+			if uint64(len(balances)) >= options.Limit || count < query.Limit {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return balances, round, nil
+}
+
 // fetchBlock looks up a block and converts it into a generated.Block object
 // the method also loads the transactions into the returned block object.
 func (si *ServerImplementation) fetchBlock(ctx context.Context, round uint64) (generated.Block, error) {
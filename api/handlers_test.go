@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -26,6 +27,7 @@ import (
 	"github.com/algorand/indexer/api/generated/v2"
 	"github.com/algorand/indexer/idb"
 	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
 )
 
 func TestTransactionParamToTransactionFilter(t *testing.T) {
@@ -71,6 +73,15 @@ func TestTransactionParamToTransactionFilter(t *testing.T) {
 			idb.TransactionFilter{NotePrefix: []byte("SomeData"), Limit: defaultTransactionsLimit},
 			nil,
 		},
+		{
+			"Group and lease",
+			generated.SearchForTransactionsParams{
+				GroupId: strPtr(base64.StdEncoding.EncodeToString([]byte("group"))),
+				Lease:   strPtr(base64.StdEncoding.EncodeToString([]byte("lease"))),
+			},
+			idb.TransactionFilter{GroupID: []byte("group"), Lease: []byte("lease"), Limit: defaultTransactionsLimit},
+			nil,
+		},
 		{
 			"Enum fields",
 			generated.SearchForTransactionsParams{TxType: strPtr("pay"), SigType: strPtr("lsig")},
@@ -917,3 +928,195 @@ func TestTimeouts(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupAssetBalancesAtRound(t *testing.T) {
+	pages := map[string][]idb.AssetBalanceRow{
+		"": {
+			{Address: test.AccountA[:], AssetID: 1, Amount: 90, CreatedRound: uint64Ptr(1)},
+			{Address: test.AccountB[:], AssetID: 1, Amount: 10, CreatedRound: uint64Ptr(6)},
+		},
+		string(test.AccountB[:]): {
+			{Address: test.AccountC[:], AssetID: 1, Amount: 7, CreatedRound: uint64Ptr(1)},
+		},
+	}
+	var queries []idb.AssetBalanceQuery
+	db := &mocks.IndexerDb{}
+	db.On("AssetBalances", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, abq idb.AssetBalanceQuery) <-chan idb.AssetBalanceRow {
+			queries = append(queries, abq)
+			rows := pages[string(abq.PrevAddress)]
+			ch := make(chan idb.AssetBalanceRow, len(rows))
+			for _, row := range rows {
+				ch <- row
+			}
+			close(ch)
+			return ch
+		},
+		uint64(8))
+
+	transfer := transactions.SignedTxnWithAD{}
+	transfer.Txn.Type = protocol.AssetTransferTx
+	transfer.Txn.Sender = test.AccountA
+	transfer.Txn.XferAsset = 1
+	transfer.Txn.AssetAmount = 10
+	transfer.Txn.AssetReceiver = test.AccountB
+	txns := make(chan idb.TxnRow, 1)
+	txns <- idb.TxnRow{Round: 6, Txn: &transfer, AssetID: 1}
+	close(txns)
+	var outCh <-chan idb.TxnRow = txns
+	db.On("Transactions", mock.Anything, mock.Anything).Return(outCh, uint64(8))
+	db.On("GetOldestRound").Return(uint64(0), nil)
+
+	si := ServerImplementation{db: db, timeout: time.Second}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	round := uint64(5)
+	err := si.LookupAssetBalances(c, 1, generated.LookupAssetBalancesParams{
+		Round:               &round,
+		CurrencyGreaterThan: uint64Ptr(0),
+		Limit:               uint64Ptr(2),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The amount filter is applied after rewinding, and B did not hold the
+	// asset at the round, so a second page is needed.
+	require.Len(t, queries, 2)
+	for _, query := range queries {
+		assert.Nil(t, query.AmountGT)
+		assert.True(t, query.IncludeDeleted)
+	}
+
+	var res generated.AssetBalancesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, uint64(8), res.CurrentRound)
+	require.Len(t, res.Balances, 2)
+	assert.Equal(t, test.AccountA.String(), res.Balances[0].Address)
+	assert.Equal(t, uint64(100), res.Balances[0].Amount)
+	assert.Equal(t, test.AccountC.String(), res.Balances[1].Address)
+	assert.Equal(t, uint64(7), res.Balances[1].Amount)
+	require.NotNil(t, res.NextToken)
+	assert.Equal(t, test.AccountC.String(), *res.NextToken)
+}
+
+func TestLookupTransactionGroup(t *testing.T) {
+	// Standard base64 of the group contains '/', base64url is used in the path.
+	var group [32]byte
+	for i := range group {
+		group[i] = 0xff
+	}
+	pay0 := test.MakePaymentTxn(
+		1000, 1, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{},
+		basics.Address{})
+	pay0.Txn.Group = group
+	pay1 := test.MakePaymentTxn(
+		1000, 2, 0, 0, 0, 0, test.AccountB, test.AccountA, basics.Address{},
+		basics.Address{})
+	pay1.Txn.Group = group
+
+	var filters []idb.TransactionFilter
+	db := &mocks.IndexerDb{}
+	db.On("GetOldestRound").Return(uint64(0), nil)
+	db.On("Transactions", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, tf idb.TransactionFilter) <-chan idb.TxnRow {
+			filters = append(filters, tf)
+			ch := make(chan idb.TxnRow, 2)
+			if bytes.Equal(tf.GroupID, group[:]) {
+				ch <- idb.TxnRow{Round: 3, Intra: 4, Txn: &pay0}
+				ch <- idb.TxnRow{Round: 3, Intra: 5, Txn: &pay1}
+			}
+			close(ch)
+			return ch
+		},
+		uint64(8))
+	si := ServerImplementation{db: db, timeout: time.Second}
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	err := si.LookupTransactionGroup(c, base64.URLEncoding.EncodeToString(group[:]))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, filters, 1)
+	assert.Equal(t, idb.TransactionFilter{GroupID: group[:]}, filters[0])
+
+	var res generated.TransactionsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, uint64(8), res.CurrentRound)
+	require.Len(t, res.Transactions, 2)
+	assert.Equal(t, pay0.Txn.ID().String(), *res.Transactions[0].Id)
+	assert.Equal(t, pay1.Txn.ID().String(), *res.Transactions[1].Id)
+
+	// Standard base64 is accepted too.
+	rec = httptest.NewRecorder()
+	c = echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	other := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	err = si.LookupTransactionGroup(c, other)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	c = echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	err = si.LookupTransactionGroup(c, "!")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, filters, 2)
+}
+
+func TestPrunedRounds(t *testing.T) {
+	db := &mocks.IndexerDb{}
+	db.On("GetOldestRound").Return(uint64(10), nil)
+	db.On("GetBlock", mock.Anything, mock.Anything, mock.Anything).Return(
+		bookkeeping.BlockHeader{}, nil, nil)
+	ch := make(chan idb.TxnRow)
+	close(ch)
+	var outCh <-chan idb.TxnRow = ch
+	db.On("Transactions", mock.Anything, mock.Anything).Return(outCh, uint64(20))
+	si := ServerImplementation{db: db, timeout: time.Second}
+
+	call := func(handler func(c echo.Context) error) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		require.NoError(t, handler(c))
+		return rec
+	}
+	search := func(params generated.SearchForTransactionsParams) *httptest.ResponseRecorder {
+		return call(func(c echo.Context) error { return si.SearchForTransactions(c, params) })
+	}
+
+	rec := search(generated.SearchForTransactionsParams{MinRound: uint64Ptr(5)})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(errTransactionsPruned, 10))
+	assert.Equal(t, http.StatusBadRequest, search(generated.SearchForTransactionsParams{Round: uint64Ptr(9)}).Code)
+	assert.Equal(t, http.StatusBadRequest, search(generated.SearchForTransactionsParams{MaxRound: uint64Ptr(9)}).Code)
+	assert.Equal(t, http.StatusOK, search(generated.SearchForTransactionsParams{MinRound: uint64Ptr(10)}).Code)
+	assert.Equal(t, http.StatusOK, search(generated.SearchForTransactionsParams{}).Code)
+
+	rec = call(func(c echo.Context) error { return si.LookupBlock(c, 9) })
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// A lookup by id which finds nothing may have needed the pruned rounds.
+	txid := transactions.Txid{}.String()
+	rec = call(func(c echo.Context) error { return si.LookupTransaction(c, txid) })
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(errTransactionsPruned, 10))
+	rec = call(func(c echo.Context) error {
+		return si.LookupTransactionGroup(c, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = call(func(c echo.Context) error {
+		return si.LookupTransactionTree(c, txid, generated.LookupTransactionTreeParams{})
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Rewinding to round 8 needs the transactions of round 9.
+	rec = call(func(c echo.Context) error {
+		return si.LookupAccountByID(c, test.AccountA.String(), generated.LookupAccountByIDParams{Round: uint64Ptr(8)})
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), errRewindingAccount)
+	rec = call(func(c echo.Context) error {
+		return si.LookupAssetBalances(c, 1, generated.LookupAssetBalancesParams{Round: uint64Ptr(8)})
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
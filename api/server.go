@@ -37,6 +37,15 @@ type ExtraOptions struct {
 
 	// ReadTimeout is the maximum duration for reading the entire request, including the body.
 	ReadTimeout time.Duration
+
+	// RoundNotifier is notified by the importer after each committed round.
+	// If nil, the transaction streams poll the database for new rounds.
+	RoundNotifier *RoundNotifier
+
+	// Webhooks turns on the webhook admin API, which requires one of the
+	// AdminTokens.
+	Webhooks    *Webhooks
+	AdminTokens []string
 }
 
 func (e ExtraOptions) handlerTimeout() time.Duration {
@@ -77,19 +86,29 @@ func Serve(ctx context.Context, serveAddr string, db idb.IndexerDb, fetcherError
 		middleware = append(middleware, middlewares.MakeAuth("X-Indexer-API-Token", options.Tokens))
 	}
 
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rounds := options.RoundNotifier
+	if rounds == nil {
+		rounds = makePollingRoundNotifier(ctx, db, defaultRoundPollInterval, log)
+	}
+
 	api := ServerImplementation{
 		EnableAddressSearchRoundRewind: options.DeveloperMode,
 		db:                             db,
 		fetcher:                        fetcherError,
 		timeout:                        options.handlerTimeout(),
+		rounds:                         rounds,
 		log:                            log,
 	}
 
 	generated.RegisterHandlers(e, &api, middleware...)
 	common.RegisterHandlers(e, &api)
-
-	if ctx == nil {
-		ctx = context.Background()
+	registerStreamHandlers(e, &api, middleware...)
+	if options.Webhooks != nil {
+		registerWebhookHandlers(e, options.Webhooks, middlewares.MakeAuth(WebhookAdminTokenHeader, options.AdminTokens))
 	}
 	getctx := func(l net.Listener) context.Context {
 		return ctx
@@ -100,6 +119,7 @@ func Serve(ctx context.Context, serveAddr string, db idb.IndexerDb, fetcherError
 		WriteTimeout:   options.WriteTimeout,
 		MaxHeaderBytes: 1 << 20,
 		BaseContext:    getctx,
+		ConnContext:    withConn,
 	}
 
 	go func() {
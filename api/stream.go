@@ -0,0 +1,332 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+)
+
+// maxStreamCatchUpRounds is how far back a stream may start. Older
+// transactions are read with the search endpoints.
+const maxStreamCatchUpRounds = 1000
+
+// defaultRoundPollInterval is how often the server checks the database for
+// new rounds when no importer notifies it.
+const defaultRoundPollInterval = time.Second
+
+// RoundNotifier wakes up the transaction streams when a round is committed.
+type RoundNotifier struct {
+	mu sync.Mutex
+	ch chan struct{}
+
+	// poll, when set, notifies the streams of new rounds until its context is
+	// canceled. It runs while there are subscribers, started with ctx.
+	poll        func(ctx context.Context)
+	ctx         context.Context
+	subscribers int
+	stopPoll    context.CancelFunc
+}
+
+// MakeRoundNotifier creates a RoundNotifier.
+func MakeRoundNotifier() *RoundNotifier {
+	return &RoundNotifier{ch: make(chan struct{})}
+}
+
+// makePollingRoundNotifier creates a RoundNotifier for servers without an
+// importer, which polls the database for new rounds while streams are open.
+func makePollingRoundNotifier(ctx context.Context, db idb.IndexerDb, interval time.Duration, log *log.Logger) *RoundNotifier {
+	n := MakeRoundNotifier()
+	n.ctx = ctx
+	n.poll = func(ctx context.Context) {
+		pollRounds(ctx, db, n, interval, log)
+	}
+	return n
+}
+
+// Notify wakes up the streams. Call it after a round is committed.
+func (n *RoundNotifier) Notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	close(n.ch)
+	n.ch = make(chan struct{})
+}
+
+// wait returns a channel that is closed by the next Notify().
+func (n *RoundNotifier) wait() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.ch
+}
+
+// subscribe registers a stream until the returned function is called. A
+// polling notifier polls from the first subscriber until the last one leaves.
+func (n *RoundNotifier) subscribe() func() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.poll == nil {
+		return func() {}
+	}
+	n.subscribers++
+	if n.subscribers == 1 {
+		ctx, cancel := context.WithCancel(n.ctx)
+		n.stopPoll = cancel
+		go n.poll(ctx)
+	}
+	return func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		n.subscribers--
+		if n.subscribers == 0 {
+			n.stopPoll()
+			n.stopPoll = nil
+		}
+	}
+}
+
+// pollRounds notifies `n` when the next round of the database changes.
+func pollRounds(ctx context.Context, db idb.IndexerDb, n *RoundNotifier, interval time.Duration, log *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := uint64(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		next, err := db.GetNextRoundToAccount()
+		if err != nil {
+			log.WithError(err).Debug("pollRounds() failed to get next round")
+			continue
+		}
+		if next != last {
+			last = next
+			n.Notify()
+		}
+	}
+}
+
+// connContextKey is the request context key of the connection of a request,
+// set by the server's ConnContext.
+type connContextKey struct{}
+
+// withConn is the http.Server ConnContext function which stores the connection
+// in the request context.
+func withConn(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, c)
+}
+
+// clearWriteDeadline removes the server's WriteTimeout from the connection of
+// a streaming request, the server sets it again for the next request.
+func clearWriteDeadline(r *http.Request) error {
+	c, ok := r.Context().Value(connContextKey{}).(net.Conn)
+	if !ok {
+		return nil
+	}
+	return c.SetWriteDeadline(time.Time{})
+}
+
+// streamWrapper serves the streaming endpoints with the parameter binding of
+// the generated search routes.
+type streamWrapper struct {
+	*ServerImplementation
+}
+
+// SearchForTransactions streams the transactions matching the parameters.
+// (GET /v2/transactions/stream)
+func (w streamWrapper) SearchForTransactions(ctx echo.Context, params generated.SearchForTransactionsParams) error {
+	return w.streamTransactions(ctx, params)
+}
+
+// LookupAccountTransactions streams the transactions of an account.
+// (GET /v2/accounts/{account-id}/transactions/stream)
+func (w streamWrapper) LookupAccountTransactions(ctx echo.Context, accountID string, params generated.LookupAccountTransactionsParams) error {
+	_, errors := decodeAddress(strPtr(accountID), "account-id", make([]string, 0))
+	if len(errors) != 0 {
+		return badRequest(ctx, errors[0])
+	}
+	return w.streamTransactions(ctx, accountTransactionsParams(accountID, params))
+}
+
+// registerStreamHandlers adds the streaming endpoints.
+func registerStreamHandlers(e *echo.Echo, si *ServerImplementation, m ...echo.MiddlewareFunc) {
+	wrapper := generated.ServerInterfaceWrapper{Handler: streamWrapper{si}}
+	e.GET("/v2/transactions/stream", wrapper.SearchForTransactions, m...)
+	e.GET("/v2/accounts/:account-id/transactions/stream", wrapper.LookupAccountTransactions, m...)
+}
+
+// StreamEvent is the data of a server-sent event of a transaction stream. One
+// event is sent per round, with the round as the event id.
+type StreamEvent struct {
+	Round        uint64                  `json:"round"`
+	Transactions []generated.Transaction `json:"transactions"`
+}
+
+// streamStart returns the first round of a stream: the round after the
+// Last-Event-ID of a reconnecting client, min-round, or the next round.
+func streamStart(ctx echo.Context, filter *idb.TransactionFilter, nextRound uint64) (uint64, error) {
+	start := nextRound
+	if filter.MinRound != 0 {
+		start = filter.MinRound
+	}
+	if id := ctx.Request().Header.Get("Last-Event-ID"); id != "" {
+		last, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return 0, errors.New(errStreamLastEventID)
+		}
+		if last+1 > filter.MinRound {
+			start = last + 1
+		}
+	}
+	if start+maxStreamCatchUpRounds < nextRound {
+		return 0, fmt.Errorf(errStreamTooOld, maxStreamCatchUpRounds)
+	}
+	return start, nil
+}
+
+// fetchRoundTransactions returns the transactions of one round matching the
+// filter, in round order.
+func (si *ServerImplementation) fetchRoundTransactions(ctx context.Context, filter idb.TransactionFilter, round uint64) ([]generated.Transaction, error) {
+	filter.Round = &round
+	filter.MinRound = 0
+	filter.MaxRound = 0
+	results := make([]generated.Transaction, 0)
+	seen := make(map[string]bool)
+	for {
+		txns, next, _, err := si.fetchTransactions(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, txn := range txns {
+			// A root transaction is repeated on the next page when its inner
+			// transactions are split between pages.
+			if !seen[*txn.Id] {
+				seen[*txn.Id] = true
+				results = append(results, txn)
+			}
+		}
+		if len(txns) == 0 || next == "" {
+			break
+		}
+		filter.NextToken = next
+	}
+
+	// The transactions of an address are returned newest first.
+	if filter.Address != nil {
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	}
+	return results, nil
+}
+
+func writeStreamEvent(ctx echo.Context, event string, id string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	res := ctx.Response()
+	if id != "" {
+		_, err = fmt.Fprintf(res, "id: %s\n", id)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, b)
+	if err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
+
+// streamTransactions sends the transactions matching the parameters as
+// server-sent events, one "transactions" event per committed round, until
+// max-round or until the client disconnects.
+func (si *ServerImplementation) streamTransactions(ctx echo.Context, params generated.SearchForTransactionsParams) error {
+	filter, err := transactionParamsToTransactionFilter(params)
+	if err != nil {
+		return badRequest(ctx, err.Error())
+	}
+	err = validateTransactionFilter(&filter)
+	if err != nil {
+		return badRequest(ctx, err.Error())
+	}
+	if filter.Round != nil || filter.NextToken != "" {
+		return badRequest(ctx, errStreamRoundOrNext)
+	}
+
+	defer si.rounds.subscribe()()
+
+	// Get the notification channel before the next round, so that a round
+	// committed in between is not missed.
+	wake := si.rounds.wait()
+	nextRound, err := si.db.GetNextRoundToAccount()
+	if err != nil {
+		return indexerError(ctx, err)
+	}
+	round, err := streamStart(ctx, &filter, nextRound)
+	if err != nil {
+		return badRequest(ctx, err.Error())
+	}
+
+	// The stream is open until the client leaves, past the WriteTimeout.
+	err = clearWriteDeadline(ctx.Request())
+	if err != nil {
+		return indexerError(ctx, err)
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	reqCtx := ctx.Request().Context()
+	for filter.MaxRound == 0 || round <= filter.MaxRound {
+		for round < nextRound && (filter.MaxRound == 0 || round <= filter.MaxRound) {
+			txns, err := si.fetchRoundTransactions(reqCtx, filter, round)
+			if err != nil {
+				// The status is already sent.
+				si.log.WithError(err).Warnf("streaming round %d failed", round)
+				return writeStreamEvent(ctx, "error", "", generated.ErrorResponse{
+					Message: fmt.Sprintf("%s: %v", errTransactionSearch, err),
+				})
+			}
+			err = writeStreamEvent(ctx, "transactions", strconv.FormatUint(round, 10), StreamEvent{Round: round, Transactions: txns})
+			if err != nil {
+				// The client is gone.
+				return nil
+			}
+			round++
+		}
+		if filter.MaxRound != 0 && round > filter.MaxRound {
+			break
+		}
+
+		select {
+		case <-reqCtx.Done():
+			return nil
+		case <-wake:
+		}
+		wake = si.rounds.wait()
+		nextRound, err = si.db.GetNextRoundToAccount()
+		if err != nil {
+			si.log.WithError(err).Warn("streaming failed to get the next round")
+			return writeStreamEvent(ctx, "error", "", generated.ErrorResponse{Message: err.Error()})
+		}
+	}
+	return nil
+}
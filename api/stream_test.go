@@ -0,0 +1,214 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+// mockRoundTransactions makes the mock return one payment per round.
+func mockRoundTransactions(db *mocks.IndexerDb) {
+	db.On("Transactions", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, filter idb.TransactionFilter) <-chan idb.TxnRow {
+			ch := make(chan idb.TxnRow, 1)
+			if filter.NextToken == "" {
+				pay := test.MakePaymentTxn(1000, *filter.Round, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+				ch <- idb.TxnRow{Round: *filter.Round, RoundTime: time.Unix(1600000000, 0), Txn: &pay}
+			}
+			close(ch)
+			return ch
+		},
+		uint64(0))
+}
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+func parseEvents(t *testing.T, body string) []sseEvent {
+	var events []sseEvent
+	var cur sseEvent
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			events = append(events, cur)
+			cur = sseEvent{}
+		case strings.HasPrefix(line, "id: "):
+			cur.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			cur.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			cur.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func makeStreamServer(db *mocks.IndexerDb) *ServerImplementation {
+	return &ServerImplementation{
+		db:      db,
+		timeout: time.Second,
+		rounds:  MakeRoundNotifier(),
+		log:     log.New(),
+	}
+}
+
+func TestStreamTransactions(t *testing.T) {
+	db := &mocks.IndexerDb{}
+	mockRoundTransactions(db)
+	db.On("GetNextRoundToAccount").Return(uint64(6), nil).Once()
+	db.On("GetNextRoundToAccount").Return(uint64(8), nil)
+	si := makeStreamServer(db)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/transactions/stream", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	minRound, maxRound := uint64(5), uint64(7)
+
+	done := make(chan error)
+	go func() {
+		done <- si.streamTransactions(c, generated.SearchForTransactionsParams{MinRound: &minRound, MaxRound: &maxRound})
+	}()
+	// Round 7 is committed after the stream sent round 5.
+	var err error
+	for stop := false; !stop; {
+		select {
+		case err = <-done:
+			stop = true
+		case <-time.After(10 * time.Millisecond):
+			si.rounds.Notify()
+		}
+	}
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+	events := parseEvents(t, rec.Body.String())
+	require.Len(t, events, 3)
+	for i, event := range events {
+		round := uint64(5 + i)
+		assert.Equal(t, "transactions", event.event)
+		assert.Equal(t, strconv.FormatUint(round, 10), event.id)
+		var data StreamEvent
+		require.NoError(t, json.Unmarshal([]byte(event.data), &data))
+		assert.Equal(t, round, data.Round)
+		require.Len(t, data.Transactions, 1)
+		require.NotNil(t, data.Transactions[0].ConfirmedRound)
+		assert.Equal(t, round, *data.Transactions[0].ConfirmedRound)
+	}
+}
+
+func TestStreamTransactionsResume(t *testing.T) {
+	db := &mocks.IndexerDb{}
+	mockRoundTransactions(db)
+	db.On("GetNextRoundToAccount").Return(uint64(20), nil)
+	si := makeStreamServer(db)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/transactions/stream", nil)
+	req.Header.Set("Last-Event-ID", "15")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	minRound, maxRound := uint64(10), uint64(17)
+
+	err := si.streamTransactions(c, generated.SearchForTransactionsParams{MinRound: &minRound, MaxRound: &maxRound})
+	require.NoError(t, err)
+
+	var ids []string
+	for _, event := range parseEvents(t, rec.Body.String()) {
+		ids = append(ids, event.id)
+	}
+	assert.Equal(t, []string{"16", "17"}, ids)
+}
+
+func TestPollingRoundNotifier(t *testing.T) {
+	var polls int64
+	db := &mocks.IndexerDb{}
+	db.On("GetNextRoundToAccount").Return(func() uint64 {
+		return uint64(atomic.AddInt64(&polls, 1))
+	}, nil)
+	n := makePollingRoundNotifier(context.Background(), db, time.Millisecond, log.New())
+
+	// Nothing is polled without streams.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&polls))
+
+	unsubscribe1 := n.subscribe()
+	unsubscribe2 := n.subscribe()
+	select {
+	case <-n.wait():
+	case <-time.After(time.Second):
+		t.Fatal("no round notification")
+	}
+	unsubscribe1()
+	wake := n.wait()
+	select {
+	case <-wake:
+	case <-time.After(time.Second):
+		t.Fatal("polling stopped with a subscriber left")
+	}
+
+	// The last stream leaving stops the polling.
+	unsubscribe2()
+	time.Sleep(20 * time.Millisecond)
+	stopped := atomic.LoadInt64(&polls)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt64(&polls))
+}
+
+func TestStreamTransactionsBadRequests(t *testing.T) {
+	db := &mocks.IndexerDb{}
+	db.On("GetNextRoundToAccount").Return(uint64(5000), nil)
+	si := makeStreamServer(db)
+
+	round, old := uint64(3), uint64(10)
+	next := "token"
+	tests := []struct {
+		name        string
+		params      generated.SearchForTransactionsParams
+		lastEventID string
+		message     string
+	}{
+		{"round", generated.SearchForTransactionsParams{Round: &round}, "", errStreamRoundOrNext},
+		{"next", generated.SearchForTransactionsParams{Next: &next}, "", errStreamRoundOrNext},
+		{"too old", generated.SearchForTransactionsParams{MinRound: &old}, "", "cannot stream from more than 1000 rounds back"},
+		{"bad event id", generated.SearchForTransactionsParams{}, "x", errStreamLastEventID},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v2/transactions/stream", nil)
+			if tc.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			require.NoError(t, si.streamTransactions(c, tc.params))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.message)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/labstack/echo/v4"
+
+	"github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+)
+
+// treeFilter selects the inner transactions of a tree, the root transaction is
+// always returned.
+type treeFilter struct {
+	typeEnum idb.TxnTypeEnum
+	assetID  uint64
+}
+
+// LookupTransactionTree returns a transaction and its inner transactions as a
+// call tree.
+// (GET /v2/transactions/{txid}/tree)
+func (si *ServerImplementation) LookupTransactionTree(ctx echo.Context, txid string, params generated.LookupTransactionTreeParams) error {
+	filter, err := transactionParamsToTransactionFilter(generated.SearchForTransactionsParams{
+		Txid: strPtr(txid),
+	})
+	if err != nil {
+		return badRequest(ctx, err.Error())
+	}
+	tf, err := treeFilterFromParams(params)
+	if err != nil {
+		return badRequest(ctx, err.Error())
+	}
+
+	row, round, err := si.fetchRootTxnRow(ctx.Request().Context(), filter)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errTransactionSearch, err))
+	}
+	if row == nil {
+		return si.notFoundOrPruned(ctx, fmt.Sprintf("%s: %s", errNoTransactionFound, txid))
+	}
+
+	nodes, err := transactionTree(*row, tf)
+	if err != nil {
+		return indexerError(ctx, fmt.Errorf("%s: %w", errTransactionSearch, err))
+	}
+	return ctx.JSON(http.StatusOK, generated.TransactionTreeResponse{
+		CurrentRound: round,
+		Nodes:        nodes,
+	})
+}
+
+// treeFilterFromParams parses the tx-type and asset-id query parameters.
+func treeFilterFromParams(params generated.LookupTransactionTreeParams) (treeFilter, error) {
+	var tf treeFilter
+	if params.TxType != nil {
+		errorArr := make([]string, 0)
+		tf.typeEnum, errorArr = decodeType(params.TxType, errorArr)
+		if len(errorArr) > 0 {
+			return treeFilter{}, errors.New(errorArr[0])
+		}
+	}
+	tf.assetID = uintOrDefault(params.AssetId)
+	return tf, nil
+}
+
+// fetchRootTxnRow returns the row of the root transaction selected by
+// `filter`, nil if there is none.
+func (si *ServerImplementation) fetchRootTxnRow(ctx context.Context, filter idb.TransactionFilter) (*idb.TxnRow, uint64 /*round*/, error) {
+	var round uint64
+	var out *idb.TxnRow
+	err := callWithTimeout(ctx, si.log, si.timeout, func(ctx context.Context) error {
+		var txchan <-chan idb.TxnRow
+		txchan, round = si.db.Transactions(ctx, filter)
+		for row := range txchan {
+			if row.Error != nil {
+				return row.Error
+			}
+			if row.Txn != nil && out == nil {
+				row := row
+				out = &row
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, round, nil
+}
+
+// match returns whether an inner transaction is selected by the filter. The
+// asset filter only matches asset transactions.
+func (tf treeFilter) match(stxn *transactions.SignedTxnWithAD, assetID uint64) bool {
+	if tf.typeEnum != 0 {
+		typeEnum, _ := idb.GetTypeEnum(stxn.Txn.Type)
+		if typeEnum != tf.typeEnum {
+			return false
+		}
+	}
+	if tf.assetID != 0 {
+		if stxn.Txn.Type == protocol.ApplicationCallTx || assetID != tf.assetID {
+			return false
+		}
+	}
+	return true
+}
+
+// transactionTree returns the nodes of the call tree of a root transaction row
+// in preorder, the inner transactions not selected by `tf` are left out.
+func transactionTree(row idb.TxnRow, tf treeFilter) ([]generated.TransactionTreeNode, error) {
+	root, err := txnRowToTransaction(row)
+	if err != nil {
+		return nil, err
+	}
+	root.InnerTxns = nil
+	nodes := []generated.TransactionTreeNode{{
+		IntraRoundOffset: uint64(row.Intra),
+		Transaction:      root,
+	}}
+
+	extra := rowData{
+		Round:     row.Round,
+		RoundTime: row.RoundTime.Unix(),
+		Intra:     uint(row.Intra),
+	}
+	intra := uint64(row.Intra)
+	// addInner numbers the inner transactions of `stxn` like
+	// yieldInnerTransactions() does.
+	var addInner func(stxn *transactions.SignedTxnWithAD, appID uint64, parent uint64, depth uint64) error
+	addInner = func(stxn *transactions.SignedTxnWithAD, appID uint64, parent uint64, depth uint64) error {
+		for i := range stxn.ApplyData.EvalDelta.InnerTxns {
+			itxn := &stxn.ApplyData.EvalDelta.InnerTxns[i]
+			intra++
+			offset := intra
+			assetID, err := idb.TransactionAssetID(itxn, 0, nil)
+			if err != nil {
+				return err
+			}
+			if tf.match(itxn, assetID) {
+				inner := *itxn
+				inner.EvalDelta.InnerTxns = nil
+				e := extra
+				e.AssetID = assetID
+				e.AssetCloseAmount = itxn.ApplyData.AssetClosingAmount
+				txn, err := signedTxnWithAdToTransaction(&inner, e)
+				if err != nil {
+					return err
+				}
+				nodes = append(nodes, generated.TransactionTreeNode{
+					Depth:                  depth,
+					IntraRoundOffset:       offset,
+					ParentIntraRoundOffset: uint64Ptr(parent),
+					CallerApplicationId:    uint64Ptr(appID),
+					Transaction:            txn,
+				})
+			}
+			// Only application calls have inner transactions.
+			err = addInner(itxn, assetID, offset, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The asset column of an application call row is its application id.
+	err = addInner(row.Txn, row.AssetID, uint64(row.Intra), 1)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
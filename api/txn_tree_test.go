@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+// treeRow returns the row of an application call of application 10 which pays
+// AccountB, calls application 20 that sends asset 5, and sends asset 6.
+func treeRow() idb.TxnRow {
+	pay := transactions.SignedTxnWithAD{}
+	pay.Txn.Type = protocol.PaymentTx
+	pay.Txn.Receiver = test.AccountB
+	pay.Txn.Amount = basics.MicroAlgos{Raw: 1}
+
+	axfer := func(assetID uint64) transactions.SignedTxnWithAD {
+		stxn := transactions.SignedTxnWithAD{}
+		stxn.Txn.Type = protocol.AssetTransferTx
+		stxn.Txn.XferAsset = basics.AssetIndex(assetID)
+		stxn.Txn.AssetReceiver = test.AccountC
+		return stxn
+	}
+
+	call := transactions.SignedTxnWithAD{}
+	call.Txn.Type = protocol.ApplicationCallTx
+	call.Txn.ApplicationID = 20
+	call.EvalDelta.InnerTxns = []transactions.SignedTxnWithAD{axfer(5)}
+
+	root := transactions.SignedTxnWithAD{}
+	root.Txn.Type = protocol.ApplicationCallTx
+	root.Txn.Sender = test.AccountA
+	root.Txn.ApplicationID = 10
+	root.EvalDelta.InnerTxns = []transactions.SignedTxnWithAD{pay, call, axfer(6)}
+
+	return idb.TxnRow{Round: 7, Intra: 2, RoundTime: time.Unix(100, 0), Txn: &root, AssetID: 10}
+}
+
+func TestTransactionTree(t *testing.T) {
+	nodes, err := transactionTree(treeRow(), treeFilter{})
+	require.NoError(t, err)
+	require.Len(t, nodes, 5)
+
+	type node struct {
+		depth  uint64
+		intra  uint64
+		parent *uint64
+		caller *uint64
+		txType string
+	}
+	expected := []node{
+		{0, 2, nil, nil, "appl"},
+		{1, 3, uint64Ptr(2), uint64Ptr(10), "pay"},
+		{1, 4, uint64Ptr(2), uint64Ptr(10), "appl"},
+		{2, 5, uint64Ptr(4), uint64Ptr(20), "axfer"},
+		{1, 6, uint64Ptr(2), uint64Ptr(10), "axfer"},
+	}
+	for i, n := range nodes {
+		assert.Equal(
+			t, expected[i],
+			node{n.Depth, n.IntraRoundOffset, n.ParentIntraRoundOffset, n.CallerApplicationId, n.Transaction.TxType},
+			"node %d", i)
+		assert.Nil(t, n.Transaction.InnerTxns)
+		assert.Equal(t, uint64(7), *n.Transaction.ConfirmedRound)
+	}
+	require.NotNil(t, nodes[0].Transaction.Id)
+	assert.Nil(t, nodes[1].Transaction.Id)
+
+	nodes, err = transactionTree(treeRow(), treeFilter{assetID: 5})
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, uint64(5), nodes[1].IntraRoundOffset)
+	assert.Equal(t, uint64(20), *nodes[1].CallerApplicationId)
+
+	nodes, err = transactionTree(treeRow(), treeFilter{typeEnum: idb.TypeEnumPay})
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "pay", nodes[1].Transaction.TxType)
+}
+
+func TestLookupTransactionTree(t *testing.T) {
+	row := treeRow()
+	txid := row.Txn.Txn.ID().String()
+	db := &mocks.IndexerDb{}
+	ch := make(chan idb.TxnRow, 1)
+	ch <- row
+	close(ch)
+	var outCh <-chan idb.TxnRow = ch
+	db.On("Transactions", mock.Anything, mock.MatchedBy(func(tf idb.TransactionFilter) bool {
+		return tf.Txid == txid
+	})).Return(outCh, uint64(8))
+	si := ServerImplementation{db: db}
+
+	lookup := func(params generated.LookupTransactionTreeParams) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		require.NoError(t, si.LookupTransactionTree(c, txid, params))
+		return rec
+	}
+
+	rec := lookup(generated.LookupTransactionTreeParams{TxType: strPtr("axfer")})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var res generated.TransactionTreeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, uint64(8), res.CurrentRound)
+	require.Len(t, res.Nodes, 3)
+	assert.Equal(t, txid, *res.Nodes[0].Transaction.Id)
+
+	assert.Equal(t, http.StatusBadRequest, lookup(generated.LookupTransactionTreeParams{TxType: strPtr("x")}).Code)
+}
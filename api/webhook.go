@@ -0,0 +1,389 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+)
+
+// Webhook delivery settings.
+const (
+	// webhookTimeout is the timeout of a POST to a webhook.
+	webhookTimeout = 10 * time.Second
+	// webhookQueryTimeout is the timeout of the query of the transactions of
+	// one round.
+	webhookQueryTimeout = 30 * time.Second
+	// webhookMaxAttempts is the number of failed deliveries of a round after
+	// which it is dead-lettered.
+	webhookMaxAttempts = 8
+	// The delay before a retry doubles with every failed attempt.
+	webhookMinBackoff = 5 * time.Second
+	webhookMaxBackoff = time.Hour
+	// webhookMaxDeadLetters is the number of dead letters kept per webhook.
+	webhookMaxDeadLetters = 100
+	// webhookRoundsPerPass is the number of rounds a webhook goes through
+	// before the other webhooks get new rounds, when it catches up.
+	webhookRoundsPerPass = 100
+)
+
+// Headers of a webhook delivery.
+const (
+	WebhookIDHeader        = "X-Indexer-Webhook-Id"
+	WebhookRoundHeader     = "X-Indexer-Webhook-Round"
+	WebhookTimestampHeader = "X-Indexer-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Indexer-Webhook-Signature"
+)
+
+// WebhookPayload is the JSON body POSTed to a webhook, with the matching
+// transactions of one round. Rounds without matching transactions are not
+// POSTed.
+type WebhookPayload struct {
+	Webhook      string                  `json:"webhook"`
+	Round        uint64                  `json:"round"`
+	Transactions []generated.Transaction `json:"transactions"`
+}
+
+// SignWebhookPayload returns the signature of a delivery, sent as
+// "sha256=<signature>" in WebhookSignatureHeader: the hex HMAC-SHA256 of the
+// WebhookTimestampHeader value, a dot and the body, keyed with the secret.
+func SignWebhookPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns the delay after the failed attempt number `attempts`.
+func webhookBackoff(attempts uint64) time.Duration {
+	backoff := webhookMinBackoff
+	for i := uint64(1); i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	return backoff
+}
+
+// recordDelivery updates `w` after the delivery of `round` failed with `err`,
+// or succeeded if `err` is nil. The rounds from `from`, the pending round when
+// the delivery started, to `round` had no matching transactions. Nothing is
+// changed if the pending round was changed in the meantime.
+func recordDelivery(w *idb.Webhook, from, round uint64, err error, now time.Time) {
+	if w.PendingRound() != from {
+		return
+	}
+	replay := len(w.Replay) > 0
+	if !replay {
+		w.NextRound = round
+	}
+
+	if err != nil {
+		w.Attempts++
+		w.LastError = err.Error()
+		if w.Attempts < webhookMaxAttempts {
+			w.NextAttempt = now.Add(webhookBackoff(w.Attempts)).Unix()
+			return
+		}
+		w.DeadLetters = append(w.DeadLetters, idb.WebhookDeadLetter{
+			Round:    round,
+			Attempts: w.Attempts,
+			Error:    w.LastError,
+			Time:     now.Unix(),
+		})
+		if len(w.DeadLetters) > webhookMaxDeadLetters {
+			w.DeadLetters = w.DeadLetters[len(w.DeadLetters)-webhookMaxDeadLetters:]
+		}
+	} else {
+		w.LastError = ""
+	}
+
+	w.Attempts = 0
+	w.NextAttempt = 0
+	if replay {
+		w.Replay = w.Replay[1:]
+	} else {
+		w.NextRound = round + 1
+	}
+}
+
+// webhookFilter converts the filter of a webhook into a transaction filter.
+func webhookFilter(f idb.WebhookFilter) (idb.TransactionFilter, error) {
+	params := generated.SearchForTransactionsParams{
+		CurrencyGreaterThan: f.CurrencyGreaterThan,
+	}
+	if f.Address != "" {
+		params.Address = &f.Address
+	}
+	if f.AddressRole != "" {
+		params.AddressRole = &f.AddressRole
+	}
+	if f.AssetID != 0 {
+		params.AssetId = &f.AssetID
+	}
+	if f.ApplicationID != 0 {
+		params.ApplicationId = &f.ApplicationID
+	}
+	if f.TxType != "" {
+		params.TxType = &f.TxType
+	}
+
+	filter, err := transactionParamsToTransactionFilter(params)
+	if err != nil {
+		return idb.TransactionFilter{}, err
+	}
+	err = validateTransactionFilter(&filter)
+	if err != nil {
+		return idb.TransactionFilter{}, err
+	}
+	return filter, nil
+}
+
+// Webhooks POSTs the transactions matching the stored webhooks after they are
+// imported, and serves the admin API of the webhooks.
+type Webhooks struct {
+	si     *ServerImplementation
+	db     idb.IndexerDb
+	client *http.Client
+	log    *log.Logger
+	now    func() time.Time
+
+	// rounds wakes up the delivery after a round is imported, changed after a
+	// webhook is changed by the admin API.
+	rounds  *RoundNotifier
+	changed *RoundNotifier
+
+	// mu serializes the updates of the stored webhooks.
+	mu sync.Mutex
+}
+
+// MakeWebhooks creates the webhook delivery. `rounds` must be notified after
+// each imported round.
+func MakeWebhooks(db idb.IndexerDb, rounds *RoundNotifier, log *log.Logger) *Webhooks {
+	return &Webhooks{
+		si: &ServerImplementation{
+			db:      db,
+			timeout: webhookQueryTimeout,
+			log:     log,
+		},
+		db:      db,
+		client:  &http.Client{},
+		log:     log,
+		now:     time.Now,
+		rounds:  rounds,
+		changed: MakeRoundNotifier(),
+	}
+}
+
+// update applies `f` to the stored webhook `id`, unless it returns an error.
+func (wh *Webhooks) update(id string, f func(w *idb.Webhook) error) (idb.Webhook, error) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	w, err := wh.db.GetWebhook(id)
+	if err != nil {
+		return idb.Webhook{}, err
+	}
+	err = f(&w)
+	if err != nil {
+		return idb.Webhook{}, err
+	}
+	err = wh.db.SetWebhook(id, w)
+	if err != nil {
+		return idb.Webhook{}, err
+	}
+	return w, nil
+}
+
+// Run delivers the webhooks until `ctx` is done.
+func (wh *Webhooks) Run(ctx context.Context) {
+	for {
+		// Get the channels before the delivery, so that a round imported in
+		// between is not missed.
+		wake := wh.rounds.wait()
+		changed := wh.changed.wait()
+
+		var timer *time.Timer
+		var retry <-chan time.Time
+		if next := wh.deliverAll(ctx); !next.IsZero() {
+			timer = time.NewTimer(next.Sub(wh.now()))
+			retry = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-wake:
+		case <-changed:
+		case <-retry:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// deliverAll delivers the pending rounds of all webhooks, concurrently.
+// Returns the time of the next retry, or zero if there is nothing to retry.
+func (wh *Webhooks) deliverAll(ctx context.Context) time.Time {
+	webhooks, err := wh.db.GetWebhooks()
+	if err != nil {
+		wh.log.WithError(err).Warn("failed to get the webhooks")
+		return wh.now().Add(webhookMinBackoff)
+	}
+	nextRound, err := wh.db.GetNextRoundToAccount()
+	if err != nil {
+		wh.log.WithError(err).Warn("webhooks failed to get the next round")
+		return wh.now().Add(webhookMinBackoff)
+	}
+
+	var mu sync.Mutex
+	var retry time.Time
+	var wg sync.WaitGroup
+	for id, w := range webhooks {
+		if w.Paused {
+			continue
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			next := wh.deliver(ctx, id, nextRound)
+			mu.Lock()
+			defer mu.Unlock()
+			if !next.IsZero() && (retry.IsZero() || next.Before(retry)) {
+				retry = next
+			}
+		}(id)
+	}
+	wg.Wait()
+	return retry
+}
+
+// deliver delivers the pending rounds of webhook `id` before `nextRound`,
+// until a delivery fails. Returns the time of the next retry, or zero if
+// there is nothing to retry.
+func (wh *Webhooks) deliver(ctx context.Context, id string, nextRound uint64) time.Time {
+	rounds := 0
+	for ctx.Err() == nil {
+		w, err := wh.db.GetWebhook(id)
+		if errors.Is(err, idb.ErrorWebhookNotFound) {
+			return time.Time{}
+		}
+		if err != nil {
+			wh.log.WithError(err).Warnf("failed to get webhook %s", id)
+			return wh.now().Add(webhookMinBackoff)
+		}
+		if w.Paused {
+			return time.Time{}
+		}
+		if w.Attempts > 0 && wh.now().Unix() < w.NextAttempt {
+			return time.Unix(w.NextAttempt, 0)
+		}
+		from := w.PendingRound()
+		replay := len(w.Replay) > 0
+		if !replay && from >= nextRound {
+			return time.Time{}
+		}
+		if rounds >= webhookRoundsPerPass {
+			return wh.now()
+		}
+		filter, err := webhookFilter(w.Filter)
+		if err != nil {
+			// The filter is validated when the webhook is created.
+			wh.log.WithError(err).Errorf("webhook %s has an invalid filter", id)
+			return time.Time{}
+		}
+
+		// Skip the rounds without matching transactions.
+		round := from
+		var txns []generated.Transaction
+		for {
+			rounds++
+			txns, err = wh.si.fetchRoundTransactions(ctx, filter, round)
+			if err != nil || len(txns) > 0 || replay || round+1 >= nextRound || rounds >= webhookRoundsPerPass {
+				break
+			}
+			round++
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				wh.log.WithError(err).Warnf("webhook %s failed to get the transactions of round %d", id, round)
+			}
+			return wh.now().Add(webhookMinBackoff)
+		}
+		var postErr error
+		if len(txns) > 0 {
+			postErr = wh.post(ctx, id, &w, round, txns)
+			if postErr != nil && ctx.Err() != nil {
+				// Stopping is not a failed delivery.
+				return time.Time{}
+			}
+			if postErr != nil {
+				wh.log.WithError(postErr).Infof("delivering round %d to webhook %s failed", round, id)
+			}
+		}
+
+		_, err = wh.update(id, func(w *idb.Webhook) error {
+			recordDelivery(w, from, round, postErr, wh.now())
+			return nil
+		})
+		if errors.Is(err, idb.ErrorWebhookNotFound) {
+			return time.Time{}
+		}
+		if err != nil {
+			wh.log.WithError(err).Warnf("failed to store the delivery state of webhook %s", id)
+			return wh.now().Add(webhookMinBackoff)
+		}
+	}
+	return time.Time{}
+}
+
+// post POSTs the transactions of `round` to webhook `w`.
+func (wh *Webhooks) post(ctx context.Context, id string, w *idb.Webhook, round uint64, txns []generated.Transaction) error {
+	body, err := json.Marshal(WebhookPayload{Webhook: id, Round: round, Transactions: txns})
+	if err != nil {
+		return fmt.Errorf("post() marshal err: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post() err: %w", err)
+	}
+	timestamp := strconv.FormatInt(wh.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, id)
+	req.Header.Set(WebhookRoundHeader, strconv.FormatUint(round, 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(w.Secret, timestamp, body))
+
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post() err: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("post() webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// WebhookAdminTokenHeader is the header of the token of the webhook admin API.
+const WebhookAdminTokenHeader = "X-Indexer-Admin-Token"
+
+// WebhookRequest is the body of a request creating a webhook.
+type WebhookRequest struct {
+	URL string `json:"url"`
+	// Secret signs the deliveries, a random secret is generated if empty.
+	Secret string            `json:"secret,omitempty"`
+	Filter idb.WebhookFilter `json:"filter"`
+	// MinRound is the first round delivered, the next imported round if nil.
+	MinRound *uint64 `json:"min-round,omitempty"`
+}
+
+// WebhookResponse is a webhook in the admin API. The secret is only returned
+// when the webhook is created.
+type WebhookResponse struct {
+	ID          string                  `json:"id"`
+	URL         string                  `json:"url"`
+	Secret      string                  `json:"secret,omitempty"`
+	Filter      idb.WebhookFilter       `json:"filter"`
+	Paused      bool                    `json:"paused"`
+	NextRound   uint64                  `json:"next-round"`
+	Replay      []uint64                `json:"replay,omitempty"`
+	Attempts    uint64                  `json:"attempts"`
+	NextAttempt int64                   `json:"next-attempt,omitempty"`
+	LastError   string                  `json:"last-error,omitempty"`
+	DeadLetters []idb.WebhookDeadLetter `json:"dead-letters,omitempty"`
+}
+
+// WebhooksResponse is the response of the webhook list.
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+func webhookResponse(id string, w idb.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:          id,
+		URL:         w.URL,
+		Filter:      w.Filter,
+		Paused:      w.Paused,
+		NextRound:   w.NextRound,
+		Replay:      w.Replay,
+		Attempts:    w.Attempts,
+		NextAttempt: w.NextAttempt,
+		LastError:   w.LastError,
+		DeadLetters: w.DeadLetters,
+	}
+}
+
+// randomHex returns `n` random bytes in hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// registerWebhookHandlers adds the webhook admin API.
+func registerWebhookHandlers(e *echo.Echo, wh *Webhooks, m ...echo.MiddlewareFunc) {
+	e.GET("/v2/webhooks", wh.listWebhooks, m...)
+	e.POST("/v2/webhooks", wh.createWebhook, m...)
+	e.GET("/v2/webhooks/:webhook-id", wh.getWebhook, m...)
+	e.DELETE("/v2/webhooks/:webhook-id", wh.deleteWebhook, m...)
+	e.POST("/v2/webhooks/:webhook-id/pause", wh.pauseWebhook, m...)
+	e.POST("/v2/webhooks/:webhook-id/resume", wh.resumeWebhook, m...)
+	e.POST("/v2/webhooks/:webhook-id/replay", wh.replayWebhook, m...)
+}
+
+// webhookError returns the response to a failed webhook lookup or update.
+func webhookError(ctx echo.Context, err error) error {
+	if errors.Is(err, idb.ErrorWebhookNotFound) {
+		return notFound(ctx, errNoWebhookFound)
+	}
+	var invalid errInvalidWebhookRequest
+	if errors.As(err, &invalid) {
+		return badRequest(ctx, invalid.Error())
+	}
+	return indexerError(ctx, err)
+}
+
+// errInvalidWebhookRequest is returned by the update functions of the admin
+// API for a bad request.
+type errInvalidWebhookRequest string
+
+func (e errInvalidWebhookRequest) Error() string {
+	return string(e)
+}
+
+// listWebhooks returns all webhooks, by id.
+// (GET /v2/webhooks)
+func (wh *Webhooks) listWebhooks(ctx echo.Context) error {
+	webhooks, err := wh.db.GetWebhooks()
+	if err != nil {
+		return indexerError(ctx, err)
+	}
+
+	res := WebhooksResponse{Webhooks: make([]WebhookResponse, 0, len(webhooks))}
+	for id, w := range webhooks {
+		res.Webhooks = append(res.Webhooks, webhookResponse(id, w))
+	}
+	sort.Slice(res.Webhooks, func(i, j int) bool {
+		return res.Webhooks[i].ID < res.Webhooks[j].ID
+	})
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// createWebhook validates and stores a new webhook.
+// (POST /v2/webhooks)
+func (wh *Webhooks) createWebhook(ctx echo.Context) error {
+	var req WebhookRequest
+	err := ctx.Bind(&req)
+	if err != nil {
+		return badRequest(ctx, fmt.Sprintf("%s: %v", errInvalidWebhook, err))
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return badRequest(ctx, errInvalidWebhookURL)
+	}
+	_, err = webhookFilter(req.Filter)
+	if err != nil {
+		return badRequest(ctx, err.Error())
+	}
+
+	nextRound, err := wh.db.GetNextRoundToAccount()
+	if err != nil {
+		return indexerError(ctx, err)
+	}
+	w := idb.Webhook{
+		URL:       req.URL,
+		Secret:    req.Secret,
+		Filter:    req.Filter,
+		NextRound: nextRound,
+	}
+	if req.MinRound != nil {
+		if *req.MinRound > nextRound {
+			return badRequest(ctx, errWebhookFutureRound)
+		}
+		w.NextRound = *req.MinRound
+	}
+	if w.Secret == "" {
+		w.Secret, err = randomHex(32)
+		if err != nil {
+			return indexerError(ctx, err)
+		}
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return indexerError(ctx, err)
+	}
+
+	err = wh.db.SetWebhook(id, w)
+	if err != nil {
+		return indexerError(ctx, err)
+	}
+	wh.changed.Notify()
+
+	res := webhookResponse(id, w)
+	res.Secret = w.Secret
+	return ctx.JSON(http.StatusCreated, res)
+}
+
+// getWebhook returns a webhook and its delivery state.
+// (GET /v2/webhooks/{webhook-id})
+func (wh *Webhooks) getWebhook(ctx echo.Context) error {
+	id := ctx.Param("webhook-id")
+	w, err := wh.db.GetWebhook(id)
+	if err != nil {
+		return webhookError(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, webhookResponse(id, w))
+}
+
+// deleteWebhook stops the deliveries of a webhook and removes it.
+// (DELETE /v2/webhooks/{webhook-id})
+func (wh *Webhooks) deleteWebhook(ctx echo.Context) error {
+	id := ctx.Param("webhook-id")
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	_, err := wh.db.GetWebhook(id)
+	if err != nil {
+		return webhookError(ctx, err)
+	}
+	err = wh.db.DeleteWebhook(id)
+	if err != nil {
+		return indexerError(ctx, err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// updateWebhook applies `f` to a webhook and returns it.
+func (wh *Webhooks) updateWebhook(ctx echo.Context, f func(w *idb.Webhook) error) error {
+	id := ctx.Param("webhook-id")
+	w, err := wh.update(id, f)
+	if err != nil {
+		return webhookError(ctx, err)
+	}
+	wh.changed.Notify()
+	return ctx.JSON(http.StatusOK, webhookResponse(id, w))
+}
+
+// pauseWebhook stops the deliveries of a webhook, they continue at the same
+// round when it is resumed.
+// (POST /v2/webhooks/{webhook-id}/pause)
+func (wh *Webhooks) pauseWebhook(ctx echo.Context) error {
+	return wh.updateWebhook(ctx, func(w *idb.Webhook) error {
+		w.Paused = true
+		return nil
+	})
+}
+
+// resumeWebhook restarts the deliveries of a webhook, a failed delivery is
+// retried immediately.
+// (POST /v2/webhooks/{webhook-id}/resume)
+func (wh *Webhooks) resumeWebhook(ctx echo.Context) error {
+	return wh.updateWebhook(ctx, func(w *idb.Webhook) error {
+		w.Paused = false
+		w.NextAttempt = 0
+		return nil
+	})
+}
+
+// replayWebhook delivers rounds again. With min-round the webhook goes back to
+// that round, otherwise the dead-lettered rounds are queued again.
+// (POST /v2/webhooks/{webhook-id}/replay)
+func (wh *Webhooks) replayWebhook(ctx echo.Context) error {
+	var minRound *uint64
+	if s := ctx.QueryParam("min-round"); s != "" {
+		round, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return badRequest(ctx, fmt.Sprintf("%s: %v", errInvalidWebhookReplay, err))
+		}
+		minRound = &round
+	}
+
+	return wh.updateWebhook(ctx, func(w *idb.Webhook) error {
+		if minRound != nil {
+			if *minRound > w.NextRound {
+				return errInvalidWebhookRequest(errWebhookFutureRound)
+			}
+			// The queued rounds from min-round on are delivered again anyway.
+			var replay []uint64
+			for _, round := range w.Replay {
+				if round < *minRound {
+					replay = append(replay, round)
+				}
+			}
+			w.NextRound = *minRound
+			w.Replay = replay
+		} else {
+			for _, letter := range w.DeadLetters {
+				w.Replay = append(w.Replay, letter.Round)
+			}
+			w.DeadLetters = nil
+		}
+		w.Attempts = 0
+		w.NextAttempt = 0
+		return nil
+	})
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+// webhookStore keeps the webhooks of a mock database.
+type webhookStore struct {
+	mu       sync.Mutex
+	webhooks map[string]idb.Webhook
+}
+
+func (s *webhookStore) get(id string) idb.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.webhooks[id]
+}
+
+func mockWebhookStore(db *mocks.IndexerDb, webhooks map[string]idb.Webhook) *webhookStore {
+	s := &webhookStore{webhooks: webhooks}
+	db.On("GetWebhook", mock.Anything).Return(
+		func(id string) idb.Webhook {
+			return s.get(id)
+		},
+		func(id string) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if _, ok := s.webhooks[id]; !ok {
+				return idb.ErrorWebhookNotFound
+			}
+			return nil
+		})
+	db.On("GetWebhooks").Return(
+		func() map[string]idb.Webhook {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			res := make(map[string]idb.Webhook)
+			for id, w := range s.webhooks {
+				res[id] = w
+			}
+			return res
+		},
+		nil)
+	db.On("SetWebhook", mock.Anything, mock.Anything).Return(
+		func(id string, w idb.Webhook) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.webhooks[id] = w
+			return nil
+		})
+	db.On("DeleteWebhook", mock.Anything).Return(
+		func(id string) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.webhooks, id)
+			return nil
+		})
+	return s
+}
+
+// mockMatchingRounds makes the mock return one payment in each of `rounds`.
+func mockMatchingRounds(db *mocks.IndexerDb, rounds ...uint64) {
+	db.On("Transactions", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, filter idb.TransactionFilter) <-chan idb.TxnRow {
+			ch := make(chan idb.TxnRow, 1)
+			for _, round := range rounds {
+				if filter.NextToken == "" && *filter.Round == round {
+					pay := test.MakePaymentTxn(1000, round, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+					ch <- idb.TxnRow{Round: round, RoundTime: time.Unix(1600000000, 0), Txn: &pay}
+				}
+			}
+			close(ch)
+			return ch
+		},
+		uint64(0))
+}
+
+// webhookReceiver records the deliveries and responds with `status`.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+func (r *webhookReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusOK}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	db := &mocks.IndexerDb{}
+	mockMatchingRounds(db, 6)
+	db.On("GetNextRoundToAccount").Return(uint64(8), nil)
+	store := mockWebhookStore(db, map[string]idb.Webhook{
+		"a":      {URL: srv.URL, Secret: "secret", NextRound: 5},
+		"paused": {URL: srv.URL, Secret: "secret", NextRound: 5, Paused: true},
+	})
+	wh := MakeWebhooks(db, MakeRoundNotifier(), log.New())
+
+	retry := wh.deliverAll(context.Background())
+	assert.True(t, retry.IsZero())
+
+	// Only the round with a matching transaction is POSTed.
+	require.Equal(t, 1, receiver.count())
+	req, body := receiver.requests[0], receiver.bodies[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "a", req.Header.Get(WebhookIDHeader))
+	assert.Equal(t, "6", req.Header.Get(WebhookRoundHeader))
+	timestamp := req.Header.Get(WebhookTimestampHeader)
+	assert.Equal(t, "sha256="+SignWebhookPayload("secret", timestamp, body), req.Header.Get(WebhookSignatureHeader))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, "a", payload.Webhook)
+	assert.Equal(t, uint64(6), payload.Round)
+	assert.Len(t, payload.Transactions, 1)
+
+	assert.Equal(t, idb.Webhook{URL: srv.URL, Secret: "secret", NextRound: 8}, store.get("a"))
+	assert.Equal(t, uint64(5), store.get("paused").NextRound)
+}
+
+func TestWebhookRetries(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	db := &mocks.IndexerDb{}
+	mockMatchingRounds(db, 6)
+	db.On("GetNextRoundToAccount").Return(uint64(8), nil)
+	store := mockWebhookStore(db, map[string]idb.Webhook{
+		"a": {URL: srv.URL, Secret: "secret", NextRound: 5},
+	})
+	wh := MakeWebhooks(db, MakeRoundNotifier(), log.New())
+	now := time.Unix(1600000000, 0)
+	wh.now = func() time.Time { return now }
+
+	retry := wh.deliverAll(context.Background())
+	w := store.get("a")
+	assert.Equal(t, uint64(6), w.NextRound)
+	assert.Equal(t, uint64(1), w.Attempts)
+	assert.Contains(t, w.LastError, "500")
+	assert.Equal(t, now.Add(webhookMinBackoff), retry)
+
+	// Nothing is retried before the backoff.
+	wh.deliverAll(context.Background())
+	assert.Equal(t, 1, receiver.count())
+
+	for i := 1; i < webhookMaxAttempts; i++ {
+		now = time.Unix(store.get("a").NextAttempt, 0)
+		wh.deliverAll(context.Background())
+	}
+	assert.Equal(t, webhookMaxAttempts, receiver.count())
+
+	// The round is dead-lettered and the webhook moves on.
+	w = store.get("a")
+	assert.Equal(t, uint64(8), w.NextRound)
+	assert.Equal(t, uint64(0), w.Attempts)
+	require.Len(t, w.DeadLetters, 1)
+	assert.Equal(t, uint64(6), w.DeadLetters[0].Round)
+	assert.Equal(t, uint64(webhookMaxAttempts), w.DeadLetters[0].Attempts)
+
+	// Replay the dead letters.
+	e := echo.New()
+	registerWebhookHandlers(e, wh)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/webhooks/a/replay", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []uint64{6}, store.get("a").Replay)
+
+	receiver.setStatus(http.StatusOK)
+	assert.True(t, wh.deliverAll(context.Background()).IsZero())
+	assert.Equal(t, webhookMaxAttempts+1, receiver.count())
+	assert.Equal(t, "6", receiver.requests[webhookMaxAttempts].Header.Get(WebhookRoundHeader))
+	w = store.get("a")
+	assert.Empty(t, w.Replay)
+	assert.Empty(t, w.DeadLetters)
+	assert.Equal(t, uint64(8), w.NextRound)
+}
+
+func TestRecordDeliveryChangedWebhook(t *testing.T) {
+	// The webhook was sent back to round 2 during the delivery of round 5.
+	w := idb.Webhook{NextRound: 2}
+	recordDelivery(&w, 5, 5, nil, time.Now())
+	assert.Equal(t, idb.Webhook{NextRound: 2}, w)
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, webhookMinBackoff, webhookBackoff(1))
+	assert.Equal(t, 2*webhookMinBackoff, webhookBackoff(2))
+	assert.Equal(t, 4*webhookMinBackoff, webhookBackoff(3))
+	assert.Equal(t, webhookMaxBackoff, webhookBackoff(100))
+}
+
+func TestWebhookAdmin(t *testing.T) {
+	db := &mocks.IndexerDb{}
+	db.On("GetNextRoundToAccount").Return(uint64(8), nil)
+	store := mockWebhookStore(db, map[string]idb.Webhook{})
+	wh := MakeWebhooks(db, MakeRoundNotifier(), log.New())
+	e := echo.New()
+	registerWebhookHandlers(e, wh)
+
+	call := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, "application/json")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	// Invalid webhooks.
+	code, body := call(http.MethodPost, "/v2/webhooks", `{"url": "ftp://example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, errInvalidWebhookURL)
+	code, body = call(http.MethodPost, "/v2/webhooks", `{"url": "https://example.com", "filter": {"tx-type": "bogus"}}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "unknown tx-type")
+	code, body = call(http.MethodPost, "/v2/webhooks", `{"url": "https://example.com", "min-round": 9}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, errWebhookFutureRound)
+
+	code, body = call(http.MethodPost, "/v2/webhooks", `{"url": "https://example.com", "filter": {"asset-id": 5, "currency-greater-than": 0}}`)
+	require.Equal(t, http.StatusCreated, code)
+	var created WebhookResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.NotEmpty(t, created.Secret)
+	assert.Equal(t, uint64(8), created.NextRound)
+	assert.Equal(t, uint64(5), created.Filter.AssetID)
+	require.NotNil(t, created.Filter.CurrencyGreaterThan)
+	assert.Equal(t, created.Secret, store.get(created.ID).Secret)
+
+	// The secret is not returned afterwards.
+	path := "/v2/webhooks/" + created.ID
+	code, body = call(http.MethodGet, path, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, body, created.Secret)
+	code, body = call(http.MethodGet, "/v2/webhooks", "")
+	require.Equal(t, http.StatusOK, code)
+	var list WebhooksResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &list))
+	require.Len(t, list.Webhooks, 1)
+	assert.Equal(t, created.ID, list.Webhooks[0].ID)
+
+	code, _ = call(http.MethodPost, path+"/pause", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, store.get(created.ID).Paused)
+	code, _ = call(http.MethodPost, path+"/resume", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, store.get(created.ID).Paused)
+
+	code, body = call(http.MethodPost, path+"/replay?min-round=9", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, errWebhookFutureRound)
+	code, _ = call(http.MethodPost, path+"/replay?min-round=3", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint64(3), store.get(created.ID).NextRound)
+
+	code, _ = call(http.MethodDelete, path, "")
+	assert.Equal(t, http.StatusNoContent, code)
+	code, body = call(http.MethodGet, path, "")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Contains(t, body, errNoWebhookFound)
+	code, _ = call(http.MethodPost, path+"/pause", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
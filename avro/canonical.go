@@ -0,0 +1,114 @@
+package avro
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// CanonicalForm returns the Parsing Canonical Form of the schema, which drops
+// everything that does not affect how data is read, such as docs and defaults.
+// Two schemas with the same canonical form encode data identically.
+func (s *Schema) CanonicalForm() string {
+	var b strings.Builder
+	s.writeCanonical(&b, make(map[string]bool))
+	return b.String()
+}
+
+func writeJSONString(b *strings.Builder, str string) {
+	encoded, _ := json.Marshal(str)
+	b.Write(encoded)
+}
+
+func (s *Schema) writeCanonical(b *strings.Builder, seen map[string]bool) {
+	switch s.Type {
+	case Record, Enum, Fixed:
+		if seen[s.Name] {
+			writeJSONString(b, s.Name)
+			return
+		}
+		seen[s.Name] = true
+		b.WriteString(`{"name":`)
+		writeJSONString(b, s.Name)
+		b.WriteString(`,"type":`)
+		writeJSONString(b, string(s.Type))
+		switch s.Type {
+		case Record:
+			b.WriteString(`,"fields":[`)
+			for i, f := range s.Fields {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(`{"name":`)
+				writeJSONString(b, f.Name)
+				b.WriteString(`,"type":`)
+				f.Type.writeCanonical(b, seen)
+				b.WriteByte('}')
+			}
+			b.WriteByte(']')
+		case Enum:
+			b.WriteString(`,"symbols":[`)
+			for i, sym := range s.Symbols {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				writeJSONString(b, sym)
+			}
+			b.WriteByte(']')
+		case Fixed:
+			b.WriteString(`,"size":`)
+			b.WriteString(strconv.Itoa(s.Size))
+		}
+		b.WriteByte('}')
+	case Array:
+		b.WriteString(`{"type":"array","items":`)
+		s.Items.writeCanonical(b, seen)
+		b.WriteByte('}')
+	case Map:
+		b.WriteString(`{"type":"map","values":`)
+		s.Values.writeCanonical(b, seen)
+		b.WriteByte('}')
+	case Union:
+		b.WriteByte('[')
+		for i, branch := range s.Branches {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			branch.writeCanonical(b, seen)
+		}
+		b.WriteByte(']')
+	default:
+		writeJSONString(b, string(s.Type))
+	}
+}
+
+// FingerprintSHA256 returns the SHA-256 digest of the canonical form.
+func (s *Schema) FingerprintSHA256() [32]byte {
+	return sha256.Sum256([]byte(s.CanonicalForm()))
+}
+
+const rabinEmpty = uint64(0xc15d213aa4d7a795)
+
+var rabinTable = makeRabinTable()
+
+func makeRabinTable() (table [256]uint64) {
+	for i := range table {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (rabinEmpty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	return
+}
+
+// FingerprintRabin returns the 64-bit Rabin fingerprint (CRC-64-AVRO) of the
+// canonical form, as used by the single object encoding.
+func (s *Schema) FingerprintRabin() uint64 {
+	fp := rabinEmpty
+	for _, c := range []byte(s.CanonicalForm()) {
+		fp = (fp >> 8) ^ rabinTable[byte(fp)^c]
+	}
+	return fp
+}
@@ -0,0 +1,50 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalForm(t *testing.T) {
+	tests := []struct {
+		schema    string
+		canonical string
+	}{
+		{`"long"`, `"long"`},
+		{`{"type": "string"}`, `"string"`},
+		{`["null", {"type": "long"}]`, `["null","long"]`},
+		{
+			`{"type": "record", "namespace": "a.b", "name": "R", "doc": "dropped", "fields": [
+				{"name": "x", "type": "long", "default": 1, "doc": "dropped"},
+				{"name": "self", "type": ["null", "R"]},
+				{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["A"]}},
+				{"name": "f", "type": {"type": "fixed", "name": "c.F", "size": 4}},
+				{"name": "m", "type": {"type": "map", "values": {"type": "array", "items": "E"}}}
+			]}`,
+			`{"name":"a.b.R","type":"record","fields":[{"name":"x","type":"long"},{"name":"self","type":["null","a.b.R"]},{"name":"e","type":{"name":"a.b.E","type":"enum","symbols":["A"]}},{"name":"f","type":{"name":"c.F","type":"fixed","size":4}},{"name":"m","type":{"type":"map","values":{"type":"array","items":"a.b.E"}}}]}`,
+		},
+	}
+
+	for _, tc := range tests {
+		s, err := ParseSchema(tc.schema)
+		require.NoError(t, err)
+		assert.Equal(t, tc.canonical, s.CanonicalForm())
+	}
+}
+
+// Test vectors from the reference implementation.
+func TestFingerprintRabin(t *testing.T) {
+	assert.Equal(t, uint64(7195948357588979594), MustParseSchema(`"null"`).FingerprintRabin())
+	assert.Equal(t, uint64(0x7275d51a3f395c8f), MustParseSchema(`"int"`).FingerprintRabin())
+}
+
+func TestFingerprintIgnoresDocs(t *testing.T) {
+	a := MustParseSchema(`{"type": "record", "name": "R", "fields": [{"name": "x", "type": "long"}]}`)
+	b := MustParseSchema(`{"type": "record", "name": "R", "doc": "doc", "fields": [{"name": "x", "type": "long", "doc": "doc"}]}`)
+	c := MustParseSchema(`{"type": "record", "name": "R", "fields": [{"name": "x", "type": "int"}]}`)
+	assert.Equal(t, a.FingerprintSHA256(), b.FingerprintSHA256())
+	assert.Equal(t, a.FingerprintRabin(), b.FingerprintRabin())
+	assert.NotEqual(t, a.FingerprintSHA256(), c.FingerprintSHA256())
+}
@@ -0,0 +1,126 @@
+package avro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckCompatibility returns an error if data written with the writer schema
+// cannot be read with the reader schema, following the schema resolution rules
+// of the specification. A new version of a schema is backward compatible with
+// the previous one if CheckCompatibility(new, previous) returns nil.
+func CheckCompatibility(reader, writer *Schema) error {
+	return checkCompatibility(reader, writer, "", make(map[[2]*Schema]bool))
+}
+
+func promotable(reader, writer Type) bool {
+	switch writer {
+	case Int:
+		return reader == Long || reader == Float || reader == Double
+	case Long:
+		return reader == Float || reader == Double
+	case Float:
+		return reader == Double
+	case String:
+		return reader == Bytes
+	case Bytes:
+		return reader == String
+	}
+	return false
+}
+
+// unqualified strips the namespace from a full name. Named types are matched by
+// their unqualified name, so that bumping the namespace of a schema version
+// keeps it compatible with the files of the previous version.
+func unqualified(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func describe(path string) string {
+	if path == "" {
+		return "schema"
+	}
+	return path
+}
+
+func checkCompatibility(reader, writer *Schema, path string, visited map[[2]*Schema]bool) error {
+	// Recursive named types.
+	key := [2]*Schema{reader, writer}
+	if visited[key] {
+		return nil
+	}
+	visited[key] = true
+
+	if writer.Type == Union {
+		// Every branch the writer may have used must be readable.
+		for _, branch := range writer.Branches {
+			err := checkCompatibility(reader, branch, path, visited)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if reader.Type == Union {
+		for _, branch := range reader.Branches {
+			if checkCompatibility(branch, writer, path, visited) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: no branch of the reader union matches writer type %s", describe(path), writer.Type)
+	}
+
+	if reader.Type != writer.Type {
+		if promotable(reader.Type, writer.Type) {
+			return nil
+		}
+		return fmt.Errorf("%s: writer type %s cannot be read as %s", describe(path), writer.Type, reader.Type)
+	}
+
+	switch reader.Type {
+	case Record:
+		if unqualified(reader.Name) != unqualified(writer.Name) {
+			return fmt.Errorf("%s: record name changed from %s to %s", describe(path), writer.Name, reader.Name)
+		}
+		for _, rf := range reader.Fields {
+			fieldPath := rf.Name
+			if path != "" {
+				fieldPath = path + "." + rf.Name
+			}
+			wf, ok := writer.Field(rf.Name)
+			if !ok {
+				if !rf.HasDefault() {
+					return fmt.Errorf("%s: new field has no default value", fieldPath)
+				}
+				continue
+			}
+			err := checkCompatibility(rf.Type, wf.Type, fieldPath, visited)
+			if err != nil {
+				return err
+			}
+		}
+	case Enum:
+		if unqualified(reader.Name) != unqualified(writer.Name) {
+			return fmt.Errorf("%s: enum name changed from %s to %s", describe(path), writer.Name, reader.Name)
+		}
+		symbols := make(map[string]bool, len(reader.Symbols))
+		for _, sym := range reader.Symbols {
+			symbols[sym] = true
+		}
+		for _, sym := range writer.Symbols {
+			if !symbols[sym] {
+				return fmt.Errorf("%s: enum symbol %s was removed", describe(path), sym)
+			}
+		}
+	case Fixed:
+		if unqualified(reader.Name) != unqualified(writer.Name) || reader.Size != writer.Size {
+			return fmt.Errorf("%s: fixed %s(%d) cannot be read as %s(%d)", describe(path), writer.Name, writer.Size, reader.Name, reader.Size)
+		}
+	case Array:
+		return checkCompatibility(reader.Items, writer.Items, path+"[]", visited)
+	case Map:
+		return checkCompatibility(reader.Values, writer.Values, path+"{}", visited)
+	}
+	return nil
+}
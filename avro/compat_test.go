@@ -0,0 +1,82 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	const base = `{"type": "record", "name": "R", "fields": [
+		{"name": "a", "type": "int"},
+		{"name": "b", "type": ["null", "string"], "default": null},
+		{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y"]}}
+	]}`
+
+	tests := []struct {
+		name       string
+		reader     string
+		compatible bool
+	}{
+		{"same", base, true},
+		{"promote int to long", `{"type": "record", "name": "R", "fields": [
+			{"name": "a", "type": "long"},
+			{"name": "b", "type": ["null", "string"], "default": null},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y"]}}
+		]}`, true},
+		{"remove field", `{"type": "record", "name": "R", "fields": [
+			{"name": "a", "type": "int"},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y"]}}
+		]}`, true},
+		{"add field with default", `{"type": "record", "name": "R", "fields": [
+			{"name": "a", "type": "int"},
+			{"name": "b", "type": ["null", "string"], "default": null},
+			{"name": "c", "type": ["null", "long"], "default": null},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y", "Z"]}}
+		]}`, true},
+		{"add field without default", `{"type": "record", "name": "R", "fields": [
+			{"name": "a", "type": "int"},
+			{"name": "b", "type": ["null", "string"], "default": null},
+			{"name": "c", "type": "long"},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y"]}}
+		]}`, false},
+		{"narrow type", `{"type": "record", "name": "R", "fields": [
+			{"name": "a", "type": "boolean"},
+			{"name": "b", "type": ["null", "string"], "default": null},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y"]}}
+		]}`, false},
+		{"drop union branch", `{"type": "record", "name": "R", "fields": [
+			{"name": "a", "type": "int"},
+			{"name": "b", "type": "string"},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y"]}}
+		]}`, false},
+		{"remove enum symbol", `{"type": "record", "name": "R", "fields": [
+			{"name": "a", "type": "int"},
+			{"name": "b", "type": ["null", "string"], "default": null},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X"]}}
+		]}`, false},
+		{"rename record", `{"type": "record", "name": "S", "fields": [
+			{"name": "a", "type": "int"}
+		]}`, false},
+		{"new namespace", `{"type": "record", "name": "R", "namespace": "v2", "fields": [
+			{"name": "a", "type": "int"},
+			{"name": "b", "type": ["null", "string"], "default": null},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["X", "Y"]}}
+		]}`, true},
+		{"rename record in new namespace", `{"type": "record", "name": "S", "namespace": "v2", "fields": [
+			{"name": "a", "type": "int"}
+		]}`, false},
+	}
+
+	writer := MustParseSchema(base)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckCompatibility(MustParseSchema(tc.reader), writer)
+			if tc.compatible {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
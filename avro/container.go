@@ -0,0 +1,347 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Codec is the compression codec used for the data blocks of a container file.
+type Codec string
+
+const (
+	// CodecNull writes uncompressed blocks.
+	CodecNull Codec = "null"
+	// CodecDeflate compresses blocks with raw deflate (RFC 1951).
+	CodecDeflate Codec = "deflate"
+)
+
+// Metadata keys reserved by the Avro specification.
+const (
+	MetaSchema = "avro.schema"
+	MetaCodec  = "avro.codec"
+)
+
+const (
+	syncSize = 16
+
+	// defaultBlockRecords is the number of records buffered before a block is
+	// written out.
+	defaultBlockRecords = 4096
+	// defaultBlockBytes is the encoded size after which a block is written out.
+	defaultBlockBytes = 1 << 20
+)
+
+var magic = []byte{'O', 'b', 'j', 1}
+
+var metaSchema = &Schema{Type: Map, Values: &Schema{Type: Bytes}}
+
+// Writer writes records to an Avro Object Container File.
+type Writer struct {
+	w      io.Writer
+	schema *Schema
+	codec  Codec
+	sync   [syncSize]byte
+
+	block []byte
+	count int64
+	err   error
+}
+
+// NewWriter writes the container file header to w and returns a Writer for
+// records of the given schema. User metadata is stored in the header next to
+// the schema and codec.
+func NewWriter(w io.Writer, schema *Schema, codec Codec, meta map[string][]byte) (*Writer, error) {
+	switch codec {
+	case "":
+		codec = CodecNull
+	case CodecNull, CodecDeflate:
+	default:
+		return nil, fmt.Errorf("NewWriter() unsupported codec %q", codec)
+	}
+
+	writer := &Writer{w: w, schema: schema, codec: codec}
+	_, err := rand.Read(writer.sync[:])
+	if err != nil {
+		return nil, fmt.Errorf("NewWriter() err: %w", err)
+	}
+
+	header := make(map[string]interface{}, len(meta)+2)
+	for k, v := range meta {
+		header[k] = v
+	}
+	header[MetaSchema] = []byte(schema.String())
+	header[MetaCodec] = []byte(codec)
+
+	buf := append([]byte{}, magic...)
+	buf, err = AppendEncode(buf, metaSchema, header)
+	if err != nil {
+		return nil, fmt.Errorf("NewWriter() err: %w", err)
+	}
+	buf = append(buf, writer.sync[:]...)
+	_, err = w.Write(buf)
+	if err != nil {
+		return nil, fmt.Errorf("NewWriter() err: %w", err)
+	}
+
+	return writer, nil
+}
+
+// Append encodes a record and adds it to the current block. The block is
+// written out once it is large enough.
+func (w *Writer) Append(record interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	block, err := AppendEncode(w.block, w.schema, record)
+	if err != nil {
+		// Drop the partially encoded record.
+		return fmt.Errorf("Append() err: %w", err)
+	}
+	w.block = block
+	w.count++
+	if w.count >= defaultBlockRecords || len(w.block) >= defaultBlockBytes {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes out the current block, if it is not empty.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.count == 0 {
+		return nil
+	}
+
+	data := w.block
+	if w.codec == CodecDeflate {
+		var compressed bytes.Buffer
+		fw, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+		if err != nil {
+			w.err = fmt.Errorf("Flush() err: %w", err)
+			return w.err
+		}
+		fw.Write(data)
+		err = fw.Close()
+		if err != nil {
+			w.err = fmt.Errorf("Flush() err: %w", err)
+			return w.err
+		}
+		data = compressed.Bytes()
+	}
+
+	buf := appendLong(nil, w.count)
+	buf = appendLong(buf, int64(len(data)))
+	buf = append(buf, data...)
+	buf = append(buf, w.sync[:]...)
+	_, err := w.w.Write(buf)
+	if err != nil {
+		w.err = fmt.Errorf("Flush() err: %w", err)
+		return w.err
+	}
+
+	w.block = w.block[:0]
+	w.count = 0
+	return nil
+}
+
+// Close flushes the last block. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+// Reader reads records from an Avro Object Container File.
+type Reader struct {
+	r      *bufio.Reader
+	schema *Schema
+	codec  Codec
+	meta   map[string][]byte
+	sync   [syncSize]byte
+
+	block     []byte
+	remaining int64
+}
+
+// ReadHeader reads the header of a container file, returning its metadata
+// including the schema text and codec.
+func ReadHeader(r io.Reader) (map[string][]byte, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return reader.Metadata(), nil
+}
+
+// NewReader reads the container file header from r.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	var head [4]byte
+	_, err := io.ReadFull(br, head[:])
+	if err != nil {
+		return nil, fmt.Errorf("NewReader() err: %w", err)
+	}
+	if !bytes.Equal(head[:], magic) {
+		return nil, fmt.Errorf("NewReader() not an avro container file")
+	}
+
+	meta := make(map[string][]byte)
+	for {
+		count, err := readStreamLong(br)
+		if err != nil {
+			return nil, fmt.Errorf("NewReader() err: %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			count = -count
+			_, err = readStreamLong(br)
+			if err != nil {
+				return nil, fmt.Errorf("NewReader() err: %w", err)
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			k, err := readStreamBytes(br)
+			if err != nil {
+				return nil, fmt.Errorf("NewReader() err: %w", err)
+			}
+			v, err := readStreamBytes(br)
+			if err != nil {
+				return nil, fmt.Errorf("NewReader() err: %w", err)
+			}
+			meta[string(k)] = v
+		}
+	}
+
+	reader := &Reader{r: br, meta: meta}
+	_, err = io.ReadFull(br, reader.sync[:])
+	if err != nil {
+		return nil, fmt.Errorf("NewReader() err: %w", err)
+	}
+
+	reader.schema, err = ParseSchema(string(meta[MetaSchema]))
+	if err != nil {
+		return nil, fmt.Errorf("NewReader() err: %w", err)
+	}
+	reader.codec = Codec(meta[MetaCodec])
+	switch reader.codec {
+	case "":
+		reader.codec = CodecNull
+	case CodecNull, CodecDeflate:
+	default:
+		return nil, fmt.Errorf("NewReader() unsupported codec %q", reader.codec)
+	}
+
+	return reader, nil
+}
+
+// Schema returns the writer schema stored in the file header.
+func (r *Reader) Schema() *Schema {
+	return r.schema
+}
+
+// Metadata returns the metadata stored in the file header.
+func (r *Reader) Metadata() map[string][]byte {
+	return r.meta
+}
+
+// Next decodes the next record. It returns io.EOF after the last record.
+func (r *Reader) Next() (interface{}, error) {
+	for r.remaining == 0 {
+		err := r.readBlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	v, rest, err := Decode(r.schema, r.block)
+	if err != nil {
+		return nil, fmt.Errorf("Next() err: %w", err)
+	}
+	r.block = rest
+	r.remaining--
+	return v, nil
+}
+
+func (r *Reader) readBlock() error {
+	count, err := readStreamLong(r.r)
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("readBlock() err: %w", err)
+	}
+	size, err := readStreamLong(r.r)
+	if err != nil {
+		return fmt.Errorf("readBlock() err: %w", err)
+	}
+	if count < 0 || size < 0 {
+		return fmt.Errorf("readBlock() corrupt block header")
+	}
+	data := make([]byte, size)
+	_, err = io.ReadFull(r.r, data)
+	if err != nil {
+		return fmt.Errorf("readBlock() err: %w", err)
+	}
+	var sync [syncSize]byte
+	_, err = io.ReadFull(r.r, sync[:])
+	if err != nil {
+		return fmt.Errorf("readBlock() err: %w", err)
+	}
+	if sync != r.sync {
+		return fmt.Errorf("readBlock() sync marker mismatch")
+	}
+
+	if r.codec == CodecDeflate {
+		data, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return fmt.Errorf("readBlock() err: %w", err)
+		}
+	}
+	r.block = data
+	r.remaining = count
+	return nil
+}
+
+func readStreamLong(r io.ByteReader) (int64, error) {
+	var u uint64
+	var shift uint
+	for i := 0; ; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if shift >= 64 {
+			return 0, errors.New("varint overflow")
+		}
+		u |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+		shift += 7
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func readStreamBytes(r *bufio.Reader) ([]byte, error) {
+	l, err := readStreamLong(r)
+	if err != nil {
+		return nil, err
+	}
+	if l < 0 {
+		return nil, fmt.Errorf("negative length %d", l)
+	}
+	out := make([]byte, l)
+	_, err = io.ReadFull(r, out)
+	return out, err
+}
@@ -0,0 +1,109 @@
+package avro
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = MustParseSchema(`{
+  "type": "record",
+  "name": "Row",
+  "fields": [
+    {"name": "round", "type": "long"},
+    {"name": "note", "type": ["null", "bytes"]}
+  ]
+}`)
+
+func writeRows(t *testing.T, codec Codec, n int) *bytes.Buffer {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testSchema, codec, map[string][]byte{"app.version": []byte("1")})
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		var note interface{}
+		if i%2 == 0 {
+			note = []byte(fmt.Sprintf("note %d", i))
+		}
+		err = w.Append(map[string]interface{}{"round": uint64(i), "note": note})
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return &buf
+}
+
+func TestContainerRoundTrip(t *testing.T) {
+	for _, codec := range []Codec{CodecNull, CodecDeflate} {
+		t.Run(string(codec), func(t *testing.T) {
+			// Enough rows to span several blocks.
+			n := 2*defaultBlockRecords + 10
+			buf := writeRows(t, codec, n)
+
+			r, err := NewReader(buf)
+			require.NoError(t, err)
+			assert.Equal(t, testSchema.String(), r.Schema().String())
+			assert.Equal(t, []byte("1"), r.Metadata()["app.version"])
+			assert.Equal(t, []byte(codec), r.Metadata()[MetaCodec])
+
+			for i := 0; i < n; i++ {
+				v, err := r.Next()
+				require.NoError(t, err)
+				row := v.(map[string]interface{})
+				assert.Equal(t, int64(i), row["round"])
+				if i%2 == 0 {
+					assert.Equal(t, []byte(fmt.Sprintf("note %d", i)), row["note"])
+				} else {
+					assert.Nil(t, row["note"])
+				}
+			}
+			_, err = r.Next()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestContainerEmpty(t *testing.T) {
+	buf := writeRows(t, CodecDeflate, 0)
+	r, err := NewReader(buf)
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestContainerCorrupt(t *testing.T) {
+	buf := writeRows(t, CodecNull, 3)
+	data := buf.Bytes()
+	// Break the sync marker at the end of the only block.
+	data[len(data)-1] ^= 0xff
+
+	r, err := NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader([]byte("not avro")))
+	assert.Error(t, err)
+}
+
+func TestAppendInvalidRecord(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testSchema, CodecNull, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.Append(map[string]interface{}{"round": 1, "note": nil}))
+	assert.Error(t, w.Append(map[string]interface{}{"round": "x"}))
+	require.NoError(t, w.Append(map[string]interface{}{"round": 2, "note": nil}))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	for _, expected := range []int64{1, 2} {
+		v, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, expected, v.(map[string]interface{})["round"])
+	}
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,183 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrShortBuffer is returned when the input ends in the middle of a value.
+var ErrShortBuffer = errors.New("avro: short buffer")
+
+// Decode decodes one value of schema s from the beginning of buf and returns
+// the value along with the remaining bytes.
+func Decode(s *Schema, buf []byte) (interface{}, []byte, error) {
+	switch s.Type {
+	case Null:
+		return nil, buf, nil
+	case Boolean:
+		if len(buf) < 1 {
+			return nil, buf, ErrShortBuffer
+		}
+		return buf[0] != 0, buf[1:], nil
+	case Int:
+		i, rest, err := readLong(buf)
+		if err != nil {
+			return nil, buf, err
+		}
+		if i < math.MinInt32 || i > math.MaxInt32 {
+			return nil, buf, fmt.Errorf("int out of range: %d", i)
+		}
+		return int32(i), rest, nil
+	case Long:
+		i, rest, err := readLong(buf)
+		if err != nil {
+			return nil, buf, err
+		}
+		return i, rest, nil
+	case Float:
+		if len(buf) < 4 {
+			return nil, buf, ErrShortBuffer
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(buf)), buf[4:], nil
+	case Double:
+		if len(buf) < 8 {
+			return nil, buf, ErrShortBuffer
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf)), buf[8:], nil
+	case Bytes:
+		b, rest, err := readBytes(buf)
+		if err != nil {
+			return nil, buf, err
+		}
+		out := make([]byte, len(b))
+		copy(out, b)
+		return out, rest, nil
+	case String:
+		b, rest, err := readBytes(buf)
+		if err != nil {
+			return nil, buf, err
+		}
+		return string(b), rest, nil
+	case Record:
+		rec := make(map[string]interface{}, len(s.Fields))
+		rest := buf
+		for _, f := range s.Fields {
+			var v interface{}
+			var err error
+			v, rest, err = Decode(f.Type, rest)
+			if err != nil {
+				return nil, buf, fmt.Errorf("%s.%s: %w", s.Name, f.Name, err)
+			}
+			rec[f.Name] = v
+		}
+		return rec, rest, nil
+	case Enum:
+		i, rest, err := readLong(buf)
+		if err != nil {
+			return nil, buf, err
+		}
+		if i < 0 || i >= int64(len(s.Symbols)) {
+			return nil, buf, fmt.Errorf("enum %s index out of range: %d", s.Name, i)
+		}
+		return s.Symbols[i], rest, nil
+	case Array:
+		out := make([]interface{}, 0)
+		rest := buf
+		err := readBlocks(&rest, func() error {
+			var v interface{}
+			var err error
+			v, rest, err = Decode(s.Items, rest)
+			out = append(out, v)
+			return err
+		})
+		if err != nil {
+			return nil, buf, err
+		}
+		return out, rest, nil
+	case Map:
+		out := make(map[string]interface{})
+		rest := buf
+		err := readBlocks(&rest, func() error {
+			k, r, err := readBytes(rest)
+			if err != nil {
+				return err
+			}
+			var v interface{}
+			v, rest, err = Decode(s.Values, r)
+			out[string(k)] = v
+			return err
+		})
+		if err != nil {
+			return nil, buf, err
+		}
+		return out, rest, nil
+	case Fixed:
+		if len(buf) < s.Size {
+			return nil, buf, ErrShortBuffer
+		}
+		out := make([]byte, s.Size)
+		copy(out, buf)
+		return out, buf[s.Size:], nil
+	case Union:
+		i, rest, err := readLong(buf)
+		if err != nil {
+			return nil, buf, err
+		}
+		if i < 0 || i >= int64(len(s.Branches)) {
+			return nil, buf, fmt.Errorf("union index out of range: %d", i)
+		}
+		return Decode(s.Branches[i], rest)
+	}
+	return nil, buf, fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// readBlocks reads the block encoding used by arrays and maps, calling item
+// once per element.
+func readBlocks(buf *[]byte, item func() error) error {
+	for {
+		count, rest, err := readLong(*buf)
+		if err != nil {
+			return err
+		}
+		*buf = rest
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// A negative count is followed by the block size in bytes.
+			count = -count
+			_, rest, err = readLong(*buf)
+			if err != nil {
+				return err
+			}
+			*buf = rest
+		}
+		for i := int64(0); i < count; i++ {
+			err = item()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func readLong(buf []byte) (int64, []byte, error) {
+	u, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, buf, ErrShortBuffer
+	}
+	return int64(u>>1) ^ -int64(u&1), buf[n:], nil
+}
+
+func readBytes(buf []byte) ([]byte, []byte, error) {
+	l, rest, err := readLong(buf)
+	if err != nil {
+		return nil, buf, err
+	}
+	if l < 0 || int64(len(rest)) < l {
+		return nil, buf, ErrShortBuffer
+	}
+	return rest[:l], rest[l:], nil
+}
@@ -0,0 +1,275 @@
+package avro
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Encode returns the binary encoding of v according to schema s.
+func Encode(s *Schema, v interface{}) ([]byte, error) {
+	return AppendEncode(nil, s, v)
+}
+
+// AppendEncode appends the binary encoding of v according to schema s to buf.
+func AppendEncode(buf []byte, s *Schema, v interface{}) ([]byte, error) {
+	switch s.Type {
+	case Null:
+		if v != nil {
+			return buf, fmt.Errorf("expected nil for null, got %T", v)
+		}
+		return buf, nil
+	case Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			return buf, fmt.Errorf("expected bool, got %T", v)
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case Int:
+		i, ok := toInt64(v)
+		if !ok || i < math.MinInt32 || i > math.MaxInt32 {
+			return buf, fmt.Errorf("expected int, got %T(%v)", v, v)
+		}
+		return appendLong(buf, i), nil
+	case Long:
+		i, ok := toLong(v)
+		if !ok {
+			return buf, fmt.Errorf("expected long, got %T(%v)", v, v)
+		}
+		return appendLong(buf, i), nil
+	case Float:
+		f, ok := toFloat64(v)
+		if !ok {
+			return buf, fmt.Errorf("expected float, got %T", v)
+		}
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(f)))
+		return append(buf, b[:]...), nil
+	case Double:
+		f, ok := toFloat64(v)
+		if !ok {
+			return buf, fmt.Errorf("expected double, got %T", v)
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+		return append(buf, b[:]...), nil
+	case Bytes:
+		switch b := v.(type) {
+		case []byte:
+			return appendBytes(buf, b), nil
+		case string:
+			return appendBytes(buf, []byte(b)), nil
+		}
+		return buf, fmt.Errorf("expected bytes, got %T", v)
+	case String:
+		switch str := v.(type) {
+		case string:
+			return appendBytes(buf, []byte(str)), nil
+		case []byte:
+			return appendBytes(buf, str), nil
+		}
+		return buf, fmt.Errorf("expected string, got %T", v)
+	case Record:
+		rec, ok := v.(map[string]interface{})
+		if !ok {
+			return buf, fmt.Errorf("expected map[string]interface{} for record %s, got %T", s.Name, v)
+		}
+		var err error
+		for _, f := range s.Fields {
+			buf, err = AppendEncode(buf, f.Type, rec[f.Name])
+			if err != nil {
+				return buf, fmt.Errorf("%s.%s: %w", s.Name, f.Name, err)
+			}
+		}
+		return buf, nil
+	case Enum:
+		sym, ok := v.(string)
+		if !ok {
+			return buf, fmt.Errorf("expected string for enum %s, got %T", s.Name, v)
+		}
+		for i, candidate := range s.Symbols {
+			if candidate == sym {
+				return appendLong(buf, int64(i)), nil
+			}
+		}
+		return buf, fmt.Errorf("%q is not a symbol of enum %s", sym, s.Name)
+	case Array:
+		rv := reflect.ValueOf(v)
+		if v == nil {
+			return appendLong(buf, 0), nil
+		}
+		if rv.Kind() != reflect.Slice {
+			return buf, fmt.Errorf("expected slice for array, got %T", v)
+		}
+		if rv.Len() > 0 {
+			buf = appendLong(buf, int64(rv.Len()))
+			var err error
+			for i := 0; i < rv.Len(); i++ {
+				buf, err = AppendEncode(buf, s.Items, rv.Index(i).Interface())
+				if err != nil {
+					return buf, fmt.Errorf("[%d]: %w", i, err)
+				}
+			}
+		}
+		return appendLong(buf, 0), nil
+	case Map:
+		if v == nil {
+			return appendLong(buf, 0), nil
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return buf, fmt.Errorf("expected map[string]interface{} for map, got %T", v)
+		}
+		if len(m) > 0 {
+			buf = appendLong(buf, int64(len(m)))
+			var err error
+			for _, k := range sortedKeys(m) {
+				buf = appendBytes(buf, []byte(k))
+				buf, err = AppendEncode(buf, s.Values, m[k])
+				if err != nil {
+					return buf, fmt.Errorf("[%q]: %w", k, err)
+				}
+			}
+		}
+		return appendLong(buf, 0), nil
+	case Fixed:
+		b, ok := v.([]byte)
+		if !ok || len(b) != s.Size {
+			return buf, fmt.Errorf("expected %d bytes for fixed %s, got %T", s.Size, s.Name, v)
+		}
+		return append(buf, b...), nil
+	case Union:
+		idx := s.selectBranch(v)
+		if idx < 0 {
+			return buf, fmt.Errorf("no union branch matches %T", v)
+		}
+		buf = appendLong(buf, int64(idx))
+		return AppendEncode(buf, s.Branches[idx], v)
+	}
+	return buf, fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// selectBranch returns the index of the first union branch that can encode v.
+func (s *Schema) selectBranch(v interface{}) int {
+	for i, b := range s.Branches {
+		if b.accepts(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// accepts is a shallow check for whether v can be encoded with schema s.
+func (s *Schema) accepts(v interface{}) bool {
+	switch s.Type {
+	case Null:
+		return v == nil
+	case Boolean:
+		_, ok := v.(bool)
+		return ok
+	case Int:
+		i, ok := toInt64(v)
+		return ok && i >= math.MinInt32 && i <= math.MaxInt32
+	case Long:
+		_, ok := toLong(v)
+		return ok
+	case Float, Double:
+		_, ok := toFloat64(v)
+		return ok
+	case Bytes:
+		_, ok := v.([]byte)
+		return ok
+	case String, Enum:
+		_, ok := v.(string)
+		return ok
+	case Record, Map:
+		_, ok := v.(map[string]interface{})
+		return ok
+	case Array:
+		if v == nil {
+			return false
+		}
+		rv := reflect.ValueOf(v)
+		_, isBytes := v.([]byte)
+		return rv.Kind() == reflect.Slice && !isBytes
+	case Fixed:
+		b, ok := v.([]byte)
+		return ok && len(b) == s.Size
+	}
+	return false
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch i := v.(type) {
+	case int:
+		return int64(i), true
+	case int8:
+		return int64(i), true
+	case int16:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	case uint:
+		return int64(i), uint64(i) <= math.MaxInt64
+	case uint8:
+		return int64(i), true
+	case uint16:
+		return int64(i), true
+	case uint32:
+		return int64(i), true
+	case uint64:
+		return int64(i), i <= math.MaxInt64
+	}
+	return 0, false
+}
+
+// toLong is like toInt64 but also accepts unsigned values above
+// math.MaxInt64, they are written as their two's complement int64. Readers get
+// the original value back with uint64(i).
+func toLong(v interface{}) (int64, bool) {
+	switch i := v.(type) {
+	case uint:
+		return int64(i), true
+	case uint64:
+		return int64(i), true
+	}
+	return toInt64(v)
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch f := v.(type) {
+	case float32:
+		return float64(f), true
+	case float64:
+		return f, true
+	}
+	return 0, false
+}
+
+// appendLong appends the zig-zag variable length encoding of i.
+func appendLong(buf []byte, i int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], uint64((i<<1)^(i>>63)))
+	return append(buf, b[:n]...)
+}
+
+func appendBytes(buf []byte, b []byte) []byte {
+	buf = appendLong(buf, int64(len(b)))
+	return append(buf, b...)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
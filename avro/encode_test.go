@@ -0,0 +1,145 @@
+package avro
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from the Avro specification.
+func TestEncodePrimitives(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		value    interface{}
+		expected []byte
+	}{
+		{"long zero", `"long"`, int64(0), []byte{0x00}},
+		{"long -1", `"long"`, int64(-1), []byte{0x01}},
+		{"long 1", `"long"`, 1, []byte{0x02}},
+		{"long -64", `"long"`, int64(-64), []byte{0x7f}},
+		{"long 64", `"long"`, uint64(64), []byte{0x80, 0x01}},
+		{"long max uint64", `"long"`, uint64(math.MaxUint64), []byte{0x01}},
+		{"long 1<<63", `"long"`, uint64(1) << 63, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{"int", `"int"`, int32(-2), []byte{0x03}},
+		{"string", `"string"`, "foo", []byte{0x06, 0x66, 0x6f, 0x6f}},
+		{"bytes", `"bytes"`, []byte{1, 2}, []byte{0x04, 0x01, 0x02}},
+		{"boolean", `"boolean"`, true, []byte{0x01}},
+		{"null", `"null"`, nil, []byte{}},
+		{"union null", `["null", "string"]`, nil, []byte{0x00}},
+		{"union string", `["null", "string"]`, "a", []byte{0x02, 0x02, 0x61}},
+		{"array", `{"type": "array", "items": "long"}`, []interface{}{int64(3), int64(27)}, []byte{0x04, 0x06, 0x36, 0x00}},
+		{"typed array", `{"type": "array", "items": "long"}`, []uint64{3, 27}, []byte{0x04, 0x06, 0x36, 0x00}},
+		{"empty array", `{"type": "array", "items": "long"}`, []interface{}{}, []byte{0x00}},
+		{"fixed", `{"type": "fixed", "name": "f", "size": 2}`, []byte{7, 8}, []byte{0x07, 0x08}},
+		{"enum", `{"type": "enum", "name": "e", "symbols": ["a", "b"]}`, "b", []byte{0x02}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseSchema(tc.schema)
+			require.NoError(t, err)
+			buf, err := Encode(s, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, buf)
+		})
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  interface{}
+	}{
+		{"int overflow", `"int"`, int64(1) << 40},
+		{"uint64 int overflow", `"int"`, uint64(math.MaxUint64)},
+		{"string for long", `"long"`, "1"},
+		{"nil for string", `"string"`, nil},
+		{"no union branch", `["null", "long"]`, "x"},
+		{"bad enum symbol", `{"type": "enum", "name": "e", "symbols": ["a"]}`, "b"},
+		{"short fixed", `{"type": "fixed", "name": "f", "size": 2}`, []byte{1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseSchema(tc.schema)
+			require.NoError(t, err)
+			_, err = Encode(s, tc.value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	s := MustParseSchema(`{
+  "type": "record",
+  "name": "Outer",
+  "namespace": "test",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "name", "type": ["null", "string"], "default": null},
+    {"name": "tags", "type": {"type": "map", "values": "bytes"}},
+    {"name": "score", "type": "double"},
+    {"name": "inner", "type": {"type": "record", "name": "Inner", "fields": [
+      {"name": "ok", "type": "boolean"},
+      {"name": "ratio", "type": "float"}
+    ]}},
+    {"name": "others", "type": {"type": "array", "items": "Inner"}}
+  ]
+}`)
+
+	inner := map[string]interface{}{"ok": true, "ratio": float32(0.5)}
+	record := map[string]interface{}{
+		"id":     int64(-12345678901),
+		"name":   "algo",
+		"tags":   map[string]interface{}{"b": []byte{2}, "a": []byte{1}},
+		"score":  float64(3.25),
+		"inner":  inner,
+		"others": []interface{}{inner, inner},
+	}
+
+	buf, err := Encode(s, record)
+	require.NoError(t, err)
+
+	decoded, rest, err := Decode(s, buf)
+	require.NoError(t, err)
+	assert.Empty(t, rest)
+	assert.Equal(t, record["id"], decoded.(map[string]interface{})["id"])
+	assert.Equal(t, record["name"], decoded.(map[string]interface{})["name"])
+	assert.Equal(t, record["tags"], decoded.(map[string]interface{})["tags"])
+	assert.Equal(t, record["score"], decoded.(map[string]interface{})["score"])
+	assert.Equal(t, inner, decoded.(map[string]interface{})["inner"])
+	assert.Equal(t, record["others"], decoded.(map[string]interface{})["others"])
+
+	_, _, err = Decode(s, buf[:len(buf)-1])
+	assert.Error(t, err)
+}
+
+func TestUint64RoundTrip(t *testing.T) {
+	s := MustParseSchema(`["null", "long"]`)
+	for _, v := range []uint64{0, math.MaxInt64, 1 << 63, math.MaxUint64} {
+		buf, err := Encode(s, v)
+		require.NoError(t, err)
+		decoded, _, err := Decode(s, buf)
+		require.NoError(t, err)
+		assert.Equal(t, v, uint64(decoded.(int64)))
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	schemas := []string{
+		`"unknown"`,
+		`{"type": "record", "fields": []}`,
+		`{"type": "record", "name": "r", "fields": [{"name": "a", "type": "long"}, {"name": "a", "type": "long"}]}`,
+		`{"type": "array"}`,
+		`[["null"]]`,
+		`{not json`,
+	}
+	for _, text := range schemas {
+		_, err := ParseSchema(text)
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,308 @@
+// Package avro implements the subset of the Apache Avro 1.x specification
+// needed to write and read Object Container Files: schema parsing, the
+// binary encoding and the "null" and "deflate" codecs.
+//
+// Values are represented generically:
+//
+//	null    -> nil
+//	boolean -> bool
+//	int     -> int32 (any Go integer is accepted when encoding)
+//	long    -> int64 (any Go integer is accepted when encoding, uint64
+//	           values above math.MaxInt64 are written as their two's
+//	           complement, uint64(v) restores them)
+//	float   -> float32
+//	double  -> float64
+//	bytes   -> []byte
+//	string  -> string
+//	record  -> map[string]interface{}
+//	enum    -> string
+//	array   -> []interface{}
+//	map     -> map[string]interface{}
+//	fixed   -> []byte
+//	union   -> the value of the selected branch
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Type is the name of an Avro schema type.
+type Type string
+
+// Avro primitive and complex type names.
+const (
+	Null    Type = "null"
+	Boolean Type = "boolean"
+	Int     Type = "int"
+	Long    Type = "long"
+	Float   Type = "float"
+	Double  Type = "double"
+	Bytes   Type = "bytes"
+	String  Type = "string"
+	Record  Type = "record"
+	Enum    Type = "enum"
+	Array   Type = "array"
+	Map     Type = "map"
+	Fixed   Type = "fixed"
+	Union   Type = "union"
+)
+
+// Field is one field of a record schema.
+type Field struct {
+	Name    string
+	Doc     string
+	Type    *Schema
+	Default json.RawMessage
+}
+
+// HasDefault returns true if the field declares a default value.
+func (f Field) HasDefault() bool {
+	return len(f.Default) > 0
+}
+
+// Schema is a parsed Avro schema.
+type Schema struct {
+	Type Type
+
+	// Name is the full name of a named type (record, enum, fixed).
+	Name string
+	Doc  string
+
+	// Fields of a record.
+	Fields []Field
+	// Symbols of an enum.
+	Symbols []string
+	// Items of an array.
+	Items *Schema
+	// Values of a map.
+	Values *Schema
+	// Branches of a union.
+	Branches []*Schema
+	// Size of a fixed.
+	Size int
+
+	text string
+}
+
+// String returns the JSON text the schema was parsed from.
+func (s *Schema) String() string {
+	return s.text
+}
+
+// Field returns the record field with the given name.
+func (s *Schema) Field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// ParseSchema parses the JSON representation of an Avro schema.
+func ParseSchema(text string) (*Schema, error) {
+	var obj interface{}
+	err := json.Unmarshal([]byte(text), &obj)
+	if err != nil {
+		return nil, fmt.Errorf("ParseSchema() err: %w", err)
+	}
+
+	p := parser{named: make(map[string]*Schema)}
+	s, err := p.parse(obj, "")
+	if err != nil {
+		return nil, fmt.Errorf("ParseSchema() err: %w", err)
+	}
+	s.text = text
+	return s, nil
+}
+
+// MustParseSchema is like ParseSchema but panics on error. It is meant for
+// schemas that are compiled into the binary.
+func MustParseSchema(text string) *Schema {
+	s, err := ParseSchema(text)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type parser struct {
+	named map[string]*Schema
+}
+
+func isPrimitive(t Type) bool {
+	switch t {
+	case Null, Boolean, Int, Long, Float, Double, Bytes, String:
+		return true
+	}
+	return false
+}
+
+func fullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func (p *parser) parse(obj interface{}, namespace string) (*Schema, error) {
+	switch v := obj.(type) {
+	case string:
+		t := Type(v)
+		if isPrimitive(t) {
+			return &Schema{Type: t}, nil
+		}
+		if s, ok := p.named[fullName(v, namespace)]; ok {
+			return s, nil
+		}
+		if s, ok := p.named[v]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", v)
+	case []interface{}:
+		s := &Schema{Type: Union}
+		for _, b := range v {
+			branch, err := p.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if branch.Type == Union {
+				return nil, fmt.Errorf("union may not immediately contain a union")
+			}
+			s.Branches = append(s.Branches, branch)
+		}
+		return s, nil
+	case map[string]interface{}:
+		return p.parseObject(v, namespace)
+	default:
+		return nil, fmt.Errorf("unexpected schema element %v", obj)
+	}
+}
+
+func stringAttr(obj map[string]interface{}, key string) string {
+	if s, ok := obj[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+func (p *parser) parseObject(obj map[string]interface{}, namespace string) (*Schema, error) {
+	typ, ok := obj["type"]
+	if !ok {
+		return nil, fmt.Errorf("schema object has no type")
+	}
+	typeName, ok := typ.(string)
+	if !ok {
+		// e.g. {"type": {"type": "array", ...}}
+		return p.parse(typ, namespace)
+	}
+
+	t := Type(typeName)
+	switch t {
+	case Record, Enum, Fixed:
+		name := stringAttr(obj, "name")
+		if name == "" {
+			return nil, fmt.Errorf("%s has no name", t)
+		}
+		if ns := stringAttr(obj, "namespace"); ns != "" {
+			namespace = ns
+		}
+		name = fullName(name, namespace)
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			namespace = name[:idx]
+		}
+		if _, ok := p.named[name]; ok {
+			return nil, fmt.Errorf("type %q defined twice", name)
+		}
+		s := &Schema{Type: t, Name: name, Doc: stringAttr(obj, "doc")}
+		p.named[name] = s
+
+		switch t {
+		case Record:
+			fields, ok := obj["fields"].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record %q has no fields", name)
+			}
+			seen := make(map[string]bool)
+			for _, f := range fields {
+				fobj, ok := f.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("record %q has a malformed field", name)
+				}
+				field := Field{
+					Name: stringAttr(fobj, "name"),
+					Doc:  stringAttr(fobj, "doc"),
+				}
+				if field.Name == "" {
+					return nil, fmt.Errorf("record %q has a field without a name", name)
+				}
+				if seen[field.Name] {
+					return nil, fmt.Errorf("record %q has duplicate field %q", name, field.Name)
+				}
+				seen[field.Name] = true
+				ftype, ok := fobj["type"]
+				if !ok {
+					return nil, fmt.Errorf("field %q of %q has no type", field.Name, name)
+				}
+				var err error
+				field.Type, err = p.parse(ftype, namespace)
+				if err != nil {
+					return nil, fmt.Errorf("field %q of %q: %w", field.Name, name, err)
+				}
+				if def, ok := fobj["default"]; ok {
+					field.Default, err = json.Marshal(def)
+					if err != nil {
+						return nil, err
+					}
+				}
+				s.Fields = append(s.Fields, field)
+			}
+		case Enum:
+			symbols, ok := obj["symbols"].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("enum %q has no symbols", name)
+			}
+			for _, sym := range symbols {
+				str, ok := sym.(string)
+				if !ok {
+					return nil, fmt.Errorf("enum %q has a non-string symbol", name)
+				}
+				s.Symbols = append(s.Symbols, str)
+			}
+		case Fixed:
+			size, ok := obj["size"].(float64)
+			if !ok || size < 0 {
+				return nil, fmt.Errorf("fixed %q has no valid size", name)
+			}
+			s.Size = int(size)
+		}
+		return s, nil
+	case Array:
+		items, ok := obj["items"]
+		if !ok {
+			return nil, fmt.Errorf("array has no items")
+		}
+		itemSchema, err := p.parse(items, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: Array, Items: itemSchema}, nil
+	case Map:
+		values, ok := obj["values"]
+		if !ok {
+			return nil, fmt.Errorf("map has no values")
+		}
+		valueSchema, err := p.parse(values, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: Map, Values: valueSchema}, nil
+	default:
+		if isPrimitive(t) {
+			return &Schema{Type: t}, nil
+		}
+		return p.parse(typeName, namespace)
+	}
+}
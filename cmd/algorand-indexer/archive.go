@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/algorand/go-algorand/rpcs"
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/config"
+	"github.com/algorand/indexer/fetcher"
+	"github.com/algorand/indexer/importer"
+)
+
+var (
+	archiveDir    string
+	archiveRounds uint64
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "write blocks from algod to tar files",
+	Long:  "follow algod and write every block to gzip compressed tar files of round ranges that the import command reads. Resumes after the last archived round.",
+	Run: func(cmd *cobra.Command, args []string) {
+		config.BindFlags(cmd)
+		err := configureLogger()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to configure logger: %v", err)
+			os.Exit(1)
+		}
+
+		if algodDataDir == "" {
+			algodDataDir = os.Getenv("ALGORAND_DATA")
+		}
+
+		ctx, cf := context.WithCancel(context.Background())
+		defer cf()
+		{
+			cancelCh := make(chan os.Signal, 1)
+			signal.Notify(cancelCh, syscall.SIGTERM, syscall.SIGINT)
+			go func() {
+				<-cancelCh
+				logger.Println("Stopping archiver.")
+				cf()
+			}()
+		}
+
+		var bot fetcher.Fetcher
+		if algodAddr != "" && algodToken != "" {
+			bot, err = fetcher.ForNetAndToken(algodAddr, algodToken, logger)
+			maybeFail(err, "fetcher setup, %v", err)
+		} else if algodDataDir != "" {
+			bot, err = fetcher.ForDataDir(algodDataDir, logger)
+			maybeFail(err, "fetcher setup, %v", err)
+		} else {
+			maybeFail(fmt.Errorf("no algod configured"), "the archiver requires algod")
+		}
+
+		archiverOpts := importer.ArchiverOptions{
+			Dir:           archiveDir,
+			RoundsPerFile: archiveRounds,
+		}
+		if bucket := s3ClientFromFlags(); bucket != nil {
+			// Upload the files published before a restart, then every new one.
+			err = os.MkdirAll(archiveDir, 0755)
+			maybeFail(err, "archiver setup, %v", err)
+			err = syncToS3(ctx, bucket, archiveDir)
+			maybeFail(err, "upload failed")
+			archiverOpts.OnPublish = func(path string) error {
+				return syncToS3(ctx, bucket, archiveDir)
+			}
+		}
+		archiver, err := importer.MakeArchiver(archiverOpts)
+		maybeFail(err, "archiver setup, %v", err)
+		defer archiver.Close()
+
+		nextRound, err := archiver.NextRound()
+		maybeFail(err, "failed to get next round, %v", err)
+		bot.SetNextRound(nextRound)
+
+		bot.SetBlockHandler(func(ctx context.Context, block *rpcs.EncodedBlockCert) error {
+			err := archiver.HandleBlock(ctx, block)
+			if err != nil {
+				logger.WithError(err).Errorf("archiving block %d failed", block.Block.Round())
+				return fmt.Errorf("archive handler err: %w", err)
+			}
+			logger.Debugf("round r=%d (%d txn) archived", block.Block.Round(), len(block.Block.Payset))
+			return nil
+		})
+
+		logger.Infof("Starting archiver at round %d in %s.", nextRound, archiveDir)
+		err = bot.Run(ctx)
+		if err != nil && ctx.Err() == nil {
+			archiver.Close()
+			maybeFail(err, "fetcher exited with error")
+		}
+	},
+}
+
+func init() {
+	archiveCmd.Flags().StringVarP(&algodDataDir, "algod", "d", "", "path to algod data dir, or $ALGORAND_DATA")
+	archiveCmd.Flags().StringVarP(&algodAddr, "algod-net", "", "", "host:port of algod")
+	archiveCmd.Flags().StringVarP(&algodToken, "algod-token", "", "", "api access token for algod")
+	archiveCmd.Flags().StringVarP(&archiveDir, "dir", "", "", "directory of the archive files")
+	archiveCmd.Flags().Uint64VarP(&archiveRounds, "rounds-per-file", "", importer.DefaultArchiveRoundsPerFile, "number of rounds stored in one archive file")
+	addS3Flags(archiveCmd)
+	archiveCmd.MarkFlagRequired("dir")
+}
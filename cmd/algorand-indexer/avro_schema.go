@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/exporter/schema"
+)
+
+var (
+	avroSchemaTable       string
+	avroSchemaFingerprint bool
+	avroSchemaCheckDir    string
+)
+
+var avroSchemaCmd = &cobra.Command{
+	Use:   "avro-schema",
+	Short: "print or check the avro export schemas",
+	Long:  "print the avro schemas used by export-avro and the avro sink, or check that they are backward compatible with the files of a previous export.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if avroSchemaCheckDir != "" {
+			err := schema.CheckDir(avroSchemaCheckDir)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("schemas are backward compatible with %s\n", avroSchemaCheckDir)
+			return
+		}
+
+		tables := schema.Tables()
+		if avroSchemaTable != "" {
+			tables = []string{avroSchemaTable}
+		}
+		for _, table := range tables {
+			s, ok := schema.ForTable(table)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "unknown table %s\n", table)
+				os.Exit(1)
+			}
+			if avroSchemaFingerprint {
+				fmt.Printf("%s %s\n", table, schema.Fingerprint(s))
+			} else {
+				fmt.Println(s.String())
+			}
+		}
+	},
+}
+
+func init() {
+	avroSchemaCmd.Flags().StringVarP(&avroSchemaTable, "table", "", "", "only print the schema of this table")
+	avroSchemaCmd.Flags().BoolVarP(&avroSchemaFingerprint, "fingerprint", "", false, "print the SHA-256 fingerprint of the canonical form instead of the schema")
+	avroSchemaCmd.Flags().StringVarP(&avroSchemaCheckDir, "check-dir", "", "", "check compatibility with the latest files in this export directory")
+}
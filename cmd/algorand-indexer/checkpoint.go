@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/config"
+	"github.com/algorand/indexer/idb"
+)
+
+var checkpointRewindRound uint64
+
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "inspect and change export checkpoints",
+	Long:  "inspect and change the checkpoints of the exporters, which record the last round durably written by each named exporter and the files it published. Stop the exporter before changing its checkpoint.",
+}
+
+// openCheckpointDb opens the database for the checkpoint commands.
+func openCheckpointDb(cmd *cobra.Command, readOnly bool) idb.IndexerDb {
+	config.BindFlags(cmd)
+	err := configureLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to configure logger: %v", err)
+		os.Exit(1)
+	}
+
+	db, availableCh := indexerDbFromFlags(idb.IndexerDbOptions{ReadOnly: readOnly})
+	<-availableCh
+	return db
+}
+
+func printCheckpoint(name string, checkpoint idb.ExportCheckpoint) {
+	fmt.Printf("%s: next round %d, %d files\n", name, checkpoint.NextRound, len(checkpoint.Files))
+}
+
+var checkpointShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "show export checkpoints",
+	Long:  "show the checkpoint of the named exporter with its files, or of all the exporters.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openCheckpointDb(cmd, true)
+		defer db.Close()
+
+		if len(args) == 1 {
+			checkpoint, err := db.GetExportCheckpoint(args[0])
+			maybeFail(err, "failed to get checkpoint %s, %v", args[0], err)
+			printCheckpoint(args[0], checkpoint)
+			for _, f := range checkpoint.Files {
+				fmt.Printf("  %s: rounds %d to %d\n", f.Path, f.FirstRound, f.LastRound)
+			}
+			return
+		}
+
+		checkpoints, err := db.GetExportCheckpoints()
+		maybeFail(err, "failed to get checkpoints, %v", err)
+		names := make([]string, 0, len(checkpoints))
+		for name := range checkpoints {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			printCheckpoint(name, checkpoints[name])
+		}
+	},
+}
+
+var checkpointResetCmd = &cobra.Command{
+	Use:   "reset name",
+	Short: "remove an export checkpoint",
+	Long:  "remove the checkpoint of the named exporter, which starts again at its --first-round. The exported files are not removed.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openCheckpointDb(cmd, false)
+		defer db.Close()
+
+		err := db.DeleteExportCheckpoint(args[0])
+		maybeFail(err, "failed to reset checkpoint %s, %v", args[0], err)
+		logger.Infof("reset checkpoint %s", args[0])
+	},
+}
+
+var checkpointRewindCmd = &cobra.Command{
+	Use:   "rewind name",
+	Short: "move an export checkpoint back",
+	Long:  "move the checkpoint of the named exporter back so that it exports --round again. Files that hold --round or later rounds are removed from the checkpoint, so the exporter may restart before --round at the first round of such a file. The files are listed but not removed, the exporter overwrites them.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openCheckpointDb(cmd, false)
+		defer db.Close()
+
+		name := args[0]
+		checkpoint, err := db.GetExportCheckpoint(name)
+		maybeFail(err, "failed to get checkpoint %s, %v", name, err)
+
+		removed := checkpoint.Rewind(checkpointRewindRound)
+		err = db.SetExportCheckpoint(name, checkpoint)
+		maybeFail(err, "failed to rewind checkpoint %s, %v", name, err)
+		for _, f := range removed {
+			logger.Infof("removed file %s from checkpoint %s", f.Path, name)
+		}
+		logger.Infof("checkpoint %s is at round %d", name, checkpoint.NextRound)
+	},
+}
+
+func init() {
+	checkpointRewindCmd.Flags().Uint64VarP(&checkpointRewindRound, "round", "r", 0, "first round to export again")
+	checkpointRewindCmd.MarkFlagRequired("round")
+
+	checkpointCmd.AddCommand(checkpointShowCmd)
+	checkpointCmd.AddCommand(checkpointResetCmd)
+	checkpointCmd.AddCommand(checkpointRewindCmd)
+}
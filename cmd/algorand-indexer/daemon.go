@@ -10,15 +10,20 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
 	"github.com/algorand/go-algorand/rpcs"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/algorand/indexer/api"
+	"github.com/algorand/indexer/avro"
 	"github.com/algorand/indexer/config"
+	"github.com/algorand/indexer/exporter"
 	"github.com/algorand/indexer/fetcher"
 	"github.com/algorand/indexer/idb"
 	"github.com/algorand/indexer/importer"
+	"github.com/algorand/indexer/kafka"
 	"github.com/algorand/indexer/util/metrics"
 )
 
@@ -34,6 +39,23 @@ var (
 	tokenString      string
 	writeTimeout     time.Duration
 	readTimeout      time.Duration
+	avroSinkDir      string
+	avroSinkRounds   uint64
+	avroSinkCodec    string
+	deltaDir         string
+	deltaFormat      string
+	deltaRounds      uint64
+	deltaCodec       string
+	rollbackRounds   uint64
+	partitionRounds  uint64
+	retentionRounds  uint64
+	retentionTime    time.Duration
+	kafkaBrokers     []string
+	kafkaTopic       string
+	kafkaFormat      string
+	kafkaCheckpoint  string
+	kafkaRetries     int
+	webhookToken     string
 )
 
 var daemonCmd = &cobra.Command{
@@ -79,14 +101,62 @@ var daemonCmd = &cobra.Command{
 			// no algod was found
 			noAlgod = true
 		}
-		opts := idb.IndexerDbOptions{}
+
+		if avroSinkDir != "" {
+			if bot == nil {
+				maybeFail(fmt.Errorf("no algod configured"), "the avro sink requires algod")
+			}
+			runAvroSink(ctx, bot)
+			return
+		}
+
+		opts := idb.IndexerDbOptions{MaxRollbackRounds: rollbackRounds, PartitionRounds: partitionRounds}
 		if noAlgod && !allowMigration {
 			opts.ReadOnly = true
 		}
+		var deltaWriter *exporter.DeltaWriter
+		if deltaDir != "" {
+			if bot == nil {
+				maybeFail(fmt.Errorf("no algod configured"), "the state delta stream requires algod")
+			}
+			deltaWriter, err = exporter.MakeDeltaWriter(exporter.DeltaOptions{
+				Dir:           deltaDir,
+				RoundsPerFile: deltaRounds,
+				Format:        exporter.Format(deltaFormat),
+				Codec:         avro.Codec(deltaCodec),
+			})
+			maybeFail(err, "state delta stream setup, %v", err)
+			opts.StateDeltaHandler = func(round basics.Round, delta *ledgercore.StateDelta) error {
+				// The round is not committed if this fails, the fetcher
+				// retries it.
+				err := deltaWriter.WriteDelta(round, delta)
+				if err != nil {
+					return fmt.Errorf("failed to write the state delta of round %d: %w", round, err)
+				}
+				return nil
+			}
+		}
+		if len(kafkaBrokers) > 0 && bot == nil {
+			maybeFail(fmt.Errorf("no algod configured"), "the kafka event stream requires algod")
+		}
+		if webhookToken != "" && bot == nil {
+			maybeFail(fmt.Errorf("no algod configured"), "webhooks require algod")
+		}
+		if (retentionRounds > 0 || retentionTime > 0) && bot == nil {
+			maybeFail(fmt.Errorf("no algod configured"), "transaction retention requires algod")
+		}
 		db, availableCh := indexerDbFromFlags(opts)
 		defer db.Close()
 		var wg sync.WaitGroup
+		fetcherFailed := false
+		options := makeOptions()
 		if bot != nil {
+			// Wakes up the transaction streams of the API after each round.
+			options.RoundNotifier = api.MakeRoundNotifier()
+			if webhookToken != "" {
+				options.Webhooks = api.MakeWebhooks(db, options.RoundNotifier, logger)
+				options.AdminTokens = []string{webhookToken}
+			}
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
@@ -101,11 +171,46 @@ var daemonCmd = &cobra.Command{
 
 				nextRound, err := db.GetNextRoundToAccount()
 				maybeFail(err, "failed to get next round, %v", err)
+				if deltaWriter != nil {
+					checkDeltaStream(deltaWriter, nextRound)
+				}
 				bot.SetNextRound(nextRound)
 
+				if options.Webhooks != nil {
+					go options.Webhooks.Run(ctx)
+				}
+
+				if retentionRounds > 0 || retentionTime > 0 {
+					pruner, err := importer.MakePruner(db, importer.PrunerOptions{
+						Rounds:   retentionRounds,
+						Duration: retentionTime,
+					}, logger)
+					maybeFail(err, "transaction retention setup, %v", err)
+					go pruner.Run(ctx)
+				}
+
+				var events *exporter.EventStream
+				if len(kafkaBrokers) > 0 {
+					events = startEventStream(ctx, db, nextRound)
+				}
+
 				imp := importer.NewImporter(db)
 				handler := func(ctx context.Context, block *rpcs.EncodedBlockCert) error {
-					return handleBlock(block, &imp)
+					err := handleBlock(block, &imp)
+					if err != nil {
+						return err
+					}
+					options.RoundNotifier.Notify()
+					if events == nil {
+						return nil
+					}
+					// The round is committed, a failure stops the daemon and the
+					// next start publishes the round again.
+					err = events.Publish(ctx, uint64(block.Block.Round())+1)
+					if err != nil {
+						logger.WithError(err).Errorf("publishing round %d to kafka failed", block.Block.Round())
+					}
+					return err
 				}
 				bot.SetBlockHandler(handler)
 
@@ -115,7 +220,8 @@ var daemonCmd = &cobra.Command{
 					// If context is not expired.
 					if ctx.Err() == nil {
 						logger.WithError(err).Errorf("fetcher exited with error")
-						os.Exit(1)
+						fetcherFailed = true
+						cf()
 					}
 				}
 			}()
@@ -125,8 +231,15 @@ var daemonCmd = &cobra.Command{
 
 		fmt.Printf("serving on %s\n", daemonServerAddr)
 		logger.Infof("serving on %s", daemonServerAddr)
-		api.Serve(ctx, daemonServerAddr, db, bot, logger, makeOptions())
+		api.Serve(ctx, daemonServerAddr, db, bot, logger, options)
 		wg.Wait()
+		if deltaWriter != nil {
+			publishDeltaStream(deltaWriter, db)
+		}
+		if fetcherFailed {
+			db.Close()
+			os.Exit(1)
+		}
 	},
 }
 
@@ -143,6 +256,23 @@ func init() {
 	daemonCmd.Flags().StringVarP(&metricsMode, "metrics-mode", "", "OFF", "configure the /metrics endpoint to [ON, OFF, VERBOSE]")
 	daemonCmd.Flags().DurationVarP(&writeTimeout, "write-timeout", "", 30*time.Second, "set the maximum duration to wait before timing out writes to a http response, breaking connection")
 	daemonCmd.Flags().DurationVarP(&readTimeout, "read-timeout", "", 5*time.Second, "set the maximum duration for reading the entire request")
+	daemonCmd.Flags().StringVarP(&avroSinkDir, "avro-sink-dir", "", "", "write blocks from algod to rolling avro files in this directory instead of importing them into the database, the API is not served in this mode")
+	daemonCmd.Flags().Uint64VarP(&avroSinkRounds, "avro-sink-rounds-per-file", "", 100, "number of rounds stored in one avro sink file")
+	daemonCmd.Flags().StringVarP(&avroSinkCodec, "avro-sink-codec", "", string(avro.CodecDeflate), "avro sink block compression codec: [null, deflate]")
+	daemonCmd.Flags().StringVarP(&deltaDir, "state-delta-dir", "", "", "also write the account state changes of every imported round to a change stream in this directory")
+	daemonCmd.Flags().StringVarP(&deltaFormat, "state-delta-format", "", string(exporter.FormatAvro), "state delta stream file format: [avro, ndjson]")
+	daemonCmd.Flags().Uint64VarP(&deltaRounds, "state-delta-rounds-per-file", "", 100, "maximum number of rounds stored in one state delta file")
+	daemonCmd.Flags().StringVarP(&deltaCodec, "state-delta-codec", "", string(avro.CodecDeflate), "state delta avro block compression codec: [null, deflate]")
+	daemonCmd.Flags().Uint64VarP(&rollbackRounds, "max-rollback-rounds", "", 0, "keep undo records of this many most recent rounds, so that the rollback command can remove them")
+	daemonCmd.Flags().Uint64VarP(&partitionRounds, "partition-rounds", "", 0, "partition the postgres txn and txn_participation tables by ranges of this many rounds, an existing database is converted by a migration; 0 leaves them unpartitioned")
+	daemonCmd.Flags().Uint64VarP(&retentionRounds, "retention-rounds", "", 0, "keep the transactions of this many most recent rounds and remove older ones in the background, account state is kept; 0 keeps all transactions")
+	daemonCmd.Flags().DurationVarP(&retentionTime, "retention-duration", "", 0, "keep the transactions of the rounds of this recent duration, e.g. 720h, and remove older ones in the background; with --retention-rounds a round is kept while it is within either window")
+	daemonCmd.Flags().StringSliceVarP(&kafkaBrokers, "kafka-brokers", "", nil, "also publish the transactions of every imported round to kafka, comma separated host:port of the brokers")
+	daemonCmd.Flags().StringVarP(&kafkaTopic, "kafka-topic", "", "algorand-txn", "kafka topic of the event stream")
+	daemonCmd.Flags().StringVarP(&kafkaFormat, "kafka-format", "", string(exporter.FormatAvro), "kafka message payload format: [avro, json]")
+	daemonCmd.Flags().StringVarP(&kafkaCheckpoint, "kafka-checkpoint", "", "kafka", "name of the export checkpoint recording the rounds published to kafka")
+	daemonCmd.Flags().IntVarP(&kafkaRetries, "kafka-retries", "", 10, "number of times a failed kafka request is retried, with exponential backoff")
+	daemonCmd.Flags().StringVarP(&webhookToken, "webhook-admin-token", "", "", "deliver the transactions matching the stored webhooks after they are imported, and serve the webhook admin API which requires this token in a bearer format, or in a 'X-Indexer-Admin-Token' header")
 
 	viper.RegisterAlias("algod", "algod-data-dir")
 	viper.RegisterAlias("algod-net", "algod-address")
@@ -174,6 +304,123 @@ func makeOptions() (options api.ExtraOptions) {
 	return
 }
 
+// startEventStream connects the kafka event stream and publishes the rounds
+// imported since its checkpoint. A stream without a checkpoint starts with
+// `nextRound`.
+func startEventStream(ctx context.Context, db idb.IndexerDb, nextRound uint64) *exporter.EventStream {
+	producer, err := kafka.MakeProducer(kafka.ProducerOptions{
+		Brokers:    kafkaBrokers,
+		Topic:      kafkaTopic,
+		ClientID:   "algorand-indexer",
+		MaxRetries: kafkaRetries,
+	})
+	maybeFail(err, "kafka producer setup, %v", err)
+	events, err := exporter.MakeEventStream(db, producer, exporter.EventOptions{
+		Format:     exporter.Format(kafkaFormat),
+		Checkpoint: kafkaCheckpoint,
+	})
+	maybeFail(err, "kafka event stream setup, %v", err)
+
+	err = events.Resume(nextRound)
+	maybeFail(err, "failed to resume the kafka event stream, %v", err)
+	if events.NextRound() > nextRound {
+		logger.Warnf("kafka event stream is at round %d, rounds %d to %d are not published again", events.NextRound(), nextRound, events.NextRound()-1)
+	}
+	if events.NextRound() < nextRound {
+		logger.Infof("publishing rounds %d to %d to kafka", events.NextRound(), nextRound-1)
+	}
+	err = events.Publish(ctx, nextRound)
+	maybeFail(err, "failed to publish to kafka, %v", err)
+	return events
+}
+
+// deltaStreamRound returns the round of the state delta stream that follows
+// the database import. Block 0 has no state delta, round 0 of the stream is the
+// genesis.
+func deltaStreamRound(nextRound uint64) uint64 {
+	if nextRound == 0 {
+		return 1
+	}
+	return nextRound
+}
+
+// checkDeltaStream makes sure that the state delta stream continues where the
+// database import continues, recovering the rounds written before a crash.
+func checkDeltaStream(deltaWriter *exporter.DeltaWriter, nextRound uint64) {
+	err := deltaWriter.Resume(deltaStreamRound(nextRound))
+	maybeFail(err, "state delta stream in %s does not continue where the database import does, %v", deltaDir, err)
+
+	streamNext, err := deltaWriter.NextRound()
+	maybeFail(err, "failed to get next state delta round, %v", err)
+	if streamNext == 0 && nextRound > 0 {
+		logger.Warnf("state delta stream in %s starts at round %d, earlier rounds are not part of it", deltaDir, nextRound)
+	}
+}
+
+// publishDeltaStream publishes the state delta files of the rounds committed to
+// the database when the daemon stops.
+func publishDeltaStream(deltaWriter *exporter.DeltaWriter, db idb.IndexerDb) {
+	nextRound, err := db.GetNextRoundToAccount()
+	if err == nil {
+		err = deltaWriter.Publish(deltaStreamRound(nextRound))
+	}
+	if err != nil {
+		logger.WithError(err).Error("failed to publish the state delta stream, it is resumed on the next start")
+	}
+	err = deltaWriter.Close()
+	if err != nil {
+		logger.WithError(err).Error("failed to close the state delta stream")
+	}
+}
+
+// runAvroSink follows algod and writes every block to avro files, without
+// touching the database.
+func runAvroSink(ctx context.Context, bot fetcher.Fetcher) {
+	sink, err := exporter.MakeSink(exporter.Options{
+		Dir:           avroSinkDir,
+		RoundsPerFile: avroSinkRounds,
+		Codec:         avro.Codec(avroSinkCodec),
+	})
+	maybeFail(err, "avro sink setup, %v", err)
+	defer sink.Close()
+
+	nextRound, err := sink.NextRound()
+	maybeFail(err, "failed to get next round, %v", err)
+	bot.SetNextRound(nextRound)
+
+	bot.SetBlockHandler(func(ctx context.Context, block *rpcs.EncodedBlockCert) error {
+		return handleBlockAvro(ctx, block, sink)
+	})
+
+	logger.Infof("Starting avro sink at round %d in %s.", nextRound, avroSinkDir)
+	err = bot.Run(ctx)
+	if err != nil && ctx.Err() == nil {
+		sink.Close()
+		maybeFail(err, "fetcher exited with error")
+	}
+}
+
+func handleBlockAvro(ctx context.Context, block *rpcs.EncodedBlockCert, sink *exporter.Sink) error {
+	start := time.Now()
+	err := sink.HandleBlock(ctx, block)
+	if err != nil {
+		logger.WithError(err).Errorf(
+			"writing block %d to avro failed", block.Block.Round())
+		return fmt.Errorf("handleBlockAvro() err: %w", err)
+	}
+	dt := time.Since(start)
+
+	if block.Block.Round() > 0 {
+		metrics.BlockImportTimeSeconds.Observe(dt.Seconds())
+		metrics.ImportedTxnsPerBlock.Observe(float64(len(block.Block.Payset)))
+		metrics.ImportedRoundGauge.Set(float64(block.Block.Round()))
+	}
+
+	logger.Infof("round r=%d (%d txn) written to avro in %s", block.Block.Round(), len(block.Block.Payset), dt.String())
+
+	return nil
+}
+
 func handleBlock(block *rpcs.EncodedBlockCert, imp *importer.Importer) error {
 	start := time.Now()
 	err := imp.ImportBlock(block)
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/config"
+	"github.com/algorand/indexer/exporter"
+	"github.com/algorand/indexer/idb"
+)
+
+var (
+	exportDir           string
+	exportFirstRound    uint64
+	exportLastRound     int64
+	exportRoundsPerFile uint64
+	exportCodec         string
+	exportPreviousDir   string
+	exportCheckpoint    string
+)
+
+var exportAvroCmd = &cobra.Command{
+	Use:   "export-avro",
+	Short: "export blocks to avro files",
+	Long:  "export block headers, transactions and transaction participation from the database to Avro Object Container Files. Files are split by round range, one directory per table. Ranges are aligned to multiples of --rounds-per-file and clipped to --first-round and --last-round, so an export that starts or ends inside a range writes a file named after the rounds it holds. An existing file for the same rounds is overwritten.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config.BindFlags(cmd)
+		err := configureLogger()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to configure logger: %v", err)
+			os.Exit(1)
+		}
+
+		ctx, cf := context.WithCancel(context.Background())
+		defer cf()
+		{
+			cancelCh := make(chan os.Signal, 1)
+			signal.Notify(cancelCh, syscall.SIGTERM, syscall.SIGINT)
+			go func() {
+				<-cancelCh
+				logger.Println("Stopping export.")
+				cf()
+			}()
+		}
+
+		// The checkpoint is stored in the database.
+		db, availableCh := indexerDbFromFlags(idb.IndexerDbOptions{ReadOnly: exportCheckpoint == ""})
+		defer db.Close()
+		<-availableCh
+
+		bucket := s3ClientFromFlags()
+
+		var checkpoint idb.ExportCheckpoint
+		if exportCheckpoint != "" {
+			checkpoint, err = exporter.LoadCheckpoint(db, exportCheckpoint, exportFirstRound)
+			maybeFail(err, "failed to load checkpoint %s, %v", exportCheckpoint, err)
+			exportFirstRound = checkpoint.NextRound
+		}
+
+		last := uint64(exportLastRound)
+		if exportLastRound < 0 {
+			nextRound, err := db.GetNextRoundToAccount()
+			maybeFail(err, "failed to get next round, %v", err)
+			if nextRound == 0 {
+				logger.Info("nothing to export")
+				return
+			}
+			last = nextRound - 1
+		}
+		if exportCheckpoint != "" && last < exportFirstRound {
+			logger.Infof("checkpoint %s is at round %d, nothing to export", exportCheckpoint, exportFirstRound)
+			return
+		}
+		if last < exportFirstRound {
+			maybeFail(fmt.Errorf("last round %d is before first round %d", last, exportFirstRound), "invalid round range")
+		}
+
+		writer, err := exporter.MakeWriter(exporter.Options{
+			Dir:           exportDir,
+			RoundsPerFile: exportRoundsPerFile,
+			FirstRound:    exportFirstRound,
+			LastRound:     &last,
+			Codec:         avro.Codec(exportCodec),
+			PreviousDir:   exportPreviousDir,
+		})
+		maybeFail(err, "failed to create writer, %v", err)
+
+		logger.Infof("exporting rounds %d to %d into %s", exportFirstRound, last, exportDir)
+		if exportCheckpoint != "" {
+			err = exporter.ExportRoundsWithCheckpoint(ctx, db, writer, exportCheckpoint, &checkpoint, last, logger)
+			maybeFail(err, "export failed")
+			if bucket != nil {
+				err = syncToS3(ctx, bucket, exportDir)
+				maybeFail(err, "upload failed")
+			}
+			logger.Infof("export finished, checkpoint %s is at round %d", exportCheckpoint, checkpoint.NextRound)
+			return
+		}
+		err = exporter.ExportRounds(ctx, db, writer, exportFirstRound, last, logger)
+		if err != nil {
+			writer.Abort()
+			maybeFail(err, "export failed")
+		}
+		err = writer.Close()
+		maybeFail(err, "failed to close output files")
+		if bucket != nil {
+			err = syncToS3(ctx, bucket, exportDir)
+			maybeFail(err, "upload failed")
+		}
+		logger.Info("export finished")
+	},
+}
+
+func init() {
+	exportAvroCmd.Flags().StringVarP(&exportDir, "output", "o", "", "output directory")
+	exportAvroCmd.Flags().Uint64VarP(&exportFirstRound, "first-round", "", 0, "first round to export")
+	exportAvroCmd.Flags().Int64VarP(&exportLastRound, "last-round", "", -1, "last round to export, defaults to the last round in the database")
+	exportAvroCmd.Flags().Uint64VarP(&exportRoundsPerFile, "rounds-per-file", "", exporter.DefaultRoundsPerFile, "number of rounds stored in one file")
+	exportAvroCmd.Flags().StringVarP(&exportCodec, "codec", "", string(avro.CodecDeflate), "avro block compression codec: [null, deflate]")
+	exportAvroCmd.Flags().StringVarP(&exportPreviousDir, "previous-dir", "", "", "directory of a previous export whose schemas must stay readable, defaults to the output directory")
+	exportAvroCmd.Flags().StringVarP(&exportCheckpoint, "checkpoint", "", "", "name of the export checkpoint stored in the database, the export resumes after the last recorded round instead of --first-round")
+	addS3Flags(exportAvroCmd)
+	exportAvroCmd.MarkFlagRequired("output")
+}
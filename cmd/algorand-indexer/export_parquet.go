@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/config"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/postgres"
+	"github.com/algorand/indexer/parquet"
+)
+
+var (
+	parquetDir           string
+	parquetFirstRound    uint64
+	parquetLastRound     int64
+	parquetRoundsPerFile uint64
+	parquetCodec         string
+)
+
+var exportParquetCmd = &cobra.Command{
+	Use:   "export-parquet",
+	Short: "export tables to parquet files",
+	Long:  "export the transaction and account tables from the database to Parquet files for analytics, one directory per table. Transactions and transaction participation are split by round range, aligned to multiples of --rounds-per-file. Accounts, asset holdings, assets, applications and application local states are written as one snapshot of the current state. Existing files with the same name are overwritten.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config.BindFlags(cmd)
+		err := configureLogger()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to configure logger: %v", err)
+			os.Exit(1)
+		}
+		if postgresAddr == "" {
+			logger.Errorf("export-parquet requires a postgres database")
+			os.Exit(1)
+		}
+
+		ctx, cf := context.WithCancel(context.Background())
+		defer cf()
+		{
+			cancelCh := make(chan os.Signal, 1)
+			signal.Notify(cancelCh, syscall.SIGTERM, syscall.SIGINT)
+			go func() {
+				<-cancelCh
+				logger.Println("Stopping export.")
+				cf()
+			}()
+		}
+
+		db, availableCh, err := postgres.OpenPostgres(postgresAddr, idb.IndexerDbOptions{ReadOnly: true}, logger)
+		maybeFail(err, "could not init db, %v", err)
+		defer db.Close()
+		<-availableCh
+
+		bucket := s3ClientFromFlags()
+
+		opts := postgres.ParquetExportOptions{
+			Dir:           parquetDir,
+			RoundsPerFile: parquetRoundsPerFile,
+			FirstRound:    parquetFirstRound,
+			Codec:         parquet.Codec(parquetCodec),
+		}
+		if parquetLastRound >= 0 {
+			last := uint64(parquetLastRound)
+			opts.LastRound = &last
+		}
+
+		logger.Infof("exporting into %s", parquetDir)
+		res, err := db.ExportParquet(ctx, opts)
+		maybeFail(err, "export failed")
+		for table, files := range res.Files {
+			logger.Infof("table %s: %d files", table, len(files))
+		}
+		if bucket != nil {
+			err = syncToS3(ctx, bucket, parquetDir)
+			maybeFail(err, "upload failed")
+		}
+		logger.Infof("export finished, snapshot round %d", res.Round)
+	},
+}
+
+func init() {
+	exportParquetCmd.Flags().StringVarP(&parquetDir, "output", "o", "", "output directory")
+	exportParquetCmd.Flags().Uint64VarP(&parquetFirstRound, "first-round", "", 0, "first round of the exported transactions")
+	exportParquetCmd.Flags().Int64VarP(&parquetLastRound, "last-round", "", -1, "last round of the exported transactions, defaults to the last round in the database")
+	exportParquetCmd.Flags().Uint64VarP(&parquetRoundsPerFile, "rounds-per-file", "", postgres.DefaultParquetRoundsPerFile, "number of rounds of transactions stored in one file")
+	exportParquetCmd.Flags().StringVarP(&parquetCodec, "codec", "", string(parquet.CodecGzip), "parquet compression codec: [uncompressed, gzip]")
+	addS3Flags(exportParquetCmd)
+	exportParquetCmd.MarkFlagRequired("output")
+}
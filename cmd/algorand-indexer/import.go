@@ -14,7 +14,7 @@ import (
 var importCmd = &cobra.Command{
 	Use:   "import",
 	Short: "import block file or tar file of blocks",
-	Long:  "import block file or tar file of blocks. arguments are interpret as file globs (e.g. *.tar.bz2)",
+	Long:  "import block file or tar file of blocks. arguments are interpret as file globs (e.g. *.tar.bz2), or as key patterns of an S3 bucket (e.g. s3://bucket/archive/*.tar.gz) that only match the files of the manifest.json of the pattern directory if there is one",
 	Run: func(cmd *cobra.Command, args []string) {
 		config.BindFlags(cmd)
 		err := configureLogger()
@@ -31,6 +31,7 @@ var importCmd = &cobra.Command{
 			genesisJSONPath,
 			blockFileLimit,
 			logger)
+		helper.S3 = s3OptionsFromFlags()
 
 		helper.Import(db, args)
 	},
@@ -44,4 +45,5 @@ var (
 func init() {
 	importCmd.Flags().StringVarP(&genesisJSONPath, "genesis", "g", "", "path to genesis.json")
 	importCmd.Flags().IntVarP(&blockFileLimit, "block-file-limit", "", 0, "number of block files to process (for debugging)")
+	addS3EndpointFlags(importCmd)
 }
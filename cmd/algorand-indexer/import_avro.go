@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/rpcs"
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/config"
+	"github.com/algorand/indexer/exporter"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/importer"
+)
+
+var importAvroDir string
+
+var importAvroCmd = &cobra.Command{
+	Use:   "import-avro",
+	Short: "import blocks from avro files",
+	Long:  "rebuild the database from the block_header and txn files of an export-avro export. The blocks are reconstructed from the msgpack columns, checked against the transaction root of their header and imported in round order, starting at the next round of the database. Overlapping exports are read once; a missing round stops the import.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config.BindFlags(cmd)
+		err := configureLogger()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to configure logger: %v", err)
+			os.Exit(1)
+		}
+
+		ctx, cf := context.WithCancel(context.Background())
+		defer cf()
+		{
+			cancelCh := make(chan os.Signal, 1)
+			signal.Notify(cancelCh, syscall.SIGTERM, syscall.SIGINT)
+			go func() {
+				<-cancelCh
+				logger.Println("Stopping import.")
+				cf()
+			}()
+		}
+
+		db, availableCh := indexerDbFromFlags(idb.IndexerDbOptions{})
+		defer db.Close()
+		<-availableCh
+
+		importer.InitialImport(db, genesisJSONPath, nil, logger)
+		nextRound, err := db.GetNextRoundToAccount()
+		maybeFail(err, "failed to get next round, %v", err)
+
+		imp := importer.NewImporter(db)
+		start := time.Now()
+		txCount := 0
+		logger.Infof("importing from round %d out of %s", nextRound, importAvroDir)
+		next, err := exporter.ReadBlocks(ctx, importAvroDir, nextRound, func(block *bookkeeping.Block) error {
+			err := imp.ImportBlock(&rpcs.EncodedBlockCert{Block: *block})
+			if err != nil {
+				return err
+			}
+			txCount += len(block.Payset)
+			if block.Round()%1000 == 0 {
+				logger.Infof("imported round %d", block.Round())
+			}
+			return nil
+		})
+		maybeFail(err, "import failed at round %d", next)
+
+		blocks := next - nextRound
+		if blocks > 0 {
+			dt := time.Since(start)
+			logger.Infof("%d blocks in %s, %.0f/s, %d txn, %.0f/s", blocks, dt.String(), float64(time.Second)*float64(blocks)/float64(dt), txCount, float64(time.Second)*float64(txCount)/float64(dt))
+		}
+		logger.Infof("import finished, next round %d", next)
+	},
+}
+
+func init() {
+	importAvroCmd.Flags().StringVarP(&importAvroDir, "input", "i", "", "directory of the export")
+	importAvroCmd.Flags().StringVarP(&genesisJSONPath, "genesis", "g", "", "path to genesis.json")
+	importAvroCmd.MarkFlagRequired("input")
+}
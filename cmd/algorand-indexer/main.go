@@ -15,6 +15,7 @@ import (
 	"github.com/algorand/indexer/config"
 	"github.com/algorand/indexer/idb"
 	"github.com/algorand/indexer/idb/dummy"
+	_ "github.com/algorand/indexer/idb/kv"
 	_ "github.com/algorand/indexer/idb/postgres"
 	"github.com/algorand/indexer/util/metrics"
 	"github.com/algorand/indexer/version"
@@ -75,6 +76,8 @@ var rootCmd = &cobra.Command{
 
 var (
 	postgresAddr   string
+	sqlitePath     string
+	kvDir          string
 	dummyIndexerDb bool
 	doVersion      bool
 	cpuProfile     string
@@ -91,6 +94,16 @@ func indexerDbFromFlags(opts idb.IndexerDbOptions) (idb.IndexerDb, chan struct{}
 		maybeFail(err, "could not init db, %v", err)
 		return db, ch
 	}
+	if sqlitePath != "" {
+		db, ch, err := idb.IndexerDbByName("sqlite", sqlitePath, opts, logger)
+		maybeFail(err, "could not init db, %v", err)
+		return db, ch
+	}
+	if kvDir != "" {
+		db, ch, err := idb.IndexerDbByName("kv", kvDir, opts, logger)
+		maybeFail(err, "could not init db, %v", err)
+		return db, ch
+	}
 	if dummyIndexerDb {
 		return dummy.IndexerDb(), nil
 	}
@@ -110,10 +123,18 @@ func init() {
 	rootCmd.AddCommand(importCmd)
 	importCmd.Hidden = true
 	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(exportAvroCmd)
+	rootCmd.AddCommand(avroSchemaCmd)
+	rootCmd.AddCommand(exportParquetCmd)
+	rootCmd.AddCommand(rollbackCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(checkpointCmd)
+	rootCmd.AddCommand(importAvroCmd)
 
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "loglevel", "l", "info", "verbosity of logs: [error, warn, info, debug, trace]")
 	rootCmd.PersistentFlags().StringVarP(&logFile, "logfile", "f", "", "file to write logs to, if unset logs are written to standard out")
 	rootCmd.PersistentFlags().StringVarP(&postgresAddr, "postgres", "P", "", "connection string for postgres database")
+	rootCmd.PersistentFlags().StringVarP(&kvDir, "kv", "", "", "directory of an embedded key-value database")
 	rootCmd.PersistentFlags().BoolVarP(&dummyIndexerDb, "dummydb", "n", false, "use dummy indexer db")
 	rootCmd.PersistentFlags().StringVarP(&cpuProfile, "cpuprofile", "", "", "file to record cpu profile to")
 	rootCmd.PersistentFlags().StringVarP(&pidFilePath, "pidfile", "", "", "file to write daemon's process id to")
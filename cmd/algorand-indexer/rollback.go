@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/config"
+	"github.com/algorand/indexer/idb"
+)
+
+var rollbackRound uint64
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "remove the most recent rounds from the database",
+	Long:  "remove the rounds after --round from the database and restore the account state of that round. Only rounds imported by a daemon running with --max-rollback-rounds can be removed. Stop the daemon first.",
+	Run: func(cmd *cobra.Command, args []string) {
+		config.BindFlags(cmd)
+		err := configureLogger()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to configure logger: %v", err)
+			os.Exit(1)
+		}
+
+		db, availableCh := indexerDbFromFlags(idb.IndexerDbOptions{})
+		defer db.Close()
+		<-availableCh
+
+		err = db.Rollback(rollbackRound)
+		maybeFail(err, "rollback to round %d failed, %v", rollbackRound, err)
+		logger.Infof("rolled back to round %d", rollbackRound)
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().Uint64VarP(&rollbackRound, "round", "r", 0, "last round to keep")
+	rollbackCmd.MarkFlagRequired("round")
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/indexer/s3"
+)
+
+var (
+	s3Endpoint string
+	s3Region   string
+	s3Bucket   string
+	s3Prefix   string
+)
+
+// addS3EndpointFlags adds the flags of the S3 service. The credentials are
+// read from $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY.
+func addS3EndpointFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s3Endpoint, "s3-endpoint", "", "https://s3.amazonaws.com", "URL of the S3 service, e.g. http://localhost:9000 for MinIO")
+	cmd.Flags().StringVarP(&s3Region, "s3-region", "", s3.DefaultRegion, "region of the S3 bucket")
+}
+
+// addS3Flags adds the flags of the bucket the output files are uploaded to.
+func addS3Flags(cmd *cobra.Command) {
+	addS3EndpointFlags(cmd)
+	cmd.Flags().StringVarP(&s3Bucket, "s3-bucket", "", "", "S3 bucket the output files are uploaded to, with a manifest.json written after the files")
+	cmd.Flags().StringVarP(&s3Prefix, "s3-prefix", "", "", "key prefix of the uploaded files")
+}
+
+func s3OptionsFromFlags() s3.Options {
+	return s3.Options{
+		Endpoint:  s3Endpoint,
+		Region:    s3Region,
+		Bucket:    s3Bucket,
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	}
+}
+
+// s3ClientFromFlags returns the client of the output bucket, nil if
+// --s3-bucket is not set.
+func s3ClientFromFlags() *s3.Client {
+	if s3Bucket == "" {
+		return nil
+	}
+	client, err := s3.MakeClient(s3OptionsFromFlags())
+	maybeFail(err, "s3 setup, %v", err)
+	return client
+}
+
+// syncToS3 uploads the new and changed files of `dir`.
+func syncToS3(ctx context.Context, client *s3.Client, dir string) error {
+	uploaded, err := client.SyncDir(ctx, dir, s3Prefix)
+	if err != nil {
+		return fmt.Errorf("syncToS3() err: %w", err)
+	}
+	for _, key := range uploaded {
+		logger.Infof("uploaded s3://%s/%s", client.Bucket(), s3.Key(s3Prefix, key))
+	}
+	return nil
+}
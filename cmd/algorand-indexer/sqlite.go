@@ -0,0 +1,13 @@
+// The sqlite backend is only available in builds with `go build --tags sqlite`.
+//go:build sqlite
+// +build sqlite
+
+package main
+
+import (
+	_ "github.com/algorand/indexer/idb/sqlite"
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&sqlitePath, "sqlite", "", "", "path to a sqlite database file")
+}
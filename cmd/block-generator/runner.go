@@ -18,6 +18,10 @@ func init() {
 		Use:   "runner",
 		Short: "Run test suite and collect results.",
 		Run: func(cmd *cobra.Command, args []string) {
+			if (runnerArgs.PostgresConnectionString == "") == (runnerArgs.KVDir == "") {
+				fmt.Println("exactly one of --postgres-connection-string and --kv-dir is required")
+				return
+			}
 			if err := runner.Run(runnerArgs); err != nil {
 				fmt.Println(err)
 			}
@@ -28,6 +32,7 @@ func init() {
 	runnerCmd.Flags().StringVarP(&runnerArgs.IndexerBinary, "indexer-binary", "i", "", "Path to indexer binary.")
 	runnerCmd.Flags().Uint64VarP(&runnerArgs.IndexerPort, "indexer-port", "p", 4010, "Port to start the server at. This is useful if you have a prometheus server for collecting additional data.")
 	runnerCmd.Flags().StringVarP(&runnerArgs.PostgresConnectionString, "postgres-connection-string", "c", "", "Postgres connection string.")
+	runnerCmd.Flags().StringVarP(&runnerArgs.KVDir, "kv-dir", "", "", "Directory of an embedded key-value database to use instead of postgres, it is deleted before each scenario.")
 	runnerCmd.Flags().DurationVarP(&runnerArgs.RunDuration, "test-duration", "d", 5*time.Minute, "Duration to use for each scenario.")
 	runnerCmd.Flags().StringVarP(&runnerArgs.ReportDirectory, "report-directory", "r", "", "Location to place test reports.")
 	runnerCmd.Flags().StringVarP(&runnerArgs.LogLevel, "log-level", "l", "error", "LogLevel to use when starting Indexer. [error, warn, info, debug, trace]")
@@ -37,7 +42,6 @@ func init() {
 
 	runnerCmd.MarkFlagRequired("scenario")
 	runnerCmd.MarkFlagRequired("indexer-binary")
-	runnerCmd.MarkFlagRequired("postgres-connection-string")
 	runnerCmd.MarkFlagRequired("report-directory")
 
 	rootCmd.AddCommand(runnerCmd)
@@ -31,12 +31,14 @@ type Args struct {
 	IndexerBinary            string
 	IndexerPort              uint64
 	PostgresConnectionString string
-	CPUProfilePath           string
-	RunDuration              time.Duration
-	LogLevel                 string
-	ReportDirectory          string
-	ResetReportDir           bool
-	RunValidation            bool
+	// KVDir selects the embedded key-value backend instead of postgres.
+	KVDir           string
+	CPUProfilePath  string
+	RunDuration     time.Duration
+	LogLevel        string
+	ReportDirectory string
+	ResetReportDir  bool
+	RunValidation   bool
 }
 
 // Run is a public helper to run the tests.
@@ -88,7 +90,7 @@ func (r *Args) run() error {
 	indexerNet := fmt.Sprintf("localhost:%d", r.IndexerPort)
 	generatorShutdownFunc, generator := startGenerator(r.Path, algodNet, blockMiddleware)
 
-	indexerShutdownFunc, err := startIndexer(logfile, r.LogLevel, r.IndexerBinary, algodNet, indexerNet, r.PostgresConnectionString, r.CPUProfilePath)
+	indexerShutdownFunc, err := startIndexer(logfile, r.LogLevel, r.IndexerBinary, algodNet, indexerNet, r.PostgresConnectionString, r.KVDir, r.CPUProfilePath)
 	if err != nil {
 		return fmt.Errorf("failed to start indexer: %w", err)
 	}
@@ -392,10 +394,16 @@ func startGenerator(configFile string, addr string, blockMiddleware func(http.Ha
 	}, generator
 }
 
-// startIndexer resets the postgres database and executes the indexer binary. It performs some simple verification to
-// ensure that the service has started properly.
-func startIndexer(logfile string, loglevel string, indexerBinary string, algodNet string, indexerNet string, postgresConnectionString string, cpuprofile string) (func() error, error) {
-	{
+// startIndexer resets the postgres or key-value database and executes the indexer binary. It performs some simple
+// verification to ensure that the service has started properly.
+func startIndexer(logfile string, loglevel string, indexerBinary string, algodNet string, indexerNet string, postgresConnectionString string, kvDir string, cpuprofile string) (func() error, error) {
+	var dbArgs []string
+	if kvDir != "" {
+		if err := os.RemoveAll(kvDir); err != nil {
+			return nil, fmt.Errorf("unable to reset kv DB: %w", err)
+		}
+		dbArgs = []string{"--kv", kvDir}
+	} else {
 		conn, err := pgx.Connect(context.Background(), postgresConnectionString)
 		if err != nil {
 			return nil, fmt.Errorf("postgres connection string did not work: %w", err)
@@ -407,19 +415,19 @@ func startIndexer(logfile string, loglevel string, indexerBinary string, algodNe
 		if err := conn.Close(context.Background()); err != nil {
 			return nil, fmt.Errorf("unable to close database handle: %w", err)
 		}
+		dbArgs = []string{"--postgres", postgresConnectionString}
 	}
 
-	cmd := exec.Command(
-		indexerBinary,
+	args := []string{
 		"daemon",
 		"--algod-net", algodNet,
 		"--algod-token", "secure-token-here",
 		"--metrics-mode", "VERBOSE",
-		"--postgres", postgresConnectionString,
 		"--server", indexerNet,
 		"--logfile", logfile,
 		"--loglevel", loglevel,
-		"--cpuprofile", cpuprofile)
+		"--cpuprofile", cpuprofile}
+	cmd := exec.Command(indexerBinary, append(args, dbArgs...)...)
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -0,0 +1,77 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// LoadCheckpoint returns the checkpoint of the exporter `name`, or a new
+// checkpoint starting at `first` if it has none.
+func LoadCheckpoint(db idb.IndexerDb, name string, first uint64) (idb.ExportCheckpoint, error) {
+	checkpoint, err := db.GetExportCheckpoint(name)
+	if errors.Is(err, idb.ErrorCheckpointNotFound) {
+		return idb.ExportCheckpoint{NextRound: first}, nil
+	}
+	if err != nil {
+		return idb.ExportCheckpoint{}, fmt.Errorf("LoadCheckpoint() err: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// ExportRoundsWithCheckpoint exports the rounds from checkpoint.NextRound to
+// `last` like ExportRounds(). The files of each round range are published
+// before the checkpoint of the exporter `name` is updated, so a round is
+// recorded only once it is durably written. An export that stops between the
+// two writes the same files again when it resumes. w must start at
+// checkpoint.NextRound, its files are all closed on return.
+func ExportRoundsWithCheckpoint(ctx context.Context, db idb.IndexerDb, w *Writer, name string, checkpoint *idb.ExportCheckpoint, last uint64, logger *log.Logger) error {
+	for checkpoint.NextRound <= last {
+		first := checkpoint.NextRound
+		_, rangeLast := w.rangeOf(first)
+		if rangeLast > last {
+			rangeLast = last
+		}
+
+		err := ExportRounds(ctx, db, w, first, rangeLast, logger)
+		if err != nil {
+			w.Abort()
+			return fmt.Errorf("ExportRoundsWithCheckpoint() err: %w", err)
+		}
+
+		tables := make([]string, 0, len(w.files))
+		for table := range w.files {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		err = w.Close()
+		if err != nil {
+			return fmt.Errorf("ExportRoundsWithCheckpoint() err: %w", err)
+		}
+
+		for _, table := range tables {
+			checkpoint.Files = append(checkpoint.Files, idb.ExportFile{
+				Path:       filepath.Join(table, FileName(table, first, rangeLast)),
+				FirstRound: first,
+				LastRound:  rangeLast,
+			})
+		}
+		checkpoint.NextRound = rangeLast + 1
+		err = db.SetExportCheckpoint(name, *checkpoint)
+		if err != nil {
+			return fmt.Errorf("ExportRoundsWithCheckpoint() err: %w", err)
+		}
+
+		if rangeLast == last {
+			// Avoid overflow when last is the maximum uint64.
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package exporter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/util/test"
+)
+
+func TestExportRoundsWithCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	db := &mocks.IndexerDb{}
+	db.On("GetExportCheckpoint", "avro").Return(idb.ExportCheckpoint{}, idb.ErrorCheckpointNotFound)
+	db.On("GetBlock", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, round uint64, options idb.GetBlockOptions) bookkeeping.BlockHeader {
+			header := test.MakeGenesisBlock().BlockHeader
+			header.Round = basics.Round(round)
+			return header
+		}, nil, nil)
+	var saved []idb.ExportCheckpoint
+	db.On("SetExportCheckpoint", "avro", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(idb.ExportCheckpoint))
+	})
+
+	checkpoint, err := LoadCheckpoint(db, "avro", 5)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), checkpoint.NextRound)
+
+	last := uint64(12)
+	w, err := MakeWriter(Options{Dir: dir, RoundsPerFile: 10, FirstRound: 5, LastRound: &last})
+	require.NoError(t, err)
+	err = ExportRoundsWithCheckpoint(context.Background(), db, w, "avro", &checkpoint, last, nil)
+	require.NoError(t, err)
+
+	// One checkpoint per round range, recorded after its files.
+	require.Len(t, saved, 2)
+	assert.Equal(t, uint64(10), saved[0].NextRound)
+	assert.Equal(t, uint64(13), saved[1].NextRound)
+	assert.Equal(t, saved[1], checkpoint)
+	require.Len(t, checkpoint.Files, 6)
+	for _, f := range checkpoint.Files {
+		assert.FileExists(t, filepath.Join(dir, f.Path))
+	}
+	assert.Equal(t,
+		idb.ExportFile{Path: filepath.Join(schema.TxnTable, FileName(schema.TxnTable, 10, 12)), FirstRound: 10, LastRound: 12},
+		checkpoint.Files[4])
+}
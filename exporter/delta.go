@@ -0,0 +1,665 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+)
+
+// Format is the file format of the state delta stream.
+type Format string
+
+// Supported formats.
+const (
+	FormatAvro   Format = "avro"
+	FormatNDJSON Format = "ndjson"
+)
+
+// deltaTables are the tables of the state delta stream, in write order.
+var deltaTables = []string{schema.AccountDeltaTable, schema.CreatableTable, schema.HoldingTable}
+
+func fixedOrNil(b [32]byte) interface{} {
+	if b == ([32]byte{}) {
+		return nil
+	}
+	return b[:]
+}
+
+func creatableTypeName(ctype basics.CreatableType) string {
+	if ctype == basics.AssetCreatable {
+		return "asset"
+	}
+	return "app"
+}
+
+// AccountDeltaRecords converts the account deltas of a round to
+// schema.AccountDelta records, in the order the evaluator modified them.
+func AccountDeltaRecords(round basics.Round, accts ledgercore.AccountDeltas) []map[string]interface{} {
+	records := make([]map[string]interface{}, 0, accts.Len())
+	for i := 0; i < accts.Len(); i++ {
+		address, ad := accts.GetByIdx(i)
+		records = append(records, map[string]interface{}{
+			"round":                           uint64(round),
+			"address":                         address.String(),
+			"closed":                          ad.IsZero(),
+			"status":                          int(ad.Status),
+			"microalgos":                      ad.MicroAlgos.Raw,
+			"rewards_base":                    ad.RewardsBase,
+			"rewarded_microalgos":             ad.RewardedMicroAlgos.Raw,
+			"vote_id":                         fixedOrNil([32]byte(ad.VoteID)),
+			"selection_id":                    fixedOrNil([32]byte(ad.SelectionID)),
+			"vote_first_valid":                uint64(ad.VoteFirstValid),
+			"vote_last_valid":                 uint64(ad.VoteLastValid),
+			"vote_key_dilution":               ad.VoteKeyDilution,
+			"auth_addr":                       addrOrNil(ad.AuthAddr),
+			"total_app_schema_num_uint":       ad.TotalAppSchema.NumUint,
+			"total_app_schema_num_byte_slice": ad.TotalAppSchema.NumByteSlice,
+			"total_extra_app_pages":           uint64(ad.TotalExtraAppPages),
+			"account_msgpack":                 protocol.Encode(&ad),
+		})
+	}
+	return records
+}
+
+// CreatableRecords converts the modified creatables of a round to
+// schema.Creatable records, sorted by index.
+func CreatableRecords(round basics.Round, creatables map[basics.CreatableIndex]ledgercore.ModifiedCreatable) []map[string]interface{} {
+	indexes := make([]basics.CreatableIndex, 0, len(creatables))
+	for index := range creatables {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	records := make([]map[string]interface{}, 0, len(creatables))
+	for _, index := range indexes {
+		creatable := creatables[index]
+		records = append(records, map[string]interface{}{
+			"round":   uint64(round),
+			"index":   uint64(index),
+			"type":    creatableTypeName(creatable.Ctype),
+			"created": creatable.Created,
+			"creator": creatable.Creator.String(),
+		})
+	}
+	return records
+}
+
+type holdingKey struct {
+	address basics.Address
+	index   uint64
+	ctype   basics.CreatableType
+	created bool
+}
+
+// HoldingRecords converts the modified asset holdings and app local states of
+// a round to schema.Holding records, sorted by address, then asset holdings
+// before app local states, then index.
+func HoldingRecords(round basics.Round, delta *ledgercore.StateDelta) []map[string]interface{} {
+	keys := make([]holdingKey, 0, len(delta.ModifiedAssetHoldings)+len(delta.ModifiedAppLocalStates))
+	for aa, created := range delta.ModifiedAssetHoldings {
+		keys = append(keys, holdingKey{aa.Address, uint64(aa.Asset), basics.AssetCreatable, created})
+	}
+	for aa, created := range delta.ModifiedAppLocalStates {
+		keys = append(keys, holdingKey{aa.Address, uint64(aa.App), basics.AppCreatable, created})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].address != keys[j].address {
+			return bytes.Compare(keys[i].address[:], keys[j].address[:]) < 0
+		}
+		if keys[i].ctype != keys[j].ctype {
+			return keys[i].ctype < keys[j].ctype
+		}
+		return keys[i].index < keys[j].index
+	})
+
+	records := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		records = append(records, map[string]interface{}{
+			"round":   uint64(round),
+			"address": key.address.String(),
+			"index":   key.index,
+			"type":    creatableTypeName(key.ctype),
+			"created": key.created,
+		})
+	}
+	return records
+}
+
+// ndjsonWriter writes one JSON object per line. Byte fields are base64
+// encoded.
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (w ndjsonWriter) Append(record interface{}) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(append(b, '\n'))
+	return err
+}
+
+func (w ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (w ndjsonWriter) Close() error {
+	return nil
+}
+
+// DeltaOptions configure where and how the state delta stream is written.
+type DeltaOptions struct {
+	// Dir is the output directory. Each table is written to its own
+	// subdirectory.
+	Dir string
+
+	// RoundsPerFile is the size of the aligned round range a file may span.
+	RoundsPerFile uint64
+
+	// Format is the file format, FormatAvro by default.
+	Format Format
+
+	// Codec is the Avro block compression codec.
+	Codec avro.Codec
+}
+
+// DeltaWriter writes the state deltas of consecutive rounds as a change stream,
+// one file per table and round range. Unlike Writer, files are named after the
+// rounds they actually hold, so that the stream can start and stop at any
+// round: a file holds at most the rounds of one aligned range.
+//
+// WriteDelta() is meant to be called before the database commits the round.
+// Every round is synced to disk before WriteDelta() returns, in temporary files
+// named after the rounds they hold, so that no committed round is lost if the
+// process dies: Resume() picks the files up again and drops the rounds that
+// were not committed. A file is published when the first round of the next
+// range is written, which means that its last round was committed, or by
+// Publish().
+type DeltaWriter struct {
+	opts DeltaOptions
+	ext  string
+
+	// resumed is set once the files of a previous run were recovered.
+	resumed bool
+
+	// firstRound is the first round of the open files.
+	firstRound uint64
+	files      map[string]*rangeFile
+
+	// checked holds the tables whose schema was checked against Dir.
+	checked map[string]bool
+
+	// lastRound is the last round written, valid if written is set.
+	lastRound uint64
+	written   bool
+}
+
+// MakeDeltaWriter creates a DeltaWriter.
+func MakeDeltaWriter(opts DeltaOptions) (*DeltaWriter, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("MakeDeltaWriter() output directory not set")
+	}
+	if opts.RoundsPerFile == 0 {
+		opts.RoundsPerFile = DefaultRoundsPerFile
+	}
+	if opts.Format == "" {
+		opts.Format = FormatAvro
+	}
+	if opts.Codec == "" {
+		opts.Codec = avro.CodecDeflate
+	}
+	switch opts.Format {
+	case FormatAvro, FormatNDJSON:
+	default:
+		return nil, fmt.Errorf("MakeDeltaWriter() unknown format %s", opts.Format)
+	}
+	return &DeltaWriter{
+		opts:    opts,
+		ext:     "." + string(opts.Format),
+		checked: make(map[string]bool),
+	}, nil
+}
+
+// NextRound returns the round following the last written or published round,
+// or 0 if the stream is empty.
+func (w *DeltaWriter) NextRound() (uint64, error) {
+	if w.written {
+		return w.lastRound + 1, nil
+	}
+	next, err := nextRound(w.opts.Dir, schema.AccountDeltaTable, w.ext)
+	if err != nil {
+		return 0, fmt.Errorf("NextRound() err: %w", err)
+	}
+	return next, nil
+}
+
+func (w *DeltaWriter) rangeLast(round uint64) uint64 {
+	return round - round%w.opts.RoundsPerFile + w.opts.RoundsPerFile - 1
+}
+
+// tmpPath returns the temporary name of a file holding the durable rounds
+// [first, last].
+func (w *DeltaWriter) tmpPath(table string, first, last uint64) string {
+	return filepath.Join(w.opts.Dir, table, fileName(table, first, last, w.ext)+".tmp")
+}
+
+// partialPath returns the name of a new file until its first round is durable.
+// Partial files are never recovered.
+func (w *DeltaWriter) partialPath(table string, first uint64) string {
+	return filepath.Join(w.opts.Dir, table, fileName(table, first, first, w.ext)+".partial")
+}
+
+func (w *DeltaWriter) openFile(table string, first uint64) (*rangeFile, error) {
+	s, ok := schema.ForTable(table)
+	if !ok {
+		return nil, fmt.Errorf("openFile() unknown table %s", table)
+	}
+
+	// The final name is only known when the file is published.
+	path := filepath.Join(w.opts.Dir, table, fileName(table, first, first, w.ext))
+	var makeWriter func(io.Writer) (recordWriter, error)
+	if w.opts.Format == FormatNDJSON {
+		makeWriter = func(out io.Writer) (recordWriter, error) {
+			return ndjsonWriter{w: out}, nil
+		}
+	} else {
+		if !w.checked[table] {
+			err := schema.CheckTable(w.opts.Dir, table)
+			if err != nil {
+				return nil, fmt.Errorf("openFile() err: %w", err)
+			}
+			w.checked[table] = true
+		}
+		meta := map[string][]byte{
+			schema.MetaVersion:     []byte(strconv.Itoa(schema.Version)),
+			schema.MetaFingerprint: []byte(schema.Fingerprint(s)),
+			schema.MetaTable:       []byte(table),
+			schema.MetaFirstRound:  []byte(strconv.FormatUint(first, 10)),
+		}
+		makeWriter = avroWriter(s, w.opts.Codec, meta)
+	}
+	return openRangeFile(path, w.partialPath(table, first), makeWriter)
+}
+
+// syncDir makes the renames in a directory durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// syncFiles makes the rounds written so far durable, and renames the files
+// after them.
+func (w *DeltaWriter) syncFiles() error {
+	for _, table := range deltaTables {
+		f := w.files[table]
+		err := f.sync()
+		if err == nil {
+			err = f.rename(w.tmpPath(table, w.firstRound, w.lastRound))
+		}
+		if err == nil {
+			err = syncDir(filepath.Dir(f.tmp))
+		}
+		if err != nil {
+			return fmt.Errorf("syncFiles() err: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyRecords appends the records of the rounds before `next` from the file at
+// `path` to `dst`. Reading stops at the first incomplete record, which can
+// only belong to a round that was never synced.
+func (w *DeltaWriter) copyRecords(path string, dst recordWriter, next uint64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("copyRecords() err: %w", err)
+	}
+	defer f.Close()
+
+	if w.opts.Format == FormatNDJSON {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 16*1024*1024)
+		for scanner.Scan() {
+			var rec struct {
+				Round uint64 `json:"round"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+				break
+			}
+			if rec.Round < next {
+				err = dst.Append(json.RawMessage(append([]byte{}, scanner.Bytes()...)))
+				if err != nil {
+					return fmt.Errorf("copyRecords() err: %w", err)
+				}
+			}
+		}
+		return nil
+	}
+
+	reader, err := avro.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("copyRecords() %s err: %w", path, err)
+	}
+	for {
+		rec, err := reader.Next()
+		if err != nil {
+			break
+		}
+		m := rec.(map[string]interface{})
+		if uint64(m["round"].(int64)) < next {
+			err = dst.Append(m)
+			if err != nil {
+				return fmt.Errorf("copyRecords() err: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// truncate rewrites the files so that they only hold the rounds before `next`.
+// The files must be closed and named by `paths`.
+func (w *DeltaWriter) truncate(first uint64, paths map[string]string, next uint64) error {
+	w.files = make(map[string]*rangeFile)
+	for _, table := range deltaTables {
+		f, err := w.openFile(table, first)
+		if err != nil {
+			return fmt.Errorf("truncate() err: %w", err)
+		}
+		w.files[table] = f
+		err = w.copyRecords(paths[table], f.writer, next)
+		if err != nil {
+			return fmt.Errorf("truncate() err: %w", err)
+		}
+	}
+	w.firstRound = first
+	w.lastRound = next - 1
+	w.written = true
+	err := w.syncFiles()
+	if err != nil {
+		return fmt.Errorf("truncate() err: %w", err)
+	}
+	for _, path := range paths {
+		if path != w.tmpPath(filepath.Base(filepath.Dir(path)), first, next-1) {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// findFiles returns the temporary files left by a previous run, and removes
+// the partial ones.
+func (w *DeltaWriter) findFiles() (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, table := range deltaTables {
+		dir := filepath.Join(w.opts.Dir, table)
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("findFiles() err: %w", err)
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".partial") {
+				os.Remove(filepath.Join(dir, entry.Name()))
+				continue
+			}
+			if _, _, ok := parseFileName(table, entry.Name(), w.ext+".tmp"); !ok {
+				continue
+			}
+			if _, ok := paths[table]; ok {
+				return nil, fmt.Errorf("findFiles() several temporary files in %s", dir)
+			}
+			paths[table] = filepath.Join(dir, entry.Name())
+		}
+	}
+	return paths, nil
+}
+
+// finishPublish publishes the temporary files left when publishing the range
+// [first, next-1] failed after the files of some tables were published. All
+// of their rounds were committed.
+func (w *DeltaWriter) finishPublish(first uint64, paths map[string]string, next uint64) error {
+	for _, table := range deltaTables {
+		if _, ok := paths[table]; ok {
+			continue
+		}
+		path := filepath.Join(w.opts.Dir, table, fileName(table, first, next-1, w.ext))
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("finishPublish() temporary file of %s missing for rounds %d to %d", table, first, next-1)
+		}
+	}
+	for table, path := range paths {
+		if path != w.tmpPath(table, first, next-1) {
+			return fmt.Errorf("finishPublish() unexpected temporary file %s", path)
+		}
+		err := os.Rename(path, filepath.Join(w.opts.Dir, table, fileName(table, first, next-1, w.ext)))
+		if err == nil {
+			err = syncDir(filepath.Dir(path))
+		}
+		if err != nil {
+			return fmt.Errorf("finishPublish() err: %w", err)
+		}
+	}
+	return nil
+}
+
+// Resume recovers the files left by a previous run, keeping the rounds before
+// `next`, the next round of the database. It returns an error if the stream
+// does not continue at `next`. WriteDelta() resumes at the round it writes.
+func (w *DeltaWriter) Resume(next uint64) error {
+	if w.resumed {
+		if w.written && w.lastRound+1 != next {
+			return fmt.Errorf("Resume() the state delta stream continues at round %d, not %d", w.lastRound+1, next)
+		}
+		return nil
+	}
+
+	paths, err := w.findFiles()
+	if err != nil {
+		return fmt.Errorf("Resume() err: %w", err)
+	}
+	if len(paths) > 0 {
+		// The files of a round are renamed one table after the other, the
+		// durable rounds are the ones of the table that is behind.
+		var first, last uint64
+		i := 0
+		for table, path := range paths {
+			f, l, _ := parseFileName(table, filepath.Base(path), w.ext+".tmp")
+			if i > 0 && f != first {
+				return fmt.Errorf("Resume() temporary files start at rounds %d and %d", first, f)
+			}
+			if i == 0 || l < last {
+				last = l
+			}
+			first = f
+			i++
+		}
+
+		if next > first {
+			if len(paths) != len(deltaTables) {
+				err = w.finishPublish(first, paths, next)
+				if err != nil {
+					return fmt.Errorf("Resume() err: %w", err)
+				}
+				return w.Resume(next)
+			}
+			if next-1 > last {
+				return fmt.Errorf("Resume() the state delta files end at round %d, the next round is %d", last, next)
+			}
+			err = w.truncate(first, paths, next)
+			if err != nil {
+				w.Close()
+				return fmt.Errorf("Resume() err: %w", err)
+			}
+			w.resumed = true
+			return nil
+		}
+
+		// None of these rounds were committed.
+		for _, path := range paths {
+			os.Remove(path)
+		}
+	}
+
+	published, err := nextRound(w.opts.Dir, schema.AccountDeltaTable, w.ext)
+	if err != nil {
+		return fmt.Errorf("Resume() err: %w", err)
+	}
+	if published != 0 {
+		if published != next {
+			return fmt.Errorf("Resume() the published state delta files continue at round %d, the next round is %d", published, next)
+		}
+		w.lastRound = published - 1
+		w.written = true
+	}
+	w.resumed = true
+	return nil
+}
+
+// reset drops the state of the open files after an error, the next
+// WriteDelta() resumes from what is on disk.
+func (w *DeltaWriter) reset() {
+	w.Close()
+	w.resumed = false
+	w.written = false
+}
+
+// WriteDelta appends the records of the state delta of a round to the open
+// files and syncs them. Rounds must be written in increasing order, except
+// that the last round may be written again, e.g. when the database failed to
+// commit it.
+func (w *DeltaWriter) WriteDelta(round basics.Round, delta *ledgercore.StateDelta) error {
+	r := uint64(round)
+	err := w.Resume(r)
+	if err != nil && w.written && w.lastRound == r {
+		// Written again, drop the previous attempt.
+		w.Close()
+		w.resumed = false
+		w.written = false
+		err = w.Resume(r)
+	}
+	if err != nil {
+		return fmt.Errorf("WriteDelta() err: %w", err)
+	}
+
+	if w.files != nil && w.rangeLast(r) != w.rangeLast(w.firstRound) {
+		// Round r-1 was committed, the range is complete.
+		err = w.publish()
+		if err != nil {
+			w.reset()
+			return fmt.Errorf("WriteDelta() err: %w", err)
+		}
+	}
+	if w.files == nil {
+		w.files = make(map[string]*rangeFile)
+		w.firstRound = r
+		for _, table := range deltaTables {
+			f, err := w.openFile(table, r)
+			if err != nil {
+				w.reset()
+				return fmt.Errorf("WriteDelta() err: %w", err)
+			}
+			w.files[table] = f
+		}
+	}
+
+	records := map[string][]map[string]interface{}{
+		schema.AccountDeltaTable: AccountDeltaRecords(round, delta.Accts),
+		schema.CreatableTable:    CreatableRecords(round, delta.Creatables),
+		schema.HoldingTable:      HoldingRecords(round, delta),
+	}
+	for _, table := range deltaTables {
+		for _, rec := range records[table] {
+			err = w.files[table].writer.Append(rec)
+			if err != nil {
+				w.reset()
+				return fmt.Errorf("WriteDelta() %s round %d err: %w", table, r, err)
+			}
+		}
+	}
+	w.lastRound = r
+	w.written = true
+
+	err = w.syncFiles()
+	if err != nil {
+		w.reset()
+		return fmt.Errorf("WriteDelta() err: %w", err)
+	}
+	return nil
+}
+
+// publish renames the open files after the rounds they hold.
+func (w *DeltaWriter) publish() error {
+	var firstErr error
+	for table, f := range w.files {
+		f.path = filepath.Join(w.opts.Dir, table, fileName(table, w.firstRound, w.lastRound, w.ext))
+		err := f.close()
+		if err == nil {
+			err = syncDir(filepath.Dir(f.path))
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	w.files = nil
+	return firstErr
+}
+
+// Publish publishes the open files with the rounds before `next`, the next
+// round of the database, e.g. when the daemon stops.
+func (w *DeltaWriter) Publish(next uint64) error {
+	err := w.Resume(next)
+	if err != nil && w.written && w.lastRound == next {
+		// The last round was not committed.
+		w.Close()
+		w.resumed = false
+		w.written = false
+		err = w.Resume(next)
+	}
+	if err != nil {
+		return fmt.Errorf("Publish() err: %w", err)
+	}
+	if w.files == nil {
+		return nil
+	}
+	err = w.publish()
+	if err != nil {
+		return fmt.Errorf("Publish() err: %w", err)
+	}
+	return nil
+}
+
+// Close closes the open files without publishing them, the next Resume()
+// continues them.
+func (w *DeltaWriter) Close() error {
+	var firstErr error
+	for _, f := range w.files {
+		err := f.file.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	w.files = nil
+	return firstErr
+}
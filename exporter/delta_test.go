@@ -0,0 +1,272 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/util/test"
+)
+
+func makeStateDelta() ledgercore.StateDelta {
+	var delta ledgercore.StateDelta
+	delta.Accts.Upsert(test.AccountB, basics.AccountData{
+		MicroAlgos: basics.MicroAlgos{Raw: 5},
+		AuthAddr:   test.AccountC,
+		Assets:     map[basics.AssetIndex]basics.AssetHolding{3: {Amount: 7}},
+	})
+	delta.Accts.Upsert(test.AccountA, basics.AccountData{})
+	delta.Creatables = map[basics.CreatableIndex]ledgercore.ModifiedCreatable{
+		4: {Ctype: basics.AppCreatable, Created: false, Creator: test.AccountA},
+		3: {Ctype: basics.AssetCreatable, Created: true, Creator: test.AccountB},
+	}
+	delta.ModifiedAssetHoldings = map[ledgercore.AccountAsset]bool{
+		{Address: test.AccountB, Asset: 3}: true,
+	}
+	delta.ModifiedAppLocalStates = map[ledgercore.AccountApp]bool{
+		{Address: test.AccountB, App: 1}: false,
+	}
+	return delta
+}
+
+func TestStateDeltaRecords(t *testing.T) {
+	delta := makeStateDelta()
+
+	accounts := AccountDeltaRecords(9, delta.Accts)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, test.AccountB.String(), accounts[0]["address"])
+	assert.Equal(t, false, accounts[0]["closed"])
+	assert.Equal(t, uint64(5), accounts[0]["microalgos"])
+	assert.Equal(t, test.AccountC.String(), accounts[0]["auth_addr"])
+	assert.Nil(t, accounts[0]["vote_id"])
+	var ad basics.AccountData
+	require.NoError(t, protocol.Decode(accounts[0]["account_msgpack"].([]byte), &ad))
+	assert.Equal(t, uint64(7), ad.Assets[3].Amount)
+	assert.Equal(t, test.AccountA.String(), accounts[1]["address"])
+	assert.Equal(t, true, accounts[1]["closed"])
+
+	creatables := CreatableRecords(9, delta.Creatables)
+	require.Len(t, creatables, 2)
+	assert.Equal(t, uint64(3), creatables[0]["index"])
+	assert.Equal(t, "asset", creatables[0]["type"])
+	assert.Equal(t, true, creatables[0]["created"])
+	assert.Equal(t, uint64(4), creatables[1]["index"])
+	assert.Equal(t, "app", creatables[1]["type"])
+	assert.Equal(t, test.AccountA.String(), creatables[1]["creator"])
+
+	holdings := HoldingRecords(9, &delta)
+	require.Len(t, holdings, 2)
+	assert.Equal(t, "asset", holdings[0]["type"])
+	assert.Equal(t, true, holdings[0]["created"])
+	assert.Equal(t, "app", holdings[1]["type"])
+	assert.Equal(t, uint64(1), holdings[1]["index"])
+	assert.Equal(t, false, holdings[1]["created"])
+
+	for table, records := range map[string][]map[string]interface{}{
+		schema.AccountDeltaTable: accounts,
+		schema.CreatableTable:    creatables,
+		schema.HoldingTable:      holdings,
+	} {
+		s, _ := schema.ForTable(table)
+		for _, rec := range records {
+			_, err := avro.Encode(s, rec)
+			assert.NoError(t, err, table)
+		}
+	}
+}
+
+func TestDeltaWriterFileNames(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+
+	delta := makeStateDelta()
+	for round := basics.Round(5); round <= 12; round++ {
+		require.NoError(t, w.WriteDelta(round, &delta))
+	}
+	assert.Error(t, w.WriteDelta(11, &delta))
+	assert.Error(t, w.WriteDelta(14, &delta))
+
+	// The range ending at round 9 is published once round 10 is written.
+	accounts := filepath.Join(dir, schema.AccountDeltaTable)
+	assert.Equal(t, []int64{5, 5, 6, 6, 7, 7, 8, 8, 9, 9},
+		readRounds(t, filepath.Join(accounts, FileName(schema.AccountDeltaTable, 5, 9))))
+	assert.NoFileExists(t, filepath.Join(accounts, FileName(schema.AccountDeltaTable, 10, 12)))
+
+	next, err := w.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(13), next)
+
+	// Publishing writes the incomplete range under the rounds it holds.
+	require.NoError(t, w.Publish(13))
+	for _, table := range deltaTables {
+		assert.FileExists(t, filepath.Join(dir, table, FileName(table, 10, 12)))
+	}
+
+	// A new writer resumes after the published rounds.
+	w, err = MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+	assert.Error(t, w.Resume(14))
+	require.NoError(t, w.Resume(13))
+	next, err = w.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(13), next)
+}
+
+func TestDeltaWriterRetry(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+
+	// Round 4 is written again after the database failed to commit it.
+	delta := makeStateDelta()
+	require.NoError(t, w.WriteDelta(3, &delta))
+	require.NoError(t, w.WriteDelta(4, &delta))
+	require.NoError(t, w.WriteDelta(4, &delta))
+	require.NoError(t, w.WriteDelta(5, &delta))
+
+	// Round 5 was not committed either.
+	require.NoError(t, w.Publish(5))
+	assert.Equal(t, []int64{3, 3, 4, 4},
+		readRounds(t, filepath.Join(dir, schema.AccountDeltaTable, FileName(schema.AccountDeltaTable, 3, 4))))
+	assert.Equal(t, []int64{3, 3, 4, 4},
+		readRounds(t, filepath.Join(dir, schema.CreatableTable, FileName(schema.CreatableTable, 3, 4))))
+}
+
+func TestDeltaWriterResume(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+
+	delta := makeStateDelta()
+	for round := basics.Round(5); round <= 12; round++ {
+		require.NoError(t, w.WriteDelta(round, &delta))
+	}
+
+	// The process dies after syncing round 12, before the database commits
+	// it.
+	require.NoError(t, w.Close())
+	accounts := filepath.Join(dir, schema.AccountDeltaTable)
+	assert.FileExists(t, w.tmpPath(schema.AccountDeltaTable, 10, 12))
+
+	// The database continues at a round the files do not hold.
+	w, err = MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+	assert.Error(t, w.Resume(14))
+
+	w, err = MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteDelta(12, &delta))
+	require.NoError(t, w.WriteDelta(13, &delta))
+	require.NoError(t, w.Publish(14))
+	assert.Equal(t, []int64{10, 10, 11, 11, 12, 12, 13, 13},
+		readRounds(t, filepath.Join(accounts, FileName(schema.AccountDeltaTable, 10, 13))))
+	for _, table := range deltaTables {
+		entries, err := ioutil.ReadDir(filepath.Join(dir, table))
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, table)
+	}
+
+	// Nothing is left to recover.
+	w, err = MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+	require.NoError(t, w.Resume(14))
+	require.NoError(t, w.Publish(14))
+}
+
+func TestDeltaWriterResumeFirstRound(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+
+	// Only the first round of a file was synced, and it was never committed.
+	delta := makeStateDelta()
+	require.NoError(t, w.WriteDelta(20, &delta))
+	require.NoError(t, w.Close())
+
+	w, err = MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+	require.NoError(t, w.Resume(20))
+	for _, table := range deltaTables {
+		entries, err := ioutil.ReadDir(filepath.Join(dir, table))
+		require.NoError(t, err)
+		assert.Empty(t, entries, table)
+	}
+}
+
+func TestDeltaWriterNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeDeltaWriter(DeltaOptions{Dir: dir, Format: FormatNDJSON})
+	require.NoError(t, err)
+
+	delta := makeStateDelta()
+	require.NoError(t, w.WriteDelta(3, &delta))
+	require.NoError(t, w.WriteDelta(4, &delta))
+	require.NoError(t, w.Close())
+
+	// Round 4 was not committed.
+	w, err = MakeDeltaWriter(DeltaOptions{Dir: dir, Format: FormatNDJSON})
+	require.NoError(t, err)
+	require.NoError(t, w.Publish(4))
+
+	f, err := os.Open(filepath.Join(dir, schema.CreatableTable, fileName(schema.CreatableTable, 3, 3, ".ndjson")))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, lines, 2)
+	assert.Equal(t, float64(3), lines[0]["round"])
+	assert.Equal(t, "asset", lines[0]["type"])
+	assert.Equal(t, test.AccountB.String(), lines[0]["creator"])
+}
+
+func TestDeltaWriterUnknownFormat(t *testing.T) {
+	_, err := MakeDeltaWriter(DeltaOptions{Dir: t.TempDir(), Format: "csv"})
+	assert.Error(t, err)
+}
+
+func TestDeltaWriterResumePublish(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+
+	delta := makeStateDelta()
+	require.NoError(t, w.WriteDelta(8, &delta))
+	require.NoError(t, w.WriteDelta(9, &delta))
+	require.NoError(t, w.Publish(10))
+
+	// The process died while publishing, after the first table.
+	for _, table := range deltaTables[1:] {
+		require.NoError(t, os.Rename(
+			filepath.Join(dir, table, FileName(table, 8, 9)), w.tmpPath(table, 8, 9)))
+	}
+
+	w, err = MakeDeltaWriter(DeltaOptions{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+	require.NoError(t, w.Resume(10))
+	for _, table := range deltaTables {
+		assert.FileExists(t, filepath.Join(dir, table, FileName(table, 8, 9)))
+		assert.NoFileExists(t, w.tmpPath(table, 8, 9))
+	}
+	next, err := w.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), next)
+}
@@ -0,0 +1,171 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/kafka"
+)
+
+// FormatJSON is the JSON payload of the event stream, one JSON object per
+// message with byte fields base64 encoded.
+const FormatJSON Format = "json"
+
+// Header keys of the event stream messages.
+const (
+	// EventHeaderFormat holds the payload format, avro or json.
+	EventHeaderFormat = "algorand.format"
+)
+
+// EventOptions configure an EventStream.
+type EventOptions struct {
+	// Format is the payload format, FormatAvro or FormatJSON.
+	Format Format
+
+	// Checkpoint is the name of the export checkpoint that records the
+	// published rounds.
+	Checkpoint string
+}
+
+// EventStream publishes the transactions of the imported rounds to a Kafka
+// topic. Each transaction is a schema.Txn record keyed by its sender, so the
+// transactions of an account are in one partition in round order. After the
+// transactions of a round, a schema.BlockHeader record with no key is
+// written to every partition to mark the round as complete.
+//
+// The published rounds are recorded in an export checkpoint after they are
+// acknowledged. A stream that stops before recording a round publishes it
+// again, so consumers see every round at least once.
+type EventStream struct {
+	db         idb.IndexerDb
+	producer   *kafka.Producer
+	opts       EventOptions
+	checkpoint idb.ExportCheckpoint
+}
+
+// MakeEventStream creates an EventStream. Call Resume() before Publish().
+func MakeEventStream(db idb.IndexerDb, producer *kafka.Producer, opts EventOptions) (*EventStream, error) {
+	if opts.Format != FormatAvro && opts.Format != FormatJSON {
+		return nil, fmt.Errorf("MakeEventStream() unsupported format %q", opts.Format)
+	}
+	if opts.Checkpoint == "" {
+		return nil, fmt.Errorf("MakeEventStream() checkpoint name not set")
+	}
+	return &EventStream{db: db, producer: producer, opts: opts}, nil
+}
+
+// Resume loads the checkpoint of the stream. A stream without a checkpoint
+// starts at round `next`.
+func (s *EventStream) Resume(next uint64) error {
+	checkpoint, err := LoadCheckpoint(s.db, s.opts.Checkpoint, next)
+	if err != nil {
+		return fmt.Errorf("Resume() err: %w", err)
+	}
+	s.checkpoint = checkpoint
+	return nil
+}
+
+// NextRound returns the next round to publish.
+func (s *EventStream) NextRound() uint64 {
+	return s.checkpoint.NextRound
+}
+
+// encodeEvent encodes a record in the payload format.
+func encodeEvent(format Format, s *avro.Schema, record map[string]interface{}) ([]byte, error) {
+	if format == FormatJSON {
+		return json.Marshal(record)
+	}
+	return avro.Encode(s, record)
+}
+
+func eventHeaders(format Format, table string, s *avro.Schema) []kafka.Header {
+	return []kafka.Header{
+		{Key: schema.MetaTable, Value: []byte(table)},
+		{Key: schema.MetaFingerprint, Value: []byte(schema.Fingerprint(s))},
+		{Key: EventHeaderFormat, Value: []byte(format)},
+	}
+}
+
+// EventMessages returns the messages of a block: one message per
+// transaction, inner transactions included, followed by one round marker per
+// partition.
+func EventMessages(data *BlockData, format Format, partitions int) ([]kafka.Message, error) {
+	txns, err := TxnRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("EventMessages() err: %w", err)
+	}
+	roundTime := time.Unix(data.Header.TimeStamp, 0)
+
+	msgs := make([]kafka.Message, 0, len(txns)+partitions)
+	txnHeaders := eventHeaders(format, schema.TxnTable, schema.Txn)
+	for _, rec := range txns {
+		value, err := encodeEvent(format, schema.Txn, rec)
+		if err != nil {
+			return nil, fmt.Errorf("EventMessages() round %d intra %d err: %w", rec["round"], rec["intra"], err)
+		}
+		msgs = append(msgs, kafka.Message{
+			Partition: kafka.PartitionByKey,
+			Key:       []byte(rec["sender"].(string)),
+			Value:     value,
+			Headers:   txnHeaders,
+			Time:      roundTime,
+		})
+	}
+
+	marker, err := encodeEvent(format, schema.BlockHeader, HeaderRecord(&data.Header))
+	if err != nil {
+		return nil, fmt.Errorf("EventMessages() round %d header err: %w", data.Header.Round, err)
+	}
+	markerHeaders := eventHeaders(format, schema.BlockHeaderTable, schema.BlockHeader)
+	for partition := 0; partition < partitions; partition++ {
+		msgs = append(msgs, kafka.Message{
+			Partition: int32(partition),
+			Value:     marker,
+			Headers:   markerHeaders,
+			Time:      roundTime,
+		})
+	}
+	return msgs, nil
+}
+
+// Publish publishes the rounds from the checkpoint up to the round before
+// `next`, reading them from the database. The checkpoint is updated after
+// every round.
+func (s *EventStream) Publish(ctx context.Context, next uint64) error {
+	for s.checkpoint.NextRound < next {
+		round := s.checkpoint.NextRound
+		header, rows, err := s.db.GetBlock(ctx, round, idb.GetBlockOptions{Transactions: true})
+		if err != nil {
+			return fmt.Errorf("Publish() GetBlock(%d) err: %w", round, err)
+		}
+		data, err := BlockDataFromRows(header, rows)
+		if err != nil {
+			return fmt.Errorf("Publish() round %d err: %w", round, err)
+		}
+
+		partitions, err := s.producer.Partitions(ctx)
+		if err != nil {
+			return fmt.Errorf("Publish() err: %w", err)
+		}
+		msgs, err := EventMessages(&data, s.opts.Format, partitions)
+		if err != nil {
+			return fmt.Errorf("Publish() err: %w", err)
+		}
+		err = s.producer.Send(ctx, msgs)
+		if err != nil {
+			return fmt.Errorf("Publish() round %d err: %w", round, err)
+		}
+
+		s.checkpoint.NextRound = round + 1
+		err = s.db.SetExportCheckpoint(s.opts.Checkpoint, s.checkpoint)
+		if err != nil {
+			return fmt.Errorf("Publish() err: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/kafka"
+	"github.com/algorand/indexer/kafka/kafkatest"
+	"github.com/algorand/indexer/util/test"
+)
+
+func TestEventMessages(t *testing.T) {
+	pay := test.MakePaymentTxn(1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	appCall := test.MakeAppCallWithInnerTxn(test.AccountC, test.AccountB, test.AccountA, test.AccountD, test.AccountE)
+	data := makeBlockData(t, 5, pay, appCall)
+
+	msgs, err := EventMessages(&data, FormatAvro, 2)
+	require.NoError(t, err)
+	txns, err := TxnRecords(&data)
+	require.NoError(t, err)
+	require.Len(t, msgs, len(txns)+2)
+
+	for i, rec := range txns {
+		msg := msgs[i]
+		assert.Equal(t, kafka.PartitionByKey, msg.Partition)
+		assert.Equal(t, rec["sender"], string(msg.Key))
+		assert.Equal(t, []byte(schema.TxnTable), msg.Headers[0].Value)
+		decoded, rest, err := avro.Decode(schema.Txn, msg.Value)
+		require.NoError(t, err)
+		assert.Empty(t, rest)
+		assert.Equal(t, int64(5), decoded.(map[string]interface{})["round"])
+		assert.Equal(t, int64(rec["intra"].(uint64)), decoded.(map[string]interface{})["intra"])
+		assert.Equal(t, int64(1234), msg.Time.Unix())
+	}
+	for partition, msg := range msgs[len(txns):] {
+		assert.Equal(t, int32(partition), msg.Partition)
+		assert.Nil(t, msg.Key)
+		assert.Equal(t, []byte(schema.BlockHeaderTable), msg.Headers[0].Value)
+	}
+
+	msgs, err = EventMessages(&data, FormatJSON, 1)
+	require.NoError(t, err)
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(msgs[0].Value, &rec))
+	assert.Equal(t, test.AccountA.String(), rec["sender"])
+	assert.Equal(t, []byte("json"), msgs[0].Headers[2].Value)
+}
+
+func TestEventStreamPublish(t *testing.T) {
+	broker, err := kafkatest.NewBroker()
+	require.NoError(t, err)
+	defer broker.Close()
+	broker.CreateTopic("events", 2)
+	producer, err := kafka.MakeProducer(kafka.ProducerOptions{
+		Brokers: []string{broker.Addr()},
+		Topic:   "events",
+		Timeout: 5 * time.Second,
+	})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	pay := test.MakePaymentTxn(1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	db := &mocks.IndexerDb{}
+	db.On("GetExportCheckpoint", "kafka").Return(idb.ExportCheckpoint{NextRound: 3}, nil)
+	db.On("GetBlock", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, round uint64, options idb.GetBlockOptions) bookkeeping.BlockHeader {
+			header := test.MakeGenesisBlock().BlockHeader
+			header.Round = basics.Round(round)
+			return header
+		},
+		[]idb.TxnRow{{Txn: &pay}}, nil)
+	var saved []uint64
+	db.On("SetExportCheckpoint", "kafka", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(idb.ExportCheckpoint).NextRound)
+	})
+
+	stream, err := MakeEventStream(db, producer, EventOptions{Format: FormatAvro, Checkpoint: "kafka"})
+	require.NoError(t, err)
+	require.NoError(t, stream.Resume(10))
+	assert.Equal(t, uint64(3), stream.NextRound())
+
+	require.NoError(t, stream.Publish(context.Background(), 5))
+	assert.Equal(t, []uint64{4, 5}, saved)
+	assert.Equal(t, uint64(5), stream.NextRound())
+
+	// Each round is one transaction and one marker per partition.
+	partition := kafka.PartitionForKey([]byte(test.AccountA.String()), 2)
+	assert.Len(t, broker.Messages("events", int(partition)), 4)
+	assert.Len(t, broker.Messages("events", int(1-partition)), 2)
+	last := broker.Messages("events", int(partition))[3]
+	decoded, _, err := avro.Decode(schema.BlockHeader, last.Value)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), decoded.(map[string]interface{})["round"])
+}
@@ -0,0 +1,45 @@
+// Package exporter writes indexer data as Avro Object Container Files, split
+// into files by round range.
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// ExportRounds reads the rounds [first, last] from the database and writes
+// them with w. The files of the last round range are left open, call
+// w.Close() to finish them.
+func ExportRounds(ctx context.Context, db idb.IndexerDb, w *Writer, first, last uint64, logger *log.Logger) error {
+	for round := first; round <= last; round++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("ExportRounds() ctx.Err(): %w", err)
+		}
+
+		header, rows, err := db.GetBlock(ctx, round, idb.GetBlockOptions{Transactions: true})
+		if err != nil {
+			return fmt.Errorf("ExportRounds() GetBlock(%d) err: %w", round, err)
+		}
+		data, err := BlockDataFromRows(header, rows)
+		if err != nil {
+			return fmt.Errorf("ExportRounds() round %d err: %w", round, err)
+		}
+		err = w.WriteBlock(&data)
+		if err != nil {
+			return fmt.Errorf("ExportRounds() err: %w", err)
+		}
+
+		if logger != nil && (round%1000 == 0 || round == last) {
+			logger.Infof("exported round %d", round)
+		}
+		if round == last {
+			// Avoid overflow when last is the maximum uint64.
+			break
+		}
+	}
+	return nil
+}
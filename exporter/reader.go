@@ -0,0 +1,233 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+)
+
+// rangeFiles returns the first and last rounds of the files of a table,
+// sorted by first round.
+func rangeFiles(dir, table string) ([][2]uint64, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(dir, table))
+	if err != nil {
+		return nil, err
+	}
+	var res [][2]uint64
+	for _, entry := range entries {
+		if first, last, ok := parseFileName(table, entry.Name(), ".avro"); ok {
+			res = append(res, [2]uint64{first, last})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i][0] < res[j][0] })
+	return res, nil
+}
+
+// recordFile reads the records of one exported file.
+type recordFile struct {
+	file   *os.File
+	reader *avro.Reader
+	// next is the record read ahead, nil at the end of the file.
+	next map[string]interface{}
+}
+
+func openRecordFile(path string) (*recordFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := avro.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	rf := &recordFile{file: f, reader: reader}
+	if err = rf.advance(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rf, nil
+}
+
+func (rf *recordFile) advance() error {
+	v, err := rf.reader.Next()
+	if err == io.EOF {
+		rf.next = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rec, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("advance() unexpected record %T", v)
+	}
+	rf.next = rec
+	return nil
+}
+
+func (rf *recordFile) close() {
+	rf.file.Close()
+}
+
+// recordRound returns the round of a header or transaction record.
+func recordRound(rec map[string]interface{}) (uint64, error) {
+	round, ok := rec["round"].(int64)
+	if !ok {
+		return 0, fmt.Errorf("record without round")
+	}
+	return uint64(round), nil
+}
+
+func recordBytes(rec map[string]interface{}, field string) ([]byte, error) {
+	b, ok := rec[field].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("record without %s", field)
+	}
+	return b, nil
+}
+
+// blockFromRecords rebuilds a block from its header record and the records of
+// its transactions. The payset is encoded from the root transactions; inner
+// transactions are part of the apply data of their root. The payset
+// commitment must match the header.
+func blockFromRecords(headerRec map[string]interface{}, txns []map[string]interface{}) (bookkeeping.Block, error) {
+	var block bookkeeping.Block
+	b, err := recordBytes(headerRec, "header_msgpack")
+	if err != nil {
+		return bookkeeping.Block{}, err
+	}
+	err = protocol.Decode(b, &block.BlockHeader)
+	if err != nil {
+		return bookkeeping.Block{}, fmt.Errorf("decode header err: %w", err)
+	}
+
+	for _, rec := range txns {
+		if rec["root_intra"] != nil {
+			// Inner transaction.
+			continue
+		}
+		b, err := recordBytes(rec, "txn_msgpack")
+		if err != nil {
+			return bookkeeping.Block{}, err
+		}
+		var stxnad transactions.SignedTxnWithAD
+		err = protocol.Decode(b, &stxnad)
+		if err != nil {
+			return bookkeeping.Block{}, fmt.Errorf("decode txn intra %v err: %w", rec["intra"], err)
+		}
+		stib, err := block.EncodeSignedTxn(stxnad.SignedTxn, stxnad.ApplyData)
+		if err != nil {
+			return bookkeeping.Block{}, fmt.Errorf("encode txn intra %v err: %w", rec["intra"], err)
+		}
+		block.Payset = append(block.Payset, stib)
+	}
+
+	commit, err := block.PaysetCommit()
+	if err != nil {
+		return bookkeeping.Block{}, fmt.Errorf("payset commit err: %w", err)
+	}
+	if commit != block.TxnRoot {
+		return bookkeeping.Block{}, fmt.Errorf("payset does not match the txn root of the header")
+	}
+	return block, nil
+}
+
+// readRange reads the blocks of one round range, calling `handler` for the
+// rounds from `next` on. Returns the round after the last block read.
+func readRange(ctx context.Context, dir string, first, last, next uint64, handler func(*bookkeeping.Block) error) (uint64, error) {
+	headers, err := openRecordFile(filepath.Join(dir, schema.BlockHeaderTable, FileName(schema.BlockHeaderTable, first, last)))
+	if err != nil {
+		return next, err
+	}
+	defer headers.close()
+	txns, err := openRecordFile(filepath.Join(dir, schema.TxnTable, FileName(schema.TxnTable, first, last)))
+	if err != nil {
+		return next, err
+	}
+	defer txns.close()
+
+	for headers.next != nil {
+		if err := ctx.Err(); err != nil {
+			return next, err
+		}
+		round, err := recordRound(headers.next)
+		if err != nil {
+			return next, err
+		}
+
+		// The transactions are sorted by round and intra like the headers.
+		var records []map[string]interface{}
+		for txns.next != nil {
+			txnRound, err := recordRound(txns.next)
+			if err != nil {
+				return next, err
+			}
+			if txnRound > round {
+				break
+			}
+			if txnRound == round {
+				records = append(records, txns.next)
+			}
+			if err = txns.advance(); err != nil {
+				return next, err
+			}
+		}
+
+		if round > next {
+			return next, fmt.Errorf("round %d is missing", next)
+		}
+		if round == next {
+			block, err := blockFromRecords(headers.next, records)
+			if err != nil {
+				return next, fmt.Errorf("round %d: %w", round, err)
+			}
+			err = handler(&block)
+			if err != nil {
+				return next, fmt.Errorf("round %d: %w", round, err)
+			}
+			next++
+		}
+		if err = headers.advance(); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
+
+// ReadBlocks reads the blocks of an export written by Writer back, in round
+// order starting at round `next`, and calls `handler` with each of them.
+// Rounds before `next` are skipped, so overlapping round ranges of several
+// exports are read once. Returns the round after the last block read, and an
+// error if a round after `next` is missing or a block does not match its
+// header.
+func ReadBlocks(ctx context.Context, dir string, next uint64, handler func(*bookkeeping.Block) error) (uint64, error) {
+	ranges, err := rangeFiles(dir, schema.BlockHeaderTable)
+	if err != nil {
+		return next, fmt.Errorf("ReadBlocks() err: %w", err)
+	}
+	for _, r := range ranges {
+		if r[1] < next {
+			continue
+		}
+		if r[0] > next {
+			return next, fmt.Errorf("ReadBlocks() round %d is missing", next)
+		}
+		next, err = readRange(ctx, dir, r[0], r[1], next, handler)
+		if err != nil {
+			return next, fmt.Errorf("ReadBlocks() err: %w", err)
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,114 @@
+package exporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/util/test"
+)
+
+// makeBlocks returns the blocks of rounds 0..n-1 with one payment per round
+// after the genesis block.
+func makeBlocks(t *testing.T, n int) []bookkeeping.Block {
+	genesis := test.MakeGenesisBlock()
+	commit, err := genesis.PaysetCommit()
+	require.NoError(t, err)
+	genesis.TxnRoot = commit
+	blocks := []bookkeeping.Block{genesis}
+	for i := 1; i < n; i++ {
+		pay := test.MakePaymentTxn(1000, uint64(i), 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+		block, err := test.MakeBlockForTxns(blocks[i-1].BlockHeader, &pay)
+		require.NoError(t, err)
+		block.TxnRoot, err = block.PaysetCommit()
+		require.NoError(t, err)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func exportBlocks(t *testing.T, dir string, blocks []bookkeeping.Block, first, last uint64) {
+	w, err := MakeWriter(Options{Dir: dir, RoundsPerFile: 5, FirstRound: first, LastRound: &last})
+	require.NoError(t, err)
+	for round := first; round <= last; round++ {
+		data, err := BlockDataFromBlock(&blocks[round])
+		require.NoError(t, err)
+		require.NoError(t, w.WriteBlock(&data))
+	}
+	require.NoError(t, w.Close())
+}
+
+func TestReadBlocks(t *testing.T) {
+	dir := t.TempDir()
+	blocks := makeBlocks(t, 13)
+	// The second export overlaps the last range of the first one.
+	exportBlocks(t, dir, blocks, 0, 6)
+	exportBlocks(t, dir, blocks, 5, 12)
+
+	var read []bookkeeping.Block
+	next, err := ReadBlocks(context.Background(), dir, 0, func(block *bookkeeping.Block) error {
+		read = append(read, *block)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(13), next)
+	assert.Equal(t, blocks, read)
+
+	// Reading resumes at `next`.
+	read = nil
+	next, err = ReadBlocks(context.Background(), dir, 8, func(block *bookkeeping.Block) error {
+		read = append(read, *block)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(13), next)
+	assert.Equal(t, blocks[8:], read)
+}
+
+func TestReadBlocksMissingRange(t *testing.T) {
+	dir := t.TempDir()
+	blocks := makeBlocks(t, 15)
+	exportBlocks(t, dir, blocks, 0, 4)
+	exportBlocks(t, dir, blocks, 10, 14)
+
+	count := 0
+	next, err := ReadBlocks(context.Background(), dir, 0, func(block *bookkeeping.Block) error {
+		count++
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, uint64(5), next)
+	assert.Equal(t, 5, count)
+}
+
+func TestReadBlocksChecksTxnRoot(t *testing.T) {
+	dir := t.TempDir()
+	blocks := makeBlocks(t, 3)
+	blocks[2].TxnRoot = blocks[1].TxnRoot
+	exportBlocks(t, dir, blocks, 0, 2)
+
+	next, err := ReadBlocks(context.Background(), dir, 0, func(block *bookkeeping.Block) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, uint64(2), next)
+}
+
+func TestReadBlocksMissingTxnFile(t *testing.T) {
+	dir := t.TempDir()
+	blocks := makeBlocks(t, 3)
+	exportBlocks(t, dir, blocks, 0, 2)
+	require.NoError(t, os.Remove(filepath.Join(dir, schema.TxnTable, FileName(schema.TxnTable, 0, 2))))
+
+	_, err := ReadBlocks(context.Background(), dir, 0, func(block *bookkeeping.Block) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
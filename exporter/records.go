@@ -0,0 +1,321 @@
+package exporter
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/accounting"
+	"github.com/algorand/indexer/idb"
+)
+
+// RootTxn is a top level transaction of a block.
+type RootTxn struct {
+	Txn transactions.SignedTxnWithAD
+
+	// AssetID is the asset or application id related to the transaction, as in
+	// idb.TxnRow.
+	AssetID uint64
+
+	// AssetCloseAmount is the asset close amount computed by the evaluator.
+	AssetCloseAmount uint64
+}
+
+// BlockData is a block header with its top level transactions in payset order.
+type BlockData struct {
+	Header bookkeeping.BlockHeader
+	Txns   []RootTxn
+}
+
+// BlockDataFromRows builds a BlockData from the result of IndexerDb.GetBlock().
+// Inner transaction rows are skipped because their content is reconstructed
+// from the root transaction.
+func BlockDataFromRows(header bookkeeping.BlockHeader, rows []idb.TxnRow) (BlockData, error) {
+	res := BlockData{Header: header}
+	for _, row := range rows {
+		if row.Error != nil {
+			return BlockData{}, fmt.Errorf("BlockDataFromRows() row err: %w", row.Error)
+		}
+		if row.Txn == nil {
+			// Inner transaction.
+			continue
+		}
+		res.Txns = append(res.Txns, RootTxn{
+			Txn:              *row.Txn,
+			AssetID:          row.AssetID,
+			AssetCloseAmount: row.Extra.AssetCloseAmount,
+		})
+	}
+	return res, nil
+}
+
+// BlockDataFromBlock builds a BlockData from a block received from algod.
+// Without the evaluator the asset close amount is only known for blocks whose
+// apply data includes it.
+func BlockDataFromBlock(block *bookkeeping.Block) (BlockData, error) {
+	res := BlockData{
+		Header: block.BlockHeader,
+		Txns:   make([]RootTxn, 0, len(block.Payset)),
+	}
+	for i, stib := range block.Payset {
+		var stxnad transactions.SignedTxnWithAD
+		var err error
+		// Sets the genesis information so that transaction ids are correct.
+		stxnad.SignedTxn, stxnad.ApplyData, err = block.BlockHeader.DecodeSignedTxn(stib)
+		if err != nil {
+			return BlockData{}, fmt.Errorf("BlockDataFromBlock() decode signed txn err: %w", err)
+		}
+		assetID, err := idb.TransactionAssetID(&stxnad, uint(i), block)
+		if err != nil {
+			return BlockData{}, fmt.Errorf("BlockDataFromBlock() err: %w", err)
+		}
+		res.Txns = append(res.Txns, RootTxn{
+			Txn:              stxnad,
+			AssetID:          assetID,
+			AssetCloseAmount: stxnad.ApplyData.AssetClosingAmount,
+		})
+	}
+	return res, nil
+}
+
+func addrOrNil(addr basics.Address) interface{} {
+	if addr.IsZero() {
+		return nil
+	}
+	return addr.String()
+}
+
+func bytesOrNil(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// HeaderRecord converts a block header to a schema.BlockHeader record.
+func HeaderRecord(header *bookkeeping.BlockHeader) map[string]interface{} {
+	var nextProtocol, upgradePropose interface{}
+	if header.NextProtocol != "" {
+		nextProtocol = string(header.NextProtocol)
+	}
+	if header.UpgradePropose != "" {
+		upgradePropose = string(header.UpgradePropose)
+	}
+
+	return map[string]interface{}{
+		"round":                     uint64(header.Round),
+		"timestamp":                 header.TimeStamp,
+		"genesis_id":                header.GenesisID,
+		"genesis_hash":              header.GenesisHash[:],
+		"prev":                      header.Branch[:],
+		"seed":                      header.Seed[:],
+		"txn_root":                  header.TxnRoot[:],
+		"txn_counter":               header.TxnCounter,
+		"fee_sink":                  header.FeeSink.String(),
+		"rewards_pool":              header.RewardsPool.String(),
+		"rewards_level":             header.RewardsLevel,
+		"rewards_rate":              header.RewardsRate,
+		"rewards_residue":           header.RewardsResidue,
+		"rewards_calculation_round": uint64(header.RewardsRecalculationRound),
+		"current_protocol":          string(header.CurrentProtocol),
+		"next_protocol":             nextProtocol,
+		"next_protocol_approvals":   header.NextProtocolApprovals,
+		"next_protocol_vote_before": uint64(header.NextProtocolVoteBefore),
+		"next_protocol_switch_on":   uint64(header.NextProtocolSwitchOn),
+		"upgrade_propose":           upgradePropose,
+		"upgrade_delay":             uint64(header.UpgradeDelay),
+		"upgrade_approve":           header.UpgradeApprove,
+		"header_msgpack":            protocol.Encode(header),
+	}
+}
+
+type txnPosition struct {
+	round     uint64
+	roundTime int64
+	intra     uint64
+	txid      string
+	rootIntra *uint64
+	rootTxid  string
+}
+
+func txnRecord(stxnad *transactions.SignedTxnWithAD, pos txnPosition, assetID uint64, assetCloseAmount uint64) (map[string]interface{}, error) {
+	txn := &stxnad.Txn
+	typeenum, ok := idb.GetTypeEnum(txn.Type)
+	if !ok {
+		return nil, fmt.Errorf("txnRecord() unknown transaction type %q", txn.Type)
+	}
+
+	rec := map[string]interface{}{
+		"round":              pos.round,
+		"intra":              pos.intra,
+		"round_time":         pos.roundTime,
+		"txid":               nil,
+		"root_intra":         nil,
+		"root_txid":          nil,
+		"type":               string(txn.Type),
+		"type_enum":          int(typeenum),
+		"asset_id":           assetID,
+		"sender":             txn.Sender.String(),
+		"fee":                txn.Fee.Raw,
+		"first_valid":        uint64(txn.FirstValid),
+		"last_valid":         uint64(txn.LastValid),
+		"note":               bytesOrNil(txn.Note),
+		"group":              nil,
+		"lease":              nil,
+		"rekey_to":           addrOrNil(txn.RekeyTo),
+		"sig_type":           nil,
+		"receiver":           nil,
+		"amount":             nil,
+		"close_remainder_to": nil,
+		"close_amount":       nil,
+		"asset_receiver":     nil,
+		"asset_amount":       nil,
+		"asset_sender":       nil,
+		"asset_close_to":     nil,
+		"asset_close_amount": nil,
+		"application_id":     nil,
+		"on_completion":      nil,
+		"sender_rewards":     stxnad.ApplyData.SenderRewards.Raw,
+		"receiver_rewards":   stxnad.ApplyData.ReceiverRewards.Raw,
+		"close_rewards":      stxnad.ApplyData.CloseRewards.Raw,
+		"txn_msgpack":        protocol.Encode(stxnad),
+	}
+
+	if pos.rootIntra == nil {
+		rec["txid"] = pos.txid
+	} else {
+		rec["root_intra"] = *pos.rootIntra
+		rec["root_txid"] = pos.rootTxid
+	}
+	if !txn.Group.IsZero() {
+		rec["group"] = txn.Group[:]
+	}
+	if txn.Lease != ([32]byte{}) {
+		rec["lease"] = txn.Lease[:]
+	}
+	if sigtype, err := idb.SignatureType(&stxnad.SignedTxn); err == nil {
+		rec["sig_type"] = string(sigtype)
+	}
+
+	switch txn.Type {
+	case protocol.PaymentTx:
+		rec["receiver"] = txn.Receiver.String()
+		rec["amount"] = txn.Amount.Raw
+		rec["close_remainder_to"] = addrOrNil(txn.CloseRemainderTo)
+		rec["close_amount"] = stxnad.ApplyData.ClosingAmount.Raw
+	case protocol.AssetTransferTx:
+		rec["asset_receiver"] = txn.AssetReceiver.String()
+		rec["asset_amount"] = txn.AssetAmount
+		rec["asset_sender"] = addrOrNil(txn.AssetSender)
+		rec["asset_close_to"] = addrOrNil(txn.AssetCloseTo)
+		rec["asset_close_amount"] = assetCloseAmount
+	case protocol.ApplicationCallTx:
+		rec["application_id"] = assetID
+		rec["on_completion"] = int(txn.OnCompletion)
+	}
+
+	return rec, nil
+}
+
+// innerTxnRecords appends the records of the inner transactions of stxnad in
+// preorder, returning the intra of the next transaction.
+func innerTxnRecords(stxnad *transactions.SignedTxnWithAD, pos txnPosition, records []map[string]interface{}) (uint64, []map[string]interface{}, error) {
+	for i := range stxnad.ApplyData.EvalDelta.InnerTxns {
+		itxn := &stxnad.ApplyData.EvalDelta.InnerTxns[i]
+		assetID, err := idb.TransactionAssetID(itxn, 0, nil)
+		if err != nil {
+			return 0, nil, err
+		}
+		rec, err := txnRecord(itxn, pos, assetID, itxn.ApplyData.AssetClosingAmount)
+		if err != nil {
+			return 0, nil, err
+		}
+		records = append(records, rec)
+
+		pos.intra++
+		pos.intra, records, err = innerTxnRecords(itxn, pos, records)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	return pos.intra, records, nil
+}
+
+// TxnRecords converts the transactions of a block to schema.Txn records. Inner
+// transactions are flattened in preorder, so that the intra of each record
+// matches the txn table.
+func TxnRecords(data *BlockData) ([]map[string]interface{}, error) {
+	records := make([]map[string]interface{}, 0, len(data.Txns))
+	intra := uint64(0)
+	for i := range data.Txns {
+		root := &data.Txns[i]
+		rootIntra := intra
+		pos := txnPosition{
+			round:     uint64(data.Header.Round),
+			roundTime: data.Header.TimeStamp,
+			intra:     intra,
+			txid:      root.Txn.Txn.ID().String(),
+		}
+		rec, err := txnRecord(&root.Txn, pos, root.AssetID, root.AssetCloseAmount)
+		if err != nil {
+			return nil, fmt.Errorf("TxnRecords() err: %w", err)
+		}
+		records = append(records, rec)
+
+		pos.intra = intra + 1
+		pos.rootIntra = &rootIntra
+		pos.rootTxid = pos.txid
+		intra, records, err = innerTxnRecords(&root.Txn, pos, records)
+		if err != nil {
+			return nil, fmt.Errorf("TxnRecords() err: %w", err)
+		}
+	}
+	return records, nil
+}
+
+func participationRecords(stxnad *transactions.SignedTxnWithAD, includeInner bool, round, intra uint64, records []map[string]interface{}) []map[string]interface{} {
+	seen := make(map[basics.Address]bool)
+	add := func(address basics.Address) {
+		if seen[address] {
+			return
+		}
+		seen[address] = true
+		records = append(records, map[string]interface{}{
+			"addr":  address.String(),
+			"round": round,
+			"intra": intra,
+		})
+	}
+	accounting.GetTransactionParticipants(stxnad, includeInner, add)
+	return records
+}
+
+func innerParticipationRecords(stxnad *transactions.SignedTxnWithAD, round, intra uint64, records []map[string]interface{}) (uint64, []map[string]interface{}) {
+	next := intra
+	for i := range stxnad.ApplyData.EvalDelta.InnerTxns {
+		itxn := &stxnad.ApplyData.EvalDelta.InnerTxns[i]
+		// Inner transactions only list their direct participants.
+		records = participationRecords(itxn, false, round, next, records)
+		next, records = innerParticipationRecords(itxn, round, next+1, records)
+	}
+	return next, records
+}
+
+// TxnParticipationRecords converts the transactions of a block to
+// schema.TxnParticipation records, following the rules used to fill the
+// txn_participation table: a root transaction lists the participants of all of
+// its inner transactions, an inner transaction only its direct participants.
+func TxnParticipationRecords(data *BlockData) []map[string]interface{} {
+	var records []map[string]interface{}
+	round := uint64(data.Header.Round)
+	next := uint64(0)
+	for i := range data.Txns {
+		stxnad := &data.Txns[i].Txn
+		records = participationRecords(stxnad, true, round, next, records)
+		next, records = innerParticipationRecords(stxnad, round, next+1, records)
+	}
+	return records
+}
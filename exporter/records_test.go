@@ -0,0 +1,168 @@
+package exporter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/util/test"
+)
+
+func makeBlockData(t *testing.T, round uint64, txns ...transactions.SignedTxnWithAD) BlockData {
+	header := test.MakeGenesisBlock().BlockHeader
+	header.Round = basics.Round(round)
+	header.TimeStamp = 1234
+	data := BlockData{Header: header}
+	for i := range txns {
+		data.Txns = append(data.Txns, RootTxn{Txn: txns[i], AssetID: uint64(i)})
+	}
+	return data
+}
+
+func TestHeaderRecord(t *testing.T) {
+	data := makeBlockData(t, 5)
+	rec := HeaderRecord(&data.Header)
+
+	buf, err := avro.Encode(schema.BlockHeader, rec)
+	require.NoError(t, err)
+	decoded, _, err := avro.Decode(schema.BlockHeader, buf)
+	require.NoError(t, err)
+	out := decoded.(map[string]interface{})
+	assert.Equal(t, int64(5), out["round"])
+	assert.Equal(t, int64(1234), out["timestamp"])
+	assert.Equal(t, test.FeeAddr.String(), out["fee_sink"])
+	assert.Equal(t, string(test.Proto), out["current_protocol"])
+	assert.Nil(t, out["next_protocol"])
+
+	// The msgpack column holds the complete header.
+	var header bookkeeping.BlockHeader
+	err = protocol.Decode(out["header_msgpack"].([]byte), &header)
+	require.NoError(t, err)
+	assert.Equal(t, data.Header, header)
+}
+
+func TestTxnRecordsInnerFlattening(t *testing.T) {
+	pay := test.MakePaymentTxn(1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	appCall := test.MakeAppCallWithInnerTxn(test.AccountA, test.AccountB, test.AccountC, test.AccountD, test.AccountE)
+	data := makeBlockData(t, 3, pay, appCall)
+
+	records, err := TxnRecords(&data)
+	require.NoError(t, err)
+	// payment, app call, 2 inner payments, 1 nested asset transfer.
+	require.Len(t, records, 5)
+
+	for i, rec := range records {
+		assert.Equal(t, uint64(i), rec["intra"])
+		assert.Equal(t, uint64(3), rec["round"])
+		_, err := avro.Encode(schema.Txn, rec)
+		require.NoError(t, err)
+	}
+
+	appTxid := appCall.Txn.ID().String()
+	assert.Equal(t, pay.Txn.ID().String(), records[0]["txid"])
+	assert.Nil(t, records[0]["root_intra"])
+	assert.Equal(t, test.AccountB.String(), records[0]["receiver"])
+	assert.Equal(t, "sig", records[0]["sig_type"])
+
+	assert.Equal(t, appTxid, records[1]["txid"])
+	assert.Equal(t, uint64(1), records[1]["asset_id"])
+
+	for _, i := range []int{2, 3, 4} {
+		assert.Nil(t, records[i]["txid"])
+		assert.Equal(t, uint64(1), records[i]["root_intra"])
+		assert.Equal(t, appTxid, records[i]["root_txid"])
+		assert.Nil(t, records[i]["sig_type"])
+	}
+	assert.Equal(t, uint64(12), records[2]["amount"])
+	assert.Equal(t, uint64(123), records[3]["amount"])
+	assert.Equal(t, string(protocol.AssetTransferTx), records[4]["type"])
+	assert.Equal(t, uint64(456), records[4]["asset_amount"])
+
+	// The msgpack column of a root transaction includes its inner transactions.
+	var stxnad transactions.SignedTxnWithAD
+	err = protocol.Decode(records[1]["txn_msgpack"].([]byte), &stxnad)
+	require.NoError(t, err)
+	assert.Equal(t, appTxid, stxnad.Txn.ID().String())
+	assert.Len(t, stxnad.ApplyData.EvalDelta.InnerTxns, 2)
+}
+
+func TestTxnRecordMaxUint64Amount(t *testing.T) {
+	axfer := test.MakeAssetTransferTxn(1, math.MaxUint64, test.AccountA, test.AccountB, test.AccountC)
+	data := makeBlockData(t, 3, axfer)
+	records, err := TxnRecords(&data)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	buf, err := avro.Encode(schema.Txn, records[0])
+	require.NoError(t, err)
+	decoded, _, err := avro.Decode(schema.Txn, buf)
+	require.NoError(t, err)
+	out := decoded.(map[string]interface{})
+	assert.Equal(t, uint64(math.MaxUint64), uint64(out["asset_amount"].(int64)))
+}
+
+func TestTxnParticipationRecords(t *testing.T) {
+	pay := test.MakePaymentTxn(1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	appCall := test.MakeAppCallWithInnerTxn(test.AccountA, test.AccountB, test.AccountC, test.AccountD, test.AccountE)
+	data := makeBlockData(t, 3, pay, appCall)
+
+	type key struct {
+		addr  string
+		intra uint64
+	}
+	got := make(map[key]bool)
+	for _, rec := range TxnParticipationRecords(&data) {
+		_, err := avro.Encode(schema.TxnParticipation, rec)
+		require.NoError(t, err)
+		k := key{rec["addr"].(string), rec["intra"].(uint64)}
+		assert.False(t, got[k], "duplicate participation %v", k)
+		got[k] = true
+	}
+
+	expected := map[key]bool{
+		{test.AccountA.String(), 0}: true,
+		{test.AccountB.String(), 0}: true,
+		// The root app call includes all inner participants.
+		{test.AccountA.String(), 1}: true,
+		{test.AccountB.String(), 1}: true,
+		{test.AccountC.String(), 1}: true,
+		{test.AccountD.String(), 1}: true,
+		{test.AccountE.String(), 1}: true,
+		// Inner transactions only include direct participants.
+		{test.AccountB.String(), 2}: true,
+		{test.AccountC.String(), 2}: true,
+		{test.AccountB.String(), 3}: true,
+		{test.AccountC.String(), 3}: true,
+		{test.AccountD.String(), 4}: true,
+		{test.AccountE.String(), 4}: true,
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestBlockDataFromRows(t *testing.T) {
+	appCall := test.MakeAppCallWithInnerTxn(test.AccountA, test.AccountB, test.AccountC, test.AccountD, test.AccountE)
+	header := test.MakeGenesisBlock().BlockHeader
+	rows := []idb.TxnRow{
+		{Round: 0, Intra: 0, Txn: &appCall, AssetID: 7},
+		{Round: 0, Intra: 1, RootTxn: &appCall, Extra: idb.TxnExtra{RootIntra: idb.OptionalUint{Present: true}}},
+		{Round: 0, Intra: 2, RootTxn: &appCall, Extra: idb.TxnExtra{RootIntra: idb.OptionalUint{Present: true}}},
+	}
+
+	data, err := BlockDataFromRows(header, rows)
+	require.NoError(t, err)
+	require.Len(t, data.Txns, 1)
+	assert.Equal(t, uint64(7), data.Txns[0].AssetID)
+
+	rows = append(rows, idb.TxnRow{Error: assert.AnError})
+	_, err = BlockDataFromRows(header, rows)
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/algorand/indexer/avro"
+)
+
+// The field maps document how the fields of the go-algorand types are derived
+// into schema fields. Fields that are only kept in the msgpack column map to
+// that column. CheckDerived uses them to detect go-algorand fields that are not
+// covered by a schema yet.
+
+// BlockHeaderFields maps bookkeeping.BlockHeader fields to BlockHeader fields.
+var BlockHeaderFields = map[string]string{
+	"Round":                     "round",
+	"Branch":                    "prev",
+	"Seed":                      "seed",
+	"TxnRoot":                   "txn_root",
+	"TimeStamp":                 "timestamp",
+	"GenesisID":                 "genesis_id",
+	"GenesisHash":               "genesis_hash",
+	"FeeSink":                   "fee_sink",
+	"RewardsPool":               "rewards_pool",
+	"RewardsLevel":              "rewards_level",
+	"RewardsRate":               "rewards_rate",
+	"RewardsResidue":            "rewards_residue",
+	"RewardsRecalculationRound": "rewards_calculation_round",
+	"CurrentProtocol":           "current_protocol",
+	"NextProtocol":              "next_protocol",
+	"NextProtocolApprovals":     "next_protocol_approvals",
+	"NextProtocolVoteBefore":    "next_protocol_vote_before",
+	"NextProtocolSwitchOn":      "next_protocol_switch_on",
+	"UpgradePropose":            "upgrade_propose",
+	"UpgradeDelay":              "upgrade_delay",
+	"UpgradeApprove":            "upgrade_approve",
+	"TxnCounter":                "txn_counter",
+	"CompactCert":               "header_msgpack",
+}
+
+// AccountDataFields maps basics.AccountData fields to AccountDelta fields.
+var AccountDataFields = map[string]string{
+	"Status":             "status",
+	"MicroAlgos":         "microalgos",
+	"RewardsBase":        "rewards_base",
+	"RewardedMicroAlgos": "rewarded_microalgos",
+	"VoteID":             "vote_id",
+	"SelectionID":        "selection_id",
+	"VoteFirstValid":     "vote_first_valid",
+	"VoteLastValid":      "vote_last_valid",
+	"VoteKeyDilution":    "vote_key_dilution",
+	"AssetParams":        "account_msgpack",
+	"Assets":             "account_msgpack",
+	"AuthAddr":           "auth_addr",
+	"AppLocalStates":     "account_msgpack",
+	"AppParams":          "account_msgpack",
+	"TotalAppSchema":     "total_app_schema_num_uint",
+	"TotalExtraAppPages": "total_extra_app_pages",
+}
+
+// TxnFields maps transactions.Transaction fields to Txn fields. The fields of
+// the embedded per type structs are listed flat.
+var TxnFields = map[string]string{
+	"Type": "type",
+
+	// transactions.Header
+	"Sender":      "sender",
+	"Fee":         "fee",
+	"FirstValid":  "first_valid",
+	"LastValid":   "last_valid",
+	"Note":        "note",
+	"GenesisID":   "txn_msgpack",
+	"GenesisHash": "txn_msgpack",
+	"Group":       "group",
+	"Lease":       "lease",
+	"RekeyTo":     "rekey_to",
+
+	// transactions.KeyregTxnFields
+	"VotePK":           "txn_msgpack",
+	"SelectionPK":      "txn_msgpack",
+	"VoteFirst":        "txn_msgpack",
+	"VoteLast":         "txn_msgpack",
+	"VoteKeyDilution":  "txn_msgpack",
+	"Nonparticipation": "txn_msgpack",
+
+	// transactions.PaymentTxnFields
+	"Receiver":         "receiver",
+	"Amount":           "amount",
+	"CloseRemainderTo": "close_remainder_to",
+
+	// transactions.AssetConfigTxnFields
+	"ConfigAsset": "asset_id",
+	"AssetParams": "txn_msgpack",
+
+	// transactions.AssetTransferTxnFields
+	"XferAsset":     "asset_id",
+	"AssetAmount":   "asset_amount",
+	"AssetSender":   "asset_sender",
+	"AssetReceiver": "asset_receiver",
+	"AssetCloseTo":  "asset_close_to",
+
+	// transactions.AssetFreezeTxnFields
+	"FreezeAccount": "txn_msgpack",
+	"FreezeAsset":   "asset_id",
+	"AssetFrozen":   "txn_msgpack",
+
+	// transactions.ApplicationCallTxnFields
+	"ApplicationID":     "application_id",
+	"OnCompletion":      "on_completion",
+	"ApplicationArgs":   "txn_msgpack",
+	"Accounts":          "txn_msgpack",
+	"ForeignApps":       "txn_msgpack",
+	"ForeignAssets":     "txn_msgpack",
+	"LocalStateSchema":  "txn_msgpack",
+	"GlobalStateSchema": "txn_msgpack",
+	"ApprovalProgram":   "txn_msgpack",
+	"ClearStateProgram": "txn_msgpack",
+	"ExtraProgramPages": "txn_msgpack",
+
+	// transactions.CompactCertTxnFields
+	"CertRound": "txn_msgpack",
+	"CertType":  "txn_msgpack",
+	"Cert":      "txn_msgpack",
+}
+
+// ApplyDataFields maps transactions.ApplyData fields to Txn fields. Inner
+// transactions have their own rows.
+var ApplyDataFields = map[string]string{
+	"ClosingAmount":      "close_amount",
+	"AssetClosingAmount": "asset_close_amount",
+	"SenderRewards":      "sender_rewards",
+	"ReceiverRewards":    "receiver_rewards",
+	"CloseRewards":       "close_rewards",
+	"EvalDelta":          "txn_msgpack",
+	"ConfigAsset":        "asset_id",
+	"ApplicationID":      "asset_id",
+}
+
+// ModifiedCreatableFields maps ledgercore.ModifiedCreatable fields to
+// Creatable fields. An empty name marks ledger bookkeeping that is not exported.
+var ModifiedCreatableFields = map[string]string{
+	"Ctype":   "type",
+	"Created": "created",
+	"Creator": "creator",
+	"Ndeltas": "",
+}
+
+// exportedFields lists the exported fields of a struct type, descending into
+// embedded structs.
+func exportedFields(t reflect.Type) []string {
+	var res []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			res = append(res, exportedFields(f.Type)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported, e.g. the msgp _struct marker.
+			continue
+		}
+		res = append(res, f.Name)
+	}
+	return res
+}
+
+// CheckDerived returns an error if a field of the Go struct type t is missing
+// from the field map, or if the field map refers to a field that the record
+// schema s does not have.
+func CheckDerived(t reflect.Type, fields map[string]string, s *avro.Schema) error {
+	var missing []string
+	for _, name := range exportedFields(t) {
+		target, ok := fields[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		if target == "" {
+			continue
+		}
+		if _, ok := s.Field(target); !ok {
+			return fmt.Errorf("CheckDerived() %s.%s maps to %s, which is not a field of %s", t.Name(), name, target, s.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("CheckDerived() fields of %s not covered by %s: %v", t.Name(), s.Name, missing)
+	}
+	return nil
+}
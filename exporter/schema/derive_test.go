@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/stretchr/testify/assert"
+)
+
+// Fails when go-algorand adds fields that the schemas do not cover yet.
+func TestDerivedFieldsCovered(t *testing.T) {
+	assert.NoError(t, CheckDerived(reflect.TypeOf(bookkeeping.BlockHeader{}), BlockHeaderFields, BlockHeader))
+	assert.NoError(t, CheckDerived(reflect.TypeOf(basics.AccountData{}), AccountDataFields, AccountDelta))
+	assert.NoError(t, CheckDerived(reflect.TypeOf(ledgercore.ModifiedCreatable{}), ModifiedCreatableFields, Creatable))
+	assert.NoError(t, CheckDerived(reflect.TypeOf(transactions.Transaction{}), TxnFields, Txn))
+	assert.NoError(t, CheckDerived(reflect.TypeOf(transactions.ApplyData{}), ApplyDataFields, Txn))
+}
+
+func TestCheckDerived(t *testing.T) {
+	type embedded struct {
+		Round uint64
+	}
+	type example struct {
+		embedded
+		Index uint64
+		Extra string
+	}
+
+	fields := map[string]string{"Round": "round", "Index": "index", "Extra": ""}
+	assert.NoError(t, CheckDerived(reflect.TypeOf(example{}), fields, Creatable))
+
+	delete(fields, "Extra")
+	assert.Error(t, CheckDerived(reflect.TypeOf(example{}), fields, Creatable))
+
+	fields["Extra"] = "nope"
+	assert.Error(t, CheckDerived(reflect.TypeOf(example{}), fields, Creatable))
+}
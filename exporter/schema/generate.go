@@ -0,0 +1,8 @@
+package schema
+
+//go:generate go run ../../cmd/texttosource/main.go schema BlockHeaderAvsc block_header.avsc block_header_avsc.go
+//go:generate go run ../../cmd/texttosource/main.go schema TxnAvsc txn.avsc txn_avsc.go
+//go:generate go run ../../cmd/texttosource/main.go schema TxnParticipationAvsc txn_participation.avsc txn_participation_avsc.go
+//go:generate go run ../../cmd/texttosource/main.go schema AccountDeltaAvsc account_delta.avsc account_delta_avsc.go
+//go:generate go run ../../cmd/texttosource/main.go schema CreatableAvsc creatable.avsc creatable_avsc.go
+//go:generate go run ../../cmd/texttosource/main.go schema HoldingAvsc holding.avsc holding_avsc.go
@@ -0,0 +1,167 @@
+// Package schema holds the Avro schemas of the records written by the exporter,
+// and checks that they evolve in a backward compatible way.
+package schema
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/algorand/indexer/avro"
+)
+
+// Version is the version of the exported schemas. It is part of the record
+// namespace and is written into the metadata of every exported file. Bump it
+// together with the namespace whenever a schema changes.
+const Version = 1
+
+// Metadata keys written into the header of every exported file.
+const (
+	// MetaVersion holds Version.
+	MetaVersion = "algorand.schema.version"
+	// MetaFingerprint holds the hex encoded SHA-256 fingerprint of the
+	// canonical form of the schema.
+	MetaFingerprint = "algorand.schema.fingerprint"
+	// MetaTable holds the name of the exported table.
+	MetaTable = "algorand.table"
+	// MetaFirstRound and MetaLastRound hold the round range covered by the file.
+	MetaFirstRound = "algorand.round.first"
+	MetaLastRound  = "algorand.round.last"
+)
+
+// Names of the exported tables. They match the Postgres tables they mirror,
+// except for the state delta tables: account_delta, creatable and holding.
+const (
+	BlockHeaderTable      = "block_header"
+	TxnTable              = "txn"
+	TxnParticipationTable = "txn_participation"
+	AccountDeltaTable     = "account_delta"
+	CreatableTable        = "creatable"
+	HoldingTable          = "holding"
+)
+
+// Parsed schemas.
+var (
+	BlockHeader      = avro.MustParseSchema(BlockHeaderAvsc)
+	Txn              = avro.MustParseSchema(TxnAvsc)
+	TxnParticipation = avro.MustParseSchema(TxnParticipationAvsc)
+	AccountDelta     = avro.MustParseSchema(AccountDeltaAvsc)
+	Creatable        = avro.MustParseSchema(CreatableAvsc)
+	Holding          = avro.MustParseSchema(HoldingAvsc)
+)
+
+var tables = map[string]*avro.Schema{
+	BlockHeaderTable:      BlockHeader,
+	TxnTable:              Txn,
+	TxnParticipationTable: TxnParticipation,
+	AccountDeltaTable:     AccountDelta,
+	CreatableTable:        Creatable,
+	HoldingTable:          Holding,
+}
+
+// ForTable returns the schema of the given table.
+func ForTable(table string) (*avro.Schema, bool) {
+	s, ok := tables[table]
+	return s, ok
+}
+
+// Tables returns the sorted names of all tables.
+func Tables() []string {
+	res := make([]string, 0, len(tables))
+	for table := range tables {
+		res = append(res, table)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Fingerprint returns the value stored under MetaFingerprint for a schema.
+func Fingerprint(s *avro.Schema) string {
+	fp := s.FingerprintSHA256()
+	return hex.EncodeToString(fp[:])
+}
+
+// LatestFile returns the path of the last published file of a table in an
+// export directory, or "" if there is none. File names sort in round order.
+func LatestFile(dir, table string) (string, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(dir, table))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("LatestFile() err: %w", err)
+	}
+
+	latest := ""
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, table+"_") || !strings.HasSuffix(name, ".avro") {
+			continue
+		}
+		if name > latest {
+			latest = name
+		}
+	}
+	if latest == "" {
+		return "", nil
+	}
+	return filepath.Join(dir, table, latest), nil
+}
+
+// ReadFileSchema returns the schema stored in the header of an exported file.
+func ReadFileSchema(path string) (*avro.Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFileSchema() err: %w", err)
+	}
+	defer f.Close()
+
+	reader, err := avro.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFileSchema() %s err: %w", path, err)
+	}
+	return reader.Schema(), nil
+}
+
+// CheckTable returns an error if the current schema of a table cannot read
+// the latest file of that table in a previous export directory.
+func CheckTable(dir, table string) error {
+	current, ok := ForTable(table)
+	if !ok {
+		return fmt.Errorf("CheckTable() unknown table %s", table)
+	}
+	path, err := LatestFile(dir, table)
+	if err != nil {
+		return fmt.Errorf("CheckTable() err: %w", err)
+	}
+	if path == "" {
+		return nil
+	}
+	previous, err := ReadFileSchema(path)
+	if err != nil {
+		return fmt.Errorf("CheckTable() err: %w", err)
+	}
+	if previous.CanonicalForm() == current.CanonicalForm() {
+		return nil
+	}
+	err = avro.CheckCompatibility(current, previous)
+	if err != nil {
+		return fmt.Errorf("CheckTable() schema of %s is not backward compatible with %s: %w", table, path, err)
+	}
+	return nil
+}
+
+// CheckDir runs CheckTable for every table.
+func CheckDir(dir string) error {
+	for _, table := range Tables() {
+		err := CheckTable(dir, table)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
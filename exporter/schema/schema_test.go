@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/avro"
+)
+
+func TestSchemasAreVersioned(t *testing.T) {
+	for _, table := range Tables() {
+		s, ok := ForTable(table)
+		require.True(t, ok)
+		assert.Equal(t, avro.Record, s.Type)
+		assert.Contains(t, s.Name, "org.algorand.indexer.v1.")
+		assert.Len(t, Fingerprint(s), 64)
+	}
+}
+
+func writeFile(t *testing.T, dir, table, name string, s *avro.Schema) {
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, table), 0755))
+	f, err := os.Create(filepath.Join(dir, table, name))
+	require.NoError(t, err)
+	defer f.Close()
+	w, err := avro.NewWriter(f, s, avro.CodecNull, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func TestLatestFile(t *testing.T) {
+	dir := t.TempDir()
+	path, err := LatestFile(dir, TxnTable)
+	require.NoError(t, err)
+	assert.Equal(t, "", path)
+
+	writeFile(t, dir, TxnTable, "txn_0000_0009.avro", Txn)
+	writeFile(t, dir, TxnTable, "txn_0010_0019.avro", Txn)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, TxnTable, "txn_0020_0029.avro.tmp"), nil, 0644))
+
+	path, err = LatestFile(dir, TxnTable)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, TxnTable, "txn_0010_0019.avro"), path)
+}
+
+// The next schema version bumps the namespace, files of the previous version
+// must stay readable.
+func TestCheckTableNewNamespace(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, HoldingTable, "holding_0000_0009.avro", Holding)
+
+	v2 := avro.MustParseSchema(strings.ReplaceAll(HoldingAvsc, "org.algorand.indexer.v1", "org.algorand.indexer.v2"))
+	require.NotEqual(t, Holding.Name, v2.Name)
+	assert.NoError(t, avro.CheckCompatibility(v2, Holding))
+
+	added := strings.Replace(HoldingAvsc, `{"name": "round", "type": "long"},`,
+		`{"name": "round", "type": "long"}, {"name": "note", "type": ["null", "string"], "default": null},`, 1)
+	v2 = avro.MustParseSchema(strings.ReplaceAll(added, "org.algorand.indexer.v1", "org.algorand.indexer.v2"))
+	_, ok := v2.Field("note")
+	require.True(t, ok)
+	previous, err := ReadFileSchema(filepath.Join(dir, HoldingTable, "holding_0000_0009.avro"))
+	require.NoError(t, err)
+	assert.NoError(t, avro.CheckCompatibility(v2, previous))
+}
+
+func TestCheckTable(t *testing.T) {
+	dir := t.TempDir()
+	// Nothing to compare with.
+	require.NoError(t, CheckDir(dir))
+
+	// Same schema.
+	writeFile(t, dir, CreatableTable, "creatable_0000_0009.avro", Creatable)
+	require.NoError(t, CheckTable(dir, CreatableTable))
+
+	// The current schema added the creator without a default, so it cannot
+	// read files written without it.
+	older := avro.MustParseSchema(`{"type": "record", "name": "Creatable", "namespace": "org.algorand.indexer.v1", "fields": [
+		{"name": "round", "type": "long"},
+		{"name": "index", "type": "long"},
+		{"name": "type", "type": {"type": "enum", "name": "CreatableType", "symbols": ["asset", "app"]}},
+		{"name": "created", "type": "boolean"}
+	]}`)
+	writeFile(t, dir, CreatableTable, "creatable_0010_0019.avro", older)
+	err := CheckTable(dir, CreatableTable)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "creator")
+	assert.Error(t, CheckDir(dir))
+
+	// An older schema with an extra field is compatible, the field is skipped.
+	extra := avro.MustParseSchema(`{"type": "record", "name": "Creatable", "namespace": "org.algorand.indexer.v1", "fields": [
+		{"name": "round", "type": "long"},
+		{"name": "index", "type": "int"},
+		{"name": "type", "type": {"type": "enum", "name": "CreatableType", "symbols": ["asset", "app"]}},
+		{"name": "created", "type": "boolean"},
+		{"name": "creator", "type": "string"},
+		{"name": "removed", "type": "string"}
+	]}`)
+	writeFile(t, dir, CreatableTable, "creatable_0020_0029.avro", extra)
+	require.NoError(t, CheckTable(dir, CreatableTable))
+}
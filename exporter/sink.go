@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand/rpcs"
+)
+
+// Sink appends blocks received from algod to rolling Avro files, without going
+// through the database. Only complete round ranges are published, so after a
+// restart the sink resumes at the first round of the range it was writing.
+type Sink struct {
+	writer *Writer
+	dir    string
+}
+
+// MakeSink creates a Sink.
+func MakeSink(opts Options) (*Sink, error) {
+	writer, err := MakeWriter(opts)
+	if err != nil {
+		return nil, fmt.Errorf("MakeSink() err: %w", err)
+	}
+	return &Sink{writer: writer, dir: opts.Dir}, nil
+}
+
+// NextRound returns the round the sink expects next, based on the published
+// files.
+func (s *Sink) NextRound() (uint64, error) {
+	return NextRound(s.dir)
+}
+
+// HandleBlock converts a block to records and appends them to the open files.
+// It has the signature expected by fetcher.Fetcher.SetBlockHandler().
+func (s *Sink) HandleBlock(ctx context.Context, block *rpcs.EncodedBlockCert) error {
+	data, err := BlockDataFromBlock(&block.Block)
+	if err != nil {
+		return fmt.Errorf("HandleBlock() round %d err: %w", block.Block.Round(), err)
+	}
+	err = s.writer.WriteBlock(&data)
+	if err != nil {
+		return fmt.Errorf("HandleBlock() err: %w", err)
+	}
+
+	// Publish the range as soon as its last round is written, instead of
+	// waiting for the first block of the next range.
+	round := uint64(block.Block.Round())
+	if _, last := s.writer.rangeOf(round); round == last {
+		err = s.writer.Close()
+		if err != nil {
+			return fmt.Errorf("HandleBlock() err: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close discards the files of the incomplete round range.
+func (s *Sink) Close() {
+	s.writer.Abort()
+}
@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/rpcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/util/test"
+)
+
+func makeBlockCerts(t *testing.T, n int) []rpcs.EncodedBlockCert {
+	var res []rpcs.EncodedBlockCert
+	prev := test.MakeGenesisBlock().BlockHeader
+	res = append(res, rpcs.EncodedBlockCert{Block: bookkeeping.Block{BlockHeader: prev}})
+	for i := 1; i < n; i++ {
+		pay := test.MakePaymentTxn(1000, uint64(i), 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+		block, err := test.MakeBlockForTxns(prev, &pay)
+		require.NoError(t, err)
+		res = append(res, rpcs.EncodedBlockCert{Block: block})
+		prev = block.BlockHeader
+	}
+	return res
+}
+
+func TestSinkPublishesCompleteRanges(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := MakeSink(Options{Dir: dir, RoundsPerFile: 4})
+	require.NoError(t, err)
+
+	next, err := sink.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), next)
+
+	blocks := makeBlockCerts(t, 6)
+	for i := range blocks {
+		require.NoError(t, sink.HandleBlock(context.Background(), &blocks[i]))
+	}
+
+	// Rounds 0-3 are published, 4-5 are pending.
+	assert.Equal(t, []int64{0, 1, 2, 3}, readRounds(t, sink.writer.Path(schema.BlockHeaderTable, 0)))
+	assert.Equal(t, []int64{1, 2, 3}, readRounds(t, sink.writer.Path(schema.TxnTable, 0)))
+	assert.NoFileExists(t, sink.writer.Path(schema.BlockHeaderTable, 4))
+	next, err = sink.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), next)
+
+	// The incomplete range is discarded, a new sink resumes at its first round.
+	sink.Close()
+	sink, err = MakeSink(Options{Dir: dir, RoundsPerFile: 4})
+	require.NoError(t, err)
+	defer sink.Close()
+	next, err = sink.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), next)
+}
+
+func TestBlockDataFromBlock(t *testing.T) {
+	blocks := makeBlockCerts(t, 2)
+	data, err := BlockDataFromBlock(&blocks[1].Block)
+	require.NoError(t, err)
+	require.Len(t, data.Txns, 1)
+	// The genesis hash is restored, so the txid matches the original transaction.
+	pay := test.MakePaymentTxn(1000, 1, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	assert.Equal(t, pay.Txn.ID(), data.Txns[0].Txn.Txn.ID())
+}
@@ -0,0 +1,373 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+)
+
+// DefaultRoundsPerFile is the default size of the round range stored in one file.
+const DefaultRoundsPerFile = 1000
+
+// Options configure where and how records are written.
+type Options struct {
+	// Dir is the output directory. Each table is written to its own
+	// subdirectory.
+	Dir string
+
+	// RoundsPerFile is the size of the round range stored in one file. Ranges
+	// are aligned to multiples of RoundsPerFile and clipped to FirstRound and
+	// LastRound, so that the file names and metadata match the rounds written.
+	RoundsPerFile uint64
+
+	// FirstRound is the first round that will be written.
+	FirstRound uint64
+
+	// LastRound is the last round that will be written, nil if there is no
+	// upper bound, e.g. when following algod.
+	LastRound *uint64
+
+	// Codec is the Avro block compression codec.
+	Codec avro.Codec
+
+	// PreviousDir is the directory of a previous export. Writing a table
+	// fails if its schema cannot read the files of that export. Defaults to
+	// Dir.
+	PreviousDir string
+}
+
+// FileName returns the name of the file holding the rounds [first, last] of a
+// table. Zero padding keeps the lexicographic order equal to the round order.
+func FileName(table string, first, last uint64) string {
+	return fileName(table, first, last, ".avro")
+}
+
+func fileName(table string, first, last uint64, ext string) string {
+	return fmt.Sprintf("%s_%020d_%020d%s", table, first, last, ext)
+}
+
+// parseFileName is the inverse of fileName.
+func parseFileName(table, name, ext string) (first, last uint64, ok bool) {
+	rest := strings.TrimPrefix(name, table+"_")
+	if rest == name || !strings.HasSuffix(rest, ext) {
+		return 0, 0, false
+	}
+	parts := strings.Split(strings.TrimSuffix(rest, ext), "_")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	first, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	last, err = strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return first, last, true
+}
+
+// NextRound returns the round following the last complete round range found in
+// the output directory, or 0 if there is none.
+func NextRound(dir string) (uint64, error) {
+	next, err := nextRound(dir, schema.BlockHeaderTable, ".avro")
+	if err != nil {
+		return 0, fmt.Errorf("NextRound() err: %w", err)
+	}
+	return next, nil
+}
+
+// nextRound returns the round following the last file of a table, or 0 if
+// there is none.
+func nextRound(dir, table, ext string) (uint64, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(dir, table))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	next := uint64(0)
+	for _, entry := range entries {
+		_, last, ok := parseFileName(table, entry.Name(), ext)
+		if ok && last+1 > next {
+			next = last + 1
+		}
+	}
+	return next, nil
+}
+
+// recordWriter encodes records into a file, e.g. an avro.Writer.
+type recordWriter interface {
+	Append(record interface{}) error
+	Flush() error
+	Close() error
+}
+
+// rangeFile is an open file for one round range of one table. It is written
+// under a temporary name and renamed to path when closed.
+type rangeFile struct {
+	path   string
+	tmp    string
+	file   *os.File
+	buf    *bufio.Writer
+	writer recordWriter
+}
+
+func avroWriter(s *avro.Schema, codec avro.Codec, meta map[string][]byte) func(io.Writer) (recordWriter, error) {
+	return func(w io.Writer) (recordWriter, error) {
+		return avro.NewWriter(w, s, codec, meta)
+	}
+}
+
+// openRangeFile creates the temporary file `tmp`, so that readers never see a
+// partial file at `path`.
+func openRangeFile(path, tmp string, makeWriter func(io.Writer) (recordWriter, error)) (*rangeFile, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("openRangeFile() err: %w", err)
+	}
+	file, err := os.Create(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("openRangeFile() err: %w", err)
+	}
+	buf := bufio.NewWriter(file)
+	writer, err := makeWriter(buf)
+	if err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return nil, fmt.Errorf("openRangeFile() err: %w", err)
+	}
+	return &rangeFile{path: path, tmp: tmp, file: file, buf: buf, writer: writer}, nil
+}
+
+// sync writes the records appended so far to disk.
+func (f *rangeFile) sync() error {
+	err := f.writer.Flush()
+	if err == nil {
+		err = f.buf.Flush()
+	}
+	if err == nil {
+		err = f.file.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("sync() %s err: %w", f.tmp, err)
+	}
+	return nil
+}
+
+// rename moves the temporary file to a new temporary name.
+func (f *rangeFile) rename(tmp string) error {
+	if tmp == f.tmp {
+		return nil
+	}
+	err := os.Rename(f.tmp, tmp)
+	if err != nil {
+		return fmt.Errorf("rename() err: %w", err)
+	}
+	f.tmp = tmp
+	return nil
+}
+
+func (f *rangeFile) close() error {
+	err := f.writer.Close()
+	if err == nil {
+		err = f.buf.Flush()
+	}
+	if err == nil {
+		err = f.file.Sync()
+	}
+	closeErr := f.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("close() %s err: %w", f.path, err)
+	}
+	err = os.Rename(f.tmp, f.path)
+	if err != nil {
+		return fmt.Errorf("close() err: %w", err)
+	}
+	return nil
+}
+
+func (f *rangeFile) abort() {
+	f.file.Close()
+	os.Remove(f.tmp)
+}
+
+// Writer writes blocks as Avro container files split by round range. Blocks
+// must be written in increasing round order.
+type Writer struct {
+	opts Options
+
+	// rangeStart is the first round of the currently open files.
+	rangeStart uint64
+	files      map[string]*rangeFile
+
+	// checked holds the tables whose schema was checked against PreviousDir.
+	checked map[string]bool
+
+	// lastRound is the last round written, valid if written is set.
+	lastRound uint64
+	written   bool
+}
+
+// MakeWriter creates a Writer.
+func MakeWriter(opts Options) (*Writer, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("MakeWriter() output directory not set")
+	}
+	if opts.RoundsPerFile == 0 {
+		opts.RoundsPerFile = DefaultRoundsPerFile
+	}
+	if opts.Codec == "" {
+		opts.Codec = avro.CodecDeflate
+	}
+	if opts.PreviousDir == "" {
+		opts.PreviousDir = opts.Dir
+	}
+	return &Writer{opts: opts, checked: make(map[string]bool)}, nil
+}
+
+// rangeOf returns the rounds of the file holding `round`.
+func (w *Writer) rangeOf(round uint64) (uint64, uint64) {
+	first := round - round%w.opts.RoundsPerFile
+	last := first + w.opts.RoundsPerFile - 1
+	if first < w.opts.FirstRound {
+		first = w.opts.FirstRound
+	}
+	if w.opts.LastRound != nil && last > *w.opts.LastRound {
+		last = *w.opts.LastRound
+	}
+	return first, last
+}
+
+// Path returns the path of the file that holds the given round of a table.
+func (w *Writer) Path(table string, round uint64) string {
+	first, last := w.rangeOf(round)
+	return filepath.Join(w.opts.Dir, table, FileName(table, first, last))
+}
+
+func (w *Writer) file(table string, round uint64) (*rangeFile, error) {
+	if f, ok := w.files[table]; ok {
+		return f, nil
+	}
+	s, ok := schema.ForTable(table)
+	if !ok {
+		return nil, fmt.Errorf("file() unknown table %s", table)
+	}
+	if !w.checked[table] {
+		// Must happen before the first file of the table is created, which
+		// could replace the previous latest file.
+		err := schema.CheckTable(w.opts.PreviousDir, table)
+		if err != nil {
+			return nil, fmt.Errorf("file() err: %w", err)
+		}
+		w.checked[table] = true
+	}
+	first, last := w.rangeOf(round)
+	meta := map[string][]byte{
+		schema.MetaVersion:     []byte(strconv.Itoa(schema.Version)),
+		schema.MetaFingerprint: []byte(schema.Fingerprint(s)),
+		schema.MetaTable:       []byte(table),
+		schema.MetaFirstRound:  []byte(strconv.FormatUint(first, 10)),
+		schema.MetaLastRound:   []byte(strconv.FormatUint(last, 10)),
+	}
+	path := w.Path(table, round)
+	f, err := openRangeFile(path, path+".tmp", avroWriter(s, w.opts.Codec, meta))
+	if err != nil {
+		return nil, err
+	}
+	w.files[table] = f
+	return f, nil
+}
+
+func (w *Writer) appendRecords(table string, round uint64, records []map[string]interface{}) error {
+	f, err := w.file(table, round)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		err = f.writer.Append(rec)
+		if err != nil {
+			return fmt.Errorf("appendRecords() %s round %d err: %w", table, round, err)
+		}
+	}
+	return nil
+}
+
+// WriteBlock appends the records of a block to the files of its round range,
+// closing the files of the previous range first if needed.
+func (w *Writer) WriteBlock(data *BlockData) error {
+	round := uint64(data.Header.Round)
+	if w.written && round <= w.lastRound {
+		return fmt.Errorf("WriteBlock() round %d written after round %d", round, w.lastRound)
+	}
+	if round < w.opts.FirstRound || (w.opts.LastRound != nil && round > *w.opts.LastRound) {
+		return fmt.Errorf("WriteBlock() round %d outside of the exported rounds", round)
+	}
+	first, _ := w.rangeOf(round)
+	if w.files != nil && first != w.rangeStart {
+		err := w.Close()
+		if err != nil {
+			return fmt.Errorf("WriteBlock() err: %w", err)
+		}
+	}
+	if w.files == nil {
+		w.files = make(map[string]*rangeFile)
+		w.rangeStart = first
+	}
+
+	txns, err := TxnRecords(data)
+	if err != nil {
+		return fmt.Errorf("WriteBlock() err: %w", err)
+	}
+
+	err = w.appendRecords(schema.BlockHeaderTable, round, []map[string]interface{}{HeaderRecord(&data.Header)})
+	if err != nil {
+		return fmt.Errorf("WriteBlock() err: %w", err)
+	}
+	err = w.appendRecords(schema.TxnTable, round, txns)
+	if err != nil {
+		return fmt.Errorf("WriteBlock() err: %w", err)
+	}
+	err = w.appendRecords(schema.TxnParticipationTable, round, TxnParticipationRecords(data))
+	if err != nil {
+		return fmt.Errorf("WriteBlock() err: %w", err)
+	}
+
+	w.lastRound = round
+	w.written = true
+	return nil
+}
+
+// Close finishes and renames the open files.
+func (w *Writer) Close() error {
+	var firstErr error
+	for _, f := range w.files {
+		err := f.close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	w.files = nil
+	return firstErr
+}
+
+// Abort removes the open files without publishing them.
+func (w *Writer) Abort() {
+	for _, f := range w.files {
+		f.abort()
+	}
+	w.files = nil
+}
@@ -0,0 +1,156 @@
+package exporter
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/avro"
+	"github.com/algorand/indexer/exporter/schema"
+	"github.com/algorand/indexer/util/test"
+)
+
+func readRounds(t *testing.T, path string) []int64 {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := avro.NewReader(f)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), r.Metadata()[schema.MetaVersion])
+
+	var rounds []int64
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return rounds
+		}
+		require.NoError(t, err)
+		rounds = append(rounds, rec.(map[string]interface{})["round"].(int64))
+	}
+}
+
+func TestWriterRoundRanges(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeWriter(Options{Dir: dir, RoundsPerFile: 10})
+	require.NoError(t, err)
+
+	pay := test.MakePaymentTxn(1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	for round := uint64(5); round <= 12; round++ {
+		data := makeBlockData(t, round, pay)
+		require.NoError(t, w.WriteBlock(&data))
+	}
+
+	// Files are only published once closed.
+	assert.NoFileExists(t, w.Path(schema.BlockHeaderTable, 12))
+	require.NoError(t, w.Close())
+
+	headers := filepath.Join(dir, schema.BlockHeaderTable)
+	assert.Equal(t, []int64{5, 6, 7, 8, 9},
+		readRounds(t, filepath.Join(headers, FileName(schema.BlockHeaderTable, 0, 9))))
+	assert.Equal(t, []int64{10, 11, 12},
+		readRounds(t, filepath.Join(headers, FileName(schema.BlockHeaderTable, 10, 19))))
+	assert.Equal(t, []int64{10, 11, 12},
+		readRounds(t, w.Path(schema.TxnTable, 10)))
+	assert.Equal(t, []int64{10, 10, 11, 11, 12, 12},
+		readRounds(t, w.Path(schema.TxnParticipationTable, 10)))
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*", "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestWriterClipsRanges(t *testing.T) {
+	dir := t.TempDir()
+	last := uint64(23)
+	w, err := MakeWriter(Options{Dir: dir, RoundsPerFile: 10, FirstRound: 5, LastRound: &last})
+	require.NoError(t, err)
+
+	for round := uint64(5); round <= last; round++ {
+		data := makeBlockData(t, round)
+		require.NoError(t, w.WriteBlock(&data))
+	}
+	data := makeBlockData(t, last+1)
+	assert.Error(t, w.WriteBlock(&data))
+	require.NoError(t, w.Close())
+
+	headers := filepath.Join(dir, schema.BlockHeaderTable)
+	for _, r := range [][2]uint64{{5, 9}, {10, 19}, {20, 23}} {
+		path := filepath.Join(headers, FileName(schema.BlockHeaderTable, r[0], r[1]))
+		require.FileExists(t, path)
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		reader, err := avro.NewReader(f)
+		require.NoError(t, err)
+		meta := reader.Metadata()
+		assert.Equal(t, strconv.FormatUint(r[0], 10), string(meta[schema.MetaFirstRound]))
+		assert.Equal(t, strconv.FormatUint(r[1], 10), string(meta[schema.MetaLastRound]))
+		f.Close()
+	}
+
+	// A partial range does not hide the rounds that were not exported.
+	next, err := NextRound(dir)
+	require.NoError(t, err)
+	assert.Equal(t, last+1, next)
+
+	w, err = MakeWriter(Options{Dir: dir, RoundsPerFile: 10, FirstRound: 5})
+	require.NoError(t, err)
+	defer w.Abort()
+	data = makeBlockData(t, 4)
+	assert.Error(t, w.WriteBlock(&data))
+}
+
+func TestWriterRejectsOutOfOrderRounds(t *testing.T) {
+	w, err := MakeWriter(Options{Dir: t.TempDir()})
+	require.NoError(t, err)
+	defer w.Abort()
+
+	data := makeBlockData(t, 5)
+	require.NoError(t, w.WriteBlock(&data))
+	assert.Error(t, w.WriteBlock(&data))
+}
+
+func TestWriterAbort(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MakeWriter(Options{Dir: dir})
+	require.NoError(t, err)
+
+	data := makeBlockData(t, 5)
+	require.NoError(t, w.WriteBlock(&data))
+	w.Abort()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*", "*"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestNextRound(t *testing.T) {
+	dir := t.TempDir()
+	next, err := NextRound(dir)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), next)
+
+	headers := filepath.Join(dir, schema.BlockHeaderTable)
+	require.NoError(t, os.MkdirAll(headers, 0755))
+	for _, name := range []string{
+		FileName(schema.BlockHeaderTable, 0, 99),
+		FileName(schema.BlockHeaderTable, 100, 199),
+		// Ignored: unpublished and unrelated files.
+		FileName(schema.BlockHeaderTable, 200, 299) + ".tmp",
+		"README",
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(headers, name), nil, 0644))
+	}
+
+	next, err = NextRound(dir)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(200), next)
+}
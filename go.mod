@@ -16,6 +16,7 @@ require (
 	github.com/jackc/pgx/v4 v4.13.0
 	github.com/labstack/echo-contrib v0.11.0
 	github.com/labstack/echo/v4 v4.3.0
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/orlangure/gnomock v0.12.0
 	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/client_golang v1.10.0
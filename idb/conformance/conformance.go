@@ -0,0 +1,941 @@
+// Package conformance is a test suite for idb.IndexerDb implementations. It
+// loads genesis and a set of blocks into a backend built by an
+// idb.IndexerDbFactory and checks the results of every interface method.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/internal/convert"
+	"github.com/algorand/indexer/util/test"
+)
+
+// Setup returns the argument of IndexerDbFactory.Build() for a new empty
+// database and a function that removes the database.
+type Setup func(t *testing.T) (arg string, shutdownFunc func())
+
+type suite struct {
+	factory idb.IndexerDbFactory
+	setup   Setup
+}
+
+func (s suite) open(t *testing.T, opts idb.IndexerDbOptions) (idb.IndexerDb, func()) {
+	arg, shutdownFunc := s.setup(t)
+	db, availableCh, err := s.factory.Build(arg, opts, nil)
+	if err != nil {
+		shutdownFunc()
+	}
+	require.NoError(t, err)
+	if availableCh != nil {
+		<-availableCh
+	}
+
+	return db, func() {
+		db.Close()
+		shutdownFunc()
+	}
+}
+
+func (s suite) setupIdb(t *testing.T) (idb.IndexerDb, func()) {
+	db, shutdownFunc := s.open(t, idb.IndexerDbOptions{})
+
+	err := db.LoadGenesis(test.MakeGenesis())
+	require.NoError(t, err)
+
+	genesisBlock := test.MakeGenesisBlock()
+	err = db.AddBlock(&genesisBlock)
+	require.NoError(t, err)
+
+	return db, shutdownFunc
+}
+
+// addBlock adds a block with `txns` on top of `prev` and returns its header.
+func addBlock(t *testing.T, db idb.IndexerDb, prev bookkeeping.BlockHeader, txns ...*transactions.SignedTxnWithAD) bookkeeping.BlockHeader {
+	block, err := test.MakeBlockForTxns(prev, txns...)
+	require.NoError(t, err)
+	err = db.AddBlock(&block)
+	require.NoError(t, err)
+	return block.BlockHeader
+}
+
+func txnRows(t *testing.T, db idb.IndexerDb, tf idb.TransactionFilter) []idb.TxnRow {
+	rowsCh, _ := db.Transactions(context.Background(), tf)
+	var rows []idb.TxnRow
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func accounts(t *testing.T, db idb.IndexerDb, opts idb.AccountQueryOptions) []models.Account {
+	rowsCh, _ := db.GetAccounts(context.Background(), opts)
+	var res []models.Account
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		res = append(res, row.Account)
+	}
+	return res
+}
+
+func assets(t *testing.T, db idb.IndexerDb, q idb.AssetsQuery) []idb.AssetRow {
+	rowsCh, _ := db.Assets(context.Background(), q)
+	var res []idb.AssetRow
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		res = append(res, row)
+	}
+	return res
+}
+
+func assetBalances(t *testing.T, db idb.IndexerDb, q idb.AssetBalanceQuery) []idb.AssetBalanceRow {
+	rowsCh, _ := db.AssetBalances(context.Background(), q)
+	var res []idb.AssetBalanceRow
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		res = append(res, row)
+	}
+	return res
+}
+
+func applications(t *testing.T, db idb.IndexerDb, params models.SearchForApplicationsParams) []models.Application {
+	rowsCh, _ := db.Applications(context.Background(), &params)
+	var res []models.Application
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		res = append(res, row.Application)
+	}
+	return res
+}
+
+func testGenesisAccounts(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	round, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), round)
+
+	accountsCh, maxRound := db.GetAccounts(context.Background(), idb.AccountQueryOptions{})
+	assert.Equal(t, uint64(0), maxRound)
+
+	num := 0
+	for row := range accountsCh {
+		require.NoError(t, row.Error)
+		num++
+	}
+	assert.Equal(t, len(test.MakeGenesis().Allocation), num)
+}
+
+func testTransactionFilters(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	const assetid = uint64(1)
+	createAsset := test.MakeAssetConfigTxn(0, 100, 0, false, "UNIT", "Test Asset", "", test.AccountA)
+	optIn := test.MakeAssetOptInTxn(assetid, test.AccountB)
+	transfer := test.MakeAssetTransferTxn(assetid, 25, test.AccountA, test.AccountB, basics.Address{})
+	pay := test.MakePaymentTxn(
+		1000, 1000000, 0, 0, 0, 0, test.AccountC, test.AccountD, basics.Address{},
+		basics.Address{})
+	// The transfer and the payment are a group, the payment has a lease.
+	pay.Txn.Lease[0] = 1
+	group := crypto.HashObj(transactions.TxGroup{
+		TxGroupHashes: []crypto.Digest{crypto.HashObj(transfer.Txn), crypto.HashObj(pay.Txn)},
+	})
+	transfer.Txn.Group = group
+	pay.Txn.Group = group
+	addBlock(
+		t, db, test.MakeGenesisBlock().BlockHeader, &createAsset, &optIn, &transfer, &pay)
+
+	// Transactions of an address are returned newest first.
+	rows := txnRows(t, db, idb.TransactionFilter{Address: test.AccountB[:]})
+	require.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[0].Intra)
+	assert.Equal(t, 1, rows[1].Intra)
+
+	rows = txnRows(
+		t, db,
+		idb.TransactionFilter{Address: test.AccountB[:], AddressRole: idb.AddressRoleSender})
+	require.Len(t, rows, 1)
+	assert.Equal(t, optIn.Txn, rows[0].Txn.Txn)
+
+	rows = txnRows(t, db, idb.TransactionFilter{AssetID: assetid, AssetAmountGT: convert.Uint64Ptr(10)})
+	require.Len(t, rows, 1)
+	assert.Equal(t, transfer.Txn, rows[0].Txn.Txn)
+
+	rows = txnRows(t, db, idb.TransactionFilter{AlgosGT: convert.Uint64Ptr(0)})
+	require.Len(t, rows, 1)
+	assert.Equal(t, pay.Txn, rows[0].Txn.Txn)
+
+	rows = txnRows(t, db, idb.TransactionFilter{TypeEnum: idb.TypeEnumAssetTransfer})
+	assert.Len(t, rows, 2)
+
+	rows = txnRows(t, db, idb.TransactionFilter{Txid: pay.Txn.ID().String()})
+	require.Len(t, rows, 1)
+	assert.Equal(t, 3, rows[0].Intra)
+
+	rows = txnRows(t, db, idb.TransactionFilter{GroupID: group[:]})
+	require.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[0].Intra)
+	assert.Equal(t, 3, rows[1].Intra)
+
+	rows = txnRows(t, db, idb.TransactionFilter{Address: test.AccountD[:], GroupID: group[:]})
+	require.Len(t, rows, 1)
+	assert.Equal(t, pay.Txn, rows[0].Txn.Txn)
+
+	rows = txnRows(t, db, idb.TransactionFilter{Lease: pay.Txn.Lease[:]})
+	require.Len(t, rows, 1)
+	assert.Equal(t, 3, rows[0].Intra)
+
+	rows = txnRows(t, db, idb.TransactionFilter{Lease: group[:]})
+	assert.Empty(t, rows)
+}
+
+func testTransactionPaging(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	header := test.MakeGenesisBlock().BlockHeader
+	for i := 0; i < 3; i++ {
+		pay1 := test.MakePaymentTxn(
+			1000, uint64(i+1), 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{},
+			basics.Address{})
+		pay2 := test.MakePaymentTxn(
+			1000, uint64(i+10), 0, 0, 0, 0, test.AccountC, test.AccountD, basics.Address{},
+			basics.Address{})
+		header = addBlock(t, db, header, &pay1, &pay2)
+	}
+
+	// Paging forward through all transactions, 2 at a time.
+	var all []idb.TxnRow
+	tf := idb.TransactionFilter{Limit: 2}
+	for {
+		rows := txnRows(t, db, tf)
+		all = append(all, rows...)
+		if len(rows) < 2 {
+			break
+		}
+		next, err := rows[len(rows)-1].Next(true)
+		require.NoError(t, err)
+		tf.NextToken = next
+	}
+	require.Len(t, all, 6)
+	for i, row := range all {
+		assert.Equal(t, uint64(i/2+1), row.Round)
+		assert.Equal(t, i%2, row.Intra)
+	}
+
+	// Paging backward through the transactions of an address, 1 at a time.
+	var rounds []uint64
+	tf = idb.TransactionFilter{Address: test.AccountB[:], Limit: 1}
+	for {
+		rows := txnRows(t, db, tf)
+		if len(rows) == 0 {
+			break
+		}
+		require.Len(t, rows, 1)
+		rounds = append(rounds, rows[0].Round)
+		next, err := rows[0].Next(false)
+		require.NoError(t, err)
+		tf.NextToken = next
+	}
+	assert.Equal(t, []uint64{3, 2, 1}, rounds)
+}
+
+func testGetAccounts(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	const assetid = uint64(1)
+	const appid = uint64(2)
+	createAsset := test.MakeAssetConfigTxn(0, 100, 0, false, "UNIT", "Test Asset", "", test.AccountA)
+	createApp := test.MakeCreateAppTxn(test.AccountA)
+	optIn := test.MakeAssetOptInTxn(assetid, test.AccountB)
+	transfer := test.MakeAssetTransferTxn(assetid, 25, test.AccountA, test.AccountB, basics.Address{})
+	appOptIn := test.MakeAppOptInTxn(appid, test.AccountB)
+	rekey := test.MakePaymentTxn(
+		1000, 0, 0, 0, 0, 0, test.AccountA, test.AccountA, basics.Address{}, test.AccountE)
+	header := addBlock(
+		t, db, test.MakeGenesisBlock().BlockHeader,
+		&createAsset, &createApp, &optIn, &transfer, &appOptIn, &rekey)
+
+	res := accounts(t, db, idb.AccountQueryOptions{
+		EqualToAddress:       test.AccountA[:],
+		IncludeAssetHoldings: true,
+		IncludeAssetParams:   true,
+	})
+	require.Len(t, res, 1)
+	account := res[0]
+	assert.Equal(t, test.AccountA.String(), account.Address)
+	assert.Equal(t, uint64(1), account.Round)
+	assert.Equal(t, "Offline", account.Status)
+	require.NotNil(t, account.AuthAddr)
+	assert.Equal(t, test.AccountE.String(), *account.AuthAddr)
+	require.NotNil(t, account.Assets)
+	require.Len(t, *account.Assets, 1)
+	assert.Equal(t, uint64(75), (*account.Assets)[0].Amount)
+	require.NotNil(t, account.CreatedAssets)
+	require.Len(t, *account.CreatedAssets, 1)
+	assert.Equal(t, "Test Asset", *(*account.CreatedAssets)[0].Params.Name)
+	require.NotNil(t, account.CreatedApps)
+	require.Len(t, *account.CreatedApps, 1)
+	assert.Equal(t, appid, (*account.CreatedApps)[0].Id)
+	assert.Equal(t, createApp.Txn.ApprovalProgram, (*account.CreatedApps)[0].Params.ApprovalProgram)
+
+	// Holdings and created assets are only returned when requested.
+	res = accounts(t, db, idb.AccountQueryOptions{EqualToAddress: test.AccountA[:]})
+	require.Len(t, res, 1)
+	assert.Nil(t, res[0].Assets)
+	assert.Nil(t, res[0].CreatedAssets)
+
+	res = accounts(t, db, idb.AccountQueryOptions{EqualToAuthAddr: test.AccountE[:]})
+	require.Len(t, res, 1)
+	assert.Equal(t, test.AccountA.String(), res[0].Address)
+
+	// Several addresses are returned in address order, once. Unknown addresses
+	// are left out.
+	unknown := basics.Address{1, 2, 3}
+	res = accounts(t, db, idb.AccountQueryOptions{
+		EqualToAddresses:     [][]byte{test.AccountB[:], unknown[:], test.AccountA[:], test.AccountB[:]},
+		IncludeAssetHoldings: true,
+	})
+	require.Len(t, res, 2)
+	expected := []string{test.AccountA.String(), test.AccountB.String()}
+	if bytes.Compare(test.AccountB[:], test.AccountA[:]) < 0 {
+		expected = []string{test.AccountB.String(), test.AccountA.String()}
+	}
+	assert.Equal(t, expected, []string{res[0].Address, res[1].Address})
+	require.NotNil(t, res[0].Assets)
+
+	res = accounts(t, db, idb.AccountQueryOptions{HasAppID: appid})
+	require.Len(t, res, 1)
+	assert.Equal(t, test.AccountB.String(), res[0].Address)
+	require.NotNil(t, res[0].AppsLocalState)
+	assert.Equal(t, appid, (*res[0].AppsLocalState)[0].Id)
+
+	// Created apps and local states are left out when excluded.
+	res = accounts(t, db, idb.AccountQueryOptions{
+		EqualToAddresses: [][]byte{test.AccountA[:], test.AccountB[:]},
+		ExcludeApps:      true,
+	})
+	require.Len(t, res, 2)
+	for _, account := range res {
+		assert.Nil(t, account.CreatedApps)
+		assert.Nil(t, account.AppsLocalState)
+	}
+
+	res = accounts(t, db, idb.AccountQueryOptions{HasAssetID: assetid, AssetLT: convert.Uint64Ptr(50)})
+	require.Len(t, res, 1)
+	assert.Equal(t, test.AccountB.String(), res[0].Address)
+
+	// Asset amount filters require an asset id.
+	rowsCh, _ := db.GetAccounts(
+		context.Background(), idb.AccountQueryOptions{AssetGT: convert.Uint64Ptr(50)})
+	row, ok := <-rowsCh
+	require.True(t, ok)
+	assert.Error(t, row.Error)
+
+	res = accounts(t, db, idb.AccountQueryOptions{AlgosGreaterThan: convert.Uint64Ptr(1000 * 1000 * 1000 * 1000)})
+	assert.Empty(t, res)
+
+	// Paging by address.
+	all := accounts(t, db, idb.AccountQueryOptions{})
+	page := accounts(t, db, idb.AccountQueryOptions{Limit: 2})
+	require.Len(t, page, 2)
+	lastAddr, err := basics.UnmarshalChecksumAddress(page[1].Address)
+	require.NoError(t, err)
+	page = append(page, accounts(t, db, idb.AccountQueryOptions{GreaterThanAddress: lastAddr[:]})...)
+	assert.Equal(t, all, page)
+
+	// Deleted apps are only returned with IncludeDeleted and without params.
+	destroyApp := test.MakeAppDestroyTxn(appid, test.AccountA)
+	addBlock(t, db, header, &destroyApp)
+
+	res = accounts(t, db, idb.AccountQueryOptions{EqualToAddress: test.AccountA[:]})
+	require.Len(t, res, 1)
+	assert.Nil(t, res[0].CreatedApps)
+
+	res = accounts(
+		t, db, idb.AccountQueryOptions{EqualToAddress: test.AccountA[:], IncludeDeleted: true})
+	require.Len(t, res, 1)
+	require.NotNil(t, res[0].CreatedApps)
+	app := (*res[0].CreatedApps)[0]
+	require.NotNil(t, app.Deleted)
+	assert.True(t, *app.Deleted)
+	require.NotNil(t, app.DeletedAtRound)
+	assert.Equal(t, uint64(2), *app.DeletedAtRound)
+	assert.Nil(t, app.Params.ApprovalProgram)
+}
+
+func testAssetsAndBalances(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	createAsset1 := test.MakeAssetConfigTxn(0, 100, 0, false, "UNIT", "Test Asset", "", test.AccountA)
+	createAsset2 := test.MakeAssetConfigTxn(0, 10, 0, false, "OTH", "Other", "", test.AccountB)
+	optInB := test.MakeAssetOptInTxn(1, test.AccountB)
+	optInC := test.MakeAssetOptInTxn(1, test.AccountC)
+	transferB := test.MakeAssetTransferTxn(1, 25, test.AccountA, test.AccountB, basics.Address{})
+	transferC := test.MakeAssetTransferTxn(1, 5, test.AccountA, test.AccountC, basics.Address{})
+	header := addBlock(
+		t, db, test.MakeGenesisBlock().BlockHeader,
+		&createAsset1, &createAsset2, &optInB, &optInC, &transferB, &transferC)
+
+	res := assets(t, db, idb.AssetsQuery{Name: "test"})
+	require.Len(t, res, 1)
+	assert.Equal(t, uint64(1), res[0].AssetID)
+	assert.Equal(t, test.AccountA[:], res[0].Creator)
+	assert.Equal(t, createAsset1.Txn.AssetParams, res[0].Params)
+
+	res = assets(t, db, idb.AssetsQuery{Unit: "oth"})
+	require.Len(t, res, 1)
+	assert.Equal(t, uint64(2), res[0].AssetID)
+
+	res = assets(t, db, idb.AssetsQuery{Query: "unit"})
+	require.Len(t, res, 1)
+	assert.Equal(t, uint64(1), res[0].AssetID)
+
+	res = assets(t, db, idb.AssetsQuery{Creator: test.AccountB[:]})
+	require.Len(t, res, 1)
+	assert.Equal(t, uint64(2), res[0].AssetID)
+
+	res = assets(t, db, idb.AssetsQuery{AssetIDGreaterThan: 1})
+	require.Len(t, res, 1)
+	assert.Equal(t, uint64(2), res[0].AssetID)
+
+	res = assets(t, db, idb.AssetsQuery{Limit: 1})
+	require.Len(t, res, 1)
+	assert.Equal(t, uint64(1), res[0].AssetID)
+
+	balances := assetBalances(t, db, idb.AssetBalanceQuery{AssetID: 1})
+	require.Len(t, balances, 3)
+	amounts := make(map[basics.Address]uint64)
+	for _, balance := range balances {
+		var addr basics.Address
+		copy(addr[:], balance.Address)
+		amounts[addr] = balance.Amount
+	}
+	assert.Equal(
+		t,
+		map[basics.Address]uint64{test.AccountA: 70, test.AccountB: 25, test.AccountC: 5},
+		amounts)
+
+	balances = assetBalances(
+		t, db, idb.AssetBalanceQuery{AssetID: 1, AmountGT: convert.Uint64Ptr(5), AmountLT: convert.Uint64Ptr(70)})
+	require.Len(t, balances, 1)
+	assert.Equal(t, test.AccountB[:], balances[0].Address)
+
+	// Paging by address.
+	var paged []idb.AssetBalanceRow
+	q := idb.AssetBalanceQuery{AssetID: 1, Limit: 1}
+	for {
+		page := assetBalances(t, db, q)
+		if len(page) == 0 {
+			break
+		}
+		paged = append(paged, page...)
+		q.PrevAddress = page[0].Address
+	}
+	assert.Len(t, paged, 3)
+
+	// Destroyed assets are only returned with IncludeDeleted.
+	destroyAsset := test.MakeAssetDestroyTxn(2, test.AccountB)
+	addBlock(t, db, header, &destroyAsset)
+
+	res = assets(t, db, idb.AssetsQuery{})
+	require.Len(t, res, 1)
+	assert.Equal(t, uint64(1), res[0].AssetID)
+
+	res = assets(t, db, idb.AssetsQuery{IncludeDeleted: true})
+	require.Len(t, res, 2)
+	require.NotNil(t, res[1].Deleted)
+	assert.True(t, *res[1].Deleted)
+	require.NotNil(t, res[1].ClosedRound)
+	assert.Equal(t, uint64(2), *res[1].ClosedRound)
+	assert.Equal(t, basics.AssetParams{}, res[1].Params)
+}
+
+func testApplications(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	createApp1 := test.MakeCreateAppTxn(test.AccountA)
+	createApp2 := test.MakeCreateAppTxn(test.AccountB)
+	createApp3 := test.MakeCreateAppTxn(test.AccountC)
+	header := addBlock(
+		t, db, test.MakeGenesisBlock().BlockHeader, &createApp1, &createApp2, &createApp3)
+	destroyApp := test.MakeAppDestroyTxn(2, test.AccountB)
+	addBlock(t, db, header, &destroyApp)
+
+	ids := func(apps []models.Application) []uint64 {
+		var res []uint64
+		for _, app := range apps {
+			res = append(res, app.Id)
+		}
+		return res
+	}
+
+	apps := applications(t, db, models.SearchForApplicationsParams{})
+	assert.Equal(t, []uint64{1, 3}, ids(apps))
+	require.NotNil(t, apps[0].Params.Creator)
+	assert.Equal(t, test.AccountA.String(), *apps[0].Params.Creator)
+	assert.Equal(t, createApp1.Txn.ApprovalProgram, apps[0].Params.ApprovalProgram)
+	require.NotNil(t, apps[0].CreatedAtRound)
+	assert.Equal(t, uint64(1), *apps[0].CreatedAtRound)
+
+	includeAll := true
+	apps = applications(t, db, models.SearchForApplicationsParams{IncludeAll: &includeAll})
+	assert.Equal(t, []uint64{1, 2, 3}, ids(apps))
+	require.NotNil(t, apps[1].Deleted)
+	assert.True(t, *apps[1].Deleted)
+
+	appid := uint64(3)
+	apps = applications(t, db, models.SearchForApplicationsParams{ApplicationId: &appid})
+	assert.Equal(t, []uint64{3}, ids(apps))
+
+	next := "1"
+	limit := uint64(1)
+	apps = applications(
+		t, db,
+		models.SearchForApplicationsParams{Next: &next, Limit: &limit, IncludeAll: &includeAll})
+	assert.Equal(t, []uint64{2}, ids(apps))
+
+	rowsCh, _ := db.Applications(context.Background(), nil)
+	row, ok := <-rowsCh
+	require.True(t, ok)
+	assert.Error(t, row.Error)
+}
+
+func testInnerTransactions(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	appCall := test.MakeAppCallWithInnerTxn(
+		test.AccountA, test.AccountB, test.AccountC, test.AccountD, test.AccountE)
+	addBlock(t, db, test.MakeGenesisBlock().BlockHeader, &appCall)
+
+	// The root and 3 inner transactions.
+	rows := txnRows(t, db, idb.TransactionFilter{})
+	require.Len(t, rows, 4)
+	require.NotNil(t, rows[0].Txn)
+	for _, row := range rows[1:] {
+		assert.Nil(t, row.Txn)
+		require.NotNil(t, row.RootTxn)
+		assert.Equal(t, appCall.Txn, row.RootTxn.Txn)
+	}
+
+	// AccountE only receives the nested asset transfer.
+	rows = txnRows(t, db, idb.TransactionFilter{Address: test.AccountE[:]})
+	require.Len(t, rows, 1)
+	assert.Equal(t, 3, rows[0].Intra)
+	assert.Equal(t, appCall.Txn, rows[0].RootTxn.Txn)
+
+	_, blockRows, err := db.GetBlock(context.Background(), 1, idb.GetBlockOptions{Transactions: true})
+	require.NoError(t, err)
+	assert.Len(t, blockRows, 4)
+}
+
+func testStateDeltaHandlerError(t *testing.T, s suite) {
+	var rounds []basics.Round
+	handlerErr := errors.New("handler failed")
+	opts := idb.IndexerDbOptions{
+		StateDeltaHandler: func(round basics.Round, delta *ledgercore.StateDelta) error {
+			rounds = append(rounds, round)
+			if len(rounds) == 2 {
+				return handlerErr
+			}
+			return nil
+		},
+	}
+	db, shutdownFunc := s.open(t, opts)
+	defer shutdownFunc()
+
+	require.NoError(t, db.LoadGenesis(test.MakeGenesis()))
+	block := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&block))
+
+	// The round is rolled back when the handler fails.
+	txn := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	block, err := test.MakeBlockForTxns(block.BlockHeader, &txn)
+	require.NoError(t, err)
+	err = db.AddBlock(&block)
+	assert.True(t, errors.Is(err, handlerErr))
+	next, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), next)
+	assert.Empty(t, txnRows(t, db, idb.TransactionFilter{}))
+
+	require.NoError(t, db.AddBlock(&block))
+	assert.Equal(t, []basics.Round{0, 1, 1}, rounds)
+	assert.Len(t, txnRows(t, db, idb.TransactionFilter{}), 1)
+}
+
+func testBlocks(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	genesisBlock := test.MakeGenesisBlock()
+	pay := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	header := addBlock(t, db, genesisBlock.BlockHeader, &pay)
+
+	round, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), round)
+
+	blockHeader, txns, err := db.GetBlock(context.Background(), 1, idb.GetBlockOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, header.Round, blockHeader.Round)
+	assert.Equal(t, header.TimeStamp, blockHeader.TimeStamp)
+	assert.Equal(t, header.GenesisHash, blockHeader.GenesisHash)
+	assert.Equal(t, header.TxnRoot, blockHeader.TxnRoot)
+	assert.Empty(t, txns)
+
+	_, txns, err = db.GetBlock(
+		context.Background(), 1, idb.GetBlockOptions{Transactions: true})
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, pay.Txn, txns[0].Txn.Txn)
+	assert.Equal(t, uint64(1), txns[0].Round)
+	assert.Equal(t, time.Unix(header.TimeStamp, 0).UTC(), txns[0].RoundTime.UTC())
+
+	level, err := db.GetRewardsLevel(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, header.RewardsLevel, level)
+	_, err = db.GetRewardsLevel(context.Background(), 2)
+	assert.True(t, errors.Is(err, idb.ErrorBlockNotFound), "err: %v", err)
+
+	special, err := db.GetSpecialAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, test.FeeAddr, special.FeeSink)
+	assert.Equal(t, test.RewardAddr, special.RewardsPool)
+
+	health, err := db.Health()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), health.Round)
+	assert.True(t, health.DBAvailable)
+	assert.False(t, health.IsMigrating)
+}
+
+func testRollback(t *testing.T, s suite) {
+	db, shutdownFunc := s.open(t, idb.IndexerDbOptions{MaxRollbackRounds: 2})
+	defer shutdownFunc()
+
+	require.NoError(t, db.LoadGenesis(test.MakeGenesis()))
+	genesisBlock := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&genesisBlock))
+
+	const assetid = uint64(1)
+	createAsset := test.MakeAssetConfigTxn(0, 100, 0, false, "UNIT", "Test Asset", "", test.AccountA)
+	optIn := test.MakeAssetOptInTxn(assetid, test.AccountB)
+	header := addBlock(t, db, genesisBlock.BlockHeader, &createAsset, &optIn)
+
+	allAccounts := idb.AccountQueryOptions{IncludeAssetHoldings: true, IncludeAssetParams: true}
+	before := accounts(t, db, allAccounts)
+	beforeAssets := assets(t, db, idb.AssetsQuery{})
+
+	transfer := test.MakeAssetTransferTxn(assetid, 25, test.AccountA, test.AccountB, basics.Address{})
+	rekey := test.MakePaymentTxn(
+		1000, 0, 0, 0, 0, 0, test.AccountA, test.AccountA, basics.Address{}, test.AccountE)
+	header2 := addBlock(t, db, header, &transfer, &rekey)
+	createApp := test.MakeCreateAppTxn(test.AccountA)
+	createAsset2 := test.MakeAssetConfigTxn(0, 10, 0, false, "OTH", "Other", "", test.AccountB)
+	addBlock(t, db, header2, &createApp, &createAsset2)
+
+	// Round 1 is older than the 2 rounds with undo records.
+	err := db.Rollback(0)
+	assert.True(t, errors.Is(err, idb.ErrorRollbackNotAvailable), "%v", err)
+	assert.Error(t, db.Rollback(4))
+
+	require.NoError(t, db.Rollback(1))
+	next, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), next)
+	assert.Equal(t, before, accounts(t, db, allAccounts))
+	assert.Equal(t, beforeAssets, assets(t, db, idb.AssetsQuery{}))
+	assert.Empty(t, applications(t, db, models.SearchForApplicationsParams{}))
+	rows := txnRows(t, db, idb.TransactionFilter{})
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		assert.Equal(t, uint64(1), row.Round)
+	}
+	_, _, err = db.GetBlock(context.Background(), 2, idb.GetBlockOptions{})
+	assert.Error(t, err)
+
+	// The removed rounds can be imported again.
+	addBlock(t, db, header, &transfer, &rekey)
+	res := accounts(t, db, idb.AccountQueryOptions{EqualToAuthAddr: test.AccountE[:]})
+	require.Len(t, res, 1)
+	assert.Equal(t, test.AccountA.String(), res[0].Address)
+
+	// A rollback to the last round does nothing.
+	require.NoError(t, db.Rollback(2))
+	assert.Len(t, txnRows(t, db, idb.TransactionFilter{}), 4)
+}
+
+func testPruneTransactions(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	oldest, err := db.GetOldestRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), oldest)
+
+	appCall := test.MakeAppCallWithInnerTxn(
+		test.AccountA, test.AccountB, test.AccountC, test.AccountD, test.AccountE)
+	pay := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	pay.Txn.Lease[0] = 1
+	header := addBlock(t, db, test.MakeGenesisBlock().BlockHeader, &appCall, &pay)
+	pay2 := test.MakePaymentTxn(
+		1000, 20, 0, 0, 0, 0, test.AccountA, test.AccountC, basics.Address{}, basics.Address{})
+	addBlock(t, db, header, &pay2)
+
+	allAccounts := idb.AccountQueryOptions{IncludeAssetHoldings: true, IncludeAssetParams: true}
+	before := accounts(t, db, allAccounts)
+
+	require.NoError(t, db.PruneTransactions(2))
+	oldest, err = db.GetOldestRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), oldest)
+
+	// Only the transactions are removed, not the state or the block headers.
+	assert.Equal(t, before, accounts(t, db, allAccounts))
+	_, blockRows, err := db.GetBlock(context.Background(), 1, idb.GetBlockOptions{Transactions: true})
+	require.NoError(t, err)
+	assert.Empty(t, blockRows)
+
+	rows := txnRows(t, db, idb.TransactionFilter{})
+	require.Len(t, rows, 1)
+	assert.Equal(t, pay2.Txn, rows[0].Txn.Txn)
+	rows = txnRows(t, db, idb.TransactionFilter{Address: test.AccountA[:]})
+	require.Len(t, rows, 1)
+	assert.Equal(t, uint64(2), rows[0].Round)
+	assert.Empty(t, txnRows(t, db, idb.TransactionFilter{Address: test.AccountE[:]}))
+	assert.Empty(t, txnRows(t, db, idb.TransactionFilter{Txid: appCall.Txn.ID().String()}))
+	assert.Empty(t, txnRows(t, db, idb.TransactionFilter{Lease: pay.Txn.Lease[:]}))
+
+	// An older round does not bring anything back.
+	require.NoError(t, db.PruneTransactions(1))
+	oldest, err = db.GetOldestRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), oldest)
+	assert.Len(t, txnRows(t, db, idb.TransactionFilter{}), 1)
+}
+
+func testExportCheckpoints(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	_, err := db.GetExportCheckpoint("avro")
+	assert.True(t, errors.Is(err, idb.ErrorCheckpointNotFound), "%v", err)
+	checkpoints, err := db.GetExportCheckpoints()
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+
+	avro := idb.ExportCheckpoint{
+		NextRound: 200,
+		Files: []idb.ExportFile{
+			{Path: "txn/txn_0_99.avro", FirstRound: 0, LastRound: 99},
+			{Path: "txn/txn_100_199.avro", FirstRound: 100, LastRound: 199},
+		},
+	}
+	parquet := idb.ExportCheckpoint{NextRound: 5}
+	require.NoError(t, db.SetExportCheckpoint("avro", avro))
+	require.NoError(t, db.SetExportCheckpoint("parquet", parquet))
+
+	// Checkpoints are independent of the import state.
+	genesisBlock := test.MakeGenesisBlock()
+	addBlock(t, db, genesisBlock.BlockHeader)
+
+	checkpoint, err := db.GetExportCheckpoint("avro")
+	require.NoError(t, err)
+	assert.Equal(t, avro, checkpoint)
+	checkpoints, err = db.GetExportCheckpoints()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]idb.ExportCheckpoint{"avro": avro, "parquet": parquet}, checkpoints)
+
+	avro.Rewind(100)
+	require.NoError(t, db.SetExportCheckpoint("avro", avro))
+	checkpoint, err = db.GetExportCheckpoint("avro")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), checkpoint.NextRound)
+	assert.Len(t, checkpoint.Files, 1)
+
+	require.NoError(t, db.DeleteExportCheckpoint("parquet"))
+	require.NoError(t, db.DeleteExportCheckpoint("missing"))
+	checkpoints, err = db.GetExportCheckpoints()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avro"}, keys(checkpoints))
+
+	next, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), next)
+}
+
+func keys(m map[string]idb.ExportCheckpoint) []string {
+	var res []string
+	for k := range m {
+		res = append(res, k)
+	}
+	return res
+}
+
+func testWebhooks(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	_, err := db.GetWebhook("a")
+	assert.True(t, errors.Is(err, idb.ErrorWebhookNotFound), "%v", err)
+	webhooks, err := db.GetWebhooks()
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+
+	amount := uint64(0)
+	a := idb.Webhook{
+		URL:    "https://example.com/a",
+		Secret: "secret",
+		Filter: idb.WebhookFilter{
+			Address:             test.AccountA.String(),
+			AddressRole:         "receiver",
+			CurrencyGreaterThan: &amount,
+		},
+		NextRound: 10,
+		Replay:    []uint64{3, 4},
+		DeadLetters: []idb.WebhookDeadLetter{
+			{Round: 2, Attempts: 8, Error: "500 Internal Server Error", Time: 1600000000},
+		},
+	}
+	b := idb.Webhook{URL: "https://example.com/b", Filter: idb.WebhookFilter{AssetID: 5}, Paused: true}
+	require.NoError(t, db.SetWebhook("a", a))
+	require.NoError(t, db.SetWebhook("b", b))
+
+	webhook, err := db.GetWebhook("a")
+	require.NoError(t, err)
+	assert.Equal(t, a, webhook)
+	webhooks, err = db.GetWebhooks()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]idb.Webhook{"a": a, "b": b}, webhooks)
+
+	// Webhooks and export checkpoints do not see each other.
+	require.NoError(t, db.SetExportCheckpoint("a", idb.ExportCheckpoint{NextRound: 1}))
+	checkpoints, err := db.GetExportCheckpoints()
+	require.NoError(t, err)
+	assert.Len(t, checkpoints, 1)
+
+	require.NoError(t, db.DeleteWebhook("b"))
+	require.NoError(t, db.DeleteWebhook("missing"))
+	webhooks, err = db.GetWebhooks()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]idb.Webhook{"a": a}, webhooks)
+}
+
+// Run runs the conformance tests against the backends built by `factory`.
+// Every test builds a new backend with the argument returned by `setup`.
+func Run(t *testing.T, factory idb.IndexerDbFactory, setup Setup) {
+	s := suite{factory: factory, setup: setup}
+	tests := []struct {
+		name string
+		f    func(*testing.T, suite)
+	}{
+		{"Contract", testContract},
+		{"Blocks", testBlocks},
+		{"GenesisAccounts", testGenesisAccounts},
+		{"TransactionFilters", testTransactionFilters},
+		{"TransactionPaging", testTransactionPaging},
+		{"GetAccounts", testGetAccounts},
+		{"AssetsAndBalances", testAssetsAndBalances},
+		{"Applications", testApplications},
+		{"InnerTransactions", testInnerTransactions},
+		{"StateDeltaHandlerError", testStateDeltaHandlerError},
+		{"Rollback", testRollback},
+		{"PruneTransactions", testPruneTransactions},
+		{"ExportCheckpoints", testExportCheckpoints},
+		{"Webhooks", testWebhooks},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.f(t, s)
+		})
+	}
+}
+
+// RunContract only checks the interface contract that does not depend on
+// stored data, for backends such as the dummy backend that don't store
+// anything: writes succeed and every query returns a channel that is closed.
+func RunContract(t *testing.T, factory idb.IndexerDbFactory, setup Setup) {
+	testContract(t, suite{factory: factory, setup: setup})
+}
+
+// drain reads `ch` until it is closed and fails the test if that takes
+// longer than a few seconds. `ch` must be a channel.
+func drain(t *testing.T, name string, ch interface{}) {
+	v := reflect.ValueOf(ch)
+	require.False(t, v.IsNil(), "%s returned a nil channel", name)
+	timeout := reflect.ValueOf(time.After(5 * time.Second))
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: v},
+		{Dir: reflect.SelectRecv, Chan: timeout},
+	}
+	for {
+		chosen, _, ok := reflect.Select(cases)
+		if chosen == 1 {
+			require.Fail(t, name+" did not close its channel")
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func testContract(t *testing.T, s suite) {
+	db, shutdownFunc := s.setupIdb(t)
+	defer shutdownFunc()
+
+	genesisBlock := test.MakeGenesisBlock()
+	pay := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	addBlock(t, db, genesisBlock.BlockHeader, &pay)
+
+	_, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	_, err = db.GetSpecialAccounts()
+	require.NoError(t, err)
+	_, err = db.Health()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	txnCh, _ := db.Transactions(ctx, idb.TransactionFilter{})
+	drain(t, "Transactions()", txnCh)
+	accountCh, _ := db.GetAccounts(ctx, idb.AccountQueryOptions{})
+	drain(t, "GetAccounts()", accountCh)
+	assetCh, _ := db.Assets(ctx, idb.AssetsQuery{})
+	drain(t, "Assets()", assetCh)
+	balanceCh, _ := db.AssetBalances(ctx, idb.AssetBalanceQuery{})
+	drain(t, "AssetBalances()", balanceCh)
+	appCh, _ := db.Applications(ctx, &models.SearchForApplicationsParams{})
+	drain(t, "Applications()", appCh)
+
+	// A query with a canceled context still closes its channel.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	txnCh, _ = db.Transactions(canceled, idb.TransactionFilter{})
+	drain(t, "Transactions() with a canceled context", txnCh)
+}
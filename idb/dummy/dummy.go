@@ -17,7 +17,7 @@ type dummyIndexerDb struct {
 
 // IndexerDb is a mock implementation of IndexerDb
 func IndexerDb() idb.IndexerDb {
-	return &dummyIndexerDb{}
+	return &dummyIndexerDb{log: log.New()}
 }
 
 func (db *dummyIndexerDb) Close() {
@@ -33,6 +33,61 @@ func (db *dummyIndexerDb) LoadGenesis(genesis bookkeeping.Genesis) (err error) {
 	return nil
 }
 
+// Rollback is part of idb.IndexerDB
+func (db *dummyIndexerDb) Rollback(round uint64) error {
+	return nil
+}
+
+// PruneTransactions is part of idb.IndexerDB
+func (db *dummyIndexerDb) PruneTransactions(round uint64) error {
+	return nil
+}
+
+// GetOldestRound is part of idb.IndexerDB
+func (db *dummyIndexerDb) GetOldestRound() (uint64, error) {
+	return 0, nil
+}
+
+// GetExportCheckpoint is part of idb.IndexerDB
+func (db *dummyIndexerDb) GetExportCheckpoint(name string) (idb.ExportCheckpoint, error) {
+	return idb.ExportCheckpoint{}, idb.ErrorCheckpointNotFound
+}
+
+// GetExportCheckpoints is part of idb.IndexerDB
+func (db *dummyIndexerDb) GetExportCheckpoints() (map[string]idb.ExportCheckpoint, error) {
+	return nil, nil
+}
+
+// SetExportCheckpoint is part of idb.IndexerDB
+func (db *dummyIndexerDb) SetExportCheckpoint(name string, checkpoint idb.ExportCheckpoint) error {
+	return nil
+}
+
+// DeleteExportCheckpoint is part of idb.IndexerDB
+func (db *dummyIndexerDb) DeleteExportCheckpoint(name string) error {
+	return nil
+}
+
+// GetWebhook is part of idb.IndexerDB
+func (db *dummyIndexerDb) GetWebhook(id string) (idb.Webhook, error) {
+	return idb.Webhook{}, idb.ErrorWebhookNotFound
+}
+
+// GetWebhooks is part of idb.IndexerDB
+func (db *dummyIndexerDb) GetWebhooks() (map[string]idb.Webhook, error) {
+	return nil, nil
+}
+
+// SetWebhook is part of idb.IndexerDB
+func (db *dummyIndexerDb) SetWebhook(id string, webhook idb.Webhook) error {
+	return nil
+}
+
+// DeleteWebhook is part of idb.IndexerDB
+func (db *dummyIndexerDb) DeleteWebhook(id string) error {
+	return nil
+}
+
 // GetNextRoundToAccount is part of idb.IndexerDB
 func (db *dummyIndexerDb) GetNextRoundToAccount() (uint64, error) {
 	return 0, nil
@@ -53,29 +108,44 @@ func (db *dummyIndexerDb) GetBlock(ctx context.Context, round uint64, options id
 	return bookkeeping.BlockHeader{}, nil, nil
 }
 
+// GetRewardsLevel is part of idb.IndexerDB
+func (db *dummyIndexerDb) GetRewardsLevel(ctx context.Context, round uint64) (uint64, error) {
+	return 0, nil
+}
+
 // Transactions is part of idb.IndexerDB
 func (db *dummyIndexerDb) Transactions(ctx context.Context, tf idb.TransactionFilter) (<-chan idb.TxnRow, uint64) {
-	return nil, 0
+	out := make(chan idb.TxnRow)
+	close(out)
+	return out, 0
 }
 
 // GetAccounts is part of idb.IndexerDB
 func (db *dummyIndexerDb) GetAccounts(ctx context.Context, opts idb.AccountQueryOptions) (<-chan idb.AccountRow, uint64) {
-	return nil, 0
+	out := make(chan idb.AccountRow)
+	close(out)
+	return out, 0
 }
 
 // Assets is part of idb.IndexerDB
 func (db *dummyIndexerDb) Assets(ctx context.Context, filter idb.AssetsQuery) (<-chan idb.AssetRow, uint64) {
-	return nil, 0
+	out := make(chan idb.AssetRow)
+	close(out)
+	return out, 0
 }
 
 // AssetBalances is part of idb.IndexerDB
 func (db *dummyIndexerDb) AssetBalances(ctx context.Context, abq idb.AssetBalanceQuery) (<-chan idb.AssetBalanceRow, uint64) {
-	return nil, 0
+	out := make(chan idb.AssetBalanceRow)
+	close(out)
+	return out, 0
 }
 
 // Applications is part of idb.IndexerDB
 func (db *dummyIndexerDb) Applications(ctx context.Context, filter *models.SearchForApplicationsParams) (<-chan idb.ApplicationRow, uint64) {
-	return nil, 0
+	out := make(chan idb.ApplicationRow)
+	close(out)
+	return out, 0
 }
 
 // Health is part of idb.IndexerDB
@@ -15,8 +15,11 @@ func (df dummyFactory) Name() string {
 }
 
 // Build is part of the IndexerFactory interface.
-func (df dummyFactory) Build(arg string, opts idb.IndexerDbOptions, log *log.Logger) (idb.IndexerDb, chan struct{}, error) {
-	return &dummyIndexerDb{log: log}, nil, nil
+func (df dummyFactory) Build(arg string, opts idb.IndexerDbOptions, logger *log.Logger) (idb.IndexerDb, chan struct{}, error) {
+	if logger == nil {
+		logger = log.New()
+	}
+	return &dummyIndexerDb{log: logger}, nil, nil
 }
 
 func init() {
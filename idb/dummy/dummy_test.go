@@ -0,0 +1,13 @@
+package dummy
+
+import (
+	"testing"
+
+	"github.com/algorand/indexer/idb/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RunContract(t, dummyFactory{}, func(t *testing.T) (string, func()) {
+		return "", func() {}
+	})
+}
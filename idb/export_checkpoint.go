@@ -0,0 +1,59 @@
+package idb
+
+import "errors"
+
+// ErrorCheckpointNotFound is returned by GetExportCheckpoint() if the exporter
+// has no checkpoint.
+var ErrorCheckpointNotFound = errors.New("export checkpoint not found")
+
+// ExportFile is a file published by an exporter, with the rounds it holds.
+type ExportFile struct {
+	Path       string `codec:"path"`
+	FirstRound uint64 `codec:"first"`
+	LastRound  uint64 `codec:"last"`
+}
+
+// ExportCheckpoint is the progress of a named exporter, stored in the database
+// next to the import state. The rounds before NextRound are durably written to
+// Files. An exporter resumes at NextRound, and replaces or removes the files
+// it finds that are not in Files.
+type ExportCheckpoint struct {
+	NextRound uint64       `codec:"next_round"`
+	Files     []ExportFile `codec:"files"`
+}
+
+// Rewind moves the checkpoint back so that the exporter writes `round` again.
+// The files with rounds at or after `round` are removed from the manifest, so
+// if `round` is inside a file the checkpoint moves back to its first round.
+// Returns the files that were removed.
+func (c *ExportCheckpoint) Rewind(round uint64) []ExportFile {
+	if round >= c.NextRound {
+		return nil
+	}
+
+	// Move back to the start of the files that hold `round`, and of the files
+	// of other tables that hold the new next round.
+	next := round
+	for moved := true; moved; {
+		moved = false
+		for _, f := range c.Files {
+			if f.FirstRound < next && f.LastRound >= next {
+				next = f.FirstRound
+				moved = true
+			}
+		}
+	}
+
+	var final, removed []ExportFile
+	for _, f := range c.Files {
+		if f.LastRound >= next {
+			removed = append(removed, f)
+		} else {
+			final = append(final, f)
+		}
+	}
+
+	c.Files = final
+	c.NextRound = next
+	return removed
+}
@@ -0,0 +1,74 @@
+package idb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/algorand/indexer/idb"
+)
+
+func TestExportCheckpointRewind(t *testing.T) {
+	files := []idb.ExportFile{
+		{Path: "txn/0_99", FirstRound: 0, LastRound: 99},
+		{Path: "txn/100_199", FirstRound: 100, LastRound: 199},
+		{Path: "state/50_149", FirstRound: 50, LastRound: 149},
+		{Path: "txn/200_299", FirstRound: 200, LastRound: 299},
+	}
+
+	testcases := []struct {
+		name    string
+		round   uint64
+		next    uint64
+		kept    []string
+		removed []string
+	}{
+		{
+			name:    "file boundary",
+			round:   200,
+			next:    200,
+			kept:    []string{"txn/0_99", "txn/100_199", "state/50_149"},
+			removed: []string{"txn/200_299"},
+		},
+		{
+			name:    "inside a file",
+			round:   250,
+			next:    200,
+			kept:    []string{"txn/0_99", "txn/100_199", "state/50_149"},
+			removed: []string{"txn/200_299"},
+		},
+		{
+			name:    "overlapping files of other tables",
+			round:   120,
+			next:    0,
+			removed: []string{"txn/0_99", "txn/100_199", "state/50_149", "txn/200_299"},
+		},
+		{
+			name:  "at the checkpoint",
+			round: 300,
+			next:  300,
+			kept:  []string{"txn/0_99", "txn/100_199", "state/50_149", "txn/200_299"},
+		},
+	}
+
+	paths := func(files []idb.ExportFile) []string {
+		var res []string
+		for _, f := range files {
+			res = append(res, f.Path)
+		}
+		return res
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkpoint := idb.ExportCheckpoint{
+				NextRound: 300,
+				Files:     append([]idb.ExportFile{}, files...),
+			}
+			removed := checkpoint.Rewind(tc.round)
+			assert.Equal(t, tc.next, checkpoint.NextRound)
+			assert.Equal(t, tc.kept, paths(checkpoint.Files))
+			assert.Equal(t, tc.removed, paths(removed))
+		})
+	}
+}
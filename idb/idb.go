@@ -12,6 +12,7 @@ import (
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
 
 	models "github.com/algorand/indexer/api/generated/v2"
 )
@@ -151,8 +152,11 @@ var ErrorNotInitialized error = errors.New("accounting not initialized")
 // ErrorBlockNotFound is used when requesting a block that isn't in the DB.
 var ErrorBlockNotFound = errors.New("block not found")
 
+// ErrorRollbackNotAvailable is returned by Rollback() if some of the rounds to
+// remove were written without undo records.
+var ErrorRollbackNotAvailable = errors.New("rollback not available")
+
 // IndexerDb is the interface used to define alternative Indexer backends.
-// TODO: sqlite3 impl
 // TODO: cockroachdb impl
 type IndexerDb interface {
 	// Close all connections to the database. Should be called when IndexerDb is
@@ -164,11 +168,45 @@ type IndexerDb interface {
 
 	LoadGenesis(genesis bookkeeping.Genesis) (err error)
 
+	// Rollback removes the rounds after `round` and restores the state of
+	// `round`. Only rounds written with IndexerDbOptions.MaxRollbackRounds set
+	// can be removed, otherwise it returns ErrorRollbackNotAvailable.
+	Rollback(round uint64) error
+
+	// PruneTransactions removes the transactions of the rounds before `round`
+	// and records `round` as the oldest round with transactions. It does nothing
+	// if `round` is not after the oldest round. The state tables are not
+	// changed.
+	PruneTransactions(round uint64) error
+	// GetOldestRound returns the oldest round whose transactions are stored,
+	// 0 unless transactions were pruned.
+	GetOldestRound() (uint64, error)
+
+	// GetExportCheckpoint returns the checkpoint of the exporter `name`, or
+	// ErrorCheckpointNotFound.
+	GetExportCheckpoint(name string) (ExportCheckpoint, error)
+	// GetExportCheckpoints returns the checkpoints of all exporters by name.
+	GetExportCheckpoints() (map[string]ExportCheckpoint, error)
+	SetExportCheckpoint(name string, checkpoint ExportCheckpoint) error
+	// DeleteExportCheckpoint does nothing if the exporter has no checkpoint.
+	DeleteExportCheckpoint(name string) error
+
+	// GetWebhook returns the webhook `id`, or ErrorWebhookNotFound.
+	GetWebhook(id string) (Webhook, error)
+	// GetWebhooks returns all webhooks by id.
+	GetWebhooks() (map[string]Webhook, error)
+	SetWebhook(id string, webhook Webhook) error
+	// DeleteWebhook does nothing if there is no webhook with the id.
+	DeleteWebhook(id string) error
+
 	// GetNextRoundToAccount returns ErrorNotInitialized if genesis is not loaded.
 	GetNextRoundToAccount() (uint64, error)
 	GetSpecialAccounts() (transactions.SpecialAddresses, error)
 
 	GetBlock(ctx context.Context, round uint64, options GetBlockOptions) (blockHeader bookkeeping.BlockHeader, transactions []TxnRow, err error)
+	// GetRewardsLevel returns the rewards level of a round, or
+	// ErrorBlockNotFound.
+	GetRewardsLevel(ctx context.Context, round uint64) (uint64, error)
 
 	// The next multiple functions return a channel with results as well as the latest round
 	// accounted.
@@ -209,6 +247,8 @@ type TransactionFilter struct {
 	OffsetGT   *uint64 // nil for no filter
 	SigType    SigType // ["", "sig", "msig", "lsig"]
 	NotePrefix []byte
+	GroupID    []byte  // nil for no filter
+	Lease      []byte  // nil for no filter
 	AlgosGT    *uint64 // implictly filters on "pay" txns for Algos > this. This will be a slightly faster query than EffectiveAmountGT.
 	AlgosLT    *uint64
 	RekeyTo    *bool // nil for no filter
@@ -230,8 +270,9 @@ type TransactionFilter struct {
 
 // AccountQueryOptions is a parameter object with all of the account filter options.
 type AccountQueryOptions struct {
-	GreaterThanAddress []byte // for paging results
-	EqualToAddress     []byte // return exactly this one account
+	GreaterThanAddress []byte   // for paging results
+	EqualToAddress     []byte   // return exactly this one account
+	EqualToAddresses   [][]byte // return the accounts with these addresses
 
 	// return any accounts with this auth addr
 	EqualToAuthAddr []byte
@@ -253,6 +294,10 @@ type AccountQueryOptions struct {
 	IncludeAssetHoldings bool
 	IncludeAssetParams   bool
 
+	// ExcludeApps leaves out the created applications and the application
+	// local states, which are included otherwise.
+	ExcludeApps bool
+
 	// IncludeDeleted indicated whether to include deleted Assets, Applications, etc within the account.
 	IncludeDeleted bool
 
@@ -309,7 +354,8 @@ type AssetBalanceQuery struct {
 	Limit uint64 // max rows to return
 
 	// PrevAddress for paging, the last item from the previous
-	// query (items returned in address order)
+	// query (items returned in address order, or in address id order
+	// on postgres)
 	PrevAddress []byte
 }
 
@@ -331,9 +377,29 @@ type ApplicationRow struct {
 	Error       error
 }
 
+// StateDeltaHandler receives the state delta of every round written by an
+// IndexerDb, in round order, right before the round is committed. Round 0 holds
+// the genesis allocation. The same round is passed again if its commit fails
+// and the round is written again.
+type StateDeltaHandler func(round basics.Round, delta *ledgercore.StateDelta) error
+
 // IndexerDbOptions are the options common to all indexer backends.
 type IndexerDbOptions struct {
 	ReadOnly bool
+
+	// StateDeltaHandler is optional. If it returns an error the round is not
+	// committed, and the error is returned by AddBlock() or LoadGenesis().
+	StateDeltaHandler StateDeltaHandler
+
+	// MaxRollbackRounds is the number of most recent rounds for which undo
+	// records are kept, so that Rollback() can remove them. 0 keeps none.
+	MaxRollbackRounds uint64
+
+	// PartitionRounds is the number of rounds of each partition of the
+	// transaction tables, 0 leaves them unpartitioned. A new database is created
+	// partitioned and an existing one is converted by a migration, after which
+	// the setting cannot change. Only supported by postgres.
+	PartitionRounds uint64
 }
 
 // Health is the response object that IndexerDb objects need to return from the Health method.
@@ -0,0 +1,228 @@
+// Package convert builds the API models returned by the IndexerDb
+// implementations from go-algorand state objects. It holds the parts of the
+// account, asset and application queries that don't depend on how a backend
+// stores the data.
+package convert
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/util"
+)
+
+// StatusStrings are the account status names indexed by basics.Status.
+var StatusStrings = []string{"Offline", "Online", "NotParticipating"}
+
+// OfflineStatus is the status of accounts that never registered keys.
+const OfflineStatus = "Offline"
+
+func tealValue(tv basics.TealValue) models.TealValue {
+	switch tv.Type {
+	case basics.TealUintType:
+		return models.TealValue{
+			Uint: tv.Uint,
+			Type: uint64(tv.Type),
+		}
+	case basics.TealBytesType:
+		return models.TealValue{
+			Bytes: base64.StdEncoding.EncodeToString([]byte(tv.Bytes)),
+			Type:  uint64(tv.Type),
+		}
+	}
+	return models.TealValue{}
+}
+
+// TealKeyValue converts a teal key value store, returns nil for an empty
+// store.
+func TealKeyValue(tkv basics.TealKeyValue) *models.TealKeyValueStore {
+	if len(tkv) == 0 {
+		return nil
+	}
+	var out models.TealKeyValueStore = make([]models.TealKeyValue, len(tkv))
+	pos := 0
+	for key, tv := range tkv {
+		out[pos].Key = base64.StdEncoding.EncodeToString([]byte(key))
+		out[pos].Value = tealValue(tv)
+		pos++
+	}
+	return &out
+}
+
+// AccountData sets the fields of `account` that come from the account data
+// record: status, participation keys, auth address and app schema totals.
+func AccountData(account *models.Account, ad basics.AccountData) {
+	account.Status = StatusStrings[ad.Status]
+	hasSel := !allZero(ad.SelectionID[:])
+	hasVote := !allZero(ad.VoteID[:])
+	if hasSel || hasVote {
+		part := new(models.AccountParticipation)
+		if hasSel {
+			part.SelectionParticipationKey = ad.SelectionID[:]
+		}
+		if hasVote {
+			part.VoteParticipationKey = ad.VoteID[:]
+		}
+		part.VoteFirstValid = uint64(ad.VoteFirstValid)
+		part.VoteLastValid = uint64(ad.VoteLastValid)
+		part.VoteKeyDilution = ad.VoteKeyDilution
+		account.Participation = part
+	}
+
+	if !ad.AuthAddr.IsZero() {
+		account.AuthAddr = StringPtr(ad.AuthAddr.String())
+	}
+
+	totalSchema := models.ApplicationStateSchema{
+		NumByteSlice: ad.TotalAppSchema.NumByteSlice,
+		NumUint:      ad.TotalAppSchema.NumUint,
+	}
+	if totalSchema != (models.ApplicationStateSchema{}) {
+		account.AppsTotalSchema = &totalSchema
+	}
+	if ad.TotalExtraAppPages != 0 {
+		account.AppsTotalExtraPages = Uint64Ptr(uint64(ad.TotalExtraAppPages))
+	}
+}
+
+// Rewards sets the pending rewards and the amount of `account` as of
+// `header`. The account status must be set.
+func Rewards(account *models.Account, header bookkeeping.BlockHeader, microalgos uint64, rewardsbase uint64) error {
+	account.PendingRewards = 0
+	if account.Status != "NotParticipating" {
+		proto, ok := config.Consensus[header.CurrentProtocol]
+		if !ok {
+			return fmt.Errorf("get protocol err (%s)", header.CurrentProtocol)
+		}
+		rewardsUnits := uint64(0)
+		if proto.RewardUnit != 0 {
+			rewardsUnits = microalgos / proto.RewardUnit
+		}
+		rewardsDelta := header.RewardsLevel - rewardsbase
+		account.PendingRewards = rewardsUnits * rewardsDelta
+	}
+	account.Amount = microalgos + account.PendingRewards
+	return nil
+}
+
+// AssetParams converts the params of an asset created by `creator`.
+func AssetParams(creator string, ap basics.AssetParams) models.AssetParams {
+	return models.AssetParams{
+		Creator:       creator,
+		Total:         ap.Total,
+		Decimals:      uint64(ap.Decimals),
+		DefaultFrozen: BoolPtr(ap.DefaultFrozen),
+		UnitName:      StringPtr(util.PrintableUTF8OrEmpty(ap.UnitName)),
+		UnitNameB64:   ByteSlicePtr([]byte(ap.UnitName)),
+		Name:          StringPtr(util.PrintableUTF8OrEmpty(ap.AssetName)),
+		NameB64:       ByteSlicePtr([]byte(ap.AssetName)),
+		Url:           StringPtr(util.PrintableUTF8OrEmpty(ap.URL)),
+		UrlB64:        ByteSlicePtr([]byte(ap.URL)),
+		MetadataHash:  byteSliceOmitZeroPtr(ap.MetadataHash[:]),
+		Manager:       addrStr(ap.Manager),
+		Reserve:       addrStr(ap.Reserve),
+		Freeze:        addrStr(ap.Freeze),
+		Clawback:      addrStr(ap.Clawback),
+	}
+}
+
+// AppParams sets the program and state fields of `params` from `ap`. The
+// creator is left to the caller, an account lookup sets it even for deleted
+// applications whose params are not set.
+func AppParams(params *models.ApplicationParams, ap basics.AppParams) {
+	params.ApprovalProgram = ap.ApprovalProgram
+	params.ClearStateProgram = ap.ClearStateProgram
+	params.GlobalState = TealKeyValue(ap.GlobalState)
+	params.GlobalStateSchema = &models.ApplicationStateSchema{
+		NumByteSlice: ap.GlobalStateSchema.NumByteSlice,
+		NumUint:      ap.GlobalStateSchema.NumUint,
+	}
+	params.LocalStateSchema = &models.ApplicationStateSchema{
+		NumByteSlice: ap.LocalStateSchema.NumByteSlice,
+		NumUint:      ap.LocalStateSchema.NumUint,
+	}
+	if ap.ExtraProgramPages != 0 {
+		params.ExtraProgramPages = Uint64Ptr(uint64(ap.ExtraProgramPages))
+	}
+}
+
+// AppLocalState converts the local state of application `appid`. Round and
+// deleted fields are left to the caller.
+func AppLocalState(appid uint64, ls basics.AppLocalState) models.ApplicationLocalState {
+	return models.ApplicationLocalState{
+		Id: appid,
+		Schema: models.ApplicationStateSchema{
+			NumByteSlice: ls.Schema.NumByteSlice,
+			NumUint:      ls.Schema.NumUint,
+		},
+		KeyValue: TealKeyValue(ls.KeyValue),
+	}
+}
+
+// Uint64Ptr returns a pointer to a copy of `x`.
+func Uint64Ptr(x uint64) *uint64 {
+	out := new(uint64)
+	*out = x
+	return out
+}
+
+// BoolPtr returns a pointer to a copy of `x`.
+func BoolPtr(x bool) *bool {
+	out := new(bool)
+	*out = x
+	return out
+}
+
+// StringPtr returns nil for an empty string, a pointer to a copy otherwise.
+func StringPtr(x string) *string {
+	if len(x) == 0 {
+		return nil
+	}
+	out := new(string)
+	*out = x
+	return out
+}
+
+// ByteSlicePtr returns nil for an empty slice, a pointer to a copy otherwise.
+func ByteSlicePtr(x []byte) *[]byte {
+	if len(x) == 0 {
+		return nil
+	}
+
+	xx := make([]byte, len(x))
+	copy(xx, x)
+	return &xx
+}
+
+func byteSliceOmitZeroPtr(x []byte) *[]byte {
+	if allZero(x) {
+		return nil
+	}
+
+	xx := make([]byte, len(x))
+	copy(xx, x)
+	return &xx
+}
+
+func allZero(x []byte) bool {
+	for _, v := range x {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addrStr(addr basics.Address) *string {
+	if addr.IsZero() {
+		return nil
+	}
+	out := new(string)
+	*out = addr.String()
+	return out
+}
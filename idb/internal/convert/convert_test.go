@@ -0,0 +1,36 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/stretchr/testify/assert"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+)
+
+func TestAppParamsKeepsCreator(t *testing.T) {
+	ap := basics.AppParams{
+		ApprovalProgram:   []byte{1},
+		ClearStateProgram: []byte{2},
+		StateSchemas: basics.StateSchemas{
+			GlobalStateSchema: basics.StateSchema{NumUint: 3},
+			LocalStateSchema:  basics.StateSchema{NumByteSlice: 4},
+		},
+	}
+
+	var params models.ApplicationParams
+	AppParams(&params, ap)
+	assert.Nil(t, params.Creator)
+	assert.Equal(t, []byte{1}, params.ApprovalProgram)
+	assert.Equal(t, []byte{2}, params.ClearStateProgram)
+	assert.Nil(t, params.GlobalState)
+	assert.Equal(t, &models.ApplicationStateSchema{NumUint: 3}, params.GlobalStateSchema)
+	assert.Equal(t, &models.ApplicationStateSchema{NumByteSlice: 4}, params.LocalStateSchema)
+	assert.Nil(t, params.ExtraProgramPages)
+
+	creator := "creator"
+	params = models.ApplicationParams{Creator: &creator}
+	AppParams(&params, ap)
+	assert.Equal(t, &creator, params.Creator)
+}
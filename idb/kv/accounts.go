@@ -0,0 +1,402 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/internal/convert"
+)
+
+func uintOrDefault(x *uint64) uint64 {
+	if x != nil {
+		return *x
+	}
+	return 0
+}
+
+type getAccountsRequest struct {
+	ctx         context.Context
+	r           Reader
+	opts        idb.AccountQueryOptions
+	blockheader bookkeeping.BlockHeader
+	out         chan idb.AccountRow
+}
+
+// GetAccounts is part of idb.IndexerDB
+func (db *IndexerDb) GetAccounts(ctx context.Context, opts idb.AccountQueryOptions) (<-chan idb.AccountRow, uint64) {
+	out := make(chan idb.AccountRow, 1)
+
+	if opts.HasAssetID != 0 {
+		opts.IncludeAssetHoldings = true
+	} else if (opts.AssetGT != nil) || (opts.AssetLT != nil) {
+		err := fmt.Errorf("AssetGT=%d, AssetLT=%d, but HasAssetID=%d", uintOrDefault(opts.AssetGT), uintOrDefault(opts.AssetLT), opts.HasAssetID)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		return out, 0
+	}
+
+	// Take a snapshot so we get everything at one consistent point in time and
+	// round of accounting.
+	snap := db.store.Snapshot()
+
+	// Get round number through which accounting has been updated
+	round, err := getMaxRoundAccounted(snap)
+	if err != nil {
+		err = fmt.Errorf("account round err %v", err)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		snap.Release()
+		return out, round
+	}
+
+	// Get block header for that round so we know protocol and rewards info
+	blockheader, ok, err := getBlockHeader(snap, round)
+	if err == nil && !ok {
+		err = fmt.Errorf("not found")
+	}
+	if err != nil {
+		err = fmt.Errorf("account round header %d err %v", round, err)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		snap.Release()
+		return out, round
+	}
+
+	req := &getAccountsRequest{
+		ctx:         ctx,
+		r:           snap,
+		opts:        opts,
+		blockheader: blockheader,
+		out:         out,
+	}
+	go func() {
+		db.yieldAccountsThread(req)
+		close(req.out)
+		snap.Release()
+	}()
+	return out, round
+}
+
+// iterateAccountAddresses calls `f` for the addresses selected by the most
+// specific key range of the query options, in ascending order.
+func iterateAccountAddresses(r Reader, opts idb.AccountQueryOptions, f func(addr []byte) bool) {
+	if len(opts.EqualToAddress) > 0 {
+		f(opts.EqualToAddress)
+		return
+	}
+	if len(opts.EqualToAddresses) > 0 {
+		addrs := append([][]byte{}, opts.EqualToAddresses...)
+		sort.Slice(addrs, func(i, j int) bool {
+			return bytes.Compare(addrs[i], addrs[j]) < 0
+		})
+		for i, addr := range addrs {
+			if i > 0 && bytes.Equal(addr, addrs[i-1]) {
+				continue
+			}
+			if !f(addr) {
+				return
+			}
+		}
+		return
+	}
+
+	var prefix []byte
+	switch {
+	case opts.HasAssetID != 0:
+		prefix = idKey(accountAssetByAssetPrefix, opts.HasAssetID)
+	case opts.HasAppID != 0:
+		prefix = idKey(accountAppByAppPrefix, opts.HasAppID)
+	case len(opts.EqualToAuthAddr) > 0:
+		prefix = addressKey(accountByAuthAddrPrefix, opts.EqualToAuthAddr)
+	default:
+		prefix = []byte{accountPrefix}
+	}
+	start := prefix
+	if len(opts.GreaterThanAddress) > 0 {
+		start = after(append(append([]byte{}, prefix...), opts.GreaterThanAddress...))
+	}
+	r.Iterate(start, prefixEnd(prefix), false, func(key, value []byte) bool {
+		return f(keyAddress(key))
+	})
+}
+
+// matchAccount applies the filters of the query options to the account.
+func matchAccount(r Reader, opts idb.AccountQueryOptions, addr []byte, row *accountRow) (bool, error) {
+	switch {
+	case len(opts.GreaterThanAddress) > 0 && bytes.Compare(addr, opts.GreaterThanAddress) <= 0:
+		return false, nil
+	case opts.AlgosGreaterThan != nil && !(row.MicroAlgos > *opts.AlgosGreaterThan):
+		return false, nil
+	case opts.AlgosLessThan != nil && !(row.MicroAlgos < *opts.AlgosLessThan):
+		return false, nil
+	case !opts.IncludeDeleted && row.Deleted:
+		return false, nil
+	case len(opts.EqualToAuthAddr) > 0 && !bytes.Equal(row.AuthAddr, opts.EqualToAuthAddr):
+		return false, nil
+	}
+
+	// filter by has-asset or has-app, regardless of deleted holdings
+	if opts.HasAssetID != 0 {
+		var holding holdingRow
+		ok, err := getRow(r, accountAssetKey(addr, opts.HasAssetID), &holding)
+		if err != nil || !ok {
+			return false, err
+		}
+		if opts.AssetGT != nil && !(holding.Amount > *opts.AssetGT) {
+			return false, nil
+		}
+		if opts.AssetLT != nil && !(holding.Amount < *opts.AssetLT) {
+			return false, nil
+		}
+	}
+	if opts.HasAppID != 0 {
+		if _, ok := r.Get(accountAppKey(addr, opts.HasAppID)); !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
+	count := uint64(0)
+	iterateAccountAddresses(req.r, req.opts, func(addr []byte) bool {
+		var row accountRow
+		ok, err := getRow(req.r, accountKey(addr), &row)
+		if err == nil && ok {
+			ok, err = matchAccount(req.r, req.opts, addr, &row)
+		}
+		if err != nil {
+			err = fmt.Errorf("account scan err %v", err)
+			req.out <- idb.AccountRow{Error: err}
+			return false
+		}
+		if !ok {
+			return true
+		}
+
+		account, err := buildAccount(req, addr, &row)
+		if err != nil {
+			req.out <- idb.AccountRow{Error: err}
+			return false
+		}
+
+		select {
+		case req.out <- idb.AccountRow{Account: account}:
+			count++
+			return req.opts.Limit == 0 || count < req.opts.Limit
+		case <-req.ctx.Done():
+			return false
+		}
+	})
+}
+
+// buildAccount converts the account row to a model and loads the per account
+// tables selected by the query options.
+func buildAccount(req *getAccountsRequest, addr []byte, row *accountRow) (models.Account, error) {
+	var account models.Account
+	var aaddr basics.Address
+	copy(aaddr[:], addr)
+	account.Address = aaddr.String()
+	account.Round = uint64(req.blockheader.Round)
+	account.AmountWithoutPendingRewards = row.MicroAlgos
+	account.RewardBase = convert.Uint64Ptr(row.RewardsBase)
+	// Accounts without keyreg transactions are offline, deleted accounts have
+	// no account data.
+	account.Status = convert.OfflineStatus
+	if row.AccountData != nil {
+		ad, err := decodeAccountData(row.AccountData)
+		if err != nil {
+			return models.Account{}, fmt.Errorf("account decode err %v", err)
+		}
+		convert.AccountData(&account, ad)
+	}
+
+	err := convert.Rewards(&account, req.blockheader, row.MicroAlgos, row.RewardsBase)
+	if err != nil {
+		return models.Account{}, err
+	}
+	account.Rewards = row.RewardsTotal
+	account.CreatedAtRound = convert.Uint64Ptr(row.CreatedAt)
+	account.ClosedAtRound = row.ClosedAt
+	account.Deleted = convert.BoolPtr(row.Deleted)
+	if row.KeyType != "" {
+		account.SigType = convert.StringPtr(string(row.KeyType))
+	}
+
+	if req.opts.IncludeAssetHoldings {
+		holdings, err := loadAccountAssetHoldings(req, addr)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(holdings) > 0 {
+			account.Assets = &holdings
+		}
+	}
+	if req.opts.IncludeAssetParams {
+		assets, err := loadAccountCreatedAssets(req, addr, account.Address)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(assets) > 0 {
+			account.CreatedAssets = &assets
+		}
+	}
+	if !req.opts.ExcludeApps {
+		apps, err := loadAccountCreatedApps(req, addr, account.Address)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(apps) > 0 {
+			account.CreatedApps = &apps
+		}
+		localStates, err := loadAccountAppLocalStates(req, addr)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(localStates) > 0 {
+			account.AppsLocalState = &localStates
+		}
+	}
+
+	return account, nil
+}
+
+func loadAccountAssetHoldings(req *getAccountsRequest, addr []byte) ([]models.AssetHolding, error) {
+	var res []models.AssetHolding
+	var err error
+	iteratePrefix(req.r, addressKey(accountAssetPrefix, addr), false, func(key, value []byte) bool {
+		var row holdingRow
+		err = decodeRow(value, &row)
+		if err != nil {
+			err = fmt.Errorf("account asset holdings decode err %v", err)
+			return false
+		}
+		if row.Deleted && !req.opts.IncludeDeleted {
+			return true
+		}
+		res = append(res, models.AssetHolding{
+			Amount:          row.Amount,
+			IsFrozen:        row.Frozen,
+			AssetId:         keyID(key),
+			OptedOutAtRound: row.ClosedAt,
+			OptedInAtRound:  convert.Uint64Ptr(row.CreatedAt),
+			Deleted:         convert.BoolPtr(row.Deleted),
+		})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// iterateCreated calls `f` for the assets or apps created by `addr`, in id
+// order.
+func iterateCreated(req *getAccountsRequest, byCreatorPrefix, prefix byte, addr []byte, f func(id uint64, row *creatableRow) error) error {
+	var err error
+	iteratePrefix(req.r, addressKey(byCreatorPrefix, addr), false, func(key, value []byte) bool {
+		id := keyID(key)
+		var row creatableRow
+		var ok bool
+		ok, err = getRow(req.r, idKey(prefix, id), &row)
+		if err != nil {
+			return false
+		}
+		if !ok || (row.Deleted && !req.opts.IncludeDeleted) {
+			return true
+		}
+		err = f(id, &row)
+		return err == nil
+	})
+	return err
+}
+
+func loadAccountCreatedAssets(req *getAccountsRequest, addr []byte, creator string) ([]models.Asset, error) {
+	var res []models.Asset
+	err := iterateCreated(req, assetByCreatorPrefix, assetPrefix, addr, func(id uint64, row *creatableRow) error {
+		ap, err := decodeAssetParams(row.Params)
+		if err != nil {
+			return fmt.Errorf("account created assets params err %v", err)
+		}
+		res = append(res, models.Asset{
+			Index:            id,
+			CreatedAtRound:   convert.Uint64Ptr(row.CreatedAt),
+			DestroyedAtRound: row.ClosedAt,
+			Deleted:          convert.BoolPtr(row.Deleted),
+			Params:           convert.AssetParams(creator, ap),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func loadAccountCreatedApps(req *getAccountsRequest, addr []byte, creator string) ([]models.Application, error) {
+	var res []models.Application
+	err := iterateCreated(req, appByCreatorPrefix, appPrefix, addr, func(id uint64, row *creatableRow) error {
+		params, err := decodeAppParams(row.Params)
+		if err != nil {
+			return fmt.Errorf("account created apps params err %v", err)
+		}
+
+		app := models.Application{
+			Id:             id,
+			CreatedAtRound: convert.Uint64Ptr(row.CreatedAt),
+			DeletedAtRound: row.ClosedAt,
+			Deleted:        convert.BoolPtr(row.Deleted),
+		}
+		app.Params.Creator = convert.StringPtr(creator)
+
+		// If these are both nil the app was probably deleted, leave out params
+		// some "required" fields will be left in the results.
+		if params.ApprovalProgram != nil || params.ClearStateProgram != nil {
+			convert.AppParams(&app.Params, params)
+		}
+		res = append(res, app)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func loadAccountAppLocalStates(req *getAccountsRequest, addr []byte) ([]models.ApplicationLocalState, error) {
+	var res []models.ApplicationLocalState
+	var err error
+	iteratePrefix(req.r, addressKey(accountAppPrefix, addr), false, func(key, value []byte) bool {
+		var row localStateRow
+		err = decodeRow(value, &row)
+		if err != nil {
+			err = fmt.Errorf("account local states decode err %v", err)
+			return false
+		}
+		if row.Deleted && !req.opts.IncludeDeleted {
+			return true
+		}
+		ls, err2 := decodeAppLocalState(row.LocalState)
+		if err2 != nil {
+			err = fmt.Errorf("account local states decode err %v", err2)
+			return false
+		}
+		localState := convert.AppLocalState(keyID(key), ls)
+		localState.OptedInAtRound = convert.Uint64Ptr(row.CreatedAt)
+		localState.ClosedOutAtRound = row.ClosedAt
+		localState.Deleted = convert.BoolPtr(row.Deleted)
+		res = append(res, localState)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,302 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand/data/basics"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/internal/convert"
+)
+
+// containsFold is a case insensitive substring comparison, like ILIKE.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// iterateCreatables calls `f` for the assets or apps with an id greater than
+// `gt`, in id order. If `creator` is set only the ones it created are
+// visited.
+func iterateCreatables(r Reader, prefix, byCreatorPrefix byte, creator []byte, gt uint64, f func(id uint64, value []byte) bool) {
+	if gt == ^uint64(0) {
+		return
+	}
+
+	if creator != nil {
+		indexPrefix := addressKey(byCreatorPrefix, creator)
+		start := addressIDKey(byCreatorPrefix, creator, gt+1)
+		r.Iterate(start, prefixEnd(indexPrefix), false, func(key, value []byte) bool {
+			id := keyID(key)
+			row, ok := r.Get(idKey(prefix, id))
+			if !ok {
+				return true
+			}
+			return f(id, row)
+		})
+		return
+	}
+
+	r.Iterate(idKey(prefix, gt+1), []byte{prefix + 1}, false, func(key, value []byte) bool {
+		return f(keyID(key), value)
+	})
+}
+
+func matchAsset(filter idb.AssetsQuery, id uint64, row *creatableRow) bool {
+	switch {
+	case filter.AssetID != 0 && id != filter.AssetID:
+		return false
+	case filter.Name != "" && !containsFold(row.Name, filter.Name):
+		return false
+	case filter.Unit != "" && !containsFold(row.Unit, filter.Unit):
+		return false
+	case filter.Query != "" && !containsFold(row.Name, filter.Query) && !containsFold(row.Unit, filter.Query):
+		return false
+	case !filter.IncludeDeleted && row.Deleted:
+		return false
+	}
+	return true
+}
+
+// Assets is part of idb.IndexerDB
+func (db *IndexerDb) Assets(ctx context.Context, filter idb.AssetsQuery) (<-chan idb.AssetRow, uint64) {
+	out := make(chan idb.AssetRow, 1)
+
+	snap := db.store.Snapshot()
+	round, err := getMaxRoundAccounted(snap)
+	if err != nil {
+		out <- idb.AssetRow{Error: err}
+		close(out)
+		snap.Release()
+		return out, round
+	}
+
+	go func() {
+		db.yieldAssetsThread(ctx, snap, filter, out)
+		close(out)
+		snap.Release()
+	}()
+	return out, round
+}
+
+func (db *IndexerDb) yieldAssetsThread(ctx context.Context, r Reader, filter idb.AssetsQuery, out chan<- idb.AssetRow) {
+	gt := filter.AssetIDGreaterThan
+	if filter.AssetID != 0 && filter.AssetID-1 > gt {
+		gt = filter.AssetID - 1
+	}
+
+	count := uint64(0)
+	iterateCreatables(r, assetPrefix, assetByCreatorPrefix, filter.Creator, gt, func(id uint64, value []byte) bool {
+		var row creatableRow
+		err := decodeRow(value, &row)
+		if err != nil {
+			out <- idb.AssetRow{Error: err}
+			return false
+		}
+		if filter.AssetID != 0 && id > filter.AssetID {
+			return false
+		}
+		if !matchAsset(filter, id, &row) {
+			return true
+		}
+		params, err := decodeAssetParams(row.Params)
+		if err != nil {
+			out <- idb.AssetRow{Error: err}
+			return false
+		}
+		rec := idb.AssetRow{
+			AssetID:      id,
+			Creator:      row.Creator,
+			Params:       params,
+			CreatedRound: convert.Uint64Ptr(row.CreatedAt),
+			ClosedRound:  row.ClosedAt,
+			Deleted:      convert.BoolPtr(row.Deleted),
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- rec:
+			count++
+			return filter.Limit == 0 || count < filter.Limit
+		}
+	})
+}
+
+// AssetBalances is part of idb.IndexerDB
+func (db *IndexerDb) AssetBalances(ctx context.Context, abq idb.AssetBalanceQuery) (<-chan idb.AssetBalanceRow, uint64) {
+	out := make(chan idb.AssetBalanceRow, 1)
+
+	snap := db.store.Snapshot()
+	round, err := getMaxRoundAccounted(snap)
+	if err != nil {
+		out <- idb.AssetBalanceRow{Error: err}
+		close(out)
+		snap.Release()
+		return out, round
+	}
+
+	go func() {
+		db.yieldAssetBalanceThread(ctx, snap, abq, out)
+		close(out)
+		snap.Release()
+	}()
+	return out, round
+}
+
+// iterateHoldings calls `f` for the asset holdings selected by `abq`, in
+// address order.
+func iterateHoldings(r Reader, abq idb.AssetBalanceQuery, f func(addr []byte, assetID uint64, value []byte) bool) {
+	if abq.AssetID != 0 {
+		prefix := idKey(accountAssetByAssetPrefix, abq.AssetID)
+		start := prefix
+		if len(abq.PrevAddress) != 0 {
+			start = after(idAddressKey(accountAssetByAssetPrefix, abq.AssetID, abq.PrevAddress))
+		}
+		r.Iterate(start, prefixEnd(prefix), false, func(key, value []byte) bool {
+			addr := keyAddress(key)
+			row, ok := r.Get(accountAssetKey(addr, abq.AssetID))
+			if !ok {
+				return true
+			}
+			return f(addr, abq.AssetID, row)
+		})
+		return
+	}
+
+	start := []byte{accountAssetPrefix}
+	if len(abq.PrevAddress) != 0 {
+		// Skip all the holdings of the previous address.
+		start = prefixEnd(addressKey(accountAssetPrefix, abq.PrevAddress))
+		if start == nil {
+			return
+		}
+	}
+	r.Iterate(start, []byte{accountAssetPrefix + 1}, false, func(key, value []byte) bool {
+		addr := key[1 : 1+addressLen]
+		return f(addr, keyID(key), value)
+	})
+}
+
+func (db *IndexerDb) yieldAssetBalanceThread(ctx context.Context, r Reader, abq idb.AssetBalanceQuery, out chan<- idb.AssetBalanceRow) {
+	count := uint64(0)
+	iterateHoldings(r, abq, func(addr []byte, assetID uint64, value []byte) bool {
+		var row holdingRow
+		err := decodeRow(value, &row)
+		if err != nil {
+			out <- idb.AssetBalanceRow{Error: err}
+			return false
+		}
+		switch {
+		case abq.AmountGT != nil && !(row.Amount > *abq.AmountGT):
+			return true
+		case abq.AmountLT != nil && !(row.Amount < *abq.AmountLT):
+			return true
+		case !abq.IncludeDeleted && row.Deleted:
+			return true
+		}
+		rec := idb.AssetBalanceRow{
+			Address:      append([]byte{}, addr...),
+			AssetID:      assetID,
+			Amount:       row.Amount,
+			Frozen:       row.Frozen,
+			ClosedRound:  row.ClosedAt,
+			CreatedRound: convert.Uint64Ptr(row.CreatedAt),
+			Deleted:      convert.BoolPtr(row.Deleted),
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- rec:
+			count++
+			return abq.Limit == 0 || count < abq.Limit
+		}
+	})
+}
+
+// Applications is part of idb.IndexerDB
+func (db *IndexerDb) Applications(ctx context.Context, filter *models.SearchForApplicationsParams) (<-chan idb.ApplicationRow, uint64) {
+	out := make(chan idb.ApplicationRow, 1)
+	if filter == nil {
+		out <- idb.ApplicationRow{Error: fmt.Errorf("no arguments provided to application search")}
+		close(out)
+		return out, 0
+	}
+
+	snap := db.store.Snapshot()
+	round, err := getMaxRoundAccounted(snap)
+	if err != nil {
+		out <- idb.ApplicationRow{Error: err}
+		close(out)
+		snap.Release()
+		return out, round
+	}
+
+	go func() {
+		db.yieldApplicationsThread(ctx, snap, filter, out)
+		close(out)
+		snap.Release()
+	}()
+	return out, round
+}
+
+func (db *IndexerDb) yieldApplicationsThread(ctx context.Context, r Reader, filter *models.SearchForApplicationsParams, out chan idb.ApplicationRow) {
+	gt := uint64(0)
+	if filter.Next != nil {
+		var err error
+		gt, err = strconv.ParseUint(*filter.Next, 10, 64)
+		if err != nil {
+			out <- idb.ApplicationRow{Error: fmt.Errorf("invalid next token %q, %v", *filter.Next, err)}
+			return
+		}
+	}
+	if filter.ApplicationId != nil {
+		if *filter.ApplicationId <= gt {
+			return
+		}
+		gt = *filter.ApplicationId - 1
+	}
+	includeAll := filter.IncludeAll != nil && *filter.IncludeAll
+
+	count := uint64(0)
+	iterateCreatables(r, appPrefix, appByCreatorPrefix, nil, gt, func(id uint64, value []byte) bool {
+		if filter.ApplicationId != nil && id != *filter.ApplicationId {
+			return false
+		}
+		var row creatableRow
+		err := decodeRow(value, &row)
+		if err != nil {
+			out <- idb.ApplicationRow{Error: err}
+			return false
+		}
+		if row.Deleted && !includeAll {
+			return true
+		}
+
+		var rec idb.ApplicationRow
+		rec.Application.Id = id
+		rec.Application.CreatedAtRound = convert.Uint64Ptr(row.CreatedAt)
+		rec.Application.DeletedAtRound = row.ClosedAt
+		rec.Application.Deleted = convert.BoolPtr(row.Deleted)
+		ap, err := decodeAppParams(row.Params)
+		if err != nil {
+			rec.Error = fmt.Errorf("app=%d decode err, %v", id, err)
+			out <- rec
+			return false
+		}
+		var aaddr basics.Address
+		copy(aaddr[:], row.Creator)
+		rec.Application.Params.Creator = convert.StringPtr(aaddr.String())
+		convert.AppParams(&rec.Application.Params, ap)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- rec:
+			count++
+			return filter.Limit == nil || count < *filter.Limit
+		}
+	})
+}
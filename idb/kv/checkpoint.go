@@ -0,0 +1,89 @@
+package kv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// exportCheckpointKey returns the metastate key of the checkpoint of the
+// exporter `name`.
+func exportCheckpointKey(name string) string {
+	return exportCheckpointMetastateKeyPrefix + name
+}
+
+// GetExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) GetExportCheckpoint(name string) (idb.ExportCheckpoint, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	var checkpoint idb.ExportCheckpoint
+	err := getMetastate(snap, exportCheckpointKey(name), &checkpoint)
+	if err == idb.ErrorNotInitialized {
+		return idb.ExportCheckpoint{}, idb.ErrorCheckpointNotFound
+	}
+	if err != nil {
+		return idb.ExportCheckpoint{}, fmt.Errorf("GetExportCheckpoint() err: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// GetExportCheckpoints is part of idb.IndexerDb.
+func (db *IndexerDb) GetExportCheckpoints() (map[string]idb.ExportCheckpoint, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	res := make(map[string]idb.ExportCheckpoint)
+	prefix := metastateKey(exportCheckpointMetastateKeyPrefix)
+	var err error
+	snap.Iterate(prefix, prefixEnd(prefix), false, func(key, value []byte) bool {
+		var checkpoint idb.ExportCheckpoint
+		err = decodeRow(value, &checkpoint)
+		if err != nil {
+			err = fmt.Errorf("GetExportCheckpoints() key %q err: %w", key, err)
+			return false
+		}
+		name := strings.TrimPrefix(string(key[1:]), exportCheckpointMetastateKeyPrefix)
+		res[name] = checkpoint
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SetExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) SetExportCheckpoint(name string, checkpoint idb.ExportCheckpoint) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	setMetastate(o, exportCheckpointKey(name), &checkpoint)
+	err := db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("SetExportCheckpoint() err: %w", err)
+	}
+	return nil
+}
+
+// DeleteExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) DeleteExportCheckpoint(name string) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	o.delete(metastateKey(exportCheckpointKey(name)))
+	err := db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("DeleteExportCheckpoint() err: %w", err)
+	}
+	return nil
+}
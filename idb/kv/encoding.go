@@ -0,0 +1,215 @@
+package kv
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// importState encodes an import round counter.
+type importState struct {
+	NextRoundToAccount uint64 `codec:"next_account_round"`
+}
+
+// undoOp is the value of a key before a round was written. Present is false if
+// the key did not exist.
+type undoOp struct {
+	Key     []byte `codec:"k"`
+	Value   []byte `codec:"v,omitempty"`
+	Present bool   `codec:"p,omitempty"`
+}
+
+// undoRow holds the previous values of all the keys written by a round.
+type undoRow struct {
+	Ops []undoOp `codec:"ops"`
+}
+
+// The row types mirror the columns of the sqlite tables. Nested blobs are
+// msgpack encoded with protocol.Encode(), rows with protocol.EncodeReflect().
+
+type txnRow struct {
+	TypeEnum idb.TxnTypeEnum `codec:"typeenum"`
+	// Asset is 0 for Algos, otherwise the AssetIndex or AppIndex.
+	Asset uint64 `codec:"asset,omitempty"`
+	// Txid is empty for inner transactions.
+	Txid string `codec:"txid,omitempty"`
+	// Txn is the signed txn with apply data; inner txns exclude nested inner
+	// txns.
+	Txn   []byte       `codec:"txn"`
+	Extra idb.TxnExtra `codec:"extra"`
+	// SigType is empty for inner transactions.
+	SigType idb.SigType `codec:"sigtype,omitempty"`
+}
+
+type accountRow struct {
+	MicroAlgos   uint64  `codec:"microalgos,omitempty"`
+	RewardsBase  uint64  `codec:"rewardsbase,omitempty"`
+	RewardsTotal uint64  `codec:"rewards_total,omitempty"`
+	Deleted      bool    `codec:"deleted,omitempty"`
+	CreatedAt    uint64  `codec:"created_at,omitempty"`
+	ClosedAt     *uint64 `codec:"closed_at,omitempty"`
+	// KeyType is empty if unknown.
+	KeyType idb.SigType `codec:"keytype,omitempty"`
+	// AuthAddr is empty if not rekeyed.
+	AuthAddr []byte `codec:"auth_addr,omitempty"`
+	// AccountData is the trimmed AccountData, nil iff the account is deleted.
+	AccountData []byte `codec:"account_data,omitempty"`
+}
+
+type holdingRow struct {
+	Amount    uint64  `codec:"amount,omitempty"`
+	Frozen    bool    `codec:"frozen,omitempty"`
+	Deleted   bool    `codec:"deleted,omitempty"`
+	CreatedAt uint64  `codec:"created_at,omitempty"`
+	ClosedAt  *uint64 `codec:"closed_at,omitempty"`
+}
+
+// creatableRow is a row of the asset and app tables.
+type creatableRow struct {
+	Creator []byte `codec:"creator"`
+	// Params is nil iff the creatable is deleted.
+	Params []byte `codec:"params,omitempty"`
+	// Name and Unit are the asset name and unit name, for searching.
+	Name      string  `codec:"name,omitempty"`
+	Unit      string  `codec:"unit,omitempty"`
+	Deleted   bool    `codec:"deleted,omitempty"`
+	CreatedAt uint64  `codec:"created_at,omitempty"`
+	ClosedAt  *uint64 `codec:"closed_at,omitempty"`
+}
+
+type localStateRow struct {
+	// LocalState is nil iff deleted from the account.
+	LocalState []byte  `codec:"localstate,omitempty"`
+	Deleted    bool    `codec:"deleted,omitempty"`
+	CreatedAt  uint64  `codec:"created_at,omitempty"`
+	ClosedAt   *uint64 `codec:"closed_at,omitempty"`
+}
+
+func encodeRow(row interface{}) []byte {
+	return protocol.EncodeReflect(row)
+}
+
+func decodeRow(data []byte, row interface{}) error {
+	err := protocol.DecodeReflect(data, row)
+	if err != nil {
+		return fmt.Errorf("decodeRow() err: %w", err)
+	}
+	return nil
+}
+
+// trimAccountData removes the fields that are stored in separate fields or
+// tables.
+func trimAccountData(ad basics.AccountData) basics.AccountData {
+	ad.MicroAlgos = basics.MicroAlgos{}
+	ad.RewardsBase = 0
+	ad.RewardedMicroAlgos = basics.MicroAlgos{}
+	ad.AssetParams = nil
+	ad.Assets = nil
+	ad.AppLocalStates = nil
+	ad.AppParams = nil
+
+	return ad
+}
+
+func addressOrNil(addr basics.Address) []byte {
+	if addr.IsZero() {
+		return nil
+	}
+	return append([]byte{}, addr[:]...)
+}
+
+func encodeAccountData(ad basics.AccountData) []byte {
+	return protocol.Encode(&ad)
+}
+
+func decodeAccountData(data []byte) (basics.AccountData, error) {
+	var ad basics.AccountData
+	err := protocol.Decode(data, &ad)
+	if err != nil {
+		return basics.AccountData{}, fmt.Errorf("decodeAccountData() err: %w", err)
+	}
+	return ad, nil
+}
+
+func encodeBlockHeader(header bookkeeping.BlockHeader) []byte {
+	return protocol.Encode(&header)
+}
+
+func decodeBlockHeader(data []byte) (bookkeeping.BlockHeader, error) {
+	var header bookkeeping.BlockHeader
+	err := protocol.Decode(data, &header)
+	if err != nil {
+		return bookkeeping.BlockHeader{}, fmt.Errorf("decodeBlockHeader() err: %w", err)
+	}
+	return header, nil
+}
+
+func encodeSignedTxnWithAD(stxn transactions.SignedTxnWithAD) []byte {
+	return protocol.Encode(&stxn)
+}
+
+func decodeSignedTxnWithAD(data []byte) (transactions.SignedTxnWithAD, error) {
+	var stxn transactions.SignedTxnWithAD
+	err := protocol.Decode(data, &stxn)
+	if err != nil {
+		return transactions.SignedTxnWithAD{},
+			fmt.Errorf("decodeSignedTxnWithAD() err: %w", err)
+	}
+	return stxn, nil
+}
+
+func encodeAssetParams(params basics.AssetParams) []byte {
+	return protocol.Encode(&params)
+}
+
+// decodeAssetParams returns empty params for deleted assets.
+func decodeAssetParams(data []byte) (basics.AssetParams, error) {
+	var params basics.AssetParams
+	if data == nil {
+		return params, nil
+	}
+	err := protocol.Decode(data, &params)
+	if err != nil {
+		return basics.AssetParams{}, fmt.Errorf("decodeAssetParams() err: %w", err)
+	}
+	return params, nil
+}
+
+func encodeAppParams(params basics.AppParams) []byte {
+	return protocol.Encode(&params)
+}
+
+// decodeAppParams returns empty params for deleted apps.
+func decodeAppParams(data []byte) (basics.AppParams, error) {
+	var params basics.AppParams
+	if data == nil {
+		return params, nil
+	}
+	err := protocol.Decode(data, &params)
+	if err != nil {
+		return basics.AppParams{}, fmt.Errorf("decodeAppParams() err: %w", err)
+	}
+	return params, nil
+}
+
+func encodeAppLocalState(state basics.AppLocalState) []byte {
+	return protocol.Encode(&state)
+}
+
+// decodeAppLocalState returns an empty state for deleted local states.
+func decodeAppLocalState(data []byte) (basics.AppLocalState, error) {
+	var state basics.AppLocalState
+	if data == nil {
+		return state, nil
+	}
+	err := protocol.Decode(data, &state)
+	if err != nil {
+		return basics.AppLocalState{}, fmt.Errorf("decodeAppLocalState() err: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,462 @@
+package kv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	snapshotFileName = "snapshot"
+	logFileName      = "log"
+
+	// maxRecordOps bounds the size of the records of a snapshot file.
+	maxRecordOps = 4096
+
+	// DefaultCompactSize is the default log size that triggers a compaction.
+	DefaultCompactSize = 256 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errShortRecord = errors.New("short record")
+
+// StoreOptions configure the built-in store.
+type StoreOptions struct {
+	// ReadOnly opens the store without modifying its files, so that it can be
+	// read while another process writes to it. Write() returns an error.
+	ReadOnly bool
+
+	// CompactSize is the log size that triggers a compaction. Defaults to
+	// DefaultCompactSize.
+	CompactSize int64
+}
+
+// fileStore is the built-in Store. The data set is held in memory in an
+// immutable treap, so snapshots are free. Committed batches are appended to a
+// log file and synced. Once the log grows past CompactSize, the whole tree is
+// written to the snapshot file and the log is truncated.
+//
+// A record of both files is the length and the CRC-32C of the payload followed
+// by the payload, a sequence of operations.
+type fileStore struct {
+	dir  string
+	opts StoreOptions
+
+	mu      sync.Mutex
+	root    *node
+	log     *os.File
+	logSize int64
+	closed  bool
+}
+
+// OpenStore opens or creates the built-in store in directory `dir`. The store
+// keeps all data in memory and is meant for single node deployments, private
+// networks and tests. Only one process may open a directory for writing.
+func OpenStore(dir string, opts StoreOptions) (Store, error) {
+	return openFileStore(dir, opts)
+}
+
+func openFileStore(dir string, opts StoreOptions) (*fileStore, error) {
+	if opts.CompactSize == 0 {
+		opts.CompactSize = DefaultCompactSize
+	}
+	s := &fileStore{dir: dir, opts: opts}
+
+	if opts.ReadOnly {
+		err := s.loadReadOnly()
+		if err != nil {
+			return nil, fmt.Errorf("openFileStore() err: %w", err)
+		}
+		return s, nil
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("openFileStore() err: %w", err)
+	}
+	_, err = s.loadSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("openFileStore() err: %w", err)
+	}
+	err = s.replayLog()
+	if err != nil {
+		return nil, fmt.Errorf("openFileStore() err: %w", err)
+	}
+	return s, nil
+}
+
+// loadSnapshot applies the snapshot file and returns its file info, nil if
+// there is no snapshot.
+func (s *fileStore) loadSnapshot() (os.FileInfo, error) {
+	f, err := os.Open(filepath.Join(s.dir, snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loadSnapshot() err: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("loadSnapshot() err: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		ops, _, err := readRecord(r)
+		if err == io.EOF {
+			return info, nil
+		}
+		if err != nil {
+			// The snapshot is renamed into place once complete, it is never
+			// partially written.
+			return nil, fmt.Errorf("loadSnapshot() err: %w", err)
+		}
+		s.apply(ops)
+	}
+}
+
+// readLog applies the records of the log file `f` and returns the size of the
+// complete records.
+func (s *fileStore) readLog(f *os.File) int64 {
+	r := bufio.NewReader(f)
+	size := int64(0)
+	for {
+		// Stop at the end of the log or at the first torn record.
+		ops, n, err := readRecord(r)
+		if err != nil {
+			return size
+		}
+		s.apply(ops)
+		size += n
+	}
+}
+
+// replayLog applies the records of the log. A torn write at the end of the
+// log is a batch that was not committed, it is truncated.
+func (s *fileStore) replayLog() error {
+	f, err := os.OpenFile(filepath.Join(s.dir, logFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("replayLog() err: %w", err)
+	}
+
+	size := s.readLog(f)
+	err = f.Truncate(size)
+	if err == nil {
+		_, err = f.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("replayLog() err: %w", err)
+	}
+	s.log = f
+	s.logSize = size
+	return nil
+}
+
+// loadReadOnly reads the snapshot and the log without modifying them. The
+// writer may compact the store in between, in which case the log no longer
+// follows the snapshot that was read and loading starts over.
+func (s *fileStore) loadReadOnly() error {
+	const maxAttempts = 10
+	for i := 0; i < maxAttempts; i++ {
+		s.root = nil
+		before, err := s.loadSnapshot()
+		if err != nil {
+			return fmt.Errorf("loadReadOnly() err: %w", err)
+		}
+		f, err := os.Open(filepath.Join(s.dir, logFileName))
+		if err == nil {
+			s.readLog(f)
+			f.Close()
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("loadReadOnly() err: %w", err)
+		}
+
+		after, err := os.Stat(filepath.Join(s.dir, snapshotFileName))
+		if os.IsNotExist(err) {
+			after, err = nil, nil
+		}
+		if err != nil {
+			return fmt.Errorf("loadReadOnly() err: %w", err)
+		}
+		if (before == nil && after == nil) ||
+			(before != nil && after != nil && os.SameFile(before, after)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("loadReadOnly() store in %s keeps changing", s.dir)
+}
+
+func (s *fileStore) apply(ops []op) {
+	root := s.root
+	for _, o := range ops {
+		switch o.typ {
+		case opSet:
+			root = root.insert(string(o.key), o.value)
+		case opDelete:
+			root = root.remove(string(o.key))
+		}
+	}
+	s.root = root
+}
+
+// Snapshot is part of the Store interface.
+func (s *fileStore) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return treapSnapshot{root: s.root}
+}
+
+// Write is part of the Store interface.
+func (s *fileStore) Write(b *Batch) error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+	record := encodeRecord(b.ops)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("Write() store is closed")
+	}
+	if s.opts.ReadOnly {
+		return fmt.Errorf("Write() store is read only")
+	}
+
+	_, err := s.log.Write(record)
+	if err == nil {
+		err = s.log.Sync()
+	}
+	if err != nil {
+		// Drop what may have been written, the batch is not committed.
+		s.log.Truncate(s.logSize)
+		s.log.Seek(s.logSize, io.SeekStart)
+		return fmt.Errorf("Write() err: %w", err)
+	}
+	s.logSize += int64(len(record))
+	s.apply(b.ops)
+
+	if s.logSize >= s.opts.CompactSize {
+		// The batch is committed, a failed compaction leaves the log in place
+		// and is retried by the next Write.
+		s.compact()
+	}
+	return nil
+}
+
+// compact writes the tree to the snapshot file and truncates the log. The log
+// is only truncated after the new snapshot is in place, a crash in between
+// replays batches that are already in the snapshot, which is harmless.
+func (s *fileStore) compact() error {
+	path := filepath.Join(s.dir, snapshotFileName)
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("compact() err: %w", err)
+	}
+	w := bufio.NewWriter(f)
+
+	ops := make([]op, 0, maxRecordOps)
+	s.root.each(func(n *node) {
+		if err != nil {
+			return
+		}
+		ops = append(ops, op{typ: opSet, key: []byte(n.key), value: n.value})
+		if len(ops) == maxRecordOps {
+			_, err = w.Write(encodeRecord(ops))
+			ops = ops[:0]
+		}
+	})
+	if err == nil && len(ops) > 0 {
+		_, err = w.Write(encodeRecord(ops))
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err == nil {
+		err = syncDir(s.dir)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("compact() err: %w", err)
+	}
+
+	err = s.log.Truncate(0)
+	if err == nil {
+		_, err = s.log.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		err = s.log.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("compact() err: %w", err)
+	}
+	s.logSize = 0
+	return nil
+}
+
+// Close is part of the Store interface. It compacts the store, so that the
+// next open doesn't need to replay the log.
+func (s *fileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.opts.ReadOnly {
+		return nil
+	}
+
+	var err error
+	if s.logSize > 0 {
+		err = s.compact()
+	}
+	closeErr := s.log.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("Close() err: %w", err)
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+type treapSnapshot struct {
+	root *node
+}
+
+// Get is part of the Reader interface.
+func (s treapSnapshot) Get(key []byte) ([]byte, bool) {
+	return s.root.get(string(key))
+}
+
+// Iterate is part of the Reader interface.
+func (s treapSnapshot) Iterate(start, end []byte, reverse bool, f func(key, value []byte) bool) {
+	if end != nil && len(end) == 0 {
+		return
+	}
+	visit := func(n *node) bool {
+		return f([]byte(n.key), n.value)
+	}
+	if reverse {
+		s.root.iterateReverse(string(start), string(end), visit)
+	} else {
+		s.root.iterate(string(start), string(end), visit)
+	}
+}
+
+// Release is part of the Snapshot interface. The tree is immutable, there is
+// nothing to release.
+func (s treapSnapshot) Release() {
+}
+
+func encodeRecord(ops []op) []byte {
+	payload := make([]byte, 0, 64*len(ops))
+	var buf [binary.MaxVarintLen64]byte
+	for _, o := range ops {
+		payload = append(payload, byte(o.typ))
+		n := binary.PutUvarint(buf[:], uint64(len(o.key)))
+		payload = append(payload, buf[:n]...)
+		payload = append(payload, o.key...)
+		if o.typ == opSet {
+			n = binary.PutUvarint(buf[:], uint64(len(o.value)))
+			payload = append(payload, buf[:n]...)
+			payload = append(payload, o.value...)
+		}
+	}
+
+	record := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(payload, crcTable))
+	return append(record, payload...)
+}
+
+// readRecord returns the operations of the next record and its size. Returns
+// io.EOF at the end of the input.
+func readRecord(r io.Reader) ([]op, int64, error) {
+	var header [8]byte
+	_, err := io.ReadFull(r, header[:])
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("readRecord() header err: %w", errShortRecord)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("readRecord() payload err: %w", errShortRecord)
+	}
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, fmt.Errorf("readRecord() checksum mismatch")
+	}
+
+	ops, err := decodeOps(payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("readRecord() err: %w", err)
+	}
+	return ops, int64(len(header) + len(payload)), nil
+}
+
+func decodeOps(payload []byte) ([]op, error) {
+	var ops []op
+	readBytes := func() ([]byte, error) {
+		l, n := binary.Uvarint(payload)
+		if n <= 0 || uint64(len(payload)-n) < l {
+			return nil, errShortRecord
+		}
+		b := payload[n : n+int(l)]
+		payload = payload[n+int(l):]
+		return b, nil
+	}
+	for len(payload) > 0 {
+		o := op{typ: opType(payload[0])}
+		payload = payload[1:]
+		var err error
+		o.key, err = readBytes()
+		if err != nil {
+			return nil, err
+		}
+		switch o.typ {
+		case opSet:
+			o.value, err = readBytes()
+			if err != nil {
+				return nil, err
+			}
+		case opDelete:
+		default:
+			return nil, fmt.Errorf("decodeOps() unknown operation %d", o.typ)
+		}
+		ops = append(ops, o)
+	}
+	return ops, nil
+}
@@ -0,0 +1,188 @@
+package kv
+
+import (
+	"encoding/binary"
+)
+
+// Every table is a key prefix. Keys are built from fixed width big endian
+// integers and 32 byte addresses, so that the key order matches the order of
+// the primary keys of the postgres tables.
+const (
+	// metastate: k -> v
+	metastatePrefix = 'm'
+	// block_header: round -> msgpack block header
+	blockHeaderPrefix = 'h'
+	// txn: (round, intra) -> txnRow
+	txnPrefix = 't'
+	// txn by txid: txid -> (round, intra)
+	txidPrefix = 'x'
+	// txn by txgroup: (group, round, intra) -> nil
+	txnByGroupPrefix = 'g'
+	// txn by lease: (lease, round, intra) -> nil
+	txnByLeasePrefix = 'e'
+	// txn_participation: (addr, round DESC, intra DESC) -> roles
+	txnParticipationPrefix = 'p'
+	// account: addr -> accountRow
+	accountPrefix = 'a'
+	// account by auth_addr: (auth_addr, addr) -> nil
+	accountByAuthAddrPrefix = 'r'
+	// asset: id -> creatableRow
+	assetPrefix = 's'
+	// asset by creator_addr: (creator_addr, id) -> nil
+	assetByCreatorPrefix = 'c'
+	// account_asset: (addr, assetid) -> holdingRow
+	accountAssetPrefix = 'b'
+	// account_asset by asset: (assetid, addr) -> nil
+	accountAssetByAssetPrefix = 'B'
+	// app: id -> creatableRow
+	appPrefix = 'l'
+	// app by creator: (creator, id) -> nil
+	appByCreatorPrefix = 'L'
+	// account_app: (addr, app) -> localStateRow
+	accountAppPrefix = 'o'
+	// account_app by app: (app, addr) -> nil
+	accountAppByAppPrefix = 'O'
+	// undo records: round -> undoRow
+	undoPrefix = 'u'
+)
+
+const addressLen = 32
+
+func makeKey(prefix byte, size int) []byte {
+	key := make([]byte, 1, 1+size)
+	key[0] = prefix
+	return key
+}
+
+func appendUint64(key []byte, x uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], x)
+	return append(key, buf[:]...)
+}
+
+func appendUint32(key []byte, x uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], x)
+	return append(key, buf[:]...)
+}
+
+func metastateKey(k string) []byte {
+	return append(makeKey(metastatePrefix, len(k)), k...)
+}
+
+func blockHeaderKey(round uint64) []byte {
+	return appendUint64(makeKey(blockHeaderPrefix, 8), round)
+}
+
+func undoKey(round uint64) []byte {
+	return appendUint64(makeKey(undoPrefix, 8), round)
+}
+
+func txnKey(round uint64, intra uint32) []byte {
+	return appendUint32(appendUint64(makeKey(txnPrefix, 12), round), intra)
+}
+
+// parseTxnKey is the inverse of txnKey().
+func parseTxnKey(key []byte) (uint64, uint32) {
+	return binary.BigEndian.Uint64(key[1:9]), binary.BigEndian.Uint32(key[9:13])
+}
+
+func txidKey(txid string) []byte {
+	return append(makeKey(txidPrefix, len(txid)), txid...)
+}
+
+// digestTxnKey indexes a transaction by a 32 byte group id or lease.
+func digestTxnKey(prefix byte, digest []byte, round uint64, intra uint32) []byte {
+	return appendUint32(appendUint64(addressKey(prefix, digest), round), intra)
+}
+
+// parseDigestTxnKey is the inverse of digestTxnKey().
+func parseDigestTxnKey(key []byte) (uint64, uint32) {
+	rest := key[1+addressLen:]
+	return binary.BigEndian.Uint64(rest[0:8]), binary.BigEndian.Uint32(rest[8:12])
+}
+
+// txnParticipationKey stores the complement of the round and intra, so that
+// ascending iteration returns the transactions of an address newest first.
+func txnParticipationKey(addr []byte, round uint64, intra uint32) []byte {
+	key := append(makeKey(txnParticipationPrefix, addressLen+12), addr...)
+	return appendUint32(appendUint64(key, ^round), ^intra)
+}
+
+// parseTxnParticipationKey is the inverse of txnParticipationKey().
+func parseTxnParticipationKey(key []byte) (uint64, uint32) {
+	rest := key[1+addressLen:]
+	return ^binary.BigEndian.Uint64(rest[0:8]), ^binary.BigEndian.Uint32(rest[8:12])
+}
+
+func addressKey(prefix byte, addr []byte) []byte {
+	return append(makeKey(prefix, addressLen), addr...)
+}
+
+func addressIDKey(prefix byte, addr []byte, id uint64) []byte {
+	return appendUint64(append(makeKey(prefix, addressLen+8), addr...), id)
+}
+
+func idKey(prefix byte, id uint64) []byte {
+	return appendUint64(makeKey(prefix, 8), id)
+}
+
+func idAddressKey(prefix byte, id uint64, addr []byte) []byte {
+	return append(appendUint64(makeKey(prefix, 8+addressLen), id), addr...)
+}
+
+func accountKey(addr []byte) []byte {
+	return addressKey(accountPrefix, addr)
+}
+
+func accountByAuthAddrKey(authAddr, addr []byte) []byte {
+	return append(addressKey(accountByAuthAddrPrefix, authAddr), addr...)
+}
+
+func assetKey(id uint64) []byte {
+	return idKey(assetPrefix, id)
+}
+
+func assetByCreatorKey(creator []byte, id uint64) []byte {
+	return addressIDKey(assetByCreatorPrefix, creator, id)
+}
+
+func accountAssetKey(addr []byte, assetid uint64) []byte {
+	return addressIDKey(accountAssetPrefix, addr, assetid)
+}
+
+func accountAssetByAssetKey(assetid uint64, addr []byte) []byte {
+	return idAddressKey(accountAssetByAssetPrefix, assetid, addr)
+}
+
+func appKey(id uint64) []byte {
+	return idKey(appPrefix, id)
+}
+
+func appByCreatorKey(creator []byte, id uint64) []byte {
+	return addressIDKey(appByCreatorPrefix, creator, id)
+}
+
+func accountAppKey(addr []byte, app uint64) []byte {
+	return addressIDKey(accountAppPrefix, addr, app)
+}
+
+func accountAppByAppKey(app uint64, addr []byte) []byte {
+	return idAddressKey(accountAppByAppPrefix, app, addr)
+}
+
+// keyID returns the id at the end of a key built by addressIDKey() or
+// idKey().
+func keyID(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key[len(key)-8:])
+}
+
+// keyAddress returns the address at the end of a key.
+func keyAddress(key []byte) []byte {
+	return key[len(key)-addressLen:]
+}
+
+// after returns the smallest key greater than `key`.
+func after(key []byte) []byte {
+	return append(append([]byte{}, key...), 0)
+}
@@ -0,0 +1,516 @@
+// Package kv implements idb.IndexerDb on top of an ordered key-value store.
+// It is meant for single node deployments that don't want to run a postgres
+// server.
+//
+// Every table of the postgres schema is a key prefix, see keys.go. Queries
+// iterate the keys in the order of the postgres primary keys and indexes, and
+// the remaining filters are applied in Go. Any store implementing the Store
+// interface can be used with Open(); OpenKV() uses the built-in store.
+package kv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/algorand/go-algorand/protocol"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/accounting"
+	"github.com/algorand/indexer/idb"
+)
+
+// Names of the keys for the metastate table.
+const (
+	stateMetastateKey           = "state"
+	specialAccountsMetastateKey = "accounts"
+	accountTotalsMetastateKey   = "totals"
+	// The oldest round with transactions, not set unless transactions were
+	// pruned.
+	oldestRoundMetastateKey = "oldest_round"
+	// The checkpoint of an exporter is stored under the prefix and its name.
+	exportCheckpointMetastateKeyPrefix = "export/"
+	// A webhook is stored under the prefix and its id.
+	webhookMetastateKeyPrefix = "webhook/"
+)
+
+// OpenKV opens or creates the built-in store in directory `dir`. Returns an
+// error object and a channel that gets closed when the database becomes
+// available.
+func OpenKV(dir string, opts idb.IndexerDbOptions, log *log.Logger) (*IndexerDb, chan struct{}, error) {
+	store, err := OpenStore(dir, StoreOptions{ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return nil, nil, fmt.Errorf("OpenKV() err: %w", err)
+	}
+	return Open(store, opts, log)
+}
+
+// Open returns an IndexerDb using `store`. The IndexerDb takes ownership of
+// the store and closes it in Close().
+func Open(store Store, opts idb.IndexerDbOptions, logger *log.Logger) (*IndexerDb, chan struct{}, error) {
+	idb := &IndexerDb{
+		readonly:          opts.ReadOnly,
+		log:               logger,
+		store:             store,
+		stateDeltaHandler: opts.StateDeltaHandler,
+		maxRollbackRounds: opts.MaxRollbackRounds,
+	}
+
+	if idb.log == nil {
+		idb.log = log.New()
+		idb.log.SetFormatter(&log.JSONFormatter{})
+		idb.log.SetOutput(os.Stdout)
+		idb.log.SetLevel(log.TraceLevel)
+	}
+
+	// There are no migrations yet, the database is available right away.
+	ch := make(chan struct{})
+	close(ch)
+	return idb, ch, nil
+}
+
+// IndexerDb is an idb.IndexerDB implementation
+type IndexerDb struct {
+	readonly bool
+	log      *log.Logger
+
+	store          Store
+	accountingLock sync.Mutex
+
+	// stateDeltaHandler is optional, see idb.IndexerDbOptions.
+	stateDeltaHandler idb.StateDeltaHandler
+	// maxRollbackRounds is the number of rounds with undo records.
+	maxRollbackRounds uint64
+}
+
+// Close is part of idb.IndexerDb.
+func (db *IndexerDb) Close() {
+	err := db.store.Close()
+	if err != nil {
+		db.log.WithError(err).Error("failed to close the store")
+	}
+}
+
+// getRow decodes the row stored at `key` into `row`. Returns false if there is
+// no such row.
+func getRow(r Reader, key []byte, row interface{}) (bool, error) {
+	value, ok := r.Get(key)
+	if !ok {
+		return false, nil
+	}
+	err := decodeRow(value, row)
+	if err != nil {
+		return false, fmt.Errorf("getRow() key %x err: %w", key, err)
+	}
+	return true, nil
+}
+
+// Returns `idb.ErrorNotInitialized` if uninitialized.
+func getMetastate(r Reader, key string, objptr interface{}) error {
+	value, ok := r.Get(metastateKey(key))
+	if !ok {
+		return idb.ErrorNotInitialized
+	}
+
+	err := protocol.DecodeReflect(value, objptr)
+	if err != nil {
+		return fmt.Errorf("getMetastate() decode %s err: %w", key, err)
+	}
+
+	return nil
+}
+
+func setMetastate(o *overlay, key string, obj interface{}) {
+	o.set(metastateKey(key), protocol.EncodeReflect(obj))
+}
+
+// Returns idb.ErrorNotInitialized if uninitialized.
+func getImportState(r Reader) (importState, error) {
+	var state importState
+	err := getMetastate(r, stateMetastateKey, &state)
+	if err == idb.ErrorNotInitialized {
+		return importState{}, idb.ErrorNotInitialized
+	}
+	if err != nil {
+		return importState{}, fmt.Errorf("unable to get import state err: %w", err)
+	}
+
+	return state, nil
+}
+
+// Returns ErrorNotInitialized if genesis is not loaded.
+func getNextRoundToAccount(r Reader) (uint64, error) {
+	state, err := getImportState(r)
+	if err == idb.ErrorNotInitialized {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getNextRoundToAccount() err: %w", err)
+	}
+
+	return state.NextRoundToAccount, nil
+}
+
+// GetNextRoundToAccount is part of idb.IndexerDB
+// Returns ErrorNotInitialized if genesis is not loaded.
+func (db *IndexerDb) GetNextRoundToAccount() (uint64, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	return getNextRoundToAccount(snap)
+}
+
+// Returns ErrorNotInitialized if genesis is not loaded.
+func getMaxRoundAccounted(r Reader) (uint64, error) {
+	round, err := getNextRoundToAccount(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if round > 0 {
+		round--
+	}
+	return round, nil
+}
+
+func getBlockHeader(r Reader, round uint64) (bookkeeping.BlockHeader, bool, error) {
+	value, ok := r.Get(blockHeaderKey(round))
+	if !ok {
+		return bookkeeping.BlockHeader{}, false, nil
+	}
+	header, err := decodeBlockHeader(value)
+	if err != nil {
+		return bookkeeping.BlockHeader{}, false, err
+	}
+	return header, true, nil
+}
+
+// Returns all addresses referenced in `block`.
+func getBlockAddresses(block *bookkeeping.Block) map[basics.Address]struct{} {
+	// Reserve a reasonable memory size for the map.
+	res := make(map[basics.Address]struct{}, len(block.Payset)+2)
+
+	res[block.FeeSink] = struct{}{}
+	res[block.RewardsPool] = struct{}{}
+	for _, stib := range block.Payset {
+		addFunc := func(address basics.Address) {
+			res[address] = struct{}{}
+		}
+		accounting.GetTransactionParticipants(&stib.SignedTxnWithAD, true, addFunc)
+	}
+
+	return res
+}
+
+func prepareEvalResources(l *ledgerForEvaluator, block *bookkeeping.Block) (ledger.EvalForIndexerResources, error) {
+	addresses := getBlockAddresses(block)
+	assets := make(map[basics.AssetIndex]struct{})
+	apps := make(map[basics.AppIndex]struct{})
+
+	for _, stib := range block.Payset {
+		switch stib.Txn.Type {
+		case protocol.AssetConfigTx:
+			if stib.Txn.ConfigAsset != 0 {
+				assets[stib.Txn.ConfigAsset] = struct{}{}
+			}
+		case protocol.AssetTransferTx:
+			if stib.Txn.XferAsset != 0 {
+				assets[stib.Txn.XferAsset] = struct{}{}
+			}
+		case protocol.AssetFreezeTx:
+			if stib.Txn.FreezeAsset != 0 {
+				assets[stib.Txn.FreezeAsset] = struct{}{}
+			}
+		case protocol.ApplicationCallTx:
+			if stib.Txn.ApplicationID != 0 {
+				apps[stib.Txn.ApplicationID] = struct{}{}
+			}
+		}
+	}
+
+	res := ledger.EvalForIndexerResources{
+		Accounts: nil,
+		Creators: make(map[ledger.Creatable]ledger.FoundAddress),
+	}
+
+	assetCreators, err := l.GetAssetCreator(assets)
+	if err != nil {
+		return ledger.EvalForIndexerResources{},
+			fmt.Errorf("prepareEvalResources() err: %w", err)
+	}
+	for index, foundAddress := range assetCreators {
+		creatable := ledger.Creatable{
+			Index: basics.CreatableIndex(index),
+			Type:  basics.AssetCreatable,
+		}
+		res.Creators[creatable] = foundAddress
+
+		if foundAddress.Exists {
+			addresses[foundAddress.Address] = struct{}{}
+		}
+	}
+
+	appCreators, err := l.GetAppCreator(apps)
+	if err != nil {
+		return ledger.EvalForIndexerResources{},
+			fmt.Errorf("prepareEvalResources() err: %w", err)
+	}
+	for index, foundAddress := range appCreators {
+		creatable := ledger.Creatable{
+			Index: basics.CreatableIndex(index),
+			Type:  basics.AppCreatable,
+		}
+		res.Creators[creatable] = foundAddress
+
+		if foundAddress.Exists {
+			addresses[foundAddress.Address] = struct{}{}
+		}
+	}
+
+	res.Accounts, err = l.LookupWithoutRewards(addresses)
+	if err != nil {
+		return ledger.EvalForIndexerResources{},
+			fmt.Errorf("prepareEvalResources() err: %w", err)
+	}
+
+	return res, nil
+}
+
+// AddBlock is part of idb.IndexerDb.
+func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
+	db.log.Printf("adding block %d", block.Round())
+
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	// Check and increment next round counter.
+	importstate, err := getImportState(o)
+	if err != nil {
+		return fmt.Errorf("AddBlock() err: %w", err)
+	}
+	if block.Round() != basics.Round(importstate.NextRoundToAccount) {
+		return fmt.Errorf(
+			"AddBlock() adding block round %d but next round to account is %d",
+			block.Round(), importstate.NextRoundToAccount)
+	}
+	importstate.NextRoundToAccount++
+	setMetastate(o, stateMetastateKey, &importstate)
+
+	w := writer{o: o}
+
+	var delta ledgercore.StateDelta
+	if block.Round() == basics.Round(0) {
+		// Block 0 is special, we cannot run the evaluator on it.
+		w.addBlock0(block)
+	} else {
+		proto, ok := config.Consensus[block.BlockHeader.CurrentProtocol]
+		if !ok {
+			return fmt.Errorf(
+				"AddBlock() cannot find proto version %s", block.BlockHeader.CurrentProtocol)
+		}
+		proto.EnableAssetCloseAmount = true
+
+		ledgerForEval := makeLedgerForEvaluator(o, block.Round()-1)
+
+		resources, err := prepareEvalResources(&ledgerForEval, block)
+		if err != nil {
+			return fmt.Errorf("AddBlock() eval err: %w", err)
+		}
+
+		var modifiedTxns []transactions.SignedTxnInBlock
+		delta, modifiedTxns, err =
+			ledger.EvalForIndexer(ledgerForEval, block, proto, resources)
+		if err != nil {
+			return fmt.Errorf("AddBlock() eval err: %w", err)
+		}
+
+		err = w.addBlock(block, modifiedTxns, delta)
+		if err != nil {
+			return fmt.Errorf("AddBlock() err: %w", err)
+		}
+	}
+
+	// Block 0 has no state delta, the genesis allocation is reported by
+	// LoadGenesis(). The round is not committed if the handler fails.
+	if db.stateDeltaHandler != nil && block.Round() != basics.Round(0) {
+		err = db.stateDeltaHandler(block.Round(), &delta)
+		if err != nil {
+			return fmt.Errorf("AddBlock() state delta handler err: %w", err)
+		}
+	}
+
+	// Block 0 is not undone, there is nothing before it.
+	if block.Round() != basics.Round(0) {
+		addUndo(o, uint64(block.Round()), db.maxRollbackRounds)
+	}
+
+	err = db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("AddBlock() commit err: %w", err)
+	}
+
+	return nil
+}
+
+// LoadGenesis is part of idb.IndexerDB
+func (db *IndexerDb) LoadGenesis(genesis bookkeeping.Genesis) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	proto, ok := config.Consensus[genesis.Proto]
+	if !ok {
+		return fmt.Errorf("LoadGenesis() consensus version %s not found", genesis.Proto)
+	}
+	var delta ledgercore.StateDelta
+	var ot basics.OverflowTracker
+	var totals ledgercore.AccountTotals
+	for ai, alloc := range genesis.Allocation {
+		addr, err := basics.UnmarshalChecksumAddress(alloc.Address)
+		if err != nil {
+			return fmt.Errorf("LoadGenesis() decode address err: %w", err)
+		}
+		if len(alloc.State.AssetParams) > 0 || len(alloc.State.Assets) > 0 {
+			return fmt.Errorf("LoadGenesis() genesis account[%d] has unhandled asset", ai)
+		}
+		row := accountRow{
+			MicroAlgos:  alloc.State.MicroAlgos.Raw,
+			AuthAddr:    addressOrNil(alloc.State.AuthAddr),
+			AccountData: encodeAccountData(trimAccountData(alloc.State)),
+		}
+		o.set(accountKey(addr[:]), encodeRow(&row))
+		if row.AuthAddr != nil {
+			o.set(accountByAuthAddrKey(row.AuthAddr, addr[:]), nil)
+		}
+
+		totals.AddAccount(proto, alloc.State, &ot)
+		delta.Accts.Upsert(addr, alloc.State)
+	}
+	delta.Totals = totals
+
+	setMetastate(o, accountTotalsMetastateKey, &totals)
+	setMetastate(o, stateMetastateKey, &importState{NextRoundToAccount: 0})
+
+	if db.stateDeltaHandler != nil {
+		err := db.stateDeltaHandler(basics.Round(0), &delta)
+		if err != nil {
+			return fmt.Errorf("LoadGenesis() state delta handler err: %w", err)
+		}
+	}
+
+	err := db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("LoadGenesis() commit err: %w", err)
+	}
+
+	return nil
+}
+
+// GetRewardsLevel is part of idb.IndexerDB
+func (db *IndexerDb) GetRewardsLevel(ctx context.Context, round uint64) (uint64, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	header, ok, err := getBlockHeader(snap, round)
+	if err != nil {
+		return 0, fmt.Errorf("GetRewardsLevel() err: %w", err)
+	}
+	if !ok {
+		return 0, idb.ErrorBlockNotFound
+	}
+	return header.RewardsLevel, nil
+}
+
+// GetBlock is part of idb.IndexerDB
+func (db *IndexerDb) GetBlock(ctx context.Context, round uint64, options idb.GetBlockOptions) (blockHeader bookkeeping.BlockHeader, transactions []idb.TxnRow, err error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	blockHeader, ok, err := getBlockHeader(snap, round)
+	if err != nil {
+		return bookkeeping.BlockHeader{}, nil, err
+	}
+	if !ok {
+		return bookkeeping.BlockHeader{}, nil, idb.ErrorBlockNotFound
+	}
+
+	if options.Transactions {
+		out := make(chan idb.TxnRow, 1)
+		go func() {
+			db.yieldTxns(ctx, snap, idb.TransactionFilter{Round: &round}, out)
+			close(out)
+		}()
+
+		results := make([]idb.TxnRow, 0)
+		for txrow := range out {
+			if txrow.Error != nil {
+				err = txrow.Error
+			}
+			results = append(results, txrow)
+		}
+		if err != nil {
+			return bookkeeping.BlockHeader{}, nil, err
+		}
+		transactions = results
+	}
+
+	return blockHeader, transactions, nil
+}
+
+// Health is part of idb.IndexerDB
+func (db *IndexerDb) Health() (idb.Health, error) {
+	var data = make(map[string]interface{})
+
+	if db.readonly {
+		data["read-only-mode"] = true
+	}
+	data["migration-required"] = false
+
+	round, err := db.getMaxRoundAccounted()
+
+	// We'll just have to set the round to 0
+	if err == idb.ErrorNotInitialized {
+		err = nil
+		round = 0
+	}
+
+	return idb.Health{
+		Data:        &data,
+		Round:       round,
+		IsMigrating: false,
+		DBAvailable: true,
+	}, err
+}
+
+func (db *IndexerDb) getMaxRoundAccounted() (uint64, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	return getMaxRoundAccounted(snap)
+}
+
+// GetSpecialAccounts is part of idb.IndexerDB
+func (db *IndexerDb) GetSpecialAccounts() (transactions.SpecialAddresses, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	var accounts transactions.SpecialAddresses
+	err := getMetastate(snap, specialAccountsMetastateKey, &accounts)
+	if err != nil {
+		return transactions.SpecialAddresses{}, fmt.Errorf("GetSpecialAccounts() err: %w", err)
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,24 @@
+package kv
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/idb"
+)
+
+type kvFactory struct {
+}
+
+// Name is part of the IndexerFactory interface.
+func (df kvFactory) Name() string {
+	return "kv"
+}
+
+// Build is part of the IndexerFactory interface.
+func (df kvFactory) Build(arg string, opts idb.IndexerDbOptions, log *log.Logger) (idb.IndexerDb, chan struct{}, error) {
+	return OpenKV(arg, opts, log)
+}
+
+func init() {
+	idb.RegisterFactory("kv", &kvFactory{})
+}
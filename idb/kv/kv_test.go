@@ -0,0 +1,60 @@
+package kv
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/conformance"
+	"github.com/algorand/indexer/util/test"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, kvFactory{}, func(t *testing.T) (string, func()) {
+		dir, err := ioutil.TempDir("", "indexer-kv")
+		require.NoError(t, err)
+		return dir, func() { os.RemoveAll(dir) }
+	})
+}
+
+func TestReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "indexer-kv")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, _, err := OpenKV(dir, idb.IndexerDbOptions{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.LoadGenesis(test.MakeGenesis()))
+	block := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&block))
+	pay := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	block2, err := test.MakeBlockForTxns(block.BlockHeader, &pay)
+	require.NoError(t, err)
+	require.NoError(t, db.AddBlock(&block2))
+	db.Close()
+
+	// A read only open sees the committed rounds and rejects writes.
+	db, _, err = OpenKV(dir, idb.IndexerDbOptions{ReadOnly: true}, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	next, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), next)
+	rowsCh, _ := db.Transactions(context.Background(), idb.TransactionFilter{})
+	var rows []idb.TxnRow
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		rows = append(rows, row)
+	}
+	require.Len(t, rows, 1)
+	assert.Equal(t, pay.Txn, rows[0].Txn.Txn)
+	assert.Error(t, db.LoadGenesis(test.MakeGenesis()))
+}
@@ -0,0 +1,262 @@
+package kv
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+)
+
+// ledgerForEvaluator implements the indexerLedgerForEval interface from
+// go-algorand ledger/eval.go and is used for accounting.
+type ledgerForEvaluator struct {
+	r           Reader
+	latestRound basics.Round
+}
+
+func makeLedgerForEvaluator(r Reader, latestRound basics.Round) ledgerForEvaluator {
+	return ledgerForEvaluator{
+		r:           r,
+		latestRound: latestRound,
+	}
+}
+
+// LatestBlockHdr is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) LatestBlockHdr() (bookkeeping.BlockHeader, error) {
+	res, ok, err := getBlockHeader(l.r, uint64(l.latestRound))
+	if err != nil {
+		return bookkeeping.BlockHeader{}, fmt.Errorf("BlockHdr() err: %w", err)
+	}
+	if !ok {
+		return bookkeeping.BlockHeader{},
+			fmt.Errorf("BlockHdr() block header %d not found", l.latestRound)
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) loadAccount(address basics.Address) (*basics.AccountData, error) {
+	var row accountRow
+	ok, err := getRow(l.r, accountKey(address[:]), &row)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	if !ok || row.Deleted {
+		return nil, nil
+	}
+
+	res, err := decodeAccountData(row.AccountData)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.MicroAlgos = basics.MicroAlgos{Raw: row.MicroAlgos}
+	res.RewardsBase = row.RewardsBase
+	res.RewardedMicroAlgos = basics.MicroAlgos{Raw: row.RewardsTotal}
+
+	res.Assets, err = l.loadAssetHoldings(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.AssetParams, err = l.loadAssetParams(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.AppParams, err = l.loadAppParams(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.AppLocalStates, err = l.loadAppLocalStates(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (l ledgerForEvaluator) loadAssetHoldings(address basics.Address) (map[basics.AssetIndex]basics.AssetHolding, error) {
+	var res map[basics.AssetIndex]basics.AssetHolding
+	var err error
+	iteratePrefix(l.r, addressKey(accountAssetPrefix, address[:]), false, func(key, value []byte) bool {
+		var row holdingRow
+		err = decodeRow(value, &row)
+		if err != nil {
+			err = fmt.Errorf("loadAssetHoldings() err: %w", err)
+			return false
+		}
+		if row.Deleted {
+			return true
+		}
+
+		if res == nil {
+			res = make(map[basics.AssetIndex]basics.AssetHolding)
+		}
+		res[basics.AssetIndex(keyID(key))] = basics.AssetHolding{
+			Amount: row.Amount,
+			Frozen: row.Frozen,
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// loadCreatables calls `f` for the assets or apps created by `address` that
+// are not deleted.
+func (l ledgerForEvaluator) loadCreatables(byCreatorPrefix, prefix byte, address basics.Address, f func(id uint64, params []byte) error) error {
+	var err error
+	iteratePrefix(l.r, addressKey(byCreatorPrefix, address[:]), false, func(key, value []byte) bool {
+		id := keyID(key)
+		var row creatableRow
+		var ok bool
+		ok, err = getRow(l.r, idKey(prefix, id), &row)
+		if err != nil {
+			return false
+		}
+		if !ok || row.Deleted {
+			return true
+		}
+		err = f(id, row.Params)
+		return err == nil
+	})
+	return err
+}
+
+func (l ledgerForEvaluator) loadAssetParams(address basics.Address) (map[basics.AssetIndex]basics.AssetParams, error) {
+	var res map[basics.AssetIndex]basics.AssetParams
+	err := l.loadCreatables(assetByCreatorPrefix, assetPrefix, address, func(id uint64, params []byte) error {
+		if res == nil {
+			res = make(map[basics.AssetIndex]basics.AssetParams)
+		}
+		var err error
+		res[basics.AssetIndex(id)], err = decodeAssetParams(params)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loadAssetParams() err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) loadAppParams(address basics.Address) (map[basics.AppIndex]basics.AppParams, error) {
+	var res map[basics.AppIndex]basics.AppParams
+	err := l.loadCreatables(appByCreatorPrefix, appPrefix, address, func(id uint64, params []byte) error {
+		if res == nil {
+			res = make(map[basics.AppIndex]basics.AppParams)
+		}
+		var err error
+		res[basics.AppIndex(id)], err = decodeAppParams(params)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loadAppParams() err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) loadAppLocalStates(address basics.Address) (map[basics.AppIndex]basics.AppLocalState, error) {
+	var res map[basics.AppIndex]basics.AppLocalState
+	var err error
+	iteratePrefix(l.r, addressKey(accountAppPrefix, address[:]), false, func(key, value []byte) bool {
+		var row localStateRow
+		err = decodeRow(value, &row)
+		if err != nil {
+			err = fmt.Errorf("loadAppLocalStates() err: %w", err)
+			return false
+		}
+		if row.Deleted {
+			return true
+		}
+
+		if res == nil {
+			res = make(map[basics.AppIndex]basics.AppLocalState)
+		}
+		res[basics.AppIndex(keyID(key))], err = decodeAppLocalState(row.LocalState)
+		if err != nil {
+			err = fmt.Errorf("loadAppLocalStates() err: %w", err)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LookupWithoutRewards is part of go-algorand's indexerLedgerForEval interface.
+// nil is stored for those accounts that were not found.
+func (l ledgerForEvaluator) LookupWithoutRewards(addresses map[basics.Address]struct{}) (map[basics.Address]*basics.AccountData, error) {
+	res := make(map[basics.Address]*basics.AccountData, len(addresses))
+	for address := range addresses {
+		accountData, err := l.loadAccount(address)
+		if err != nil {
+			return nil, fmt.Errorf("LookupWithoutRewards() err: %w", err)
+		}
+		res[address] = accountData
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) getCreator(key []byte) (ledger.FoundAddress, error) {
+	var row creatableRow
+	ok, err := getRow(l.r, key, &row)
+	if err != nil {
+		return ledger.FoundAddress{}, fmt.Errorf("getCreator() err: %w", err)
+	}
+	if !ok || row.Deleted {
+		return ledger.FoundAddress{}, nil
+	}
+
+	var address basics.Address
+	copy(address[:], row.Creator)
+
+	return ledger.FoundAddress{Address: address, Exists: true}, nil
+}
+
+// GetAssetCreator is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) GetAssetCreator(indices map[basics.AssetIndex]struct{}) (map[basics.AssetIndex]ledger.FoundAddress, error) {
+	res := make(map[basics.AssetIndex]ledger.FoundAddress, len(indices))
+	for index := range indices {
+		foundAddress, err := l.getCreator(assetKey(uint64(index)))
+		if err != nil {
+			return nil, fmt.Errorf("GetAssetCreator() err: %w", err)
+		}
+		res[index] = foundAddress
+	}
+
+	return res, nil
+}
+
+// GetAppCreator is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) GetAppCreator(indices map[basics.AppIndex]struct{}) (map[basics.AppIndex]ledger.FoundAddress, error) {
+	res := make(map[basics.AppIndex]ledger.FoundAddress, len(indices))
+	for index := range indices {
+		foundAddress, err := l.getCreator(appKey(uint64(index)))
+		if err != nil {
+			return nil, fmt.Errorf("GetAppCreator() err: %w", err)
+		}
+		res[index] = foundAddress
+	}
+
+	return res, nil
+}
+
+// LatestTotals is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) LatestTotals() (ledgercore.AccountTotals, error) {
+	var totals ledgercore.AccountTotals
+	err := getMetastate(l.r, accountTotalsMetastateKey, &totals)
+	if err != nil {
+		return ledgercore.AccountTotals{}, fmt.Errorf("LatestTotals() err: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,99 @@
+package kv
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+
+	"github.com/algorand/indexer/accounting"
+	"github.com/algorand/indexer/idb"
+)
+
+// getOldestRound returns the oldest round with transactions, 0 if transactions
+// were never pruned.
+func getOldestRound(r Reader) (uint64, error) {
+	var round uint64
+	err := getMetastate(r, oldestRoundMetastateKey, &round)
+	if err == idb.ErrorNotInitialized {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getOldestRound() err: %w", err)
+	}
+	return round, nil
+}
+
+// GetOldestRound is part of idb.IndexerDb.
+func (db *IndexerDb) GetOldestRound() (uint64, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	return getOldestRound(snap)
+}
+
+// deleteTxn deletes the txn row stored at `key` with its index keys. The
+// participation keys are recomputed from the transaction the way
+// addTransactionParticipation() writes them: a root transaction participates
+// through its inner transactions, an inner transaction only directly.
+func deleteTxn(o *overlay, key []byte, value []byte) error {
+	round, intra := parseTxnKey(key)
+	var row txnRow
+	err := decodeRow(value, &row)
+	if err != nil {
+		return fmt.Errorf("deleteTxn() key %x err: %w", key, err)
+	}
+	stxnad, err := decodeSignedTxnWithAD(row.Txn)
+	if err != nil {
+		return fmt.Errorf("deleteTxn() key %x err: %w", key, err)
+	}
+
+	o.delete(key)
+	if row.Txid != "" {
+		o.delete(txidKey(row.Txid))
+	}
+	if !stxnad.Txn.Group.IsZero() {
+		o.delete(digestTxnKey(txnByGroupPrefix, stxnad.Txn.Group[:], round, intra))
+	}
+	if stxnad.Txn.Lease != ([32]byte{}) {
+		o.delete(digestTxnKey(txnByLeasePrefix, stxnad.Txn.Lease[:], round, intra))
+	}
+	accounting.GetTransactionParticipants(
+		&stxnad, !row.Extra.RootIntra.Present, func(address basics.Address) {
+			o.delete(txnParticipationKey(address[:], round, intra))
+		})
+	return nil
+}
+
+// PruneTransactions is part of idb.IndexerDb.
+func (db *IndexerDb) PruneTransactions(round uint64) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	oldest, err := getOldestRound(snap)
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() err: %w", err)
+	}
+	if round <= oldest {
+		return nil
+	}
+
+	snap.Iterate(txnKey(oldest, 0), txnKey(round, 0), false, func(key, value []byte) bool {
+		err = deleteTxn(o, key, value)
+		return err == nil
+	})
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() err: %w", err)
+	}
+	setMetastate(o, oldestRoundMetastateKey, &round)
+
+	err = db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() commit err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package kv
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// addUndo stores the previous values of the keys written to `o` as the undo
+// record of `round`, and removes the records of the rounds that are more than
+// `maxRounds` rounds old. With `maxRounds` 0 all records are removed.
+func addUndo(o *overlay, round uint64, maxRounds uint64) {
+	if maxRounds > 0 {
+		keys := make([]string, 0, len(o.pending))
+		for key := range o.pending {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		row := undoRow{Ops: make([]undoOp, 0, len(keys))}
+		for _, key := range keys {
+			value, ok := o.Reader.Get([]byte(key))
+			row.Ops = append(row.Ops, undoOp{Key: []byte(key), Value: value, Present: ok})
+		}
+		o.set(undoKey(round), encodeRow(&row))
+	}
+
+	// Iterate does not see the pending record of `round`.
+	end := []byte{undoPrefix + 1}
+	if maxRounds > 0 {
+		if round < maxRounds {
+			return
+		}
+		end = undoKey(round - maxRounds + 1)
+	}
+	var stale [][]byte
+	o.Iterate([]byte{undoPrefix}, end, false, func(key, value []byte) bool {
+		stale = append(stale, append([]byte{}, key...))
+		return true
+	})
+	for _, key := range stale {
+		o.delete(key)
+	}
+}
+
+// Rollback is part of idb.IndexerDb.
+func (db *IndexerDb) Rollback(round uint64) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	maxRound, err := getMaxRoundAccounted(snap)
+	if err != nil {
+		return fmt.Errorf("Rollback() err: %w", err)
+	}
+	if round > maxRound {
+		return fmt.Errorf("Rollback() round %d is after the last round %d", round, maxRound)
+	}
+
+	// Undo the rounds newest first, so that every key ends up with its value
+	// before round + 1.
+	for r := maxRound; r > round; r-- {
+		var row undoRow
+		ok, err := getRow(snap, undoKey(r), &row)
+		if err != nil {
+			return fmt.Errorf("Rollback() err: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("Rollback() round %d err: %w", r, idb.ErrorRollbackNotAvailable)
+		}
+		for _, op := range row.Ops {
+			if op.Present {
+				o.set(op.Key, op.Value)
+			} else {
+				o.delete(op.Key)
+			}
+		}
+		o.delete(undoKey(r))
+	}
+
+	err = db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("Rollback() commit err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package kv
+
+// Reader reads a consistent view of an ordered key-value store.
+type Reader interface {
+	// Get returns the value of `key` and whether the key exists. The returned
+	// slice must not be modified.
+	Get(key []byte) ([]byte, bool)
+
+	// Iterate calls `f` for the keys in [start, end) in ascending order, or in
+	// descending order if `reverse` is set. A nil `end` has no upper bound.
+	// Iteration stops when `f` returns false. The slices passed to `f` must
+	// not be modified or retained.
+	Iterate(start, end []byte, reverse bool, f func(key, value []byte) bool)
+}
+
+// Snapshot is a read-only view of the store at the time it was taken. Writes
+// that happen later are not visible.
+type Snapshot interface {
+	Reader
+
+	// Release frees the resources held by the snapshot.
+	Release()
+}
+
+// Store is an ordered key-value store. The IndexerDb only needs point reads,
+// ordered range scans, atomic batches and snapshots, so embedded stores such as
+// Pebble or Bolt can implement it; OpenStore() returns the built-in one.
+type Store interface {
+	// Snapshot returns a view of the last committed batch.
+	Snapshot() Snapshot
+
+	// Write commits a batch atomically. The batch is durable when Write
+	// returns.
+	Write(b *Batch) error
+
+	Close() error
+}
+
+type opType byte
+
+const (
+	opSet    opType = 1
+	opDelete opType = 2
+)
+
+type op struct {
+	typ   opType
+	key   []byte
+	value []byte
+}
+
+// Batch collects writes that are committed together by Store.Write().
+type Batch struct {
+	ops []op
+}
+
+// Set stores `value` at `key`. The batch keeps the slices, they must not be
+// modified afterwards.
+func (b *Batch) Set(key, value []byte) {
+	b.ops = append(b.ops, op{typ: opSet, key: key, value: value})
+}
+
+// Delete removes `key`.
+func (b *Batch) Delete(key []byte) {
+	b.ops = append(b.ops, op{typ: opDelete, key: key})
+}
+
+// Len returns the number of writes in the batch.
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// prefixEnd returns the smallest key that is greater than every key starting
+// with `prefix`, or nil if there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// iteratePrefix calls `f` for the keys starting with `prefix`.
+func iteratePrefix(r Reader, prefix []byte, reverse bool, f func(key, value []byte) bool) {
+	r.Iterate(prefix, prefixEnd(prefix), reverse, f)
+}
+
+// overlay reads through the pending writes of a batch, so that a block writer
+// sees its own updates before they are committed.
+type overlay struct {
+	Reader
+	batch   Batch
+	pending map[string]op
+}
+
+func makeOverlay(r Reader) *overlay {
+	return &overlay{
+		Reader:  r,
+		pending: make(map[string]op),
+	}
+}
+
+// Get is part of the Reader interface. Iterate does not see pending writes.
+func (o *overlay) Get(key []byte) ([]byte, bool) {
+	if p, ok := o.pending[string(key)]; ok {
+		return p.value, p.typ == opSet
+	}
+	return o.Reader.Get(key)
+}
+
+func (o *overlay) set(key, value []byte) {
+	o.batch.Set(key, value)
+	o.pending[string(key)] = op{typ: opSet, value: value}
+}
+
+func (o *overlay) delete(key []byte) {
+	o.batch.Delete(key)
+	o.pending[string(key)] = op{typ: opDelete}
+}
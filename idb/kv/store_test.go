@@ -0,0 +1,218 @@
+package kv
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keys(r Reader, start, end []byte, reverse bool) []string {
+	var res []string
+	r.Iterate(start, end, reverse, func(key, value []byte) bool {
+		res = append(res, string(key))
+		return true
+	})
+	return res
+}
+
+func TestTreapIterate(t *testing.T) {
+	s, err := openFileStore(t.TempDir(), StoreOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	var b Batch
+	var all []string
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("k%03d", (i*37)%200)
+		b.Set([]byte(key), []byte(key))
+		all = append(all, key)
+	}
+	b.Delete([]byte("k100"))
+	require.NoError(t, s.Write(&b))
+	sort.Strings(all)
+	all = append(all[:100], all[101:]...)
+
+	snap := s.Snapshot()
+	defer snap.Release()
+	assert.Equal(t, all, keys(snap, nil, nil, false))
+	assert.Equal(t, all[10:20], keys(snap, []byte("k010"), []byte("k020"), false))
+	assert.Equal(t, []string{"k019", "k018", "k017"}, keys(snap, []byte("k017"), []byte("k020"), true))
+	assert.Equal(t, []string{"k101", "k099"}, keys(snap, []byte("k099"), []byte("k102"), true))
+
+	var first []string
+	snap.Iterate([]byte("k05"), nil, false, func(key, value []byte) bool {
+		first = append(first, string(key))
+		return len(first) < 2
+	})
+	assert.Equal(t, []string{"k050", "k051"}, first)
+
+	value, ok := snap.Get([]byte("k150"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("k150"), value)
+	_, ok = snap.Get([]byte("k100"))
+	assert.False(t, ok)
+}
+
+func TestTreapRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	expected := make(map[string]bool)
+	var root *node
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("%04d", rng.Intn(1000))
+		if rng.Intn(3) == 0 {
+			root = root.remove(key)
+			delete(expected, key)
+		} else {
+			root = root.insert(key, nil)
+			expected[key] = true
+		}
+	}
+
+	var want []string
+	for key := range expected {
+		want = append(want, key)
+	}
+	sort.Strings(want)
+	assert.Equal(t, want, keys(treapSnapshot{root: root}, nil, nil, false))
+
+	var reversed []string
+	for i := len(want) - 1; i >= 0; i-- {
+		reversed = append(reversed, want[i])
+	}
+	assert.Equal(t, reversed, keys(treapSnapshot{root: root}, nil, nil, true))
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	s, err := openFileStore(t.TempDir(), StoreOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	var b Batch
+	b.Set([]byte("a"), []byte("1"))
+	require.NoError(t, s.Write(&b))
+
+	snap := s.Snapshot()
+	b = Batch{}
+	b.Set([]byte("a"), []byte("2"))
+	b.Set([]byte("b"), []byte("2"))
+	require.NoError(t, s.Write(&b))
+
+	value, _ := snap.Get([]byte("a"))
+	assert.Equal(t, []byte("1"), value)
+	assert.Equal(t, []string{"a"}, keys(snap, nil, nil, false))
+	assert.Equal(t, []string{"a", "b"}, keys(s.Snapshot(), nil, nil, false))
+}
+
+func TestPrefixIteration(t *testing.T) {
+	assert.Equal(t, []byte{'a', 'c'}, prefixEnd([]byte{'a', 'b'}))
+	assert.Equal(t, []byte{'b'}, prefixEnd([]byte{'a', 0xff}))
+	assert.Nil(t, prefixEnd([]byte{0xff, 0xff}))
+
+	s, err := openFileStore(t.TempDir(), StoreOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	var b Batch
+	for _, key := range []string{"a", "ab", "ab\xff", "ac", "b"} {
+		b.Set([]byte(key), nil)
+	}
+	require.NoError(t, s.Write(&b))
+
+	var res []string
+	iteratePrefix(s.Snapshot(), []byte("ab"), true, func(key, value []byte) bool {
+		res = append(res, string(key))
+		return true
+	})
+	assert.Equal(t, []string{"ab\xff", "ab"}, res)
+}
+
+func TestStoreRecovery(t *testing.T) {
+	dir := t.TempDir()
+	// Compact after every few batches.
+	s, err := openFileStore(dir, StoreOptions{CompactSize: 100})
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		var b Batch
+		b.Set([]byte(fmt.Sprintf("k%02d", i)), []byte("value"))
+		if i > 0 {
+			b.Delete([]byte(fmt.Sprintf("k%02d", i-1)))
+		}
+		require.NoError(t, s.Write(&b))
+	}
+	// Simulate a crash: don't close, and tear the last record.
+	var b Batch
+	b.Set([]byte("torn"), []byte("value"))
+	record := encodeRecord(b.ops)
+	_, err = s.log.Write(record[:len(record)-1])
+	require.NoError(t, err)
+	logSize := s.logSize
+	s.log.Close()
+
+	s, err = openFileStore(dir, StoreOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k19"}, keys(s.Snapshot(), nil, nil, false))
+	assert.Equal(t, logSize, s.logSize)
+
+	b = Batch{}
+	b.Set([]byte("k20"), []byte("value"))
+	require.NoError(t, s.Write(&b))
+	require.NoError(t, s.Close())
+
+	info, err := os.Stat(filepath.Join(dir, logFileName))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	s, err = openFileStore(dir, StoreOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, []string{"k19", "k20"}, keys(s.Snapshot(), nil, nil, false))
+}
+
+func TestOverlay(t *testing.T) {
+	s, err := openFileStore(t.TempDir(), StoreOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	var b Batch
+	b.Set([]byte("a"), []byte("1"))
+	require.NoError(t, s.Write(&b))
+
+	o := makeOverlay(s.Snapshot())
+	o.set([]byte("b"), []byte("2"))
+	o.delete([]byte("a"))
+	_, ok := o.Get([]byte("a"))
+	assert.False(t, ok)
+	value, ok := o.Get([]byte("b"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), value)
+
+	require.NoError(t, s.Write(&o.batch))
+	assert.Equal(t, []string{"b"}, keys(s.Snapshot(), nil, nil, false))
+}
+
+func TestStoreReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openFileStore(dir, StoreOptions{CompactSize: 100})
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := 0; i < 10; i++ {
+		var b Batch
+		b.Set([]byte(fmt.Sprintf("k%02d", i)), []byte("value"))
+		require.NoError(t, s.Write(&b))
+
+		// Sees every committed batch, whether it is in the snapshot or the log.
+		r, err := openFileStore(dir, StoreOptions{ReadOnly: true})
+		require.NoError(t, err)
+		assert.Len(t, keys(r.Snapshot(), nil, nil, false), i+1)
+		assert.Error(t, r.Write(&b))
+		require.NoError(t, r.Close())
+	}
+}
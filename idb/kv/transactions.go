@@ -0,0 +1,394 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/algorand/go-algorand/data/transactions"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// txnCandidate is a transaction selected by the key range of a query, before
+// the remaining filters are applied.
+type txnCandidate struct {
+	round uint64
+	intra uint32
+	row   []byte
+}
+
+// roundRange returns the rounds selected by `tf`, empty is false if no round
+// matches.
+func roundRange(tf idb.TransactionFilter) (min, max uint64, empty bool) {
+	min = tf.MinRound
+	max = ^uint64(0)
+	if tf.MaxRound != 0 {
+		max = tf.MaxRound
+	}
+	if tf.Round != nil {
+		if *tf.Round > min {
+			min = *tf.Round
+		}
+		if *tf.Round < max {
+			max = *tf.Round
+		}
+	}
+	return min, max, min > max
+}
+
+// iterateTxns calls `f` for the transactions in the key range selected by
+// `tf`, in the order of the postgres queries: by address (round, intra)
+// descending, otherwise (round, intra) ascending.
+func iterateTxns(r Reader, tf idb.TransactionFilter, f func(c txnCandidate) bool) {
+	min, max, empty := roundRange(tf)
+	if empty {
+		return
+	}
+
+	switch {
+	case tf.Address != nil:
+		prefix := addressKey(txnParticipationPrefix, tf.Address)
+		start := txnParticipationKey(tf.Address, max, ^uint32(0))
+		r.Iterate(start, prefixEnd(prefix), false, func(key, value []byte) bool {
+			round, intra := parseTxnParticipationKey(key)
+			if round < min {
+				return false
+			}
+			if tf.AddressRole != 0 && decodeRoles(value)&tf.AddressRole == 0 {
+				return true
+			}
+			row, ok := r.Get(txnKey(round, intra))
+			if !ok {
+				return true
+			}
+			return f(txnCandidate{round: round, intra: intra, row: row})
+		})
+	case len(tf.Txid) != 0:
+		value, ok := r.Get(txidKey(tf.Txid))
+		if !ok {
+			return
+		}
+		round, intra := parseTxnKey(append([]byte{txnPrefix}, value...))
+		row, ok := r.Get(txnKey(round, intra))
+		if !ok || round < min || round > max {
+			return
+		}
+		f(txnCandidate{round: round, intra: intra, row: row})
+	case len(tf.GroupID) != 0:
+		iterateDigestTxns(r, txnByGroupPrefix, tf.GroupID, min, max, f)
+	case len(tf.Lease) != 0:
+		iterateDigestTxns(r, txnByLeasePrefix, tf.Lease, min, max, f)
+	default:
+		end := []byte{txnPrefix + 1}
+		if max != ^uint64(0) {
+			end = txnKey(max+1, 0)
+		}
+		r.Iterate(txnKey(min, 0), end, false, func(key, value []byte) bool {
+			round, intra := parseTxnKey(key)
+			return f(txnCandidate{round: round, intra: intra, row: value})
+		})
+	}
+}
+
+// iterateDigestTxns calls `f` for the transactions indexed by `digest` under
+// `prefix` in [min, max], (round, intra) ascending. Group ids and leases are
+// 32 bytes, nothing else matches.
+func iterateDigestTxns(r Reader, prefix byte, digest []byte, min, max uint64, f func(c txnCandidate) bool) {
+	if len(digest) != addressLen {
+		return
+	}
+	iteratePrefix(r, addressKey(prefix, digest), false, func(key, value []byte) bool {
+		round, intra := parseDigestTxnKey(key)
+		if round < min {
+			return true
+		}
+		if round > max {
+			return false
+		}
+		row, ok := r.Get(txnKey(round, intra))
+		if !ok {
+			return true
+		}
+		return f(txnCandidate{round: round, intra: intra, row: row})
+	})
+}
+
+// matchDigest returns whether a group id or lease is set and equal to `b`.
+func matchDigest(d [32]byte, b []byte) bool {
+	return d != ([32]byte{}) && bytes.Equal(d[:], b)
+}
+
+// matchTxn applies the filters that are not covered by the key range.
+// Missing fields never match a comparison, like the NULL columns and omitted
+// json fields in SQL.
+func matchTxn(tf idb.TransactionFilter, creatableID uint64, c txnCandidate, row *txnRow, stxn *transactions.SignedTxnWithAD, roundTime time.Time) bool {
+	txn := &stxn.Txn
+	intra := uint64(c.intra)
+	switch {
+	case !tf.BeforeTime.IsZero() && !roundTime.Before(tf.BeforeTime):
+		return false
+	case !tf.AfterTime.IsZero() && !roundTime.After(tf.AfterTime):
+		return false
+	case creatableID != 0 && row.Asset != creatableID:
+		return false
+	case tf.AssetAmountGT != nil && !(txn.AssetAmount != 0 && txn.AssetAmount > *tf.AssetAmountGT):
+		return false
+	case tf.AssetAmountLT != nil && !(txn.AssetAmount != 0 && txn.AssetAmount < *tf.AssetAmountLT):
+		return false
+	case tf.TypeEnum != 0 && row.TypeEnum != tf.TypeEnum:
+		return false
+	case len(tf.Txid) != 0 && row.Txid != tf.Txid:
+		return false
+	case tf.Offset != nil && intra != *tf.Offset:
+		return false
+	case tf.OffsetLT != nil && !(intra < *tf.OffsetLT):
+		return false
+	case tf.OffsetGT != nil && !(intra > *tf.OffsetGT):
+		return false
+	case len(tf.SigType) != 0 && row.SigType != tf.SigType:
+		return false
+	case len(tf.NotePrefix) > 0 && !bytes.HasPrefix(txn.Note, tf.NotePrefix):
+		return false
+	case len(tf.GroupID) > 0 && !matchDigest(txn.Group, tf.GroupID):
+		return false
+	case len(tf.Lease) > 0 && !matchDigest(txn.Lease, tf.Lease):
+		return false
+	case tf.AlgosGT != nil && !(txn.Amount.Raw != 0 && txn.Amount.Raw > *tf.AlgosGT):
+		return false
+	case tf.AlgosLT != nil && !(txn.Amount.Raw != 0 && txn.Amount.Raw < *tf.AlgosLT):
+		return false
+	}
+
+	if tf.EffectiveAmountGT != nil || tf.EffectiveAmountLT != nil {
+		closeAmount := stxn.ApplyData.ClosingAmount.Raw
+		if closeAmount == 0 || txn.Amount.Raw == 0 {
+			return false
+		}
+		effective := closeAmount + txn.Amount.Raw
+		if tf.EffectiveAmountGT != nil && !(effective > *tf.EffectiveAmountGT) {
+			return false
+		}
+		if tf.EffectiveAmountLT != nil && !(effective < *tf.EffectiveAmountLT) {
+			return false
+		}
+	}
+	if tf.RekeyTo != nil && (*tf.RekeyTo) && txn.RekeyTo.IsZero() {
+		return false
+	}
+	return true
+}
+
+// This function blocks. `r` must be a consistent view of the store.
+func (db *IndexerDb) yieldTxns(ctx context.Context, r Reader, tf idb.TransactionFilter, out chan<- idb.TxnRow) {
+	if len(tf.NextToken) > 0 {
+		db.txnsWithNext(ctx, r, tf, out)
+		return
+	}
+
+	db.yieldTxnsThreadSimple(ctx, r, tf, out, nil, nil)
+}
+
+// Transactions is part of idb.IndexerDB
+func (db *IndexerDb) Transactions(ctx context.Context, tf idb.TransactionFilter) (<-chan idb.TxnRow, uint64) {
+	out := make(chan idb.TxnRow, 1)
+
+	snap := db.store.Snapshot()
+	round, err := getMaxRoundAccounted(snap)
+	if err != nil {
+		snap.Release()
+		out <- idb.TxnRow{Error: err}
+		close(out)
+		return out, round
+	}
+
+	go func() {
+		db.yieldTxns(ctx, snap, tf, out)
+		snap.Release()
+		close(out)
+	}()
+
+	return out, round
+}
+
+// This function blocks. `r` must be a consistent view of the store.
+func (db *IndexerDb) txnsWithNext(ctx context.Context, r Reader, tf idb.TransactionFilter, out chan<- idb.TxnRow) {
+	// Check for remainder of round from previous page.
+	nextround, nextintra32, err := idb.DecodeTxnRowNext(tf.NextToken)
+	nextintra := uint64(nextintra32)
+	if err != nil {
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+	origRound := tf.Round
+	origOLT := tf.OffsetLT
+	origOGT := tf.OffsetGT
+	if tf.Address != nil {
+		// (round,intra) descending into the past
+		if nextround == 0 && nextintra == 0 {
+			return
+		}
+		tf.Round = &nextround
+		tf.OffsetLT = &nextintra
+	} else {
+		// (round,intra) ascending into the future
+		tf.Round = &nextround
+		tf.OffsetGT = &nextintra
+	}
+
+	count := 0
+	db.yieldTxnsThreadSimple(ctx, r, tf, out, &count, &err)
+	if err != nil {
+		return
+	}
+
+	// If we haven't reached the limit, restore the original filter and
+	// re-run the original search with new Min/Max round and reduced limit.
+	if uint64(count) >= tf.Limit {
+		return
+	}
+	tf.Limit -= uint64(count)
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	tf.Round = origRound
+	if tf.Address != nil {
+		// (round,intra) descending into the past
+		tf.OffsetLT = origOLT
+
+		if nextround <= 1 {
+			// NO second query
+			return
+		}
+
+		tf.MaxRound = nextround - 1
+	} else {
+		// (round,intra) ascending into the future
+		tf.OffsetGT = origOGT
+		tf.MinRound = nextround + 1
+	}
+	db.yieldTxnsThreadSimple(ctx, r, tf, out, nil, nil)
+}
+
+// roundTimes caches the block time of the last round read.
+type roundTimes struct {
+	r     Reader
+	round uint64
+	time  time.Time
+	ok    bool
+}
+
+func (rt *roundTimes) get(round uint64) (time.Time, error) {
+	if rt.ok && rt.round == round {
+		return rt.time, nil
+	}
+	header, ok, err := getBlockHeader(rt.r, round)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("block header %d not found", round)
+	}
+	rt.round = round
+	rt.time = time.Unix(header.TimeStamp, 0).UTC()
+	rt.ok = true
+	return rt.time, nil
+}
+
+// buildTxnRow decodes the candidate and returns whether it matches `tf`.
+func buildTxnRow(r Reader, tf idb.TransactionFilter, creatableID uint64, times *roundTimes, c txnCandidate) (idb.TxnRow, bool, error) {
+	var row txnRow
+	err := decodeRow(c.row, &row)
+	if err != nil {
+		return idb.TxnRow{}, false, fmt.Errorf("%d:%d decode txn row, %v", c.round, c.intra, err)
+	}
+	stxn, err := decodeSignedTxnWithAD(row.Txn)
+	if err != nil {
+		return idb.TxnRow{}, false, fmt.Errorf("error decoding txn, err: %w", err)
+	}
+	roundTime, err := times.get(c.round)
+	if err != nil {
+		return idb.TxnRow{}, false, err
+	}
+	if !matchTxn(tf, creatableID, c, &row, &stxn, roundTime) {
+		return idb.TxnRow{}, false, nil
+	}
+
+	res := idb.TxnRow{
+		Round:     c.round,
+		Intra:     int(c.intra),
+		RoundTime: roundTime,
+		AssetID:   row.Asset,
+		Extra:     row.Extra,
+	}
+	if row.Extra.RootIntra.Present {
+		// Inner transaction.
+		var root txnRow
+		ok, err := getRow(r, txnKey(c.round, uint32(row.Extra.RootIntra.Value)), &root)
+		if err == nil && !ok {
+			err = fmt.Errorf("root txn %d:%d not found", c.round, row.Extra.RootIntra.Value)
+		}
+		if err != nil {
+			return idb.TxnRow{}, false, fmt.Errorf("error decoding roottxn, err: %w", err)
+		}
+		res.RootTxn = new(transactions.SignedTxnWithAD)
+		*res.RootTxn, err = decodeSignedTxnWithAD(root.Txn)
+		if err != nil {
+			return idb.TxnRow{}, false, fmt.Errorf("error decoding roottxn, err: %w", err)
+		}
+	} else {
+		// Root transaction.
+		res.Txn = &stxn
+	}
+	return res, true, nil
+}
+
+func (db *IndexerDb) yieldTxnsThreadSimple(ctx context.Context, r Reader, tf idb.TransactionFilter, results chan<- idb.TxnRow, countp *int, errp *error) {
+	var creatableID uint64
+	if tf.AssetID != 0 || tf.ApplicationID != 0 {
+		if tf.AssetID != 0 {
+			creatableID = tf.AssetID
+			if tf.ApplicationID != 0 && tf.AssetID != tf.ApplicationID {
+				err := fmt.Errorf("txn query err %v", fmt.Errorf("cannot search both assetid and appid"))
+				results <- idb.TxnRow{Error: err}
+				if errp != nil {
+					*errp = err
+				}
+				return
+			}
+		} else {
+			creatableID = tf.ApplicationID
+		}
+	}
+
+	times := roundTimes{r: r}
+	count := 0
+	iterateTxns(r, tf, func(c txnCandidate) bool {
+		row, ok, err := buildTxnRow(r, tf, creatableID, &times, c)
+		if err == nil && !ok {
+			return true
+		}
+		if err != nil {
+			row = idb.TxnRow{Error: err}
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case results <- row:
+			if err != nil {
+				if errp != nil {
+					*errp = err
+				}
+				return false
+			}
+			count++
+			return tf.Limit == 0 || uint64(count) < tf.Limit
+		}
+	})
+	if countp != nil {
+		*countp = count
+	}
+}
@@ -0,0 +1,171 @@
+package kv
+
+import (
+	"hash/fnv"
+)
+
+// node is a node of an immutable treap. Updates copy the nodes on the path to
+// the modified key, so a root pointer is a snapshot of the whole tree.
+type node struct {
+	key      string
+	value    []byte
+	priority uint32
+	left     *node
+	right    *node
+}
+
+// priority is derived from the key, which keeps the tree shape independent of
+// the insertion history.
+func priority(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+func (n *node) get(key string) ([]byte, bool) {
+	for n != nil {
+		switch {
+		case key < n.key:
+			n = n.left
+		case key > n.key:
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+	return nil, false
+}
+
+func (n *node) copy() *node {
+	c := *n
+	return &c
+}
+
+// insert returns a tree where `key` is set to `value`.
+func (n *node) insert(key string, value []byte) *node {
+	if n == nil {
+		return &node{key: key, value: value, priority: priority(key)}
+	}
+	c := n.copy()
+	switch {
+	case key < n.key:
+		c.left = n.left.insert(key, value)
+		if c.left.priority > c.priority {
+			// Rotate right.
+			l := c.left.copy()
+			c.left = l.right
+			l.right = c
+			return l
+		}
+	case key > n.key:
+		c.right = n.right.insert(key, value)
+		if c.right.priority > c.priority {
+			// Rotate left.
+			r := c.right.copy()
+			c.right = r.left
+			r.left = c
+			return r
+		}
+	default:
+		c.value = value
+	}
+	return c
+}
+
+// remove returns a tree without `key`.
+func (n *node) remove(key string) *node {
+	if n == nil {
+		return nil
+	}
+	switch {
+	case key < n.key:
+		left := n.left.remove(key)
+		if left == n.left {
+			return n
+		}
+		c := n.copy()
+		c.left = left
+		return c
+	case key > n.key:
+		right := n.right.remove(key)
+		if right == n.right {
+			return n
+		}
+		c := n.copy()
+		c.right = right
+		return c
+	default:
+		return merge(n.left, n.right)
+	}
+}
+
+// merge joins two trees, the keys of `a` are less than the keys of `b`.
+func merge(a, b *node) *node {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		c := a.copy()
+		c.right = merge(a.right, b)
+		return c
+	}
+	c := b.copy()
+	c.left = merge(a, b.left)
+	return c
+}
+
+// iterate calls `f` for the keys in [start, end) in order. An empty `end` has
+// no upper bound. Returns false if `f` stopped the iteration.
+func (n *node) iterate(start, end string, f func(n *node) bool) bool {
+	if n == nil {
+		return true
+	}
+	if start < n.key {
+		if !n.left.iterate(start, end, f) {
+			return false
+		}
+	}
+	if start <= n.key && (end == "" || n.key < end) {
+		if !f(n) {
+			return false
+		}
+	}
+	if end == "" || n.key < end {
+		return n.right.iterate(start, end, f)
+	}
+	return true
+}
+
+// iterateReverse is iterate in descending order.
+func (n *node) iterateReverse(start, end string, f func(n *node) bool) bool {
+	if n == nil {
+		return true
+	}
+	if end == "" || n.key < end {
+		if !n.right.iterateReverse(start, end, f) {
+			return false
+		}
+	}
+	if start <= n.key && (end == "" || n.key < end) {
+		if !f(n) {
+			return false
+		}
+	}
+	if start < n.key {
+		return n.left.iterateReverse(start, end, f)
+	}
+	return true
+}
+
+// each calls `f` for every node in order.
+func (n *node) each(f func(n *node)) {
+	if n == nil {
+		return
+	}
+	n.left.each(f)
+	f(n)
+	n.right.each(f)
+}
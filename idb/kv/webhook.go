@@ -0,0 +1,88 @@
+package kv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// webhookKey returns the metastate key of the webhook `id`.
+func webhookKey(id string) string {
+	return webhookMetastateKeyPrefix + id
+}
+
+// GetWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) GetWebhook(id string) (idb.Webhook, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	var webhook idb.Webhook
+	err := getMetastate(snap, webhookKey(id), &webhook)
+	if err == idb.ErrorNotInitialized {
+		return idb.Webhook{}, idb.ErrorWebhookNotFound
+	}
+	if err != nil {
+		return idb.Webhook{}, fmt.Errorf("GetWebhook() err: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetWebhooks is part of idb.IndexerDb.
+func (db *IndexerDb) GetWebhooks() (map[string]idb.Webhook, error) {
+	snap := db.store.Snapshot()
+	defer snap.Release()
+
+	res := make(map[string]idb.Webhook)
+	prefix := metastateKey(webhookMetastateKeyPrefix)
+	var err error
+	snap.Iterate(prefix, prefixEnd(prefix), false, func(key, value []byte) bool {
+		var webhook idb.Webhook
+		err = decodeRow(value, &webhook)
+		if err != nil {
+			err = fmt.Errorf("GetWebhooks() key %q err: %w", key, err)
+			return false
+		}
+		id := strings.TrimPrefix(string(key[1:]), webhookMetastateKeyPrefix)
+		res[id] = webhook
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SetWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) SetWebhook(id string, webhook idb.Webhook) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	setMetastate(o, webhookKey(id), &webhook)
+	err := db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("SetWebhook() err: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) DeleteWebhook(id string) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	snap := db.store.Snapshot()
+	defer snap.Release()
+	o := makeOverlay(snap)
+
+	o.delete(metastateKey(webhookKey(id)))
+	err := db.store.Write(&o.batch)
+	if err != nil {
+		return fmt.Errorf("DeleteWebhook() err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,533 @@
+package kv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+
+	"github.com/algorand/indexer/accounting"
+	"github.com/algorand/indexer/idb"
+)
+
+// writer is responsible for writing blocks and accounting state deltas to the
+// batch of an overlay. Upserts behave like the sqlite statements: created_at
+// is kept when a row is updated, closed_at is kept when it is recreated.
+type writer struct {
+	o *overlay
+}
+
+func (w *writer) addBlockHeader(blockHeader *bookkeeping.BlockHeader) {
+	key := blockHeaderKey(uint64(blockHeader.Round))
+	if _, ok := w.o.Get(key); !ok {
+		w.o.set(key, encodeBlockHeader(*blockHeader))
+	}
+
+	specialAddresses := transactions.SpecialAddresses{
+		FeeSink:     blockHeader.FeeSink,
+		RewardsPool: blockHeader.RewardsPool,
+	}
+	setMetastate(w.o, specialAccountsMetastateKey, &specialAddresses)
+}
+
+// Describes a change to the `account.keytype` field. If `present` is true,
+// `value` is the new value. Otherwise, empty will be the new value.
+type sigTypeDelta struct {
+	present bool
+	value   idb.SigType
+}
+
+func getSigTypeDeltas(payset []transactions.SignedTxnInBlock) (map[basics.Address]sigTypeDelta, error) {
+	res := make(map[basics.Address]sigTypeDelta, len(payset))
+
+	for i := range payset {
+		if payset[i].Txn.RekeyTo == (basics.Address{}) {
+			sigtype, err := idb.SignatureType(&payset[i].SignedTxn)
+			if err != nil {
+				return nil, fmt.Errorf("getSigTypeDelta() err: %w", err)
+			}
+			res[payset[i].Txn.Sender] = sigTypeDelta{present: true, value: sigtype}
+		} else {
+			res[payset[i].Txn.Sender] = sigTypeDelta{}
+		}
+	}
+
+	return res, nil
+}
+
+type optionalSigTypeDelta struct {
+	present bool
+	value   sigTypeDelta
+}
+
+// upsertCreatable updates the asset or app `id`. `byCreator` is the key of
+// the creator index.
+func (w *writer) upsertCreatable(key, byCreator []byte, round basics.Round, update func(row *creatableRow)) error {
+	var row creatableRow
+	ok, err := getRow(w.o, key, &row)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		row.CreatedAt = uint64(round)
+		w.o.set(byCreator, nil)
+	}
+	update(&row)
+	w.o.set(key, encodeRow(&row))
+	return nil
+}
+
+func (w *writer) upsertHolding(addr basics.Address, assetid basics.AssetIndex, round basics.Round, update func(row *holdingRow)) error {
+	key := accountAssetKey(addr[:], uint64(assetid))
+	var row holdingRow
+	ok, err := getRow(w.o, key, &row)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		row.CreatedAt = uint64(round)
+		w.o.set(accountAssetByAssetKey(uint64(assetid), addr[:]), nil)
+	}
+	update(&row)
+	w.o.set(key, encodeRow(&row))
+	return nil
+}
+
+func (w *writer) upsertLocalState(addr basics.Address, app basics.AppIndex, round basics.Round, update func(row *localStateRow)) error {
+	key := accountAppKey(addr[:], uint64(app))
+	var row localStateRow
+	ok, err := getRow(w.o, key, &row)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		row.CreatedAt = uint64(round)
+		w.o.set(accountAppByAppKey(uint64(app), addr[:]), nil)
+	}
+	update(&row)
+	w.o.set(key, encodeRow(&row))
+	return nil
+}
+
+func (w *writer) upsertAccount(addr basics.Address, round basics.Round, update func(row *accountRow)) error {
+	key := accountKey(addr[:])
+	var row accountRow
+	ok, err := getRow(w.o, key, &row)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		row.CreatedAt = uint64(round)
+	}
+	oldAuthAddr := row.AuthAddr
+	update(&row)
+	if !bytes.Equal(oldAuthAddr, row.AuthAddr) {
+		if oldAuthAddr != nil {
+			w.o.delete(accountByAuthAddrKey(oldAuthAddr, addr[:]))
+		}
+		if row.AuthAddr != nil {
+			w.o.set(accountByAuthAddrKey(row.AuthAddr, addr[:]), nil)
+		}
+	}
+	w.o.set(key, encodeRow(&row))
+	return nil
+}
+
+func (w *writer) writeAccount(round basics.Round, address basics.Address, accountData basics.AccountData, sigtypeDelta optionalSigTypeDelta) error {
+	// Update `asset` table.
+	for assetid, params := range accountData.AssetParams {
+		params := params
+		err := w.upsertCreatable(
+			assetKey(uint64(assetid)), assetByCreatorKey(address[:], uint64(assetid)), round,
+			func(row *creatableRow) {
+				row.Creator = append([]byte{}, address[:]...)
+				row.Params = encodeAssetParams(params)
+				row.Name = params.AssetName
+				row.Unit = params.UnitName
+				row.Deleted = false
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update `account_asset` table.
+	for assetid, holding := range accountData.Assets {
+		holding := holding
+		err := w.upsertHolding(address, assetid, round, func(row *holdingRow) {
+			row.Amount = holding.Amount
+			row.Frozen = holding.Frozen
+			row.Deleted = false
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update `app` table.
+	for appid, params := range accountData.AppParams {
+		params := params
+		err := w.upsertCreatable(
+			appKey(uint64(appid)), appByCreatorKey(address[:], uint64(appid)), round,
+			func(row *creatableRow) {
+				row.Creator = append([]byte{}, address[:]...)
+				row.Params = encodeAppParams(params)
+				row.Deleted = false
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update `account_app` table.
+	for appid, state := range accountData.AppLocalStates {
+		state := state
+		err := w.upsertLocalState(address, appid, round, func(row *localStateRow) {
+			row.LocalState = encodeAppLocalState(state)
+			row.Deleted = false
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update `account` table.
+	return w.upsertAccount(address, round, func(row *accountRow) {
+		if sigtypeDelta.present {
+			row.KeyType = ""
+			if sigtypeDelta.value.present {
+				row.KeyType = sigtypeDelta.value.value
+			}
+		}
+
+		if accountData.IsZero() {
+			// Delete account.
+			*row = accountRow{
+				Deleted:   true,
+				CreatedAt: row.CreatedAt,
+				ClosedAt:  uint64Ptr(uint64(round)),
+				KeyType:   row.KeyType,
+			}
+			return
+		}
+
+		// Update account.
+		row.MicroAlgos = accountData.MicroAlgos.Raw
+		row.RewardsBase = accountData.RewardsBase
+		row.RewardsTotal = accountData.RewardedMicroAlgos.Raw
+		row.Deleted = false
+		row.AuthAddr = addressOrNil(accountData.AuthAddr)
+		row.AccountData = encodeAccountData(trimAccountData(accountData))
+	})
+}
+
+func (w *writer) writeAccounts(round basics.Round, accountDeltas ledgercore.AccountDeltas, sigtypeDeltas map[basics.Address]sigTypeDelta) error {
+	for i := 0; i < accountDeltas.Len(); i++ {
+		address, accountData := accountDeltas.GetByIdx(i)
+
+		var sigtypeDelta optionalSigTypeDelta
+		sigtypeDelta.value, sigtypeDelta.present = sigtypeDeltas[address]
+
+		err := w.writeAccount(round, address, accountData, sigtypeDelta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) writeDeletedCreatables(round basics.Round, creatables map[basics.CreatableIndex]ledgercore.ModifiedCreatable) error {
+	for index, creatable := range creatables {
+		// If deleted.
+		if !creatable.Created {
+			key := appKey(uint64(index))
+			byCreator := appByCreatorKey(creatable.Creator[:], uint64(index))
+			if creatable.Ctype == basics.AssetCreatable {
+				key = assetKey(uint64(index))
+				byCreator = assetByCreatorKey(creatable.Creator[:], uint64(index))
+			}
+			creator := creatable.Creator
+			err := w.upsertCreatable(key, byCreator, round, func(row *creatableRow) {
+				row.Creator = append([]byte{}, creator[:]...)
+				row.Params = nil
+				row.Deleted = true
+				row.ClosedAt = uint64Ptr(uint64(round))
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) writeDeletedAssetHoldings(round basics.Round, modifiedAssetHoldings map[ledgercore.AccountAsset]bool) error {
+	for aa, created := range modifiedAssetHoldings {
+		if !created {
+			err := w.upsertHolding(aa.Address, aa.Asset, round, func(row *holdingRow) {
+				row.Amount = 0
+				row.Deleted = true
+				row.ClosedAt = uint64Ptr(uint64(round))
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) writeDeletedAppLocalStates(round basics.Round, modifiedAppLocalStates map[ledgercore.AccountApp]bool) error {
+	for aa, created := range modifiedAppLocalStates {
+		if !created {
+			err := w.upsertLocalState(aa.Address, aa.App, round, func(row *localStateRow) {
+				row.LocalState = nil
+				row.Deleted = true
+				row.ClosedAt = uint64Ptr(uint64(round))
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func uint64Ptr(x uint64) *uint64 {
+	return &x
+}
+
+// addBlock0 writes block 0.
+func (w *writer) addBlock0(block *bookkeeping.Block) {
+	w.addBlockHeader(&block.BlockHeader)
+}
+
+// addBlock writes the block, its transactions and the accounting state deltas.
+func (w *writer) addBlock(block *bookkeeping.Block, modifiedTxns []transactions.SignedTxnInBlock, delta ledgercore.StateDelta) error {
+	w.addBlockHeader(&block.BlockHeader)
+
+	err := w.addTransactions(block, modifiedTxns)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	w.addTransactionParticipation(block)
+
+	sigTypeDeltas, err := getSigTypeDeltas(block.Payset)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeAccounts(block.Round(), delta.Accts, sigTypeDeltas)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeDeletedCreatables(block.Round(), delta.Creatables)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeDeletedAssetHoldings(block.Round(), delta.ModifiedAssetHoldings)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeDeletedAppLocalStates(block.Round(), delta.ModifiedAppLocalStates)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	setMetastate(w.o, accountTotalsMetastateKey, &delta.Totals)
+
+	return nil
+}
+
+// addTxn writes one row of the `txn` table. `txid` and `sigtype` are empty for
+// inner transactions.
+func (w *writer) addTxn(round basics.Round, intra uint, assetid uint64, txid string, stxnad *transactions.SignedTxnWithAD, extra *idb.TxnExtra, sigtype idb.SigType) error {
+	typeenum, ok := idb.GetTypeEnum(stxnad.Txn.Type)
+	if !ok {
+		return fmt.Errorf("addTxn() get type enum")
+	}
+
+	row := txnRow{
+		TypeEnum: typeenum,
+		Asset:    assetid,
+		Txid:     txid,
+		Txn:      encodeSignedTxnWithAD(*stxnad),
+		Extra:    *extra,
+		SigType:  sigtype,
+	}
+	w.o.set(txnKey(uint64(round), uint32(intra)), encodeRow(&row))
+	if txid != "" {
+		w.o.set(txidKey(txid), txnKey(uint64(round), uint32(intra))[1:])
+	}
+	if !stxnad.Txn.Group.IsZero() {
+		w.o.set(digestTxnKey(txnByGroupPrefix, stxnad.Txn.Group[:], uint64(round), uint32(intra)), nil)
+	}
+	if stxnad.Txn.Lease != ([32]byte{}) {
+		w.o.set(digestTxnKey(txnByLeasePrefix, stxnad.Txn.Lease[:], uint64(round), uint32(intra)), nil)
+	}
+	return nil
+}
+
+// Traverses the inner transaction tree and writes `txn` rows. It performs a
+// preorder traversal to correctly compute the intra round offset, the offset
+// for the next transaction is returned.
+func (w *writer) addInnerTransactions(stxnad *transactions.SignedTxnWithAD, round basics.Round, intra, rootIntra uint, rootTxid string) (uint, error) {
+	for _, itxn := range stxnad.ApplyData.EvalDelta.InnerTxns {
+		// block shouldn't be used for inner transactions.
+		assetid, err := idb.TransactionAssetID(&itxn, 0, nil)
+		if err != nil {
+			return 0, err
+		}
+		extra := idb.TxnExtra{
+			AssetCloseAmount: itxn.ApplyData.AssetClosingAmount,
+			RootIntra:        idb.OptionalUint{Present: true, Value: rootIntra},
+			RootTxid:         rootTxid,
+		}
+
+		// When encoding an inner transaction we remove any further nested inner transactions.
+		// To reconstruct a full object the root transaction must be fetched.
+		txnNoInner := itxn
+		txnNoInner.EvalDelta.InnerTxns = nil
+
+		// Inner transactions do not have a txid or a signature.
+		err = w.addTxn(round, intra, assetid, "", &txnNoInner, &extra, "")
+		if err != nil {
+			return 0, err
+		}
+
+		// Recurse at end for preorder traversal
+		intra, err = w.addInnerTransactions(&itxn, round, intra+1, rootIntra, rootTxid)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return intra, nil
+}
+
+// addTransactions writes the transactions of `block`, including inner
+// transactions. `modifiedTxns` contains enhanced apply data generated by
+// evaluator.
+func (w *writer) addTransactions(block *bookkeeping.Block, modifiedTxns []transactions.SignedTxnInBlock) error {
+	intra := uint(0)
+	for idx, stib := range block.Payset {
+		var stxnad transactions.SignedTxnWithAD
+		var err error
+		// This function makes sure to set correct genesis information so we can get the
+		// correct transaction hash.
+		stxnad.SignedTxn, stxnad.ApplyData, err = block.BlockHeader.DecodeSignedTxn(stib)
+		if err != nil {
+			return fmt.Errorf("addTransactions() decode signed txn err: %w", err)
+		}
+
+		assetid, err := idb.TransactionAssetID(&stxnad, intra, block)
+		if err != nil {
+			return fmt.Errorf("addTransactions() err: %w", err)
+		}
+		id := stxnad.Txn.ID().String()
+		extra := idb.TxnExtra{
+			AssetCloseAmount: modifiedTxns[idx].ApplyData.AssetClosingAmount,
+		}
+		err = w.addTxn(
+			block.Round(), intra, assetid, id, &stxnad, &extra, sigType(&stxnad.SignedTxn))
+		if err != nil {
+			return fmt.Errorf("addTransactions() err: %w", err)
+		}
+
+		intra, err = w.addInnerTransactions(
+			&stib.SignedTxnWithAD, block.Round(), intra+1, intra, id)
+		if err != nil {
+			return fmt.Errorf("addTransactions() adding inner: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sigType returns the name of the signature field that is set in `stxn`.
+// Unlike idb.SignatureType(), a delegated logic signature is "lsig", the same
+// as the json key checked by the postgres sigtype filter.
+func sigType(stxn *transactions.SignedTxn) idb.SigType {
+	switch {
+	case !stxn.Sig.Blank():
+		return idb.Sig
+	case !stxn.Msig.Blank():
+		return idb.Msig
+	case !stxn.Lsig.Blank():
+		return idb.Lsig
+	}
+	return ""
+}
+
+// addressRoles returns the roles of `address` in `txn`. Unset address fields
+// never match, like the omitted fields of the postgres json.
+func addressRoles(txn *transactions.Transaction, address basics.Address) idb.AddressRole {
+	var roles idb.AddressRole
+	add := func(field basics.Address, role idb.AddressRole) {
+		if !field.IsZero() && field == address {
+			roles |= role
+		}
+	}
+
+	add(txn.Sender, idb.AddressRoleSender)
+	add(txn.Receiver, idb.AddressRoleReceiver)
+	add(txn.CloseRemainderTo, idb.AddressRoleCloseRemainderTo)
+	add(txn.AssetSender, idb.AddressRoleAssetSender)
+	add(txn.AssetReceiver, idb.AddressRoleAssetReceiver)
+	add(txn.AssetCloseTo, idb.AddressRoleAssetCloseTo)
+	add(txn.FreezeAccount, idb.AddressRoleFreeze)
+
+	return roles
+}
+
+func encodeRoles(roles idb.AddressRole) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(roles))
+	return buf[:n]
+}
+
+func decodeRoles(data []byte) idb.AddressRole {
+	roles, _ := binary.Uvarint(data)
+	return idb.AddressRole(roles)
+}
+
+func (w *writer) addParticipants(stxnad *transactions.SignedTxnWithAD, round basics.Round, intra uint64, includeInner bool) {
+	add := func(address basics.Address) {
+		w.o.set(
+			txnParticipationKey(address[:], uint64(round), uint32(intra)),
+			encodeRoles(addressRoles(&stxnad.Txn, address)))
+	}
+	accounting.GetTransactionParticipants(stxnad, includeInner, add)
+}
+
+// addInnerTransactionParticipation traverses the inner transaction tree and
+// adds txn participation records for each. It performs a preorder traversal
+// to correctly compute the intra round offset, the offset for the next
+// transaction is returned.
+func (w *writer) addInnerTransactionParticipation(stxnad *transactions.SignedTxnWithAD, round basics.Round, intra uint64) uint64 {
+	next := intra
+	for _, itxn := range stxnad.ApplyData.EvalDelta.InnerTxns {
+		// Only search inner transactions by direct participation.
+		w.addParticipants(&itxn, round, next, false)
+		next = w.addInnerTransactionParticipation(&itxn, round, next+1)
+	}
+
+	return next
+}
+
+// addTransactionParticipation writes account participation info to the
+// `txn_participation` table.
+func (w *writer) addTransactionParticipation(block *bookkeeping.Block) {
+	next := uint64(0)
+	for _, stxnib := range block.Payset {
+		w.addParticipants(&stxnib.SignedTxnWithAD, block.Round(), next, true)
+		next = w.addInnerTransactionParticipation(
+			&stxnib.SignedTxnWithAD, block.Round(), next+1)
+	}
+}
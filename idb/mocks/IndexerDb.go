@@ -109,6 +109,34 @@ func (_m *IndexerDb) Close() {
 	_m.Called()
 }
 
+// DeleteExportCheckpoint provides a mock function with given fields: name
+func (_m *IndexerDb) DeleteExportCheckpoint(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteWebhook provides a mock function with given fields: id
+func (_m *IndexerDb) DeleteWebhook(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAccounts provides a mock function with given fields: ctx, opts
 func (_m *IndexerDb) GetAccounts(ctx context.Context, opts idb.AccountQueryOptions) (<-chan idb.AccountRow, uint64) {
 	ret := _m.Called(ctx, opts)
@@ -162,6 +190,50 @@ func (_m *IndexerDb) GetBlock(ctx context.Context, round uint64, options idb.Get
 	return r0, r1, r2
 }
 
+// GetExportCheckpoint provides a mock function with given fields: name
+func (_m *IndexerDb) GetExportCheckpoint(name string) (idb.ExportCheckpoint, error) {
+	ret := _m.Called(name)
+
+	var r0 idb.ExportCheckpoint
+	if rf, ok := ret.Get(0).(func(string) idb.ExportCheckpoint); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(idb.ExportCheckpoint)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportCheckpoints provides a mock function with given fields:
+func (_m *IndexerDb) GetExportCheckpoints() (map[string]idb.ExportCheckpoint, error) {
+	ret := _m.Called()
+
+	var r0 map[string]idb.ExportCheckpoint
+	if rf, ok := ret.Get(0).(func() map[string]idb.ExportCheckpoint); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]idb.ExportCheckpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNextRoundToAccount provides a mock function with given fields:
 func (_m *IndexerDb) GetNextRoundToAccount() (uint64, error) {
 	ret := _m.Called()
@@ -183,6 +255,48 @@ func (_m *IndexerDb) GetNextRoundToAccount() (uint64, error) {
 	return r0, r1
 }
 
+// GetOldestRound provides a mock function with given fields:
+func (_m *IndexerDb) GetOldestRound() (uint64, error) {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRewardsLevel provides a mock function with given fields: ctx, round
+func (_m *IndexerDb) GetRewardsLevel(ctx context.Context, round uint64) (uint64, error) {
+	ret := _m.Called(ctx, round)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) uint64); ok {
+		r0 = rf(ctx, round)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, round)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSpecialAccounts provides a mock function with given fields:
 func (_m *IndexerDb) GetSpecialAccounts() (transactions.SpecialAddresses, error) {
 	ret := _m.Called()
@@ -204,6 +318,50 @@ func (_m *IndexerDb) GetSpecialAccounts() (transactions.SpecialAddresses, error)
 	return r0, r1
 }
 
+// GetWebhook provides a mock function with given fields: id
+func (_m *IndexerDb) GetWebhook(id string) (idb.Webhook, error) {
+	ret := _m.Called(id)
+
+	var r0 idb.Webhook
+	if rf, ok := ret.Get(0).(func(string) idb.Webhook); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(idb.Webhook)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhooks provides a mock function with given fields:
+func (_m *IndexerDb) GetWebhooks() (map[string]idb.Webhook, error) {
+	ret := _m.Called()
+
+	var r0 map[string]idb.Webhook
+	if rf, ok := ret.Get(0).(func() map[string]idb.Webhook); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]idb.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Health provides a mock function with given fields:
 func (_m *IndexerDb) Health() (idb.Health, error) {
 	ret := _m.Called()
@@ -239,6 +397,62 @@ func (_m *IndexerDb) LoadGenesis(genesis bookkeeping.Genesis) error {
 	return r0
 }
 
+// PruneTransactions provides a mock function with given fields: round
+func (_m *IndexerDb) PruneTransactions(round uint64) error {
+	ret := _m.Called(round)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64) error); ok {
+		r0 = rf(round)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Rollback provides a mock function with given fields: round
+func (_m *IndexerDb) Rollback(round uint64) error {
+	ret := _m.Called(round)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64) error); ok {
+		r0 = rf(round)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetExportCheckpoint provides a mock function with given fields: name, checkpoint
+func (_m *IndexerDb) SetExportCheckpoint(name string, checkpoint idb.ExportCheckpoint) error {
+	ret := _m.Called(name, checkpoint)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, idb.ExportCheckpoint) error); ok {
+		r0 = rf(name, checkpoint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetWebhook provides a mock function with given fields: id, webhook
+func (_m *IndexerDb) SetWebhook(id string, webhook idb.Webhook) error {
+	ret := _m.Called(id, webhook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, idb.Webhook) error); ok {
+		r0 = rf(id, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Transactions provides a mock function with given fields: ctx, tf
 func (_m *IndexerDb) Transactions(ctx context.Context, tf idb.TransactionFilter) (<-chan idb.TxnRow, uint64) {
 	ret := _m.Called(ctx, tf)
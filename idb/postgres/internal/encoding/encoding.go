@@ -651,6 +651,38 @@ func DecodeMigrationState(data []byte) (types.MigrationState, error) {
 	return state, nil
 }
 
+// EncodeExportCheckpoint encodes an export checkpoint into json.
+func EncodeExportCheckpoint(checkpoint *idb.ExportCheckpoint) []byte {
+	return encodeJSON(checkpoint)
+}
+
+// DecodeExportCheckpoint decodes an export checkpoint from json.
+func DecodeExportCheckpoint(data []byte) (idb.ExportCheckpoint, error) {
+	var checkpoint idb.ExportCheckpoint
+	err := DecodeJSON(data, &checkpoint)
+	if err != nil {
+		return idb.ExportCheckpoint{}, err
+	}
+
+	return checkpoint, nil
+}
+
+// EncodeWebhook encodes a webhook into json.
+func EncodeWebhook(webhook *idb.Webhook) []byte {
+	return encodeJSON(webhook)
+}
+
+// DecodeWebhook decodes a webhook from json.
+func DecodeWebhook(data []byte) (idb.Webhook, error) {
+	var webhook idb.Webhook
+	err := DecodeJSON(data, &webhook)
+	if err != nil {
+		return idb.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
 // EncodeAccountTotals encodes account totals into json.
 func EncodeAccountTotals(totals *ledgercore.AccountTotals) []byte {
 	return encodeJSON(totals)
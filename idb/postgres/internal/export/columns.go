@@ -0,0 +1,145 @@
+package export
+
+import (
+	"github.com/algorand/indexer/parquet"
+)
+
+// Names of the exported tables, the same as the Postgres tables.
+const (
+	TxnTable              = "txn"
+	TxnParticipationTable = "txn_participation"
+	AccountTable          = "account"
+	AccountAssetTable     = "account_asset"
+	AssetTable            = "asset"
+	AppTable              = "app"
+	AccountAppTable       = "account_app"
+)
+
+// PartitionedTables are exported as one file per round range.
+var PartitionedTables = []string{TxnTable, TxnParticipationTable}
+
+// SnapshotTables hold the current state and are exported as one snapshot.
+var SnapshotTables = []string{AccountTable, AccountAssetTable, AssetTable, AppTable, AccountAppTable}
+
+// Columns of every exported table. The jsonb columns of the database are
+// decoded into typed columns, addresses are exported as strings.
+var Columns = map[string][]parquet.Column{
+	TxnTable: {
+		{Name: "round", Kind: parquet.Uint64},
+		{Name: "intra", Kind: parquet.Int32},
+		{Name: "round_time", Kind: parquet.Timestamp},
+		{Name: "typeenum", Kind: parquet.Int32},
+		{Name: "type", Kind: parquet.String},
+		{Name: "asset", Kind: parquet.Uint64},
+		{Name: "txid", Kind: parquet.String, Optional: true},
+		{Name: "root_intra", Kind: parquet.Int32, Optional: true},
+		{Name: "root_txid", Kind: parquet.String, Optional: true},
+		{Name: "sender", Kind: parquet.String},
+		{Name: "fee", Kind: parquet.Uint64},
+		{Name: "first_valid", Kind: parquet.Uint64},
+		{Name: "last_valid", Kind: parquet.Uint64},
+		{Name: "note", Kind: parquet.Bytes, Optional: true},
+		{Name: "group", Kind: parquet.Bytes, Optional: true},
+		{Name: "lease", Kind: parquet.Bytes, Optional: true},
+		{Name: "rekey_to", Kind: parquet.String, Optional: true},
+		{Name: "sig_type", Kind: parquet.String, Optional: true},
+		{Name: "receiver", Kind: parquet.String, Optional: true},
+		{Name: "amount", Kind: parquet.Uint64, Optional: true},
+		{Name: "close_remainder_to", Kind: parquet.String, Optional: true},
+		{Name: "close_amount", Kind: parquet.Uint64, Optional: true},
+		{Name: "asset_receiver", Kind: parquet.String, Optional: true},
+		{Name: "asset_amount", Kind: parquet.Uint64, Optional: true},
+		{Name: "asset_sender", Kind: parquet.String, Optional: true},
+		{Name: "asset_close_to", Kind: parquet.String, Optional: true},
+		{Name: "asset_close_amount", Kind: parquet.Uint64, Optional: true},
+		{Name: "application_id", Kind: parquet.Uint64, Optional: true},
+		{Name: "on_completion", Kind: parquet.Int32, Optional: true},
+		{Name: "sender_rewards", Kind: parquet.Uint64},
+		{Name: "receiver_rewards", Kind: parquet.Uint64},
+		{Name: "close_rewards", Kind: parquet.Uint64},
+		// The full transaction as stored in the database, for the fields that
+		// do not have a column.
+		{Name: "txn_json", Kind: parquet.JSON},
+	},
+	TxnParticipationTable: {
+		{Name: "addr", Kind: parquet.String},
+		{Name: "round", Kind: parquet.Uint64},
+		{Name: "intra", Kind: parquet.Int32},
+	},
+	AccountTable: {
+		{Name: "addr", Kind: parquet.String},
+		{Name: "microalgos", Kind: parquet.Uint64},
+		{Name: "rewardsbase", Kind: parquet.Uint64},
+		{Name: "rewards_total", Kind: parquet.Uint64},
+		{Name: "deleted", Kind: parquet.Boolean},
+		{Name: "created_at", Kind: parquet.Uint64},
+		{Name: "closed_at", Kind: parquet.Uint64, Optional: true},
+		{Name: "keytype", Kind: parquet.String, Optional: true},
+		{Name: "status", Kind: parquet.String},
+		{Name: "vote_id", Kind: parquet.Bytes, Optional: true},
+		{Name: "selection_id", Kind: parquet.Bytes, Optional: true},
+		{Name: "vote_first_valid", Kind: parquet.Uint64},
+		{Name: "vote_last_valid", Kind: parquet.Uint64},
+		{Name: "vote_key_dilution", Kind: parquet.Uint64},
+		{Name: "auth_addr", Kind: parquet.String, Optional: true},
+		{Name: "total_app_schema_num_uint", Kind: parquet.Uint64},
+		{Name: "total_app_schema_num_byte_slice", Kind: parquet.Uint64},
+		{Name: "total_extra_app_pages", Kind: parquet.Uint64},
+	},
+	AccountAssetTable: {
+		{Name: "addr", Kind: parquet.String},
+		{Name: "assetid", Kind: parquet.Uint64},
+		{Name: "amount", Kind: parquet.Uint64},
+		{Name: "frozen", Kind: parquet.Boolean},
+		{Name: "deleted", Kind: parquet.Boolean},
+		{Name: "created_at", Kind: parquet.Uint64},
+		{Name: "closed_at", Kind: parquet.Uint64, Optional: true},
+	},
+	// The params columns are null for deleted assets. Names that are not
+	// valid UTF-8 are null too.
+	AssetTable: {
+		{Name: "index", Kind: parquet.Uint64},
+		{Name: "creator_addr", Kind: parquet.String},
+		{Name: "deleted", Kind: parquet.Boolean},
+		{Name: "created_at", Kind: parquet.Uint64},
+		{Name: "closed_at", Kind: parquet.Uint64, Optional: true},
+		{Name: "total", Kind: parquet.Uint64, Optional: true},
+		{Name: "decimals", Kind: parquet.Int32, Optional: true},
+		{Name: "default_frozen", Kind: parquet.Boolean, Optional: true},
+		{Name: "unit_name", Kind: parquet.String, Optional: true},
+		{Name: "asset_name", Kind: parquet.String, Optional: true},
+		{Name: "url", Kind: parquet.String, Optional: true},
+		{Name: "metadata_hash", Kind: parquet.Bytes, Optional: true},
+		{Name: "manager", Kind: parquet.String, Optional: true},
+		{Name: "reserve", Kind: parquet.String, Optional: true},
+		{Name: "freeze", Kind: parquet.String, Optional: true},
+		{Name: "clawback", Kind: parquet.String, Optional: true},
+	},
+	// The params columns are null for deleted applications.
+	AppTable: {
+		{Name: "index", Kind: parquet.Uint64},
+		{Name: "creator", Kind: parquet.String},
+		{Name: "deleted", Kind: parquet.Boolean},
+		{Name: "created_at", Kind: parquet.Uint64},
+		{Name: "closed_at", Kind: parquet.Uint64, Optional: true},
+		{Name: "approval_program", Kind: parquet.Bytes, Optional: true},
+		{Name: "clear_state_program", Kind: parquet.Bytes, Optional: true},
+		{Name: "global_state_schema_num_uint", Kind: parquet.Uint64, Optional: true},
+		{Name: "global_state_schema_num_byte_slice", Kind: parquet.Uint64, Optional: true},
+		{Name: "local_state_schema_num_uint", Kind: parquet.Uint64, Optional: true},
+		{Name: "local_state_schema_num_byte_slice", Kind: parquet.Uint64, Optional: true},
+		{Name: "extra_program_pages", Kind: parquet.Uint64, Optional: true},
+		{Name: "global_state", Kind: parquet.JSON, Optional: true},
+	},
+	// The local state columns are null for deleted local states.
+	AccountAppTable: {
+		{Name: "addr", Kind: parquet.String},
+		{Name: "app", Kind: parquet.Uint64},
+		{Name: "deleted", Kind: parquet.Boolean},
+		{Name: "created_at", Kind: parquet.Uint64},
+		{Name: "closed_at", Kind: parquet.Uint64, Optional: true},
+		{Name: "schema_num_uint", Kind: parquet.Uint64, Optional: true},
+		{Name: "schema_num_byte_slice", Kind: parquet.Uint64, Optional: true},
+		{Name: "key_value", Kind: parquet.JSON, Optional: true},
+	},
+}
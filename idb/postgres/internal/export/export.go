@@ -0,0 +1,224 @@
+// Package export writes the indexer tables to Parquet files for analytics.
+package export
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/algorand/indexer/parquet"
+)
+
+// DefaultRoundsPerFile is the default size of the round range stored in one
+// file of a partitioned table.
+const DefaultRoundsPerFile = 100000
+
+// Options configure what is exported and where.
+type Options struct {
+	// Dir is the output directory. Each table is written to its own
+	// subdirectory.
+	Dir string
+
+	// RoundsPerFile is the size of the round range stored in one file of a
+	// partitioned table.
+	RoundsPerFile uint64
+
+	// FirstRound and LastRound bound the exported rounds of the partitioned
+	// tables.
+	FirstRound uint64
+	LastRound  uint64
+
+	// SnapshotRound is the round of the database state, it names the
+	// snapshot files. LastRound may not be after it.
+	SnapshotRound uint64
+
+	// Codec is the Parquet compression codec.
+	Codec parquet.Codec
+}
+
+// Result lists the written files by table.
+type Result struct {
+	Files map[string][]string
+}
+
+const txnQuery = `SELECT t.round, t.intra, b.realtime, t.typeenum, t.asset, t.txid, t.txn, t.extra
+	FROM txn t JOIN block_header b ON t.round = b.round
+	WHERE t.round >= $1 AND t.round <= $2 ORDER BY t.round, t.intra`
+
+const participationQuery = `SELECT a.addr, p.round, p.intra
+	FROM txn_participation p JOIN address a ON p.addr_id = a.addr_id
+	WHERE p.round >= $1 AND p.round <= $2 ORDER BY p.round, p.intra, a.addr`
+
+var snapshotQueries = map[string]string{
+	AccountTable: `SELECT addr, microalgos, rewardsbase, rewards_total, deleted, created_at, closed_at, keytype, account_data
+		FROM account ORDER BY addr`,
+	AccountAssetTable: `SELECT a.addr, aa.assetid, aa.amount, aa.frozen, aa.deleted, aa.created_at, aa.closed_at
+		FROM account_asset aa JOIN address a ON aa.addr_id = a.addr_id ORDER BY a.addr, aa.assetid`,
+	AssetTable: `SELECT index, creator_addr, params, deleted, created_at, closed_at
+		FROM asset ORDER BY index`,
+	AppTable: `SELECT index, creator, params, deleted, created_at, closed_at
+		FROM app ORDER BY index`,
+	AccountAppTable: `SELECT app, addr, localstate, deleted, created_at, closed_at
+		FROM account_app ORDER BY addr, app`,
+}
+
+// Export writes the partitioned tables for the rounds
+// [opts.FirstRound, opts.LastRound] and a snapshot of the other tables. `tx`
+// must see the database state at opts.SnapshotRound for the whole export.
+func Export(ctx context.Context, tx pgx.Tx, opts Options) (Result, error) {
+	if opts.RoundsPerFile == 0 {
+		return Result{}, fmt.Errorf("Export() rounds per file must be positive")
+	}
+	if opts.FirstRound > opts.LastRound {
+		return Result{}, fmt.Errorf("Export() first round %d is after last round %d",
+			opts.FirstRound, opts.LastRound)
+	}
+	if opts.LastRound > opts.SnapshotRound {
+		return Result{}, fmt.Errorf("Export() last round %d is after the database round %d",
+			opts.LastRound, opts.SnapshotRound)
+	}
+
+	res := Result{Files: make(map[string][]string)}
+	for _, table := range PartitionedTables {
+		files, err := exportRange(ctx, tx, table, opts)
+		if err != nil {
+			return res, fmt.Errorf("Export() err: %w", err)
+		}
+		res.Files[table] = files
+	}
+	for _, table := range SnapshotTables {
+		path, err := exportSnapshot(ctx, tx, table, opts)
+		if err != nil {
+			return res, fmt.Errorf("Export() err: %w", err)
+		}
+		res.Files[table] = []string{path}
+	}
+	return res, nil
+}
+
+func exportRange(ctx context.Context, tx pgx.Tx, table string, opts Options) ([]string, error) {
+	query := txnQuery
+	if table == TxnParticipationTable {
+		query = participationQuery
+	}
+	rows, err := tx.Query(ctx, query, opts.FirstRound, opts.LastRound)
+	if err != nil {
+		return nil, fmt.Errorf("exportRange() %s query err: %w", table, err)
+	}
+	defer rows.Close()
+
+	w := rangeWriter{opts: opts, table: table}
+	for rows.Next() {
+		var round uint64
+		var record map[string]interface{}
+		if table == TxnParticipationTable {
+			var row participationRow
+			err = rows.Scan(&row.addr, &row.round, &row.intra)
+			round = row.round
+			record = row.record()
+		} else {
+			var row txnRow
+			err = rows.Scan(
+				&row.round, &row.intra, &row.roundTime, &row.typeenum, &row.asset, &row.txid,
+				&row.txn, &row.extra)
+			round = row.round
+			if err == nil {
+				record, err = row.record()
+			}
+		}
+		if err == nil {
+			err = w.append(round, record)
+		}
+		if err != nil {
+			w.abort()
+			return nil, fmt.Errorf("exportRange() %s err: %w", table, err)
+		}
+	}
+	err = rows.Err()
+	if err == nil {
+		err = w.close()
+	}
+	if err != nil {
+		w.abort()
+		return nil, fmt.Errorf("exportRange() %s err: %w", table, err)
+	}
+	return w.files, nil
+}
+
+func scanSnapshotRow(table string, rows pgx.Rows) (map[string]interface{}, error) {
+	switch table {
+	case AccountTable:
+		var row accountRow
+		err := rows.Scan(
+			&row.addr, &row.microalgos, &row.rewardsbase, &row.rewardsTotal, &row.deleted,
+			&row.createdAt, &row.closedAt, &row.keytype, &row.accountData)
+		if err != nil {
+			return nil, err
+		}
+		return row.record()
+	case AccountAssetTable:
+		var row accountAssetRow
+		err := rows.Scan(
+			&row.addr, &row.assetid, &row.amount, &row.frozen, &row.deleted, &row.createdAt,
+			&row.closedAt)
+		if err != nil {
+			return nil, err
+		}
+		return row.record(), nil
+	}
+
+	var row creatableRow
+	err := rows.Scan(&row.index, &row.addr, &row.params, &row.deleted, &row.createdAt, &row.closedAt)
+	if err != nil {
+		return nil, err
+	}
+	switch table {
+	case AssetTable:
+		return row.assetRecord()
+	case AppTable:
+		return row.appRecord()
+	}
+	return row.accountAppRecord()
+}
+
+func exportSnapshot(ctx context.Context, tx pgx.Tx, table string, opts Options) (string, error) {
+	rows, err := tx.Query(ctx, snapshotQueries[table])
+	if err != nil {
+		return "", fmt.Errorf("exportSnapshot() %s query err: %w", table, err)
+	}
+	defer rows.Close()
+
+	meta := map[string]string{
+		MetaTable: table,
+		MetaRound: strconv.FormatUint(opts.SnapshotRound, 10),
+	}
+	path := filepath.Join(opts.Dir, table, SnapshotFileName(table, opts.SnapshotRound))
+	f, err := createFile(path, table, opts.Codec, meta)
+	if err != nil {
+		return "", fmt.Errorf("exportSnapshot() %s err: %w", table, err)
+	}
+	for rows.Next() {
+		var record map[string]interface{}
+		record, err = scanSnapshotRow(table, rows)
+		if err == nil {
+			err = f.writer.Append(record)
+		}
+		if err != nil {
+			f.abort()
+			return "", fmt.Errorf("exportSnapshot() %s err: %w", table, err)
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		f.abort()
+		return "", fmt.Errorf("exportSnapshot() %s err: %w", table, err)
+	}
+	err = f.close()
+	if err != nil {
+		return "", fmt.Errorf("exportSnapshot() %s err: %w", table, err)
+	}
+	return path, nil
+}
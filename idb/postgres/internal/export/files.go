@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/algorand/indexer/parquet"
+)
+
+// Keys of the metadata stored in every exported file.
+const (
+	MetaTable      = "algorand.table"
+	MetaFirstRound = "algorand.first_round"
+	MetaLastRound  = "algorand.last_round"
+	MetaRound      = "algorand.round"
+)
+
+// RangeFileName returns the name of the file holding the rounds [first, last]
+// of a partitioned table. Zero padding keeps the lexicographic order equal to
+// the round order.
+func RangeFileName(table string, first, last uint64) string {
+	return fmt.Sprintf("%s_%020d_%020d.parquet", table, first, last)
+}
+
+// SnapshotFileName returns the name of the file holding a snapshot of a table
+// at a round.
+func SnapshotFileName(table string, round uint64) string {
+	return fmt.Sprintf("%s_%020d.parquet", table, round)
+}
+
+// file is a Parquet file written to a temporary name and renamed on close, so
+// that readers never see a partial file.
+type file struct {
+	path   string
+	file   *os.File
+	buf    *bufio.Writer
+	writer *parquet.Writer
+}
+
+func createFile(path, table string, codec parquet.Codec, meta map[string]string) (*file, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("createFile() err: %w", err)
+	}
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("createFile() err: %w", err)
+	}
+	buf := bufio.NewWriter(f)
+	writer, err := parquet.NewWriter(buf, Columns[table], codec, meta)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("createFile() err: %w", err)
+	}
+	return &file{path: path, file: f, buf: buf, writer: writer}, nil
+}
+
+func (f *file) close() error {
+	err := f.writer.Close()
+	if err == nil {
+		err = f.buf.Flush()
+	}
+	if err == nil {
+		err = f.file.Sync()
+	}
+	closeErr := f.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("close() %s err: %w", f.path, err)
+	}
+	err = os.Rename(f.file.Name(), f.path)
+	if err != nil {
+		return fmt.Errorf("close() err: %w", err)
+	}
+	return nil
+}
+
+func (f *file) abort() {
+	f.file.Close()
+	os.Remove(f.file.Name())
+}
+
+// rangeWriter writes the rows of a partitioned table to one file per round
+// range. Ranges are aligned to multiples of the range size and clipped to the
+// exported rounds, ranges without rows have no file. Rows must be appended in
+// increasing round order.
+type rangeWriter struct {
+	opts  Options
+	table string
+	files []string
+
+	current    *file
+	rangeStart uint64
+}
+
+func (w *rangeWriter) append(round uint64, record map[string]interface{}) error {
+	start := round - round%w.opts.RoundsPerFile
+	if w.current != nil && start != w.rangeStart {
+		err := w.close()
+		if err != nil {
+			return err
+		}
+	}
+	if w.current == nil {
+		first := start
+		if first < w.opts.FirstRound {
+			first = w.opts.FirstRound
+		}
+		last := start + w.opts.RoundsPerFile - 1
+		if last > w.opts.LastRound {
+			last = w.opts.LastRound
+		}
+		meta := map[string]string{
+			MetaTable:      w.table,
+			MetaFirstRound: strconv.FormatUint(first, 10),
+			MetaLastRound:  strconv.FormatUint(last, 10),
+		}
+		path := filepath.Join(w.opts.Dir, w.table, RangeFileName(w.table, first, last))
+		var err error
+		w.current, err = createFile(path, w.table, w.opts.Codec, meta)
+		if err != nil {
+			return fmt.Errorf("append() err: %w", err)
+		}
+		w.rangeStart = start
+	}
+	err := w.current.writer.Append(record)
+	if err != nil {
+		return fmt.Errorf("append() %s round %d err: %w", w.table, round, err)
+	}
+	return nil
+}
+
+// close publishes the open file, if any.
+func (w *rangeWriter) close() error {
+	if w.current == nil {
+		return nil
+	}
+	err := w.current.close()
+	if err != nil {
+		return err
+	}
+	w.files = append(w.files, w.current.path)
+	w.current = nil
+	return nil
+}
+
+// abort removes the open file, if any.
+func (w *rangeWriter) abort() {
+	if w.current != nil {
+		w.current.abort()
+		w.current = nil
+	}
+}
@@ -0,0 +1,356 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/postgres/internal/encoding"
+)
+
+// Same strings as the account status returned by the REST API.
+var statusStrings = []string{"Offline", "Online", "NotParticipating"}
+
+// txnRow is a row of the txn table joined with the block time.
+type txnRow struct {
+	round     uint64
+	intra     int
+	roundTime time.Time
+	typeenum  int
+	asset     uint64
+	txid      []byte
+	txn       []byte
+	extra     []byte
+}
+
+// participationRow is a row of the txn_participation table.
+type participationRow struct {
+	addr  []byte
+	round uint64
+	intra int
+}
+
+// accountRow is a row of the account table.
+type accountRow struct {
+	addr         []byte
+	microalgos   uint64
+	rewardsbase  uint64
+	rewardsTotal uint64
+	deleted      bool
+	createdAt    uint64
+	closedAt     *uint64
+	keytype      *string
+	accountData  []byte
+}
+
+// accountAssetRow is a row of the account_asset table.
+type accountAssetRow struct {
+	addr      []byte
+	assetid   uint64
+	amount    uint64
+	frozen    bool
+	deleted   bool
+	createdAt uint64
+	closedAt  *uint64
+}
+
+// creatableRow is a row of the asset, app or account_app tables. For
+// account_app, index is the app id and addr the account.
+type creatableRow struct {
+	index     uint64
+	addr      []byte
+	params    []byte
+	deleted   bool
+	createdAt uint64
+	closedAt  *uint64
+}
+
+func addrString(addr []byte) string {
+	var a basics.Address
+	copy(a[:], addr)
+	return a.String()
+}
+
+// optionalAddr returns nil for the zero address.
+func optionalAddr(a basics.Address) interface{} {
+	if a.IsZero() {
+		return nil
+	}
+	return a.String()
+}
+
+// optionalBytes returns nil for empty or all zero byte slices.
+func optionalBytes(b []byte) interface{} {
+	for _, x := range b {
+		if x != 0 {
+			return b
+		}
+	}
+	return nil
+}
+
+// optionalString returns nil for empty strings and strings that are not valid
+// UTF-8.
+func optionalString(s string) interface{} {
+	if s == "" || !utf8.ValidString(s) {
+		return nil
+	}
+	return s
+}
+
+// optionalUint64 converts a nullable database column.
+func optionalUint64(u *uint64) interface{} {
+	if u == nil {
+		return nil
+	}
+	return *u
+}
+
+func (r txnRow) record() (map[string]interface{}, error) {
+	stxn, err := encoding.DecodeSignedTxnWithAD(r.txn)
+	if err != nil {
+		return nil, fmt.Errorf("txn record() round %d intra %d err: %w", r.round, r.intra, err)
+	}
+	extra, err := encoding.DecodeTxnExtra(r.extra)
+	if err != nil {
+		return nil, fmt.Errorf("txn record() round %d intra %d extra err: %w", r.round, r.intra, err)
+	}
+	txn := &stxn.Txn
+
+	res := map[string]interface{}{
+		"round":            r.round,
+		"intra":            r.intra,
+		"round_time":       r.roundTime,
+		"typeenum":         r.typeenum,
+		"type":             string(txn.Type),
+		"asset":            r.asset,
+		"sender":           txn.Sender.String(),
+		"fee":              txn.Fee.Raw,
+		"first_valid":      uint64(txn.FirstValid),
+		"last_valid":       uint64(txn.LastValid),
+		"note":             optionalBytes(txn.Note),
+		"group":            optionalBytes(txn.Group[:]),
+		"lease":            optionalBytes(txn.Lease[:]),
+		"rekey_to":         optionalAddr(txn.RekeyTo),
+		"sender_rewards":   stxn.SenderRewards.Raw,
+		"receiver_rewards": stxn.ReceiverRewards.Raw,
+		"close_rewards":    stxn.CloseRewards.Raw,
+		"txn_json":         r.txn,
+	}
+	if r.txid != nil {
+		res["txid"] = string(r.txid)
+	}
+	if extra.RootIntra.Present {
+		// Inner transactions are not signed.
+		res["root_intra"] = extra.RootIntra.Value
+		res["root_txid"] = optionalString(extra.RootTxid)
+	} else if sigtype, err := idb.SignatureType(&stxn.SignedTxn); err == nil {
+		res["sig_type"] = string(sigtype)
+	}
+
+	switch txn.Type {
+	case protocol.PaymentTx:
+		res["receiver"] = txn.Receiver.String()
+		res["amount"] = txn.Amount.Raw
+		if !txn.CloseRemainderTo.IsZero() {
+			res["close_remainder_to"] = txn.CloseRemainderTo.String()
+			res["close_amount"] = stxn.ClosingAmount.Raw
+		}
+	case protocol.AssetTransferTx:
+		res["asset_receiver"] = txn.AssetReceiver.String()
+		res["asset_amount"] = txn.AssetAmount
+		res["asset_sender"] = optionalAddr(txn.AssetSender)
+		if !txn.AssetCloseTo.IsZero() {
+			res["asset_close_to"] = txn.AssetCloseTo.String()
+			res["asset_close_amount"] = extra.AssetCloseAmount
+		}
+	case protocol.ApplicationCallTx:
+		res["application_id"] = uint64(txn.ApplicationID)
+		res["on_completion"] = int(txn.OnCompletion)
+	}
+	return res, nil
+}
+
+func (r participationRow) record() map[string]interface{} {
+	return map[string]interface{}{
+		"addr":  addrString(r.addr),
+		"round": r.round,
+		"intra": r.intra,
+	}
+}
+
+func (r accountRow) record() (map[string]interface{}, error) {
+	res := map[string]interface{}{
+		"addr":          addrString(r.addr),
+		"microalgos":    r.microalgos,
+		"rewardsbase":   r.rewardsbase,
+		"rewards_total": r.rewardsTotal,
+		"deleted":       r.deleted,
+		"created_at":    r.createdAt,
+		"closed_at":     optionalUint64(r.closedAt),
+		"status":        statusStrings[0],
+	}
+	if r.keytype != nil && *r.keytype != "" {
+		res["keytype"] = *r.keytype
+	}
+
+	ad, err := encoding.DecodeTrimmedAccountData(r.accountData)
+	if err != nil {
+		return nil, fmt.Errorf("account record() %s err: %w", res["addr"], err)
+	}
+	if int(ad.Status) < len(statusStrings) {
+		res["status"] = statusStrings[ad.Status]
+	}
+	res["vote_id"] = optionalBytes(ad.VoteID[:])
+	res["selection_id"] = optionalBytes(ad.SelectionID[:])
+	res["vote_first_valid"] = uint64(ad.VoteFirstValid)
+	res["vote_last_valid"] = uint64(ad.VoteLastValid)
+	res["vote_key_dilution"] = ad.VoteKeyDilution
+	res["auth_addr"] = optionalAddr(ad.AuthAddr)
+	res["total_app_schema_num_uint"] = ad.TotalAppSchema.NumUint
+	res["total_app_schema_num_byte_slice"] = ad.TotalAppSchema.NumByteSlice
+	res["total_extra_app_pages"] = uint64(ad.TotalExtraAppPages)
+	return res, nil
+}
+
+func (r accountAssetRow) record() map[string]interface{} {
+	return map[string]interface{}{
+		"addr":       addrString(r.addr),
+		"assetid":    r.assetid,
+		"amount":     r.amount,
+		"frozen":     r.frozen,
+		"deleted":    r.deleted,
+		"created_at": r.createdAt,
+		"closed_at":  optionalUint64(r.closedAt),
+	}
+}
+
+func (r creatableRow) assetRecord() (map[string]interface{}, error) {
+	res := map[string]interface{}{
+		"index":        r.index,
+		"creator_addr": addrString(r.addr),
+		"deleted":      r.deleted,
+		"created_at":   r.createdAt,
+		"closed_at":    optionalUint64(r.closedAt),
+	}
+	if r.deleted {
+		return res, nil
+	}
+
+	params, err := encoding.DecodeAssetParams(r.params)
+	if err != nil {
+		return nil, fmt.Errorf("asset record() %d err: %w", r.index, err)
+	}
+	res["total"] = params.Total
+	res["decimals"] = params.Decimals
+	res["default_frozen"] = params.DefaultFrozen
+	res["unit_name"] = optionalString(params.UnitName)
+	res["asset_name"] = optionalString(params.AssetName)
+	res["url"] = optionalString(params.URL)
+	res["metadata_hash"] = optionalBytes(params.MetadataHash[:])
+	res["manager"] = optionalAddr(params.Manager)
+	res["reserve"] = optionalAddr(params.Reserve)
+	res["freeze"] = optionalAddr(params.Freeze)
+	res["clawback"] = optionalAddr(params.Clawback)
+	return res, nil
+}
+
+func (r creatableRow) appRecord() (map[string]interface{}, error) {
+	res := map[string]interface{}{
+		"index":      r.index,
+		"creator":    addrString(r.addr),
+		"deleted":    r.deleted,
+		"created_at": r.createdAt,
+		"closed_at":  optionalUint64(r.closedAt),
+	}
+	if r.deleted {
+		return res, nil
+	}
+
+	params, err := encoding.DecodeAppParams(r.params)
+	if err != nil {
+		return nil, fmt.Errorf("app record() %d err: %w", r.index, err)
+	}
+	res["approval_program"] = params.ApprovalProgram
+	res["clear_state_program"] = params.ClearStateProgram
+	res["global_state_schema_num_uint"] = params.GlobalStateSchema.NumUint
+	res["global_state_schema_num_byte_slice"] = params.GlobalStateSchema.NumByteSlice
+	res["local_state_schema_num_uint"] = params.LocalStateSchema.NumUint
+	res["local_state_schema_num_byte_slice"] = params.LocalStateSchema.NumByteSlice
+	res["extra_program_pages"] = uint64(params.ExtraProgramPages)
+	res["global_state"], err = keyValueJSON(params.GlobalState)
+	if err != nil {
+		return nil, fmt.Errorf("app record() %d err: %w", r.index, err)
+	}
+	return res, nil
+}
+
+func (r creatableRow) accountAppRecord() (map[string]interface{}, error) {
+	res := map[string]interface{}{
+		"addr":       addrString(r.addr),
+		"app":        r.index,
+		"deleted":    r.deleted,
+		"created_at": r.createdAt,
+		"closed_at":  optionalUint64(r.closedAt),
+	}
+	if r.deleted {
+		return res, nil
+	}
+
+	state, err := encoding.DecodeAppLocalState(r.params)
+	if err != nil {
+		return nil, fmt.Errorf("account_app record() %s %d err: %w", res["addr"], r.index, err)
+	}
+	res["schema_num_uint"] = state.Schema.NumUint
+	res["schema_num_byte_slice"] = state.Schema.NumByteSlice
+	res["key_value"], err = keyValueJSON(state.KeyValue)
+	if err != nil {
+		return nil, fmt.Errorf("account_app record() %s %d err: %w", res["addr"], r.index, err)
+	}
+	return res, nil
+}
+
+type tealValue struct {
+	Type  uint64 `json:"type"`
+	Bytes string `json:"bytes"`
+	Uint  uint64 `json:"uint"`
+}
+
+type tealKeyValue struct {
+	Key   string    `json:"key"`
+	Value tealValue `json:"value"`
+}
+
+// keyValueJSON encodes a key value store like the REST API does, sorted by
+// key.
+func keyValueJSON(tkv basics.TealKeyValue) (string, error) {
+	keys := make([]string, 0, len(tkv))
+	for k := range tkv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]tealKeyValue, 0, len(keys))
+	for _, k := range keys {
+		tv := tkv[k]
+		v := tealValue{Type: uint64(tv.Type)}
+		switch tv.Type {
+		case basics.TealBytesType:
+			v.Bytes = encoding.Base64([]byte(tv.Bytes))
+		case basics.TealUintType:
+			v.Uint = tv.Uint
+		}
+		out = append(out, tealKeyValue{Key: encoding.Base64([]byte(k)), Value: v})
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,187 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/postgres/internal/encoding"
+	"github.com/algorand/indexer/parquet"
+)
+
+func TestRecordsMatchColumns(t *testing.T) {
+	sender := basics.Address{1}
+	receiver := basics.Address{2}
+	var stxn transactions.SignedTxnWithAD
+	stxn.Sig = crypto.Signature{3}
+	stxn.Txn.Type = protocol.PaymentTx
+	stxn.Txn.Sender = sender
+	stxn.Txn.Receiver = receiver
+	stxn.Txn.Amount = basics.MicroAlgos{Raw: 5}
+	stxn.Txn.Fee = basics.MicroAlgos{Raw: 1000}
+	stxn.Txn.FirstValid = 10
+	stxn.Txn.LastValid = 20
+
+	txn := txnRow{
+		round:     12,
+		intra:     3,
+		roundTime: time.Unix(1600000000, 0).UTC(),
+		typeenum:  int(idb.TypeEnumPay),
+		txid:      []byte("TXID"),
+		txn:       encoding.EncodeSignedTxnWithAD(stxn),
+		extra:     encoding.EncodeTxnExtra(&idb.TxnExtra{}),
+	}
+	record, err := txn.record()
+	require.NoError(t, err)
+	assert.Equal(t, "pay", record["type"])
+	assert.Equal(t, "TXID", record["txid"])
+	assert.Equal(t, "sig", record["sig_type"])
+	assert.Equal(t, sender.String(), record["sender"])
+	assert.Equal(t, receiver.String(), record["receiver"])
+	assert.Equal(t, uint64(5), record["amount"])
+	assert.Nil(t, record["close_remainder_to"])
+	assert.Nil(t, record["note"])
+	assert.Nil(t, record["asset_amount"])
+
+	// Every record must be accepted by the writer of its table.
+	records := map[string]map[string]interface{}{TxnTable: record}
+	records[TxnParticipationTable] = participationRow{addr: sender[:], round: 12, intra: 3}.record()
+	records[AccountTable], err = accountRow{
+		addr:        sender[:],
+		accountData: encoding.EncodeTrimmedAccountData(basics.AccountData{Status: basics.Online}),
+	}.record()
+	require.NoError(t, err)
+	records[AccountAssetTable] = accountAssetRow{addr: sender[:], assetid: 7, amount: 1 << 63}.record()
+	records[AssetTable], err = creatableRow{
+		index:  7,
+		addr:   sender[:],
+		params: encoding.EncodeAssetParams(basics.AssetParams{Total: 100, UnitName: "unit"}),
+	}.assetRecord()
+	require.NoError(t, err)
+	records[AppTable], err = creatableRow{
+		index:  8,
+		addr:   sender[:],
+		params: encoding.EncodeAppParams(basics.AppParams{ApprovalProgram: []byte{1}}),
+	}.appRecord()
+	require.NoError(t, err)
+	records[AccountAppTable], err = creatableRow{
+		index:  8,
+		addr:   receiver[:],
+		params: encoding.EncodeAppLocalState(basics.AppLocalState{}),
+	}.accountAppRecord()
+	require.NoError(t, err)
+
+	assert.Equal(t, "Online", records[AccountTable]["status"])
+	for table, columns := range Columns {
+		w, err := parquet.NewWriter(ioutil.Discard, columns, parquet.CodecUncompressed, nil)
+		require.NoError(t, err)
+		assert.NoError(t, w.Append(records[table]), table)
+	}
+}
+
+func TestInnerTxnRecord(t *testing.T) {
+	var stxn transactions.SignedTxnWithAD
+	stxn.Txn.Type = protocol.AssetTransferTx
+	stxn.Txn.XferAsset = 9
+	stxn.Txn.AssetAmount = 4
+	stxn.Txn.AssetCloseTo = basics.Address{5}
+
+	txn := txnRow{
+		round: 12,
+		intra: 4,
+		txn:   encoding.EncodeSignedTxnWithAD(stxn),
+		extra: encoding.EncodeTxnExtra(&idb.TxnExtra{
+			AssetCloseAmount: 6,
+			RootIntra:        idb.OptionalUint{Present: true, Value: 3},
+			RootTxid:         "ROOT",
+		}),
+	}
+	record, err := txn.record()
+	require.NoError(t, err)
+	assert.Nil(t, record["txid"])
+	assert.Nil(t, record["sig_type"])
+	assert.Equal(t, uint(3), record["root_intra"])
+	assert.Equal(t, "ROOT", record["root_txid"])
+	assert.Equal(t, uint64(4), record["asset_amount"])
+	assert.Equal(t, uint64(6), record["asset_close_amount"])
+}
+
+func TestAssetRecord(t *testing.T) {
+	params := basics.AssetParams{
+		Total:     1,
+		AssetName: string([]byte{0xff, 0xfe}),
+		Manager:   basics.Address{1},
+	}
+	record, err := creatableRow{index: 1, params: encoding.EncodeAssetParams(params)}.assetRecord()
+	require.NoError(t, err)
+	assert.Nil(t, record["asset_name"], "invalid UTF-8 is exported as null")
+	assert.Equal(t, params.Manager.String(), record["manager"])
+	assert.Nil(t, record["reserve"])
+
+	// Deleted assets have no params.
+	closed := uint64(5)
+	record, err = creatableRow{index: 1, params: []byte("null"), deleted: true, closedAt: &closed}.assetRecord()
+	require.NoError(t, err)
+	assert.Nil(t, record["total"])
+	assert.Equal(t, uint64(5), record["closed_at"])
+}
+
+func TestKeyValueJSON(t *testing.T) {
+	tkv := basics.TealKeyValue{
+		"b": {Type: basics.TealUintType, Uint: 2},
+		"a": {Type: basics.TealBytesType, Bytes: "x"},
+	}
+	js, err := keyValueJSON(tkv)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`[{"key":"YQ==","value":{"type":1,"bytes":"eA==","uint":0}},`+
+			`{"key":"Yg==","value":{"type":2,"bytes":"","uint":2}}]`,
+		js)
+
+	js, err = keyValueJSON(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", js)
+}
+
+func TestRangeWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := Options{Dir: dir, RoundsPerFile: 10, FirstRound: 5, LastRound: 34}
+	w := rangeWriter{opts: opts, table: TxnParticipationTable}
+	for _, round := range []uint64{5, 9, 25, 34} {
+		record := participationRow{addr: make([]byte, 32), round: round}.record()
+		require.NoError(t, w.append(round, record))
+	}
+	require.NoError(t, w.close())
+
+	// Ranges are clipped to the exported rounds and empty ranges are skipped.
+	tableDir := filepath.Join(dir, TxnParticipationTable)
+	expected := []string{
+		filepath.Join(tableDir, RangeFileName(TxnParticipationTable, 5, 9)),
+		filepath.Join(tableDir, RangeFileName(TxnParticipationTable, 20, 29)),
+		filepath.Join(tableDir, RangeFileName(TxnParticipationTable, 30, 34)),
+	}
+	assert.Equal(t, expected, w.files)
+
+	f, err := parquet.ReadFile(expected[0])
+	require.NoError(t, err)
+	assert.Len(t, f.Rows, 2)
+	assert.Equal(t, "5", f.Metadata[MetaFirstRound])
+	assert.Equal(t, "9", f.Metadata[MetaLastRound])
+
+	entries, err := ioutil.ReadDir(tableDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "no temporary files are left")
+}
@@ -34,7 +34,7 @@ var statements = map[string]string{
 	accountStmtName: "SELECT microalgos, rewardsbase, rewards_total, account_data " +
 		"FROM account WHERE addr = $1 AND NOT deleted",
 	assetHoldingsStmtName: "SELECT assetid, amount, frozen FROM account_asset " +
-		"WHERE addr = $1 AND NOT deleted",
+		"WHERE addr_id = (SELECT addr_id FROM address WHERE addr = $1) AND NOT deleted",
 	assetParamsStmtName: "SELECT index, params FROM asset " +
 		"WHERE creator_addr = $1 AND NOT deleted",
 	appParamsStmtName: "SELECT index, params FROM app WHERE creator = $1 AND NOT deleted",
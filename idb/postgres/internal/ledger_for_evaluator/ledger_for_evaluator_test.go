@@ -288,8 +288,8 @@ func TestLedgerForEvaluatorAccountAssetTable(t *testing.T) {
 	require.NoError(t, err)
 
 	query =
-		"INSERT INTO account_asset (addr, assetid, amount, frozen, deleted, created_at) " +
-			"VALUES ($1, $2, $3, $4, $5, 0)"
+		"INSERT INTO account_asset (addr_id, assetid, amount, frozen, deleted, created_at) " +
+			"VALUES (address_id($1), $2, $3, $4, $5, 0)"
 	_, err = db.Exec(context.Background(), query, test.AccountA[:], 1, 2, false, false)
 	require.NoError(t, err)
 	_, err = db.Exec(context.Background(), query, test.AccountA[:], 3, 4, true, false)
@@ -529,8 +529,8 @@ func TestLedgerForEvaluatorLookupMultipleAccounts(t *testing.T) {
 		(addr, microalgos, rewardsbase, rewards_total, deleted, created_at, account_data)
 		VALUES ($1, 0, 0, 0, false, 0, 'null'::jsonb)`
 	addAccountAssetQuery :=
-		"INSERT INTO account_asset (addr, assetid, amount, frozen, deleted, created_at) " +
-			"VALUES ($1, $2, 0, false, false, 0)"
+		"INSERT INTO account_asset (addr_id, assetid, amount, frozen, deleted, created_at) " +
+			"VALUES (address_id($1), $2, 0, false, false, 0)"
 	addAssetQuery :=
 		"INSERT INTO asset (index, creator_addr, params, deleted, created_at) " +
 			"VALUES ($1, $2, '{}', false, 0)"
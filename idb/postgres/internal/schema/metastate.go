@@ -6,4 +6,14 @@ const (
 	MigrationMetastateKey       = "migration"
 	SpecialAccountsMetastateKey = "accounts"
 	AccountTotals               = "totals"
+	// The number of rounds of each partition of the txn and txn_participation
+	// tables, not set if they are not partitioned.
+	PartitionRoundsMetastateKey = "partition_rounds"
+	// The oldest round with transactions, not set unless transactions were
+	// pruned.
+	OldestRoundMetastateKey = "oldest_round"
+	// The checkpoint of an exporter is stored under the prefix and its name.
+	ExportCheckpointMetastateKeyPrefix = "export/"
+	// A webhook is stored under the prefix and its id.
+	WebhookMetastateKeyPrefix = "webhook/"
 )
@@ -1,6 +1,6 @@
 -- This file is setup_postgres.sql which gets compiled into go source using a go:generate statement in postgres.go
 --
--- TODO? replace all 'addr bytea' with 'addr_id bigint' and a mapping table? makes addrs an 8 byte int that fits in a register instead of a 32 byte string
+-- TODO? replace the remaining 'addr bytea' columns with 'addr_id bigint' like txn_participation and account_asset
 
 CREATE TABLE IF NOT EXISTS block_header (
   round bigint PRIMARY KEY,
@@ -21,23 +21,54 @@ CREATE TABLE IF NOT EXISTS txn (
   txid bytea, -- base32 of [32]byte hash, or NULL for inner transactions.
   txn jsonb NOT NULL, -- json encoding of signed txn with apply data; inner txns exclude nested inner txns
   extra jsonb NOT NULL,
+  txgroup bytea, -- group id, or NULL if not in a group
+  lease bytea, -- lease, or NULL if empty
   PRIMARY KEY ( round, intra )
 );
 
 -- For transaction lookup
 CREATE INDEX IF NOT EXISTS txn_by_tixid ON txn ( txid );
 
+-- For group and lease lookup
+CREATE INDEX IF NOT EXISTS txn_by_group ON txn ( txgroup ) WHERE txgroup IS NOT NULL;
+CREATE INDEX IF NOT EXISTS txn_by_lease ON txn ( lease ) WHERE lease IS NOT NULL;
+
 -- Optional, to make txn queries by asset fast:
 -- CREATE INDEX CONCURRENTLY IF NOT EXISTS txn_asset ON txn (asset, round, intra);
 
+-- Maps addresses to 8 byte ids, which keep the biggest tables small. Ids are never
+-- removed, an id added by a round that is rolled back stays unused.
+CREATE TABLE IF NOT EXISTS address (
+  addr_id bigserial PRIMARY KEY,
+  addr bytea NOT NULL -- [32]byte
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS address_addr ON address ( addr );
+
+-- Returns the id of an address, adding it if it is new.
+CREATE OR REPLACE FUNCTION address_id(a bytea) RETURNS bigint AS $$
+DECLARE
+  id bigint;
+BEGIN
+  SELECT addr_id INTO id FROM address WHERE addr = a;
+  IF id IS NULL THEN
+    INSERT INTO address (addr) VALUES (a) RETURNING addr_id INTO id;
+  END IF;
+  RETURN id;
+END;
+$$ LANGUAGE plpgsql;
+
 CREATE TABLE IF NOT EXISTS txn_participation (
-  addr bytea NOT NULL,
+  addr_id bigint NOT NULL, -- address.addr_id
   round bigint NOT NULL,
   intra integer NOT NULL
 );
 
 -- For query account transactions
-CREATE UNIQUE INDEX IF NOT EXISTS txn_participation_i ON txn_participation ( addr, round DESC, intra DESC );
+CREATE UNIQUE INDEX IF NOT EXISTS txn_participation_i ON txn_participation ( addr_id, round DESC, intra DESC );
+
+-- For pruning old rounds, the rows are appended in round order
+CREATE INDEX IF NOT EXISTS txn_participation_round ON txn_participation USING brin ( round );
 
 -- expand data.basics.AccountData
 CREATE TABLE IF NOT EXISTS account (
@@ -54,21 +85,21 @@ CREATE TABLE IF NOT EXISTS account (
 
 -- data.basics.AccountData Assets[asset id] AssetHolding{}
 CREATE TABLE IF NOT EXISTS account_asset (
-  addr bytea NOT NULL, -- [32]byte
+  addr_id bigint NOT NULL, -- address.addr_id
   assetid bigint NOT NULL,
   amount numeric(20) NOT NULL, -- need the full 18446744073709551615
   frozen boolean NOT NULL,
   deleted bool NOT NULL, -- whether or not it is currently deleted
   created_at bigint NOT NULL, -- round that the asset was added to an account
   closed_at bigint, -- round that the asset was last removed from the account
-  PRIMARY KEY (addr, assetid)
+  PRIMARY KEY (addr_id, assetid)
 );
 
 -- For lookup up existing assets by account
-CREATE INDEX IF NOT EXISTS account_asset_by_addr_partial ON account_asset(addr) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS account_asset_by_addr_partial ON account_asset(addr_id) WHERE NOT deleted;
 
--- Optional, to make queries of all asset balances fast /v2/assets/<assetid>/balances
--- CREATE INDEX CONCURRENTLY IF NOT EXISTS account_asset_asset ON account_asset (assetid, addr ASC);
+-- For paging the asset balances /v2/assets/<assetid>/balances in addr_id order
+CREATE INDEX IF NOT EXISTS account_asset_asset ON account_asset (assetid, addr_id ASC);
 
 -- data.basics.AccountData AssetParams[index] AssetParams{}
 CREATE TABLE IF NOT EXISTS asset (
@@ -117,3 +148,45 @@ CREATE TABLE IF NOT EXISTS account_app (
 
 -- For looking up existing app local states by account
 CREATE INDEX IF NOT EXISTS account_app_by_addr_partial ON account_app(addr) WHERE NOT deleted;
+
+-- Undo records of the state tables, for rolling back the most recent rounds. The
+-- trigger below records the rows changed by the round set in 'indexer.undo_round',
+-- nothing is recorded if it is not set.
+CREATE TABLE IF NOT EXISTS state_undo (
+  seq bigserial PRIMARY KEY,
+  round bigint NOT NULL,
+  tbl text NOT NULL, -- name of the changed table
+  op char(1) NOT NULL, -- 'I', 'U' or 'D'
+  row_data text NOT NULL -- text of the inserted row for 'I', of the previous row otherwise
+);
+
+CREATE INDEX IF NOT EXISTS state_undo_round ON state_undo (round);
+
+CREATE OR REPLACE FUNCTION state_undo_log() RETURNS trigger AS $$
+DECLARE
+  undo_round text := current_setting('indexer.undo_round', true);
+BEGIN
+  IF undo_round IS NULL OR undo_round = '' THEN
+    RETURN NULL;
+  END IF;
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO state_undo (round, tbl, op, row_data) VALUES (undo_round::bigint, TG_TABLE_NAME, 'I', NEW::text);
+  ELSE
+    INSERT INTO state_undo (round, tbl, op, row_data) VALUES (undo_round::bigint, TG_TABLE_NAME, left(TG_OP, 1), OLD::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS account_undo ON account;
+CREATE TRIGGER account_undo AFTER INSERT OR UPDATE OR DELETE ON account FOR EACH ROW EXECUTE PROCEDURE state_undo_log();
+DROP TRIGGER IF EXISTS account_asset_undo ON account_asset;
+CREATE TRIGGER account_asset_undo AFTER INSERT OR UPDATE OR DELETE ON account_asset FOR EACH ROW EXECUTE PROCEDURE state_undo_log();
+DROP TRIGGER IF EXISTS asset_undo ON asset;
+CREATE TRIGGER asset_undo AFTER INSERT OR UPDATE OR DELETE ON asset FOR EACH ROW EXECUTE PROCEDURE state_undo_log();
+DROP TRIGGER IF EXISTS metastate_undo ON metastate;
+CREATE TRIGGER metastate_undo AFTER INSERT OR UPDATE OR DELETE ON metastate FOR EACH ROW EXECUTE PROCEDURE state_undo_log();
+DROP TRIGGER IF EXISTS app_undo ON app;
+CREATE TRIGGER app_undo AFTER INSERT OR UPDATE OR DELETE ON app FOR EACH ROW EXECUTE PROCEDURE state_undo_log();
+DROP TRIGGER IF EXISTS account_app_undo ON account_app;
+CREATE TRIGGER account_app_undo AFTER INSERT OR UPDATE OR DELETE ON account_app FOR EACH ROW EXECUTE PROCEDURE state_undo_log();
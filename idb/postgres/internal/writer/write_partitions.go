@@ -0,0 +1,34 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// PartitionedTables are the tables that can be partitioned by round range.
+var PartitionedTables = []string{"txn", "txn_participation"}
+
+// PartitionStart returns the first round of the partition holding `round`.
+func PartitionStart(round uint64, partitionRounds uint64) uint64 {
+	return round - round%partitionRounds
+}
+
+// AddPartitions creates the partitions of the partitioned tables for the rounds
+// [start, start+partitionRounds) unless they exist. A partition is named after
+// its table and first round, e.g. txn_1000000.
+func AddPartitions(tx pgx.Tx, start uint64, partitionRounds uint64) error {
+	for _, table := range PartitionedTables {
+		query := fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %[1]s_%[2]d PARTITION OF %[1]s "+
+				"FOR VALUES FROM (%[2]d) TO (%[3]d)",
+			table, start, start+partitionRounds)
+		_, err := tx.Exec(context.Background(), query)
+		if err != nil {
+			return fmt.Errorf("AddPartitions() create %s partition err: %w", table, err)
+		}
+	}
+
+	return nil
+}
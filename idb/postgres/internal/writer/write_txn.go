@@ -6,57 +6,19 @@ import (
 
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/transactions"
-	"github.com/algorand/go-algorand/protocol"
 	"github.com/jackc/pgx/v4"
 
 	"github.com/algorand/indexer/idb"
 	"github.com/algorand/indexer/idb/postgres/internal/encoding"
 )
 
-// Get the ID of the creatable referenced in the given transaction
-// (0 if not an asset or app transaction).
-// Note: ConsensusParams.MaxInnerTransactions could be overridden to force
-//       generating ApplyData.{ApplicationID/ConfigAsset}. This function does
-//       other things too, so it is not clear we should use it. The only
-//       real benefit is that it would slightly simplify this function by
-//       allowing us to leave out the intra / block parameters.
-func transactionAssetID(stxnad *transactions.SignedTxnWithAD, intra uint, block *bookkeeping.Block) (uint64, error) {
-	assetid := uint64(0)
-
-	switch stxnad.Txn.Type {
-	case protocol.ApplicationCallTx:
-		assetid = uint64(stxnad.Txn.ApplicationID)
-		if assetid == 0 {
-			assetid = uint64(stxnad.ApplyData.ApplicationID)
-		}
-		if assetid == 0 {
-			if block == nil {
-				return 0, fmt.Errorf("transactionAssetID(): Missing ApplicationID for transaction: %s", stxnad.ID())
-			}
-			// pre v30 transactions do not have ApplyData.ConfigAsset or InnerTxns
-			// so txn counter + payset pos calculation is OK
-			assetid = block.TxnCounter - uint64(len(block.Payset)) + uint64(intra) + 1
-		}
-	case protocol.AssetConfigTx:
-		assetid = uint64(stxnad.Txn.ConfigAsset)
-		if assetid == 0 {
-			assetid = uint64(stxnad.ApplyData.ConfigAsset)
-		}
-		if assetid == 0 {
-			if block == nil {
-				return 0, fmt.Errorf("transactionAssetID(): Missing ConfigAsset for transaction: %s", stxnad.ID())
-			}
-			// pre v30 transactions do not have ApplyData.ApplicationID or InnerTxns
-			// so txn counter + payset pos calculation is OK
-			assetid = block.TxnCounter - uint64(len(block.Payset)) + uint64(intra) + 1
-		}
-	case protocol.AssetTransferTx:
-		assetid = uint64(stxnad.Txn.XferAsset)
-	case protocol.AssetFreezeTx:
-		assetid = uint64(stxnad.Txn.FreezeAsset)
+// NullIfZeroDigest returns nil for the zero group id or lease, so that the
+// column is NULL.
+func NullIfZeroDigest(d [32]byte) interface{} {
+	if d == ([32]byte{}) {
+		return nil
 	}
-
-	return assetid, nil
+	return d[:]
 }
 
 // Traverses the inner transaction tree and writes database rows
@@ -70,7 +32,7 @@ func yieldInnerTransactions(ctx context.Context, stxnad *transactions.SignedTxnW
 			return 0, fmt.Errorf("yieldInnerTransactions() get type enum")
 		}
 		// block shouldn't be used for inner transactions.
-		assetid, err := transactionAssetID(&itxn, 0, nil)
+		assetid, err := idb.TransactionAssetID(&itxn, 0, nil)
 		if err != nil {
 			return 0, err
 		}
@@ -88,7 +50,8 @@ func yieldInnerTransactions(ctx context.Context, stxnad *transactions.SignedTxnW
 			uint64(block.Round()), intra, int(typeenum), assetid,
 			nil, // inner transactions do not have a txid.
 			encoding.EncodeSignedTxnWithAD(txnNoInner),
-			encoding.EncodeTxnExtra(&extra)}
+			encoding.EncodeTxnExtra(&extra),
+			NullIfZeroDigest(txn.Group), NullIfZeroDigest(txn.Lease)}
 		select {
 		case <-ctx.Done():
 			return 0, fmt.Errorf("yieldInnerTransactions() ctx.Err(): %w", ctx.Err())
@@ -124,7 +87,7 @@ func yieldTransactions(ctx context.Context, block *bookkeeping.Block, modifiedTx
 		if !ok {
 			return fmt.Errorf("yieldTransactions() get type enum")
 		}
-		assetid, err := transactionAssetID(&stxnad, intra, block)
+		assetid, err := idb.TransactionAssetID(&stxnad, intra, block)
 		if err != nil {
 			return err
 		}
@@ -136,7 +99,8 @@ func yieldTransactions(ctx context.Context, block *bookkeeping.Block, modifiedTx
 		row := []interface{}{
 			uint64(block.Round()), intra, int(typeenum), assetid, id,
 			encoding.EncodeSignedTxnWithAD(stxnad),
-			encoding.EncodeTxnExtra(&extra)}
+			encoding.EncodeTxnExtra(&extra),
+			NullIfZeroDigest(txn.Group), NullIfZeroDigest(txn.Lease)}
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("yieldTransactions() ctx.Err(): %w", ctx.Err())
@@ -169,7 +133,7 @@ func AddTransactions(block *bookkeeping.Block, modifiedTxns []transactions.Signe
 	_, err1 := tx.CopyFrom(
 		context.Background(),
 		pgx.Identifier{"txn"},
-		[]string{"round", "intra", "typeenum", "asset", "txid", "txn", "extra"},
+		[]string{"round", "intra", "typeenum", "asset", "txid", "txn", "extra", "txgroup", "lease"},
 		copyFromChannel(ch))
 	if err1 != nil {
 		// Exiting here will call `cancelFunc` which will cause the goroutine above to exit.
@@ -51,6 +51,43 @@ func getTransactionParticipants(stxnad *transactions.SignedTxnWithAD, includeInn
 	return res
 }
 
+// addressIDs returns the ids of `addresses` in the `address` table, the new
+// addresses are added. Two transactions adding the same address conflict, so
+// only one transaction at a time may add addresses.
+func addressIDs(tx pgx.Tx, addresses map[basics.Address]struct{}) (map[basics.Address]int64, error) {
+	addrs := make([][]byte, 0, len(addresses))
+	for address := range addresses {
+		address := address
+		addrs = append(addrs, address[:])
+	}
+
+	rows, err := tx.Query(
+		context.Background(), "SELECT a, address_id(a) FROM unnest($1::bytea[]) a", addrs)
+	if err != nil {
+		return nil, fmt.Errorf("addressIDs() query err: %w", err)
+	}
+	defer rows.Close()
+
+	res := make(map[basics.Address]int64, len(addrs))
+	for rows.Next() {
+		var addr []byte
+		var id int64
+		err = rows.Scan(&addr, &id)
+		if err != nil {
+			return nil, fmt.Errorf("addressIDs() scan err: %w", err)
+		}
+		var address basics.Address
+		copy(address[:], addr)
+		res[address] = id
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("addressIDs() rows err: %w", err)
+	}
+
+	return res, nil
+}
+
 // addInnerTransactionParticipation traverses the inner transaction tree and
 // adds txn participation records for each. It performs a preorder traversal
 // to correctly compute the intra round offset, the offset for the next
@@ -63,7 +100,7 @@ func addInnerTransactionParticipation(stxnad *transactions.SignedTxnWithAD, roun
 		participants := getTransactionParticipants(&itxn, false)
 
 		for j := range participants {
-			rows = append(rows, []interface{}{participants[j][:], round, next})
+			rows = append(rows, []interface{}{participants[j], round, next})
 		}
 
 		next, rows = addInnerTransactionParticipation(&itxn, round, next+1, rows)
@@ -72,9 +109,29 @@ func addInnerTransactionParticipation(stxnad *transactions.SignedTxnWithAD, roun
 
 }
 
+// AddBlockAddresses adds the participants of the transactions of `block` to
+// the `address` table and returns their ids. It is committed before the
+// transaction participation and the accounting state are written in parallel,
+// so that these transactions do not add the same addresses.
+func AddBlockAddresses(block *bookkeeping.Block, tx pgx.Tx) (map[basics.Address]int64, error) {
+	addresses := make(map[basics.Address]struct{})
+	for _, stxnib := range block.Payset {
+		for _, address := range getTransactionParticipants(&stxnib.SignedTxnWithAD, true) {
+			addresses[address] = struct{}{}
+		}
+	}
+
+	ids, err := addressIDs(tx, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("AddBlockAddresses() err: %w", err)
+	}
+	return ids, nil
+}
+
 // AddTransactionParticipation writes account participation info to the
-// `txn_participation` table.
-func AddTransactionParticipation(block *bookkeeping.Block, tx pgx.Tx) error {
+// `txn_participation` table. `ids` are the address ids returned by
+// AddBlockAddresses().
+func AddTransactionParticipation(block *bookkeeping.Block, ids map[basics.Address]int64, tx pgx.Tx) error {
 	var rows [][]interface{}
 	next := uint64(0)
 
@@ -82,16 +139,27 @@ func AddTransactionParticipation(block *bookkeeping.Block, tx pgx.Tx) error {
 		participants := getTransactionParticipants(&stxnib.SignedTxnWithAD, true)
 
 		for j := range participants {
-			rows = append(rows, []interface{}{participants[j][:], uint64(block.Round()), next})
+			rows = append(rows, []interface{}{participants[j], uint64(block.Round()), next})
 		}
 
 		next, rows = addInnerTransactionParticipation(&stxnib.SignedTxnWithAD, uint64(block.Round()), next+1, rows)
 	}
 
+	// Replace the addresses with their ids.
+	for _, row := range rows {
+		address := row[0].(basics.Address)
+		id, ok := ids[address]
+		if !ok {
+			return fmt.Errorf(
+				"addTransactionParticipation() missing address id of %s", address.String())
+		}
+		row[0] = id
+	}
+
 	_, err := tx.CopyFrom(
 		context.Background(),
 		pgx.Identifier{"txn_participation"},
-		[]string{"addr", "round", "intra"},
+		[]string{"addr_id", "round", "intra"},
 		pgx.CopyFromRows(rows))
 	if err != nil {
 		return fmt.Errorf("addTransactionParticipation() copy from err: %w", err)
@@ -47,8 +47,8 @@ var statements = map[string]string{
 		VALUES($1, $2, $3, FALSE, $4) ON CONFLICT (index) DO UPDATE SET
 		creator_addr = EXCLUDED.creator_addr, params = EXCLUDED.params, deleted = FALSE`,
 	upsertAccountAssetStmtName: `INSERT INTO account_asset
-		(addr, assetid, amount, frozen, deleted, created_at)
-		VALUES($1, $2, $3, $4, FALSE, $5) ON CONFLICT (addr, assetid) DO UPDATE SET
+		(addr_id, assetid, amount, frozen, deleted, created_at)
+		VALUES($1, $2, $3, $4, FALSE, $5) ON CONFLICT (addr_id, assetid) DO UPDATE SET
 		amount = EXCLUDED.amount, frozen = EXCLUDED.frozen, deleted = FALSE`,
 	upsertAppStmtName: `INSERT INTO app
 		(index, creator, params, deleted, created_at)
@@ -92,8 +92,8 @@ var statements = map[string]string{
 		creator_addr = EXCLUDED.creator_addr, params = EXCLUDED.params, deleted = TRUE,
 		closed_at = EXCLUDED.closed_at`,
 	deleteAccountAssetStmtName: `INSERT INTO account_asset
-		(addr, assetid, amount, frozen, deleted, created_at, closed_at)
-		VALUES($1, $2, 0, false, TRUE, $3, $3) ON CONFLICT (addr, assetid) DO UPDATE SET
+		(addr_id, assetid, amount, frozen, deleted, created_at, closed_at)
+		VALUES($1, $2, 0, false, TRUE, $3, $3) ON CONFLICT (addr_id, assetid) DO UPDATE SET
 		amount = EXCLUDED.amount, deleted = TRUE, closed_at = EXCLUDED.closed_at`,
 	deleteAppStmtName: `INSERT INTO app
 		(index, creator, params, deleted, created_at, closed_at)
@@ -178,7 +178,7 @@ type optionalSigTypeDelta struct {
 	value   sigTypeDelta
 }
 
-func writeAccount(round basics.Round, address basics.Address, accountData basics.AccountData, sigtypeDelta optionalSigTypeDelta, batch *pgx.Batch) {
+func writeAccount(round basics.Round, address basics.Address, accountData basics.AccountData, sigtypeDelta optionalSigTypeDelta, ids map[basics.Address]int64, batch *pgx.Batch) {
 	// Update `asset` table.
 	for assetid, params := range accountData.AssetParams {
 		batch.Queue(
@@ -190,7 +190,7 @@ func writeAccount(round basics.Round, address basics.Address, accountData basics
 	for assetid, holding := range accountData.Assets {
 		batch.Queue(
 			upsertAccountAssetStmtName,
-			address[:], uint64(assetid), strconv.FormatUint(holding.Amount, 10),
+			ids[address], uint64(assetid), strconv.FormatUint(holding.Amount, 10),
 			holding.Frozen, uint64(round))
 	}
 
@@ -249,7 +249,7 @@ func writeAccount(round basics.Round, address basics.Address, accountData basics
 	}
 }
 
-func writeAccounts(round basics.Round, accountDeltas ledgercore.AccountDeltas, sigtypeDeltas map[basics.Address]sigTypeDelta, batch *pgx.Batch) {
+func writeAccounts(round basics.Round, accountDeltas ledgercore.AccountDeltas, sigtypeDeltas map[basics.Address]sigTypeDelta, ids map[basics.Address]int64, batch *pgx.Batch) {
 	// Update `account` table.
 	for i := 0; i < accountDeltas.Len(); i++ {
 		address, accountData := accountDeltas.GetByIdx(i)
@@ -257,7 +257,7 @@ func writeAccounts(round basics.Round, accountDeltas ledgercore.AccountDeltas, s
 		var sigtypeDelta optionalSigTypeDelta
 		sigtypeDelta.value, sigtypeDelta.present = sigtypeDeltas[address]
 
-		writeAccount(round, address, accountData, sigtypeDelta, batch)
+		writeAccount(round, address, accountData, sigtypeDelta, ids, batch)
 	}
 }
 
@@ -277,18 +277,45 @@ func writeDeletedCreatables(round basics.Round, creatables map[basics.CreatableI
 	}
 }
 
-func writeDeletedAssetHoldings(round basics.Round, modifiedAssetHoldings map[ledgercore.AccountAsset]bool, batch *pgx.Batch) {
+func writeDeletedAssetHoldings(round basics.Round, modifiedAssetHoldings map[ledgercore.AccountAsset]bool, ids map[basics.Address]int64, batch *pgx.Batch) {
 	for aa, created := range modifiedAssetHoldings {
 		if !created {
-			address := new(basics.Address)
-			*address = aa.Address
-
 			batch.Queue(
-				deleteAccountAssetStmtName, address[:], uint64(aa.Asset), uint64(round))
+				deleteAccountAssetStmtName, ids[aa.Address], uint64(aa.Asset), uint64(round))
 		}
 	}
 }
 
+// assetHoldingAddressIDs returns the ids of the addresses whose asset holdings
+// change in `delta`. The addresses that are not in `ids` are added to the
+// `address` table in `tx`.
+func assetHoldingAddressIDs(tx pgx.Tx, delta ledgercore.StateDelta, ids map[basics.Address]int64) (map[basics.Address]int64, error) {
+	missing := make(map[basics.Address]struct{})
+	for i := 0; i < delta.Accts.Len(); i++ {
+		address, accountData := delta.Accts.GetByIdx(i)
+		if _, ok := ids[address]; !ok && len(accountData.Assets) > 0 {
+			missing[address] = struct{}{}
+		}
+	}
+	for aa := range delta.ModifiedAssetHoldings {
+		if _, ok := ids[aa.Address]; !ok {
+			missing[aa.Address] = struct{}{}
+		}
+	}
+	if len(missing) == 0 {
+		return ids, nil
+	}
+
+	res, err := addressIDs(tx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("assetHoldingAddressIDs() err: %w", err)
+	}
+	for address, id := range ids {
+		res[address] = id
+	}
+	return res, nil
+}
+
 func writeDeletedAppLocalStates(round basics.Round, modifiedAppLocalStates map[ledgercore.AccountApp]bool, batch *pgx.Batch) {
 	for aa, created := range modifiedAppLocalStates {
 		if !created {
@@ -330,8 +357,14 @@ func (w *Writer) AddBlock0(block *bookkeeping.Block) error {
 
 // AddBlock writes the block and accounting state deltas to the database, except for
 // transactions and transaction participation. Those are imported by free functions in
-// the writer/ directory.
-func (w *Writer) AddBlock(block *bookkeeping.Block, modifiedTxns []transactions.SignedTxnInBlock, delta ledgercore.StateDelta) error {
+// the writer/ directory. `ids` are the address ids returned by AddBlockAddresses(),
+// the addresses of asset holdings missing from it are added.
+func (w *Writer) AddBlock(block *bookkeeping.Block, modifiedTxns []transactions.SignedTxnInBlock, delta ledgercore.StateDelta, ids map[basics.Address]int64) error {
+	ids, err := assetHoldingAddressIDs(w.tx, delta, ids)
+	if err != nil {
+		return fmt.Errorf("AddBlock() err: %w", err)
+	}
+
 	var batch pgx.Batch
 
 	addBlockHeader(&block.BlockHeader, &batch)
@@ -345,10 +378,10 @@ func (w *Writer) AddBlock(block *bookkeeping.Block, modifiedTxns []transactions.
 		if err != nil {
 			return fmt.Errorf("AddBlock() err: %w", err)
 		}
-		writeAccounts(block.Round(), delta.Accts, sigTypeDeltas, &batch)
+		writeAccounts(block.Round(), delta.Accts, sigTypeDeltas, ids, &batch)
 	}
 	writeDeletedCreatables(block.Round(), delta.Creatables, &batch)
-	writeDeletedAssetHoldings(block.Round(), delta.ModifiedAssetHoldings, &batch)
+	writeDeletedAssetHoldings(block.Round(), delta.ModifiedAssetHoldings, ids, &batch)
 	writeDeletedAppLocalStates(block.Round(), delta.ModifiedAppLocalStates, &batch)
 	batch.Queue(updateAccountTotalsStmtName, encoding.EncodeAccountTotals(&delta.Totals))
 
@@ -361,7 +394,7 @@ func (w *Writer) AddBlock(block *bookkeeping.Block, modifiedTxns []transactions.
 			return fmt.Errorf("AddBlock() exec err: %w", err)
 		}
 	}
-	err := results.Close()
+	err = results.Close()
 	if err != nil {
 		return fmt.Errorf("AddBlock() close results err: %w", err)
 	}
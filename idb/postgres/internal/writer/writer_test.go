@@ -43,6 +43,16 @@ func makeTx(db *pgxpool.Pool, f func(tx pgx.Tx) error) error {
 	return pgutil.TxWithRetry(db, serializable, f, nil)
 }
 
+// addTransactionParticipation adds the block addresses and the transaction
+// participation in one transaction.
+func addTransactionParticipation(block *bookkeeping.Block, tx pgx.Tx) error {
+	ids, err := writer.AddBlockAddresses(block, tx)
+	if err != nil {
+		return err
+	}
+	return writer.AddTransactionParticipation(block, ids, tx)
+}
+
 type txnRow struct {
 	round    int
 	intra    int
@@ -84,6 +94,15 @@ type txnParticipationRow struct {
 	intra int
 }
 
+// The txn_participation and account_asset rows with the address of their address id.
+const (
+	txnParticipationSelect = `SELECT a.addr, p.round, p.intra
+		FROM txn_participation p JOIN address a ON p.addr_id = a.addr_id
+		ORDER BY p.round, p.intra, a.addr`
+	accountAssetSelect = `SELECT a.addr, aa.assetid, aa.amount, aa.frozen, aa.deleted, aa.created_at, aa.closed_at
+		FROM account_asset aa JOIN address a ON aa.addr_id = a.addr_id`
+)
+
 func txnParticipationQuery(db *pgxpool.Pool, query string) ([]txnParticipationRow, error) {
 	var results []txnParticipationRow
 	rows, err := db.Query(context.Background(), query)
@@ -117,7 +136,7 @@ func TestWriterBlockHeaderTableBasic(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{})
+		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{}, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -155,7 +174,7 @@ func TestWriterSpecialAccounts(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{})
+		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{}, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -218,7 +237,7 @@ func TestWriterTxnTableBasic(t *testing.T) {
 	err = pgutil.TxWithRetry(db, serializable, f, nil)
 	require.NoError(t, err)
 
-	rows, err := db.Query(context.Background(), "SELECT * FROM txn ORDER BY intra")
+	rows, err := db.Query(context.Background(), "SELECT round, intra, typeenum, asset, txid, txn, extra FROM txn ORDER BY intra")
 	require.NoError(t, err)
 	defer rows.Close()
 
@@ -323,6 +342,65 @@ func TestWriterTxnTableAssetCloseAmount(t *testing.T) {
 	assert.NoError(t, rows.Err())
 }
 
+// Test that the group id and the lease are written to their columns, NULL when
+// they are not set.
+func TestWriterTxnTableGroupAndLease(t *testing.T) {
+	db, shutdownFunc := setupPostgres(t)
+	defer shutdownFunc()
+
+	block := bookkeeping.Block{
+		BlockHeader: bookkeeping.BlockHeader{
+			GenesisID:   test.MakeGenesis().ID(),
+			GenesisHash: test.GenesisHash,
+			UpgradeState: bookkeeping.UpgradeState{
+				CurrentProtocol: test.Proto,
+			},
+		},
+		Payset: make(transactions.Payset, 2),
+	}
+	stxnad0 := test.MakePaymentTxn(
+		1000, 1, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{},
+		basics.Address{})
+	stxnad0.Txn.Group[0] = 1
+	stxnad0.Txn.Lease[0] = 2
+	stxnad1 := test.MakePaymentTxn(
+		1000, 2, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{},
+		basics.Address{})
+	var err error
+	block.Payset[0], err = block.EncodeSignedTxn(stxnad0.SignedTxn, stxnad0.ApplyData)
+	require.NoError(t, err)
+	block.Payset[1], err = block.EncodeSignedTxn(stxnad1.SignedTxn, stxnad1.ApplyData)
+	require.NoError(t, err)
+
+	f := func(tx pgx.Tx) error {
+		return writer.AddTransactions(&block, block.Payset, tx)
+	}
+	err = pgutil.TxWithRetry(db, serializable, f, nil)
+	require.NoError(t, err)
+
+	rows, err := db.Query(
+		context.Background(), "SELECT txgroup, lease FROM txn ORDER BY intra")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var txgroup []byte
+	var lease []byte
+	require.True(t, rows.Next())
+	err = rows.Scan(&txgroup, &lease)
+	require.NoError(t, err)
+	assert.Equal(t, stxnad0.Txn.Group[:], txgroup)
+	assert.Equal(t, stxnad0.Txn.Lease[:], lease)
+
+	require.True(t, rows.Next())
+	err = rows.Scan(&txgroup, &lease)
+	require.NoError(t, err)
+	assert.Nil(t, txgroup)
+	assert.Nil(t, lease)
+
+	assert.False(t, rows.Next())
+	assert.NoError(t, rows.Err())
+}
+
 func TestWriterTxnParticipationTable(t *testing.T) {
 	type testtype struct {
 		name     string
@@ -419,13 +497,13 @@ func TestWriterTxnParticipationTable(t *testing.T) {
 			block.Payset = testcase.payset
 
 			f := func(tx pgx.Tx) error {
-				return writer.AddTransactionParticipation(&block, tx)
+				return addTransactionParticipation(&block, tx)
 			}
 			err := pgutil.TxWithRetry(db, serializable, f, nil)
 			require.NoError(t, err)
 
 			results, err := txnParticipationQuery(
-				db, `SELECT * FROM txn_participation ORDER BY round, intra, addr`)
+				db, txnParticipationSelect)
 			assert.NoError(t, err)
 
 			// Verify expected participation
@@ -469,7 +547,7 @@ func TestWriterAccountTableBasic(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -578,7 +656,7 @@ func TestWriterAccountTableCreateDeleteSameRound(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -659,7 +737,7 @@ func TestWriterDeleteAccountDoesNotDeleteKeytype(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -714,7 +792,7 @@ func TestWriterAccountAssetTableBasic(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -731,7 +809,7 @@ func TestWriterAccountAssetTableBasic(t *testing.T) {
 	var createdAt uint64
 	var closedAt *uint64
 
-	rows, err := db.Query(context.Background(), "SELECT * FROM account_asset")
+	rows, err := db.Query(context.Background(), accountAssetSelect)
 	require.NoError(t, err)
 	defer rows.Close()
 
@@ -763,7 +841,7 @@ func TestWriterAccountAssetTableBasic(t *testing.T) {
 	err = pgutil.TxWithRetry(db, serializable, f, nil)
 	require.NoError(t, err)
 
-	rows, err = db.Query(context.Background(), "SELECT * FROM account_asset")
+	rows, err = db.Query(context.Background(), accountAssetSelect)
 	require.NoError(t, err)
 	defer rows.Close()
 
@@ -803,7 +881,7 @@ func TestWriterAccountAssetTableCreateDeleteSameRound(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -820,7 +898,7 @@ func TestWriterAccountAssetTableCreateDeleteSameRound(t *testing.T) {
 	var createdAt uint64
 	var closedAt uint64
 
-	row := db.QueryRow(context.Background(), "SELECT * FROM account_asset")
+	row := db.QueryRow(context.Background(), accountAssetSelect)
 	err = row.Scan(&addr, &assetid, &amount, &frozen, &deleted, &createdAt, &closedAt)
 	require.NoError(t, err)
 
@@ -856,7 +934,7 @@ func TestWriterAccountAssetTableLargeAmount(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -898,7 +976,7 @@ func TestWriterAssetTableBasic(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1001,7 +1079,7 @@ func TestWriterAssetTableCreateDeleteSameRound(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1063,7 +1141,7 @@ func TestWriterAppTableBasic(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1166,7 +1244,7 @@ func TestWriterAppTableCreateDeleteSameRound(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1228,7 +1306,7 @@ func TestWriterAccountAppTableBasic(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1323,7 +1401,7 @@ func TestWriterAccountAppTableCreateDeleteSameRound(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, delta)
+		err = w.AddBlock(&block, block.Payset, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1411,11 +1489,11 @@ func TestWriterAddBlockInnerTxnsAssetCreate(t *testing.T) {
 		if err != nil {
 			return err
 		}
-		return writer.AddTransactionParticipation(&block, tx)
+		return addTransactionParticipation(&block, tx)
 	})
 	require.NoError(t, err)
 
-	txns, err := txnQuery(db, "SELECT * FROM txn ORDER BY intra")
+	txns, err := txnQuery(db, "SELECT round, intra, typeenum, asset, txid, txn, extra FROM txn ORDER BY intra")
 	require.NoError(t, err)
 	require.Len(t, txns, 5)
 
@@ -1453,7 +1531,7 @@ func TestWriterAddBlockInnerTxnsAssetCreate(t *testing.T) {
 	require.Equal(t, 5, txns[4].asset, "intra == 4 -> AssetID = 5")
 
 	// Verify txn participation
-	txnPart, err := txnParticipationQuery(db, `SELECT * FROM txn_participation ORDER BY round, intra, addr`)
+	txnPart, err := txnParticipationQuery(db, txnParticipationSelect)
 	require.NoError(t, err)
 
 	expectedParticipation := []txnParticipationRow{
@@ -1545,7 +1623,7 @@ func TestWriterAccountTotals(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{Totals: accountTotals})
+		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{Totals: accountTotals}, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1573,7 +1651,7 @@ func TestWriterAddBlock0(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{})
+		err = w.AddBlock(&block, block.Payset, ledgercore.StateDelta{}, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -1618,3 +1696,67 @@ func TestWriterAddBlock0(t *testing.T) {
 		assert.Equal(t, expected, accounts)
 	}
 }
+
+// Test that the participation and asset holding rows of an address share its id,
+// and that the asset holding addresses which are not participants are added.
+func TestWriterAddressIDs(t *testing.T) {
+	db, shutdownFunc := setupPostgres(t)
+	defer shutdownFunc()
+
+	txn := test.MakePaymentTxn(
+		0, 1, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	block, err := test.MakeBlockForTxns(test.MakeGenesisBlock().BlockHeader, &txn)
+	require.NoError(t, err)
+
+	var delta ledgercore.StateDelta
+	delta.Accts.Upsert(test.AccountA, basics.AccountData{
+		MicroAlgos: basics.MicroAlgos{Raw: 5},
+		Assets:     map[basics.AssetIndex]basics.AssetHolding{3: {Amount: 4}},
+	})
+	delta.ModifiedAssetHoldings = map[ledgercore.AccountAsset]bool{
+		{Address: test.AccountC, Asset: 3}: false,
+	}
+
+	for i := 0; i < 2; i++ {
+		var ids map[basics.Address]int64
+		err = makeTx(db, func(tx pgx.Tx) error {
+			ids, err = writer.AddBlockAddresses(&block, tx)
+			return err
+		})
+		require.NoError(t, err)
+		assert.Len(t, ids, 2)
+
+		err = makeTx(db, func(tx pgx.Tx) error {
+			w, err := writer.MakeWriter(tx)
+			require.NoError(t, err)
+			defer w.Close()
+
+			err = w.AddBlock(&block, block.Payset, delta, ids)
+			require.NoError(t, err)
+			return writer.AddTransactionParticipation(&block, ids, tx)
+		})
+		require.NoError(t, err)
+		block.BlockHeader.Round++
+	}
+
+	var count int
+	err = db.QueryRow(context.Background(), "SELECT COUNT(*) FROM address").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	err = db.QueryRow(
+		context.Background(),
+		`SELECT COUNT(DISTINCT p.addr_id) FROM txn_participation p
+		JOIN account_asset aa ON p.addr_id = aa.addr_id`).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	txnPart, err := txnParticipationQuery(db, txnParticipationSelect)
+	require.NoError(t, err)
+	assert.Equal(t, []txnParticipationRow{
+		{addr: test.AccountA, round: 1, intra: 0},
+		{addr: test.AccountB, round: 1, intra: 0},
+		{addr: test.AccountA, round: 2, intra: 0},
+		{addr: test.AccountB, round: 2, intra: 0},
+	}, txnPart)
+}
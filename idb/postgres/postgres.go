@@ -31,6 +31,7 @@ import (
 	"github.com/algorand/indexer/accounting"
 	models "github.com/algorand/indexer/api/generated/v2"
 	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/internal/convert"
 	"github.com/algorand/indexer/idb/migration"
 	"github.com/algorand/indexer/idb/postgres/internal/encoding"
 	ledger_for_evaluator "github.com/algorand/indexer/idb/postgres/internal/ledger_for_evaluator"
@@ -38,7 +39,6 @@ import (
 	"github.com/algorand/indexer/idb/postgres/internal/types"
 	pgutil "github.com/algorand/indexer/idb/postgres/internal/util"
 	"github.com/algorand/indexer/idb/postgres/internal/writer"
-	"github.com/algorand/indexer/util"
 	"github.com/algorand/indexer/util/metrics"
 )
 
@@ -65,9 +65,11 @@ func OpenPostgres(connection string, opts idb.IndexerDbOptions, log *log.Logger)
 // Allow tests to inject a DB
 func openPostgres(db *pgxpool.Pool, opts idb.IndexerDbOptions, logger *log.Logger) (*IndexerDb, chan struct{}, error) {
 	idb := &IndexerDb{
-		readonly: opts.ReadOnly,
-		log:      logger,
-		db:       db,
+		readonly:          opts.ReadOnly,
+		log:               logger,
+		db:                db,
+		stateDeltaHandler: opts.StateDeltaHandler,
+		maxRollbackRounds: opts.MaxRollbackRounds,
 	}
 
 	if idb.log == nil {
@@ -108,6 +110,16 @@ type IndexerDb struct {
 	db             *pgxpool.Pool
 	migration      *migration.Migration
 	accountingLock sync.Mutex
+
+	// stateDeltaHandler is optional, see idb.IndexerDbOptions.
+	stateDeltaHandler idb.StateDeltaHandler
+	// maxRollbackRounds, see idb.IndexerDbOptions.
+	maxRollbackRounds uint64
+	// partitionRounds is the number of rounds of each partition of the
+	// transaction tables, 0 if they are not partitioned.
+	partitionRounds uint64
+	// partitionedUntil is the first round whose partitions might not exist.
+	partitionedUntil uint64
 }
 
 // Close is part of idb.IndexerDb.
@@ -154,6 +166,13 @@ func (db *IndexerDb) init(opts idb.IndexerDbOptions) (chan struct{}, error) {
 			return nil, fmt.Errorf("unable to setup postgres: %v", err)
 		}
 
+		if opts.PartitionRounds != 0 {
+			err = db.partitionTxnTables(opts.PartitionRounds)
+			if err != nil {
+				return nil, fmt.Errorf("unable to partition tables: %v", err)
+			}
+		}
+
 		err = db.markMigrationsAsDone()
 		if err != nil {
 			return nil, fmt.Errorf("unable to confirm migration: %v", err)
@@ -164,8 +183,17 @@ func (db *IndexerDb) init(opts idb.IndexerDbOptions) (chan struct{}, error) {
 		return ch, nil
 	}
 
+	partition, err := db.checkPartitionRounds(opts.PartitionRounds)
+	if err != nil {
+		return nil, fmt.Errorf("init() err: %w", err)
+	}
+
 	// see postgres_migrations.go
-	return db.runAvailableMigrations()
+	var partitionRounds uint64
+	if partition {
+		partitionRounds = opts.PartitionRounds
+	}
+	return db.runAvailableMigrations(partitionRounds)
 }
 
 // Returns all addresses referenced in `block`.
@@ -266,6 +294,26 @@ func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
 	db.accountingLock.Lock()
 	defer db.accountingLock.Unlock()
 
+	err := db.addPartitions(uint64(block.Round()))
+	if err != nil {
+		return fmt.Errorf("AddBlock() err: %w", err)
+	}
+
+	// Add the addresses of the block before the transaction participation and
+	// the accounting state are written in parallel transactions, which would
+	// otherwise add the same addresses and wait for each other.
+	var ids map[basics.Address]int64
+	if block.Round() != basics.Round(0) {
+		err = db.txWithRetry(serializable, func(tx pgx.Tx) error {
+			var err error
+			ids, err = writer.AddBlockAddresses(block, tx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("AddBlock() err: %w", err)
+		}
+	}
+
 	f := func(tx pgx.Tx) error {
 		// Check and increment next round counter.
 		importstate, err := db.getImportState(context.Background(), tx)
@@ -278,6 +326,16 @@ func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
 				block.Round(), importstate.NextRoundToAccount)
 		}
 		importstate.NextRoundToAccount++
+
+		// The undo triggers record the changes of this round, starting with the
+		// import state, while the setting is set.
+		if db.maxRollbackRounds > 0 && block.Round() != basics.Round(0) {
+			err = setUndoRound(tx, uint64(block.Round()))
+			if err != nil {
+				return fmt.Errorf("AddBlock() err: %w", err)
+			}
+		}
+
 		err = db.setImportState(tx, &importstate)
 		if err != nil {
 			return fmt.Errorf("AddBlock() err: %w", err)
@@ -322,7 +380,7 @@ func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
 							return err
 						}
 					}
-					return writer.AddTransactionParticipation(block, tx)
+					return writer.AddTransactionParticipation(block, ids, tx)
 				}
 				err0 = db.txWithRetry(serializable, f)
 			}()
@@ -362,7 +420,7 @@ func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
 				}()
 			}
 
-			err = w.AddBlock(block, modifiedTxns, delta)
+			err = w.AddBlock(block, modifiedTxns, delta, ids)
 			if err != nil {
 				return fmt.Errorf("AddBlock() err: %w", err)
 			}
@@ -382,11 +440,25 @@ func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
 			if (err1 != nil) && !isUniqueViolationFunc(err1) {
 				return fmt.Errorf("AddBlock() err1: %w", err1)
 			}
+
+			// Block 0 has no state delta, the genesis allocation is reported by
+			// LoadGenesis(). The round is not committed if the handler fails.
+			if db.stateDeltaHandler != nil {
+				err = db.stateDeltaHandler(block.Round(), &delta)
+				if err != nil {
+					return fmt.Errorf("AddBlock() state delta handler err: %w", err)
+				}
+			}
+		}
+
+		err = db.pruneUndo(tx, uint64(block.Round()))
+		if err != nil {
+			return fmt.Errorf("AddBlock() err: %w", err)
 		}
 
 		return nil
 	}
-	err := db.txWithRetry(serializable, f)
+	err = db.txWithRetry(serializable, f)
 	if err != nil {
 		return fmt.Errorf("AddBlock() err: %w", err)
 	}
@@ -397,6 +469,8 @@ func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
 // LoadGenesis is part of idb.IndexerDB
 func (db *IndexerDb) LoadGenesis(genesis bookkeeping.Genesis) error {
 	f := func(tx pgx.Tx) error {
+		var delta ledgercore.StateDelta
+
 		setAccountStatementName := "set_account"
 		query := `INSERT INTO account (addr, microalgos, rewardsbase, account_data, rewards_total, created_at, deleted) VALUES ($1, $2, 0, $3, $4, 0, false)`
 		_, err := tx.Prepare(context.Background(), setAccountStatementName, query)
@@ -428,7 +502,9 @@ func (db *IndexerDb) LoadGenesis(genesis bookkeeping.Genesis) error {
 			}
 
 			totals.AddAccount(proto, alloc.State, &ot)
+			delta.Accts.Upsert(addr, alloc.State)
 		}
+		delta.Totals = totals
 
 		err = db.setMetastate(
 			tx, schema.AccountTotals, string(encoding.EncodeAccountTotals(&totals)))
@@ -444,6 +520,13 @@ func (db *IndexerDb) LoadGenesis(genesis bookkeeping.Genesis) error {
 			return fmt.Errorf("LoadGenesis() err: %w", err)
 		}
 
+		if db.stateDeltaHandler != nil {
+			err = db.stateDeltaHandler(basics.Round(0), &delta)
+			if err != nil {
+				return fmt.Errorf("LoadGenesis() state delta handler err: %w", err)
+			}
+		}
+
 		return nil
 	}
 	err := db.txWithRetry(serializable, f)
@@ -525,6 +608,20 @@ func (db *IndexerDb) getMaxRoundAccounted(ctx context.Context, tx pgx.Tx) (uint6
 	return round, nil
 }
 
+// GetRewardsLevel is part of idb.IndexerDB
+func (db *IndexerDb) GetRewardsLevel(ctx context.Context, round uint64) (uint64, error) {
+	row := db.db.QueryRow(ctx, `SELECT rewardslevel FROM block_header WHERE round = $1`, round)
+	var level uint64
+	err := row.Scan(&level)
+	if err == pgx.ErrNoRows {
+		return 0, idb.ErrorBlockNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("GetRewardsLevel() err: %w", err)
+	}
+	return level, nil
+}
+
 // GetBlock is part of idb.IndexerDB
 func (db *IndexerDb) GetBlock(ctx context.Context, round uint64, options idb.GetBlockOptions) (blockHeader bookkeeping.BlockHeader, transactions []idb.TxnRow, err error) {
 	tx, err := db.db.BeginTx(ctx, readonlyRepeatableRead)
@@ -587,7 +684,7 @@ func buildTransactionQuery(tf idb.TransactionFilter) (query string, whereArgs []
 	joinParticipation := false
 	partNumber := 1
 	if tf.Address != nil {
-		whereParts = append(whereParts, fmt.Sprintf("p.addr = $%d", partNumber))
+		whereParts = append(whereParts, fmt.Sprintf("p.addr_id = (SELECT addr_id FROM address WHERE addr = $%d)", partNumber))
 		whereArgs = append(whereArgs, tf.Address)
 		partNumber++
 		if tf.AddressRole != 0 {
@@ -633,13 +730,22 @@ func buildTransactionQuery(tf idb.TransactionFilter) (query string, whereArgs []
 		}
 		joinParticipation = true
 	}
+	// Round conditions are repeated for the participation table, the planner
+	// does not carry them over the join and needs them on both tables to skip
+	// their partitions.
+	roundParts := func(op string) {
+		whereParts = append(whereParts, fmt.Sprintf("t.round %s $%d", op, partNumber))
+		if joinParticipation {
+			whereParts = append(whereParts, fmt.Sprintf("p.round %s $%d", op, partNumber))
+		}
+	}
 	if tf.MinRound != 0 {
-		whereParts = append(whereParts, fmt.Sprintf("t.round >= $%d", partNumber))
+		roundParts(">=")
 		whereArgs = append(whereArgs, tf.MinRound)
 		partNumber++
 	}
 	if tf.MaxRound != 0 {
-		whereParts = append(whereParts, fmt.Sprintf("t.round <= $%d", partNumber))
+		roundParts("<=")
 		whereArgs = append(whereArgs, tf.MaxRound)
 		partNumber++
 	}
@@ -692,7 +798,7 @@ func buildTransactionQuery(tf idb.TransactionFilter) (query string, whereArgs []
 		partNumber++
 	}
 	if tf.Round != nil {
-		whereParts = append(whereParts, fmt.Sprintf("t.round = $%d", partNumber))
+		roundParts("=")
 		whereArgs = append(whereArgs, *tf.Round)
 		partNumber++
 	}
@@ -721,6 +827,16 @@ func buildTransactionQuery(tf idb.TransactionFilter) (query string, whereArgs []
 		whereArgs = append(whereArgs, tf.NotePrefix)
 		partNumber++
 	}
+	if len(tf.GroupID) > 0 {
+		whereParts = append(whereParts, fmt.Sprintf("t.txgroup = $%d", partNumber))
+		whereArgs = append(whereArgs, tf.GroupID)
+		partNumber++
+	}
+	if len(tf.Lease) > 0 {
+		whereParts = append(whereParts, fmt.Sprintf("t.lease = $%d", partNumber))
+		whereArgs = append(whereArgs, tf.Lease)
+		partNumber++
+	}
 	if tf.AlgosGT != nil {
 		whereParts = append(whereParts, fmt.Sprintf("(t.txn -> 'txn' -> 'amt')::bigint > $%d", partNumber))
 		whereArgs = append(whereArgs, *tf.AlgosGT)
@@ -749,8 +865,8 @@ func buildTransactionQuery(tf idb.TransactionFilter) (query string, whereArgs []
 		query += " JOIN txn_participation p ON t.round = p.round AND t.intra = p.intra"
 	}
 
-	// join in the root transaction
-	query += " LEFT OUTER JOIN txn root ON t.round = root.round AND t.extra->>'root-intra' = root.intra::text"
+	// join in the root transaction by its primary key
+	query += " LEFT OUTER JOIN txn root ON t.round = root.round AND (t.extra->>'root-intra')::integer = root.intra"
 
 	if len(whereParts) > 0 {
 		whereStr := strings.Join(whereParts, " AND ")
@@ -758,7 +874,7 @@ func buildTransactionQuery(tf idb.TransactionFilter) (query string, whereArgs []
 	}
 	if joinParticipation {
 		// this should match the index on txn_particpation
-		query += " ORDER BY p.addr, p.round DESC, p.intra DESC"
+		query += " ORDER BY p.addr_id, p.round DESC, p.intra DESC"
 	} else {
 		// this should explicitly match the primary key on txn (round,intra)
 		query += " ORDER BY t.round, t.intra"
@@ -979,40 +1095,6 @@ finish:
 	}
 }
 
-var statusStrings = []string{"Offline", "Online", "NotParticipating"}
-
-const offlineStatusIdx = 0
-
-func tealValueToModel(tv basics.TealValue) models.TealValue {
-	switch tv.Type {
-	case basics.TealUintType:
-		return models.TealValue{
-			Uint: tv.Uint,
-			Type: uint64(tv.Type),
-		}
-	case basics.TealBytesType:
-		return models.TealValue{
-			Bytes: encoding.Base64([]byte(tv.Bytes)),
-			Type:  uint64(tv.Type),
-		}
-	}
-	return models.TealValue{}
-}
-
-func tealKeyValueToModel(tkv basics.TealKeyValue) *models.TealKeyValueStore {
-	if len(tkv) == 0 {
-		return nil
-	}
-	var out models.TealKeyValueStore = make([]models.TealKeyValue, len(tkv))
-	pos := 0
-	for key, tv := range tkv {
-		out[pos].Key = encoding.Base64([]byte(key))
-		out[pos].Value = tealValueToModel(tv)
-		pos++
-	}
-	return &out
-}
-
 func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
 	count := uint64(0)
 	defer func() {
@@ -1116,7 +1198,7 @@ func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
 		account.RewardBase = new(uint64)
 		*account.RewardBase = rewardsbase
 		// default to Offline in there have been no keyreg transactions.
-		account.Status = statusStrings[offlineStatusIdx]
+		account.Status = convert.OfflineStatus
 		if keytype != nil && *keytype != "" {
 			account.SigType = keytype
 		}
@@ -1129,61 +1211,14 @@ func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
 				req.out <- idb.AccountRow{Error: err}
 				break
 			}
-			account.Status = statusStrings[ad.Status]
-			hasSel := !allZero(ad.SelectionID[:])
-			hasVote := !allZero(ad.VoteID[:])
-			if hasSel || hasVote {
-				part := new(models.AccountParticipation)
-				if hasSel {
-					part.SelectionParticipationKey = ad.SelectionID[:]
-				}
-				if hasVote {
-					part.VoteParticipationKey = ad.VoteID[:]
-				}
-				part.VoteFirstValid = uint64(ad.VoteFirstValid)
-				part.VoteLastValid = uint64(ad.VoteLastValid)
-				part.VoteKeyDilution = ad.VoteKeyDilution
-				account.Participation = part
-			}
-
-			if !ad.AuthAddr.IsZero() {
-				var spendingkey basics.Address
-				copy(spendingkey[:], ad.AuthAddr[:])
-				account.AuthAddr = stringPtr(spendingkey.String())
-			}
-
-			{
-				totalSchema := models.ApplicationStateSchema{
-					NumByteSlice: ad.TotalAppSchema.NumByteSlice,
-					NumUint:      ad.TotalAppSchema.NumUint,
-				}
-				if totalSchema != (models.ApplicationStateSchema{}) {
-					account.AppsTotalSchema = &totalSchema
-				}
-			}
-			if ad.TotalExtraAppPages != 0 {
-				account.AppsTotalExtraPages = uint64Ptr(uint64(ad.TotalExtraAppPages))
-			}
+			convert.AccountData(&account, ad)
 		}
 
-		if account.Status == "NotParticipating" {
-			account.PendingRewards = 0
-		} else {
-			// TODO: pending rewards calculation doesn't belong in database layer (this is just the most covenient place which has all the data)
-			proto, ok := config.Consensus[req.blockheader.CurrentProtocol]
-			if !ok {
-				err = fmt.Errorf("get protocol err (%s)", req.blockheader.CurrentProtocol)
-				req.out <- idb.AccountRow{Error: err}
-				break
-			}
-			rewardsUnits := uint64(0)
-			if proto.RewardUnit != 0 {
-				rewardsUnits = microalgos / proto.RewardUnit
-			}
-			rewardsDelta := req.blockheader.RewardsLevel - rewardsbase
-			account.PendingRewards = rewardsUnits * rewardsDelta
+		err = convert.Rewards(&account, req.blockheader, microalgos, rewardsbase)
+		if err != nil {
+			req.out <- idb.AccountRow{Error: err}
+			break
 		}
-		account.Amount = microalgos + account.PendingRewards
 		// not implemented: account.Rewards sum of all rewards ever
 
 		const nullarraystr = "[null]"
@@ -1328,23 +1363,7 @@ func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
 					CreatedAtRound:   assetCreated[i],
 					DestroyedAtRound: assetClosed[i],
 					Deleted:          assetDeleted[i],
-					Params: models.AssetParams{
-						Creator:       account.Address,
-						Total:         ap.Total,
-						Decimals:      uint64(ap.Decimals),
-						DefaultFrozen: boolPtr(ap.DefaultFrozen),
-						UnitName:      stringPtr(util.PrintableUTF8OrEmpty(ap.UnitName)),
-						UnitNameB64:   byteSlicePtr([]byte(ap.UnitName)),
-						Name:          stringPtr(util.PrintableUTF8OrEmpty(ap.AssetName)),
-						NameB64:       byteSlicePtr([]byte(ap.AssetName)),
-						Url:           stringPtr(util.PrintableUTF8OrEmpty(ap.URL)),
-						UrlB64:        byteSlicePtr([]byte(ap.URL)),
-						MetadataHash:  byteSliceOmitZeroPtr(ap.MetadataHash[:]),
-						Manager:       addrStr(ap.Manager),
-						Reserve:       addrStr(ap.Reserve),
-						Freeze:        addrStr(ap.Freeze),
-						Clawback:      addrStr(ap.Clawback),
-					},
+					Params:           convert.AssetParams(account.Address, ap),
 				}
 				cal = append(cal, tma)
 			}
@@ -1407,21 +1426,7 @@ func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
 				// If these are both nil the app was probably deleted, leave out params
 				// some "required" fields will be left in the results.
 				if apps[i].ApprovalProgram != nil || apps[i].ClearStateProgram != nil {
-					aout[outpos].Params.ApprovalProgram = apps[i].ApprovalProgram
-					aout[outpos].Params.ClearStateProgram = apps[i].ClearStateProgram
-					aout[outpos].Params.GlobalState = tealKeyValueToModel(apps[i].GlobalState)
-					aout[outpos].Params.GlobalStateSchema = &models.ApplicationStateSchema{
-						NumByteSlice: apps[i].GlobalStateSchema.NumByteSlice,
-						NumUint:      apps[i].GlobalStateSchema.NumUint,
-					}
-					aout[outpos].Params.LocalStateSchema = &models.ApplicationStateSchema{
-						NumByteSlice: apps[i].LocalStateSchema.NumByteSlice,
-						NumUint:      apps[i].LocalStateSchema.NumUint,
-					}
-					if apps[i].ExtraProgramPages > 0 {
-						epp := uint64(apps[i].ExtraProgramPages)
-						aout[outpos].Params.ExtraProgramPages = &epp
-					}
+					convert.AppParams(&aout[outpos].Params, apps[i])
 				}
 
 				outpos++
@@ -1475,15 +1480,10 @@ func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
 
 			aout := make([]models.ApplicationLocalState, len(ls))
 			for i, appid := range appIds {
-				aout[i].Id = appid
+				aout[i] = convert.AppLocalState(appid, ls[i])
 				aout[i].OptedInAtRound = appCreated[i]
 				aout[i].ClosedOutAtRound = appClosed[i]
 				aout[i].Deleted = appDeleted[i]
-				aout[i].Schema = models.ApplicationStateSchema{
-					NumByteSlice: ls[i].Schema.NumByteSlice,
-					NumUint:      ls[i].Schema.NumUint,
-				}
-				aout[i].KeyValue = tealKeyValueToModel(ls[i].KeyValue)
 			}
 			account.AppsLocalState = &aout
 		}
@@ -1508,7 +1508,7 @@ func nullableInt64Ptr(x sql.NullInt64) *uint64 {
 	if !x.Valid {
 		return nil
 	}
-	return uint64Ptr(uint64(x.Int64))
+	return convert.Uint64Ptr(uint64(x.Int64))
 }
 
 func nullableBoolPtr(x sql.NullBool) *bool {
@@ -1525,72 +1525,6 @@ func uintOrDefault(x *uint64) uint64 {
 	return 0
 }
 
-func uint64Ptr(x uint64) *uint64 {
-	out := new(uint64)
-	*out = x
-	return out
-}
-
-func boolPtr(x bool) *bool {
-	out := new(bool)
-	*out = x
-	return out
-}
-
-func stringPtr(x string) *string {
-	if len(x) == 0 {
-		return nil
-	}
-	out := new(string)
-	*out = x
-	return out
-}
-
-func byteSlicePtr(x []byte) *[]byte {
-	if len(x) == 0 {
-		return nil
-	}
-
-	xx := make([]byte, len(x))
-	copy(xx, x)
-	return &xx
-}
-
-func byteSliceOmitZeroPtr(x []byte) *[]byte {
-	allzero := true
-	for _, b := range x {
-		if b != 0 {
-			allzero = false
-			break
-		}
-	}
-	if allzero {
-		return nil
-	}
-
-	xx := make([]byte, len(x))
-	copy(xx, x)
-	return &xx
-}
-
-func allZero(x []byte) bool {
-	for _, v := range x {
-		if v != 0 {
-			return false
-		}
-	}
-	return true
-}
-
-func addrStr(addr basics.Address) *string {
-	if addr.IsZero() {
-		return nil
-	}
-	out := new(string)
-	*out = addr.String()
-	return out
-}
-
 type getAccountsRequest struct {
 	ctx         context.Context
 	opts        idb.AccountQueryOptions
@@ -1688,16 +1622,16 @@ func (db *IndexerDb) buildAccountQuery(opts idb.AccountQueryOptions) (query stri
 	withClauses := make([]string, 0, maxWhereParts)
 	// filter by has-asset or has-app
 	if opts.HasAssetID != 0 {
-		aq := fmt.Sprintf("SELECT addr FROM account_asset WHERE assetid = $%d", partNumber)
+		aq := fmt.Sprintf("SELECT ad.addr FROM account_asset aa JOIN address ad ON aa.addr_id = ad.addr_id WHERE aa.assetid = $%d", partNumber)
 		whereArgs = append(whereArgs, opts.HasAssetID)
 		partNumber++
 		if opts.AssetGT != nil {
-			aq += fmt.Sprintf(" AND amount > $%d", partNumber)
+			aq += fmt.Sprintf(" AND aa.amount > $%d", partNumber)
 			whereArgs = append(whereArgs, *opts.AssetGT)
 			partNumber++
 		}
 		if opts.AssetLT != nil {
-			aq += fmt.Sprintf(" AND amount < $%d", partNumber)
+			aq += fmt.Sprintf(" AND aa.amount < $%d", partNumber)
 			whereArgs = append(whereArgs, *opts.AssetLT)
 			partNumber++
 		}
@@ -1720,6 +1654,11 @@ func (db *IndexerDb) buildAccountQuery(opts idb.AccountQueryOptions) (query stri
 		whereArgs = append(whereArgs, opts.EqualToAddress)
 		partNumber++
 	}
+	if len(opts.EqualToAddresses) > 0 {
+		whereParts = append(whereParts, fmt.Sprintf("a.addr = ANY($%d)", partNumber))
+		whereArgs = append(whereArgs, opts.EqualToAddresses)
+		partNumber++
+	}
 	if opts.AlgosGreaterThan != nil {
 		whereParts = append(whereParts, fmt.Sprintf("a.microalgos > $%d", partNumber))
 		whereArgs = append(whereArgs, *opts.AlgosGreaterThan)
@@ -1755,31 +1694,34 @@ func (db *IndexerDb) buildAccountQuery(opts idb.AccountQueryOptions) (query stri
 	if opts.Limit != 0 {
 		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
 	}
-	// TODO: asset holdings and asset params are optional, but practically always used. Either make them actually always on, or make app-global and app-local clauses also optional (they are currently always on).
 	withClauses = append(withClauses, "qaccounts AS ("+query+")")
 	query = "WITH " + strings.Join(withClauses, ", ")
 	if opts.IncludeDeleted {
 		if opts.IncludeAssetHoldings {
-			query += `, qaa AS (SELECT xa.addr, json_agg(aa.assetid) as haid, json_agg(aa.amount) as hamt, json_agg(aa.frozen) as hf, json_agg(aa.created_at) as holding_created_at, json_agg(aa.closed_at) as holding_closed_at, json_agg(coalesce(aa.deleted, false)) as holding_deleted FROM account_asset aa JOIN qaccounts xa ON aa.addr = xa.addr GROUP BY 1)`
+			query += `, qaa AS (SELECT xa.addr, json_agg(aa.assetid) as haid, json_agg(aa.amount) as hamt, json_agg(aa.frozen) as hf, json_agg(aa.created_at) as holding_created_at, json_agg(aa.closed_at) as holding_closed_at, json_agg(coalesce(aa.deleted, false)) as holding_deleted FROM account_asset aa JOIN address ad ON aa.addr_id = ad.addr_id JOIN qaccounts xa ON ad.addr = xa.addr GROUP BY 1)`
 		}
 		if opts.IncludeAssetParams {
 			query += `, qap AS (SELECT ya.addr, json_agg(ap.index) as paid, json_agg(ap.params) as pp, json_agg(ap.created_at) as asset_created_at, json_agg(ap.closed_at) as asset_closed_at, json_agg(ap.deleted) as asset_deleted FROM asset ap JOIN qaccounts ya ON ap.creator_addr = ya.addr GROUP BY 1)`
 		}
-		// app
-		query += `, qapp AS (SELECT app.creator as addr, json_agg(app.index) as papps, json_agg(app.params) as ppa, json_agg(app.created_at) as app_created_at, json_agg(app.closed_at) as app_closed_at, json_agg(app.deleted) as app_deleted FROM app JOIN qaccounts ON qaccounts.addr = app.creator GROUP BY 1)`
-		// app localstate
-		query += `, qls AS (SELECT la.addr, json_agg(la.app) as lsapps, json_agg(la.localstate) as lsls, json_agg(la.created_at) as ls_created_at, json_agg(la.closed_at) as ls_closed_at, json_agg(la.deleted) as ls_deleted FROM account_app la JOIN qaccounts ON qaccounts.addr = la.addr GROUP BY 1)`
+		if !opts.ExcludeApps {
+			// app
+			query += `, qapp AS (SELECT app.creator as addr, json_agg(app.index) as papps, json_agg(app.params) as ppa, json_agg(app.created_at) as app_created_at, json_agg(app.closed_at) as app_closed_at, json_agg(app.deleted) as app_deleted FROM app JOIN qaccounts ON qaccounts.addr = app.creator GROUP BY 1)`
+			// app localstate
+			query += `, qls AS (SELECT la.addr, json_agg(la.app) as lsapps, json_agg(la.localstate) as lsls, json_agg(la.created_at) as ls_created_at, json_agg(la.closed_at) as ls_closed_at, json_agg(la.deleted) as ls_deleted FROM account_app la JOIN qaccounts ON qaccounts.addr = la.addr GROUP BY 1)`
+		}
 	} else {
 		if opts.IncludeAssetHoldings {
-			query += `, qaa AS (SELECT xa.addr, json_agg(aa.assetid) as haid, json_agg(aa.amount) as hamt, json_agg(aa.frozen) as hf, json_agg(aa.created_at) as holding_created_at, json_agg(aa.closed_at) as holding_closed_at, json_agg(coalesce(aa.deleted, false)) as holding_deleted FROM account_asset aa JOIN qaccounts xa ON aa.addr = xa.addr WHERE coalesce(aa.deleted, false) = false GROUP BY 1)`
+			query += `, qaa AS (SELECT xa.addr, json_agg(aa.assetid) as haid, json_agg(aa.amount) as hamt, json_agg(aa.frozen) as hf, json_agg(aa.created_at) as holding_created_at, json_agg(aa.closed_at) as holding_closed_at, json_agg(coalesce(aa.deleted, false)) as holding_deleted FROM account_asset aa JOIN address ad ON aa.addr_id = ad.addr_id JOIN qaccounts xa ON ad.addr = xa.addr WHERE coalesce(aa.deleted, false) = false GROUP BY 1)`
 		}
 		if opts.IncludeAssetParams {
 			query += `, qap AS (SELECT ya.addr, json_agg(ap.index) as paid, json_agg(ap.params) as pp, json_agg(ap.created_at) as asset_created_at, json_agg(ap.closed_at) as asset_closed_at, json_agg(ap.deleted) as asset_deleted FROM asset ap JOIN qaccounts ya ON ap.creator_addr = ya.addr WHERE coalesce(ap.deleted, false) = false GROUP BY 1)`
 		}
-		// app
-		query += `, qapp AS (SELECT app.creator as addr, json_agg(app.index) as papps, json_agg(app.params) as ppa, json_agg(app.created_at) as app_created_at, json_agg(app.closed_at) as app_closed_at, json_agg(app.deleted) as app_deleted FROM app JOIN qaccounts ON qaccounts.addr = app.creator WHERE coalesce(app.deleted, false) = false GROUP BY 1)`
-		// app localstate
-		query += `, qls AS (SELECT la.addr, json_agg(la.app) as lsapps, json_agg(la.localstate) as lsls, json_agg(la.created_at) as ls_created_at, json_agg(la.closed_at) as ls_closed_at, json_agg(la.deleted) as ls_deleted FROM account_app la JOIN qaccounts ON qaccounts.addr = la.addr WHERE coalesce(la.deleted, false) = false GROUP BY 1)`
+		if !opts.ExcludeApps {
+			// app
+			query += `, qapp AS (SELECT app.creator as addr, json_agg(app.index) as papps, json_agg(app.params) as ppa, json_agg(app.created_at) as app_created_at, json_agg(app.closed_at) as app_closed_at, json_agg(app.deleted) as app_deleted FROM app JOIN qaccounts ON qaccounts.addr = app.creator WHERE coalesce(app.deleted, false) = false GROUP BY 1)`
+			// app localstate
+			query += `, qls AS (SELECT la.addr, json_agg(la.app) as lsapps, json_agg(la.localstate) as lsls, json_agg(la.created_at) as ls_created_at, json_agg(la.closed_at) as ls_closed_at, json_agg(la.deleted) as ls_deleted FROM account_app la JOIN qaccounts ON qaccounts.addr = la.addr WHERE coalesce(la.deleted, false) = false GROUP BY 1)`
+		}
 	}
 
 	// query results
@@ -1790,7 +1732,11 @@ func (db *IndexerDb) buildAccountQuery(opts idb.AccountQueryOptions) (query stri
 	if opts.IncludeAssetParams {
 		query += `, qap.paid, qap.pp, qap.asset_created_at, qap.asset_closed_at, qap.asset_deleted`
 	}
-	query += `, qapp.papps, qapp.ppa, qapp.app_created_at, qapp.app_closed_at, qapp.app_deleted, qls.lsapps, qls.lsls, qls.ls_created_at, qls.ls_closed_at, qls.ls_deleted FROM qaccounts za`
+	if opts.ExcludeApps {
+		query += `, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL FROM qaccounts za`
+	} else {
+		query += `, qapp.papps, qapp.ppa, qapp.app_created_at, qapp.app_closed_at, qapp.app_deleted, qls.lsapps, qls.lsls, qls.ls_created_at, qls.ls_closed_at, qls.ls_deleted FROM qaccounts za`
+	}
 
 	// join everything together
 	if opts.IncludeAssetHoldings {
@@ -1799,7 +1745,10 @@ func (db *IndexerDb) buildAccountQuery(opts idb.AccountQueryOptions) (query stri
 	if opts.IncludeAssetParams {
 		query += ` LEFT JOIN qap ON za.addr = qap.addr`
 	}
-	query += " LEFT JOIN qapp ON za.addr = qapp.addr LEFT JOIN qls ON qls.addr = za.addr ORDER BY za.addr ASC;"
+	if !opts.ExcludeApps {
+		query += " LEFT JOIN qapp ON za.addr = qapp.addr LEFT JOIN qls ON qls.addr = za.addr"
+	}
+	query += " ORDER BY za.addr ASC;"
 	return query, whereArgs
 }
 
@@ -1948,19 +1897,21 @@ func (db *IndexerDb) AssetBalances(ctx context.Context, abq idb.AssetBalanceQuer
 		whereArgs = append(whereArgs, *abq.AmountLT)
 		partNumber++
 	}
+	// The balances are paged in address id order, so that the account_asset_asset
+	// index returns a page without sorting all the holders of the asset.
 	if len(abq.PrevAddress) != 0 {
-		whereParts = append(whereParts, fmt.Sprintf("aa.addr > $%d", partNumber))
+		whereParts = append(whereParts, fmt.Sprintf("aa.addr_id > (SELECT addr_id FROM address WHERE addr = $%d)", partNumber))
 		whereArgs = append(whereArgs, abq.PrevAddress)
 		partNumber++
 	}
 	if !abq.IncludeDeleted {
 		whereParts = append(whereParts, "coalesce(aa.deleted, false) = false")
 	}
-	query := `SELECT addr, assetid, amount, frozen, created_at, closed_at, deleted FROM account_asset aa`
+	query := `SELECT ad.addr, aa.assetid, aa.amount, aa.frozen, aa.created_at, aa.closed_at, aa.deleted FROM account_asset aa JOIN address ad ON aa.addr_id = ad.addr_id`
 	if len(whereParts) > 0 {
 		query += " WHERE " + strings.Join(whereParts, " AND ")
 	}
-	query += " ORDER BY addr ASC"
+	query += " ORDER BY aa.addr_id ASC"
 	if abq.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", abq.Limit)
 	}
@@ -2127,28 +2078,10 @@ func (db *IndexerDb) yieldApplicationsThread(ctx context.Context, rows pgx.Rows,
 			out <- rec
 			break
 		}
-		rec.Application.Params.ApprovalProgram = ap.ApprovalProgram
-		rec.Application.Params.ClearStateProgram = ap.ClearStateProgram
-		rec.Application.Params.Creator = new(string)
-
 		var aaddr basics.Address
 		copy(aaddr[:], creator)
-		rec.Application.Params.Creator = new(string)
-		*(rec.Application.Params.Creator) = aaddr.String()
-		rec.Application.Params.GlobalState = tealKeyValueToModel(ap.GlobalState)
-		rec.Application.Params.GlobalStateSchema = &models.ApplicationStateSchema{
-			NumByteSlice: ap.GlobalStateSchema.NumByteSlice,
-			NumUint:      ap.GlobalStateSchema.NumUint,
-		}
-		rec.Application.Params.LocalStateSchema = &models.ApplicationStateSchema{
-			NumByteSlice: ap.LocalStateSchema.NumByteSlice,
-			NumUint:      ap.LocalStateSchema.NumUint,
-		}
-
-		if ap.ExtraProgramPages != 0 {
-			rec.Application.Params.ExtraProgramPages = new(uint64)
-			*rec.Application.Params.ExtraProgramPages = uint64(ap.ExtraProgramPages)
-		}
+		rec.Application.Params.Creator = convert.StringPtr(aaddr.String())
+		convert.AppParams(&rec.Application.Params, ap)
 
 		out <- rec
 	}
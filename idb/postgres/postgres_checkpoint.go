@@ -0,0 +1,87 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/postgres/internal/encoding"
+	"github.com/algorand/indexer/idb/postgres/internal/schema"
+)
+
+// exportCheckpointKey returns the metastate key of the checkpoint of the
+// exporter `name`.
+func exportCheckpointKey(name string) string {
+	return schema.ExportCheckpointMetastateKeyPrefix + name
+}
+
+// GetExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) GetExportCheckpoint(name string) (idb.ExportCheckpoint, error) {
+	checkpointJSON, err := db.getMetastate(context.Background(), nil, exportCheckpointKey(name))
+	if err == idb.ErrorNotInitialized {
+		return idb.ExportCheckpoint{}, idb.ErrorCheckpointNotFound
+	}
+	if err != nil {
+		return idb.ExportCheckpoint{}, fmt.Errorf("GetExportCheckpoint() err: %w", err)
+	}
+
+	checkpoint, err := encoding.DecodeExportCheckpoint([]byte(checkpointJSON))
+	if err != nil {
+		return idb.ExportCheckpoint{}, fmt.Errorf(
+			"GetExportCheckpoint() unable to parse checkpoint v: \"%s\" err: %w", checkpointJSON, err)
+	}
+	return checkpoint, nil
+}
+
+// GetExportCheckpoints is part of idb.IndexerDb.
+func (db *IndexerDb) GetExportCheckpoints() (map[string]idb.ExportCheckpoint, error) {
+	rows, err := db.db.Query(
+		context.Background(), `SELECT k, v FROM metastate WHERE left(k, $1) = $2`,
+		len(schema.ExportCheckpointMetastateKeyPrefix), schema.ExportCheckpointMetastateKeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("GetExportCheckpoints() query err: %w", err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]idb.ExportCheckpoint)
+	for rows.Next() {
+		var key, checkpointJSON string
+		err = rows.Scan(&key, &checkpointJSON)
+		if err != nil {
+			return nil, fmt.Errorf("GetExportCheckpoints() scan err: %w", err)
+		}
+		checkpoint, err := encoding.DecodeExportCheckpoint([]byte(checkpointJSON))
+		if err != nil {
+			return nil, fmt.Errorf("GetExportCheckpoints() decode %s err: %w", key, err)
+		}
+		res[strings.TrimPrefix(key, schema.ExportCheckpointMetastateKeyPrefix)] = checkpoint
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetExportCheckpoints() query err: %w", err)
+	}
+	return res, nil
+}
+
+// SetExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) SetExportCheckpoint(name string, checkpoint idb.ExportCheckpoint) error {
+	err := db.setMetastate(
+		nil, exportCheckpointKey(name), string(encoding.EncodeExportCheckpoint(&checkpoint)))
+	if err != nil {
+		return fmt.Errorf("SetExportCheckpoint() err: %w", err)
+	}
+	return nil
+}
+
+// DeleteExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) DeleteExportCheckpoint(name string) error {
+	_, err := db.db.Exec(
+		context.Background(), `DELETE FROM metastate WHERE k = $1`, exportCheckpointKey(name))
+	if err != nil {
+		return fmt.Errorf("DeleteExportCheckpoint() err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/algorand/indexer/idb/conformance"
+	pgtest "github.com/algorand/indexer/idb/postgres/internal/testing"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, postgresFactory{}, func(t *testing.T) (string, func()) {
+		_, connStr, shutdownFunc := pgtest.SetupPostgres(t)
+		return connStr, shutdownFunc
+	})
+}
@@ -0,0 +1,79 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/indexer/idb/postgres/internal/export"
+	"github.com/algorand/indexer/parquet"
+)
+
+// DefaultParquetRoundsPerFile is the default size of the round range stored
+// in one Parquet file of the txn and txn_participation tables.
+const DefaultParquetRoundsPerFile = export.DefaultRoundsPerFile
+
+// ParquetExportOptions configure ExportParquet.
+type ParquetExportOptions struct {
+	// Dir is the output directory. Each table is written to its own
+	// subdirectory.
+	Dir string
+
+	// RoundsPerFile is the size of the round range stored in one file of the
+	// txn and txn_participation tables.
+	RoundsPerFile uint64
+
+	// FirstRound and LastRound bound the exported transactions. LastRound
+	// defaults to the last round in the database when nil.
+	FirstRound uint64
+	LastRound  *uint64
+
+	// Codec is the Parquet compression codec.
+	Codec parquet.Codec
+}
+
+// ParquetExportResult describes the files written by ExportParquet.
+type ParquetExportResult struct {
+	// Round is the database round of the account, asset and application
+	// snapshots.
+	Round uint64
+
+	// Files lists the written files by table.
+	Files map[string][]string
+}
+
+// ExportParquet writes the transactions of a round range, and a snapshot of
+// the current account, asset and application state, to Parquet files. The
+// whole export reads one consistent view of the database, so it can run
+// while the daemon imports new rounds.
+func (db *IndexerDb) ExportParquet(ctx context.Context, opts ParquetExportOptions) (ParquetExportResult, error) {
+	tx, err := db.db.BeginTx(ctx, readonlyRepeatableRead)
+	if err != nil {
+		return ParquetExportResult{}, fmt.Errorf("ExportParquet() begin tx err: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	round, err := db.getMaxRoundAccounted(ctx, tx)
+	if err != nil {
+		return ParquetExportResult{}, fmt.Errorf("ExportParquet() err: %w", err)
+	}
+	last := round
+	if opts.LastRound != nil {
+		last = *opts.LastRound
+	}
+
+	res, err := export.Export(ctx, tx, export.Options{
+		Dir:           opts.Dir,
+		RoundsPerFile: opts.RoundsPerFile,
+		FirstRound:    opts.FirstRound,
+		LastRound:     last,
+		SnapshotRound: round,
+		Codec:         opts.Codec,
+	})
+	if err != nil {
+		return ParquetExportResult{}, fmt.Errorf("ExportParquet() err: %w", err)
+	}
+	return ParquetExportResult{Round: round, Files: res.Files}, nil
+}
@@ -3,13 +3,18 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"io/ioutil"
 	"math"
+	"os"
 	"sync"
 	"testing"
 
 	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
 	"github.com/algorand/go-algorand/protocol"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -21,7 +26,9 @@ import (
 	"github.com/algorand/indexer/idb/postgres/internal/encoding"
 	"github.com/algorand/indexer/idb/postgres/internal/schema"
 	pgtest "github.com/algorand/indexer/idb/postgres/internal/testing"
+	"github.com/algorand/indexer/idb/postgres/internal/types"
 	pgutil "github.com/algorand/indexer/idb/postgres/internal/util"
+	"github.com/algorand/indexer/parquet"
 	"github.com/algorand/indexer/util/test"
 )
 
@@ -95,7 +102,7 @@ func assertAccountAsset(t *testing.T, db *pgxpool.Pool, addr basics.Address, ass
 	var f bool
 	var a uint64
 
-	row = db.QueryRow(context.Background(), `SELECT frozen, amount FROM account_asset as a WHERE a.addr = $1 AND assetid = $2`, addr[:], assetid)
+	row = db.QueryRow(context.Background(), `SELECT frozen, amount FROM account_asset WHERE addr_id = (SELECT addr_id FROM address WHERE addr = $1) AND assetid = $2`, addr[:], assetid)
 	err := row.Scan(&f, &a)
 	assert.NoError(t, err, "failed looking up AccountA.")
 	assert.Equal(t, frozen, f)
@@ -550,7 +557,7 @@ func assertAssetHoldingDates(t *testing.T, db *pgxpool.Pool, address basics.Addr
 	row := db.QueryRow(
 		context.Background(),
 		"SELECT deleted, created_at, closed_at FROM account_asset WHERE "+
-			"addr = $1 AND assetid = $2",
+			"addr_id = (SELECT addr_id FROM address WHERE addr = $1) AND assetid = $2",
 		address[:], assetID)
 
 	var retDeleted sql.NullBool
@@ -682,7 +689,10 @@ func TestDestroyAssetDeleteCreatorsHolding(t *testing.T) {
 
 	// Check that the manager does not have an asset holding.
 	count := queryInt(
-		db.db, "SELECT COUNT(*) FROM account_asset WHERE addr = $1", test.AccountB[:])
+		db.db,
+		"SELECT COUNT(*) FROM account_asset "+
+			"WHERE addr_id = (SELECT addr_id FROM address WHERE addr = $1)",
+		test.AccountB[:])
 	assert.Equal(t, 0, count)
 }
 
@@ -721,7 +731,8 @@ func TestAssetFreezeTxnParticipation(t *testing.T) {
 	intra := uint64(2)
 
 	query :=
-		"SELECT COUNT(*) FROM txn_participation WHERE addr = $1 AND round = $2 AND " +
+		"SELECT COUNT(*) FROM txn_participation " +
+			"WHERE addr_id = (SELECT addr_id FROM address WHERE addr = $1) AND round = $2 AND " +
 			"intra = $3"
 	acctACount := queryInt(db.db, query, test.AccountA[:], round, intra)
 	acctBCount := queryInt(db.db, query, test.AccountB[:], round, intra)
@@ -763,7 +774,8 @@ func TestInnerTxnParticipation(t *testing.T) {
 	intra := uint64(0) // the only one txn in the block
 
 	query :=
-		"SELECT COUNT(*) FROM txn_participation WHERE addr = $1 AND round = $2 AND " +
+		"SELECT COUNT(*) FROM txn_participation " +
+			"WHERE addr_id = (SELECT addr_id FROM address WHERE addr = $1) AND round = $2 AND " +
 			"intra = $3"
 	acctACount := queryInt(db.db, query, test.AccountA[:], round, intra)
 	acctBCount := queryInt(db.db, query, test.AccountB[:], round, intra)
@@ -1801,8 +1813,8 @@ func TestAddBlockTxnTxnParticipationAhead(t *testing.T) {
 		require.NoError(t, err)
 	}
 	{
-		query := `INSERT INTO txn_participation (addr, round, intra)
-			VALUES ($1, 1, 0)`
+		query := `INSERT INTO txn_participation (addr_id, round, intra)
+			VALUES (address_id($1), 1, 0)`
 		_, err := db.db.Exec(context.Background(), query, test.AccountA[:])
 		require.NoError(t, err)
 	}
@@ -1876,3 +1888,269 @@ func TestTransactionsTxnAhead(t *testing.T) {
 		require.NoError(t, row.Error)
 	}
 }
+
+// Test that the state delta handler receives the genesis allocation and the
+// state delta of every committed round.
+func TestStateDeltaHandler(t *testing.T) {
+	_, connStr, shutdownFunc := pgtest.SetupPostgres(t)
+	defer shutdownFunc()
+
+	var rounds []basics.Round
+	var deltas []ledgercore.StateDelta
+	opts := idb.IndexerDbOptions{
+		StateDeltaHandler: func(round basics.Round, delta *ledgercore.StateDelta) error {
+			rounds = append(rounds, round)
+			deltas = append(deltas, *delta)
+			return nil
+		},
+	}
+	db, _, err := OpenPostgres(connStr, opts, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	genesis := test.MakeGenesis()
+	require.NoError(t, db.LoadGenesis(genesis))
+	block := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&block))
+
+	txn := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	block, err = test.MakeBlockForTxns(block.BlockHeader, &txn)
+	require.NoError(t, err)
+	require.NoError(t, db.AddBlock(&block))
+
+	require.Equal(t, []basics.Round{0, 1}, rounds)
+	assert.Equal(t, len(genesis.Allocation), deltas[0].Accts.Len())
+
+	_, ok := deltas[1].Accts.Get(test.AccountA)
+	assert.True(t, ok)
+	_, ok = deltas[1].Accts.Get(test.AccountB)
+	assert.True(t, ok)
+}
+
+// Test that a round is not committed when the state delta handler fails, and
+// that it can be added again.
+func TestStateDeltaHandlerError(t *testing.T) {
+	_, connStr, shutdownFunc := pgtest.SetupPostgres(t)
+	defer shutdownFunc()
+
+	var rounds []basics.Round
+	handlerErr := errors.New("handler failed")
+	opts := idb.IndexerDbOptions{
+		StateDeltaHandler: func(round basics.Round, delta *ledgercore.StateDelta) error {
+			rounds = append(rounds, round)
+			if len(rounds) == 2 {
+				return handlerErr
+			}
+			return nil
+		},
+	}
+	db, _, err := OpenPostgres(connStr, opts, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.LoadGenesis(test.MakeGenesis()))
+	block := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&block))
+
+	txn := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	block, err = test.MakeBlockForTxns(block.BlockHeader, &txn)
+	require.NoError(t, err)
+	err = db.AddBlock(&block)
+	assert.True(t, errors.Is(err, handlerErr))
+
+	next, err := db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), next)
+
+	require.NoError(t, db.AddBlock(&block))
+	assert.Equal(t, []basics.Round{0, 1, 1}, rounds)
+	next, err = db.GetNextRoundToAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), next)
+}
+
+func TestExportParquet(t *testing.T) {
+	_, connStr, shutdownFunc := pgtest.SetupPostgres(t)
+	defer shutdownFunc()
+	db, _, err := OpenPostgres(connStr, idb.IndexerDbOptions{}, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.LoadGenesis(test.MakeGenesis()))
+	block := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&block))
+
+	txn := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+	block, err = test.MakeBlockForTxns(block.BlockHeader, &txn)
+	require.NoError(t, err)
+	require.NoError(t, db.AddBlock(&block))
+
+	dir, err := ioutil.TempDir("", "parquet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	res, err := db.ExportParquet(context.Background(), ParquetExportOptions{
+		Dir:           dir,
+		RoundsPerFile: DefaultParquetRoundsPerFile,
+		Codec:         parquet.CodecGzip,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), res.Round)
+
+	require.Len(t, res.Files["txn"], 1)
+	f, err := parquet.ReadFile(res.Files["txn"][0])
+	require.NoError(t, err)
+	require.Len(t, f.Rows, 1)
+	assert.Equal(t, test.AccountA.String(), f.Rows[0]["sender"])
+	assert.Equal(t, test.AccountB.String(), f.Rows[0]["receiver"])
+	assert.Equal(t, "pay", f.Rows[0]["type"])
+
+	require.Len(t, res.Files["account"], 1)
+	f, err = parquet.ReadFile(res.Files["account"][0])
+	require.NoError(t, err)
+	assert.NotEmpty(t, f.Rows)
+	assert.Equal(t, "1", f.Metadata["algorand.round"])
+}
+
+// addPayments adds a round with a payment from AccountA to AccountB after
+// `block` for each of `rounds`, and returns the last block.
+func addPayments(t *testing.T, db *IndexerDb, block bookkeeping.Block, rounds int) bookkeeping.Block {
+	for i := 0; i < rounds; i++ {
+		txn := test.MakePaymentTxn(
+			1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+		var err error
+		block, err = test.MakeBlockForTxns(block.BlockHeader, &txn)
+		require.NoError(t, err)
+		require.NoError(t, db.AddBlock(&block))
+	}
+	return block
+}
+
+// countPartitions returns the number of partitions of `table`.
+func countPartitions(db *IndexerDb, table string) int {
+	return queryInt(
+		db.db, "SELECT COUNT(*) FROM pg_inherits WHERE inhparent = $1::regclass", table)
+}
+
+// Test that a new database is partitioned and that AddBlock() creates the
+// partitions of new rounds.
+func TestPartitionedTables(t *testing.T) {
+	_, connStr, shutdownFunc := pgtest.SetupPostgres(t)
+	defer shutdownFunc()
+	db, _, err := OpenPostgres(connStr, idb.IndexerDbOptions{PartitionRounds: 2}, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.LoadGenesis(test.MakeGenesis()))
+	block := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&block))
+	addPayments(t, db, block, 3)
+
+	// Rounds [0, 2) and [2, 4).
+	assert.Equal(t, 2, countPartitions(db, "txn"))
+	assert.Equal(t, 2, countPartitions(db, "txn_participation"))
+	assert.Equal(t, 2, queryInt(db.db, "SELECT COUNT(*) FROM txn_2"))
+
+	rowsCh, _ := db.Transactions(
+		context.Background(), idb.TransactionFilter{Address: test.AccountA[:], MinRound: 2})
+	var rounds []uint64
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		rounds = append(rounds, row.Round)
+	}
+	assert.Equal(t, []uint64{3, 2}, rounds)
+}
+
+// Test that pruning the transactions drops the partitions of the pruned rounds.
+func TestPrunePartitions(t *testing.T) {
+	_, connStr, shutdownFunc := pgtest.SetupPostgres(t)
+	defer shutdownFunc()
+	db, _, err := OpenPostgres(connStr, idb.IndexerDbOptions{PartitionRounds: 2}, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.LoadGenesis(test.MakeGenesis()))
+	block := test.MakeGenesisBlock()
+	require.NoError(t, db.AddBlock(&block))
+	addPayments(t, db, block, 4)
+
+	// [0, 2) is dropped, [2, 4) only loses round 2.
+	require.NoError(t, db.PruneTransactions(3))
+	assert.Equal(t, 2, countPartitions(db, "txn"))
+	assert.Equal(t, 2, countPartitions(db, "txn_participation"))
+	assert.Equal(t, 1, queryInt(db.db, "SELECT COUNT(*) FROM txn_2"))
+	assert.Equal(t, 2, queryInt(db.db, "SELECT COUNT(*) FROM txn_participation_2"))
+
+	require.NoError(t, db.PruneTransactions(4))
+	assert.Equal(t, 1, countPartitions(db, "txn"))
+	assert.Equal(t, 1, queryInt(db.db, "SELECT COUNT(*) FROM txn"))
+}
+
+// Test that the partitioning migration converts an existing database, and that
+// the partitioning cannot change afterwards.
+func TestPartitionExistingDatabase(t *testing.T) {
+	_, connStr, shutdownFunc := pgtest.SetupPostgres(t)
+	defer shutdownFunc()
+
+	db := setupIdbWithConnectionString(
+		t, connStr, test.MakeGenesis(), test.MakeGenesisBlock())
+	block := addPayments(t, db, test.MakeGenesisBlock(), 2)
+	db.Close()
+
+	db, availableCh, err := OpenPostgres(connStr, idb.IndexerDbOptions{PartitionRounds: 2}, nil)
+	require.NoError(t, err)
+	defer db.Close()
+	<-availableCh
+
+	assert.Equal(t, 2, countPartitions(db, "txn"))
+	assert.Equal(t, 2, queryInt(db.db, "SELECT COUNT(*) FROM txn"))
+	assert.Equal(t, 4, queryInt(db.db, "SELECT COUNT(*) FROM txn_participation"))
+
+	addPayments(t, db, block, 2)
+	assert.Equal(t, 3, countPartitions(db, "txn_participation"))
+
+	rowsCh, _ := db.Transactions(context.Background(), idb.TransactionFilter{})
+	count := 0
+	for row := range rowsCh {
+		require.NoError(t, row.Error)
+		count++
+	}
+	assert.Equal(t, 4, count)
+
+	_, _, err = OpenPostgres(connStr, idb.IndexerDbOptions{PartitionRounds: 3}, nil)
+	assert.Error(t, err)
+}
+
+// Test that the group and lease backfill fills the root and inner rows imported
+// before the columns existed.
+func TestBackfillTxnGroupAndLease(t *testing.T) {
+	db, shutdownFunc := setupIdb(t, test.MakeGenesis(), test.MakeGenesisBlock())
+	defer shutdownFunc()
+
+	var appAddr basics.Address
+	appAddr[1] = 99
+	appCall := test.MakeAppCallWithInnerTxn(test.AccountA, appAddr, test.AccountB, appAddr, test.AccountC)
+	appCall.Txn.Lease[0] = 1
+	// The second level inner transaction, intra 3.
+	inner := &appCall.ApplyData.EvalDelta.InnerTxns[1].ApplyData.EvalDelta.InnerTxns[0]
+	inner.Txn.Lease[0] = 2
+
+	block, err := test.MakeBlockForTxns(test.MakeGenesisBlock().BlockHeader, &appCall)
+	require.NoError(t, err)
+	require.NoError(t, db.AddBlock(&block))
+
+	_, err = db.db.Exec(context.Background(), "UPDATE txn SET txgroup = NULL, lease = NULL")
+	require.NoError(t, err)
+
+	state := types.MigrationState{NextMigration: len(migrations) - 1}
+	require.NoError(t, backfillTxnGroupAndLease(db, &state))
+	assert.Equal(t, len(migrations), state.NextMigration)
+
+	query := "SELECT get_byte(lease, 0) FROM txn WHERE round = 1 AND intra = $1"
+	assert.Equal(t, 1, queryInt(db.db, query, 0))
+	assert.Equal(t, 2, queryInt(db.db, query, 3))
+	assert.Equal(t, 2, queryInt(db.db, "SELECT COUNT(*) FROM txn WHERE lease IS NULL"))
+}
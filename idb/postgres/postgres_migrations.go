@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/algorand/go-algorand/data/transactions"
 	"github.com/jackc/pgx/v4"
 
 	"github.com/algorand/indexer/idb"
@@ -16,6 +17,7 @@ import (
 	"github.com/algorand/indexer/idb/postgres/internal/encoding"
 	"github.com/algorand/indexer/idb/postgres/internal/schema"
 	"github.com/algorand/indexer/idb/postgres/internal/types"
+	"github.com/algorand/indexer/idb/postgres/internal/writer"
 )
 
 func init() {
@@ -48,6 +50,12 @@ func init() {
 		{upgradeNotSupported, true, "change import state format"},
 		{upgradeNotSupported, true, "notify the user that upgrade is not supported"},
 		{dropTxnBytesColumn, true, "drop txnbytes column"},
+		{addStateUndo, true, "add state undo records for rollback"},
+		{addTxnGroupAndLease, true, "add txn group and lease columns"},
+		{addAddressIDs, true, "replace addresses with address ids in txn_participation and account_asset"},
+		{addTxnParticipationRoundIndex, false, "add round index of txn_participation for pruning"},
+		{backfillTxnGroupAndLease, false, "fill the txn group and lease columns of the imported transactions"},
+		{addAccountAssetAssetIndex, false, "add the asset index of account_asset for paging the asset balances"},
 	}
 }
 
@@ -89,7 +97,6 @@ func needsMigration(state types.MigrationState) bool {
 // upsertMigrationState updates the migration state, and optionally increments
 // the next counter with an existing transaction.
 // If `tx` is nil, use a normal query.
-//lint:ignore U1000 this function might be used in a future migration
 func upsertMigrationState(db *IndexerDb, tx pgx.Tx, state *types.MigrationState) error {
 	migrationStateJSON := encoding.EncodeMigrationState(state)
 	err := db.setMetastate(tx, schema.MigrationMetastateKey, string(migrationStateJSON))
@@ -101,8 +108,9 @@ func upsertMigrationState(db *IndexerDb, tx pgx.Tx, state *types.MigrationState)
 }
 
 // Returns an error object and a channel that gets closed when blocking migrations
-// finish running successfully.
-func (db *IndexerDb) runAvailableMigrations() (chan struct{}, error) {
+// finish running successfully. Unless `partitionRounds` is 0, the transaction
+// tables are partitioned after the other migrations.
+func (db *IndexerDb) runAvailableMigrations(partitionRounds uint64) (chan struct{}, error) {
 	state, err := db.getMigrationState(nil)
 	if err == idb.ErrorNotInitialized {
 		state = types.MigrationState{}
@@ -123,6 +131,18 @@ func (db *IndexerDb) runAvailableMigrations() (chan struct{}, error) {
 		nextMigration++
 	}
 
+	if partitionRounds != 0 {
+		tasks = append(tasks, migration.Task{
+			Handler: func() error {
+				return db.partitionTxnTables(partitionRounds)
+			},
+			MigrationID: nextMigration,
+			Description: fmt.Sprintf(
+				"partition the txn and txn_participation tables by %d rounds", partitionRounds),
+			DBUnavailable: true,
+		})
+	}
+
 	if len(tasks) > 0 {
 		// Add a task to mark migrations as done instead of using a channel.
 		tasks = append(tasks, migration.Task{
@@ -224,3 +244,260 @@ func dropTxnBytesColumn(db *IndexerDb, migrationState *types.MigrationState) err
 	return sqlMigration(
 		db, migrationState, []string{"ALTER TABLE txn DROP COLUMN txnbytes"})
 }
+
+// addStateUndo creates the state_undo table and its triggers, see
+// setup_postgres.sql.
+func addStateUndo(db *IndexerDb, migrationState *types.MigrationState) error {
+	sqlLines := []string{
+		`CREATE TABLE IF NOT EXISTS state_undo (
+			seq bigserial PRIMARY KEY,
+			round bigint NOT NULL,
+			tbl text NOT NULL,
+			op char(1) NOT NULL,
+			row_data text NOT NULL)`,
+		"CREATE INDEX IF NOT EXISTS state_undo_round ON state_undo (round)",
+		`CREATE OR REPLACE FUNCTION state_undo_log() RETURNS trigger AS $$
+		DECLARE
+			undo_round text := current_setting('indexer.undo_round', true);
+		BEGIN
+			IF undo_round IS NULL OR undo_round = '' THEN
+				RETURN NULL;
+			END IF;
+			IF TG_OP = 'INSERT' THEN
+				INSERT INTO state_undo (round, tbl, op, row_data) VALUES (undo_round::bigint, TG_TABLE_NAME, 'I', NEW::text);
+			ELSE
+				INSERT INTO state_undo (round, tbl, op, row_data) VALUES (undo_round::bigint, TG_TABLE_NAME, left(TG_OP, 1), OLD::text);
+			END IF;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql`,
+	}
+	for _, table := range undoTables {
+		sqlLines = append(sqlLines,
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_undo ON %s", table.name, table.name),
+			fmt.Sprintf(
+				"CREATE TRIGGER %s_undo AFTER INSERT OR UPDATE OR DELETE ON %s "+
+					"FOR EACH ROW EXECUTE PROCEDURE state_undo_log()",
+				table.name, table.name))
+	}
+	return sqlMigration(db, migrationState, sqlLines)
+}
+
+// addTxnGroupAndLease adds the indexed group and lease columns of the txn table.
+// The rows imported before are filled by backfillTxnGroupAndLease.
+func addTxnGroupAndLease(db *IndexerDb, migrationState *types.MigrationState) error {
+	return sqlMigration(db, migrationState, []string{
+		"ALTER TABLE txn ADD COLUMN IF NOT EXISTS txgroup bytea",
+		"ALTER TABLE txn ADD COLUMN IF NOT EXISTS lease bytea",
+		"CREATE INDEX IF NOT EXISTS txn_by_group ON txn ( txgroup ) WHERE txgroup IS NOT NULL",
+		"CREATE INDEX IF NOT EXISTS txn_by_lease ON txn ( lease ) WHERE lease IS NOT NULL",
+	})
+}
+
+// addAddressIDs creates the address table and rewrites the txn_participation and
+// account_asset tables with address ids, see setup_postgres.sql. The undo
+// records hold rows of the old account_asset table, so they are removed and the
+// rounds before the migration cannot be rolled back.
+func addAddressIDs(db *IndexerDb, migrationState *types.MigrationState) error {
+	return sqlMigration(db, migrationState, []string{
+		`CREATE TABLE IF NOT EXISTS address (
+			addr_id bigserial PRIMARY KEY,
+			addr bytea NOT NULL)`,
+		`INSERT INTO address (addr)
+			SELECT addr FROM txn_participation UNION SELECT addr FROM account_asset`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS address_addr ON address ( addr )",
+		`CREATE OR REPLACE FUNCTION address_id(a bytea) RETURNS bigint AS $$
+		DECLARE
+			id bigint;
+		BEGIN
+			SELECT addr_id INTO id FROM address WHERE addr = a;
+			IF id IS NULL THEN
+				INSERT INTO address (addr) VALUES (a) RETURNING addr_id INTO id;
+			END IF;
+			RETURN id;
+		END;
+		$$ LANGUAGE plpgsql`,
+
+		`CREATE TABLE txn_participation_new (
+			addr_id bigint NOT NULL,
+			round bigint NOT NULL,
+			intra integer NOT NULL)`,
+		`INSERT INTO txn_participation_new
+			SELECT a.addr_id, p.round, p.intra
+			FROM txn_participation p JOIN address a ON p.addr = a.addr`,
+		"DROP TABLE txn_participation",
+		"ALTER TABLE txn_participation_new RENAME TO txn_participation",
+		"CREATE UNIQUE INDEX txn_participation_i ON txn_participation ( addr_id, round DESC, intra DESC )",
+
+		`CREATE TABLE account_asset_new (
+			addr_id bigint NOT NULL,
+			assetid bigint NOT NULL,
+			amount numeric(20) NOT NULL,
+			frozen boolean NOT NULL,
+			deleted bool NOT NULL,
+			created_at bigint NOT NULL,
+			closed_at bigint)`,
+		`INSERT INTO account_asset_new
+			SELECT a.addr_id, aa.assetid, aa.amount, aa.frozen, aa.deleted, aa.created_at, aa.closed_at
+			FROM account_asset aa JOIN address a ON aa.addr = a.addr`,
+		"DROP TABLE account_asset",
+		"ALTER TABLE account_asset_new RENAME TO account_asset",
+		"ALTER TABLE account_asset ADD PRIMARY KEY (addr_id, assetid)",
+		"CREATE INDEX account_asset_by_addr_partial ON account_asset(addr_id) WHERE NOT deleted",
+		"CREATE TRIGGER account_asset_undo AFTER INSERT OR UPDATE OR DELETE ON account_asset " +
+			"FOR EACH ROW EXECUTE PROCEDURE state_undo_log()",
+		"DELETE FROM state_undo",
+	})
+}
+
+// addTxnParticipationRoundIndex adds the block range index that the transaction
+// pruning uses to find the old rows of txn_participation. It is only effective
+// for the rows stored in round order.
+func addTxnParticipationRoundIndex(db *IndexerDb, migrationState *types.MigrationState) error {
+	return sqlMigration(db, migrationState, []string{
+		"CREATE INDEX IF NOT EXISTS txn_participation_round ON txn_participation USING brin ( round )",
+	})
+}
+
+// addAccountAssetAssetIndex adds the index that pages the balances of an
+// asset in addr_id order. It replaces the optional index of the same name on
+// the addresses of account_asset.
+func addAccountAssetAssetIndex(db *IndexerDb, migrationState *types.MigrationState) error {
+	return sqlMigration(db, migrationState, []string{
+		"DROP INDEX IF EXISTS account_asset_asset",
+		"CREATE INDEX account_asset_asset ON account_asset ( assetid, addr_id ASC )",
+	})
+}
+
+// backfillBatchRounds is the number of rounds updated in one transaction by
+// backfillTxnGroupAndLease.
+const backfillBatchRounds = 1000
+
+// backfillTxnGroupAndLease fills the group and lease columns of the txn rows
+// imported before addTxnGroupAndLease, a batch of rounds per transaction. The
+// values of the inner transactions are read from the json of their root
+// transaction, the inner rows do not hold their own. The next round to fill is
+// saved in the migration state so that a restart continues from it.
+func backfillTxnGroupAndLease(db *IndexerDb, migrationState *types.MigrationState) error {
+	end, err := db.GetNextRoundToAccount()
+	if err == idb.ErrorNotInitialized {
+		end = 0
+	} else if err != nil {
+		return fmt.Errorf("backfillTxnGroupAndLease() err: %w", err)
+	}
+
+	for round := uint64(migrationState.NextRound); round < end; round += backfillBatchRounds {
+		nextState := *migrationState
+		nextState.NextRound = int64(round + backfillBatchRounds)
+		f := func(tx pgx.Tx) error {
+			err := backfillTxnGroupAndLeaseRounds(tx, round, round+backfillBatchRounds)
+			if err != nil {
+				return err
+			}
+			return upsertMigrationState(db, tx, &nextState)
+		}
+		err = db.txWithRetry(serializable, f)
+		if err != nil {
+			return fmt.Errorf("backfillTxnGroupAndLease() rounds %d err: %w", round, err)
+		}
+		*migrationState = nextState
+	}
+
+	nextState := *migrationState
+	nextState.NextMigration++
+	nextState.NextRound = 0
+	err = upsertMigrationState(db, nil, &nextState)
+	if err != nil {
+		return fmt.Errorf("backfillTxnGroupAndLease() err: %w", err)
+	}
+	*migrationState = nextState
+	return nil
+}
+
+// txnGroupAndLease is the group and lease of the txn row `intra` of a round.
+type txnGroupAndLease struct {
+	intra uint64
+	group interface{}
+	lease interface{}
+}
+
+// appendInnerGroupAndLease appends the group and lease of the inner
+// transactions of `stxnad`, numbered in the preorder of the writer from
+// `intra`. It returns the intra of the next transaction.
+func appendInnerGroupAndLease(stxnad *transactions.SignedTxnWithAD, intra uint64, out []txnGroupAndLease) (uint64, []txnGroupAndLease) {
+	for i := range stxnad.ApplyData.EvalDelta.InnerTxns {
+		itxn := &stxnad.ApplyData.EvalDelta.InnerTxns[i]
+		out = append(out, txnGroupAndLease{
+			intra: intra,
+			group: writer.NullIfZeroDigest(itxn.Txn.Group),
+			lease: writer.NullIfZeroDigest(itxn.Txn.Lease),
+		})
+		intra, out = appendInnerGroupAndLease(itxn, intra+1, out)
+	}
+	return intra, out
+}
+
+// backfillTxnGroupAndLeaseRounds fills the group and lease columns of the txn
+// rows of the rounds [min, max).
+func backfillTxnGroupAndLeaseRounds(tx pgx.Tx, min, max uint64) error {
+	rows, err := tx.Query(
+		context.Background(),
+		"SELECT round, intra, txn FROM txn WHERE round >= $1 AND round < $2 AND txid IS NOT NULL",
+		min, max)
+	if err != nil {
+		return fmt.Errorf("backfillTxnGroupAndLeaseRounds() query err: %w", err)
+	}
+	defer rows.Close()
+
+	var batch pgx.Batch
+	for rows.Next() {
+		var round, intra uint64
+		var txnjson []byte
+		err = rows.Scan(&round, &intra, &txnjson)
+		if err != nil {
+			return fmt.Errorf("backfillTxnGroupAndLeaseRounds() scan err: %w", err)
+		}
+		stxnad, err := encoding.DecodeSignedTxnWithAD(txnjson)
+		if err != nil {
+			return fmt.Errorf(
+				"backfillTxnGroupAndLeaseRounds() decode round %d intra %d err: %w",
+				round, intra, err)
+		}
+
+		values := []txnGroupAndLease{{
+			intra: intra,
+			group: writer.NullIfZeroDigest(stxnad.Txn.Group),
+			lease: writer.NullIfZeroDigest(stxnad.Txn.Lease),
+		}}
+		_, values = appendInnerGroupAndLease(&stxnad, intra+1, values)
+		for _, v := range values {
+			if v.group != nil || v.lease != nil {
+				batch.Queue(
+					"UPDATE txn SET txgroup = $3, lease = $4 WHERE round = $1 AND intra = $2",
+					round, v.intra, v.group, v.lease)
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("backfillTxnGroupAndLeaseRounds() rows err: %w", err)
+	}
+	rows.Close()
+
+	if batch.Len() == 0 {
+		return nil
+	}
+	results := tx.SendBatch(context.Background(), &batch)
+	// Clean the results off the connection so that the next query works.
+	defer results.Close()
+	for i := 0; i < batch.Len(); i++ {
+		_, err = results.Exec()
+		if err != nil {
+			return fmt.Errorf("backfillTxnGroupAndLeaseRounds() update err: %w", err)
+		}
+	}
+	err = results.Close()
+	if err != nil {
+		return fmt.Errorf("backfillTxnGroupAndLeaseRounds() close results err: %w", err)
+	}
+	return nil
+}
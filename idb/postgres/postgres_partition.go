@@ -0,0 +1,169 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/postgres/internal/schema"
+	"github.com/algorand/indexer/idb/postgres/internal/writer"
+)
+
+// getPartitionRounds returns the number of rounds of each partition of the txn
+// and txn_participation tables, 0 if they are not partitioned.
+func (db *IndexerDb) getPartitionRounds() (uint64, error) {
+	value, err := db.getMetastate(context.Background(), nil, schema.PartitionRoundsMetastateKey)
+	if err == idb.ErrorNotInitialized {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getPartitionRounds() err: %w", err)
+	}
+
+	rounds, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("getPartitionRounds() unable to parse v: \"%s\" err: %w", value, err)
+	}
+	return rounds, nil
+}
+
+// checkPartitionRounds loads the partitioning of the database and returns
+// whether the tables need to be partitioned by `partitionRounds` rounds.
+func (db *IndexerDb) checkPartitionRounds(partitionRounds uint64) (bool, error) {
+	var err error
+	db.partitionRounds, err = db.getPartitionRounds()
+	if err != nil {
+		return false, fmt.Errorf("checkPartitionRounds() err: %w", err)
+	}
+
+	if db.partitionRounds == 0 {
+		return partitionRounds != 0, nil
+	}
+	if partitionRounds != 0 && partitionRounds != db.partitionRounds {
+		return false, fmt.Errorf(
+			"checkPartitionRounds() the database is partitioned by %d rounds, not %d",
+			db.partitionRounds, partitionRounds)
+	}
+	return false, nil
+}
+
+// partitionTxnTables rewrites the txn and txn_participation tables as tables
+// partitioned by ranges of `partitionRounds` rounds, see setup_postgres.sql.
+func (db *IndexerDb) partitionTxnTables(partitionRounds uint64) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	f := func(tx pgx.Tx) error {
+		ctx := context.Background()
+
+		// The transaction tables can be ahead of the import state.
+		var maxRound uint64
+		err := tx.QueryRow(
+			ctx,
+			"SELECT coalesce(greatest("+
+				"(SELECT max(round) FROM txn), (SELECT max(round) FROM txn_participation)), 0)").
+			Scan(&maxRound)
+		if err != nil {
+			return fmt.Errorf("partitionTxnTables() max round err: %w", err)
+		}
+
+		queries := []string{
+			"ALTER TABLE txn RENAME TO txn_unpartitioned",
+			"ALTER TABLE txn_participation RENAME TO txn_participation_unpartitioned",
+			`CREATE TABLE txn (
+				round bigint NOT NULL,
+				intra integer NOT NULL,
+				typeenum smallint NOT NULL,
+				asset bigint NOT NULL,
+				txid bytea,
+				txn jsonb NOT NULL,
+				extra jsonb NOT NULL,
+				txgroup bytea,
+				lease bytea)
+				PARTITION BY RANGE (round)`,
+			`CREATE TABLE txn_participation (
+				addr_id bigint NOT NULL,
+				round bigint NOT NULL,
+				intra integer NOT NULL)
+				PARTITION BY RANGE (round)`,
+		}
+		for _, query := range queries {
+			_, err = tx.Exec(ctx, query)
+			if err != nil {
+				return fmt.Errorf("partitionTxnTables() exec \"%s\" err: %w", query, err)
+			}
+		}
+
+		for start := uint64(0); start <= maxRound; start += partitionRounds {
+			err = writer.AddPartitions(tx, start, partitionRounds)
+			if err != nil {
+				return fmt.Errorf("partitionTxnTables() err: %w", err)
+			}
+		}
+
+		// Indexes are created after the copy, which is faster.
+		queries = []string{
+			`INSERT INTO txn (round, intra, typeenum, asset, txid, txn, extra, txgroup, lease)
+				SELECT round, intra, typeenum, asset, txid, txn, extra, txgroup, lease
+				FROM txn_unpartitioned`,
+			`INSERT INTO txn_participation (addr_id, round, intra)
+				SELECT addr_id, round, intra FROM txn_participation_unpartitioned`,
+			"DROP TABLE txn_unpartitioned",
+			"DROP TABLE txn_participation_unpartitioned",
+			"ALTER TABLE txn ADD PRIMARY KEY (round, intra)",
+			"CREATE INDEX txn_by_tixid ON txn ( txid )",
+			"CREATE INDEX txn_by_group ON txn ( txgroup ) WHERE txgroup IS NOT NULL",
+			"CREATE INDEX txn_by_lease ON txn ( lease ) WHERE lease IS NOT NULL",
+			"CREATE UNIQUE INDEX txn_participation_i ON txn_participation ( addr_id, round DESC, intra DESC )",
+			"CREATE INDEX txn_participation_round ON txn_participation USING brin ( round )",
+		}
+		for _, query := range queries {
+			_, err = tx.Exec(ctx, query)
+			if err != nil {
+				return fmt.Errorf("partitionTxnTables() exec \"%s\" err: %w", query, err)
+			}
+		}
+
+		err = db.setMetastate(
+			tx, schema.PartitionRoundsMetastateKey, strconv.FormatUint(partitionRounds, 10))
+		if err != nil {
+			return fmt.Errorf("partitionTxnTables() err: %w", err)
+		}
+		return nil
+	}
+	err := db.txWithRetry(serializable, f)
+	if err != nil {
+		return fmt.Errorf("partitionTxnTables() err: %w", err)
+	}
+
+	db.partitionRounds = partitionRounds
+	db.partitionedUntil = 0
+	return nil
+}
+
+// addPartitions creates the partitions of the transaction tables for `round`
+// in their own database transaction, so that the parallel transactions of
+// AddBlock() see them. Must be called with the accounting lock held.
+func (db *IndexerDb) addPartitions(round uint64) error {
+	if db.partitionRounds == 0 || round < db.partitionedUntil {
+		return nil
+	}
+
+	start := writer.PartitionStart(round, db.partitionRounds)
+	f := func(tx pgx.Tx) error {
+		return writer.AddPartitions(tx, start, db.partitionRounds)
+	}
+	err := db.txWithRetry(serializable, f)
+	if err != nil {
+		return fmt.Errorf("addPartitions() err: %w", err)
+	}
+
+	db.partitionedUntil = start + db.partitionRounds
+	return nil
+}
@@ -117,7 +117,7 @@ func TestWriteReadAccountData(t *testing.T) {
 		w, err := writer.MakeWriter(tx)
 		require.NoError(t, err)
 
-		err = w.AddBlock(&bookkeeping.Block{}, transactions.Payset{}, delta)
+		err = w.AddBlock(&bookkeeping.Block{}, transactions.Payset{}, delta, nil)
 		require.NoError(t, err)
 
 		w.Close()
@@ -0,0 +1,97 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/postgres/internal/schema"
+	"github.com/algorand/indexer/idb/postgres/internal/writer"
+)
+
+// getOldestRound returns the oldest round with transactions, 0 if transactions
+// were never pruned. If `tx` is nil, use a normal query.
+func (db *IndexerDb) getOldestRound(ctx context.Context, tx pgx.Tx) (uint64, error) {
+	value, err := db.getMetastate(ctx, tx, schema.OldestRoundMetastateKey)
+	if err == idb.ErrorNotInitialized {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getOldestRound() err: %w", err)
+	}
+
+	round, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("getOldestRound() unable to parse v: \"%s\" err: %w", value, err)
+	}
+	return round, nil
+}
+
+// GetOldestRound is part of idb.IndexerDb.
+func (db *IndexerDb) GetOldestRound() (uint64, error) {
+	return db.getOldestRound(context.Background(), nil)
+}
+
+// PruneTransactions is part of idb.IndexerDb. The partitions of partitioned
+// tables that only hold pruned rounds are dropped.
+func (db *IndexerDb) PruneTransactions(round uint64) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	f := func(tx pgx.Tx) error {
+		ctx := context.Background()
+
+		oldest, err := db.getOldestRound(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("PruneTransactions() err: %w", err)
+		}
+		if round <= oldest {
+			return nil
+		}
+
+		// The rows of the partitions that only hold pruned rounds are dropped
+		// with them, the other rows are deleted.
+		deleteFrom := uint64(0)
+		if db.partitionRounds != 0 {
+			start := writer.PartitionStart(oldest, db.partitionRounds)
+			for ; start+db.partitionRounds <= round; start += db.partitionRounds {
+				for _, table := range writer.PartitionedTables {
+					_, err = tx.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s_%d", table, start))
+					if err != nil {
+						return fmt.Errorf(
+							"PruneTransactions() drop %s partition %d err: %w", table, start, err)
+					}
+				}
+			}
+			deleteFrom = start
+		}
+
+		if deleteFrom < round {
+			for _, table := range writer.PartitionedTables {
+				_, err = tx.Exec(
+					ctx, "DELETE FROM "+table+" WHERE round >= $1 AND round < $2", deleteFrom, round)
+				if err != nil {
+					return fmt.Errorf("PruneTransactions() delete from %s err: %w", table, err)
+				}
+			}
+		}
+
+		err = db.setMetastate(tx, schema.OldestRoundMetastateKey, strconv.FormatUint(round, 10))
+		if err != nil {
+			return fmt.Errorf("PruneTransactions() err: %w", err)
+		}
+		return nil
+	}
+	err := db.txWithRetry(serializable, f)
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() err: %w", err)
+	}
+
+	return nil
+}
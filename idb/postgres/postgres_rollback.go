@@ -0,0 +1,136 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// undoTables are the tables with undo triggers, with their primary keys.
+var undoTables = []struct {
+	name string
+	pk   []string
+}{
+	{"metastate", []string{"k"}},
+	{"account", []string{"addr"}},
+	{"account_asset", []string{"addr_id", "assetid"}},
+	{"asset", []string{"index"}},
+	{"app", []string{"index"}},
+	{"account_app", []string{"addr", "app"}},
+}
+
+// setUndoRound makes the undo triggers record the changes of `tx` as changes
+// of `round`.
+func setUndoRound(tx pgx.Tx, round uint64) error {
+	_, err := tx.Exec(
+		context.Background(), "SELECT set_config('indexer.undo_round', $1, true)",
+		strconv.FormatUint(round, 10))
+	if err != nil {
+		return fmt.Errorf("setUndoRound() err: %w", err)
+	}
+	return nil
+}
+
+// pruneUndo removes the undo records of the rounds that are more than
+// maxRollbackRounds older than `round`. With maxRollbackRounds 0 all records
+// are removed.
+func (db *IndexerDb) pruneUndo(tx pgx.Tx, round uint64) error {
+	if db.maxRollbackRounds > 0 && round < db.maxRollbackRounds {
+		return nil
+	}
+	_, err := tx.Exec(
+		context.Background(), "DELETE FROM state_undo WHERE round <= $1",
+		round-db.maxRollbackRounds)
+	if err != nil {
+		return fmt.Errorf("pruneUndo() err: %w", err)
+	}
+	return nil
+}
+
+// Rollback is part of idb.IndexerDb.
+func (db *IndexerDb) Rollback(round uint64) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	f := func(tx pgx.Tx) error {
+		ctx := context.Background()
+
+		maxRound, err := db.getMaxRoundAccounted(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("Rollback() err: %w", err)
+		}
+		if round > maxRound {
+			return fmt.Errorf("Rollback() round %d is after the last round %d", round, maxRound)
+		}
+
+		// Every round updates the import state, so every round written with undo
+		// records has at least one.
+		var rounds uint64
+		err = tx.QueryRow(
+			ctx, "SELECT COUNT(DISTINCT round) FROM state_undo WHERE round > $1",
+			round).Scan(&rounds)
+		if err != nil {
+			return fmt.Errorf("Rollback() count undo rounds err: %w", err)
+		}
+		if rounds != maxRound-round {
+			return fmt.Errorf(
+				"Rollback() %d of %d rounds have undo records err: %w",
+				rounds, maxRound-round, idb.ErrorRollbackNotAvailable)
+		}
+
+		// The first record of a row after `round` holds its value at `round`, or
+		// tells that it did not exist yet. Rows are recorded in their text form,
+		// which unlike json keeps the difference between NULL and json "null".
+		for _, table := range undoTables {
+			pkData := make([]string, len(table.pk))
+			pkMatch := make([]string, len(table.pk))
+			for i, column := range table.pk {
+				pkData[i] = fmt.Sprintf("(row_data::%s).%s", table.name, column)
+				pkMatch[i] = fmt.Sprintf("t.%s = (u.r).%s", column, column)
+			}
+
+			queries := []string{
+				fmt.Sprintf(
+					"CREATE TEMP TABLE undo_%[1]s ON COMMIT DROP AS "+
+						"SELECT DISTINCT ON (%[2]s) op, row_data::%[1]s AS r FROM state_undo "+
+						"WHERE tbl = '%[1]s' AND round > %[3]d ORDER BY %[2]s, seq",
+					table.name, strings.Join(pkData, ", "), round),
+				fmt.Sprintf(
+					"DELETE FROM %[1]s t USING undo_%[1]s u WHERE %[2]s",
+					table.name, strings.Join(pkMatch, " AND ")),
+				fmt.Sprintf(
+					"INSERT INTO %[1]s SELECT (u.r).* FROM undo_%[1]s u WHERE op <> 'I'",
+					table.name),
+			}
+			for _, query := range queries {
+				_, err = tx.Exec(ctx, query)
+				if err != nil {
+					return fmt.Errorf("Rollback() undo %s err: %w", table.name, err)
+				}
+			}
+		}
+
+		for _, table := range []string{"txn", "txn_participation", "block_header", "state_undo"} {
+			_, err = tx.Exec(ctx, "DELETE FROM "+table+" WHERE round > $1", round)
+			if err != nil {
+				return fmt.Errorf("Rollback() delete from %s err: %w", table, err)
+			}
+		}
+
+		return nil
+	}
+	err := db.txWithRetry(serializable, f)
+	if err != nil {
+		return fmt.Errorf("Rollback() err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/postgres/internal/encoding"
+	"github.com/algorand/indexer/idb/postgres/internal/schema"
+)
+
+// webhookKey returns the metastate key of the webhook `id`.
+func webhookKey(id string) string {
+	return schema.WebhookMetastateKeyPrefix + id
+}
+
+// GetWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) GetWebhook(id string) (idb.Webhook, error) {
+	webhookJSON, err := db.getMetastate(context.Background(), nil, webhookKey(id))
+	if err == idb.ErrorNotInitialized {
+		return idb.Webhook{}, idb.ErrorWebhookNotFound
+	}
+	if err != nil {
+		return idb.Webhook{}, fmt.Errorf("GetWebhook() err: %w", err)
+	}
+
+	webhook, err := encoding.DecodeWebhook([]byte(webhookJSON))
+	if err != nil {
+		return idb.Webhook{}, fmt.Errorf(
+			"GetWebhook() unable to parse webhook v: \"%s\" err: %w", webhookJSON, err)
+	}
+	return webhook, nil
+}
+
+// GetWebhooks is part of idb.IndexerDb.
+func (db *IndexerDb) GetWebhooks() (map[string]idb.Webhook, error) {
+	rows, err := db.db.Query(
+		context.Background(), `SELECT k, v FROM metastate WHERE left(k, $1) = $2`,
+		len(schema.WebhookMetastateKeyPrefix), schema.WebhookMetastateKeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("GetWebhooks() query err: %w", err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]idb.Webhook)
+	for rows.Next() {
+		var key, webhookJSON string
+		err = rows.Scan(&key, &webhookJSON)
+		if err != nil {
+			return nil, fmt.Errorf("GetWebhooks() scan err: %w", err)
+		}
+		webhook, err := encoding.DecodeWebhook([]byte(webhookJSON))
+		if err != nil {
+			return nil, fmt.Errorf("GetWebhooks() decode %s err: %w", key, err)
+		}
+		res[strings.TrimPrefix(key, schema.WebhookMetastateKeyPrefix)] = webhook
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetWebhooks() query err: %w", err)
+	}
+	return res, nil
+}
+
+// SetWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) SetWebhook(id string, webhook idb.Webhook) error {
+	err := db.setMetastate(nil, webhookKey(id), string(encoding.EncodeWebhook(&webhook)))
+	if err != nil {
+		return fmt.Errorf("SetWebhook() err: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) DeleteWebhook(id string) error {
+	_, err := db.db.Exec(
+		context.Background(), `DELETE FROM metastate WHERE k = $1`, webhookKey(id))
+	if err != nil {
+		return fmt.Errorf("DeleteWebhook() err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/internal/convert"
+)
+
+func nullableInt64Ptr(x sql.NullInt64) *uint64 {
+	if !x.Valid {
+		return nil
+	}
+	return convert.Uint64Ptr(uint64(x.Int64))
+}
+
+func uintOrDefault(x *uint64) uint64 {
+	if x != nil {
+		return *x
+	}
+	return 0
+}
+
+type getAccountsRequest struct {
+	ctx         context.Context
+	tx          *sql.Tx
+	opts        idb.AccountQueryOptions
+	blockheader bookkeeping.BlockHeader
+	query       string
+	rows        *sql.Rows
+	out         chan idb.AccountRow
+	start       time.Time
+}
+
+// GetAccounts is part of idb.IndexerDB
+func (db *IndexerDb) GetAccounts(ctx context.Context, opts idb.AccountQueryOptions) (<-chan idb.AccountRow, uint64) {
+	out := make(chan idb.AccountRow, 1)
+
+	if opts.HasAssetID != 0 {
+		opts.IncludeAssetHoldings = true
+	} else if (opts.AssetGT != nil) || (opts.AssetLT != nil) {
+		err := fmt.Errorf("AssetGT=%d, AssetLT=%d, but HasAssetID=%d", uintOrDefault(opts.AssetGT), uintOrDefault(opts.AssetLT), opts.HasAssetID)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		return out, 0
+	}
+
+	// Begin transaction so we get everything at one consistent point in time and round of accounting.
+	tx, err := db.db.BeginTx(ctx, readonly)
+	if err != nil {
+		err = fmt.Errorf("account tx err %v", err)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		return out, 0
+	}
+
+	// Get round number through which accounting has been updated
+	round, err := db.getMaxRoundAccounted(ctx, tx)
+	if err != nil {
+		err = fmt.Errorf("account round err %v", err)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+
+	// Get block header for that round so we know protocol and rewards info
+	row := tx.QueryRowContext(ctx, `SELECT header FROM block_header WHERE round = ?`, round)
+	var headerBytes []byte
+	err = row.Scan(&headerBytes)
+	if err != nil {
+		err = fmt.Errorf("account round header %d err %v", round, err)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+	blockheader, err := decodeBlockHeader(headerBytes)
+	if err != nil {
+		err = fmt.Errorf("account round header %d err %v", round, err)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+
+	// Construct query for fetching accounts...
+	query, whereArgs := buildAccountQuery(opts)
+	req := &getAccountsRequest{
+		ctx:         ctx,
+		tx:          tx,
+		opts:        opts,
+		blockheader: blockheader,
+		query:       query,
+		out:         out,
+		start:       time.Now(),
+	}
+	req.rows, err = tx.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		err = fmt.Errorf("account query %#v err %v", query, err)
+		out <- idb.AccountRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+	go func() {
+		db.yieldAccountsThread(req)
+		close(req.out)
+		tx.Rollback()
+	}()
+	return out, round
+}
+
+// buildAccountQuery only selects the account rows. Holdings, created
+// creatables and local states are loaded per account by yieldAccountsThread.
+func buildAccountQuery(opts idb.AccountQueryOptions) (query string, whereArgs []interface{}) {
+	const maxWhereParts = 14
+	whereParts := make([]string, 0, maxWhereParts)
+	whereArgs = make([]interface{}, 0, maxWhereParts)
+	// filter by has-asset or has-app
+	if opts.HasAssetID != 0 {
+		aq := "SELECT addr FROM account_asset WHERE assetid = ?"
+		whereArgs = append(whereArgs, opts.HasAssetID)
+		if opts.AssetGT != nil {
+			aq += " AND amount > ?"
+			whereArgs = append(whereArgs, encodeAmount(*opts.AssetGT))
+		}
+		if opts.AssetLT != nil {
+			aq += " AND amount < ?"
+			whereArgs = append(whereArgs, encodeAmount(*opts.AssetLT))
+		}
+		whereParts = append(whereParts, "a.addr IN ("+aq+")")
+	}
+	if opts.HasAppID != 0 {
+		whereParts = append(whereParts, "a.addr IN (SELECT addr FROM account_app WHERE app = ?)")
+		whereArgs = append(whereArgs, opts.HasAppID)
+	}
+	// filters against main account table
+	if len(opts.GreaterThanAddress) > 0 {
+		whereParts = append(whereParts, "a.addr > ?")
+		whereArgs = append(whereArgs, opts.GreaterThanAddress)
+	}
+	if len(opts.EqualToAddress) > 0 {
+		whereParts = append(whereParts, "a.addr = ?")
+		whereArgs = append(whereArgs, opts.EqualToAddress)
+	}
+	if len(opts.EqualToAddresses) > 0 {
+		placeholders := make([]string, len(opts.EqualToAddresses))
+		for i, addr := range opts.EqualToAddresses {
+			placeholders[i] = "?"
+			whereArgs = append(whereArgs, addr)
+		}
+		whereParts = append(whereParts, "a.addr IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if opts.AlgosGreaterThan != nil {
+		whereParts = append(whereParts, "a.microalgos > ?")
+		whereArgs = append(whereArgs, *opts.AlgosGreaterThan)
+	}
+	if opts.AlgosLessThan != nil {
+		whereParts = append(whereParts, "a.microalgos < ?")
+		whereArgs = append(whereArgs, *opts.AlgosLessThan)
+	}
+	if !opts.IncludeDeleted {
+		whereParts = append(whereParts, "a.deleted = 0")
+	}
+	if len(opts.EqualToAuthAddr) > 0 {
+		whereParts = append(whereParts, "a.auth_addr = ?")
+		whereArgs = append(whereArgs, opts.EqualToAuthAddr)
+	}
+	query = `SELECT a.addr, a.microalgos, a.rewards_total, a.created_at, a.closed_at, a.deleted, a.rewardsbase, a.keytype, a.account_data FROM account a`
+	if len(whereParts) > 0 {
+		whereStr := strings.Join(whereParts, " AND ")
+		query += " WHERE " + whereStr
+	}
+	query += " ORDER BY a.addr ASC"
+	if opts.Limit != 0 {
+		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+	return query, whereArgs
+}
+
+func (db *IndexerDb) yieldAccountsThread(req *getAccountsRequest) {
+	count := uint64(0)
+	defer func() {
+		req.rows.Close()
+
+		end := time.Now()
+		dt := end.Sub(req.start)
+		if dt > (1 * time.Second) {
+			db.log.Warnf("long query %fs: %s", dt.Seconds(), req.query)
+		}
+	}()
+	for req.rows.Next() {
+		var addr []byte
+		var microalgos uint64
+		var rewardstotal uint64
+		var createdat sql.NullInt64
+		var closedat sql.NullInt64
+		var deleted bool
+		var rewardsbase uint64
+		var keytype sql.NullString
+		var accountData []byte
+
+		err := req.rows.Scan(
+			&addr, &microalgos, &rewardstotal, &createdat, &closedat, &deleted, &rewardsbase,
+			&keytype, &accountData)
+		if err != nil {
+			err = fmt.Errorf("account scan err %v", err)
+			req.out <- idb.AccountRow{Error: err}
+			break
+		}
+
+		account, err := db.buildAccount(req, addr, microalgos, rewardsbase, accountData)
+		if err != nil {
+			req.out <- idb.AccountRow{Error: err}
+			break
+		}
+		account.Rewards = rewardstotal
+		account.CreatedAtRound = nullableInt64Ptr(createdat)
+		account.ClosedAtRound = nullableInt64Ptr(closedat)
+		account.Deleted = convert.BoolPtr(deleted)
+		if keytype.Valid && keytype.String != "" {
+			account.SigType = convert.StringPtr(keytype.String)
+		}
+
+		select {
+		case req.out <- idb.AccountRow{Account: account}:
+			count++
+			if req.opts.Limit != 0 && count >= req.opts.Limit {
+				return
+			}
+		case <-req.ctx.Done():
+			return
+		}
+	}
+	if err := req.rows.Err(); err != nil {
+		err = fmt.Errorf("error reading rows: %v", err)
+		req.out <- idb.AccountRow{Error: err}
+	}
+}
+
+// buildAccount converts the account row to a model and loads the per account
+// tables selected by the query options.
+func (db *IndexerDb) buildAccount(req *getAccountsRequest, addr []byte, microalgos uint64, rewardsbase uint64, accountData []byte) (models.Account, error) {
+	var account models.Account
+	var aaddr basics.Address
+	copy(aaddr[:], addr)
+	account.Address = aaddr.String()
+	account.Round = uint64(req.blockheader.Round)
+	account.AmountWithoutPendingRewards = microalgos
+	account.RewardBase = convert.Uint64Ptr(rewardsbase)
+	// Accounts without keyreg transactions are offline, deleted accounts have
+	// no account data.
+	account.Status = convert.OfflineStatus
+	if accountData != nil {
+		ad, err := decodeAccountData(accountData)
+		if err != nil {
+			return models.Account{}, fmt.Errorf("account decode err %v", err)
+		}
+		convert.AccountData(&account, ad)
+	}
+
+	err := convert.Rewards(&account, req.blockheader, microalgos, rewardsbase)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	if req.opts.IncludeAssetHoldings {
+		holdings, err := loadAccountAssetHoldings(req, addr)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(holdings) > 0 {
+			account.Assets = &holdings
+		}
+	}
+	if req.opts.IncludeAssetParams {
+		assets, err := loadAccountCreatedAssets(req, addr, account.Address)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(assets) > 0 {
+			account.CreatedAssets = &assets
+		}
+	}
+	if !req.opts.ExcludeApps {
+		apps, err := loadAccountCreatedApps(req, addr, account.Address)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(apps) > 0 {
+			account.CreatedApps = &apps
+		}
+		localStates, err := loadAccountAppLocalStates(req, addr)
+		if err != nil {
+			return models.Account{}, err
+		}
+		if len(localStates) > 0 {
+			account.AppsLocalState = &localStates
+		}
+	}
+
+	return account, nil
+}
+
+// deletedClause returns the condition excluding deleted rows unless the
+// query options include them.
+func deletedClause(opts idb.AccountQueryOptions, column string) string {
+	if opts.IncludeDeleted {
+		return ""
+	}
+	return " AND " + column + " = 0"
+}
+
+func loadAccountAssetHoldings(req *getAccountsRequest, addr []byte) ([]models.AssetHolding, error) {
+	query := "SELECT assetid, amount, frozen, created_at, closed_at, deleted FROM account_asset WHERE addr = ?" +
+		deletedClause(req.opts, "deleted") + " ORDER BY assetid"
+	rows, err := req.tx.QueryContext(req.ctx, query, addr)
+	if err != nil {
+		return nil, fmt.Errorf("account asset holdings query err %v", err)
+	}
+	defer rows.Close()
+
+	var res []models.AssetHolding
+	for rows.Next() {
+		var assetid uint64
+		var amountStr string
+		var frozen bool
+		var created sql.NullInt64
+		var closed sql.NullInt64
+		var deleted bool
+		err = rows.Scan(&assetid, &amountStr, &frozen, &created, &closed, &deleted)
+		if err != nil {
+			return nil, fmt.Errorf("account asset holdings scan err %v", err)
+		}
+		amount, err := decodeAmount(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("account asset holdings amount err %v", err)
+		}
+		res = append(res, models.AssetHolding{
+			Amount:          amount,
+			IsFrozen:        frozen,
+			AssetId:         assetid,
+			OptedOutAtRound: nullableInt64Ptr(closed),
+			OptedInAtRound:  nullableInt64Ptr(created),
+			Deleted:         convert.BoolPtr(deleted),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("account asset holdings rows err %v", err)
+	}
+	return res, nil
+}
+
+func loadAccountCreatedAssets(req *getAccountsRequest, addr []byte, creator string) ([]models.Asset, error) {
+	query := "SELECT id, params, created_at, closed_at, deleted FROM asset WHERE creator_addr = ?" +
+		deletedClause(req.opts, "deleted") + " ORDER BY id"
+	rows, err := req.tx.QueryContext(req.ctx, query, addr)
+	if err != nil {
+		return nil, fmt.Errorf("account created assets query err %v", err)
+	}
+	defer rows.Close()
+
+	var res []models.Asset
+	for rows.Next() {
+		var assetid uint64
+		var paramsBytes []byte
+		var created sql.NullInt64
+		var closed sql.NullInt64
+		var deleted bool
+		err = rows.Scan(&assetid, &paramsBytes, &created, &closed, &deleted)
+		if err != nil {
+			return nil, fmt.Errorf("account created assets scan err %v", err)
+		}
+		ap, err := decodeAssetParams(paramsBytes)
+		if err != nil {
+			return nil, fmt.Errorf("account created assets params err %v", err)
+		}
+		res = append(res, models.Asset{
+			Index:            assetid,
+			CreatedAtRound:   nullableInt64Ptr(created),
+			DestroyedAtRound: nullableInt64Ptr(closed),
+			Deleted:          convert.BoolPtr(deleted),
+			Params:           convert.AssetParams(creator, ap),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("account created assets rows err %v", err)
+	}
+	return res, nil
+}
+
+func loadAccountCreatedApps(req *getAccountsRequest, addr []byte, creator string) ([]models.Application, error) {
+	query := "SELECT id, params, created_at, closed_at, deleted FROM app WHERE creator = ?" +
+		deletedClause(req.opts, "deleted") + " ORDER BY id"
+	rows, err := req.tx.QueryContext(req.ctx, query, addr)
+	if err != nil {
+		return nil, fmt.Errorf("account created apps query err %v", err)
+	}
+	defer rows.Close()
+
+	var res []models.Application
+	for rows.Next() {
+		var appid uint64
+		var paramsBytes []byte
+		var created sql.NullInt64
+		var closed sql.NullInt64
+		var deleted bool
+		err = rows.Scan(&appid, &paramsBytes, &created, &closed, &deleted)
+		if err != nil {
+			return nil, fmt.Errorf("account created apps scan err %v", err)
+		}
+		params, err := decodeAppParams(paramsBytes)
+		if err != nil {
+			return nil, fmt.Errorf("account created apps params err %v", err)
+		}
+
+		app := models.Application{
+			Id:             appid,
+			CreatedAtRound: nullableInt64Ptr(created),
+			DeletedAtRound: nullableInt64Ptr(closed),
+			Deleted:        convert.BoolPtr(deleted),
+		}
+		app.Params.Creator = convert.StringPtr(creator)
+
+		// If these are both nil the app was probably deleted, leave out params
+		// some "required" fields will be left in the results.
+		if params.ApprovalProgram != nil || params.ClearStateProgram != nil {
+			convert.AppParams(&app.Params, params)
+		}
+		res = append(res, app)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("account created apps rows err %v", err)
+	}
+	return res, nil
+}
+
+func loadAccountAppLocalStates(req *getAccountsRequest, addr []byte) ([]models.ApplicationLocalState, error) {
+	query := "SELECT app, localstate, created_at, closed_at, deleted FROM account_app WHERE addr = ?" +
+		deletedClause(req.opts, "deleted") + " ORDER BY app"
+	rows, err := req.tx.QueryContext(req.ctx, query, addr)
+	if err != nil {
+		return nil, fmt.Errorf("account local states query err %v", err)
+	}
+	defer rows.Close()
+
+	var res []models.ApplicationLocalState
+	for rows.Next() {
+		var appid uint64
+		var localStateBytes []byte
+		var created sql.NullInt64
+		var closed sql.NullInt64
+		var deleted bool
+		err = rows.Scan(&appid, &localStateBytes, &created, &closed, &deleted)
+		if err != nil {
+			return nil, fmt.Errorf("account local states scan err %v", err)
+		}
+		ls, err := decodeAppLocalState(localStateBytes)
+		if err != nil {
+			return nil, fmt.Errorf("account local states decode err %v", err)
+		}
+		localState := convert.AppLocalState(appid, ls)
+		localState.OptedInAtRound = nullableInt64Ptr(created)
+		localState.ClosedOutAtRound = nullableInt64Ptr(closed)
+		localState.Deleted = convert.BoolPtr(deleted)
+		res = append(res, localState)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("account local states rows err %v", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,344 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand/data/basics"
+
+	models "github.com/algorand/indexer/api/generated/v2"
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/internal/convert"
+)
+
+// Assets is part of idb.IndexerDB
+func (db *IndexerDb) Assets(ctx context.Context, filter idb.AssetsQuery) (<-chan idb.AssetRow, uint64) {
+	query := `SELECT id, creator_addr, params, created_at, closed_at, deleted FROM asset a`
+	const maxWhereParts = 14
+	whereParts := make([]string, 0, maxWhereParts)
+	whereArgs := make([]interface{}, 0, maxWhereParts)
+	if filter.AssetID != 0 {
+		whereParts = append(whereParts, "a.id = ?")
+		whereArgs = append(whereArgs, filter.AssetID)
+	}
+	if filter.AssetIDGreaterThan != 0 {
+		whereParts = append(whereParts, "a.id > ?")
+		whereArgs = append(whereArgs, filter.AssetIDGreaterThan)
+	}
+	if filter.Creator != nil {
+		whereParts = append(whereParts, "a.creator_addr = ?")
+		whereArgs = append(whereArgs, filter.Creator)
+	}
+	// LIKE is case insensitive for ASCII characters in SQLite.
+	if filter.Name != "" {
+		whereParts = append(whereParts, "a.name LIKE ?")
+		whereArgs = append(whereArgs, "%"+filter.Name+"%")
+	}
+	if filter.Unit != "" {
+		whereParts = append(whereParts, "a.unit LIKE ?")
+		whereArgs = append(whereArgs, "%"+filter.Unit+"%")
+	}
+	if filter.Query != "" {
+		qs := "%" + filter.Query + "%"
+		whereParts = append(whereParts, "(a.unit LIKE ? OR a.name LIKE ?)")
+		whereArgs = append(whereArgs, qs, qs)
+	}
+	if !filter.IncludeDeleted {
+		whereParts = append(whereParts, "a.deleted = 0")
+	}
+	if len(whereParts) > 0 {
+		whereStr := strings.Join(whereParts, " AND ")
+		query += " WHERE " + whereStr
+	}
+	query += " ORDER BY id ASC"
+	if filter.Limit != 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	out := make(chan idb.AssetRow, 1)
+
+	tx, err := db.db.BeginTx(ctx, readonly)
+	if err != nil {
+		out <- idb.AssetRow{Error: err}
+		close(out)
+		return out, 0
+	}
+
+	round, err := db.getMaxRoundAccounted(ctx, tx)
+	if err != nil {
+		out <- idb.AssetRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+
+	rows, err := tx.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		err = fmt.Errorf("asset query %#v err %v", query, err)
+		out <- idb.AssetRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+	go func() {
+		db.yieldAssetsThread(ctx, rows, out)
+		close(out)
+		tx.Rollback()
+	}()
+	return out, round
+}
+
+func (db *IndexerDb) yieldAssetsThread(ctx context.Context, rows *sql.Rows, out chan<- idb.AssetRow) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var index uint64
+		var creatorAddr []byte
+		var paramsBytes []byte
+		var created sql.NullInt64
+		var closed sql.NullInt64
+		var deleted bool
+
+		err := rows.Scan(&index, &creatorAddr, &paramsBytes, &created, &closed, &deleted)
+		if err != nil {
+			out <- idb.AssetRow{Error: err}
+			break
+		}
+		params, err := decodeAssetParams(paramsBytes)
+		if err != nil {
+			out <- idb.AssetRow{Error: err}
+			break
+		}
+		rec := idb.AssetRow{
+			AssetID:      index,
+			Creator:      creatorAddr,
+			Params:       params,
+			CreatedRound: nullableInt64Ptr(created),
+			ClosedRound:  nullableInt64Ptr(closed),
+			Deleted:      convert.BoolPtr(deleted),
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case out <- rec:
+		}
+	}
+	if err := rows.Err(); err != nil {
+		out <- idb.AssetRow{Error: err}
+	}
+}
+
+// AssetBalances is part of idb.IndexerDB
+func (db *IndexerDb) AssetBalances(ctx context.Context, abq idb.AssetBalanceQuery) (<-chan idb.AssetBalanceRow, uint64) {
+	const maxWhereParts = 14
+	whereParts := make([]string, 0, maxWhereParts)
+	whereArgs := make([]interface{}, 0, maxWhereParts)
+	if abq.AssetID != 0 {
+		whereParts = append(whereParts, "aa.assetid = ?")
+		whereArgs = append(whereArgs, abq.AssetID)
+	}
+	if abq.AmountGT != nil {
+		whereParts = append(whereParts, "aa.amount > ?")
+		whereArgs = append(whereArgs, encodeAmount(*abq.AmountGT))
+	}
+	if abq.AmountLT != nil {
+		whereParts = append(whereParts, "aa.amount < ?")
+		whereArgs = append(whereArgs, encodeAmount(*abq.AmountLT))
+	}
+	if len(abq.PrevAddress) != 0 {
+		whereParts = append(whereParts, "aa.addr > ?")
+		whereArgs = append(whereArgs, abq.PrevAddress)
+	}
+	if !abq.IncludeDeleted {
+		whereParts = append(whereParts, "aa.deleted = 0")
+	}
+	query := `SELECT addr, assetid, amount, frozen, created_at, closed_at, deleted FROM account_asset aa`
+	if len(whereParts) > 0 {
+		query += " WHERE " + strings.Join(whereParts, " AND ")
+	}
+	query += " ORDER BY addr ASC"
+	if abq.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", abq.Limit)
+	}
+
+	out := make(chan idb.AssetBalanceRow, 1)
+
+	tx, err := db.db.BeginTx(ctx, readonly)
+	if err != nil {
+		out <- idb.AssetBalanceRow{Error: err}
+		close(out)
+		return out, 0
+	}
+
+	round, err := db.getMaxRoundAccounted(ctx, tx)
+	if err != nil {
+		out <- idb.AssetBalanceRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+
+	rows, err := tx.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		out <- idb.AssetBalanceRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+	go func() {
+		db.yieldAssetBalanceThread(ctx, rows, out)
+		close(out)
+		tx.Rollback()
+	}()
+	return out, round
+}
+
+func (db *IndexerDb) yieldAssetBalanceThread(ctx context.Context, rows *sql.Rows, out chan<- idb.AssetBalanceRow) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var addr []byte
+		var assetID uint64
+		var amountStr string
+		var frozen bool
+		var created sql.NullInt64
+		var closed sql.NullInt64
+		var deleted bool
+		err := rows.Scan(&addr, &assetID, &amountStr, &frozen, &created, &closed, &deleted)
+		if err != nil {
+			out <- idb.AssetBalanceRow{Error: err}
+			break
+		}
+		amount, err := decodeAmount(amountStr)
+		if err != nil {
+			out <- idb.AssetBalanceRow{Error: err}
+			break
+		}
+		rec := idb.AssetBalanceRow{
+			Address:      addr,
+			AssetID:      assetID,
+			Amount:       amount,
+			Frozen:       frozen,
+			ClosedRound:  nullableInt64Ptr(closed),
+			CreatedRound: nullableInt64Ptr(created),
+			Deleted:      convert.BoolPtr(deleted),
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case out <- rec:
+		}
+	}
+	if err := rows.Err(); err != nil {
+		out <- idb.AssetBalanceRow{Error: err}
+	}
+}
+
+// Applications is part of idb.IndexerDB
+func (db *IndexerDb) Applications(ctx context.Context, filter *models.SearchForApplicationsParams) (<-chan idb.ApplicationRow, uint64) {
+	out := make(chan idb.ApplicationRow, 1)
+	if filter == nil {
+		out <- idb.ApplicationRow{Error: fmt.Errorf("no arguments provided to application search")}
+		close(out)
+		return out, 0
+	}
+
+	query := `SELECT id, creator, params, created_at, closed_at, deleted FROM app`
+
+	const maxWhereParts = 30
+	whereParts := make([]string, 0, maxWhereParts)
+	whereArgs := make([]interface{}, 0, maxWhereParts)
+	if filter.ApplicationId != nil {
+		whereParts = append(whereParts, "id = ?")
+		whereArgs = append(whereArgs, *filter.ApplicationId)
+	}
+	if filter.Next != nil {
+		whereParts = append(whereParts, "id > ?")
+		whereArgs = append(whereArgs, *filter.Next)
+	}
+	if filter.IncludeAll == nil || !(*filter.IncludeAll) {
+		whereParts = append(whereParts, "deleted = 0")
+	}
+	if len(whereParts) > 0 {
+		whereStr := strings.Join(whereParts, " AND ")
+		query += " WHERE " + whereStr
+	}
+	query += " ORDER BY 1"
+	if filter.Limit != nil {
+		query += fmt.Sprintf(" LIMIT %d", *filter.Limit)
+	}
+
+	tx, err := db.db.BeginTx(ctx, readonly)
+	if err != nil {
+		out <- idb.ApplicationRow{Error: err}
+		close(out)
+		return out, 0
+	}
+
+	round, err := db.getMaxRoundAccounted(ctx, tx)
+	if err != nil {
+		out <- idb.ApplicationRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+
+	rows, err := tx.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		out <- idb.ApplicationRow{Error: err}
+		close(out)
+		tx.Rollback()
+		return out, round
+	}
+
+	go func() {
+		db.yieldApplicationsThread(ctx, rows, out)
+		close(out)
+		tx.Rollback()
+	}()
+	return out, round
+}
+
+func (db *IndexerDb) yieldApplicationsThread(ctx context.Context, rows *sql.Rows, out chan idb.ApplicationRow) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var index uint64
+		var creator []byte
+		var paramsBytes []byte
+		var created sql.NullInt64
+		var closed sql.NullInt64
+		var deleted bool
+		err := rows.Scan(&index, &creator, &paramsBytes, &created, &closed, &deleted)
+		if err != nil {
+			out <- idb.ApplicationRow{Error: err}
+			break
+		}
+		var rec idb.ApplicationRow
+		rec.Application.Id = index
+		rec.Application.CreatedAtRound = nullableInt64Ptr(created)
+		rec.Application.DeletedAtRound = nullableInt64Ptr(closed)
+		rec.Application.Deleted = convert.BoolPtr(deleted)
+		ap, err := decodeAppParams(paramsBytes)
+		if err != nil {
+			rec.Error = fmt.Errorf("app=%d decode err, %v", index, err)
+			out <- rec
+			break
+		}
+		var aaddr basics.Address
+		copy(aaddr[:], creator)
+		rec.Application.Params.Creator = convert.StringPtr(aaddr.String())
+		convert.AppParams(&rec.Application.Params, ap)
+
+		select {
+		case <-ctx.Done():
+			return
+		case out <- rec:
+		}
+	}
+	if err := rows.Err(); err != nil {
+		out <- idb.ApplicationRow{Error: err}
+	}
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// exportCheckpointKey returns the metastate key of the checkpoint of the
+// exporter `name`.
+func exportCheckpointKey(name string) string {
+	return exportCheckpointMetastateKeyPrefix + name
+}
+
+// GetExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) GetExportCheckpoint(name string) (idb.ExportCheckpoint, error) {
+	var checkpoint idb.ExportCheckpoint
+	err := db.getMetastate(context.Background(), nil, exportCheckpointKey(name), &checkpoint)
+	if err == idb.ErrorNotInitialized {
+		return idb.ExportCheckpoint{}, idb.ErrorCheckpointNotFound
+	}
+	if err != nil {
+		return idb.ExportCheckpoint{}, fmt.Errorf("GetExportCheckpoint() err: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// GetExportCheckpoints is part of idb.IndexerDb.
+func (db *IndexerDb) GetExportCheckpoints() (map[string]idb.ExportCheckpoint, error) {
+	rows, err := db.db.QueryContext(
+		context.Background(), `SELECT k, v FROM metastate WHERE substr(k, 1, ?) = ?`,
+		len(exportCheckpointMetastateKeyPrefix), exportCheckpointMetastateKeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("GetExportCheckpoints() query err: %w", err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]idb.ExportCheckpoint)
+	for rows.Next() {
+		var key string
+		var value []byte
+		err = rows.Scan(&key, &value)
+		if err != nil {
+			return nil, fmt.Errorf("GetExportCheckpoints() scan err: %w", err)
+		}
+		var checkpoint idb.ExportCheckpoint
+		err = protocol.DecodeReflect(value, &checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("GetExportCheckpoints() decode %s err: %w", key, err)
+		}
+		res[strings.TrimPrefix(key, exportCheckpointMetastateKeyPrefix)] = checkpoint
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetExportCheckpoints() query err: %w", err)
+	}
+	return res, nil
+}
+
+// SetExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) SetExportCheckpoint(name string, checkpoint idb.ExportCheckpoint) error {
+	err := db.setMetastate(context.Background(), nil, exportCheckpointKey(name), &checkpoint)
+	if err != nil {
+		return fmt.Errorf("SetExportCheckpoint() err: %w", err)
+	}
+	return nil
+}
+
+// DeleteExportCheckpoint is part of idb.IndexerDb.
+func (db *IndexerDb) DeleteExportCheckpoint(name string) error {
+	_, err := db.db.ExecContext(
+		context.Background(), `DELETE FROM metastate WHERE k = ?`, exportCheckpointKey(name))
+	if err != nil {
+		return fmt.Errorf("DeleteExportCheckpoint() err: %w", err)
+	}
+	return nil
+}
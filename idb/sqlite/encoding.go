@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// importState encodes an import round counter.
+type importState struct {
+	NextRoundToAccount uint64 `codec:"next_account_round"`
+}
+
+// encodeAmount formats a uint64 so that text comparison matches numeric
+// comparison. SQLite integers are signed 64 bit.
+func encodeAmount(amount uint64) string {
+	return fmt.Sprintf("%020d", amount)
+}
+
+func decodeAmount(s string) (uint64, error) {
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// nullIfZero returns nil for a zero amount, it mirrors the omitted fields of
+// the msgpack and json encodings.
+func nullIfZero(x uint64) interface{} {
+	if x == 0 {
+		return nil
+	}
+	return x
+}
+
+func nullIfZeroAmount(x uint64) interface{} {
+	if x == 0 {
+		return nil
+	}
+	return encodeAmount(x)
+}
+
+func nullIfEmpty(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+func nullIfZeroAddress(addr basics.Address) interface{} {
+	if addr.IsZero() {
+		return nil
+	}
+	return addr[:]
+}
+
+func nullIfZeroDigest(d [32]byte) interface{} {
+	if d == ([32]byte{}) {
+		return nil
+	}
+	return d[:]
+}
+
+// trimAccountData removes the fields that are stored in separate columns or
+// tables.
+func trimAccountData(ad basics.AccountData) basics.AccountData {
+	ad.MicroAlgos = basics.MicroAlgos{}
+	ad.RewardsBase = 0
+	ad.RewardedMicroAlgos = basics.MicroAlgos{}
+	ad.AssetParams = nil
+	ad.Assets = nil
+	ad.AppLocalStates = nil
+	ad.AppParams = nil
+
+	return ad
+}
+
+func encodeAccountData(ad basics.AccountData) []byte {
+	return protocol.Encode(&ad)
+}
+
+func decodeAccountData(data []byte) (basics.AccountData, error) {
+	var ad basics.AccountData
+	err := protocol.Decode(data, &ad)
+	if err != nil {
+		return basics.AccountData{}, fmt.Errorf("decodeAccountData() err: %w", err)
+	}
+	return ad, nil
+}
+
+func encodeBlockHeader(header bookkeeping.BlockHeader) []byte {
+	return protocol.Encode(&header)
+}
+
+func decodeBlockHeader(data []byte) (bookkeeping.BlockHeader, error) {
+	var header bookkeeping.BlockHeader
+	err := protocol.Decode(data, &header)
+	if err != nil {
+		return bookkeeping.BlockHeader{}, fmt.Errorf("decodeBlockHeader() err: %w", err)
+	}
+	return header, nil
+}
+
+func encodeSignedTxnWithAD(stxn transactions.SignedTxnWithAD) []byte {
+	return protocol.Encode(&stxn)
+}
+
+func decodeSignedTxnWithAD(data []byte) (transactions.SignedTxnWithAD, error) {
+	var stxn transactions.SignedTxnWithAD
+	err := protocol.Decode(data, &stxn)
+	if err != nil {
+		return transactions.SignedTxnWithAD{},
+			fmt.Errorf("decodeSignedTxnWithAD() err: %w", err)
+	}
+	return stxn, nil
+}
+
+func encodeTxnExtra(extra *idb.TxnExtra) []byte {
+	return protocol.EncodeReflect(extra)
+}
+
+func decodeTxnExtra(data []byte) (idb.TxnExtra, error) {
+	var extra idb.TxnExtra
+	err := protocol.DecodeReflect(data, &extra)
+	if err != nil {
+		return idb.TxnExtra{}, fmt.Errorf("decodeTxnExtra() err: %w", err)
+	}
+	return extra, nil
+}
+
+func encodeAssetParams(params basics.AssetParams) []byte {
+	return protocol.Encode(&params)
+}
+
+// decodeAssetParams returns empty params for deleted assets.
+func decodeAssetParams(data []byte) (basics.AssetParams, error) {
+	var params basics.AssetParams
+	if data == nil {
+		return params, nil
+	}
+	err := protocol.Decode(data, &params)
+	if err != nil {
+		return basics.AssetParams{}, fmt.Errorf("decodeAssetParams() err: %w", err)
+	}
+	return params, nil
+}
+
+func encodeAppParams(params basics.AppParams) []byte {
+	return protocol.Encode(&params)
+}
+
+// decodeAppParams returns empty params for deleted apps.
+func decodeAppParams(data []byte) (basics.AppParams, error) {
+	var params basics.AppParams
+	if data == nil {
+		return params, nil
+	}
+	err := protocol.Decode(data, &params)
+	if err != nil {
+		return basics.AppParams{}, fmt.Errorf("decodeAppParams() err: %w", err)
+	}
+	return params, nil
+}
+
+func encodeAppLocalState(state basics.AppLocalState) []byte {
+	return protocol.Encode(&state)
+}
+
+// decodeAppLocalState returns an empty state for deleted local states.
+func decodeAppLocalState(data []byte) (basics.AppLocalState, error) {
+	var state basics.AppLocalState
+	if data == nil {
+		return state, nil
+	}
+	err := protocol.Decode(data, &state)
+	if err != nil {
+		return basics.AppLocalState{}, fmt.Errorf("decodeAppLocalState() err: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,352 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+const (
+	blockHeaderStmtName    = "block_header"
+	assetCreatorStmtName   = "asset_creator"
+	appCreatorStmtName     = "app_creator"
+	accountStmtName        = "account"
+	assetHoldingsStmtName  = "asset_holdings"
+	assetParamsStmtName    = "asset_params"
+	appParamsStmtName      = "app_params"
+	appLocalStatesStmtName = "app_local_states"
+	accountTotalsStmtName  = "account_totals"
+)
+
+var ledgerStatements = map[string]string{
+	blockHeaderStmtName:  "SELECT header FROM block_header WHERE round = ?",
+	assetCreatorStmtName: "SELECT creator_addr FROM asset WHERE id = ? AND NOT deleted",
+	appCreatorStmtName:   "SELECT creator FROM app WHERE id = ? AND NOT deleted",
+	accountStmtName: "SELECT microalgos, rewardsbase, rewards_total, account_data " +
+		"FROM account WHERE addr = ? AND NOT deleted",
+	assetHoldingsStmtName: "SELECT assetid, amount, frozen FROM account_asset " +
+		"WHERE addr = ? AND NOT deleted",
+	assetParamsStmtName: "SELECT id, params FROM asset " +
+		"WHERE creator_addr = ? AND NOT deleted",
+	appParamsStmtName: "SELECT id, params FROM app WHERE creator = ? AND NOT deleted",
+	appLocalStatesStmtName: "SELECT app, localstate FROM account_app " +
+		"WHERE addr = ? AND NOT deleted",
+	accountTotalsStmtName: "SELECT v FROM metastate WHERE k = '" +
+		accountTotalsMetastateKey + "'",
+}
+
+// ledgerForEvaluator implements the indexerLedgerForEval interface from
+// go-algorand ledger/eval.go and is used for accounting.
+type ledgerForEvaluator struct {
+	stmts       map[string]*sql.Stmt
+	latestRound basics.Round
+}
+
+func makeLedgerForEvaluator(tx *sql.Tx, latestRound basics.Round) (ledgerForEvaluator, error) {
+	l := ledgerForEvaluator{
+		stmts:       make(map[string]*sql.Stmt, len(ledgerStatements)),
+		latestRound: latestRound,
+	}
+
+	for name, query := range ledgerStatements {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			l.close()
+			return ledgerForEvaluator{},
+				fmt.Errorf("makeLedgerForEvaluator() prepare statement err: %w", err)
+		}
+		l.stmts[name] = stmt
+	}
+
+	return l, nil
+}
+
+func (l *ledgerForEvaluator) close() {
+	for _, stmt := range l.stmts {
+		stmt.Close()
+	}
+}
+
+// LatestBlockHdr is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) LatestBlockHdr() (bookkeeping.BlockHeader, error) {
+	row := l.stmts[blockHeaderStmtName].QueryRow(uint64(l.latestRound))
+
+	var header []byte
+	err := row.Scan(&header)
+	if err != nil {
+		return bookkeeping.BlockHeader{}, fmt.Errorf("BlockHdr() scan row err: %w", err)
+	}
+
+	res, err := decodeBlockHeader(header)
+	if err != nil {
+		return bookkeeping.BlockHeader{}, fmt.Errorf("BlockHdr() err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) loadAccount(address basics.Address) (*basics.AccountData, error) {
+	row := l.stmts[accountStmtName].QueryRow(address[:])
+
+	var microalgos uint64
+	var rewardsbase uint64
+	var rewardsTotal uint64
+	var accountData []byte
+
+	err := row.Scan(&microalgos, &rewardsbase, &rewardsTotal, &accountData)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() scan row err: %w", err)
+	}
+
+	res, err := decodeAccountData(accountData)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.MicroAlgos = basics.MicroAlgos{Raw: microalgos}
+	res.RewardsBase = rewardsbase
+	res.RewardedMicroAlgos = basics.MicroAlgos{Raw: rewardsTotal}
+
+	res.Assets, err = l.loadAssetHoldings(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.AssetParams, err = l.loadAssetParams(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.AppParams, err = l.loadAppParams(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+	res.AppLocalStates, err = l.loadAppLocalStates(address)
+	if err != nil {
+		return nil, fmt.Errorf("loadAccount() err: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (l ledgerForEvaluator) loadAssetHoldings(address basics.Address) (map[basics.AssetIndex]basics.AssetHolding, error) {
+	rows, err := l.stmts[assetHoldingsStmtName].Query(address[:])
+	if err != nil {
+		return nil, fmt.Errorf("loadAssetHoldings() query err: %w", err)
+	}
+	defer rows.Close()
+
+	var res map[basics.AssetIndex]basics.AssetHolding
+	for rows.Next() {
+		var assetid uint64
+		var amount string
+		var frozen bool
+		err = rows.Scan(&assetid, &amount, &frozen)
+		if err != nil {
+			return nil, fmt.Errorf("loadAssetHoldings() scan row err: %w", err)
+		}
+
+		var holding basics.AssetHolding
+		holding.Amount, err = decodeAmount(amount)
+		if err != nil {
+			return nil, fmt.Errorf("loadAssetHoldings() decode amount err: %w", err)
+		}
+		holding.Frozen = frozen
+
+		if res == nil {
+			res = make(map[basics.AssetIndex]basics.AssetHolding)
+		}
+		res[basics.AssetIndex(assetid)] = holding
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("loadAssetHoldings() scan end err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) loadAssetParams(address basics.Address) (map[basics.AssetIndex]basics.AssetParams, error) {
+	rows, err := l.stmts[assetParamsStmtName].Query(address[:])
+	if err != nil {
+		return nil, fmt.Errorf("loadAssetParams() query err: %w", err)
+	}
+	defer rows.Close()
+
+	var res map[basics.AssetIndex]basics.AssetParams
+	for rows.Next() {
+		var index uint64
+		var params []byte
+		err = rows.Scan(&index, &params)
+		if err != nil {
+			return nil, fmt.Errorf("loadAssetParams() scan row err: %w", err)
+		}
+
+		if res == nil {
+			res = make(map[basics.AssetIndex]basics.AssetParams)
+		}
+		res[basics.AssetIndex(index)], err = decodeAssetParams(params)
+		if err != nil {
+			return nil, fmt.Errorf("loadAssetParams() err: %w", err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("loadAssetParams() scan end err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) loadAppParams(address basics.Address) (map[basics.AppIndex]basics.AppParams, error) {
+	rows, err := l.stmts[appParamsStmtName].Query(address[:])
+	if err != nil {
+		return nil, fmt.Errorf("loadAppParams() query err: %w", err)
+	}
+	defer rows.Close()
+
+	var res map[basics.AppIndex]basics.AppParams
+	for rows.Next() {
+		var index uint64
+		var params []byte
+		err = rows.Scan(&index, &params)
+		if err != nil {
+			return nil, fmt.Errorf("loadAppParams() scan row err: %w", err)
+		}
+
+		if res == nil {
+			res = make(map[basics.AppIndex]basics.AppParams)
+		}
+		res[basics.AppIndex(index)], err = decodeAppParams(params)
+		if err != nil {
+			return nil, fmt.Errorf("loadAppParams() err: %w", err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("loadAppParams() scan end err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) loadAppLocalStates(address basics.Address) (map[basics.AppIndex]basics.AppLocalState, error) {
+	rows, err := l.stmts[appLocalStatesStmtName].Query(address[:])
+	if err != nil {
+		return nil, fmt.Errorf("loadAppLocalStates() query err: %w", err)
+	}
+	defer rows.Close()
+
+	var res map[basics.AppIndex]basics.AppLocalState
+	for rows.Next() {
+		var app uint64
+		var localstate []byte
+		err = rows.Scan(&app, &localstate)
+		if err != nil {
+			return nil, fmt.Errorf("loadAppLocalStates() scan row err: %w", err)
+		}
+
+		if res == nil {
+			res = make(map[basics.AppIndex]basics.AppLocalState)
+		}
+		res[basics.AppIndex(app)], err = decodeAppLocalState(localstate)
+		if err != nil {
+			return nil, fmt.Errorf("loadAppLocalStates() err: %w", err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("loadAppLocalStates() scan end err: %w", err)
+	}
+
+	return res, nil
+}
+
+// LookupWithoutRewards is part of go-algorand's indexerLedgerForEval interface.
+// nil is stored for those accounts that were not found.
+func (l ledgerForEvaluator) LookupWithoutRewards(addresses map[basics.Address]struct{}) (map[basics.Address]*basics.AccountData, error) {
+	res := make(map[basics.Address]*basics.AccountData, len(addresses))
+	for address := range addresses {
+		accountData, err := l.loadAccount(address)
+		if err != nil {
+			return nil, fmt.Errorf("LookupWithoutRewards() err: %w", err)
+		}
+		res[address] = accountData
+	}
+
+	return res, nil
+}
+
+func (l ledgerForEvaluator) getCreator(stmtName string, index uint64) (ledger.FoundAddress, error) {
+	row := l.stmts[stmtName].QueryRow(index)
+
+	var buf []byte
+	err := row.Scan(&buf)
+	if err == sql.ErrNoRows {
+		return ledger.FoundAddress{}, nil
+	}
+	if err != nil {
+		return ledger.FoundAddress{}, fmt.Errorf("getCreator() err: %w", err)
+	}
+
+	var address basics.Address
+	copy(address[:], buf)
+
+	return ledger.FoundAddress{Address: address, Exists: true}, nil
+}
+
+// GetAssetCreator is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) GetAssetCreator(indices map[basics.AssetIndex]struct{}) (map[basics.AssetIndex]ledger.FoundAddress, error) {
+	res := make(map[basics.AssetIndex]ledger.FoundAddress, len(indices))
+	for index := range indices {
+		foundAddress, err := l.getCreator(assetCreatorStmtName, uint64(index))
+		if err != nil {
+			return nil, fmt.Errorf("GetAssetCreator() err: %w", err)
+		}
+		res[index] = foundAddress
+	}
+
+	return res, nil
+}
+
+// GetAppCreator is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) GetAppCreator(indices map[basics.AppIndex]struct{}) (map[basics.AppIndex]ledger.FoundAddress, error) {
+	res := make(map[basics.AppIndex]ledger.FoundAddress, len(indices))
+	for index := range indices {
+		foundAddress, err := l.getCreator(appCreatorStmtName, uint64(index))
+		if err != nil {
+			return nil, fmt.Errorf("GetAppCreator() err: %w", err)
+		}
+		res[index] = foundAddress
+	}
+
+	return res, nil
+}
+
+// LatestTotals is part of go-algorand's indexerLedgerForEval interface.
+func (l ledgerForEvaluator) LatestTotals() (ledgercore.AccountTotals, error) {
+	row := l.stmts[accountTotalsStmtName].QueryRow()
+
+	var value []byte
+	err := row.Scan(&value)
+	if err != nil {
+		return ledgercore.AccountTotals{}, fmt.Errorf("LatestTotals() scan err: %w", err)
+	}
+
+	var totals ledgercore.AccountTotals
+	err = protocol.DecodeReflect(value, &totals)
+	if err != nil {
+		return ledgercore.AccountTotals{}, fmt.Errorf("LatestTotals() decode err: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/internal/convert"
+	"github.com/algorand/indexer/util/test"
+)
+
+func TestEncodeAmountOrder(t *testing.T) {
+	amounts := []uint64{0, 1, 9, 10, 99, 100, 1 << 40, 1<<63 - 1, 1 << 63, 1<<64 - 1}
+	encoded := make([]string, len(amounts))
+	for i, amount := range amounts {
+		encoded[i] = encodeAmount(amount)
+	}
+	assert.True(t, sort.StringsAreSorted(encoded))
+
+	for i, s := range encoded {
+		amount, err := decodeAmount(s)
+		require.NoError(t, err)
+		assert.Equal(t, amounts[i], amount)
+	}
+}
+
+func TestBuildTransactionQuery(t *testing.T) {
+	query, args, err := buildTransactionQuery(idb.TransactionFilter{})
+	require.NoError(t, err)
+	assert.NotContains(t, query, "WHERE")
+	assert.NotContains(t, query, "txn_participation")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY t.round, t.intra"))
+	assert.Empty(t, args)
+
+	query, args, err = buildTransactionQuery(idb.TransactionFilter{
+		Address:       test.AccountA[:],
+		AddressRole:   idb.AddressRoleSender,
+		AssetID:       3,
+		AssetAmountGT: convert.Uint64Ptr(5),
+		Limit:         10,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, query, "JOIN txn_participation p")
+	assert.Contains(t, query, "ORDER BY p.addr, p.round DESC, p.intra DESC LIMIT 10")
+	assert.Equal(
+		t,
+		[]interface{}{test.AccountA[:], uint64(idb.AddressRoleSender), uint64(3), encodeAmount(5)},
+		args)
+
+	// Every placeholder has an argument.
+	query, args, err = buildTransactionQuery(idb.TransactionFilter{
+		NotePrefix: []byte("abc"),
+		GroupID:    []byte("g"),
+		Lease:      []byte("l"),
+		SigType:    idb.Lsig,
+		BeforeTime: time.Unix(100, 1),
+		AfterTime:  time.Unix(50, 0),
+		RekeyTo:    convert.BoolPtr(true),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, query, "substr(t.note, 1, 3) = ?")
+	assert.Contains(t, query, "t.txgroup = ?")
+	assert.Contains(t, query, "t.lease = ?")
+	assert.Contains(t, query, "t.rekey_to IS NOT NULL")
+	assert.Equal(t, strings.Count(query, "?"), len(args))
+	assert.Equal(
+		t,
+		[]interface{}{int64(101), int64(50), "lsig", []byte("abc"), []byte("g"), []byte("l")},
+		args)
+
+	_, _, err = buildTransactionQuery(idb.TransactionFilter{AssetID: 1, ApplicationID: 2})
+	assert.Error(t, err)
+}
+
+func TestBuildAccountQuery(t *testing.T) {
+	query, args := buildAccountQuery(idb.AccountQueryOptions{})
+	assert.Contains(t, query, "WHERE a.deleted = 0")
+	assert.Empty(t, args)
+
+	query, args = buildAccountQuery(idb.AccountQueryOptions{
+		HasAssetID:         7,
+		AssetGT:            convert.Uint64Ptr(1),
+		GreaterThanAddress: test.AccountA[:],
+		EqualToAuthAddr:    test.AccountB[:],
+		IncludeDeleted:     true,
+		Limit:              2,
+	})
+	assert.Contains(t, query, "a.addr IN (SELECT addr FROM account_asset WHERE assetid = ? AND amount > ?)")
+	assert.NotContains(t, query, "a.deleted = 0")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY a.addr ASC LIMIT 2"))
+	assert.Equal(t, strings.Count(query, "?"), len(args))
+	assert.Equal(
+		t,
+		[]interface{}{uint64(7), encodeAmount(1), test.AccountA[:], test.AccountB[:]},
+		args)
+}
+
+func TestSigTypeColumn(t *testing.T) {
+	stxn := transactions.SignedTxn{Sig: test.Signature}
+	assert.Equal(t, "sig", sigTypeColumn(&stxn))
+
+	stxn = transactions.SignedTxn{}
+	stxn.Msig.Subsigs = []crypto.MultisigSubsig{{Sig: test.Signature}}
+	assert.Equal(t, "msig", sigTypeColumn(&stxn))
+
+	stxn = transactions.SignedTxn{}
+	stxn.Lsig.Logic = []byte{0x01}
+	assert.Equal(t, "lsig", sigTypeColumn(&stxn))
+
+	stxn = transactions.SignedTxn{}
+	assert.Nil(t, sigTypeColumn(&stxn))
+}
+
+func TestAddressRoles(t *testing.T) {
+	pay := test.MakePaymentTxn(
+		1000, 10, 0, 0, 0, 0, test.AccountA, test.AccountB, test.AccountA, basics.Address{})
+	assert.Equal(
+		t, idb.AddressRoleSender|idb.AddressRoleCloseRemainderTo,
+		addressRoles(&pay.Txn, test.AccountA))
+	assert.Equal(t, idb.AddressRoleReceiver, addressRoles(&pay.Txn, test.AccountB))
+	assert.Equal(t, idb.AddressRole(0), addressRoles(&pay.Txn, test.AccountC))
+
+	// The zero address never matches an unset field.
+	assert.Equal(t, idb.AddressRole(0), addressRoles(&pay.Txn, basics.Address{}))
+}
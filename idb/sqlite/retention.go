@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// getOldestRound returns the oldest round with transactions, 0 if transactions
+// were never pruned. If `tx` is nil, use a normal query.
+func (db *IndexerDb) getOldestRound(ctx context.Context, tx *sql.Tx) (uint64, error) {
+	var round uint64
+	err := db.getMetastate(ctx, tx, oldestRoundMetastateKey, &round)
+	if err == idb.ErrorNotInitialized {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getOldestRound() err: %w", err)
+	}
+	return round, nil
+}
+
+// GetOldestRound is part of idb.IndexerDb.
+func (db *IndexerDb) GetOldestRound() (uint64, error) {
+	return db.getOldestRound(context.Background(), nil)
+}
+
+// PruneTransactions is part of idb.IndexerDb.
+func (db *IndexerDb) PruneTransactions(round uint64) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	ctx := context.Background()
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() begin tx err: %w", err)
+	}
+	defer tx.Rollback()
+
+	oldest, err := db.getOldestRound(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() err: %w", err)
+	}
+	if round <= oldest {
+		return nil
+	}
+
+	for _, table := range []string{"txn", "txn_participation"} {
+		_, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE round < ?", round)
+		if err != nil {
+			return fmt.Errorf("PruneTransactions() delete from %s err: %w", table, err)
+		}
+	}
+
+	err = db.setMetastate(ctx, tx, oldestRoundMetastateKey, &round)
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() err: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("PruneTransactions() commit err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// pruneUndo clears undo_round and removes the undo records of the rounds that
+// are more than maxRollbackRounds older than `round`. With maxRollbackRounds 0
+// all records are removed.
+func (db *IndexerDb) pruneUndo(ctx context.Context, tx *sql.Tx, round uint64) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM undo_round")
+	if err != nil {
+		return fmt.Errorf("pruneUndo() clear undo round err: %w", err)
+	}
+
+	if db.maxRollbackRounds > 0 && round < db.maxRollbackRounds {
+		return nil
+	}
+	_, err = tx.ExecContext(
+		ctx, "DELETE FROM state_undo WHERE round <= ?", round-db.maxRollbackRounds)
+	if err != nil {
+		return fmt.Errorf("pruneUndo() err: %w", err)
+	}
+
+	return nil
+}
+
+// Rollback is part of idb.IndexerDb.
+func (db *IndexerDb) Rollback(round uint64) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	ctx := context.Background()
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("Rollback() begin tx err: %w", err)
+	}
+	defer tx.Rollback()
+
+	maxRound, err := db.getMaxRoundAccounted(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("Rollback() err: %w", err)
+	}
+	if round > maxRound {
+		return fmt.Errorf("Rollback() round %d is after the last round %d", round, maxRound)
+	}
+
+	// Every round updates the import state, so every round written with undo
+	// records has at least one.
+	var rounds uint64
+	err = tx.QueryRowContext(
+		ctx, "SELECT COUNT(DISTINCT round) FROM state_undo WHERE round > ?", round).Scan(&rounds)
+	if err != nil {
+		return fmt.Errorf("Rollback() count undo rounds err: %w", err)
+	}
+	if rounds != maxRound-round {
+		return fmt.Errorf(
+			"Rollback() %d of %d rounds have undo records err: %w",
+			rounds, maxRound-round, idb.ErrorRollbackNotAvailable)
+	}
+
+	// Read the statements before running them, they modify the tables being
+	// read otherwise. Newest first, so that every row ends up with its value
+	// before round + 1.
+	rows, err := tx.QueryContext(
+		ctx, "SELECT stmt FROM state_undo WHERE round > ? ORDER BY seq DESC", round)
+	if err != nil {
+		return fmt.Errorf("Rollback() query undo err: %w", err)
+	}
+	var stmts []string
+	for rows.Next() {
+		var stmt string
+		err = rows.Scan(&stmt)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("Rollback() scan undo err: %w", err)
+		}
+		stmts = append(stmts, stmt)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("Rollback() query undo err: %w", err)
+	}
+
+	for _, stmt := range stmts {
+		_, err = tx.ExecContext(ctx, stmt)
+		if err != nil {
+			return fmt.Errorf("Rollback() undo %q err: %w", stmt, err)
+		}
+	}
+
+	for _, table := range []string{"txn", "txn_participation", "block_header", "state_undo"} {
+		_, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE round > ?", round)
+		if err != nil {
+			return fmt.Errorf("Rollback() delete from %s err: %w", table, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("Rollback() commit err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the keys for the metastate key-value table.
+const (
+	stateMetastateKey           = "state"
+	specialAccountsMetastateKey = "accounts"
+	accountTotalsMetastateKey   = "totals"
+	// The oldest round with transactions, not set unless transactions were
+	// pruned.
+	oldestRoundMetastateKey = "oldest_round"
+	// The checkpoint of an exporter is stored under the prefix and its name.
+	exportCheckpointMetastateKeyPrefix = "export/"
+	// A webhook is stored under the prefix and its id.
+	webhookMetastateKeyPrefix = "webhook/"
+)
+
+// setupSQL mirrors the postgres schema. Differences:
+//   - blobs are msgpack encoded instead of json,
+//   - `txn` has extra columns extracted from the transaction, they replace the
+//     postgres json operators in transaction filters,
+//   - `txn_participation.roles` holds the idb.AddressRole bits of the address
+//     in the transaction of the row,
+//   - amounts that may not fit a signed 64 bit integer are zero padded decimal
+//     strings, so that they compare in numeric order,
+//   - `index` is a keyword, creatable ids are stored in `id` columns.
+const setupSQL = `
+CREATE TABLE IF NOT EXISTS block_header (
+  round INTEGER PRIMARY KEY,
+  realtime INTEGER NOT NULL, -- unix time in seconds
+  rewardslevel INTEGER NOT NULL,
+  header BLOB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS block_header_time ON block_header (realtime);
+
+CREATE TABLE IF NOT EXISTS txn (
+  round INTEGER NOT NULL,
+  intra INTEGER NOT NULL,
+  typeenum INTEGER NOT NULL,
+  asset INTEGER NOT NULL, -- 0=Algos, otherwise AssetIndex
+  txid TEXT, -- base32 txid, or NULL for inner transactions
+  txn BLOB NOT NULL, -- signed txn with apply data; inner txns exclude nested inner txns
+  extra BLOB NOT NULL,
+  root_intra INTEGER, -- intra of the root transaction, only set on inner transactions
+  sigtype TEXT, -- "sig", "msig" or "lsig", NULL for inner transactions
+  note BLOB, -- NULL if empty
+  amount INTEGER, -- NULL if zero
+  close_amount INTEGER, -- NULL if zero
+  asset_amount TEXT, -- NULL if zero
+  rekey_to BLOB, -- NULL if not rekeyed
+  txgroup BLOB, -- NULL if not in a group
+  lease BLOB, -- NULL if empty
+  PRIMARY KEY (round, intra)
+);
+
+CREATE INDEX IF NOT EXISTS txn_by_txid ON txn (txid);
+CREATE INDEX IF NOT EXISTS txn_by_group ON txn (txgroup) WHERE txgroup IS NOT NULL;
+CREATE INDEX IF NOT EXISTS txn_by_lease ON txn (lease) WHERE lease IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS txn_participation (
+  addr BLOB NOT NULL,
+  round INTEGER NOT NULL,
+  intra INTEGER NOT NULL,
+  roles INTEGER NOT NULL,
+  PRIMARY KEY (addr, round, intra)
+);
+
+CREATE INDEX IF NOT EXISTS txn_participation_round ON txn_participation (round);
+
+CREATE TABLE IF NOT EXISTS account (
+  addr BLOB PRIMARY KEY,
+  microalgos INTEGER NOT NULL,
+  rewardsbase INTEGER NOT NULL,
+  rewards_total INTEGER NOT NULL,
+  deleted INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  closed_at INTEGER,
+  keytype TEXT, -- "sig", "msig", "lsig", or NULL if unknown
+  auth_addr BLOB, -- NULL if not rekeyed
+  account_data BLOB -- trimmed AccountData, NULL iff the account is deleted
+);
+
+CREATE INDEX IF NOT EXISTS account_by_auth_addr ON account (auth_addr);
+
+CREATE TABLE IF NOT EXISTS account_asset (
+  addr BLOB NOT NULL,
+  assetid INTEGER NOT NULL,
+  amount TEXT NOT NULL, -- zero padded to 20 digits
+  frozen INTEGER NOT NULL,
+  deleted INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  closed_at INTEGER,
+  PRIMARY KEY (addr, assetid)
+);
+
+CREATE INDEX IF NOT EXISTS account_asset_by_asset ON account_asset (assetid, addr);
+
+CREATE TABLE IF NOT EXISTS asset (
+  id INTEGER PRIMARY KEY,
+  creator_addr BLOB NOT NULL,
+  params BLOB, -- NULL iff the asset is deleted
+  name TEXT, -- asset name, for searching
+  unit TEXT, -- unit name, for searching
+  deleted INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  closed_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS asset_by_creator_addr ON asset (creator_addr);
+
+CREATE TABLE IF NOT EXISTS metastate (
+  k TEXT PRIMARY KEY,
+  v BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS app (
+  id INTEGER PRIMARY KEY,
+  creator BLOB NOT NULL,
+  params BLOB, -- NULL iff the app is deleted
+  deleted INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  closed_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS app_by_creator ON app (creator);
+
+CREATE TABLE IF NOT EXISTS account_app (
+  addr BLOB NOT NULL,
+  app INTEGER NOT NULL,
+  localstate BLOB, -- NULL iff deleted from the account
+  deleted INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  closed_at INTEGER,
+  PRIMARY KEY (addr, app)
+);
+
+CREATE INDEX IF NOT EXISTS account_app_by_app ON account_app (app, addr);
+
+-- undo_round holds the round being written while undo records are kept.
+CREATE TABLE IF NOT EXISTS undo_round (
+  round INTEGER NOT NULL
+);
+
+-- state_undo holds the statements that revert the changes to the state tables,
+-- they are written by the undo triggers.
+CREATE TABLE IF NOT EXISTS state_undo (
+  seq INTEGER PRIMARY KEY AUTOINCREMENT,
+  round INTEGER NOT NULL,
+  stmt TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS state_undo_round ON state_undo (round);
+`
+
+// undoTables are the tables that Rollback() restores from the undo records,
+// with their columns. The first `pk` columns are the primary key.
+var undoTables = []struct {
+	name    string
+	pk      int
+	columns []string
+}{
+	{"metastate", 1, []string{"k", "v"}},
+	{"account", 1, []string{
+		"addr", "microalgos", "rewardsbase", "rewards_total", "deleted", "created_at",
+		"closed_at", "keytype", "auth_addr", "account_data"}},
+	{"account_asset", 2, []string{
+		"addr", "assetid", "amount", "frozen", "deleted", "created_at", "closed_at"}},
+	{"asset", 1, []string{
+		"id", "creator_addr", "params", "name", "unit", "deleted", "created_at", "closed_at"}},
+	{"app", 1, []string{"id", "creator", "params", "deleted", "created_at", "closed_at"}},
+	{"account_app", 2, []string{
+		"addr", "app", "localstate", "deleted", "created_at", "closed_at"}},
+}
+
+// undoTriggersSQL returns the triggers that record the statements reverting
+// every insert, update and delete of the undo tables while undo_round is set.
+func undoTriggersSQL() string {
+	// quoted returns the sql expression that quotes the `columns` of `row` as
+	// literals separated by `sep`, each preceded by `column=` if `named` is set.
+	quoted := func(row string, columns []string, sep string, named bool) string {
+		parts := make([]string, len(columns))
+		for i, column := range columns {
+			prefix := ""
+			if named {
+				prefix = column + "="
+			}
+			parts[i] = "'" + prefix + "' || quote(" + row + "." + column + ")"
+		}
+		return strings.Join(parts, " || '"+sep+"' || ")
+	}
+	const when = "WHEN EXISTS (SELECT 1 FROM undo_round)"
+	const insertUndo = "INSERT INTO state_undo (round, stmt) SELECT round, "
+
+	var b strings.Builder
+	for _, t := range undoTables {
+		pk := t.columns[:t.pk]
+		fmt.Fprintf(
+			&b, "CREATE TRIGGER IF NOT EXISTS %s_undo_insert AFTER INSERT ON %s %s BEGIN\n"+
+				"  %s'DELETE FROM %s WHERE ' || %s FROM undo_round;\nEND;\n",
+			t.name, t.name, when, insertUndo, t.name, quoted("new", pk, " AND ", true))
+		fmt.Fprintf(
+			&b, "CREATE TRIGGER IF NOT EXISTS %s_undo_update AFTER UPDATE ON %s %s BEGIN\n"+
+				"  %s'UPDATE %s SET ' || %s || ' WHERE ' || %s FROM undo_round;\nEND;\n",
+			t.name, t.name, when, insertUndo, t.name, quoted("old", t.columns, ",", true),
+			quoted("old", pk, " AND ", true))
+		fmt.Fprintf(
+			&b, "CREATE TRIGGER IF NOT EXISTS %s_undo_delete AFTER DELETE ON %s %s BEGIN\n"+
+				"  %s'INSERT INTO %s (%s) VALUES (' || %s || ')' FROM undo_round;\nEND;\n",
+			t.name, t.name, when, insertUndo, t.name, strings.Join(t.columns, ","),
+			quoted("old", t.columns, ",", false))
+	}
+	return b.String()
+}
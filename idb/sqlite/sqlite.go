@@ -0,0 +1,594 @@
+// Package sqlite implements idb.IndexerDb on top of a single SQLite database
+// file. It is meant for private networks, tests and small deployments that
+// don't want to run a postgres server.
+//
+// The package only depends on database/sql. Building with `-tags sqlite` links
+// in the github.com/mattn/go-sqlite3 driver and registers the "sqlite"
+// IndexerDb factory.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/algorand/go-algorand/protocol"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/accounting"
+	"github.com/algorand/indexer/idb"
+)
+
+// DriverName is the database/sql driver used by OpenSqlite.
+const DriverName = "sqlite3"
+
+var readonly = &sql.TxOptions{ReadOnly: true}
+
+// OpenSqlite opens or creates the database file at `path`. Returns an error
+// object and a channel that gets closed when the database becomes available.
+func OpenSqlite(path string, opts idb.IndexerDbOptions, log *log.Logger) (*IndexerDb, chan struct{}, error) {
+	if !driverRegistered() {
+		return nil, nil, fmt.Errorf(
+			"OpenSqlite() database/sql driver %s is not registered, build with `-tags sqlite`",
+			DriverName)
+	}
+
+	db, err := sql.Open(DriverName, path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("OpenSqlite() open err: %w", err)
+	}
+
+	return openSqlite(db, opts, log)
+}
+
+func driverRegistered() bool {
+	for _, name := range sql.Drivers() {
+		if name == DriverName {
+			return true
+		}
+	}
+	return false
+}
+
+func openSqlite(db *sql.DB, opts idb.IndexerDbOptions, logger *log.Logger) (*IndexerDb, chan struct{}, error) {
+	idb := &IndexerDb{
+		readonly:          opts.ReadOnly,
+		log:               logger,
+		db:                db,
+		stateDeltaHandler: opts.StateDeltaHandler,
+		maxRollbackRounds: opts.MaxRollbackRounds,
+	}
+
+	if idb.log == nil {
+		idb.log = log.New()
+		idb.log.SetFormatter(&log.JSONFormatter{})
+		idb.log.SetOutput(os.Stdout)
+		idb.log.SetLevel(log.TraceLevel)
+	}
+
+	if !opts.ReadOnly {
+		err := idb.init()
+		if err != nil {
+			db.Close()
+			return nil, nil, fmt.Errorf("initializing sqlite: %w", err)
+		}
+	}
+
+	// There are no migrations yet, the database is available right away.
+	ch := make(chan struct{})
+	close(ch)
+	return idb, ch, nil
+}
+
+// IndexerDb is an idb.IndexerDB implementation
+type IndexerDb struct {
+	readonly bool
+	log      *log.Logger
+
+	db             *sql.DB
+	accountingLock sync.Mutex
+
+	// stateDeltaHandler is optional, see idb.IndexerDbOptions.
+	stateDeltaHandler idb.StateDeltaHandler
+	// maxRollbackRounds, see idb.IndexerDbOptions.
+	maxRollbackRounds uint64
+}
+
+// Close is part of idb.IndexerDb.
+func (db *IndexerDb) Close() {
+	db.db.Close()
+}
+
+func (db *IndexerDb) init() error {
+	// The write-ahead log lets readers run concurrently with the block importer.
+	// The setting is stored in the database file.
+	_, err := db.db.Exec("PRAGMA journal_mode=WAL")
+	if err != nil {
+		return fmt.Errorf("init() set journal mode err: %w", err)
+	}
+
+	_, err = db.db.Exec(setupSQL)
+	if err != nil {
+		return fmt.Errorf("init() setup err: %w", err)
+	}
+
+	_, err = db.db.Exec(undoTriggersSQL())
+	if err != nil {
+		return fmt.Errorf("init() undo triggers err: %w", err)
+	}
+
+	return nil
+}
+
+// querier is implemented by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// If `tx` is nil, use the database.
+func (db *IndexerDb) querier(tx *sql.Tx) querier {
+	if tx == nil {
+		return db.db
+	}
+	return tx
+}
+
+// Returns `idb.ErrorNotInitialized` if uninitialized.
+// If `tx` is nil, use a normal query.
+func (db *IndexerDb) getMetastate(ctx context.Context, tx *sql.Tx, key string, objptr interface{}) error {
+	row := db.querier(tx).QueryRowContext(ctx, `SELECT v FROM metastate WHERE k = ?`, key)
+
+	var value []byte
+	err := row.Scan(&value)
+	if err == sql.ErrNoRows {
+		return idb.ErrorNotInitialized
+	}
+	if err != nil {
+		return fmt.Errorf("getMetastate() err: %w", err)
+	}
+
+	err = protocol.DecodeReflect(value, objptr)
+	if err != nil {
+		return fmt.Errorf("getMetastate() decode %s err: %w", key, err)
+	}
+
+	return nil
+}
+
+// If `tx` is nil, use a normal query.
+func (db *IndexerDb) setMetastate(ctx context.Context, tx *sql.Tx, key string, obj interface{}) error {
+	_, err := db.querier(tx).ExecContext(
+		ctx,
+		`INSERT INTO metastate (k, v) VALUES (?, ?) ON CONFLICT (k) DO UPDATE SET v = excluded.v`,
+		key, protocol.EncodeReflect(obj))
+	if err != nil {
+		return fmt.Errorf("setMetastate() err: %w", err)
+	}
+	return nil
+}
+
+// Returns idb.ErrorNotInitialized if uninitialized.
+// If `tx` is nil, use a normal query.
+func (db *IndexerDb) getImportState(ctx context.Context, tx *sql.Tx) (importState, error) {
+	var state importState
+	err := db.getMetastate(ctx, tx, stateMetastateKey, &state)
+	if err == idb.ErrorNotInitialized {
+		return importState{}, idb.ErrorNotInitialized
+	}
+	if err != nil {
+		return importState{}, fmt.Errorf("unable to get import state err: %w", err)
+	}
+
+	return state, nil
+}
+
+// Returns ErrorNotInitialized if genesis is not loaded.
+// If `tx` is nil, use a normal query.
+func (db *IndexerDb) getNextRoundToAccount(ctx context.Context, tx *sql.Tx) (uint64, error) {
+	state, err := db.getImportState(ctx, tx)
+	if err == idb.ErrorNotInitialized {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getNextRoundToAccount() err: %w", err)
+	}
+
+	return state.NextRoundToAccount, nil
+}
+
+// GetNextRoundToAccount is part of idb.IndexerDB
+// Returns ErrorNotInitialized if genesis is not loaded.
+func (db *IndexerDb) GetNextRoundToAccount() (uint64, error) {
+	return db.getNextRoundToAccount(context.Background(), nil)
+}
+
+// Returns ErrorNotInitialized if genesis is not loaded.
+// If `tx` is nil, use a normal query.
+func (db *IndexerDb) getMaxRoundAccounted(ctx context.Context, tx *sql.Tx) (uint64, error) {
+	round, err := db.getNextRoundToAccount(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if round > 0 {
+		round--
+	}
+	return round, nil
+}
+
+// Returns all addresses referenced in `block`.
+func getBlockAddresses(block *bookkeeping.Block) map[basics.Address]struct{} {
+	// Reserve a reasonable memory size for the map.
+	res := make(map[basics.Address]struct{}, len(block.Payset)+2)
+
+	res[block.FeeSink] = struct{}{}
+	res[block.RewardsPool] = struct{}{}
+	for _, stib := range block.Payset {
+		addFunc := func(address basics.Address) {
+			res[address] = struct{}{}
+		}
+		accounting.GetTransactionParticipants(&stib.SignedTxnWithAD, true, addFunc)
+	}
+
+	return res
+}
+
+func prepareEvalResources(l *ledgerForEvaluator, block *bookkeeping.Block) (ledger.EvalForIndexerResources, error) {
+	addresses := getBlockAddresses(block)
+	assets := make(map[basics.AssetIndex]struct{})
+	apps := make(map[basics.AppIndex]struct{})
+
+	for _, stib := range block.Payset {
+		switch stib.Txn.Type {
+		case protocol.AssetConfigTx:
+			if stib.Txn.ConfigAsset != 0 {
+				assets[stib.Txn.ConfigAsset] = struct{}{}
+			}
+		case protocol.AssetTransferTx:
+			if stib.Txn.XferAsset != 0 {
+				assets[stib.Txn.XferAsset] = struct{}{}
+			}
+		case protocol.AssetFreezeTx:
+			if stib.Txn.FreezeAsset != 0 {
+				assets[stib.Txn.FreezeAsset] = struct{}{}
+			}
+		case protocol.ApplicationCallTx:
+			if stib.Txn.ApplicationID != 0 {
+				apps[stib.Txn.ApplicationID] = struct{}{}
+			}
+		}
+	}
+
+	res := ledger.EvalForIndexerResources{
+		Accounts: nil,
+		Creators: make(map[ledger.Creatable]ledger.FoundAddress),
+	}
+
+	assetCreators, err := l.GetAssetCreator(assets)
+	if err != nil {
+		return ledger.EvalForIndexerResources{},
+			fmt.Errorf("prepareEvalResources() err: %w", err)
+	}
+	for index, foundAddress := range assetCreators {
+		creatable := ledger.Creatable{
+			Index: basics.CreatableIndex(index),
+			Type:  basics.AssetCreatable,
+		}
+		res.Creators[creatable] = foundAddress
+
+		if foundAddress.Exists {
+			addresses[foundAddress.Address] = struct{}{}
+		}
+	}
+
+	appCreators, err := l.GetAppCreator(apps)
+	if err != nil {
+		return ledger.EvalForIndexerResources{},
+			fmt.Errorf("prepareEvalResources() err: %w", err)
+	}
+	for index, foundAddress := range appCreators {
+		creatable := ledger.Creatable{
+			Index: basics.CreatableIndex(index),
+			Type:  basics.AppCreatable,
+		}
+		res.Creators[creatable] = foundAddress
+
+		if foundAddress.Exists {
+			addresses[foundAddress.Address] = struct{}{}
+		}
+	}
+
+	res.Accounts, err = l.LookupWithoutRewards(addresses)
+	if err != nil {
+		return ledger.EvalForIndexerResources{},
+			fmt.Errorf("prepareEvalResources() err: %w", err)
+	}
+
+	return res, nil
+}
+
+// AddBlock is part of idb.IndexerDb.
+func (db *IndexerDb) AddBlock(block *bookkeeping.Block) error {
+	db.log.Printf("adding block %d", block.Round())
+
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	ctx := context.Background()
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("AddBlock() begin tx err: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Check and increment next round counter.
+	importstate, err := db.getImportState(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("AddBlock() err: %w", err)
+	}
+	if block.Round() != basics.Round(importstate.NextRoundToAccount) {
+		return fmt.Errorf(
+			"AddBlock() adding block round %d but next round to account is %d",
+			block.Round(), importstate.NextRoundToAccount)
+	}
+	importstate.NextRoundToAccount++
+
+	// While undo_round is set the undo triggers record the changes of this
+	// round, starting with the import state.
+	if db.maxRollbackRounds > 0 && block.Round() != basics.Round(0) {
+		_, err = tx.ExecContext(ctx, "INSERT INTO undo_round (round) VALUES (?)", uint64(block.Round()))
+		if err != nil {
+			return fmt.Errorf("AddBlock() set undo round err: %w", err)
+		}
+	}
+
+	err = db.setMetastate(ctx, tx, stateMetastateKey, &importstate)
+	if err != nil {
+		return fmt.Errorf("AddBlock() err: %w", err)
+	}
+
+	w, err := makeWriter(tx)
+	if err != nil {
+		return fmt.Errorf("AddBlock() err: %w", err)
+	}
+	defer w.close()
+
+	var delta ledgercore.StateDelta
+	if block.Round() == basics.Round(0) {
+		// Block 0 is special, we cannot run the evaluator on it.
+		err = w.addBlock0(block)
+		if err != nil {
+			return fmt.Errorf("AddBlock() err: %w", err)
+		}
+	} else {
+		proto, ok := config.Consensus[block.BlockHeader.CurrentProtocol]
+		if !ok {
+			return fmt.Errorf(
+				"AddBlock() cannot find proto version %s", block.BlockHeader.CurrentProtocol)
+		}
+		proto.EnableAssetCloseAmount = true
+
+		ledgerForEval, err := makeLedgerForEvaluator(tx, block.Round()-1)
+		if err != nil {
+			return fmt.Errorf("AddBlock() err: %w", err)
+		}
+		defer ledgerForEval.close()
+
+		resources, err := prepareEvalResources(&ledgerForEval, block)
+		if err != nil {
+			return fmt.Errorf("AddBlock() eval err: %w", err)
+		}
+
+		var modifiedTxns []transactions.SignedTxnInBlock
+		delta, modifiedTxns, err =
+			ledger.EvalForIndexer(ledgerForEval, block, proto, resources)
+		if err != nil {
+			return fmt.Errorf("AddBlock() eval err: %w", err)
+		}
+
+		err = w.addBlock(block, modifiedTxns, delta)
+		if err != nil {
+			return fmt.Errorf("AddBlock() err: %w", err)
+		}
+	}
+
+	// Block 0 has no state delta, the genesis allocation is reported by
+	// LoadGenesis(). The round is not committed if the handler fails.
+	if db.stateDeltaHandler != nil && block.Round() != basics.Round(0) {
+		err = db.stateDeltaHandler(block.Round(), &delta)
+		if err != nil {
+			return fmt.Errorf("AddBlock() state delta handler err: %w", err)
+		}
+	}
+
+	err = db.pruneUndo(ctx, tx, uint64(block.Round()))
+	if err != nil {
+		return fmt.Errorf("AddBlock() err: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("AddBlock() commit err: %w", err)
+	}
+
+	return nil
+}
+
+// LoadGenesis is part of idb.IndexerDB
+func (db *IndexerDb) LoadGenesis(genesis bookkeeping.Genesis) error {
+	db.accountingLock.Lock()
+	defer db.accountingLock.Unlock()
+
+	ctx := context.Background()
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("LoadGenesis() begin tx err: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO account
+		(addr, microalgos, rewardsbase, rewards_total, deleted, created_at, auth_addr,
+		 account_data)
+		VALUES (?, ?, 0, 0, 0, 0, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("LoadGenesis() prepare tx err: %w", err)
+	}
+	defer stmt.Close()
+
+	proto, ok := config.Consensus[genesis.Proto]
+	if !ok {
+		return fmt.Errorf("LoadGenesis() consensus version %s not found", genesis.Proto)
+	}
+	var delta ledgercore.StateDelta
+	var ot basics.OverflowTracker
+	var totals ledgercore.AccountTotals
+	for ai, alloc := range genesis.Allocation {
+		addr, err := basics.UnmarshalChecksumAddress(alloc.Address)
+		if err != nil {
+			return fmt.Errorf("LoadGenesis() decode address err: %w", err)
+		}
+		if len(alloc.State.AssetParams) > 0 || len(alloc.State.Assets) > 0 {
+			return fmt.Errorf("LoadGenesis() genesis account[%d] has unhandled asset", ai)
+		}
+		_, err = stmt.ExecContext(
+			ctx, addr[:], alloc.State.MicroAlgos.Raw, nullIfZeroAddress(alloc.State.AuthAddr),
+			encodeAccountData(trimAccountData(alloc.State)))
+		if err != nil {
+			return fmt.Errorf("LoadGenesis() error setting genesis account[%d], %w", ai, err)
+		}
+
+		totals.AddAccount(proto, alloc.State, &ot)
+		delta.Accts.Upsert(addr, alloc.State)
+	}
+	delta.Totals = totals
+
+	err = db.setMetastate(ctx, tx, accountTotalsMetastateKey, &totals)
+	if err != nil {
+		return fmt.Errorf("LoadGenesis() err: %w", err)
+	}
+
+	err = db.setMetastate(ctx, tx, stateMetastateKey, &importState{NextRoundToAccount: 0})
+	if err != nil {
+		return fmt.Errorf("LoadGenesis() err: %w", err)
+	}
+
+	if db.stateDeltaHandler != nil {
+		err = db.stateDeltaHandler(basics.Round(0), &delta)
+		if err != nil {
+			return fmt.Errorf("LoadGenesis() state delta handler err: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("LoadGenesis() commit err: %w", err)
+	}
+
+	return nil
+}
+
+// GetRewardsLevel is part of idb.IndexerDB
+func (db *IndexerDb) GetRewardsLevel(ctx context.Context, round uint64) (uint64, error) {
+	row := db.db.QueryRowContext(ctx, `SELECT rewardslevel FROM block_header WHERE round = ?`, round)
+	var level uint64
+	err := row.Scan(&level)
+	if err == sql.ErrNoRows {
+		return 0, idb.ErrorBlockNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("GetRewardsLevel() err: %w", err)
+	}
+	return level, nil
+}
+
+// GetBlock is part of idb.IndexerDB
+func (db *IndexerDb) GetBlock(ctx context.Context, round uint64, options idb.GetBlockOptions) (blockHeader bookkeeping.BlockHeader, transactions []idb.TxnRow, err error) {
+	tx, err := db.db.BeginTx(ctx, readonly)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `SELECT header FROM block_header WHERE round = ?`, round)
+	var header []byte
+	err = row.Scan(&header)
+	if err == sql.ErrNoRows {
+		err = idb.ErrorBlockNotFound
+		return
+	}
+	if err != nil {
+		return
+	}
+	blockHeader, err = decodeBlockHeader(header)
+	if err != nil {
+		return
+	}
+
+	if options.Transactions {
+		out := make(chan idb.TxnRow, 1)
+		go func() {
+			db.yieldTxns(ctx, tx, idb.TransactionFilter{Round: &round}, out)
+			close(out)
+		}()
+
+		results := make([]idb.TxnRow, 0)
+		for txrow := range out {
+			if txrow.Error != nil {
+				err = txrow.Error
+			}
+			results = append(results, txrow)
+		}
+		if err != nil {
+			return bookkeeping.BlockHeader{}, nil, err
+		}
+		transactions = results
+	}
+
+	return blockHeader, transactions, nil
+}
+
+// Health is part of idb.IndexerDB
+func (db *IndexerDb) Health() (idb.Health, error) {
+	var data = make(map[string]interface{})
+
+	if db.readonly {
+		data["read-only-mode"] = true
+	}
+	data["migration-required"] = false
+
+	round, err := db.getMaxRoundAccounted(context.Background(), nil)
+
+	// We'll just have to set the round to 0
+	if err == idb.ErrorNotInitialized {
+		err = nil
+		round = 0
+	}
+
+	return idb.Health{
+		Data:        &data,
+		Round:       round,
+		IsMigrating: false,
+		DBAvailable: true,
+	}, err
+}
+
+// GetSpecialAccounts is part of idb.IndexerDB
+func (db *IndexerDb) GetSpecialAccounts() (transactions.SpecialAddresses, error) {
+	var accounts transactions.SpecialAddresses
+	err := db.getMetastate(context.Background(), nil, specialAccountsMetastateKey, &accounts)
+	if err != nil {
+		return transactions.SpecialAddresses{}, fmt.Errorf("GetSpecialAccounts() err: %w", err)
+	}
+
+	return accounts, nil
+}
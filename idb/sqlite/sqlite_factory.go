@@ -0,0 +1,31 @@
+// The SQLite driver needs cgo, the backend is only registered when building
+// with `go build --tags sqlite`.
+//go:build sqlite
+// +build sqlite
+
+package sqlite
+
+import (
+	// Registers the "sqlite3" database/sql driver.
+	_ "github.com/mattn/go-sqlite3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/idb"
+)
+
+type sqliteFactory struct {
+}
+
+// Name is part of the IndexerFactory interface.
+func (df sqliteFactory) Name() string {
+	return "sqlite"
+}
+
+// Build is part of the IndexerFactory interface.
+func (df sqliteFactory) Build(arg string, opts idb.IndexerDbOptions, log *log.Logger) (idb.IndexerDb, chan struct{}, error) {
+	return OpenSqlite(arg, opts, log)
+}
+
+func init() {
+	idb.RegisterFactory("sqlite", &sqliteFactory{})
+}
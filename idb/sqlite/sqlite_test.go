@@ -0,0 +1,23 @@
+//go:build sqlite
+// +build sqlite
+
+package sqlite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, sqliteFactory{}, func(t *testing.T) (string, func()) {
+		dir, err := ioutil.TempDir("", "indexer-sqlite")
+		require.NoError(t, err)
+		return filepath.Join(dir, "indexer.db"), func() { os.RemoveAll(dir) }
+	})
+}
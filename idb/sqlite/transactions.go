@@ -0,0 +1,365 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand/data/transactions"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// Block timestamps are whole seconds, so comparisons against the `realtime`
+// column round the filter time.
+func unixCeil(t time.Time) int64 {
+	if t.Nanosecond() > 0 {
+		return t.Unix() + 1
+	}
+	return t.Unix()
+}
+
+func buildTransactionQuery(tf idb.TransactionFilter) (query string, whereArgs []interface{}, err error) {
+	const maxWhereParts = 30
+	whereParts := make([]string, 0, maxWhereParts)
+	whereArgs = make([]interface{}, 0, maxWhereParts)
+	joinParticipation := false
+	if tf.Address != nil {
+		whereParts = append(whereParts, "p.addr = ?")
+		whereArgs = append(whereArgs, tf.Address)
+		if tf.AddressRole != 0 {
+			whereParts = append(whereParts, "(p.roles & ?) != 0")
+			whereArgs = append(whereArgs, uint64(tf.AddressRole))
+		}
+		joinParticipation = true
+	}
+	if tf.MinRound != 0 {
+		whereParts = append(whereParts, "t.round >= ?")
+		whereArgs = append(whereArgs, tf.MinRound)
+	}
+	if tf.MaxRound != 0 {
+		whereParts = append(whereParts, "t.round <= ?")
+		whereArgs = append(whereArgs, tf.MaxRound)
+	}
+	if !tf.BeforeTime.IsZero() {
+		whereParts = append(whereParts, "h.realtime < ?")
+		whereArgs = append(whereArgs, unixCeil(tf.BeforeTime))
+	}
+	if !tf.AfterTime.IsZero() {
+		whereParts = append(whereParts, "h.realtime > ?")
+		whereArgs = append(whereArgs, tf.AfterTime.Unix())
+	}
+	if tf.AssetID != 0 || tf.ApplicationID != 0 {
+		var creatableID uint64
+		if tf.AssetID != 0 {
+			creatableID = tf.AssetID
+			if tf.ApplicationID != 0 && tf.AssetID != tf.ApplicationID {
+				return "", nil, fmt.Errorf("cannot search both assetid and appid")
+			}
+		} else {
+			creatableID = tf.ApplicationID
+		}
+		whereParts = append(whereParts, "t.asset = ?")
+		whereArgs = append(whereArgs, creatableID)
+	}
+	if tf.AssetAmountGT != nil {
+		whereParts = append(whereParts, "t.asset_amount > ?")
+		whereArgs = append(whereArgs, encodeAmount(*tf.AssetAmountGT))
+	}
+	if tf.AssetAmountLT != nil {
+		whereParts = append(whereParts, "t.asset_amount < ?")
+		whereArgs = append(whereArgs, encodeAmount(*tf.AssetAmountLT))
+	}
+	if tf.TypeEnum != 0 {
+		whereParts = append(whereParts, "t.typeenum = ?")
+		whereArgs = append(whereArgs, int(tf.TypeEnum))
+	}
+	if len(tf.Txid) != 0 {
+		whereParts = append(whereParts, "t.txid = ?")
+		whereArgs = append(whereArgs, tf.Txid)
+	}
+	if tf.Round != nil {
+		whereParts = append(whereParts, "t.round = ?")
+		whereArgs = append(whereArgs, *tf.Round)
+	}
+	if tf.Offset != nil {
+		whereParts = append(whereParts, "t.intra = ?")
+		whereArgs = append(whereArgs, *tf.Offset)
+	}
+	if tf.OffsetLT != nil {
+		whereParts = append(whereParts, "t.intra < ?")
+		whereArgs = append(whereArgs, *tf.OffsetLT)
+	}
+	if tf.OffsetGT != nil {
+		whereParts = append(whereParts, "t.intra > ?")
+		whereArgs = append(whereArgs, *tf.OffsetGT)
+	}
+	if len(tf.SigType) != 0 {
+		whereParts = append(whereParts, "t.sigtype = ?")
+		whereArgs = append(whereArgs, string(tf.SigType))
+	}
+	if len(tf.NotePrefix) > 0 {
+		whereParts = append(whereParts, fmt.Sprintf("substr(t.note, 1, %d) = ?", len(tf.NotePrefix)))
+		whereArgs = append(whereArgs, tf.NotePrefix)
+	}
+	if len(tf.GroupID) > 0 {
+		whereParts = append(whereParts, "t.txgroup = ?")
+		whereArgs = append(whereArgs, tf.GroupID)
+	}
+	if len(tf.Lease) > 0 {
+		whereParts = append(whereParts, "t.lease = ?")
+		whereArgs = append(whereArgs, tf.Lease)
+	}
+	if tf.AlgosGT != nil {
+		whereParts = append(whereParts, "t.amount > ?")
+		whereArgs = append(whereArgs, *tf.AlgosGT)
+	}
+	if tf.AlgosLT != nil {
+		whereParts = append(whereParts, "t.amount < ?")
+		whereArgs = append(whereArgs, *tf.AlgosLT)
+	}
+	if tf.EffectiveAmountGT != nil {
+		whereParts = append(whereParts, "(t.close_amount + t.amount) > ?")
+		whereArgs = append(whereArgs, *tf.EffectiveAmountGT)
+	}
+	if tf.EffectiveAmountLT != nil {
+		whereParts = append(whereParts, "(t.close_amount + t.amount) < ?")
+		whereArgs = append(whereArgs, *tf.EffectiveAmountLT)
+	}
+	if tf.RekeyTo != nil && (*tf.RekeyTo) {
+		whereParts = append(whereParts, "t.rekey_to IS NOT NULL")
+	}
+	query = "SELECT t.round, t.intra, t.txn, root.txn, t.extra, t.asset, h.realtime FROM txn t JOIN block_header h ON t.round = h.round"
+	if joinParticipation {
+		query += " JOIN txn_participation p ON t.round = p.round AND t.intra = p.intra"
+	}
+
+	// join in the root transaction
+	query += " LEFT OUTER JOIN txn root ON t.round = root.round AND t.root_intra = root.intra"
+
+	if len(whereParts) > 0 {
+		whereStr := strings.Join(whereParts, " AND ")
+		query += " WHERE " + whereStr
+	}
+	if joinParticipation {
+		// this should match the primary key on txn_participation
+		query += " ORDER BY p.addr, p.round DESC, p.intra DESC"
+	} else {
+		// this should explicitly match the primary key on txn (round,intra)
+		query += " ORDER BY t.round, t.intra"
+	}
+	if tf.Limit != 0 {
+		query += fmt.Sprintf(" LIMIT %d", tf.Limit)
+	}
+	return
+}
+
+// This function blocks. `tx` must be non-nil.
+func (db *IndexerDb) yieldTxns(ctx context.Context, tx *sql.Tx, tf idb.TransactionFilter, out chan<- idb.TxnRow) {
+	if len(tf.NextToken) > 0 {
+		db.txnsWithNext(ctx, tx, tf, out)
+		return
+	}
+
+	query, whereArgs, err := buildTransactionQuery(tf)
+	if err != nil {
+		err = fmt.Errorf("txn query err %v", err)
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+
+	rows, err := tx.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		err = fmt.Errorf("txn query %#v err %v", query, err)
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+
+	db.yieldTxnsThreadSimple(ctx, rows, out, nil, nil)
+}
+
+// Transactions is part of idb.IndexerDB
+func (db *IndexerDb) Transactions(ctx context.Context, tf idb.TransactionFilter) (<-chan idb.TxnRow, uint64) {
+	out := make(chan idb.TxnRow, 1)
+
+	tx, err := db.db.BeginTx(ctx, readonly)
+	if err != nil {
+		out <- idb.TxnRow{Error: err}
+		close(out)
+		return out, 0
+	}
+
+	round, err := db.getMaxRoundAccounted(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		out <- idb.TxnRow{Error: err}
+		close(out)
+		return out, round
+	}
+
+	go func() {
+		db.yieldTxns(ctx, tx, tf, out)
+		tx.Rollback()
+		close(out)
+	}()
+
+	return out, round
+}
+
+// This function blocks. `tx` must be non-nil.
+func (db *IndexerDb) txnsWithNext(ctx context.Context, tx *sql.Tx, tf idb.TransactionFilter, out chan<- idb.TxnRow) {
+	// Check for remainder of round from previous page.
+	nextround, nextintra32, err := idb.DecodeTxnRowNext(tf.NextToken)
+	nextintra := uint64(nextintra32)
+	if err != nil {
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+	origRound := tf.Round
+	origOLT := tf.OffsetLT
+	origOGT := tf.OffsetGT
+	if tf.Address != nil {
+		// (round,intra) descending into the past
+		if nextround == 0 && nextintra == 0 {
+			return
+		}
+		tf.Round = &nextround
+		tf.OffsetLT = &nextintra
+	} else {
+		// (round,intra) ascending into the future
+		tf.Round = &nextround
+		tf.OffsetGT = &nextintra
+	}
+	query, whereArgs, err := buildTransactionQuery(tf)
+	if err != nil {
+		err = fmt.Errorf("txn query err %v", err)
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+	rows, err := tx.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		err = fmt.Errorf("txn query %#v err %v", query, err)
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+
+	count := 0
+	db.yieldTxnsThreadSimple(ctx, rows, out, &count, &err)
+	if err != nil {
+		return
+	}
+
+	// If we haven't reached the limit, restore the original filter and
+	// re-run the original search with new Min/Max round and reduced limit.
+	if uint64(count) >= tf.Limit {
+		return
+	}
+	tf.Limit -= uint64(count)
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	tf.Round = origRound
+	if tf.Address != nil {
+		// (round,intra) descending into the past
+		tf.OffsetLT = origOLT
+
+		if nextround <= 1 {
+			// NO second query
+			return
+		}
+
+		tf.MaxRound = nextround - 1
+	} else {
+		// (round,intra) ascending into the future
+		tf.OffsetGT = origOGT
+		tf.MinRound = nextround + 1
+	}
+	query, whereArgs, err = buildTransactionQuery(tf)
+	if err != nil {
+		err = fmt.Errorf("txn query err %v", err)
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+	rows, err = tx.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		err = fmt.Errorf("txn query %#v err %v", query, err)
+		out <- idb.TxnRow{Error: err}
+		return
+	}
+	db.yieldTxnsThreadSimple(ctx, rows, out, nil, nil)
+}
+
+func (db *IndexerDb) yieldTxnsThreadSimple(ctx context.Context, rows *sql.Rows, results chan<- idb.TxnRow, countp *int, errp *error) {
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var round uint64
+		var asset uint64
+		var intra int
+		var txn []byte
+		var roottxn []byte
+		var extra []byte
+		var realtime int64
+		err := rows.Scan(&round, &intra, &txn, &roottxn, &extra, &asset, &realtime)
+		var row idb.TxnRow
+		if err != nil {
+			row.Error = err
+		} else {
+			row.Round = round
+			row.Intra = intra
+			if roottxn != nil {
+				// Inner transaction.
+				row.RootTxn = new(transactions.SignedTxnWithAD)
+				*row.RootTxn, err = decodeSignedTxnWithAD(roottxn)
+				if err != nil {
+					err = fmt.Errorf("error decoding roottxn, err: %w", err)
+					row.Error = err
+				}
+			} else {
+				// Root transaction.
+				row.Txn = new(transactions.SignedTxnWithAD)
+				*row.Txn, err = decodeSignedTxnWithAD(txn)
+				if err != nil {
+					err = fmt.Errorf("error decoding txn, err: %w", err)
+					row.Error = err
+				}
+			}
+			row.RoundTime = time.Unix(realtime, 0).UTC()
+			row.AssetID = asset
+			if len(extra) > 0 {
+				row.Extra, err = decodeTxnExtra(extra)
+				if err != nil {
+					err = fmt.Errorf("%d:%d decode txn extra, %v", row.Round, row.Intra, err)
+					row.Error = err
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			goto finish
+		case results <- row:
+			if err != nil {
+				if errp != nil {
+					*errp = err
+				}
+				goto finish
+			}
+			count++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		results <- idb.TxnRow{Error: err}
+		if errp != nil {
+			*errp = err
+		}
+	}
+finish:
+	if countp != nil {
+		*countp = count
+	}
+}
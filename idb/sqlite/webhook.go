@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// webhookKey returns the metastate key of the webhook `id`.
+func webhookKey(id string) string {
+	return webhookMetastateKeyPrefix + id
+}
+
+// GetWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) GetWebhook(id string) (idb.Webhook, error) {
+	var webhook idb.Webhook
+	err := db.getMetastate(context.Background(), nil, webhookKey(id), &webhook)
+	if err == idb.ErrorNotInitialized {
+		return idb.Webhook{}, idb.ErrorWebhookNotFound
+	}
+	if err != nil {
+		return idb.Webhook{}, fmt.Errorf("GetWebhook() err: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetWebhooks is part of idb.IndexerDb.
+func (db *IndexerDb) GetWebhooks() (map[string]idb.Webhook, error) {
+	rows, err := db.db.QueryContext(
+		context.Background(), `SELECT k, v FROM metastate WHERE substr(k, 1, ?) = ?`,
+		len(webhookMetastateKeyPrefix), webhookMetastateKeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("GetWebhooks() query err: %w", err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]idb.Webhook)
+	for rows.Next() {
+		var key string
+		var value []byte
+		err = rows.Scan(&key, &value)
+		if err != nil {
+			return nil, fmt.Errorf("GetWebhooks() scan err: %w", err)
+		}
+		var webhook idb.Webhook
+		err = protocol.DecodeReflect(value, &webhook)
+		if err != nil {
+			return nil, fmt.Errorf("GetWebhooks() decode %s err: %w", key, err)
+		}
+		res[strings.TrimPrefix(key, webhookMetastateKeyPrefix)] = webhook
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetWebhooks() query err: %w", err)
+	}
+	return res, nil
+}
+
+// SetWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) SetWebhook(id string, webhook idb.Webhook) error {
+	err := db.setMetastate(context.Background(), nil, webhookKey(id), &webhook)
+	if err != nil {
+		return fmt.Errorf("SetWebhook() err: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook is part of idb.IndexerDb.
+func (db *IndexerDb) DeleteWebhook(id string) error {
+	_, err := db.db.ExecContext(
+		context.Background(), `DELETE FROM metastate WHERE k = ?`, webhookKey(id))
+	if err != nil {
+		return fmt.Errorf("DeleteWebhook() err: %w", err)
+	}
+	return nil
+}
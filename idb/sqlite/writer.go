@@ -0,0 +1,594 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger/ledgercore"
+	"github.com/algorand/go-algorand/protocol"
+
+	"github.com/algorand/indexer/accounting"
+	"github.com/algorand/indexer/idb"
+)
+
+const (
+	addBlockHeaderStmtName             = "add_block_header"
+	setSpecialAccountsStmtName         = "set_special_accounts"
+	upsertAssetStmtName                = "upsert_asset"
+	upsertAccountAssetStmtName         = "upsert_account_asset"
+	upsertAppStmtName                  = "upsert_app"
+	upsertAccountAppStmtName           = "upsert_account_app"
+	deleteAccountStmtName              = "delete_account"
+	deleteAccountUpdateKeytypeStmtName = "delete_account_update_keytype"
+	upsertAccountStmtName              = "upsert_account"
+	upsertAccountWithKeytypeStmtName   = "upsert_account_with_keytype"
+	deleteAssetStmtName                = "delete_asset"
+	deleteAccountAssetStmtName         = "delete_account_asset"
+	deleteAppStmtName                  = "delete_app"
+	deleteAccountAppStmtName           = "delete_account_app"
+	updateAccountTotalsStmtName        = "update_account_totals"
+	addTxnStmtName                     = "add_txn"
+	addTxnParticipationStmtName        = "add_txn_participation"
+)
+
+var writerStatements = map[string]string{
+	addBlockHeaderStmtName: `INSERT INTO block_header
+		(round, realtime, rewardslevel, header)
+		VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+	setSpecialAccountsStmtName: `INSERT INTO metastate (k, v) VALUES ('` +
+		specialAccountsMetastateKey + `', ?) ON CONFLICT (k) DO UPDATE SET v = excluded.v`,
+	upsertAssetStmtName: `INSERT INTO asset
+		(id, creator_addr, params, name, unit, deleted, created_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?) ON CONFLICT (id) DO UPDATE SET
+		creator_addr = excluded.creator_addr, params = excluded.params,
+		name = excluded.name, unit = excluded.unit, deleted = 0`,
+	upsertAccountAssetStmtName: `INSERT INTO account_asset
+		(addr, assetid, amount, frozen, deleted, created_at)
+		VALUES (?, ?, ?, ?, 0, ?) ON CONFLICT (addr, assetid) DO UPDATE SET
+		amount = excluded.amount, frozen = excluded.frozen, deleted = 0`,
+	upsertAppStmtName: `INSERT INTO app
+		(id, creator, params, deleted, created_at)
+		VALUES (?, ?, ?, 0, ?) ON CONFLICT (id) DO UPDATE SET
+		creator = excluded.creator, params = excluded.params, deleted = 0`,
+	upsertAccountAppStmtName: `INSERT INTO account_app
+		(addr, app, localstate, deleted, created_at)
+		VALUES (?, ?, ?, 0, ?) ON CONFLICT (addr, app) DO UPDATE SET
+		localstate = excluded.localstate, deleted = 0`,
+	deleteAccountStmtName: `INSERT INTO account
+		(addr, microalgos, rewardsbase, rewards_total, deleted, created_at, closed_at,
+		 auth_addr, account_data)
+		VALUES (?1, 0, 0, 0, 1, ?2, ?2, NULL, NULL) ON CONFLICT (addr) DO UPDATE SET
+		microalgos = excluded.microalgos, rewardsbase = excluded.rewardsbase,
+		rewards_total = excluded.rewards_total, deleted = 1,
+		closed_at = excluded.closed_at, auth_addr = excluded.auth_addr,
+		account_data = excluded.account_data`,
+	deleteAccountUpdateKeytypeStmtName: `INSERT INTO account
+		(addr, microalgos, rewardsbase, rewards_total, deleted, created_at, closed_at,
+		 keytype, auth_addr, account_data)
+		VALUES (?1, 0, 0, 0, 1, ?2, ?2, ?3, NULL, NULL) ON CONFLICT (addr) DO UPDATE SET
+		microalgos = excluded.microalgos, rewardsbase = excluded.rewardsbase,
+		rewards_total = excluded.rewards_total, deleted = 1,
+		closed_at = excluded.closed_at, keytype = excluded.keytype,
+		auth_addr = excluded.auth_addr, account_data = excluded.account_data`,
+	upsertAccountStmtName: `INSERT INTO account
+		(addr, microalgos, rewardsbase, rewards_total, deleted, created_at, auth_addr,
+		 account_data)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?) ON CONFLICT (addr) DO UPDATE SET
+		microalgos = excluded.microalgos, rewardsbase = excluded.rewardsbase,
+		rewards_total = excluded.rewards_total, deleted = 0,
+		auth_addr = excluded.auth_addr, account_data = excluded.account_data`,
+	upsertAccountWithKeytypeStmtName: `INSERT INTO account
+		(addr, microalgos, rewardsbase, rewards_total, deleted, created_at, keytype,
+		 auth_addr, account_data)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?, ?) ON CONFLICT (addr) DO UPDATE SET
+		microalgos = excluded.microalgos, rewardsbase = excluded.rewardsbase,
+		rewards_total = excluded.rewards_total, deleted = 0, keytype = excluded.keytype,
+		auth_addr = excluded.auth_addr, account_data = excluded.account_data`,
+	deleteAssetStmtName: `INSERT INTO asset
+		(id, creator_addr, params, deleted, created_at, closed_at)
+		VALUES (?1, ?2, NULL, 1, ?3, ?3) ON CONFLICT (id) DO UPDATE SET
+		creator_addr = excluded.creator_addr, params = excluded.params, deleted = 1,
+		closed_at = excluded.closed_at`,
+	deleteAccountAssetStmtName: `INSERT INTO account_asset
+		(addr, assetid, amount, frozen, deleted, created_at, closed_at)
+		VALUES (?1, ?2, '` + zeroAmount + `', 0, 1, ?3, ?3) ON CONFLICT (addr, assetid) DO UPDATE SET
+		amount = excluded.amount, deleted = 1, closed_at = excluded.closed_at`,
+	deleteAppStmtName: `INSERT INTO app
+		(id, creator, params, deleted, created_at, closed_at)
+		VALUES (?1, ?2, NULL, 1, ?3, ?3) ON CONFLICT (id) DO UPDATE SET
+		creator = excluded.creator, params = excluded.params, deleted = 1,
+		closed_at = excluded.closed_at`,
+	deleteAccountAppStmtName: `INSERT INTO account_app
+		(addr, app, localstate, deleted, created_at, closed_at)
+		VALUES (?1, ?2, NULL, 1, ?3, ?3) ON CONFLICT (addr, app) DO UPDATE SET
+		localstate = excluded.localstate, deleted = 1, closed_at = excluded.closed_at`,
+	updateAccountTotalsStmtName: `UPDATE metastate SET v = ? WHERE k = '` +
+		accountTotalsMetastateKey + `'`,
+	addTxnStmtName: `INSERT INTO txn
+		(round, intra, typeenum, asset, txid, txn, extra, root_intra, sigtype, note, amount,
+		 close_amount, asset_amount, rekey_to, txgroup, lease)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	addTxnParticipationStmtName: `INSERT INTO txn_participation
+		(addr, round, intra, roles) VALUES (?, ?, ?, ?)`,
+}
+
+var zeroAmount = encodeAmount(0)
+
+// writer is responsible for writing blocks and accounting state deltas to the
+// database.
+type writer struct {
+	stmts map[string]*sql.Stmt
+}
+
+func makeWriter(tx *sql.Tx) (writer, error) {
+	w := writer{
+		stmts: make(map[string]*sql.Stmt, len(writerStatements)),
+	}
+
+	for name, query := range writerStatements {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			w.close()
+			return writer{}, fmt.Errorf("makeWriter() prepare statement err: %w", err)
+		}
+		w.stmts[name] = stmt
+	}
+
+	return w, nil
+}
+
+func (w *writer) close() {
+	for _, stmt := range w.stmts {
+		stmt.Close()
+	}
+}
+
+func (w *writer) exec(name string, args ...interface{}) error {
+	_, err := w.stmts[name].Exec(args...)
+	if err != nil {
+		return fmt.Errorf("exec %s err: %w", name, err)
+	}
+	return nil
+}
+
+func (w *writer) addBlockHeader(blockHeader *bookkeeping.BlockHeader) error {
+	err := w.exec(
+		addBlockHeaderStmtName,
+		uint64(blockHeader.Round), blockHeader.TimeStamp, blockHeader.RewardsLevel,
+		encodeBlockHeader(*blockHeader))
+	if err != nil {
+		return err
+	}
+
+	specialAddresses := transactions.SpecialAddresses{
+		FeeSink:     blockHeader.FeeSink,
+		RewardsPool: blockHeader.RewardsPool,
+	}
+	return w.exec(setSpecialAccountsStmtName, protocol.EncodeReflect(&specialAddresses))
+}
+
+// Describes a change to the `account.keytype` column. If `present` is true,
+// `value` is the new value. Otherwise, NULL will be the new value.
+type sigTypeDelta struct {
+	present bool
+	value   idb.SigType
+}
+
+func getSigTypeDeltas(payset []transactions.SignedTxnInBlock) (map[basics.Address]sigTypeDelta, error) {
+	res := make(map[basics.Address]sigTypeDelta, len(payset))
+
+	for i := range payset {
+		if payset[i].Txn.RekeyTo == (basics.Address{}) {
+			sigtype, err := idb.SignatureType(&payset[i].SignedTxn)
+			if err != nil {
+				return nil, fmt.Errorf("getSigTypeDelta() err: %w", err)
+			}
+			res[payset[i].Txn.Sender] = sigTypeDelta{present: true, value: sigtype}
+		} else {
+			res[payset[i].Txn.Sender] = sigTypeDelta{}
+		}
+	}
+
+	return res, nil
+}
+
+type optionalSigTypeDelta struct {
+	present bool
+	value   sigTypeDelta
+}
+
+func (w *writer) writeAccount(round basics.Round, address basics.Address, accountData basics.AccountData, sigtypeDelta optionalSigTypeDelta) error {
+	// Update `asset` table.
+	for assetid, params := range accountData.AssetParams {
+		err := w.exec(
+			upsertAssetStmtName,
+			uint64(assetid), address[:], encodeAssetParams(params), params.AssetName,
+			params.UnitName, uint64(round))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update `account_asset` table.
+	for assetid, holding := range accountData.Assets {
+		err := w.exec(
+			upsertAccountAssetStmtName,
+			address[:], uint64(assetid), encodeAmount(holding.Amount), holding.Frozen,
+			uint64(round))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update `app` table.
+	for appid, params := range accountData.AppParams {
+		err := w.exec(
+			upsertAppStmtName,
+			uint64(appid), address[:], encodeAppParams(params), uint64(round))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update `account_app` table.
+	for appid, state := range accountData.AppLocalStates {
+		err := w.exec(
+			upsertAccountAppStmtName,
+			address[:], uint64(appid), encodeAppLocalState(state), uint64(round))
+		if err != nil {
+			return err
+		}
+	}
+
+	sigtypeFunc := func(delta sigTypeDelta) interface{} {
+		if !delta.present {
+			return nil
+		}
+		return string(delta.value)
+	}
+
+	// Update `account` table.
+	if accountData.IsZero() {
+		// Delete account.
+		if sigtypeDelta.present {
+			return w.exec(
+				deleteAccountUpdateKeytypeStmtName,
+				address[:], uint64(round), sigtypeFunc(sigtypeDelta.value))
+		}
+		return w.exec(deleteAccountStmtName, address[:], uint64(round))
+	}
+
+	// Update account.
+	accountDataBytes := encodeAccountData(trimAccountData(accountData))
+	if sigtypeDelta.present {
+		return w.exec(
+			upsertAccountWithKeytypeStmtName,
+			address[:], accountData.MicroAlgos.Raw, accountData.RewardsBase,
+			accountData.RewardedMicroAlgos.Raw, uint64(round),
+			sigtypeFunc(sigtypeDelta.value), nullIfZeroAddress(accountData.AuthAddr),
+			accountDataBytes)
+	}
+	return w.exec(
+		upsertAccountStmtName,
+		address[:], accountData.MicroAlgos.Raw, accountData.RewardsBase,
+		accountData.RewardedMicroAlgos.Raw, uint64(round),
+		nullIfZeroAddress(accountData.AuthAddr), accountDataBytes)
+}
+
+func (w *writer) writeAccounts(round basics.Round, accountDeltas ledgercore.AccountDeltas, sigtypeDeltas map[basics.Address]sigTypeDelta) error {
+	for i := 0; i < accountDeltas.Len(); i++ {
+		address, accountData := accountDeltas.GetByIdx(i)
+
+		var sigtypeDelta optionalSigTypeDelta
+		sigtypeDelta.value, sigtypeDelta.present = sigtypeDeltas[address]
+
+		err := w.writeAccount(round, address, accountData, sigtypeDelta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) writeDeletedCreatables(round basics.Round, creatables map[basics.CreatableIndex]ledgercore.ModifiedCreatable) error {
+	for index, creatable := range creatables {
+		// If deleted.
+		if !creatable.Created {
+			name := deleteAppStmtName
+			if creatable.Ctype == basics.AssetCreatable {
+				name = deleteAssetStmtName
+			}
+			err := w.exec(name, uint64(index), creatable.Creator[:], uint64(round))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) writeDeletedAssetHoldings(round basics.Round, modifiedAssetHoldings map[ledgercore.AccountAsset]bool) error {
+	for aa, created := range modifiedAssetHoldings {
+		if !created {
+			err := w.exec(
+				deleteAccountAssetStmtName, aa.Address[:], uint64(aa.Asset), uint64(round))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) writeDeletedAppLocalStates(round basics.Round, modifiedAppLocalStates map[ledgercore.AccountApp]bool) error {
+	for aa, created := range modifiedAppLocalStates {
+		if !created {
+			err := w.exec(
+				deleteAccountAppStmtName, aa.Address[:], uint64(aa.App), uint64(round))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addBlock0 writes block 0 to the database.
+func (w *writer) addBlock0(block *bookkeeping.Block) error {
+	err := w.addBlockHeader(&block.BlockHeader)
+	if err != nil {
+		return fmt.Errorf("addBlock0() err: %w", err)
+	}
+	return nil
+}
+
+// addBlock writes the block, its transactions and the accounting state deltas to
+// the database.
+func (w *writer) addBlock(block *bookkeeping.Block, modifiedTxns []transactions.SignedTxnInBlock, delta ledgercore.StateDelta) error {
+	err := w.addBlockHeader(&block.BlockHeader)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+
+	err = w.addTransactions(block, modifiedTxns)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.addTransactionParticipation(block)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+
+	sigTypeDeltas, err := getSigTypeDeltas(block.Payset)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeAccounts(block.Round(), delta.Accts, sigTypeDeltas)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeDeletedCreatables(block.Round(), delta.Creatables)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeDeletedAssetHoldings(block.Round(), delta.ModifiedAssetHoldings)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.writeDeletedAppLocalStates(block.Round(), delta.ModifiedAppLocalStates)
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+	err = w.exec(updateAccountTotalsStmtName, protocol.EncodeReflect(&delta.Totals))
+	if err != nil {
+		return fmt.Errorf("addBlock() err: %w", err)
+	}
+
+	return nil
+}
+
+// addTxn writes one row of the `txn` table. `stxnad` is the transaction of the
+// row, `sigtype` and `rootIntra` are nil for root and inner transactions
+// respectively.
+func (w *writer) addTxn(round basics.Round, intra uint, assetid uint64, txid interface{}, stxnad *transactions.SignedTxnWithAD, extra *idb.TxnExtra, rootIntra interface{}, sigtype interface{}) error {
+	txn := &stxnad.Txn
+	typeenum, ok := idb.GetTypeEnum(txn.Type)
+	if !ok {
+		return fmt.Errorf("addTxn() get type enum")
+	}
+
+	return w.exec(
+		addTxnStmtName,
+		uint64(round), intra, int(typeenum), assetid, txid, encodeSignedTxnWithAD(*stxnad),
+		encodeTxnExtra(extra), rootIntra, sigtype, nullIfEmpty(txn.Note),
+		nullIfZero(txn.Amount.Raw), nullIfZero(stxnad.ApplyData.ClosingAmount.Raw),
+		nullIfZeroAmount(txn.AssetAmount), nullIfZeroAddress(txn.RekeyTo),
+		nullIfZeroDigest(txn.Group), nullIfZeroDigest(txn.Lease))
+}
+
+// Traverses the inner transaction tree and writes `txn` rows. It performs a
+// preorder traversal to correctly compute the intra round offset, the offset
+// for the next transaction is returned.
+func (w *writer) addInnerTransactions(stxnad *transactions.SignedTxnWithAD, round basics.Round, intra, rootIntra uint, rootTxid string) (uint, error) {
+	for _, itxn := range stxnad.ApplyData.EvalDelta.InnerTxns {
+		// block shouldn't be used for inner transactions.
+		assetid, err := idb.TransactionAssetID(&itxn, 0, nil)
+		if err != nil {
+			return 0, err
+		}
+		extra := idb.TxnExtra{
+			AssetCloseAmount: itxn.ApplyData.AssetClosingAmount,
+			RootIntra:        idb.OptionalUint{Present: true, Value: rootIntra},
+			RootTxid:         rootTxid,
+		}
+
+		// When encoding an inner transaction we remove any further nested inner transactions.
+		// To reconstruct a full object the root transaction must be fetched.
+		txnNoInner := itxn
+		txnNoInner.EvalDelta.InnerTxns = nil
+
+		// Inner transactions do not have a txid or a signature.
+		err = w.addTxn(round, intra, assetid, nil, &txnNoInner, &extra, rootIntra, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		// Recurse at end for preorder traversal
+		intra, err = w.addInnerTransactions(&itxn, round, intra+1, rootIntra, rootTxid)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return intra, nil
+}
+
+// addTransactions writes the transactions of `block`, including inner
+// transactions. `modifiedTxns` contains enhanced apply data generated by
+// evaluator.
+func (w *writer) addTransactions(block *bookkeeping.Block, modifiedTxns []transactions.SignedTxnInBlock) error {
+	intra := uint(0)
+	for idx, stib := range block.Payset {
+		var stxnad transactions.SignedTxnWithAD
+		var err error
+		// This function makes sure to set correct genesis information so we can get the
+		// correct transaction hash.
+		stxnad.SignedTxn, stxnad.ApplyData, err = block.BlockHeader.DecodeSignedTxn(stib)
+		if err != nil {
+			return fmt.Errorf("addTransactions() decode signed txn err: %w", err)
+		}
+
+		assetid, err := idb.TransactionAssetID(&stxnad, intra, block)
+		if err != nil {
+			return fmt.Errorf("addTransactions() err: %w", err)
+		}
+		id := stxnad.Txn.ID().String()
+		extra := idb.TxnExtra{
+			AssetCloseAmount: modifiedTxns[idx].ApplyData.AssetClosingAmount,
+		}
+		err = w.addTxn(
+			block.Round(), intra, assetid, id, &stxnad, &extra, nil,
+			sigTypeColumn(&stxnad.SignedTxn))
+		if err != nil {
+			return fmt.Errorf("addTransactions() err: %w", err)
+		}
+
+		intra, err = w.addInnerTransactions(
+			&stib.SignedTxnWithAD, block.Round(), intra+1, intra, id)
+		if err != nil {
+			return fmt.Errorf("addTransactions() adding inner: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sigTypeColumn returns the name of the signature field that is set in `stxn`.
+// Unlike idb.SignatureType(), a delegated logic signature is "lsig", the same
+// as the json key checked by the postgres sigtype filter.
+func sigTypeColumn(stxn *transactions.SignedTxn) interface{} {
+	switch {
+	case !stxn.Sig.Blank():
+		return string(idb.Sig)
+	case !stxn.Msig.Blank():
+		return string(idb.Msig)
+	case !stxn.Lsig.Blank():
+		return string(idb.Lsig)
+	}
+	return nil
+}
+
+// addressRoles returns the roles of `address` in `txn`. Unset address fields
+// never match, like the omitted fields of the postgres json.
+func addressRoles(txn *transactions.Transaction, address basics.Address) idb.AddressRole {
+	var roles idb.AddressRole
+	add := func(field basics.Address, role idb.AddressRole) {
+		if !field.IsZero() && field == address {
+			roles |= role
+		}
+	}
+
+	add(txn.Sender, idb.AddressRoleSender)
+	add(txn.Receiver, idb.AddressRoleReceiver)
+	add(txn.CloseRemainderTo, idb.AddressRoleCloseRemainderTo)
+	add(txn.AssetSender, idb.AddressRoleAssetSender)
+	add(txn.AssetReceiver, idb.AddressRoleAssetReceiver)
+	add(txn.AssetCloseTo, idb.AddressRoleAssetCloseTo)
+	add(txn.FreezeAccount, idb.AddressRoleFreeze)
+
+	return roles
+}
+
+// getTransactionParticipants returns referenced addresses from the txn and,
+// optionally, all inner txns.
+func getTransactionParticipants(stxnad *transactions.SignedTxnWithAD, includeInner bool) map[basics.Address]struct{} {
+	res := make(map[basics.Address]struct{})
+	add := func(address basics.Address) {
+		res[address] = struct{}{}
+	}
+	accounting.GetTransactionParticipants(stxnad, includeInner, add)
+
+	return res
+}
+
+func (w *writer) addParticipants(stxnad *transactions.SignedTxnWithAD, round basics.Round, intra uint64, includeInner bool) error {
+	for address := range getTransactionParticipants(stxnad, includeInner) {
+		err := w.exec(
+			addTxnParticipationStmtName,
+			address[:], uint64(round), intra, uint64(addressRoles(&stxnad.Txn, address)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addInnerTransactionParticipation traverses the inner transaction tree and
+// adds txn participation records for each. It performs a preorder traversal
+// to correctly compute the intra round offset, the offset for the next
+// transaction is returned.
+func (w *writer) addInnerTransactionParticipation(stxnad *transactions.SignedTxnWithAD, round basics.Round, intra uint64) (uint64, error) {
+	next := intra
+	for _, itxn := range stxnad.ApplyData.EvalDelta.InnerTxns {
+		// Only search inner transactions by direct participation.
+		err := w.addParticipants(&itxn, round, next, false)
+		if err != nil {
+			return 0, err
+		}
+
+		next, err = w.addInnerTransactionParticipation(&itxn, round, next+1)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return next, nil
+}
+
+// addTransactionParticipation writes account participation info to the
+// `txn_participation` table.
+func (w *writer) addTransactionParticipation(block *bookkeeping.Block) error {
+	next := uint64(0)
+	for _, stxnib := range block.Payset {
+		err := w.addParticipants(&stxnib.SignedTxnWithAD, block.Round(), next, true)
+		if err != nil {
+			return fmt.Errorf("addTransactionParticipation() err: %w", err)
+		}
+
+		next, err = w.addInnerTransactionParticipation(
+			&stxnib.SignedTxnWithAD, block.Round(), next+1)
+		if err != nil {
+			return fmt.Errorf("addTransactionParticipation() err: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package idb_test
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb"
+)
+
+func TestTransactionAssetID(t *testing.T) {
+	var xfer, created, call transactions.SignedTxnWithAD
+	xfer.Txn.Type = protocol.AssetTransferTx
+	xfer.Txn.XferAsset = 5
+	created.Txn.Type = protocol.AssetConfigTx
+	created.ApplyData.ConfigAsset = 6
+	call.Txn.Type = protocol.ApplicationCallTx
+
+	id, err := idb.TransactionAssetID(&xfer, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), id)
+
+	id, err = idb.TransactionAssetID(&created, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), id)
+
+	// Without apply data the id of a creation follows from the txn counter.
+	block := bookkeeping.Block{
+		BlockHeader: bookkeeping.BlockHeader{TxnCounter: 10},
+		Payset:      make(transactions.Payset, 3),
+	}
+	id, err = idb.TransactionAssetID(&call, 1, &block)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), id)
+
+	_, err = idb.TransactionAssetID(&call, 0, nil)
+	assert.Error(t, err)
+
+	call.Txn.ApplicationID = basics.AppIndex(7)
+	id, err = idb.TransactionAssetID(&call, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), id)
+}
@@ -0,0 +1,62 @@
+package idb
+
+import "errors"
+
+// ErrorWebhookNotFound is returned by GetWebhook() if there is no webhook with
+// the id.
+var ErrorWebhookNotFound = errors.New("webhook not found")
+
+// WebhookFilter selects the transactions delivered to a webhook. The fields
+// are the transaction search parameters of the same name, empty fields do not
+// filter.
+type WebhookFilter struct {
+	Address             string  `codec:"address" json:"address,omitempty"`
+	AddressRole         string  `codec:"address-role" json:"address-role,omitempty"`
+	AssetID             uint64  `codec:"asset-id" json:"asset-id,omitempty"`
+	ApplicationID       uint64  `codec:"application-id" json:"application-id,omitempty"`
+	TxType              string  `codec:"tx-type" json:"tx-type,omitempty"`
+	CurrencyGreaterThan *uint64 `codec:"currency-greater-than" json:"currency-greater-than,omitempty"`
+}
+
+// WebhookDeadLetter is a round whose delivery failed too many times and was
+// skipped.
+type WebhookDeadLetter struct {
+	Round    uint64 `codec:"round" json:"round"`
+	Attempts uint64 `codec:"attempts" json:"attempts"`
+	Error    string `codec:"error" json:"error"`
+	// Time is the unix time of the last attempt.
+	Time int64 `codec:"time" json:"time"`
+}
+
+// Webhook is a subscription to the transactions matching a filter, and its
+// delivery state. The transactions are POSTed to URL one round at a time,
+// signed with Secret.
+type Webhook struct {
+	URL    string        `codec:"url"`
+	Secret string        `codec:"secret"`
+	Filter WebhookFilter `codec:"filter"`
+	Paused bool          `codec:"paused"`
+
+	// NextRound is the next round to deliver. The earlier rounds are delivered
+	// or dead-lettered.
+	NextRound uint64 `codec:"next_round"`
+	// Replay are rounds queued again, which are delivered before NextRound.
+	Replay []uint64 `codec:"replay"`
+
+	// Attempts is the number of failed attempts of the next delivery, which is
+	// retried at the unix time NextAttempt.
+	Attempts    uint64 `codec:"attempts"`
+	NextAttempt int64  `codec:"next_attempt"`
+	LastError   string `codec:"last_error"`
+
+	DeadLetters []WebhookDeadLetter `codec:"dead_letters"`
+}
+
+// PendingRound returns the round of the next delivery: the first round to
+// replay, or NextRound.
+func (w *Webhook) PendingRound() uint64 {
+	if len(w.Replay) > 0 {
+		return w.Replay[0]
+	}
+	return w.NextRound
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/rpcs"
+)
+
+// DefaultArchiveRoundsPerFile is the default number of blocks in one archive
+// file.
+const DefaultArchiveRoundsPerFile = 1000
+
+// ArchiverOptions configure an Archiver.
+type ArchiverOptions struct {
+	// Dir is the directory of the archive files.
+	Dir string
+
+	// RoundsPerFile is the size of the aligned round range stored in one file,
+	// DefaultArchiveRoundsPerFile if 0.
+	RoundsPerFile uint64
+
+	// OnPublish is called with the path of every published file, e.g. to
+	// upload it. A file whose hook fails stays published.
+	OnPublish func(path string) error
+}
+
+// archiveFileRe matches the names of published archive files, the same
+// `{first}_{last}` names as the ones of misc/blockarchiver.py.
+var archiveFileRe = regexp.MustCompile(`^(\d+)_(\d+)\.tar\.gz$`)
+
+// ArchiveFileName returns the name of the archive file of rounds
+// `first`..`last`. The names sort in round order with the block tar paths of
+// ImportHelper.Import().
+func ArchiveFileName(first, last uint64) string {
+	return fmt.Sprintf("%d_%d.tar.gz", first, last)
+}
+
+// Archiver writes the blocks received from algod to gzip compressed tar files
+// that ImportHelper.Import() reads. Each file entry is named after the round
+// and holds the msgpack encoded rpcs.EncodedBlockCert. Only complete round
+// ranges are published, so after a restart the archiver resumes at the first
+// round of the range it was writing.
+type Archiver struct {
+	opts ArchiverOptions
+
+	// The file being written, nil between ranges.
+	file  *os.File
+	buf   *bufio.Writer
+	gz    *gzip.Writer
+	tw    *tar.Writer
+	first uint64
+	last  uint64
+	next  uint64
+}
+
+// MakeArchiver creates an Archiver. The directory is created if needed.
+func MakeArchiver(opts ArchiverOptions) (*Archiver, error) {
+	if opts.RoundsPerFile == 0 {
+		opts.RoundsPerFile = DefaultArchiveRoundsPerFile
+	}
+	err := os.MkdirAll(opts.Dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("MakeArchiver() err: %w", err)
+	}
+	return &Archiver{opts: opts}, nil
+}
+
+// NextRound returns the round after the last archived round, based on the
+// published files.
+func (a *Archiver) NextRound() (uint64, error) {
+	entries, err := ioutil.ReadDir(a.opts.Dir)
+	if err != nil {
+		return 0, fmt.Errorf("NextRound() err: %w", err)
+	}
+
+	next := uint64(0)
+	for _, entry := range entries {
+		match := archiveFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		last, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		if last+1 > next {
+			next = last + 1
+		}
+	}
+	return next, nil
+}
+
+// Path returns the path of the published archive file of rounds
+// `first`..`last`.
+func (a *Archiver) Path(first, last uint64) string {
+	return filepath.Join(a.opts.Dir, ArchiveFileName(first, last))
+}
+
+// open starts a new file with `round`. The file ends with the last round of
+// the aligned range of `round`, it is written under a temporary name until
+// then.
+func (a *Archiver) open(round uint64) error {
+	a.first = round
+	a.last = round - round%a.opts.RoundsPerFile + a.opts.RoundsPerFile - 1
+	file, err := os.Create(a.Path(a.first, a.last) + ".tmp")
+	if err != nil {
+		return fmt.Errorf("open() err: %w", err)
+	}
+	a.file = file
+	a.buf = bufio.NewWriter(file)
+	a.gz = gzip.NewWriter(a.buf)
+	a.tw = tar.NewWriter(a.gz)
+	return nil
+}
+
+// publish completes the open file and renames it to its final name.
+func (a *Archiver) publish() error {
+	tmp := a.file.Name()
+	err := a.tw.Close()
+	if err == nil {
+		err = a.gz.Close()
+	}
+	if err == nil {
+		err = a.buf.Flush()
+	}
+	if err == nil {
+		err = a.file.Sync()
+	}
+	if closeErr := a.file.Close(); err == nil {
+		err = closeErr
+	}
+	a.file = nil
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("publish() %s err: %w", tmp, err)
+	}
+
+	path := a.Path(a.first, a.last)
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("publish() err: %w", err)
+	}
+	if a.opts.OnPublish != nil {
+		err = a.opts.OnPublish(path)
+		if err != nil {
+			return fmt.Errorf("publish() %s hook err: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// HandleBlock appends a block to the open file, and publishes the file after
+// the last round of its range. It has the signature expected by
+// fetcher.Fetcher.SetBlockHandler().
+func (a *Archiver) HandleBlock(ctx context.Context, block *rpcs.EncodedBlockCert) error {
+	round := uint64(block.Block.Round())
+	if a.file != nil && round != a.next {
+		return fmt.Errorf("HandleBlock() expected round %d but got %d", a.next, round)
+	}
+	if a.file == nil {
+		err := a.open(round)
+		if err != nil {
+			return fmt.Errorf("HandleBlock() err: %w", err)
+		}
+	}
+
+	data := protocol.Encode(block)
+	header := tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     strconv.FormatUint(round, 10),
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Unix(block.Block.TimeStamp, 0),
+	}
+	err := a.tw.WriteHeader(&header)
+	if err == nil {
+		_, err = a.tw.Write(data)
+	}
+	if err != nil {
+		return fmt.Errorf("HandleBlock() round %d err: %w", round, err)
+	}
+	a.next = round + 1
+
+	if round == a.last {
+		err = a.publish()
+		if err != nil {
+			return fmt.Errorf("HandleBlock() err: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close discards the file of the incomplete round range.
+func (a *Archiver) Close() {
+	if a.file == nil {
+		return
+	}
+	a.file.Close()
+	os.Remove(a.file.Name())
+	a.file = nil
+}
@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/rpcs"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb/mocks"
+	"github.com/algorand/indexer/s3"
+	"github.com/algorand/indexer/s3/s3test"
+	"github.com/algorand/indexer/util/test"
+)
+
+func makeBlockCerts(t *testing.T, n int) []rpcs.EncodedBlockCert {
+	var res []rpcs.EncodedBlockCert
+	prev := test.MakeGenesisBlock().BlockHeader
+	res = append(res, rpcs.EncodedBlockCert{Block: bookkeeping.Block{BlockHeader: prev}})
+	for i := 1; i < n; i++ {
+		pay := test.MakePaymentTxn(1000, uint64(i), 0, 0, 0, 0, test.AccountA, test.AccountB, basics.Address{}, basics.Address{})
+		block, err := test.MakeBlockForTxns(prev, &pay)
+		require.NoError(t, err)
+		res = append(res, rpcs.EncodedBlockCert{Block: block})
+		prev = block.BlockHeader
+	}
+	return res
+}
+
+func TestArchiverPublishesCompleteRanges(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := MakeArchiver(ArchiverOptions{Dir: dir, RoundsPerFile: 4})
+	require.NoError(t, err)
+
+	next, err := archiver.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), next)
+
+	blocks := makeBlockCerts(t, 6)
+	for i := range blocks {
+		require.NoError(t, archiver.HandleBlock(context.Background(), &blocks[i]))
+	}
+
+	// Rounds 0-3 are published, 4-5 are pending.
+	assert.FileExists(t, archiver.Path(0, 3))
+	assert.NoFileExists(t, archiver.Path(4, 7))
+	next, err = archiver.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), next)
+
+	// Rounds must follow each other.
+	assert.Error(t, archiver.HandleBlock(context.Background(), &blocks[2]))
+
+	// The incomplete range is discarded, a new archiver resumes at its first
+	// round.
+	archiver.Close()
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{archiver.Path(0, 3)}, files)
+
+	archiver, err = MakeArchiver(ArchiverOptions{Dir: dir, RoundsPerFile: 4})
+	require.NoError(t, err)
+	defer archiver.Close()
+	next, err = archiver.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), next)
+}
+
+func TestArchiveImport(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := MakeArchiver(ArchiverOptions{Dir: dir, RoundsPerFile: 4})
+	require.NoError(t, err)
+	defer archiver.Close()
+
+	blocks := makeBlockCerts(t, 4)
+	for i := range blocks {
+		require.NoError(t, archiver.HandleBlock(context.Background(), &blocks[i]))
+	}
+
+	db := &mocks.IndexerDb{}
+	var rounds []basics.Round
+	db.On("AddBlock", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		rounds = append(rounds, args.Get(0).(*bookkeeping.Block).Round())
+	})
+	imp := NewImporter(db)
+
+	fin, err := os.Open(archiver.Path(0, 3))
+	require.NoError(t, err)
+	defer fin.Close()
+	gzin, err := gzip.NewReader(fin)
+	require.NoError(t, err)
+	blockCount, txCount, err := importTar(&imp, gzin, log.New())
+	require.NoError(t, err)
+	assert.Equal(t, 4, blockCount)
+	assert.Equal(t, 3, txCount)
+	assert.Equal(t, []basics.Round{0, 1, 2, 3}, rounds)
+}
+
+func TestArchiveS3Import(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+	server.CreateBucket("blocks")
+	client, err := s3.MakeClient(server.Options("blocks"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	var published []string
+	archiver, err := MakeArchiver(ArchiverOptions{
+		Dir:           dir,
+		RoundsPerFile: 4,
+		OnPublish: func(path string) error {
+			published = append(published, path)
+			_, err := client.SyncDir(context.Background(), dir, "archive")
+			return err
+		},
+	})
+	require.NoError(t, err)
+	defer archiver.Close()
+
+	blocks := makeBlockCerts(t, 10)
+	for i := range blocks {
+		require.NoError(t, archiver.HandleBlock(context.Background(), &blocks[i]))
+	}
+	assert.Equal(t, []string{archiver.Path(0, 3), archiver.Path(4, 7)}, published)
+
+	db := &mocks.IndexerDb{}
+	var rounds []basics.Round
+	db.On("AddBlock", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		rounds = append(rounds, args.Get(0).(*bookkeeping.Block).Round())
+	})
+	imp := NewImporter(db)
+
+	helper := ImportHelper{S3: server.Options(""), Log: log.New()}
+	blockCount, txCount := helper.importS3("s3://blocks/archive/*.tar.gz", &imp)
+	assert.Equal(t, 8, blockCount)
+	assert.Equal(t, 7, txCount)
+	assert.Equal(t, []basics.Round{0, 1, 2, 3, 4, 5, 6, 7}, rounds)
+}
+
+func TestArchiveFileNamesSort(t *testing.T) {
+	paths := blockTarPaths{
+		ArchiveFileName(1000, 1999),
+		ArchiveFileName(0, 999),
+		ArchiveFileName(10000, 10999),
+		ArchiveFileName(2000, 2999),
+	}
+	sort.Sort(&paths)
+	expected := blockTarPaths{
+		ArchiveFileName(0, 999),
+		ArchiveFileName(1000, 1999),
+		ArchiveFileName(2000, 2999),
+		ArchiveFileName(10000, 10999),
+	}
+	assert.Equal(t, expected, paths)
+}
@@ -3,10 +3,12 @@ package importer
 import (
 	"archive/tar"
 	"compress/bzip2"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -21,6 +23,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/s3"
 )
 
 // NewImportHelper builds an ImportHelper
@@ -40,9 +43,17 @@ type ImportHelper struct {
 	// BlockFileLimit is the number of block files to process.
 	BlockFileLimit int
 
+	// S3 are the endpoint and credentials used for s3://bucket/pattern
+	// arguments. The bucket is taken from the argument.
+	S3 s3.Options
+
 	Log *log.Logger
 }
 
+// s3Scheme starts the arguments of Import() that are object key patterns of
+// an S3 bucket.
+const s3Scheme = "s3://"
+
 // Import is the main ImportHelper function that glues together a directory full of block files and an Importer objects.
 func (h *ImportHelper) Import(db idb.IndexerDb, args []string) {
 	_, err := db.GetNextRoundToAccount()
@@ -58,6 +69,12 @@ func (h *ImportHelper) Import(db idb.IndexerDb, args []string) {
 	txCount := 0
 	start := time.Now()
 	for _, fname := range args {
+		if strings.HasPrefix(fname, s3Scheme) {
+			fb, ft := h.importS3(fname, &imp)
+			blocks += fb
+			txCount += ft
+			continue
+		}
 		matches, err := filepath.Glob(fname)
 		if err == nil {
 			pathsSorted := blockTarPaths(matches)
@@ -133,43 +150,84 @@ func importTar(imp *Importer, tarfile io.Reader, l *log.Logger) (blockCount, txC
 		if err != nil {
 			return
 		}
+		blockCount++
 	}
 
 	return
 }
 
+// importReader imports the blocks of a block file read from `r`, a tar file
+// compressed according to the suffix of `fname` or a standalone block.
+func importReader(fname string, r io.Reader, imp *Importer, l *log.Logger) (blocks, txCount int, err error) {
+	switch {
+	case strings.HasSuffix(fname, ".tar"):
+		return importTar(imp, r, l)
+	case strings.HasSuffix(fname, ".tar.bz2"):
+		return importTar(imp, bzip2.NewReader(r), l)
+	case strings.HasSuffix(fname, ".tar.gz"):
+		gzin, err := gzip.NewReader(r)
+		if err != nil {
+			return 0, 0, err
+		}
+		return importTar(imp, gzin, l)
+	}
+
+	// assume a standalone block msgpack blob
+	blockbytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not read, %v", err)
+	}
+	var blockContainer rpcs.EncodedBlockCert
+	err = protocol.Decode(blockbytes, &blockContainer)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot decode blockbytes err: %v", err)
+	}
+	err = imp.ImportBlock(&blockContainer)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot import block err: %v", err)
+	}
+	return 1, len(blockContainer.Block.Payset), nil
+}
+
 func importFile(fname string, imp *Importer, l *log.Logger) (blocks, txCount int) {
-	blocks = 0
-	txCount = 0
 	l.Infof("importing %s ...", fname)
-	if strings.HasSuffix(fname, ".tar") {
-		fin, err := os.Open(fname)
-		maybeFail(err, l, "%s: %v", fname, err)
-		defer fin.Close()
-		tblocks, btxns, err := importTar(imp, fin, l)
-		maybeFail(err, l, "%s: %v", fname, err)
-		blocks += tblocks
-		txCount += btxns
-	} else if strings.HasSuffix(fname, ".tar.bz2") {
-		fin, err := os.Open(fname)
-		maybeFail(err, l, "%s: %v", fname, err)
-		defer fin.Close()
-		bzin := bzip2.NewReader(fin)
-		tblocks, btxns, err := importTar(imp, bzin, l)
-		maybeFail(err, l, "%s: %v", fname, err)
-		blocks += tblocks
-		txCount += btxns
-	} else {
-		// assume a standalone block msgpack blob
-		blockbytes, err := ioutil.ReadFile(fname)
-		maybeFail(err, l, "%s: could not read, %v", fname, err)
-		var blockContainer rpcs.EncodedBlockCert
-		err = protocol.Decode(blockbytes, &blockContainer)
-		maybeFail(err, l, "cannot decode blockbytes err: %v", err)
-		err = imp.ImportBlock(&blockContainer)
-		maybeFail(err, l, "cannot import block err: %v", err)
-		blocks++
-		txCount += len(blockContainer.Block.Payset)
+	fin, err := os.Open(fname)
+	maybeFail(err, l, "%s: %v", fname, err)
+	defer fin.Close()
+	blocks, txCount, err = importReader(fname, fin, imp, l)
+	maybeFail(err, l, "%s: %v", fname, err)
+	return
+}
+
+// importS3 imports the block files of an s3://bucket/pattern argument. Only
+// the files of the manifest of the pattern directory are imported when there
+// is one, so files that are still being uploaded are skipped.
+func (h *ImportHelper) importS3(arg string, imp *Importer) (blocks, txCount int) {
+	u, err := url.Parse(arg)
+	maybeFail(err, h.Log, "%s: %v", arg, err)
+	opts := h.S3
+	opts.Bucket = u.Host
+	client, err := s3.MakeClient(opts)
+	maybeFail(err, h.Log, "%s: %v", arg, err)
+
+	ctx := context.Background()
+	keys, err := client.Match(ctx, strings.TrimPrefix(u.Path, "/"))
+	maybeFail(err, h.Log, "%s: %v", arg, err)
+	pathsSorted := blockTarPaths(keys)
+	sort.Sort(&pathsSorted)
+	if h.BlockFileLimit != 0 && len(pathsSorted) > h.BlockFileLimit {
+		pathsSorted = pathsSorted[:h.BlockFileLimit]
+	}
+
+	for _, key := range pathsSorted {
+		h.Log.Infof("importing s3://%s/%s ...", opts.Bucket, key)
+		body, err := client.Get(ctx, key)
+		maybeFail(err, h.Log, "%s: %v", key, err)
+		fb, ft, err := importReader(key, body, imp, h.Log)
+		body.Close()
+		maybeFail(err, h.Log, "%s: %v", key, err)
+		blocks += fb
+		txCount += ft
 	}
 	return
 }
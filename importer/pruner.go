@@ -0,0 +1,158 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/algorand/indexer/idb"
+)
+
+// DefaultPruneBatchRounds is the default number of rounds whose transactions
+// are removed in one database transaction.
+const DefaultPruneBatchRounds = 1000
+
+// DefaultPruneInterval is the default time between two checks for rounds to
+// prune.
+const DefaultPruneInterval = 10 * time.Minute
+
+// PrunerOptions configure a Pruner. At least one of Rounds and Duration must be
+// set, with both set a round is kept while it is within either window.
+type PrunerOptions struct {
+	// Rounds keeps the transactions of this many most recent rounds.
+	Rounds uint64
+
+	// Duration keeps the transactions of the rounds with a block time within
+	// this duration of the current time.
+	Duration time.Duration
+
+	// BatchRounds is the number of rounds pruned at once,
+	// DefaultPruneBatchRounds if 0. The block import waits for a batch.
+	BatchRounds uint64
+
+	// Interval is the time between two checks, DefaultPruneInterval if 0.
+	Interval time.Duration
+}
+
+// Pruner removes the transactions that are older than the retention window
+// from the database, oldest rounds first, in batches of rounds. The state
+// tables and block headers are kept.
+type Pruner struct {
+	db   idb.IndexerDb
+	opts PrunerOptions
+	log  *log.Logger
+
+	// now returns the current time, it is replaced by tests.
+	now func() time.Time
+}
+
+// MakePruner creates a Pruner.
+func MakePruner(db idb.IndexerDb, opts PrunerOptions, logger *log.Logger) (*Pruner, error) {
+	if opts.Rounds == 0 && opts.Duration <= 0 {
+		return nil, errors.New("MakePruner() no retention rounds or duration")
+	}
+	if opts.BatchRounds == 0 {
+		opts.BatchRounds = DefaultPruneBatchRounds
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultPruneInterval
+	}
+	return &Pruner{db: db, opts: opts, log: logger, now: time.Now}, nil
+}
+
+// firstRoundAfter returns the first round in [`first`, `last`] with a block
+// time at or after `t`, `last` if there is none. Block times do not decrease.
+func (p *Pruner) firstRoundAfter(ctx context.Context, first, last uint64, t time.Time) (uint64, error) {
+	for first < last {
+		mid := first + (last-first)/2
+		header, _, err := p.db.GetBlock(ctx, mid, idb.GetBlockOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("firstRoundAfter() round %d err: %w", mid, err)
+		}
+		if header.TimeStamp >= t.Unix() {
+			last = mid
+		} else {
+			first = mid + 1
+		}
+	}
+	return first, nil
+}
+
+// PruneRound returns the oldest round to keep, given the oldest round with
+// transactions. The last imported round is always kept.
+func (p *Pruner) PruneRound(ctx context.Context, oldest uint64) (uint64, error) {
+	next, err := p.db.GetNextRoundToAccount()
+	if err == idb.ErrorNotInitialized || (err == nil && next == 0) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("PruneRound() err: %w", err)
+	}
+	last := next - 1
+	if oldest > last {
+		return oldest, nil
+	}
+
+	round := last
+	if p.opts.Rounds > 0 {
+		round = 0
+		if next > p.opts.Rounds {
+			round = next - p.opts.Rounds
+		}
+	}
+	if p.opts.Duration > 0 {
+		byTime, err := p.firstRoundAfter(ctx, oldest, last, p.now().Add(-p.opts.Duration))
+		if err != nil {
+			return 0, fmt.Errorf("PruneRound() err: %w", err)
+		}
+		if p.opts.Rounds == 0 || byTime < round {
+			round = byTime
+		}
+	}
+	return round, nil
+}
+
+// Prune removes the transactions of the rounds before PruneRound().
+func (p *Pruner) Prune(ctx context.Context) error {
+	oldest, err := p.db.GetOldestRound()
+	if err != nil {
+		return fmt.Errorf("Prune() err: %w", err)
+	}
+	round, err := p.PruneRound(ctx, oldest)
+	if err != nil {
+		return fmt.Errorf("Prune() err: %w", err)
+	}
+
+	for oldest < round && ctx.Err() == nil {
+		batch := oldest + p.opts.BatchRounds
+		if batch > round {
+			batch = round
+		}
+		err = p.db.PruneTransactions(batch)
+		if err != nil {
+			return fmt.Errorf("Prune() err: %w", err)
+		}
+		p.log.Infof("pruned the transactions of rounds %d to %d", oldest, batch-1)
+		oldest = batch
+	}
+	return nil
+}
+
+// Run prunes the transactions every Interval until the context is canceled.
+func (p *Pruner) Run(ctx context.Context) {
+	for {
+		err := p.Prune(ctx)
+		if err != nil && ctx.Err() == nil {
+			p.log.WithError(err).Error("pruning transactions failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.opts.Interval):
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/idb"
+	"github.com/algorand/indexer/idb/mocks"
+)
+
+// prunerDb returns a database with rounds 0 to 99, 10 seconds apart starting
+// at time 1000, of which the transactions before `oldest` are pruned.
+func prunerDb(oldest uint64) *mocks.IndexerDb {
+	db := &mocks.IndexerDb{}
+	db.On("GetNextRoundToAccount").Return(uint64(100), nil)
+	db.On("GetOldestRound").Return(oldest, nil)
+	db.On("GetBlock", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, round uint64, options idb.GetBlockOptions) bookkeeping.BlockHeader {
+			return bookkeeping.BlockHeader{TimeStamp: 1000 + 10*int64(round)}
+		},
+		nil, nil)
+	return db
+}
+
+func TestPruneRound(t *testing.T) {
+	testcases := []struct {
+		name     string
+		opts     PrunerOptions
+		oldest   uint64
+		expected uint64
+	}{
+		{"rounds", PrunerOptions{Rounds: 10}, 0, 90},
+		{"more rounds than imported", PrunerOptions{Rounds: 200}, 0, 0},
+		{"duration", PrunerOptions{Duration: 100 * time.Second}, 0, 90},
+		{"duration between blocks", PrunerOptions{Duration: 95 * time.Second}, 0, 91},
+		{"duration after the last block", PrunerOptions{Duration: time.Second}, 0, 99},
+		{"duration after pruning", PrunerOptions{Duration: 100 * time.Second}, 95, 95},
+		{"larger window wins", PrunerOptions{Rounds: 5, Duration: 100 * time.Second}, 0, 90},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := MakePruner(prunerDb(tc.oldest), tc.opts, log.New())
+			require.NoError(t, err)
+			// The time of round 100.
+			p.now = func() time.Time { return time.Unix(2000, 0) }
+
+			round, err := p.PruneRound(context.Background(), tc.oldest)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, round)
+		})
+	}
+
+	_, err := MakePruner(prunerDb(0), PrunerOptions{}, log.New())
+	assert.Error(t, err)
+}
+
+func TestPruneBatches(t *testing.T) {
+	db := prunerDb(15)
+	var rounds []uint64
+	db.On("PruneTransactions", mock.Anything).Return(func(round uint64) error {
+		rounds = append(rounds, round)
+		return nil
+	})
+
+	p, err := MakePruner(db, PrunerOptions{Rounds: 50, BatchRounds: 10}, log.New())
+	require.NoError(t, err)
+	require.NoError(t, p.Prune(context.Background()))
+	assert.Equal(t, []uint64{25, 35, 45, 50}, rounds)
+}
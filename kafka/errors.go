@@ -0,0 +1,60 @@
+package kafka
+
+import "fmt"
+
+// Error is an error code returned by a broker.
+type Error int16
+
+// Error codes used by the producer.
+const (
+	ErrUnknown                  Error = -1
+	ErrCorruptMessage           Error = 2
+	ErrUnknownTopicOrPartition  Error = 3
+	ErrLeaderNotAvailable       Error = 5
+	ErrNotLeaderForPartition    Error = 6
+	ErrRequestTimedOut          Error = 7
+	ErrMessageTooLarge          Error = 10
+	ErrNetworkException         Error = 13
+	ErrRecordListTooLarge       Error = 18
+	ErrNotEnoughReplicas        Error = 19
+	ErrNotEnoughReplicasAppend  Error = 20
+	ErrTopicAuthorizationFailed Error = 29
+	ErrKafkaStorageError        Error = 56
+)
+
+var errorNames = map[Error]string{
+	ErrUnknown:                  "UNKNOWN_SERVER_ERROR",
+	ErrCorruptMessage:           "CORRUPT_MESSAGE",
+	ErrUnknownTopicOrPartition:  "UNKNOWN_TOPIC_OR_PARTITION",
+	ErrLeaderNotAvailable:       "LEADER_NOT_AVAILABLE",
+	ErrNotLeaderForPartition:    "NOT_LEADER_OR_FOLLOWER",
+	ErrRequestTimedOut:          "REQUEST_TIMED_OUT",
+	ErrMessageTooLarge:          "MESSAGE_TOO_LARGE",
+	ErrNetworkException:         "NETWORK_EXCEPTION",
+	ErrRecordListTooLarge:       "RECORD_LIST_TOO_LARGE",
+	ErrNotEnoughReplicas:        "NOT_ENOUGH_REPLICAS",
+	ErrNotEnoughReplicasAppend:  "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+	ErrTopicAuthorizationFailed: "TOPIC_AUTHORIZATION_FAILED",
+	ErrKafkaStorageError:        "KAFKA_STORAGE_ERROR",
+}
+
+// Error is part of the error interface.
+func (e Error) Error() string {
+	if name, ok := errorNames[e]; ok {
+		return fmt.Sprintf("kafka error %d %s", int16(e), name)
+	}
+	return fmt.Sprintf("kafka error %d", int16(e))
+}
+
+// Retriable returns whether a request that failed with the error may
+// succeed when retried, e.g. after a leader election.
+func (e Error) Retriable() bool {
+	switch e {
+	case ErrCorruptMessage, ErrUnknownTopicOrPartition, ErrLeaderNotAvailable,
+		ErrNotLeaderForPartition, ErrRequestTimedOut, ErrNetworkException,
+		ErrNotEnoughReplicas, ErrNotEnoughReplicasAppend, ErrKafkaStorageError:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,241 @@
+package protocol
+
+// MetadataRequest is the Metadata request, version 4.
+type MetadataRequest struct {
+	Topics                 []string
+	AllowAutoTopicCreation bool
+}
+
+// Encode is the encoder of MetadataRequest.
+func (r *MetadataRequest) Encode(e *Encoder) {
+	e.ArrayLen(len(r.Topics))
+	for _, topic := range r.Topics {
+		e.String(topic)
+	}
+	e.Bool(r.AllowAutoTopicCreation)
+}
+
+// Decode is the decoder of MetadataRequest.
+func (r *MetadataRequest) Decode(d *Decoder) {
+	n := d.ArrayLen()
+	r.Topics = make([]string, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		r.Topics = append(r.Topics, d.String())
+	}
+	r.AllowAutoTopicCreation = d.Bool()
+}
+
+// MetadataBroker is a broker of MetadataResponse.
+type MetadataBroker struct {
+	NodeID int32
+	Host   string
+	Port   int32
+	Rack   *string
+}
+
+// MetadataPartition is a partition of MetadataTopic.
+type MetadataPartition struct {
+	ErrorCode int16
+	Index     int32
+	Leader    int32
+	Replicas  []int32
+	Isr       []int32
+}
+
+// MetadataTopic is a topic of MetadataResponse.
+type MetadataTopic struct {
+	ErrorCode  int16
+	Name       string
+	IsInternal bool
+	Partitions []MetadataPartition
+}
+
+// MetadataResponse is the Metadata response, version 4.
+type MetadataResponse struct {
+	ThrottleTimeMs int32
+	Brokers        []MetadataBroker
+	ClusterID      *string
+	ControllerID   int32
+	Topics         []MetadataTopic
+}
+
+// Encode is the encoder of MetadataResponse.
+func (r *MetadataResponse) Encode(e *Encoder) {
+	e.Int32(r.ThrottleTimeMs)
+	e.ArrayLen(len(r.Brokers))
+	for _, b := range r.Brokers {
+		e.Int32(b.NodeID)
+		e.String(b.Host)
+		e.Int32(b.Port)
+		e.NullableString(b.Rack)
+	}
+	e.NullableString(r.ClusterID)
+	e.Int32(r.ControllerID)
+	e.ArrayLen(len(r.Topics))
+	for _, t := range r.Topics {
+		e.Int16(t.ErrorCode)
+		e.String(t.Name)
+		e.Bool(t.IsInternal)
+		e.ArrayLen(len(t.Partitions))
+		for _, p := range t.Partitions {
+			e.Int16(p.ErrorCode)
+			e.Int32(p.Index)
+			e.Int32(p.Leader)
+			e.Int32Array(p.Replicas)
+			e.Int32Array(p.Isr)
+		}
+	}
+}
+
+// Decode is the decoder of MetadataResponse.
+func (r *MetadataResponse) Decode(d *Decoder) {
+	r.ThrottleTimeMs = d.Int32()
+	n := d.ArrayLen()
+	r.Brokers = make([]MetadataBroker, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		r.Brokers = append(r.Brokers, MetadataBroker{
+			NodeID: d.Int32(),
+			Host:   d.String(),
+			Port:   d.Int32(),
+			Rack:   d.NullableString(),
+		})
+	}
+	r.ClusterID = d.NullableString()
+	r.ControllerID = d.Int32()
+	n = d.ArrayLen()
+	r.Topics = make([]MetadataTopic, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		t := MetadataTopic{
+			ErrorCode:  d.Int16(),
+			Name:       d.String(),
+			IsInternal: d.Bool(),
+		}
+		m := d.ArrayLen()
+		t.Partitions = make([]MetadataPartition, 0, m)
+		for j := 0; j < m && d.Err() == nil; j++ {
+			t.Partitions = append(t.Partitions, MetadataPartition{
+				ErrorCode: d.Int16(),
+				Index:     d.Int32(),
+				Leader:    d.Int32(),
+				Replicas:  d.Int32Array(),
+				Isr:       d.Int32Array(),
+			})
+		}
+		r.Topics = append(r.Topics, t)
+	}
+}
+
+// ProducePartition is a partition of ProduceTopic, with its encoded record
+// batches.
+type ProducePartition struct {
+	Index   int32
+	Records []byte
+}
+
+// ProduceTopic is a topic of ProduceRequest.
+type ProduceTopic struct {
+	Name       string
+	Partitions []ProducePartition
+}
+
+// ProduceRequest is the Produce request, version 3.
+type ProduceRequest struct {
+	TransactionalID *string
+	Acks            int16
+	TimeoutMs       int32
+	Topics          []ProduceTopic
+}
+
+// Encode is the encoder of ProduceRequest.
+func (r *ProduceRequest) Encode(e *Encoder) {
+	e.NullableString(r.TransactionalID)
+	e.Int16(r.Acks)
+	e.Int32(r.TimeoutMs)
+	e.ArrayLen(len(r.Topics))
+	for _, t := range r.Topics {
+		e.String(t.Name)
+		e.ArrayLen(len(t.Partitions))
+		for _, p := range t.Partitions {
+			e.Int32(p.Index)
+			e.Bytes(p.Records)
+		}
+	}
+}
+
+// Decode is the decoder of ProduceRequest.
+func (r *ProduceRequest) Decode(d *Decoder) {
+	r.TransactionalID = d.NullableString()
+	r.Acks = d.Int16()
+	r.TimeoutMs = d.Int32()
+	n := d.ArrayLen()
+	r.Topics = make([]ProduceTopic, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		t := ProduceTopic{Name: d.String()}
+		m := d.ArrayLen()
+		t.Partitions = make([]ProducePartition, 0, m)
+		for j := 0; j < m && d.Err() == nil; j++ {
+			t.Partitions = append(t.Partitions, ProducePartition{
+				Index:   d.Int32(),
+				Records: d.Bytes(),
+			})
+		}
+		r.Topics = append(r.Topics, t)
+	}
+}
+
+// ProducePartitionResponse is a partition of ProduceTopicResponse.
+type ProducePartitionResponse struct {
+	Index           int32
+	ErrorCode       int16
+	BaseOffset      int64
+	LogAppendTimeMs int64
+}
+
+// ProduceTopicResponse is a topic of ProduceResponse.
+type ProduceTopicResponse struct {
+	Name       string
+	Partitions []ProducePartitionResponse
+}
+
+// ProduceResponse is the Produce response, version 3.
+type ProduceResponse struct {
+	Topics         []ProduceTopicResponse
+	ThrottleTimeMs int32
+}
+
+// Encode is the encoder of ProduceResponse.
+func (r *ProduceResponse) Encode(e *Encoder) {
+	e.ArrayLen(len(r.Topics))
+	for _, t := range r.Topics {
+		e.String(t.Name)
+		e.ArrayLen(len(t.Partitions))
+		for _, p := range t.Partitions {
+			e.Int32(p.Index)
+			e.Int16(p.ErrorCode)
+			e.Int64(p.BaseOffset)
+			e.Int64(p.LogAppendTimeMs)
+		}
+	}
+	e.Int32(r.ThrottleTimeMs)
+}
+
+// Decode is the decoder of ProduceResponse.
+func (r *ProduceResponse) Decode(d *Decoder) {
+	n := d.ArrayLen()
+	r.Topics = make([]ProduceTopicResponse, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		t := ProduceTopicResponse{Name: d.String()}
+		m := d.ArrayLen()
+		t.Partitions = make([]ProducePartitionResponse, 0, m)
+		for j := 0; j < m && d.Err() == nil; j++ {
+			t.Partitions = append(t.Partitions, ProducePartitionResponse{
+				Index:           d.Int32(),
+				ErrorCode:       d.Int16(),
+				BaseOffset:      d.Int64(),
+				LogAppendTimeMs: d.Int64(),
+			})
+		}
+		r.Topics = append(r.Topics, t)
+	}
+	r.ThrottleTimeMs = d.Int32()
+}
@@ -0,0 +1,340 @@
+// Package protocol implements the parts of the Kafka wire protocol used by the
+// producer and the fake broker: the request and response framing, the
+// Metadata and Produce messages, and version 2 record batches.
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// API keys.
+const (
+	APIKeyProduce  int16 = 0
+	APIKeyMetadata int16 = 3
+)
+
+// API versions. These are the last versions before the flexible encoding, and
+// are supported by brokers since Kafka 1.0.
+const (
+	ProduceVersion  int16 = 3
+	MetadataVersion int16 = 4
+)
+
+// MaxFrameSize is the largest request or response that is read.
+const MaxFrameSize = 100 << 20
+
+// ErrMalformed is returned when a message cannot be decoded.
+var ErrMalformed = errors.New("malformed kafka message")
+
+// Encoder appends values in the Kafka encoding.
+type Encoder struct {
+	buf []byte
+}
+
+// Data returns the encoded bytes.
+func (e *Encoder) Data() []byte {
+	return e.buf
+}
+
+// Int8 appends an int8.
+func (e *Encoder) Int8(v int8) {
+	e.buf = append(e.buf, byte(v))
+}
+
+// Int16 appends a big endian int16.
+func (e *Encoder) Int16(v int16) {
+	e.buf = append(e.buf, byte(v>>8), byte(v))
+}
+
+// Int32 appends a big endian int32.
+func (e *Encoder) Int32(v int32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+// Int64 appends a big endian int64.
+func (e *Encoder) Int64(v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+// Bool appends a boolean.
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.Int8(1)
+	} else {
+		e.Int8(0)
+	}
+}
+
+// String appends a string with an int16 length.
+func (e *Encoder) String(s string) {
+	e.Int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// NullableString appends a string with an int16 length, -1 if nil.
+func (e *Encoder) NullableString(s *string) {
+	if s == nil {
+		e.Int16(-1)
+		return
+	}
+	e.String(*s)
+}
+
+// Bytes appends bytes with an int32 length, -1 if nil.
+func (e *Encoder) Bytes(b []byte) {
+	if b == nil {
+		e.Int32(-1)
+		return
+	}
+	e.Int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// ArrayLen appends the int32 length of an array.
+func (e *Encoder) ArrayLen(n int) {
+	e.Int32(int32(n))
+}
+
+// Int32Array appends an array of int32.
+func (e *Encoder) Int32Array(values []int32) {
+	e.ArrayLen(len(values))
+	for _, v := range values {
+		e.Int32(v)
+	}
+}
+
+// Varint appends a zigzag encoded variable length integer.
+func (e *Encoder) Varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], v)
+	e.buf = append(e.buf, b[:n]...)
+}
+
+// VarBytes appends bytes with a varint length, -1 if nil.
+func (e *Encoder) VarBytes(b []byte) {
+	if b == nil {
+		e.Varint(-1)
+		return
+	}
+	e.Varint(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// Decoder reads values in the Kafka encoding. The first error is kept and
+// all the following reads return zero values.
+type Decoder struct {
+	buf []byte
+	err error
+}
+
+// MakeDecoder creates a Decoder of `buf`.
+func MakeDecoder(buf []byte) Decoder {
+	return Decoder{buf: buf}
+}
+
+// Err returns the first decoding error.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Remaining returns the number of bytes not read yet.
+func (d *Decoder) Remaining() int {
+	return len(d.buf)
+}
+
+func (d *Decoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: %s", ErrMalformed, fmt.Sprintf(format, args...))
+	}
+	d.buf = nil
+}
+
+func (d *Decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.fail("need %d bytes, %d left", n, len(d.buf))
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+// Int8 reads an int8.
+func (d *Decoder) Int8() int8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+// Int16 reads a big endian int16.
+func (d *Decoder) Int16() int16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+// Int32 reads a big endian int32.
+func (d *Decoder) Int32() int32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+// Int64 reads a big endian int64.
+func (d *Decoder) Int64() int64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+// Bool reads a boolean.
+func (d *Decoder) Bool() bool {
+	return d.Int8() != 0
+}
+
+// String reads a string with an int16 length.
+func (d *Decoder) String() string {
+	n := d.Int16()
+	return string(d.next(int(n)))
+}
+
+// NullableString reads a string with an int16 length, nil if -1.
+func (d *Decoder) NullableString() *string {
+	n := d.Int16()
+	if n == -1 {
+		return nil
+	}
+	s := string(d.next(int(n)))
+	return &s
+}
+
+// Bytes reads bytes with an int32 length, nil if -1.
+func (d *Decoder) Bytes() []byte {
+	n := d.Int32()
+	if n == -1 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// ArrayLen reads the int32 length of an array. An array cannot have more
+// elements than the remaining bytes, so that a corrupt length does not make
+// the caller allocate.
+func (d *Decoder) ArrayLen() int {
+	n := d.Int32()
+	if n == -1 {
+		return 0
+	}
+	if n < 0 || int(n) > len(d.buf) {
+		d.fail("bad array length %d", n)
+		return 0
+	}
+	return int(n)
+}
+
+// Int32Array reads an array of int32.
+func (d *Decoder) Int32Array() []int32 {
+	n := d.ArrayLen()
+	res := make([]int32, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		res = append(res, d.Int32())
+	}
+	return res
+}
+
+// Varint reads a zigzag encoded variable length integer.
+func (d *Decoder) Varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail("bad varint")
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// VarBytes reads bytes with a varint length, nil if -1.
+func (d *Decoder) VarBytes() []byte {
+	n := d.Varint()
+	if n == -1 {
+		return nil
+	}
+	if n < 0 || n > math.MaxInt32 {
+		d.fail("bad length %d", n)
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// RequestHeader is the version 1 request header.
+type RequestHeader struct {
+	APIKey        int16
+	APIVersion    int16
+	CorrelationID int32
+	ClientID      string
+}
+
+// Encode is the encoder of RequestHeader.
+func (h *RequestHeader) Encode(e *Encoder) {
+	e.Int16(h.APIKey)
+	e.Int16(h.APIVersion)
+	e.Int32(h.CorrelationID)
+	e.String(h.ClientID)
+}
+
+// Decode is the decoder of RequestHeader.
+func (h *RequestHeader) Decode(d *Decoder) {
+	h.APIKey = d.Int16()
+	h.APIVersion = d.Int16()
+	h.CorrelationID = d.Int32()
+	h.ClientID = d.String()
+}
+
+// WriteFrame writes `data` prefixed by its int32 size.
+func WriteFrame(w io.Writer, data []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	_, err := w.Write(append(size[:], data...))
+	return err
+}
+
+// ReadFrame reads data prefixed by its int32 size.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	_, err := io.ReadFull(r, size[:])
+	if err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > MaxFrameSize {
+		return nil, fmt.Errorf("%w: frame of %d bytes", ErrMalformed, n)
+	}
+	data := make([]byte, n)
+	_, err = io.ReadFull(r, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,145 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Header is a record header.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Record is a record of a record batch.
+type Record struct {
+	Key     []byte
+	Value   []byte
+	Headers []Header
+	// Timestamp is the create time in milliseconds since the epoch.
+	Timestamp int64
+}
+
+const (
+	// batchMagic is the version of the record batch format.
+	batchMagic = 2
+
+	// The offset of the fields of the record batch header.
+	batchLengthOffset = 8
+	batchMagicOffset  = 16
+	batchCRCOffset    = 17
+	batchAttrsOffset  = 21
+	batchHeaderSize   = 61
+)
+
+// EncodeRecordBatch encodes records as an uncompressed record batch with
+// base offset 0.
+func EncodeRecordBatch(records []Record) []byte {
+	var first, max int64
+	for i, r := range records {
+		if i == 0 || r.Timestamp < first {
+			first = r.Timestamp
+		}
+		if i == 0 || r.Timestamp > max {
+			max = r.Timestamp
+		}
+	}
+
+	var e Encoder
+	e.Int64(0)  // base offset
+	e.Int32(0)  // length, set below
+	e.Int32(-1) // partition leader epoch
+	e.Int8(batchMagic)
+	e.Int32(0) // crc, set below
+	e.Int16(0) // attributes: no compression, create time
+	e.Int32(int32(len(records) - 1))
+	e.Int64(first)
+	e.Int64(max)
+	e.Int64(-1) // producer id
+	e.Int16(-1) // producer epoch
+	e.Int32(-1) // base sequence
+	e.ArrayLen(len(records))
+	for i, r := range records {
+		var body Encoder
+		body.Int8(0) // attributes
+		body.Varint(r.Timestamp - first)
+		body.Varint(int64(i))
+		body.VarBytes(r.Key)
+		body.VarBytes(r.Value)
+		body.Varint(int64(len(r.Headers)))
+		for _, h := range r.Headers {
+			body.VarBytes([]byte(h.Key))
+			body.VarBytes(h.Value)
+		}
+		e.Varint(int64(len(body.Data())))
+		e.buf = append(e.buf, body.Data()...)
+	}
+
+	buf := e.Data()
+	binary.BigEndian.PutUint32(buf[batchLengthOffset:], uint32(len(buf)-batchLengthOffset-4))
+	binary.BigEndian.PutUint32(buf[batchCRCOffset:], crc32.Checksum(buf[batchAttrsOffset:], castagnoli))
+	return buf
+}
+
+// DecodeRecordBatches decodes the uncompressed record batches of `data`.
+func DecodeRecordBatches(data []byte) ([]Record, error) {
+	var records []Record
+	for len(data) > 0 {
+		if len(data) < batchHeaderSize {
+			return nil, fmt.Errorf("%w: truncated record batch", ErrMalformed)
+		}
+		length := int(binary.BigEndian.Uint32(data[batchLengthOffset:]))
+		if length < batchHeaderSize-batchLengthOffset-4 || length > len(data)-batchLengthOffset-4 {
+			return nil, fmt.Errorf("%w: bad record batch length %d", ErrMalformed, length)
+		}
+		batch := data[:batchLengthOffset+4+length]
+		data = data[len(batch):]
+
+		if batch[batchMagicOffset] != batchMagic {
+			return nil, fmt.Errorf("%w: unsupported record batch version %d", ErrMalformed, batch[batchMagicOffset])
+		}
+		crc := binary.BigEndian.Uint32(batch[batchCRCOffset:])
+		if crc32.Checksum(batch[batchAttrsOffset:], castagnoli) != crc {
+			return nil, fmt.Errorf("%w: record batch checksum mismatch", ErrMalformed)
+		}
+
+		d := MakeDecoder(batch[batchAttrsOffset:])
+		attributes := d.Int16()
+		if attributes&0x7 != 0 {
+			return nil, fmt.Errorf("%w: compressed record batches are not supported", ErrMalformed)
+		}
+		d.Int32() // last offset delta
+		first := d.Int64()
+		d.Int64() // max timestamp
+		d.Int64() // producer id
+		d.Int16() // producer epoch
+		d.Int32() // base sequence
+		n := d.ArrayLen()
+		for i := 0; i < n && d.Err() == nil; i++ {
+			rd := MakeDecoder(d.VarBytes())
+			rd.Int8() // attributes
+			r := Record{Timestamp: first + rd.Varint()}
+			rd.Varint() // offset delta
+			r.Key = rd.VarBytes()
+			r.Value = rd.VarBytes()
+			headers := rd.Varint()
+			if headers < 0 || headers > int64(rd.Remaining()) {
+				return nil, fmt.Errorf("%w: bad header count %d", ErrMalformed, headers)
+			}
+			for j := int64(0); j < headers; j++ {
+				r.Headers = append(r.Headers, Header{Key: string(rd.VarBytes()), Value: rd.VarBytes()})
+			}
+			if rd.Err() != nil {
+				return nil, rd.Err()
+			}
+			records = append(records, r)
+		}
+		if d.Err() != nil {
+			return nil, d.Err()
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,55 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordBatchRoundTrip(t *testing.T) {
+	records := []Record{
+		{Key: []byte("a"), Value: []byte("1"), Timestamp: 1000},
+		{Key: nil, Value: []byte{}, Timestamp: 999, Headers: []Header{{Key: "h", Value: []byte("v")}, {Key: "n"}}},
+		{Key: []byte("c"), Value: nil, Timestamp: 5000},
+	}
+	data := EncodeRecordBatch(records)
+	data = append(data, EncodeRecordBatch(records[:1])...)
+
+	decoded, err := DecodeRecordBatches(data)
+	require.NoError(t, err)
+	assert.Equal(t, append(records, records[0]), decoded)
+}
+
+func TestRecordBatchChecksum(t *testing.T) {
+	data := EncodeRecordBatch([]Record{{Key: []byte("a"), Value: []byte("1")}})
+	data[len(data)-1] ^= 1
+	_, err := DecodeRecordBatches(data)
+	assert.True(t, errors.Is(err, ErrMalformed), "%v", err)
+
+	_, err = DecodeRecordBatches(data[:20])
+	assert.True(t, errors.Is(err, ErrMalformed), "%v", err)
+}
+
+func TestProduceRequestRoundTrip(t *testing.T) {
+	id := "tx"
+	req := ProduceRequest{
+		TransactionalID: &id,
+		Acks:            -1,
+		TimeoutMs:       100,
+		Topics: []ProduceTopic{{
+			Name:       "t",
+			Partitions: []ProducePartition{{Index: 3, Records: EncodeRecordBatch([]Record{{Value: []byte("x")}})}},
+		}},
+	}
+	var e Encoder
+	req.Encode(&e)
+
+	var decoded ProduceRequest
+	d := MakeDecoder(e.Data())
+	decoded.Decode(&d)
+	require.NoError(t, d.Err())
+	assert.Equal(t, 0, d.Remaining())
+	assert.Equal(t, req, decoded)
+}
@@ -0,0 +1,233 @@
+// Package kafkatest provides an in-process fake Kafka broker, so that the
+// producer can be tested without a network or a Kafka cluster.
+package kafkatest
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/algorand/indexer/kafka"
+	"github.com/algorand/indexer/kafka/internal/protocol"
+)
+
+// nodeID is the id of the broker in the metadata.
+const nodeID = 1
+
+// Broker is a single node cluster that answers the Metadata and Produce
+// requests of the producer and keeps the produced messages in memory.
+type Broker struct {
+	listener net.Listener
+
+	mu         sync.Mutex
+	partitions map[string]int
+	messages   map[string][][]kafka.Message
+	failures   []kafka.Error
+	conns      map[net.Conn]bool
+	wg         sync.WaitGroup
+}
+
+// NewBroker starts a broker on a local port.
+func NewBroker() (*Broker, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("NewBroker() err: %w", err)
+	}
+	b := &Broker{
+		listener:   listener,
+		partitions: make(map[string]int),
+		messages:   make(map[string][][]kafka.Message),
+		conns:      make(map[net.Conn]bool),
+	}
+	b.wg.Add(1)
+	go b.accept()
+	return b, nil
+}
+
+// Addr returns the host:port of the broker.
+func (b *Broker) Addr() string {
+	return b.listener.Addr().String()
+}
+
+// CreateTopic creates a topic with `partitions` partitions.
+func (b *Broker) CreateTopic(topic string, partitions int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.partitions[topic] = partitions
+	b.messages[topic] = make([][]kafka.Message, partitions)
+}
+
+// FailProduce makes the broker answer the next produced partitions with
+// the given errors, one error per partition, without writing their messages.
+func (b *Broker) FailProduce(errs ...kafka.Error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = append(b.failures, errs...)
+}
+
+// Messages returns the messages written to a partition of a topic.
+func (b *Broker) Messages(topic string, partition int) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if partition >= len(b.messages[topic]) {
+		return nil
+	}
+	return append([]kafka.Message(nil), b.messages[topic][partition]...)
+}
+
+// DropConnections closes the open client connections, like a broker restart.
+func (b *Broker) DropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.conns {
+		conn.Close()
+	}
+}
+
+// Close stops the broker.
+func (b *Broker) Close() {
+	b.listener.Close()
+	b.DropConnections()
+	b.wg.Wait()
+}
+
+func (b *Broker) accept() {
+	defer b.wg.Done()
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns[conn] = true
+		b.mu.Unlock()
+		b.wg.Add(1)
+		go b.serve(conn)
+	}
+}
+
+func (b *Broker) serve(conn net.Conn) {
+	defer b.wg.Done()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, conn)
+		b.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		data, err := protocol.ReadFrame(conn)
+		if err != nil {
+			return
+		}
+		d := protocol.MakeDecoder(data)
+		var header protocol.RequestHeader
+		header.Decode(&d)
+
+		var e protocol.Encoder
+		e.Int32(header.CorrelationID)
+		switch {
+		case header.APIKey == protocol.APIKeyMetadata && header.APIVersion == protocol.MetadataVersion:
+			var req protocol.MetadataRequest
+			req.Decode(&d)
+			if d.Err() != nil {
+				return
+			}
+			resp := b.metadata(&req)
+			resp.Encode(&e)
+		case header.APIKey == protocol.APIKeyProduce && header.APIVersion == protocol.ProduceVersion:
+			var req protocol.ProduceRequest
+			req.Decode(&d)
+			if d.Err() != nil {
+				return
+			}
+			resp := b.produce(&req)
+			resp.Encode(&e)
+		default:
+			// Brokers close the connection on unsupported requests.
+			return
+		}
+
+		err = protocol.WriteFrame(conn, e.Data())
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (b *Broker) metadata(req *protocol.MetadataRequest) protocol.MetadataResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	host, portStr, _ := net.SplitHostPort(b.Addr())
+	port, _ := strconv.Atoi(portStr)
+	resp := protocol.MetadataResponse{
+		Brokers:      []protocol.MetadataBroker{{NodeID: nodeID, Host: host, Port: int32(port)}},
+		ControllerID: nodeID,
+	}
+	for _, topic := range req.Topics {
+		partitions, ok := b.partitions[topic]
+		t := protocol.MetadataTopic{Name: topic}
+		if !ok {
+			t.ErrorCode = int16(kafka.ErrUnknownTopicOrPartition)
+		}
+		for i := 0; i < partitions; i++ {
+			t.Partitions = append(t.Partitions, protocol.MetadataPartition{
+				Index:    int32(i),
+				Leader:   nodeID,
+				Replicas: []int32{nodeID},
+				Isr:      []int32{nodeID},
+			})
+		}
+		resp.Topics = append(resp.Topics, t)
+	}
+	return resp
+}
+
+func (b *Broker) produce(req *protocol.ProduceRequest) protocol.ProduceResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var resp protocol.ProduceResponse
+	for _, t := range req.Topics {
+		tr := protocol.ProduceTopicResponse{Name: t.Name}
+		for _, p := range t.Partitions {
+			pr := protocol.ProducePartitionResponse{Index: p.Index, LogAppendTimeMs: -1}
+			tr.Partitions = append(tr.Partitions, pr)
+			last := &tr.Partitions[len(tr.Partitions)-1]
+
+			if len(b.failures) > 0 {
+				last.ErrorCode = int16(b.failures[0])
+				b.failures = b.failures[1:]
+				continue
+			}
+			if int(p.Index) >= len(b.messages[t.Name]) || p.Index < 0 {
+				last.ErrorCode = int16(kafka.ErrUnknownTopicOrPartition)
+				continue
+			}
+			records, err := protocol.DecodeRecordBatches(p.Records)
+			if err != nil {
+				last.ErrorCode = int16(kafka.ErrCorruptMessage)
+				continue
+			}
+
+			last.BaseOffset = int64(len(b.messages[t.Name][p.Index]))
+			for _, r := range records {
+				msg := kafka.Message{
+					Partition: p.Index,
+					Key:       r.Key,
+					Value:     r.Value,
+					Time:      time.Unix(0, r.Timestamp*int64(time.Millisecond)),
+				}
+				for _, h := range r.Headers {
+					msg.Headers = append(msg.Headers, kafka.Header{Key: h.Key, Value: h.Value})
+				}
+				b.messages[t.Name][p.Index] = append(b.messages[t.Name][p.Index], msg)
+			}
+		}
+		resp.Topics = append(resp.Topics, tr)
+	}
+	return resp
+}
@@ -0,0 +1,31 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMurmur2 checks the hash against the values of the Java client tests.
+func TestMurmur2(t *testing.T) {
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for key, expected := range cases {
+		assert.Equal(t, expected, int32(murmur2([]byte(key))), key)
+	}
+}
+
+func TestPartitionForKey(t *testing.T) {
+	for _, key := range []string{"", "21", "foobar", "abc"} {
+		partition := PartitionForKey([]byte(key), 7)
+		assert.True(t, partition >= 0 && partition < 7, key)
+		assert.Equal(t, partition, PartitionForKey([]byte(key), 7))
+	}
+	assert.Equal(t, int32(0), PartitionForKey([]byte("abc"), 1))
+}
@@ -0,0 +1,435 @@
+// Package kafka is a minimal producer for the Kafka protocol. It supports
+// plaintext connections, uncompressed record batches and acknowledgement by
+// all in-sync replicas, which is what the event stream of the daemon needs,
+// and works with brokers since Kafka 1.0 and protocol compatible services.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/algorand/indexer/kafka/internal/protocol"
+)
+
+// PartitionByKey as the partition of a message picks the partition from the
+// message key.
+const PartitionByKey int32 = -1
+
+// Defaults of ProducerOptions.
+const (
+	DefaultTimeout       = 30 * time.Second
+	DefaultBackoff       = 100 * time.Millisecond
+	DefaultMaxBackoff    = 10 * time.Second
+	DefaultMaxBatchBytes = 900 * 1024
+)
+
+// acksAll makes the broker acknowledge a write once all in-sync replicas
+// have it.
+const acksAll = -1
+
+// Header is a message header.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is a message to produce.
+type Message struct {
+	// Partition is the partition of the message, or PartitionByKey.
+	Partition int32
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	// Time is the create time of the message, the send time if zero.
+	Time time.Time
+}
+
+// ProducerOptions configure a Producer.
+type ProducerOptions struct {
+	// Brokers are the host:port addresses used to discover the cluster.
+	Brokers []string
+
+	// Topic is the topic messages are produced to.
+	Topic string
+
+	// ClientID identifies the producer in the broker logs.
+	ClientID string
+
+	// Timeout is the timeout of each request, DefaultTimeout if 0.
+	Timeout time.Duration
+
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries int
+
+	// Backoff is the wait before the first retry, DefaultBackoff if 0. It
+	// doubles with every retry up to MaxBackoff, DefaultMaxBackoff if 0.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// MaxBatchBytes is the approximate size limit of the messages sent to a
+	// partition in one request, DefaultMaxBatchBytes if 0. It must be below
+	// the max.message.bytes of the topic.
+	MaxBatchBytes int
+}
+
+// Producer sends messages to the partitions of a topic. Messages of a
+// partition are written in the order they are sent, a message that is retried
+// may be written twice. A Producer is not safe for concurrent use.
+type Producer struct {
+	opts ProducerOptions
+
+	correlationID int32
+	conns         map[string]net.Conn
+
+	// The cluster metadata, leaders is nil until it is fetched.
+	brokers map[int32]string
+	leaders []int32
+}
+
+// MakeProducer creates a Producer. It connects to the brokers on first use.
+func MakeProducer(opts ProducerOptions) (*Producer, error) {
+	if len(opts.Brokers) == 0 {
+		return nil, fmt.Errorf("MakeProducer() no brokers")
+	}
+	if opts.Topic == "" {
+		return nil, fmt.Errorf("MakeProducer() no topic")
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.MaxBatchBytes == 0 {
+		opts.MaxBatchBytes = DefaultMaxBatchBytes
+	}
+	return &Producer{opts: opts, conns: make(map[string]net.Conn)}, nil
+}
+
+// retriable returns whether an error is transient: a broker error that is
+// retriable, or a failed connection.
+func retriable(err error) bool {
+	var kerr Error
+	if errors.As(err, &kerr) {
+		return kerr.Retriable()
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retry calls `f` until it succeeds, fails with an error that is not
+// transient, or fails MaxRetries+1 times. The metadata is fetched again
+// before each retry, since most errors come from a change of leader.
+func (p *Producer) retry(ctx context.Context, f func() error) error {
+	backoff := p.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || attempt >= p.opts.MaxRetries || !retriable(err) {
+			return err
+		}
+
+		p.leaders = nil
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+		if backoff > p.opts.MaxBackoff {
+			backoff = p.opts.MaxBackoff
+		}
+	}
+}
+
+// connect returns the connection to a broker, opening it if needed.
+func (p *Producer) connect(ctx context.Context, addr string) (net.Conn, error) {
+	if conn, ok := p.conns[addr]; ok {
+		return conn, nil
+	}
+	dialer := net.Dialer{Timeout: p.opts.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[addr] = conn
+	return conn, nil
+}
+
+func (p *Producer) disconnect(addr string) {
+	if conn, ok := p.conns[addr]; ok {
+		conn.Close()
+		delete(p.conns, addr)
+	}
+}
+
+// roundTrip sends a request to a broker and decodes its response.
+func (p *Producer) roundTrip(ctx context.Context, addr string, apiKey, apiVersion int16, req func(e *protocol.Encoder), resp func(d *protocol.Decoder)) error {
+	conn, err := p.connect(ctx, addr)
+	if err != nil {
+		return err
+	}
+
+	p.correlationID++
+	header := protocol.RequestHeader{
+		APIKey:        apiKey,
+		APIVersion:    apiVersion,
+		CorrelationID: p.correlationID,
+		ClientID:      p.opts.ClientID,
+	}
+	var e protocol.Encoder
+	header.Encode(&e)
+	req(&e)
+
+	deadline := time.Now().Add(p.opts.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	err = protocol.WriteFrame(conn, e.Data())
+	var data []byte
+	if err == nil {
+		data, err = protocol.ReadFrame(conn)
+	}
+	if err != nil {
+		p.disconnect(addr)
+		return err
+	}
+
+	d := protocol.MakeDecoder(data)
+	if correlationID := d.Int32(); correlationID != header.CorrelationID {
+		p.disconnect(addr)
+		return fmt.Errorf("%w: response %d to request %d", protocol.ErrMalformed, correlationID, header.CorrelationID)
+	}
+	resp(&d)
+	if d.Err() != nil {
+		p.disconnect(addr)
+		return d.Err()
+	}
+	return nil
+}
+
+// fetchMetadata gets the partition leaders of the topic from the first broker
+// that answers.
+func (p *Producer) fetchMetadata(ctx context.Context) error {
+	addrs := append([]string(nil), p.opts.Brokers...)
+	for _, addr := range p.brokers {
+		addrs = append(addrs, addr)
+	}
+
+	var err error
+	for _, addr := range addrs {
+		req := protocol.MetadataRequest{Topics: []string{p.opts.Topic}}
+		var resp protocol.MetadataResponse
+		err = p.roundTrip(ctx, addr, protocol.APIKeyMetadata, protocol.MetadataVersion, req.Encode, resp.Decode)
+		if err == nil {
+			return p.setMetadata(&resp)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return fmt.Errorf("fetchMetadata() err: %w", err)
+}
+
+func (p *Producer) setMetadata(resp *protocol.MetadataResponse) error {
+	brokers := make(map[int32]string)
+	for _, b := range resp.Brokers {
+		brokers[b.NodeID] = net.JoinHostPort(b.Host, fmt.Sprint(b.Port))
+	}
+
+	for _, t := range resp.Topics {
+		if t.Name != p.opts.Topic {
+			continue
+		}
+		if t.ErrorCode != 0 {
+			return fmt.Errorf("setMetadata() topic %s err: %w", t.Name, Error(t.ErrorCode))
+		}
+		if len(t.Partitions) == 0 {
+			return fmt.Errorf("setMetadata() topic %s has no partitions: %w", t.Name, ErrLeaderNotAvailable)
+		}
+
+		partitions := append([]protocol.MetadataPartition(nil), t.Partitions...)
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i].Index < partitions[j].Index })
+		leaders := make([]int32, len(partitions))
+		for i, partition := range partitions {
+			if partition.Index != int32(i) {
+				return fmt.Errorf("setMetadata() topic %s is missing partition %d", t.Name, i)
+			}
+			if _, ok := brokers[partition.Leader]; !ok {
+				return fmt.Errorf("setMetadata() partition %d err: %w", i, ErrLeaderNotAvailable)
+			}
+			leaders[i] = partition.Leader
+		}
+
+		p.brokers = brokers
+		p.leaders = leaders
+		return nil
+	}
+	return fmt.Errorf("setMetadata() topic %s err: %w", p.opts.Topic, ErrUnknownTopicOrPartition)
+}
+
+// Partitions returns the number of partitions of the topic.
+func (p *Producer) Partitions(ctx context.Context) (int, error) {
+	err := p.retry(ctx, func() error {
+		if p.leaders != nil {
+			return nil
+		}
+		return p.fetchMetadata(ctx)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Partitions() err: %w", err)
+	}
+	return len(p.leaders), nil
+}
+
+// recordSize is the approximate encoded size of a record.
+func recordSize(r *protocol.Record) int {
+	size := len(r.Key) + len(r.Value) + 24
+	for _, h := range r.Headers {
+		size += len(h.Key) + len(h.Value) + 10
+	}
+	return size
+}
+
+// produce sends the queued records of each partition to the partition leaders
+// until all are written, removing the written records from `queues`.
+func (p *Producer) produce(ctx context.Context, queues map[int32][]protocol.Record) error {
+	for len(queues) > 0 {
+		if p.leaders == nil {
+			err := p.fetchMetadata(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		// One request per leader, with the next batch of each of its
+		// partitions.
+		requests := make(map[int32]*protocol.ProduceRequest)
+		sent := make(map[int32]int)
+		for partition, records := range queues {
+			if int(partition) >= len(p.leaders) {
+				return fmt.Errorf("produce() partition %d of topic %s err: %w", partition, p.opts.Topic, ErrUnknownTopicOrPartition)
+			}
+			size := 0
+			n := 0
+			for n < len(records) && (n == 0 || size+recordSize(&records[n]) <= p.opts.MaxBatchBytes) {
+				size += recordSize(&records[n])
+				n++
+			}
+			sent[partition] = n
+
+			leader := p.leaders[partition]
+			req, ok := requests[leader]
+			if !ok {
+				req = &protocol.ProduceRequest{
+					Acks:      acksAll,
+					TimeoutMs: int32(p.opts.Timeout / time.Millisecond),
+					Topics:    []protocol.ProduceTopic{{Name: p.opts.Topic}},
+				}
+				requests[leader] = req
+			}
+			req.Topics[0].Partitions = append(req.Topics[0].Partitions, protocol.ProducePartition{
+				Index:   partition,
+				Records: protocol.EncodeRecordBatch(records[:n]),
+			})
+		}
+
+		var firstErr error
+		answered := make(map[int32]bool)
+		for leader, req := range requests {
+			var resp protocol.ProduceResponse
+			err := p.roundTrip(ctx, p.brokers[leader], protocol.APIKeyProduce, protocol.ProduceVersion, req.Encode, resp.Decode)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			for _, t := range resp.Topics {
+				for _, partition := range t.Partitions {
+					n, ok := sent[partition.Index]
+					if t.Name != p.opts.Topic || !ok {
+						continue
+					}
+					answered[partition.Index] = true
+					if partition.ErrorCode != 0 {
+						if firstErr == nil {
+							firstErr = fmt.Errorf("produce() partition %d err: %w", partition.Index, Error(partition.ErrorCode))
+						}
+						continue
+					}
+					queues[partition.Index] = queues[partition.Index][n:]
+					if len(queues[partition.Index]) == 0 {
+						delete(queues, partition.Index)
+					}
+				}
+			}
+		}
+		if firstErr != nil {
+			return firstErr
+		}
+		for partition := range sent {
+			if !answered[partition] {
+				return fmt.Errorf("produce() %w: no response for partition %d", protocol.ErrMalformed, partition)
+			}
+		}
+	}
+	return nil
+}
+
+// Send writes messages to the topic, retrying with backoff on transient
+// errors. It returns once all the messages are acknowledged by the in-sync
+// replicas of their partition.
+func (p *Producer) Send(ctx context.Context, msgs []Message) error {
+	partitions, err := p.Partitions(ctx)
+	if err != nil {
+		return fmt.Errorf("Send() err: %w", err)
+	}
+
+	now := time.Now()
+	queues := make(map[int32][]protocol.Record)
+	for _, msg := range msgs {
+		partition := msg.Partition
+		if partition == PartitionByKey {
+			partition = PartitionForKey(msg.Key, partitions)
+		}
+		t := msg.Time
+		if t.IsZero() {
+			t = now
+		}
+		record := protocol.Record{
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Timestamp: t.UnixNano() / int64(time.Millisecond),
+		}
+		for _, h := range msg.Headers {
+			record.Headers = append(record.Headers, protocol.Header{Key: h.Key, Value: h.Value})
+		}
+		queues[partition] = append(queues[partition], record)
+	}
+
+	err = p.retry(ctx, func() error { return p.produce(ctx, queues) })
+	if err != nil {
+		return fmt.Errorf("Send() err: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connections to the brokers.
+func (p *Producer) Close() {
+	for addr := range p.conns {
+		p.disconnect(addr)
+	}
+}
@@ -0,0 +1,149 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/kafka"
+	"github.com/algorand/indexer/kafka/kafkatest"
+)
+
+func makeBroker(t *testing.T, partitions int) *kafkatest.Broker {
+	broker, err := kafkatest.NewBroker()
+	require.NoError(t, err)
+	t.Cleanup(broker.Close)
+	broker.CreateTopic("events", partitions)
+	return broker
+}
+
+func makeProducer(t *testing.T, broker *kafkatest.Broker, retries int) *kafka.Producer {
+	producer, err := kafka.MakeProducer(kafka.ProducerOptions{
+		Brokers:    []string{broker.Addr()},
+		Topic:      "events",
+		ClientID:   "test",
+		Timeout:    5 * time.Second,
+		MaxRetries: retries,
+		Backoff:    time.Millisecond,
+	})
+	require.NoError(t, err)
+	t.Cleanup(producer.Close)
+	return producer
+}
+
+func TestProducerPartitionsByKey(t *testing.T) {
+	broker := makeBroker(t, 3)
+	producer := makeProducer(t, broker, 0)
+
+	partitions, err := producer.Partitions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, partitions)
+
+	var msgs []kafka.Message
+	for i := 0; i < 20; i++ {
+		msgs = append(msgs, kafka.Message{
+			Partition: kafka.PartitionByKey,
+			Key:       []byte(fmt.Sprintf("key%d", i%4)),
+			Value:     []byte(fmt.Sprint(i)),
+			Headers:   []kafka.Header{{Key: "n", Value: []byte(fmt.Sprint(i))}},
+			Time:      time.Unix(1600000000, 0),
+		})
+	}
+	msgs = append(msgs, kafka.Message{Partition: 2, Value: []byte("marker")})
+	require.NoError(t, producer.Send(context.Background(), msgs))
+
+	total := 0
+	for partition := 0; partition < 3; partition++ {
+		last := make(map[string]int)
+		for _, msg := range broker.Messages("events", partition) {
+			if msg.Key == nil {
+				assert.Equal(t, 2, partition)
+				assert.Equal(t, "marker", string(msg.Value))
+				total++
+				continue
+			}
+			// Same key, same partition, in send order.
+			key := string(msg.Key)
+			assert.Equal(t, kafka.PartitionForKey(msg.Key, 3), int32(partition))
+			var i int
+			fmt.Sscan(string(msg.Value), &i)
+			if prev, ok := last[key]; ok {
+				assert.Greater(t, i, prev)
+			}
+			last[key] = i
+			assert.Equal(t, []kafka.Header{{Key: "n", Value: msg.Value}}, msg.Headers)
+			assert.Equal(t, int64(1600000000), msg.Time.Unix())
+			total++
+		}
+	}
+	assert.Equal(t, 21, total)
+}
+
+func TestProducerRetries(t *testing.T) {
+	broker := makeBroker(t, 1)
+	producer := makeProducer(t, broker, 3)
+
+	broker.FailProduce(kafka.ErrNotLeaderForPartition, kafka.ErrRequestTimedOut)
+	msg := kafka.Message{Partition: kafka.PartitionByKey, Key: []byte("a"), Value: []byte("1")}
+	require.NoError(t, producer.Send(context.Background(), []kafka.Message{msg}))
+	assert.Len(t, broker.Messages("events", 0), 1)
+
+	// Reconnects after the broker drops the connection.
+	broker.DropConnections()
+	require.NoError(t, producer.Send(context.Background(), []kafka.Message{msg}))
+	assert.Len(t, broker.Messages("events", 0), 2)
+
+	// Gives up after MaxRetries.
+	broker.FailProduce(kafka.ErrNotLeaderForPartition, kafka.ErrNotLeaderForPartition,
+		kafka.ErrNotLeaderForPartition, kafka.ErrNotLeaderForPartition)
+	err := producer.Send(context.Background(), []kafka.Message{msg})
+	assert.True(t, errors.Is(err, kafka.ErrNotLeaderForPartition), "%v", err)
+	assert.Len(t, broker.Messages("events", 0), 2)
+}
+
+func TestProducerErrorNotRetriable(t *testing.T) {
+	broker := makeBroker(t, 1)
+	producer := makeProducer(t, broker, 3)
+
+	broker.FailProduce(kafka.ErrMessageTooLarge)
+	err := producer.Send(context.Background(), []kafka.Message{{Value: []byte("1")}})
+	assert.True(t, errors.Is(err, kafka.ErrMessageTooLarge), "%v", err)
+	assert.Empty(t, broker.Messages("events", 0))
+}
+
+func TestProducerUnknownTopic(t *testing.T) {
+	broker := makeBroker(t, 1)
+	producer, err := kafka.MakeProducer(kafka.ProducerOptions{
+		Brokers: []string{broker.Addr()},
+		Topic:   "missing",
+		Backoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	_, err = producer.Partitions(context.Background())
+	assert.True(t, errors.Is(err, kafka.ErrUnknownTopicOrPartition), "%v", err)
+}
+
+func TestProducerSplitsBatches(t *testing.T) {
+	broker := makeBroker(t, 1)
+	producer, err := kafka.MakeProducer(kafka.ProducerOptions{
+		Brokers:       []string{broker.Addr()},
+		Topic:         "events",
+		MaxBatchBytes: 100,
+	})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	var msgs []kafka.Message
+	for i := 0; i < 10; i++ {
+		msgs = append(msgs, kafka.Message{Value: make([]byte, 60)})
+	}
+	require.NoError(t, producer.Send(context.Background(), msgs))
+	assert.Len(t, broker.Messages("events", 0), 10)
+}
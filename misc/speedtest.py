@@ -213,7 +213,7 @@ def assetTxns(rooturl, assets, n=1000, minTime=None, maxTime=10, ntxns=1000):
 # /v2/assets/{asset-id}/balances -- maybe add index to account_asset table?
 #
 # To make fast:
-# CREATE INDEX CONCURRENTLY IF NOT EXISTS account_asset_asset ON account_asset (assetid, addr ASC);
+# CREATE INDEX CONCURRENTLY IF NOT EXISTS account_asset_asset ON account_asset (assetid, addr_id ASC);
 def assetBalances(rooturl, assets, n=1000, minTime=None, maxTime=10, ntxns=1000):
     rootparts = urllib.parse.urlparse(rooturl)
     rawurl = list(rootparts)
@@ -0,0 +1,201 @@
+// Package parquet implements the subset of the Apache Parquet format needed to
+// write and read flat tables: required and optional columns of primitive
+// types, PLAIN encoded data pages, and the "UNCOMPRESSED" and "GZIP" codecs.
+//
+// Values are represented generically:
+//
+//	Boolean   -> bool
+//	Int32     -> int32 (any Go integer is accepted when writing)
+//	Int64     -> int64 (any Go integer is accepted when writing)
+//	Uint64    -> uint64 (any non negative Go integer is accepted when writing)
+//	Double    -> float64
+//	String    -> string ([]byte is accepted when writing)
+//	Bytes     -> []byte
+//	JSON      -> string ([]byte is accepted when writing)
+//	Timestamp -> time.Time, stored in milliseconds
+//	null      -> nil, only for optional columns
+package parquet
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Magic starts and ends every Parquet file.
+const Magic = "PAR1"
+
+// Kind is the type of a column, a Parquet physical type with an optional
+// converted type.
+type Kind int
+
+// Supported kinds.
+const (
+	Boolean Kind = iota
+	Int32
+	Int64
+	Uint64
+	Double
+	String
+	Bytes
+	JSON
+	Timestamp
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Boolean:
+		return "boolean"
+	case Int32:
+		return "int32"
+	case Int64:
+		return "int64"
+	case Uint64:
+		return "uint64"
+	case Double:
+		return "double"
+	case String:
+		return "string"
+	case Bytes:
+		return "bytes"
+	case JSON:
+		return "json"
+	case Timestamp:
+		return "timestamp"
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+// Parquet physical types.
+const (
+	typeBoolean   = 0
+	typeInt32     = 1
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+)
+
+// Parquet converted types, -1 if none.
+const (
+	convertedNone            = -1
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+	convertedUint64          = 14
+	convertedJSON            = 19
+)
+
+func (k Kind) physical() (typ int32, converted int32) {
+	switch k {
+	case Boolean:
+		return typeBoolean, convertedNone
+	case Int32:
+		return typeInt32, convertedNone
+	case Int64:
+		return typeInt64, convertedNone
+	case Uint64:
+		return typeInt64, convertedUint64
+	case Double:
+		return typeDouble, convertedNone
+	case String:
+		return typeByteArray, convertedUTF8
+	case Bytes:
+		return typeByteArray, convertedNone
+	case JSON:
+		return typeByteArray, convertedJSON
+	case Timestamp:
+		return typeInt64, convertedTimestampMillis
+	}
+	return -1, convertedNone
+}
+
+func kindOf(typ, converted int32) (Kind, bool) {
+	for k := Boolean; k <= Timestamp; k++ {
+		if t, c := k.physical(); t == typ && c == converted {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// Column describes one column of a table.
+type Column struct {
+	Name     string
+	Kind     Kind
+	Optional bool
+}
+
+// Codec is the name of a Parquet compression codec.
+type Codec string
+
+// Supported codecs.
+const (
+	CodecUncompressed Codec = "uncompressed"
+	CodecGzip         Codec = "gzip"
+)
+
+func (c Codec) id() (int32, error) {
+	switch c {
+	case CodecUncompressed, "":
+		return 0, nil
+	case CodecGzip:
+		return 2, nil
+	}
+	return 0, fmt.Errorf("unsupported codec %q", c)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), true
+	case int8:
+		return int64(x), true
+	case int16:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	case uint:
+		return int64(x), x <= math.MaxInt64
+	case uint8:
+		return int64(x), true
+	case uint16:
+		return int64(x), true
+	case uint32:
+		return int64(x), true
+	case uint64:
+		return int64(x), x <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch x := v.(type) {
+	case uint:
+		return uint64(x), true
+	case uint8:
+		return uint64(x), true
+	case uint16:
+		return uint64(x), true
+	case uint32:
+		return uint64(x), true
+	case uint64:
+		return x, true
+	}
+	i, ok := toInt64(v)
+	return uint64(i), ok && i >= 0
+}
+
+func toBytes(v interface{}) ([]byte, bool) {
+	switch x := v.(type) {
+	case []byte:
+		return x, true
+	case string:
+		return []byte(x), true
+	}
+	return nil, false
+}
+
+func timestampMillis(t time.Time) int64 {
+	return t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+}
@@ -0,0 +1,223 @@
+package parquet
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = []Column{
+	{Name: "round", Kind: Uint64},
+	{Name: "intra", Kind: Int32},
+	{Name: "balance", Kind: Int64, Optional: true},
+	{Name: "ratio", Kind: Double},
+	{Name: "frozen", Kind: Boolean},
+	{Name: "deleted", Kind: Boolean, Optional: true},
+	{Name: "addr", Kind: String},
+	{Name: "note", Kind: Bytes, Optional: true},
+	{Name: "params", Kind: JSON, Optional: true},
+	{Name: "time", Kind: Timestamp},
+}
+
+func testRow(i int) map[string]interface{} {
+	row := map[string]interface{}{
+		"round":  uint64(1<<63) + uint64(i),
+		"intra":  i,
+		"ratio":  float64(i) / 3,
+		"frozen": i%3 == 0,
+		"addr":   fmt.Sprintf("addr%d", i),
+		"time":   time.Unix(1600000000+int64(i), 123*int64(time.Millisecond)).UTC(),
+	}
+	if i%2 == 0 {
+		row["balance"] = int64(-i)
+		row["note"] = []byte{byte(i)}
+		row["deleted"] = i%4 == 0
+	}
+	if i%5 == 0 {
+		row["params"] = `{"t":1}`
+	}
+	return row
+}
+
+// expected converts a row to the types returned by Read.
+func expected(row map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+	for _, c := range testColumns {
+		v := row[c.Name]
+		if c.Kind == Int32 && v != nil {
+			v = int32(v.(int))
+		}
+		res[c.Name] = v
+	}
+	return res
+}
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenRows are the rows of the files in testdata. The same values are
+// checked by testdata/check_golden.py, which reads the files with pyarrow.
+func goldenRows() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"round":   uint64(math.MaxUint64),
+			"intra":   int32(-5),
+			"balance": int64(math.MinInt64),
+			"ratio":   0.5,
+			"frozen":  true,
+			"deleted": false,
+			"addr":    "\u00e9\u6f22",
+			"note":    []byte{},
+			"params":  `{"a":[1,2]}`,
+			"time":    time.Unix(1609556645, 678*int64(time.Millisecond)).UTC(),
+		},
+		{
+			"round":   uint64(0),
+			"intra":   int32(math.MaxInt32),
+			"balance": nil,
+			"ratio":   -1.25,
+			"frozen":  false,
+			"deleted": nil,
+			"addr":    "",
+			"note":    nil,
+			"params":  nil,
+			"time":    time.Unix(0, 0).UTC(),
+		},
+		{
+			"round":   uint64(1 << 63),
+			"intra":   int32(0),
+			"balance": int64(42),
+			"ratio":   1e300,
+			"frozen":  true,
+			"deleted": true,
+			"addr":    "addr2",
+			"note":    []byte{0, 255},
+			"params":  nil,
+			"time":    time.Unix(1600000000, 123*int64(time.Millisecond)).UTC(),
+		},
+	}
+}
+
+// Files written by this package must stay readable by other implementations.
+// The golden files are checked with pyarrow by testdata/check_golden.py, this
+// test makes sure the writer still produces them.
+func TestGolden(t *testing.T) {
+	for _, codec := range []Codec{CodecUncompressed, CodecGzip} {
+		t.Run(string(codec), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, testColumns, codec, map[string]string{"table": "golden"})
+			require.NoError(t, err)
+			for _, row := range goldenRows() {
+				require.NoError(t, w.Append(row))
+			}
+			require.NoError(t, w.Close())
+
+			path := filepath.Join("testdata", "golden_"+string(codec)+".parquet")
+			if *update {
+				require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+			}
+			golden, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+
+			// The gzip output may change with the Go version, only the
+			// uncompressed file must be identical.
+			if codec == CodecUncompressed {
+				assert.Equal(t, golden, buf.Bytes())
+			}
+			f, err := Read(golden)
+			require.NoError(t, err)
+			assert.Equal(t, testColumns, f.Columns)
+			assert.Equal(t, goldenRows(), f.Rows)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, codec := range []Codec{CodecUncompressed, CodecGzip} {
+		t.Run(string(codec), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, testColumns, codec, map[string]string{"table": "test"})
+			require.NoError(t, err)
+			// Force several row groups.
+			w.RowGroupBytes = 1000
+
+			var rows []map[string]interface{}
+			for i := 0; i < 100; i++ {
+				row := testRow(i)
+				require.NoError(t, w.Append(row))
+				rows = append(rows, expected(row))
+			}
+			require.NoError(t, w.Close())
+			assert.Greater(t, len(w.rowGroups), 1)
+
+			f, err := Read(buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, testColumns, f.Columns)
+			assert.Equal(t, map[string]string{"table": "test"}, f.Metadata)
+			assert.Equal(t, rows, f.Rows)
+		})
+	}
+}
+
+func TestAppendRejectsBadRows(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testColumns, CodecUncompressed, nil)
+	require.NoError(t, err)
+
+	missing := testRow(1)
+	delete(missing, "addr")
+	assert.Error(t, w.Append(missing))
+
+	wrongType := testRow(2)
+	wrongType["time"] = "yesterday"
+	assert.Error(t, w.Append(wrongType))
+
+	negative := testRow(3)
+	negative["round"] = -1
+	assert.Error(t, w.Append(negative))
+
+	// Failed rows leave nothing behind.
+	require.NoError(t, w.Append(testRow(4)))
+	require.NoError(t, w.Close())
+	f, err := Read(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{expected(testRow(4))}, f.Rows)
+}
+
+func TestEmptyFile(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testColumns, CodecGzip, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	f, err := Read(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, testColumns, f.Columns)
+	assert.Empty(t, f.Rows)
+}
+
+func TestReadNotParquet(t *testing.T) {
+	_, err := Read([]byte("PAR1 but not really"))
+	assert.ErrorIs(t, err, ErrNotParquet)
+}
+
+func TestNewWriterRejectsDuplicateColumns(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, []Column{{Name: "a"}, {Name: "a"}}, CodecUncompressed, nil)
+	assert.Error(t, err)
+}
+
+// A file written by another implementation (parquet-mr style bit packed
+// definition levels) must be readable too.
+func TestReadLevelsBitPacked(t *testing.T) {
+	// One bit packed group of 8 values: 1, 0, 1, 1, 0, 0, 0, 1.
+	levels, err := readLevels([]byte{0x03, 0x8d}, 8)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true, true, false, false, false, true}, levels)
+}
@@ -0,0 +1,261 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+// ErrNotParquet is returned when the data does not start and end with Magic.
+var ErrNotParquet = errors.New("not a parquet file")
+
+// File is a Parquet file read into memory.
+type File struct {
+	Columns  []Column
+	Metadata map[string]string
+	Rows     []map[string]interface{}
+}
+
+// ReadFile reads a Parquet file written by Writer.
+func ReadFile(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile() err: %w", err)
+	}
+	f, err := Read(data)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile() %s err: %w", path, err)
+	}
+	return f, nil
+}
+
+// Read decodes a Parquet file with a flat schema and PLAIN encoded data pages.
+func Read(data []byte) (*File, error) {
+	if len(data) < 12 || string(data[:4]) != Magic || string(data[len(data)-4:]) != Magic {
+		return nil, ErrNotParquet
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if size > len(data)-12 {
+		return nil, fmt.Errorf("Read() footer size %d too large", size)
+	}
+	meta, _, err := decodeStruct(data[len(data)-8-size : len(data)-8])
+	if err != nil {
+		return nil, fmt.Errorf("Read() footer err: %w", err)
+	}
+
+	res := &File{Metadata: make(map[string]string)}
+	elements := meta.list(2)
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("Read() missing schema")
+	}
+	for _, e := range elements[1:] {
+		element, _ := e.(thriftStruct)
+		converted := int32(convertedNone)
+		if _, ok := element[6]; ok {
+			converted = int32(element.int(6))
+		}
+		kind, ok := kindOf(int32(element.int(1)), converted)
+		if !ok || element.int(3) > 1 {
+			return nil, fmt.Errorf("Read() unsupported column %s", element.bytes(4))
+		}
+		res.Columns = append(res.Columns, Column{
+			Name:     string(element.bytes(4)),
+			Kind:     kind,
+			Optional: element.int(3) == 1,
+		})
+	}
+	for _, kv := range meta.list(5) {
+		pair, _ := kv.(thriftStruct)
+		res.Metadata[string(pair.bytes(1))] = string(pair.bytes(2))
+	}
+
+	for _, g := range meta.list(4) {
+		group, _ := g.(thriftStruct)
+		numRows := int(group.int(3))
+		chunks := group.list(1)
+		if len(chunks) != len(res.Columns) {
+			return nil, fmt.Errorf("Read() row group has %d columns, expected %d", len(chunks), len(res.Columns))
+		}
+		rows := make([]map[string]interface{}, numRows)
+		for i := range rows {
+			rows[i] = make(map[string]interface{}, len(res.Columns))
+		}
+		for i, ch := range chunks {
+			chunk, _ := ch.(thriftStruct)
+			md := chunk.strct(3)
+			codec := md.int(4)
+			values, err := readChunk(data, int(md.int(9)), codec, res.Columns[i], numRows)
+			if err != nil {
+				return nil, fmt.Errorf("Read() column %s err: %w", res.Columns[i].Name, err)
+			}
+			for j, v := range values {
+				rows[j][res.Columns[i].Name] = v
+			}
+		}
+		res.Rows = append(res.Rows, rows...)
+	}
+	return res, nil
+}
+
+func readChunk(data []byte, offset int, codec int64, c Column, numRows int) ([]interface{}, error) {
+	if offset < 0 || offset >= len(data) {
+		return nil, fmt.Errorf("invalid page offset %d", offset)
+	}
+	var res []interface{}
+	for len(res) < numRows {
+		header, n, err := decodeStruct(data[offset:])
+		if err != nil {
+			return nil, err
+		}
+		offset += n
+		compressedSize := int(header.int(3))
+		if compressedSize < 0 || offset+compressedSize > len(data) {
+			return nil, fmt.Errorf("invalid page size %d", compressedSize)
+		}
+		page := data[offset : offset+compressedSize]
+		offset += compressedSize
+		if header.int(1) != 0 {
+			return nil, fmt.Errorf("unsupported page type %d", header.int(1))
+		}
+		if codec == 2 {
+			zr, err := gzip.NewReader(bytes.NewReader(page))
+			if err != nil {
+				return nil, err
+			}
+			page, err = ioutil.ReadAll(zr)
+			if err != nil {
+				return nil, err
+			}
+		} else if codec != 0 {
+			return nil, fmt.Errorf("unsupported codec %d", codec)
+		}
+		dph := header.strct(5)
+		if dph.int(2) != encodingPlain {
+			return nil, fmt.Errorf("unsupported encoding %d", dph.int(2))
+		}
+		values, err := readPage(page, c, int(dph.int(1)))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, values...)
+	}
+	return res, nil
+}
+
+// readLevels decodes RLE/bit packed hybrid definition levels of bit width 1.
+func readLevels(buf []byte, n int) ([]bool, error) {
+	res := make([]bool, 0, n)
+	for len(res) < n {
+		header, k := binary.Uvarint(buf)
+		if k <= 0 {
+			return nil, fmt.Errorf("invalid definition levels")
+		}
+		buf = buf[k:]
+		if header&1 == 0 {
+			// RLE run.
+			if len(buf) < 1 {
+				return nil, fmt.Errorf("invalid definition levels")
+			}
+			for i := uint64(0); i < header>>1 && len(res) < n; i++ {
+				res = append(res, buf[0] == 1)
+			}
+			buf = buf[1:]
+		} else {
+			// Bit packed groups of 8 values.
+			count := int(header>>1) * 8
+			if len(buf) < count/8 {
+				return nil, fmt.Errorf("invalid definition levels")
+			}
+			for i := 0; i < count && len(res) < n; i++ {
+				res = append(res, buf[i/8]&(1<<(i%8)) != 0)
+			}
+			buf = buf[count/8:]
+		}
+	}
+	return res, nil
+}
+
+func readPage(page []byte, c Column, n int) ([]interface{}, error) {
+	defined := make([]bool, n)
+	for i := range defined {
+		defined[i] = true
+	}
+	if c.Optional {
+		if len(page) < 4 {
+			return nil, fmt.Errorf("short page")
+		}
+		size := int(binary.LittleEndian.Uint32(page))
+		if 4+size > len(page) {
+			return nil, fmt.Errorf("short page")
+		}
+		var err error
+		defined, err = readLevels(page[4:4+size], n)
+		if err != nil {
+			return nil, err
+		}
+		page = page[4+size:]
+	}
+
+	res := make([]interface{}, n)
+	bit := 0
+	for i := range res {
+		if !defined[i] {
+			continue
+		}
+		switch c.Kind {
+		case Boolean:
+			if bit/8 >= len(page) {
+				return nil, fmt.Errorf("short page")
+			}
+			res[i] = page[bit/8]&(1<<(bit%8)) != 0
+			bit++
+			continue
+		case Int32:
+			if len(page) < 4 {
+				return nil, fmt.Errorf("short page")
+			}
+			res[i] = int32(binary.LittleEndian.Uint32(page))
+			page = page[4:]
+			continue
+		case String, Bytes, JSON:
+			if len(page) < 4 {
+				return nil, fmt.Errorf("short page")
+			}
+			size := int(binary.LittleEndian.Uint32(page))
+			if 4+size > len(page) {
+				return nil, fmt.Errorf("short page")
+			}
+			b := page[4 : 4+size]
+			page = page[4+size:]
+			if c.Kind == Bytes {
+				res[i] = append([]byte{}, b...)
+			} else {
+				res[i] = string(b)
+			}
+			continue
+		}
+
+		if len(page) < 8 {
+			return nil, fmt.Errorf("short page")
+		}
+		u := binary.LittleEndian.Uint64(page)
+		page = page[8:]
+		switch c.Kind {
+		case Int64:
+			res[i] = int64(u)
+		case Uint64:
+			res[i] = u
+		case Double:
+			res[i] = math.Float64frombits(u)
+		case Timestamp:
+			ms := int64(u)
+			res[i] = time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond)).UTC()
+		}
+	}
+	return res, nil
+}
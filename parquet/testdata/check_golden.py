@@ -0,0 +1,122 @@
+#!/usr/bin/env python3
+#
+# Read the golden files written by the parquet package with pyarrow, and with
+# duckdb if it is installed, and compare them to the rows of goldenRows() in
+# parquet_test.go.
+#
+# setup requires:
+#  pip install "pyarrow >=6"
+
+import datetime
+import os
+import sys
+
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+
+UTC = datetime.timezone.utc
+
+EXPECTED = {
+    "round": [2**64 - 1, 0, 2**63],
+    "intra": [-5, 2**31 - 1, 0],
+    "balance": [-(2**63), None, 42],
+    "ratio": [0.5, -1.25, 1e300],
+    "frozen": [True, False, True],
+    "deleted": [False, None, True],
+    "addr": ["é漢", "", "addr2"],
+    "note": [b"", None, b"\x00\xff"],
+    "params": ['{"a":[1,2]}', None, None],
+    "time": [
+        datetime.datetime(2021, 1, 2, 3, 4, 5, 678000, tzinfo=UTC),
+        datetime.datetime(1970, 1, 1, tzinfo=UTC),
+        datetime.datetime(2020, 9, 13, 12, 26, 40, 123000, tzinfo=UTC),
+    ],
+}
+
+OPTIONAL = {"balance", "deleted", "note", "params"}
+
+
+def is_string(t):
+    # Newer pyarrow versions may map the JSON converted type to an extension
+    # type whose storage is a string.
+    t = getattr(t, "storage_type", t)
+    return pa.types.is_string(t) or pa.types.is_large_string(t)
+
+
+TYPES = {
+    "round": lambda t: t == pa.uint64(),
+    "intra": lambda t: t == pa.int32(),
+    "balance": lambda t: t == pa.int64(),
+    "ratio": lambda t: t == pa.float64(),
+    "frozen": lambda t: t == pa.bool_(),
+    "deleted": lambda t: t == pa.bool_(),
+    "addr": is_string,
+    "note": lambda t: pa.types.is_binary(t) or pa.types.is_large_binary(t),
+    "params": is_string,
+    "time": lambda t: pa.types.is_timestamp(t) and t.unit == "ms",
+}
+
+
+def normalize(column, values):
+    if column == "time":
+        return [v.replace(tzinfo=UTC) if v.tzinfo is None else v for v in values]
+    if column == "params":
+        return [v if v is None or isinstance(v, str) else str(v) for v in values]
+    return values
+
+
+def check_pyarrow(path):
+    errors = []
+    table = pq.read_table(path)
+    metadata = table.schema.metadata or {}
+    if metadata.get(b"table") != b"golden":
+        errors.append("metadata: {!r}".format(metadata))
+    if table.column_names != list(EXPECTED):
+        errors.append("columns: {}".format(table.column_names))
+    for field in table.schema:
+        if field.name not in EXPECTED:
+            continue
+        if not TYPES[field.name](field.type):
+            errors.append("{}: unexpected type {}".format(field.name, field.type))
+        if field.nullable != (field.name in OPTIONAL):
+            errors.append("{}: nullable is {}".format(field.name, field.nullable))
+        values = normalize(field.name, table.column(field.name).to_pylist())
+        if values != EXPECTED[field.name]:
+            errors.append("{}: {!r} != {!r}".format(field.name, values, EXPECTED[field.name]))
+    return errors
+
+
+def check_duckdb(path):
+    try:
+        import duckdb
+    except ImportError:
+        return []
+    errors = []
+    con = duckdb.connect()
+    rows = con.execute(
+        "SELECT round, intra, balance, ratio, frozen, deleted, addr, note "
+        "FROM read_parquet(?)", [path]).fetchall()
+    for column, values in zip(list(EXPECTED)[:8], zip(*rows)):
+        values = [bytes(v) if isinstance(v, (bytearray, memoryview)) else v for v in values]
+        if list(values) != EXPECTED[column]:
+            errors.append("duckdb {}: {!r} != {!r}".format(column, values, EXPECTED[column]))
+    return errors
+
+
+def main():
+    failed = False
+    for codec in ("uncompressed", "gzip"):
+        path = os.path.join(HERE, "golden_{}.parquet".format(codec))
+        errors = check_pyarrow(path) + check_duckdb(path)
+        for err in errors:
+            print("{}: {}".format(os.path.basename(path), err))
+        failed = failed or bool(errors)
+    if failed:
+        sys.exit(1)
+    print("golden files OK")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,274 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The Parquet metadata is serialized with the Thrift compact protocol. Only
+// the parts of the protocol used by the Parquet structures are implemented.
+
+// Compact protocol field types.
+const (
+	tBoolTrue  = 1
+	tBoolFalse = 2
+	tByte      = 3
+	tI16       = 4
+	tI32       = 5
+	tI64       = 6
+	tDouble    = 7
+	tBinary    = 8
+	tList      = 9
+	tSet       = 10
+	tMap       = 11
+	tStruct    = 12
+)
+
+// thriftWriter encodes one top level struct. Nested structs are written
+// between structBegin and structEnd.
+type thriftWriter struct {
+	buf []byte
+	// lastID holds the id of the last field written in each open struct.
+	lastID []int16
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	w.buf = append(w.buf, tmp[:n]...)
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.lastID[len(w.lastID)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, tI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, tI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) binary(id int16, b []byte) {
+	w.fieldHeader(id, tBinary)
+	w.varint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *thriftWriter) listBegin(id int16, elemType byte, n int) {
+	w.fieldHeader(id, tList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elemType)
+	} else {
+		w.buf = append(w.buf, 0xf0|elemType)
+		w.varint(uint64(n))
+	}
+}
+
+// i32Elem and binaryElem write list elements.
+func (w *thriftWriter) i32Elem(v int32) {
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) binaryElem(b []byte) {
+	w.varint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+// structBegin opens a struct field, or a list element if id is 0.
+func (w *thriftWriter) structBegin(id int16) {
+	if id != 0 {
+		w.fieldHeader(id, tStruct)
+	}
+	w.lastID = append(w.lastID, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf = append(w.buf, 0)
+	w.lastID = w.lastID[:len(w.lastID)-1]
+}
+
+// encodeStruct encodes a top level struct written by f.
+func encodeStruct(f func(w *thriftWriter)) []byte {
+	w := thriftWriter{lastID: []int16{0}}
+	f(&w)
+	w.buf = append(w.buf, 0)
+	return w.buf
+}
+
+var errThrift = errors.New("invalid thrift data")
+
+// thriftStruct is a decoded struct, keyed by field id. Values are int64,
+// bool, float64, []byte, []interface{} or thriftStruct.
+type thriftStruct map[int16]interface{}
+
+func (s thriftStruct) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func (s thriftStruct) bytes(id int16) []byte {
+	v, _ := s[id].([]byte)
+	return v
+}
+
+func (s thriftStruct) list(id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
+
+func (s thriftStruct) strct(id int16) thriftStruct {
+	v, _ := s[id].(thriftStruct)
+	return v
+}
+
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errThrift
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errThrift
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) zigzag() (int64, error) {
+	v, err := r.varint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (r *thriftReader) value(typ byte) (interface{}, error) {
+	switch typ {
+	case tBoolTrue:
+		return true, nil
+	case tBoolFalse:
+		return false, nil
+	case tByte:
+		b, err := r.byte()
+		return int64(int8(b)), err
+	case tI16, tI32, tI64:
+		return r.zigzag()
+	case tDouble:
+		if r.pos+8 > len(r.buf) {
+			return nil, errThrift
+		}
+		v := binary.LittleEndian.Uint64(r.buf[r.pos:])
+		r.pos += 8
+		return math.Float64frombits(v), nil
+	case tBinary:
+		n, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(r.buf)-r.pos) < n {
+			return nil, errThrift
+		}
+		b := r.buf[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return b, nil
+	case tList, tSet:
+		header, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(header >> 4)
+		if n == 15 {
+			n, err = r.varint()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if n > uint64(len(r.buf)) {
+			return nil, errThrift
+		}
+		elemType := header & 0x0f
+		res := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			var v interface{}
+			if elemType == tBoolTrue || elemType == tBoolFalse {
+				// Booleans in containers take one byte each.
+				b, err := r.byte()
+				if err != nil {
+					return nil, err
+				}
+				v = b == tBoolTrue
+			} else {
+				v, err = r.value(elemType)
+				if err != nil {
+					return nil, err
+				}
+			}
+			res = append(res, v)
+		}
+		return res, nil
+	case tStruct:
+		return r.readStruct()
+	}
+	return nil, fmt.Errorf("unsupported thrift type %d", typ)
+}
+
+func (r *thriftReader) readStruct() (thriftStruct, error) {
+	res := make(thriftStruct)
+	last := int16(0)
+	for {
+		header, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return res, nil
+		}
+		typ := header & 0x0f
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			v, err := r.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+		v, err := r.value(typ)
+		if err != nil {
+			return nil, err
+		}
+		res[id] = v
+	}
+}
+
+// decodeStruct decodes a struct at the start of buf, returning the number of
+// bytes read.
+func decodeStruct(buf []byte) (thriftStruct, int, error) {
+	r := thriftReader{buf: buf}
+	s, err := r.readStruct()
+	return s, r.pos, err
+}
@@ -0,0 +1,416 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultRowGroupBytes is the approximate amount of encoded data buffered
+// before a row group is written.
+const DefaultRowGroupBytes = 64 << 20
+
+// Parquet encodings.
+const (
+	encodingPlain = 0
+	encodingRLE   = 3
+)
+
+// columnBuffer holds the values of one column in the current row group.
+type columnBuffer struct {
+	// defined holds one entry per row for optional columns.
+	defined []bool
+	// values holds the PLAIN encoding of the non null values, except for
+	// booleans which are bit packed when the row group is written.
+	values []byte
+	bools  []bool
+}
+
+type chunkMeta struct {
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+type rowGroupMeta struct {
+	chunks   []chunkMeta
+	numRows  int64
+	byteSize int64
+}
+
+// Writer writes rows to a Parquet file. Rows are buffered and written as row
+// groups of about RowGroupBytes. The file is only valid after Close.
+type Writer struct {
+	w       io.Writer
+	offset  int64
+	columns []Column
+	codec   Codec
+	codecID int32
+	meta    map[string]string
+
+	// RowGroupBytes may be changed before the first row is appended.
+	RowGroupBytes int
+
+	buffers   []columnBuffer
+	scratch   []byte
+	ends      []int
+	rows      int64
+	bufBytes  int
+	rowGroups []rowGroupMeta
+	numRows   int64
+}
+
+// NewWriter writes the file header and returns a Writer. The metadata is
+// stored in the file footer.
+func NewWriter(w io.Writer, columns []Column, codec Codec, meta map[string]string) (*Writer, error) {
+	codecID, err := codec.id()
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns")
+	}
+	seen := make(map[string]bool)
+	for _, c := range columns {
+		if typ, _ := c.Kind.physical(); typ < 0 {
+			return nil, fmt.Errorf("column %s has unsupported kind %v", c.Name, c.Kind)
+		}
+		if c.Name == "" || seen[c.Name] {
+			return nil, fmt.Errorf("invalid or duplicate column name %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+
+	res := &Writer{
+		w:             w,
+		columns:       columns,
+		codec:         codec,
+		codecID:       codecID,
+		meta:          meta,
+		RowGroupBytes: DefaultRowGroupBytes,
+		buffers:       make([]columnBuffer, len(columns)),
+	}
+	err = res.write([]byte(Magic))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// appendValue appends the PLAIN encoding of v to out. Booleans take one byte,
+// they are bit packed when the row group is written.
+func appendValue(out []byte, c Column, v interface{}) ([]byte, error) {
+	var tmp [8]byte
+	switch c.Kind {
+	case Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			return out, fmt.Errorf("expected bool, got %T", v)
+		}
+		if b {
+			return append(out, 1), nil
+		}
+		return append(out, 0), nil
+	case Int32:
+		i, ok := toInt64(v)
+		if !ok || i < math.MinInt32 || i > math.MaxInt32 {
+			return out, fmt.Errorf("expected int32, got %T %v", v, v)
+		}
+		binary.LittleEndian.PutUint32(tmp[:], uint32(i))
+		return append(out, tmp[:4]...), nil
+	case Int64:
+		i, ok := toInt64(v)
+		if !ok {
+			return out, fmt.Errorf("expected int64, got %T %v", v, v)
+		}
+		binary.LittleEndian.PutUint64(tmp[:], uint64(i))
+	case Uint64:
+		u, ok := toUint64(v)
+		if !ok {
+			return out, fmt.Errorf("expected uint64, got %T %v", v, v)
+		}
+		binary.LittleEndian.PutUint64(tmp[:], u)
+	case Double:
+		f, ok := v.(float64)
+		if !ok {
+			return out, fmt.Errorf("expected float64, got %T", v)
+		}
+		binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(f))
+	case String, Bytes, JSON:
+		b, ok := toBytes(v)
+		if !ok {
+			return out, fmt.Errorf("expected string or []byte, got %T", v)
+		}
+		binary.LittleEndian.PutUint32(tmp[:], uint32(len(b)))
+		return append(append(out, tmp[:4]...), b...), nil
+	case Timestamp:
+		t, ok := v.(time.Time)
+		if !ok {
+			return out, fmt.Errorf("expected time.Time, got %T", v)
+		}
+		binary.LittleEndian.PutUint64(tmp[:], uint64(timestampMillis(t)))
+	}
+	return append(out, tmp[:]...), nil
+}
+
+// Append adds a row. Columns missing from the record are null.
+func (w *Writer) Append(record map[string]interface{}) error {
+	// Encode the whole row first so that a bad value does not leave the
+	// columns with different lengths.
+	w.scratch = w.scratch[:0]
+	w.ends = w.ends[:0]
+	for _, c := range w.columns {
+		v := record[c.Name]
+		if v == nil && !c.Optional {
+			return fmt.Errorf("Append() column %s is required", c.Name)
+		}
+		if v != nil {
+			var err error
+			w.scratch, err = appendValue(w.scratch, c, v)
+			if err != nil {
+				return fmt.Errorf("Append() column %s: %w", c.Name, err)
+			}
+		}
+		w.ends = append(w.ends, len(w.scratch))
+	}
+
+	start := 0
+	for i, c := range w.columns {
+		buf := &w.buffers[i]
+		encoded := w.scratch[start:w.ends[i]]
+		start = w.ends[i]
+		if c.Optional {
+			buf.defined = append(buf.defined, record[c.Name] != nil)
+		}
+		if len(encoded) == 0 {
+			continue
+		}
+		if c.Kind == Boolean {
+			buf.bools = append(buf.bools, encoded[0] == 1)
+		} else {
+			buf.values = append(buf.values, encoded...)
+		}
+	}
+	w.bufBytes += len(w.scratch)
+	w.rows++
+	if w.bufBytes >= w.RowGroupBytes {
+		return w.flush()
+	}
+	return nil
+}
+
+// appendLevels appends the RLE/bit packed hybrid encoding of definition
+// levels with bit width 1, as runs of equal values.
+func appendLevels(out []byte, defined []bool) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	for i := 0; i < len(defined); {
+		j := i
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		n := binary.PutUvarint(tmp[:], uint64(j-i)<<1)
+		out = append(out, tmp[:n]...)
+		if defined[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+func packBools(out []byte, bools []bool) []byte {
+	packed := make([]byte, (len(bools)+7)/8)
+	for i, b := range bools {
+		if b {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return append(out, packed...)
+}
+
+func (w *Writer) compress(page []byte) ([]byte, error) {
+	if w.codec != CodecGzip {
+		return page, nil
+	}
+	var out bytes.Buffer
+	zw := gzip.NewWriter(&out)
+	_, err := zw.Write(page)
+	if err == nil {
+		err = zw.Close()
+	}
+	return out.Bytes(), err
+}
+
+// writeChunk writes the column chunk of the current row group as one data
+// page.
+func (w *Writer) writeChunk(c Column, buf *columnBuffer) (chunkMeta, error) {
+	var page []byte
+	if c.Optional {
+		levels := appendLevels(nil, buf.defined)
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(len(levels)))
+		page = append(page, size[:]...)
+		page = append(page, levels...)
+	}
+	if c.Kind == Boolean {
+		page = packBools(page, buf.bools)
+	} else {
+		page = append(page, buf.values...)
+	}
+	compressed, err := w.compress(page)
+	if err != nil {
+		return chunkMeta{}, err
+	}
+
+	header := encodeStruct(func(t *thriftWriter) {
+		t.i32(1, 0) // DATA_PAGE
+		t.i32(2, int32(len(page)))
+		t.i32(3, int32(len(compressed)))
+		t.structBegin(5)
+		t.i32(1, int32(w.rows))
+		t.i32(2, encodingPlain)
+		t.i32(3, encodingRLE)
+		t.i32(4, encodingRLE)
+		t.structEnd()
+	})
+
+	res := chunkMeta{
+		offset:           w.offset,
+		numValues:        w.rows,
+		uncompressedSize: int64(len(header) + len(page)),
+		compressedSize:   int64(len(header) + len(compressed)),
+	}
+	err = w.write(header)
+	if err == nil {
+		err = w.write(compressed)
+	}
+	return res, err
+}
+
+func (w *Writer) flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+	group := rowGroupMeta{numRows: w.rows}
+	for i, c := range w.columns {
+		chunk, err := w.writeChunk(c, &w.buffers[i])
+		if err != nil {
+			return fmt.Errorf("flush() column %s: %w", c.Name, err)
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.byteSize += chunk.uncompressedSize
+		w.buffers[i] = columnBuffer{}
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += w.rows
+	w.rows = 0
+	w.bufBytes = 0
+	return nil
+}
+
+func (w *Writer) footer() []byte {
+	return encodeStruct(func(t *thriftWriter) {
+		t.i32(1, 1)
+
+		t.listBegin(2, tStruct, len(w.columns)+1)
+		t.structBegin(0)
+		t.binary(4, []byte("schema"))
+		t.i32(5, int32(len(w.columns)))
+		t.structEnd()
+		for _, c := range w.columns {
+			typ, converted := c.Kind.physical()
+			t.structBegin(0)
+			t.i32(1, typ)
+			if c.Optional {
+				t.i32(3, 1)
+			} else {
+				t.i32(3, 0)
+			}
+			t.binary(4, []byte(c.Name))
+			if converted != convertedNone {
+				t.i32(6, converted)
+			}
+			t.structEnd()
+		}
+
+		t.i64(3, w.numRows)
+
+		t.listBegin(4, tStruct, len(w.rowGroups))
+		for _, group := range w.rowGroups {
+			t.structBegin(0)
+			t.listBegin(1, tStruct, len(group.chunks))
+			for i, chunk := range group.chunks {
+				typ, _ := w.columns[i].Kind.physical()
+				t.structBegin(0)
+				t.i64(2, chunk.offset)
+				t.structBegin(3)
+				t.i32(1, typ)
+				t.listBegin(2, tI32, 2)
+				t.i32Elem(encodingPlain)
+				t.i32Elem(encodingRLE)
+				t.listBegin(3, tBinary, 1)
+				t.binaryElem([]byte(w.columns[i].Name))
+				t.i32(4, w.codecID)
+				t.i64(5, chunk.numValues)
+				t.i64(6, chunk.uncompressedSize)
+				t.i64(7, chunk.compressedSize)
+				t.i64(9, chunk.offset)
+				t.structEnd()
+				t.structEnd()
+			}
+			t.i64(2, group.byteSize)
+			t.i64(3, group.numRows)
+			t.structEnd()
+		}
+
+		if len(w.meta) > 0 {
+			keys := make([]string, 0, len(w.meta))
+			for k := range w.meta {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			t.listBegin(5, tStruct, len(keys))
+			for _, k := range keys {
+				t.structBegin(0)
+				t.binary(1, []byte(k))
+				t.binary(2, []byte(w.meta[k]))
+				t.structEnd()
+			}
+		}
+
+		t.binary(6, []byte("algorand indexer"))
+	})
+}
+
+// Close writes the buffered rows and the file footer. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	err := w.flush()
+	if err != nil {
+		return fmt.Errorf("Close() err: %w", err)
+	}
+	footer := w.footer()
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer)))
+	for _, b := range [][]byte{footer, size[:], []byte(Magic)} {
+		err = w.write(b)
+		if err != nil {
+			return fmt.Errorf("Close() err: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+// Package s3 is a minimal client for the S3 object storage API: single and
+// multipart uploads checked with Content-MD5 and signed payload hashes,
+// downloads and listings. It addresses buckets in path style, so it works
+// with AWS S3 as well as MinIO and other S3 compatible services.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/algorand/indexer/s3/internal/sigv4"
+)
+
+// Defaults of Options.
+const (
+	DefaultRegion   = "us-east-1"
+	DefaultPartSize = 16 << 20
+
+	// MinPartSize is the smallest part of a multipart upload accepted by S3,
+	// except for the last part.
+	MinPartSize = 5 << 20
+)
+
+// ErrNotFound is returned when an object or bucket does not exist.
+var ErrNotFound = errors.New("s3 object not found")
+
+// Error is an error response of the service.
+type Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+// Error is part of the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("s3 error %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is makes errors.Is(err, ErrNotFound) true for the not found errors.
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && (e.Code == "NoSuchKey" || e.Code == "NoSuchBucket")
+}
+
+// Options configure a Client.
+type Options struct {
+	// Endpoint is the URL of the service, e.g. https://s3.amazonaws.com or
+	// http://localhost:9000.
+	Endpoint string
+
+	// Region is the region of the bucket, DefaultRegion if empty.
+	Region string
+
+	// Bucket is the bucket of the objects.
+	Bucket string
+
+	// AccessKey and SecretKey sign the requests.
+	AccessKey string
+	SecretKey string
+
+	// PartSize is the size of the parts of multipart uploads, DefaultPartSize
+	// if 0. Smaller objects are uploaded in one request.
+	PartSize int
+
+	// HTTPClient sends the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// Object is an object of a listing.
+type Object struct {
+	Key  string
+	Size int64
+}
+
+// Client reads and writes the objects of a bucket.
+type Client struct {
+	opts     Options
+	endpoint *url.URL
+}
+
+// MakeClient creates a Client.
+func MakeClient(opts Options) (*Client, error) {
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("MakeClient() bucket not set")
+	}
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("MakeClient() err: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("MakeClient() unsupported endpoint %q", opts.Endpoint)
+	}
+	if opts.Region == "" {
+		opts.Region = DefaultRegion
+	}
+	if opts.PartSize == 0 {
+		opts.PartSize = DefaultPartSize
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &Client{opts: opts, endpoint: endpoint}, nil
+}
+
+// Bucket returns the bucket of the client.
+func (c *Client) Bucket() string {
+	return c.opts.Bucket
+}
+
+// do sends a signed request for an object, or the bucket if `key` is empty,
+// and returns the response if its status is 2xx.
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.opts.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		sum := md5.Sum(body)
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	creds := sigv4.Credentials{AccessKey: c.opts.AccessKey, SecretKey: c.opts.SecretKey}
+	sigv4.Sign(req, sigv4.HashHex(body), creds, c.opts.Region, time.Now())
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, readError(resp)
+	}
+	return resp, nil
+}
+
+func readError(resp *http.Response) error {
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	serr := &Error{StatusCode: resp.StatusCode}
+	if xml.Unmarshal(data, serr) != nil || serr.Code == "" {
+		serr.Code = http.StatusText(resp.StatusCode)
+		if resp.StatusCode == http.StatusNotFound {
+			serr.Code = "NoSuchKey"
+		}
+	}
+	return serr
+}
+
+// doXML sends a request and decodes its XML response into `v`. S3 may
+// report a failure in the body of a 200 response, e.g. when completing a
+// multipart upload.
+func (c *Client) doXML(ctx context.Context, method, key string, query url.Values, body []byte, v interface{}) error {
+	resp, err := c.do(ctx, method, key, query, body, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var root struct{ XMLName xml.Name }
+	if err = xml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if root.XMLName.Local == "Error" {
+		serr := &Error{StatusCode: resp.StatusCode}
+		xml.Unmarshal(data, serr)
+		return serr
+	}
+	return xml.Unmarshal(data, v)
+}
+
+// Put uploads an object in one request.
+func (c *Client) Put(ctx context.Context, key string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	resp, err := c.do(ctx, http.MethodPut, key, nil, data, nil)
+	if err != nil {
+		return fmt.Errorf("Put() %s err: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// uploadParts uploads the parts of a multipart upload, the first of which
+// is already read.
+func (c *Client) uploadParts(ctx context.Context, key, uploadID string, first []byte, r io.Reader) error {
+	var complete completeMultipartUpload
+	part := first
+	for number := 1; len(part) > 0; number++ {
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		resp, err := c.do(ctx, http.MethodPut, key, query, part, nil)
+		if err != nil {
+			return fmt.Errorf("part %d err: %w", number, err)
+		}
+		resp.Body.Close()
+		complete.Parts = append(complete.Parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		part = make([]byte, c.opts.PartSize)
+		n, err := io.ReadFull(r, part)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		part = part[:n]
+	}
+
+	body, err := xml.Marshal(&complete)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		ETag string `xml:"ETag"`
+	}
+	return c.doXML(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body, &result)
+}
+
+// Upload uploads an object from a reader, with a multipart upload if it is
+// larger than the part size. The object only becomes visible once it is
+// complete. A failed multipart upload is aborted.
+func (c *Client) Upload(ctx context.Context, key string, r io.Reader) error {
+	first := make([]byte, c.opts.PartSize+1)
+	n, err := io.ReadFull(r, first)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return c.Put(ctx, key, first[:n])
+	}
+	if err != nil {
+		return fmt.Errorf("Upload() %s err: %w", key, err)
+	}
+
+	var initiate struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = c.doXML(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, &initiate)
+	if err != nil {
+		return fmt.Errorf("Upload() %s initiate err: %w", key, err)
+	}
+
+	// The byte read past the first part starts the second one.
+	err = c.uploadParts(ctx, key, initiate.UploadID, first[:c.opts.PartSize], io.MultiReader(bytes.NewReader(first[c.opts.PartSize:]), r))
+	if err != nil {
+		resp, abortErr := c.do(context.Background(), http.MethodDelete, key, url.Values{"uploadId": {initiate.UploadID}}, nil, nil)
+		if abortErr == nil {
+			resp.Body.Close()
+		}
+		return fmt.Errorf("Upload() %s err: %w", key, err)
+	}
+	return nil
+}
+
+// Get returns the content of an object.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Get() %s err: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// List returns the objects whose key starts with `prefix`, sorted by key.
+func (c *Client) List(ctx context.Context, prefix string) ([]Object, error) {
+	var res []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		var page struct {
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+			Contents              []struct {
+				Key  string `xml:"Key"`
+				Size int64  `xml:"Size"`
+			} `xml:"Contents"`
+		}
+		err := c.doXML(ctx, http.MethodGet, "", query, nil, &page)
+		if err != nil {
+			return nil, fmt.Errorf("List() %s err: %w", prefix, err)
+		}
+		for _, object := range page.Contents {
+			res = append(res, Object{Key: object.Key, Size: object.Size})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return res, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// Delete removes an object.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Delete() %s err: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,161 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/s3"
+	"github.com/algorand/indexer/s3/s3test"
+)
+
+func makeServer(t *testing.T) *s3test.Server {
+	server := s3test.NewServer()
+	t.Cleanup(server.Close)
+	server.CreateBucket("blocks")
+	return server
+}
+
+func makeClient(t *testing.T, opts s3.Options) *s3.Client {
+	client, err := s3.MakeClient(opts)
+	require.NoError(t, err)
+	return client
+}
+
+func get(t *testing.T, client *s3.Client, key string) []byte {
+	body, err := client.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer body.Close()
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+	return data
+}
+
+func TestPutGetDelete(t *testing.T) {
+	server := makeServer(t)
+	client := makeClient(t, server.Options("blocks"))
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "a/b c+d.txt", []byte("hello")))
+	assert.Equal(t, []byte("hello"), get(t, client, "a/b c+d.txt"))
+
+	require.NoError(t, client.Delete(ctx, "a/b c+d.txt"))
+	_, err := client.Get(ctx, "a/b c+d.txt")
+	assert.True(t, errors.Is(err, s3.ErrNotFound), err)
+}
+
+func TestBadCredentials(t *testing.T) {
+	server := makeServer(t)
+	opts := server.Options("blocks")
+	opts.SecretKey = "wrong"
+	client := makeClient(t, opts)
+
+	err := client.Put(context.Background(), "x", []byte("x"))
+	var serr *s3.Error
+	require.True(t, errors.As(err, &serr), err)
+	assert.Equal(t, "SignatureDoesNotMatch", serr.Code)
+}
+
+func TestMissingBucket(t *testing.T) {
+	server := makeServer(t)
+	client := makeClient(t, server.Options("missing"))
+
+	_, err := client.Get(context.Background(), "x")
+	assert.True(t, errors.Is(err, s3.ErrNotFound), err)
+}
+
+func TestUploadMultipart(t *testing.T) {
+	server := makeServer(t)
+	server.MinPartSize = 10
+	opts := server.Options("blocks")
+	opts.PartSize = 10
+	client := makeClient(t, opts)
+
+	for _, size := range []int{0, 9, 10, 11, 20, 35} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			data := bytes.Repeat([]byte("0123456789abcdef"), 3)[:size]
+			before := server.Requests("UploadPart")
+			require.NoError(t, client.Upload(context.Background(), "obj", bytes.NewReader(data)))
+
+			stored, ok := server.Object("blocks", "obj")
+			require.True(t, ok)
+			assert.Equal(t, data, stored)
+			parts := 0
+			if size > 10 {
+				parts = (size + 9) / 10
+			}
+			assert.Equal(t, parts, server.Requests("UploadPart")-before)
+			assert.Equal(t, 0, server.Uploads())
+		})
+	}
+}
+
+type failingReader struct {
+	r io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("disk error")
+	}
+	return n, err
+}
+
+func TestUploadAborts(t *testing.T) {
+	server := makeServer(t)
+	server.MinPartSize = 10
+	opts := server.Options("blocks")
+	opts.PartSize = 10
+	client := makeClient(t, opts)
+
+	err := client.Upload(context.Background(), "obj", &failingReader{bytes.NewReader(make([]byte, 25))})
+	require.Error(t, err)
+
+	_, ok := server.Object("blocks", "obj")
+	assert.False(t, ok)
+	assert.Equal(t, 1, server.Requests("AbortMultipartUpload"))
+	assert.Equal(t, 0, server.Uploads())
+}
+
+func TestUploadPartTooSmall(t *testing.T) {
+	server := makeServer(t)
+	opts := server.Options("blocks")
+	opts.PartSize = 10
+	client := makeClient(t, opts)
+
+	// The server requires parts of s3.MinPartSize, reported in a 200 response.
+	err := client.Upload(context.Background(), "obj", bytes.NewReader(make([]byte, 25)))
+	var serr *s3.Error
+	require.True(t, errors.As(err, &serr), err)
+	assert.Equal(t, "EntityTooSmall", serr.Code)
+	assert.Equal(t, 0, server.Uploads())
+}
+
+func TestListPages(t *testing.T) {
+	server := makeServer(t)
+	server.PageSize = 2
+	client := makeClient(t, server.Options("blocks"))
+	ctx := context.Background()
+
+	for _, key := range []string{"a/3", "a/1", "b/1", "a/2", "a/4", "a/5"} {
+		require.NoError(t, client.Put(ctx, key, []byte(key)))
+	}
+
+	objects, err := client.List(ctx, "a/")
+	require.NoError(t, err)
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+		assert.Equal(t, int64(3), object.Size)
+	}
+	assert.Equal(t, []string{"a/1", "a/2", "a/3", "a/4", "a/5"}, keys)
+	assert.Equal(t, 3, server.Requests("ListObjectsV2"))
+}
@@ -0,0 +1,194 @@
+// Package sigv4 implements AWS Signature Version 4 for S3 requests with the
+// signature in the Authorization header.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm = "AWS4-HMAC-SHA256"
+	service   = "s3"
+
+	// TimeFormat is the format of the X-Amz-Date header.
+	TimeFormat = "20060102T150405Z"
+	dateFormat = "20060102"
+
+	// HeaderDate and HeaderContentSHA256 are the headers set by Sign().
+	HeaderDate          = "X-Amz-Date"
+	HeaderContentSHA256 = "X-Amz-Content-Sha256"
+)
+
+// ErrSignature is returned by Verify() when a request is not signed correctly.
+var ErrSignature = errors.New("bad request signature")
+
+// Credentials are the keys used to sign requests.
+type Credentials struct {
+	AccessKey string
+	SecretKey string
+}
+
+// HashHex returns the hex encoded SHA-256 of `data`, the value of the
+// X-Amz-Content-Sha256 header.
+func HashHex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escape is the URI encoding of the canonical request: every byte except
+// the unreserved characters is percent encoded.
+func escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, escape(key, false)+"="+escape(value, false))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+func host(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+// canonicalRequest returns the canonical form of `req` with the given signed
+// headers, which must be sorted and lower case.
+func canonicalRequest(req *http.Request, signedHeaders []string, payloadHash string) string {
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		var value string
+		if name == "host" {
+			value = host(req)
+		} else {
+			values := req.Header.Values(name)
+			for i := range values {
+				values[i] = strings.Join(strings.Fields(values[i]), " ")
+			}
+			value = strings.Join(values, ",")
+		}
+		headers.WriteString(name + ":" + value + "\n")
+	}
+
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	return strings.Join([]string{
+		req.Method,
+		escape(path, true),
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func signature(req *http.Request, signedHeaders []string, payloadHash, secretKey, region string, t time.Time) string {
+	date := t.Format(dateFormat)
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		algorithm,
+		t.Format(TimeFormat),
+		scope,
+		HashHex([]byte(canonicalRequest(req, signedHeaders, payloadHash))),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// Sign sets the date, payload hash and Authorization headers of `req`. The
+// host and all the X-Amz-* and Content-* headers are signed.
+func Sign(req *http.Request, payloadHash string, creds Credentials, region string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set(HeaderDate, now.Format(TimeFormat))
+	req.Header.Set(HeaderContentSHA256, payloadHash)
+
+	signedHeaders := []string{"host"}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || strings.HasPrefix(lower, "content-") || lower == "range" {
+			signedHeaders = append(signedHeaders, lower)
+		}
+	}
+	sort.Strings(signedHeaders)
+
+	sig := signature(req, signedHeaders, payloadHash, creds.SecretKey, region, now)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s/%s/%s/aws4_request, SignedHeaders=%s, Signature=%s",
+		algorithm, creds.AccessKey, now.Format(dateFormat), region, service,
+		strings.Join(signedHeaders, ";"), sig))
+}
+
+// Verify checks the Authorization header of a request received by a server.
+// `secretKey` returns the secret of an access key. The payload hash is not
+// checked against the body.
+func Verify(req *http.Request, secretKey func(accessKey string) (string, bool)) error {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, algorithm+" ") {
+		return fmt.Errorf("%w: unsupported authorization %q", ErrSignature, auth)
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(auth, algorithm+" "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) == 2 {
+			fields[kv[0]] = kv[1]
+		}
+	}
+
+	credential := strings.Split(fields["Credential"], "/")
+	if len(credential) != 5 || credential[3] != service || credential[4] != "aws4_request" {
+		return fmt.Errorf("%w: bad credential %q", ErrSignature, fields["Credential"])
+	}
+	secret, ok := secretKey(credential[0])
+	if !ok {
+		return fmt.Errorf("%w: unknown access key %q", ErrSignature, credential[0])
+	}
+	t, err := time.Parse(TimeFormat, req.Header.Get(HeaderDate))
+	if err != nil || t.Format(dateFormat) != credential[1] {
+		return fmt.Errorf("%w: bad date %q", ErrSignature, req.Header.Get(HeaderDate))
+	}
+
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	expected := signature(req, signedHeaders, req.Header.Get(HeaderContentSHA256), secret, credential[2], t)
+	if !hmac.Equal([]byte(expected), []byte(fields["Signature"])) {
+		return fmt.Errorf("%w: signature mismatch", ErrSignature)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestName is the name of the manifest object of a prefix.
+const ManifestName = "manifest.json"
+
+// ManifestFile is an uploaded file.
+type ManifestFile struct {
+	// Key is the object key relative to the prefix of the manifest.
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Modified is the modification time of the local file in nanoseconds,
+	// used to skip the files that did not change.
+	Modified int64 `json:"modified"`
+}
+
+// Manifest lists the complete objects under a prefix. It is written after
+// the objects it lists, so readers that only read the objects of the
+// manifest never see a partial upload.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// Key returns the object key of a key relative to `prefix`.
+func Key(prefix, rel string) string {
+	if prefix == "" {
+		return rel
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + rel
+}
+
+// ReadManifest returns the manifest of a prefix, or an empty manifest if
+// there is none.
+func (c *Client) ReadManifest(ctx context.Context, prefix string) (Manifest, error) {
+	body, err := c.Get(ctx, Key(prefix, ManifestName))
+	if errors.Is(err, ErrNotFound) {
+		return Manifest{}, nil
+	}
+	if err != nil {
+		return Manifest{}, fmt.Errorf("ReadManifest() err: %w", err)
+	}
+	defer body.Close()
+
+	var manifest Manifest
+	err = json.NewDecoder(body).Decode(&manifest)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("ReadManifest() %s err: %w", prefix, err)
+	}
+	return manifest, nil
+}
+
+func fileSHA256(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadFile uploads a local file and returns its manifest entry.
+func (c *Client) uploadFile(ctx context.Context, p, key string, info os.FileInfo) (ManifestFile, error) {
+	sum, err := fileSHA256(p)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	defer f.Close()
+	err = c.Upload(ctx, key, f)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	return ManifestFile{Size: info.Size(), SHA256: sum, Modified: info.ModTime().UnixNano()}, nil
+}
+
+// SyncDir uploads the files of a local directory and its subdirectories
+// under `prefix`, then writes the manifest of the prefix. Files that are in
+// the manifest with the same size and modification time are skipped, as are
+// the .tmp files of the writers. Returns the keys of the uploaded files,
+// relative to `prefix`.
+func (c *Client) SyncDir(ctx context.Context, dir, prefix string) ([]string, error) {
+	manifest, err := c.ReadManifest(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("SyncDir() err: %w", err)
+	}
+	files := make(map[string]ManifestFile)
+	for _, f := range manifest.Files {
+		files[f.Key] = f
+	}
+
+	var uploaded []string
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(p, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ManifestName {
+			return nil
+		}
+		if f, ok := files[rel]; ok && f.Size == info.Size() && f.Modified == info.ModTime().UnixNano() {
+			return nil
+		}
+
+		f, err := c.uploadFile(ctx, p, Key(prefix, rel), info)
+		if err != nil {
+			return err
+		}
+		f.Key = rel
+		files[rel] = f
+		uploaded = append(uploaded, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SyncDir() err: %w", err)
+	}
+	if len(uploaded) == 0 && len(manifest.Files) > 0 {
+		return nil, nil
+	}
+
+	manifest.Files = manifest.Files[:0]
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f)
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Key < manifest.Files[j].Key })
+	data, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("SyncDir() err: %w", err)
+	}
+	err = c.Put(ctx, Key(prefix, ManifestName), data)
+	if err != nil {
+		return nil, fmt.Errorf("SyncDir() manifest err: %w", err)
+	}
+	return uploaded, nil
+}
+
+// Match returns the keys of the manifest of the directory of a key pattern
+// that match the pattern, in the syntax of path.Match. Without a manifest the
+// objects are listed instead.
+func (c *Client) Match(ctx context.Context, pattern string) ([]string, error) {
+	dir := path.Dir(pattern)
+	if dir == "." {
+		dir = ""
+	}
+	manifest, err := c.ReadManifest(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("Match() err: %w", err)
+	}
+
+	var keys []string
+	if len(manifest.Files) > 0 {
+		for _, f := range manifest.Files {
+			keys = append(keys, Key(dir, f.Key))
+		}
+	} else {
+		listPrefix := pattern
+		if i := strings.IndexAny(pattern, "*?[\\"); i >= 0 {
+			listPrefix = pattern[:i]
+		}
+		objects, err := c.List(ctx, listPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("Match() err: %w", err)
+		}
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+		}
+	}
+
+	var res []string
+	for _, key := range keys {
+		if ok, err := path.Match(pattern, key); err != nil {
+			return nil, fmt.Errorf("Match() err: %w", err)
+		} else if ok {
+			res = append(res, key)
+		}
+	}
+	return res, nil
+}
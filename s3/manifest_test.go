@@ -0,0 +1,105 @@
+package s3_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/indexer/s3"
+)
+
+func writeFile(t *testing.T, p, content string, modified time.Time) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(p, modified, modified))
+}
+
+func TestSyncDir(t *testing.T) {
+	server := makeServer(t)
+	client := makeClient(t, server.Options("blocks"))
+	ctx := context.Background()
+	dir := t.TempDir()
+	t0 := time.Unix(1600000000, 0)
+
+	writeFile(t, filepath.Join(dir, "txn", "txn_0-999.avro"), "txns", t0)
+	writeFile(t, filepath.Join(dir, "block_header", "block_header_0-999.avro"), "headers", t0)
+	writeFile(t, filepath.Join(dir, "txn", "txn_1000-1999.avro.tmp"), "partial", t0)
+
+	uploaded, err := client.SyncDir(ctx, dir, "export")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"block_header/block_header_0-999.avro", "txn/txn_0-999.avro"}, uploaded)
+	assert.Equal(t, []string{
+		"export/block_header/block_header_0-999.avro",
+		"export/manifest.json",
+		"export/txn/txn_0-999.avro",
+	}, server.Keys("blocks"))
+
+	manifest, err := client.ReadManifest(ctx, "export")
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "txn/txn_0-999.avro", manifest.Files[1].Key)
+	assert.Equal(t, int64(4), manifest.Files[1].Size)
+	assert.Equal(t, t0.UnixNano(), manifest.Files[1].Modified)
+
+	// Unchanged files are skipped and the manifest is not rewritten.
+	puts := server.Requests("PutObject")
+	uploaded, err = client.SyncDir(ctx, dir, "export")
+	require.NoError(t, err)
+	assert.Empty(t, uploaded)
+	assert.Equal(t, puts, server.Requests("PutObject"))
+
+	// A changed file is uploaded again.
+	writeFile(t, filepath.Join(dir, "txn", "txn_0-999.avro"), "txns2", t0.Add(time.Second))
+	uploaded, err = client.SyncDir(ctx, dir, "export")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"txn/txn_0-999.avro"}, uploaded)
+	data, _ := server.Object("blocks", "export/txn/txn_0-999.avro")
+	assert.Equal(t, "txns2", string(data))
+}
+
+func TestSyncDirChecksum(t *testing.T) {
+	server := makeServer(t)
+	client := makeClient(t, server.Options("blocks"))
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a"), "abc", time.Now())
+
+	_, err := client.SyncDir(context.Background(), dir, "")
+	require.NoError(t, err)
+
+	data, ok := server.Object("blocks", s3.ManifestName)
+	require.True(t, ok)
+	var manifest s3.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Len(t, manifest.Files, 1)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", manifest.Files[0].SHA256)
+}
+
+func TestMatch(t *testing.T) {
+	server := makeServer(t)
+	client := makeClient(t, server.Options("blocks"))
+	ctx := context.Background()
+
+	// Without a manifest the objects are listed.
+	for _, key := range []string{"archive/blocks_0-999.tar.gz", "archive/blocks_1000-1999.tar.gz", "archive/notes.txt"} {
+		require.NoError(t, client.Put(ctx, key, []byte(key)))
+	}
+	keys, err := client.Match(ctx, "archive/*.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive/blocks_0-999.tar.gz", "archive/blocks_1000-1999.tar.gz"}, keys)
+
+	// With a manifest, objects that are not in it are partial uploads.
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "blocks_0-999.tar.gz"), "x", time.Now())
+	_, err = client.SyncDir(ctx, dir, "archive")
+	require.NoError(t, err)
+	keys, err = client.Match(ctx, "archive/*.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive/blocks_0-999.tar.gz"}, keys)
+}
@@ -0,0 +1,337 @@
+// Package s3test provides an in-process S3 compatible server, a stand-in for
+// MinIO in tests. It checks the request signatures and the content
+// checksums, and enforces the multipart upload rules of S3.
+package s3test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/algorand/indexer/s3"
+	"github.com/algorand/indexer/s3/internal/sigv4"
+)
+
+// Credentials accepted by the server.
+const (
+	AccessKey = "indexer-test"
+	SecretKey = "indexer-test-secret"
+)
+
+type upload struct {
+	bucket string
+	key    string
+	parts  map[int][]byte
+}
+
+// Server is an S3 compatible server keeping the objects in memory.
+type Server struct {
+	server *httptest.Server
+
+	// MinPartSize is the smallest part of a multipart upload except for the
+	// last, s3.MinPartSize by default.
+	MinPartSize int
+
+	// PageSize is the number of keys of a listing page.
+	PageSize int
+
+	mu       sync.Mutex
+	buckets  map[string]map[string][]byte
+	uploads  map[string]*upload
+	nextID   int
+	requests map[string]int
+}
+
+// NewServer starts a server on a local port.
+func NewServer() *Server {
+	s := &Server{
+		MinPartSize: s3.MinPartSize,
+		PageSize:    1000,
+		buckets:     make(map[string]map[string][]byte),
+		uploads:     make(map[string]*upload),
+		requests:    make(map[string]int),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL returns the endpoint of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Options returns the client options of a bucket of the server.
+func (s *Server) Options(bucket string) s3.Options {
+	return s3.Options{
+		Endpoint:  s.URL(),
+		Bucket:    bucket,
+		AccessKey: AccessKey,
+		SecretKey: SecretKey,
+	}
+}
+
+// CreateBucket creates an empty bucket.
+func (s *Server) CreateBucket(bucket string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[bucket] = make(map[string][]byte)
+}
+
+// Object returns the content of an object.
+func (s *Server) Object(bucket, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.buckets[bucket][key]
+	return data, ok
+}
+
+// Keys returns the sorted keys of the objects of a bucket.
+func (s *Server) Keys(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.buckets[bucket])
+}
+
+// Uploads returns the number of multipart uploads in progress.
+func (s *Server) Uploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.uploads)
+}
+
+// Requests returns the number of requests of a kind, e.g. "PutObject" or
+// "UploadPart".
+func (s *Server) Requests(kind string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[kind]
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func sortedKeys(objects map[string][]byte) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(&s3.Error{Code: code, Message: message})
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	err := sigv4.Verify(r, func(accessKey string) (string, bool) {
+		return SecretKey, accessKey == AccessKey
+	})
+	if err != nil {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if r.Header.Get(sigv4.HeaderContentSHA256) != sigv4.HashHex(body) {
+		writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch", "payload hash mismatch")
+		return
+	}
+	if md5sum := r.Header.Get("Content-Md5"); md5sum != "" {
+		sum := md5.Sum(body)
+		if md5sum != base64.StdEncoding.EncodeToString(sum[:]) {
+			writeError(w, http.StatusBadRequest, "BadDigest", "Content-MD5 mismatch")
+			return
+		}
+	}
+
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket := path[0]
+	key := ""
+	if len(path) == 2 {
+		key = path[1]
+	}
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", bucket)
+		return
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodGet && query.Get("list-type") == "2":
+		s.requests["ListObjectsV2"]++
+		s.list(w, objects, query)
+	case key == "":
+		writeError(w, http.StatusNotImplemented, "NotImplemented", r.Method+" bucket")
+	case r.Method == http.MethodPost && query["uploads"] != nil:
+		s.requests["CreateMultipartUpload"]++
+		s.nextID++
+		id := strconv.Itoa(s.nextID)
+		s.uploads[id] = &upload{bucket: bucket, key: key, parts: make(map[int][]byte)}
+		writeXML(w, &struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadID string `xml:"UploadId"`
+		}{Bucket: bucket, Key: key, UploadID: id})
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		s.requests["UploadPart"]++
+		u, ok := s.uploads[query.Get("uploadId")]
+		number, err := strconv.Atoi(query.Get("partNumber"))
+		if !ok || u.bucket != bucket || u.key != key {
+			writeError(w, http.StatusNotFound, "NoSuchUpload", query.Get("uploadId"))
+			return
+		}
+		if err != nil || number < 1 || number > 10000 {
+			writeError(w, http.StatusBadRequest, "InvalidArgument", "bad part number")
+			return
+		}
+		u.parts[number] = body
+		w.Header().Set("ETag", etag(body))
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		s.requests["CompleteMultipartUpload"]++
+		s.complete(w, objects, bucket, key, query.Get("uploadId"), body)
+	case r.Method == http.MethodDelete && query.Get("uploadId") != "":
+		s.requests["AbortMultipartUpload"]++
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.requests["PutObject"]++
+		objects[key] = body
+		w.Header().Set("ETag", etag(body))
+	case r.Method == http.MethodGet:
+		s.requests["GetObject"]++
+		data, ok := objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", key)
+			return
+		}
+		w.Header().Set("ETag", etag(data))
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		s.requests["DeleteObject"]++
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", r.Method)
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, objects map[string][]byte, query map[string][]string) {
+	prefix := ""
+	if v := query["prefix"]; len(v) > 0 {
+		prefix = v[0]
+	}
+	after := ""
+	if v := query["continuation-token"]; len(v) > 0 {
+		after = v[0]
+	}
+
+	type contents struct {
+		Key  string
+		Size int
+		ETag string
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Prefix                string
+		KeyCount              int
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+		Contents              []contents
+	}{Prefix: prefix}
+	for _, key := range sortedKeys(objects) {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+		if len(result.Contents) == s.PageSize {
+			result.IsTruncated = true
+			result.NextContinuationToken = result.Contents[len(result.Contents)-1].Key
+			break
+		}
+		result.Contents = append(result.Contents, contents{Key: key, Size: len(objects[key]), ETag: etag(objects[key])})
+	}
+	result.KeyCount = len(result.Contents)
+	writeXML(w, &result)
+}
+
+func (s *Server) complete(w http.ResponseWriter, objects map[string][]byte, bucket, key, id string, body []byte) {
+	u, ok := s.uploads[id]
+	if !ok || u.bucket != bucket || u.key != key {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", id)
+		return
+	}
+	var req struct {
+		Parts []struct {
+			PartNumber int
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Parts) == 0 {
+		writeError(w, http.StatusBadRequest, "MalformedXML", "bad part list")
+		return
+	}
+
+	var data bytes.Buffer
+	var md5s []byte
+	for i, part := range req.Parts {
+		content, ok := u.parts[part.PartNumber]
+		if !ok || etag(content) != part.ETag {
+			writeError(w, http.StatusBadRequest, "InvalidPart", fmt.Sprint(part.PartNumber))
+			return
+		}
+		if i > 0 && part.PartNumber <= req.Parts[i-1].PartNumber {
+			writeError(w, http.StatusBadRequest, "InvalidPartOrder", fmt.Sprint(part.PartNumber))
+			return
+		}
+		if i < len(req.Parts)-1 && len(content) < s.MinPartSize {
+			// S3 reports this error in the body of a 200 response.
+			writeXML(w, &s3.Error{Code: "EntityTooSmall", Message: fmt.Sprint(part.PartNumber)})
+			return
+		}
+		data.Write(content)
+		sum := md5.Sum(content)
+		md5s = append(md5s, sum[:]...)
+	}
+
+	objects[key] = data.Bytes()
+	delete(s.uploads, id)
+	sum := md5.Sum(md5s)
+	writeXML(w, &struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: bucket, Key: key, ETag: fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(req.Parts))})
+}
@@ -576,7 +576,7 @@ function create_delete_tests() {
     # Asset Holding Tests #
     #######################
     sql_test "[sql] asset optin" $1 \
-      "select deleted, created_at, closed_at, assetid from account_asset WHERE addr=decode('MFkWBNGTXkuqhxtNVtRZYFN6jHUWeQQxqEn5cUp1DGs=', 'base64') AND assetid=27" \
+      "select deleted, created_at, closed_at, assetid from account_asset aa JOIN address a ON aa.addr_id = a.addr_id WHERE a.addr=decode('MFkWBNGTXkuqhxtNVtRZYFN6jHUWeQQxqEn5cUp1DGs=', 'base64') AND assetid=27" \
       "f|13||27"
     rest_test "[rest - balances] asset optin" \
       "/v2/assets/27/balances?pretty&currency-less-than=100" \
@@ -592,7 +592,7 @@ function create_delete_tests() {
       '"opted-in-at-round": 13'
 
     sql_test "[sql] asset optin / close-out" $1 \
-      "select deleted, created_at, closed_at, assetid from account_asset WHERE addr=decode('E/p3R9m9X0c7eAv9DapnDcuNGC47kU0BxIVdSgHaFbk=', 'base64') AND assetid=36" \
+      "select deleted, created_at, closed_at, assetid from account_asset aa JOIN address a ON aa.addr_id = a.addr_id WHERE a.addr=decode('E/p3R9m9X0c7eAv9DapnDcuNGC47kU0BxIVdSgHaFbk=', 'base64') AND assetid=36" \
       "t|16|25|36"
     rest_test "[rest] asset optin" \
       "/v2/assets/36/balances?pretty&currency-less-than=100" \
@@ -608,7 +608,7 @@ function create_delete_tests() {
       '"opted-out-at-round": 25'
 
     sql_test "[sql] asset optin / close-out / optin / close-out" $1 \
-      "select deleted, created_at, closed_at, assetid from account_asset WHERE addr=decode('ZF6AVNLThS9R3lC9jO+c7DQxMGyJvOqrNSYQdZPBQ0Y=', 'base64') AND assetid=135" \
+      "select deleted, created_at, closed_at, assetid from account_asset aa JOIN address a ON aa.addr_id = a.addr_id WHERE a.addr=decode('ZF6AVNLThS9R3lC9jO+c7DQxMGyJvOqrNSYQdZPBQ0Y=', 'base64') AND assetid=135" \
       "t|25|31|135"
     rest_test "[rest] asset optin" \
       "/v2/assets/135/balances?pretty&currency-less-than=100" \
@@ -628,7 +628,7 @@ function create_delete_tests() {
       '"opted-out-at-round": 31'
 
     sql_test "[sql] asset optin / close-out / optin" $1 \
-      "select deleted, created_at, closed_at, assetid from account_asset WHERE addr=decode('ZF6AVNLThS9R3lC9jO+c7DQxMGyJvOqrNSYQdZPBQ0Y=', 'base64') AND assetid=168" \
+      "select deleted, created_at, closed_at, assetid from account_asset aa JOIN address a ON aa.addr_id = a.addr_id WHERE a.addr=decode('ZF6AVNLThS9R3lC9jO+c7DQxMGyJvOqrNSYQdZPBQ0Y=', 'base64') AND assetid=168" \
       "f|37|39|168"
     rest_test "[rest] asset optin" \
       "/v2/assets/168/balances?pretty&currency-less-than=100" \